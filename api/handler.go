@@ -11,6 +11,7 @@ type HandlerProvider interface {
 	CreateAccount(ctx *gin.Context)
 	GetAccount(ctx *gin.Context)
 	ListAccounts(ctx *gin.Context)
+	ListTransfers(ctx *gin.Context)
 	UpdateAccount(ctx *gin.Context)
 	DeleteAccount(ctx *gin.Context)
 	TransferMoney(ctx *gin.Context)
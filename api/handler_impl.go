@@ -14,15 +14,19 @@ import (
 	"github.com/zde37/Swift_Bank/config"
 	"github.com/zde37/Swift_Bank/helpers"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/rates"
+	"github.com/zde37/Swift_Bank/repository"
 	"github.com/zde37/Swift_Bank/service"
 	"github.com/zde37/Swift_Bank/token"
+	"github.com/zde37/Swift_Bank/val"
 )
 
 type handlerImpl struct {
-	service    service.ServiceProvider
-	tokenMaker token.Maker
-	router     *gin.Engine
-	config     config.Config
+	service        service.ServiceProvider
+	tokenMaker     token.Maker
+	router         *gin.Engine
+	config         config.Config
+	passwordPolicy val.PasswordPolicy
 }
 
 func newHandlerImpl(c config.Config, s service.ServiceProvider) (*handlerImpl, error) {
@@ -31,16 +35,31 @@ func newHandlerImpl(c config.Config, s service.ServiceProvider) (*handlerImpl, e
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	passwordPolicy, err := val.NewPasswordPolicy(c.PasswordMinLength, c.PasswordCharClasses, c.PasswordRejectUserInfo, c.PasswordRejectCommon)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password policy: %w", err)
+	}
+
 	handlerImpl := handlerImpl{
-		service:    s,
-		config:     c,
-		tokenMaker: tokenMaker,
+		service:        s,
+		config:         c,
+		tokenMaker:     tokenMaker,
+		passwordPolicy: passwordPolicy,
 	}
 	r := gin.New()
 
-	// register currency validator
+	// without a proxy in front, X-Forwarded-For is whatever the client sent
+	if !c.BehindProxy {
+		if err := r.SetTrustedProxies(nil); err != nil {
+			return nil, fmt.Errorf("cannot set trusted proxies: %w", err)
+		}
+	}
+
+	// register currency and bank code validators
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", helpers.ValidCurrency)
+		v.RegisterValidation("iban", helpers.ValidIBAN)
+		v.RegisterValidation("bic", helpers.ValidBIC)
 	}
 
 	// inProduction := false
@@ -85,7 +104,7 @@ func (h *handlerImpl) registerRoutes() {
 		v1.POST("/users/login", h.LoginUser)
 		v1.POST("/tokens/renew_access", h.RenewAccessToken)
 
-		authRoutes := v1.Group("/").Use(auth(h.tokenMaker))
+		authRoutes := v1.Group("/").Use(authMiddleware(h.tokenMaker))
 
 		authRoutes.POST("/account", h.CreateAccount)
 		authRoutes.GET("/account/:id", h.GetAccount)
@@ -94,8 +113,10 @@ func (h *handlerImpl) registerRoutes() {
 		authRoutes.DELETE("/account/:id", h.DeleteAccount)
 
 		authRoutes.GET("/users/:id", h.GetUser)
-		authRoutes.GET("/users", h.ListUsers)
-		authRoutes.POST("/transfer", h.TransferMoney)
+		authRoutes.GET("/users", roleMiddleware(helpers.BankerRole), h.ListUsers)
+		authRoutes.GET("/transfers", h.ListTransfers)
+		authRoutes.POST("/transfers", h.TransferMoney)
+		authRoutes.POST("/transfer", h.TransferMoney) // the old path, kept for existing clients
 	}
 
 }
@@ -107,9 +128,17 @@ func (h *handlerImpl) CreateAccount(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authPayload := authPayload(ctx)
 	createdAccount, err := h.service.CreateAccount(ctx, req, authPayload.UserName)
 	if err != nil {
+		var existsErr *service.AccountExistsError
+		if errors.As(err, &existsErr) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":      existsErr.Error(),
+				"account_id": existsErr.AccountID,
+			})
+			return
+		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			ctx.JSON(http.StatusForbidden, h.errorResponse(err))
@@ -129,7 +158,26 @@ func (h *handlerImpl) GetAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.service.GetAccount(ctx, req.ID)
+	var query models.GetAccountQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
+		return
+	}
+
+	authPayload := authPayload(ctx)
+
+	// deleted accounts are only looked up for bankers who ask for them
+	getAccount := h.service.GetAccount
+	if query.IncludeDeleted {
+		if authPayload.Role != helpers.BankerRole {
+			err := errors.New("only bankers can include deleted accounts")
+			ctx.JSON(http.StatusForbidden, h.errorResponse(err))
+			return
+		}
+		getAccount = h.service.GetAccountIncludingDeleted
+	}
+
+	account, err := getAccount(ctx, req.ID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, h.errorResponse(err))
@@ -140,7 +188,6 @@ func (h *handlerImpl) GetAccount(ctx *gin.Context) {
 	}
 
 	// authorization rule
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if account.Owner != authPayload.UserName {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, h.errorResponse(err))
@@ -151,20 +198,56 @@ func (h *handlerImpl) GetAccount(ctx *gin.Context) {
 }
 
 func (h *handlerImpl) ListAccounts(ctx *gin.Context) {
-	var req models.ListAccountRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
+	var req models.ListAccountsRequest
+	if !bindQuery(ctx, &req) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	accounts, err := h.service.ListAccounts(ctx, authPayload.UserName, req.PageSize, ((req.PageID - 1) * req.PageSize))
+	authPayload := authPayload(ctx)
+	// one more than asked for tells whether there is a next page
+	accounts, err := h.service.ListAccounts(ctx, models.ListAccountsParams{
+		Owner:  authPayload.UserName,
+		Type:   req.Type,
+		Limit:  req.PageSize + 1,
+		Offset: req.Offset(),
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, newPageResponse(accounts, req.PageRequest))
+}
+
+func (h *handlerImpl) ListTransfers(ctx *gin.Context) {
+	var req models.ListTransfersRequest
+	if !bindQuery(ctx, &req) {
+		return
+	}
+
+	account, err := h.service.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, h.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
+		return
+	}
+
+	if account.Owner != authPayload(ctx).UserName {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, h.errorResponse(err))
+		return
+	}
+
+	transfers, err := h.service.ListTransactions(ctx, account.ID, account.ID, int64(req.PageSize)+1, int64(req.Offset()))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPageResponse(transfers, req.PageRequest))
 }
 
 func (h *handlerImpl) UpdateAccount(ctx *gin.Context) {
@@ -180,12 +263,41 @@ func (h *handlerImpl) UpdateAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.service.AddAccountBalance(ctx, req.ID, update.Balance)
+	account, valid := h.validAccount(ctx, req.ID, "")
+	if !valid {
+		return
+	}
+
+	// a deposit is positive and a withdrawal negative, either moves a valid amount
+	units := update.Balance
+	if units < 0 {
+		units = -units
+	}
+	if err := val.ValidateAmountUnits(units, account.Currency); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("balance %w", err)))
+		return
+	}
+
+	tags, valid := h.validCategory(ctx, update.Category, update.Tags)
+	if !valid {
+		return
+	}
+
+	account, err := h.service.AddAccountBalance(ctx, models.AddAccountBalanceParams{
+		AccountID: req.ID,
+		Amount:    update.Balance,
+		Category:  update.Category,
+		Tags:      tags,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, h.errorResponse(err))
 			return
 		}
+		if errors.Is(err, service.ErrUnknownCategory) {
+			ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
 		return
 	}
@@ -219,6 +331,12 @@ func (h *handlerImpl) CreateUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
 		return
 	}
+	req.UserName = val.NormalizeUsername(req.UserName)
+	req.Email = val.NormalizeEmail(req.Email)
+	if err := val.ValidatePassword(req.Password, h.passwordPolicy, req.UserName, req.Email); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("password %w", err)))
+		return
+	}
 	hashedPassword, err := helpers.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
@@ -228,6 +346,13 @@ func (h *handlerImpl) CreateUser(ctx *gin.Context) {
 
 	createdUser, err := h.service.CreateUser(ctx, req)
 	if err != nil {
+		if field, ok := repository.TakenUserField(err); ok {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("%s is already taken", field),
+				"field": field,
+			})
+			return
+		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
@@ -246,6 +371,7 @@ func (h *handlerImpl) LoginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
 		return
 	}
+	req.UserName = val.NormalizeUsername(req.UserName)
 
 	user, err := h.service.LoginUser(ctx, req)
 	if err != nil {
@@ -258,13 +384,13 @@ func (h *handlerImpl) LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, accessTokenPayload, err := h.tokenMaker.CreateToken(req.UserName, h.config.AccessTokenDuration)
+	accessToken, accessTokenPayload, err := h.tokenMaker.CreateToken(user.UserName, user.Role, h.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
 		return
 	}
 
-	refreshToken, refreshTokenPayload, err := h.tokenMaker.CreateToken(req.UserName, h.config.RefreshTokenDuration)
+	refreshToken, refreshTokenPayload, err := h.tokenMaker.CreateToken(user.UserName, user.Role, h.config.RefreshTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
 		return
@@ -340,6 +466,7 @@ func (h *handlerImpl) RenewAccessToken(ctx *gin.Context) {
 
 	accessToken, accessTokenPayload, err := h.tokenMaker.CreateToken(
 		refreshTokenPayload.UserName,
+		refreshTokenPayload.Role,
 		h.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -396,28 +523,38 @@ func (h *handlerImpl) TransferMoney(ctx *gin.Context) {
 		return
 	}
 
-	fromAccount, valid := h.validAccount(ctx, req.FromAccountID, req.Currency)
-	// check if the currencies match
+	if err := val.ValidateAmountUnits(req.Amount, req.Currency); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("amount %w", err)))
+		return
+	}
+
+	req.Description = val.NormalizeDescription(req.Description)
+	if err := val.ValidateDescription(req.Description); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("description %w", err)))
+		return
+	}
+
+	tags, valid := h.validCategory(ctx, req.Category, req.Tags)
 	if !valid {
 		return
 	}
 
-	// check if sender has enough money
-	if fromAccount.Balance < req.Amount {
-		err := errors.New("sender does not have sufficient funds to transfer")
-		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
+	fromAccount, valid := h.validAccount(ctx, req.FromAccountID, req.Currency)
+	// check if the currencies match
+	if !valid {
 		return
 	}
 
 	// authorization rule
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authPayload := authPayload(ctx)
 	if fromAccount.Owner != authPayload.UserName {
 		err := errors.New("sender account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, h.errorResponse(err))
+		ctx.JSON(http.StatusForbidden, h.errorResponse(err))
 		return
 	}
 
-	_, valid = h.validAccount(ctx, req.ToAccountID, req.Currency)
+	// the receiver may hold a different currency, the service converts the amount
+	_, valid = h.validAccount(ctx, req.ToAccountID, "")
 	if !valid {
 		return
 	}
@@ -428,11 +565,34 @@ func (h *handlerImpl) TransferMoney(ctx *gin.Context) {
 		Amount:        req.Amount,
 		Currency:      req.Currency,
 		Description:   req.Description,
-		Fee:           req.Fee,
+		Category:      req.Category,
+		Tags:          tags,
 	}
 
 	createdAccount, err := h.service.TransferTx(ctx, arg)
 	if err != nil {
+		// the balance check includes the transfer fee, so it happens in the service
+		if errors.Is(err, service.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusConflict, h.errorResponse(err))
+			return
+		}
+		if errors.Is(err, service.ErrTransferLimitReached) {
+			ctx.JSON(http.StatusTooManyRequests, h.errorResponse(err))
+			return
+		}
+		// an account closed since it was looked up
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, h.errorResponse(err))
+			return
+		}
+		if errors.Is(err, service.ErrUnknownCategory) {
+			ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
+			return
+		}
+		if errors.Is(err, rates.ErrRateUnavailable) || errors.Is(err, rates.ErrRateStale) || errors.Is(err, service.ErrCurrencyDisabled) {
+			ctx.JSON(http.StatusPreconditionFailed, h.errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, h.errorResponse(err))
 		return
 	}
@@ -451,7 +611,7 @@ func (h *handlerImpl) validAccount(ctx *gin.Context, accountID int64, currency s
 		return account, false
 	}
 
-	if account.Currency != currency {
+	if currency != "" && account.Currency != currency {
 		err = fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, h.errorResponse(err))
 		return account, false
@@ -460,6 +620,25 @@ func (h *handlerImpl) validAccount(ctx *gin.Context, accountID int64, currency s
 	return account, true
 }
 
+// validCategory validates the optional category and tags of a request and returns the
+// normalized tags. Whether the category exists is only known to the database.
+func (h *handlerImpl) validCategory(ctx *gin.Context, category string, tags []string) ([]string, bool) {
+	if category != "" {
+		if err := val.ValidateCategory(category); err != nil {
+			ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("category %w", err)))
+			return nil, false
+		}
+	}
+
+	tags = val.NormalizeTags(tags)
+	if err := val.ValidateTags(tags); err != nil {
+		ctx.JSON(http.StatusBadRequest, h.errorResponse(fmt.Errorf("tags %w", err)))
+		return nil, false
+	}
+
+	return tags, true
+}
+
 func (h *handlerImpl) errorResponse(err error) gin.H {
 	return gin.H{
 		"error": err.Error(),
@@ -18,6 +18,7 @@ import (
 	"github.com/zde37/Swift_Bank/helpers"
 	mockedproviders "github.com/zde37/Swift_Bank/mock"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/service"
 	"github.com/zde37/Swift_Bank/token"
 	"go.uber.org/mock/gomock"
 )
@@ -53,10 +54,14 @@ func eqCreateUserRequest(a models.CreateUserRequest, p string) gomock.Matcher {
 func TestGetAccountAPI(t *testing.T) {
 	user, _ := randomUser()
 	account := randomAccount(user.UserName)
+	deletedAt := time.Now().UTC().Truncate(time.Second)
+	deletedAccount := account
+	deletedAccount.DeletedAt = &deletedAt
 
 	testCases := []struct {
 		name          string
 		accountID     int64
+		query         string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(service *mockedproviders.MockServiceProvider)
 		checkResponse func(recorder *httptest.ResponseRecorder)
@@ -140,6 +145,40 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:      "DELETED",
+			accountID: account.ID,
+			query:     "?include_deleted=true",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().
+					GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(deletedAccount.ID)).
+					Times(1).
+					Return(deletedAccount, nil)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, user.UserName, helpers.BankerRole, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchWithAccount(t, recorder.Body, deletedAccount)
+			},
+		},
+		{
+			name:      "DELETED NOT BANKER",
+			accountID: account.ID,
+			query:     "?include_deleted=true",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Any()).Times(0)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.UserName, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:      "INVALID ID",
 			accountID: 0,
@@ -174,7 +213,7 @@ func TestGetAccountAPI(t *testing.T) {
 			require.NoError(t, err)
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/sb/api/v1/account/%d", tc.accountID)
+			url := fmt.Sprintf("/sb/api/v1/account/%d%s", tc.accountID, tc.query)
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
@@ -249,7 +288,45 @@ func TestCreateUserAPI(t *testing.T) {
 				service.EXPECT().
 					CreateUser(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(models.User{}, &pgconn.PgError{Code: "23505"})
+					Return(models.User{}, &pgconn.PgError{Code: "23505", ConstraintName: "users_pkey"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireTakenField(t, recorder.Body, "username")
+			},
+		},
+		{
+			name: "DUPLICATE EMAIL",
+			body: gin.H{
+				"username": user.UserName,
+				"fullname": user.FullName,
+				"password": password,
+				"email":    user.Email,
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.User{}, &pgconn.PgError{Code: "23505", ConstraintName: "users_lower_email_key"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireTakenField(t, recorder.Body, "email")
+			},
+		},
+		{
+			name: "DB ERROR",
+			body: gin.H{
+				"username": user.UserName,
+				"fullname": user.FullName,
+				"password": password,
+				"email":    user.Email,
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.User{}, &pgconn.PgError{Code: "23514"})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -339,12 +416,256 @@ func TestCreateUserAPI(t *testing.T) {
 	}
 }
 
+func TestCreateAccountAPI(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount(user.UserName)
+	existsErr := &service.AccountExistsError{AccountID: account.ID, Currency: account.Currency}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(models.CreateAccountRequest{Currency: account.Currency}), gomock.Eq(user.UserName)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchWithAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "ACCOUNT EXISTS",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.Account{}, existsErr)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+
+				var body struct {
+					Error     string `json:"error"`
+					AccountID int64  `json:"account_id"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, account.ID, body.AccountID)
+				require.Equal(t, existsErr.Error(), body.Error)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server, err := NewHandler(testConfig, service)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"currency": account.Currency})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/sb/api/v1/account", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.H.GetTokenMaker(), authorizationTypeBearer, user.UserName, time.Minute)
+			server.H.GetGin().ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestTransferMoneyAPI(t *testing.T) {
+	user, _ := randomUser()
+	other, _ := randomUser()
+	from := randomAccount(user.UserName)
+	to := randomAccount(other.UserName)
+	amount := int64(10)
+
+	body := func(changes func(body gin.H)) gin.H {
+		b := gin.H{
+			"from_account_id": from.ID,
+			"to_account_id":   to.ID,
+			"amount":          amount,
+			"currency":        from.Currency,
+		}
+		if changes != nil {
+			changes(b)
+		}
+		return b
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(provider *mockedproviders.MockServiceProvider)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     body(nil),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				arg := models.TransferTxParams{
+					FromAccountID: from.ID,
+					ToAccountID:   to.ID,
+					Amount:        amount,
+					Currency:      from.Currency,
+				}
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(models.TransferTxResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NOT OWNER",
+			body:     body(nil),
+			username: other.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "SENDER NOT FOUND",
+			body:     body(nil),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "RECEIVER NOT FOUND",
+			body:     body(nil),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "CURRENCY MISMATCH",
+			body:     body(func(b gin.H) { b["currency"] = otherCurrency(from.Currency) }),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "INSUFFICIENT FUNDS",
+			body:     body(nil),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				provider.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(models.TransferTxResult{}, service.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "UNSUPPORTED CURRENCY",
+			body:     body(func(b gin.H) { b["currency"] = "XYZ" }),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NEGATIVE AMOUNT",
+			body:     body(func(b gin.H) { b["amount"] = -amount }),
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NO AUTHORIZATION",
+			body: body(nil),
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server, err := NewHandler(testConfig, service)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/sb/api/v1/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if tc.username != "" {
+				addAuthorization(t, request, server.H.GetTokenMaker(), authorizationTypeBearer, tc.username, time.Minute)
+			}
+			server.H.GetGin().ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// otherCurrency returns a supported currency other than currency
+func otherCurrency(currency string) string {
+	if currency == helpers.USD {
+		return helpers.EUR
+	}
+	return helpers.USD
+}
+
 func randomAccount(owner string) models.Account {
 	return models.Account{
 		ID:       helpers.RandomInt(1, 1000),
 		Owner:    owner,
-		Balance:  float64(helpers.RandomMoney()),
+		Balance:  helpers.RandomMoney(),
 		Currency: helpers.RandomCurrency(),
+		Type:     models.AccountChecking,
 	}
 }
 
@@ -375,3 +696,12 @@ func requireBodyMatchWithUser(t *testing.T, body *bytes.Buffer, user models.User
 	require.NoError(t, err)
 	require.Equal(t, gotUser, user)
 }
+
+func requireTakenField(t *testing.T, body *bytes.Buffer, field string) {
+	var answer struct {
+		Field string `json:"field"`
+	}
+	err := json.Unmarshal(body.Bytes(), &answer)
+	require.NoError(t, err)
+	require.Equal(t, field, answer.Field)
+}
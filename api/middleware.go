@@ -1,9 +1,11 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -13,10 +15,19 @@ import (
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
-	authorizationPayloadKey = "authorization_payload"
 )
 
-func auth(tokenMaker token.Maker) gin.HandlerFunc {
+type authPayloadKey struct{}
+
+// authPayload returns the access token payload of the caller, put in the request context by
+// authMiddleware. Only handlers behind authMiddleware may call it.
+func authPayload(ctx *gin.Context) *token.Payload {
+	payload, _ := ctx.Request.Context().Value(authPayloadKey{}).(*token.Payload)
+	return payload
+}
+
+// authMiddleware lets through requests with a valid bearer access token, and aborts the others with 401
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -46,7 +57,28 @@ func auth(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), authPayloadKey{}, payload))
+		ctx.Next()
+	}
+}
+
+// roleMiddleware lets through callers with one of roles and aborts the others with 403.
+// It goes after authMiddleware.
+func roleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := authPayload(ctx)
+		if payload == nil {
+			err := errors.New("access token is not verified")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !slices.Contains(roles, payload.Role) {
+			err := fmt.Errorf("role %s may not access this resource", payload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
 		ctx.Next()
 	}
 }
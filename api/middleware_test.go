@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
 	mockedproviders "github.com/zde37/Swift_Bank/mock"
 	"github.com/zde37/Swift_Bank/token"
 	"go.uber.org/mock/gomock"
@@ -22,7 +23,19 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, duration)
+	addAuthorizationWithRole(t, request, tokenMaker, authorizationType, username, helpers.DepositorRole, duration)
+}
+
+func addAuthorizationWithRole(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	role string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -44,6 +57,7 @@ func TestAuthMiddleware(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"username":"user"}`, recorder.Body.String())
 			},
 		},
 		{
@@ -94,8 +108,8 @@ func TestAuthMiddleware(t *testing.T) {
 			require.NoError(t, err)
 
 			authPath := "/auth"
-			server.H.GetGin().GET(authPath, auth(server.H.GetTokenMaker()), func(ctx *gin.Context) {
-				ctx.JSON(http.StatusOK, gin.H{})
+			server.H.GetGin().GET(authPath, authMiddleware(server.H.GetTokenMaker()), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{"username": authPayload(ctx).UserName})
 			})
 
 			recorder := httptest.NewRecorder()
@@ -108,3 +122,70 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		code      int
+	}{
+		{
+			name: "ALLOWED ROLE",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "user", helpers.BankerRole, time.Minute)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "OTHER ROLE",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "user", helpers.DepositorRole, time.Minute)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:      "NO AUTHORIZATION",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			code:      http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			server, err := NewHandler(testConfig, service)
+			require.NoError(t, err)
+
+			rolePath := "/role"
+			server.H.GetGin().GET(rolePath, authMiddleware(server.H.GetTokenMaker()), roleMiddleware(helpers.BankerRole), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, rolePath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.H.GetTokenMaker())
+			server.H.GetGin().ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestListUsersBankerOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().ListUsers(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	server, err := NewHandler(testConfig, service)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/sb/api/v1/users?page_id=1&page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.H.GetTokenMaker(), authorizationTypeBearer, "user", time.Minute)
+	server.H.GetGin().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// pageResponse is the envelope of a page of a listing
+type pageResponse[T any] struct {
+	Data     []T   `json:"data"`
+	PageID   int32 `json:"page_id"`
+	PageSize int32 `json:"page_size"`
+	HasMore  bool  `json:"has_more"`
+}
+
+// newPageResponse wraps the rows of page. They are expected to be fetched with one more than
+// page.PageSize as limit, an extra row means there is a next page and is left out.
+func newPageResponse[T any](rows []T, page models.PageRequest) pageResponse[T] {
+	rsp := pageResponse[T]{
+		Data:     rows,
+		PageID:   page.PageID,
+		PageSize: page.PageSize,
+	}
+	if len(rows) > int(page.PageSize) {
+		rsp.Data, rsp.HasMore = rows[:page.PageSize], true
+	}
+	if rsp.Data == nil {
+		rsp.Data = []T{}
+	}
+
+	return rsp
+}
+
+// bindQuery binds the query parameters into req, a pointer to a struct. When they are not
+// valid it answers 400 with a message for each parameter at fault, and returns false.
+func bindQuery(ctx *gin.Context, req any) bool {
+	err := ctx.ShouldBindQuery(req)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		// a value that is not even of the right type, like page_id=one
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields[queryParamName(req, fieldErr.StructField())] = validationMessage(fieldErr)
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error":  "invalid query parameters",
+		"fields": fields,
+	})
+	return false
+}
+
+// queryParamName returns the form tag of the field of req, promoted fields included
+func queryParamName(req any, field string) string {
+	structField, ok := reflect.TypeOf(req).Elem().FieldByName(field)
+	if !ok {
+		return field
+	}
+	if name, _, _ := strings.Cut(structField.Tag.Get("form"), ","); name != "" {
+		return name
+	}
+	return field
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"go.uber.org/mock/gomock"
+)
+
+type pageBody struct {
+	Data     []json.RawMessage `json:"data"`
+	PageID   int32             `json:"page_id"`
+	PageSize int32             `json:"page_size"`
+	HasMore  bool              `json:"has_more"`
+	Fields   map[string]string `json:"fields"`
+}
+
+func TestListAccountsPagination(t *testing.T) {
+	user, _ := randomUser()
+
+	testCases := []struct {
+		name     string
+		query    string
+		code     int
+		limit    int32
+		offset   int32
+		kind     string
+		rows     int
+		hasMore  bool
+		problems map[string]string
+	}{
+		{name: "SmallestPage", query: "page_id=1&page_size=5", code: http.StatusOK, limit: 6, offset: 0, rows: 5},
+		{name: "LargestPage", query: "page_id=3&page_size=50", code: http.StatusOK, limit: 51, offset: 100, rows: 51, hasMore: true},
+		{name: "EmptyPage", query: "page_id=2&page_size=10", code: http.StatusOK, limit: 11, offset: 10, rows: 0},
+		{name: "SavingsOnly", query: "page_id=1&page_size=5&account_type=savings", code: http.StatusOK, limit: 6, offset: 0, kind: models.AccountSavings, rows: 2},
+		{name: "UnknownAccountType", query: "page_id=1&page_size=5&account_type=credit", code: http.StatusBadRequest, problems: map[string]string{"account_type": "must be one of checking, savings"}},
+		{name: "PageIDZero", query: "page_id=0&page_size=5", code: http.StatusBadRequest, problems: map[string]string{"page_id": "is required"}},
+		{name: "PageIDNegative", query: "page_id=-1&page_size=5", code: http.StatusBadRequest, problems: map[string]string{"page_id": "must be at least 1"}},
+		{name: "PageSizeTooSmall", query: "page_id=1&page_size=4", code: http.StatusBadRequest, problems: map[string]string{"page_size": "must be at least 5"}},
+		{name: "PageSizeTooLarge", query: "page_id=1&page_size=51", code: http.StatusBadRequest, problems: map[string]string{"page_size": "must be at most 50"}},
+		{name: "Missing", query: "", code: http.StatusBadRequest, problems: map[string]string{"page_id": "is required", "page_size": "is required"}},
+		{name: "NotANumber", query: "page_id=one&page_size=5", code: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			if tc.code == http.StatusOK {
+				accounts := make([]models.Account, tc.rows)
+				for i := range accounts {
+					accounts[i] = randomAccount(user.UserName)
+				}
+				service.EXPECT().
+					ListAccounts(gomock.Any(), models.ListAccountsParams{Owner: user.UserName, Type: tc.kind, Limit: tc.limit, Offset: tc.offset}).
+					Times(1).
+					Return(accounts, nil)
+			} else {
+				service.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			server, err := NewHandler(testConfig, service)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/sb/api/v1/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.H.GetTokenMaker(), authorizationTypeBearer, user.UserName, time.Minute)
+			server.H.GetGin().ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+
+			var body pageBody
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			if tc.code != http.StatusOK {
+				if tc.problems != nil {
+					require.Equal(t, tc.problems, body.Fields)
+				}
+				return
+			}
+
+			require.NotNil(t, body.Data)
+			require.Len(t, body.Data, min(tc.rows, int(tc.limit-1)))
+			require.Equal(t, tc.limit-1, body.PageSize)
+			require.Equal(t, tc.hasMore, body.HasMore)
+		})
+	}
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser()
+	other, _ := randomUser()
+	account := randomAccount(user.UserName)
+
+	testCases := []struct {
+		name       string
+		query      string
+		username   string
+		buildStubs func(provider *mockedproviders.MockServiceProvider)
+		code       int
+	}{
+		{
+			name:     "OK",
+			query:    "account_id=" + strconv.FormatInt(account.ID, 10) + "&page_id=2&page_size=5",
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				provider.EXPECT().
+					ListTransactions(gomock.Any(), account.ID, account.ID, int64(6), int64(5)).
+					Times(1).
+					Return([]models.Transaction{{ID: 1, FromAccountID: account.ID}}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NOT OWNER",
+			query:    "account_id=" + strconv.FormatInt(account.ID, 10) + "&page_id=1&page_size=5",
+			username: other.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				provider.EXPECT().ListTransactions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "NO ACCOUNT ID",
+			query:    "page_id=1&page_size=5",
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "PAGE SIZE TOO LARGE",
+			query:    "account_id=" + strconv.FormatInt(account.ID, 10) + "&page_id=1&page_size=51",
+			username: user.UserName,
+			buildStubs: func(provider *mockedproviders.MockServiceProvider) {
+				provider.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server, err := NewHandler(testConfig, service)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/sb/api/v1/transfers?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.H.GetTokenMaker(), authorizationTypeBearer, tc.username, time.Minute)
+			server.H.GetGin().ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+
+			if tc.code == http.StatusOK {
+				var body pageBody
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body.Data, 1)
+				require.Equal(t, int32(2), body.PageID)
+				require.False(t, body.HasMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// kinds of entity in the audit log
+const (
+	EntityUser     = "user"
+	EntityAccount  = "account"
+	EntityTransfer = "transfer"
+	EntityCurrency = "currency"
+)
+
+// actions recorded in the audit log
+const (
+	ActionUpdateUser         = "user.update"
+	ActionChangePassword     = "user.change_password"
+	ActionSetOverdraftLimit  = "account.set_overdraft_limit"
+	ActionUpdateAccount      = "account.update"
+	ActionBulkCreateAccounts = "account.bulk_create" // one record for the whole import, its entity id is empty
+	ActionReverseTransfer    = "transfer.reverse"
+	ActionReadTransfer       = "transfer.read" // a banker looking at a transfer of someone else
+	ActionAddCurrency        = "currency.add"
+	ActionDisableCurrency    = "currency.disable"
+)
+
+// writeTimeout bounds the write of a record, which outlives the request it belongs to
+const writeTimeout = 5 * time.Second
+
+// Writer stores audit logs
+type Writer interface {
+	CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error)
+}
+
+// Recorder writes to the audit log. It never fails the operation being recorded: a record that
+// cannot be written is logged and counted in audit_logs_dropped_total.
+type Recorder struct {
+	writer  Writer
+	dropped prometheus.Counter
+}
+
+// NewRecorder creates a recorder writing to writer, with its metric registered with registerer
+func NewRecorder(writer Writer, registerer prometheus.Registerer) *Recorder {
+	r := &Recorder{
+		writer: writer,
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "audit_logs_dropped_total",
+			Help: "Number of audit log records that could not be written.",
+		}),
+	}
+
+	registerer.MustRegister(r.dropped)
+	return r
+}
+
+// Record notes that actor did action to an entity, with snapshots of it before and after. Proto
+// messages are stored as their JSON form, anything else with encoding/json; nil stores null.
+// Recording on a nil Recorder does nothing.
+func (r *Recorder) Record(ctx context.Context, actor, action, entity, entityID string, before, after any) {
+	if r == nil {
+		return
+	}
+
+	logger := log.Ctx(ctx).With().
+		Str("actor", actor).
+		Str("action", action).
+		Str("entity", entity).
+		Str("entity_id", entityID).
+		Logger()
+
+	arg := models.CreateAuditLogParams{
+		Actor:     actor,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		RequestID: requestid.FromContext(ctx),
+	}
+
+	var err error
+	if arg.Before, err = snapshot(before); err != nil {
+		logger.Error().Err(err).Msg("failed to encode audit log snapshot")
+	}
+	if arg.After, err = snapshot(after); err != nil {
+		logger.Error().Err(err).Msg("failed to encode audit log snapshot")
+	}
+
+	// the operation is done by now, a caller hanging up must not lose its record
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+
+	if _, err := r.writer.CreateAuditLog(ctx, arg); err != nil {
+		r.dropped.Inc()
+		logger.Error().Err(err).Msg("failed to write audit log")
+	}
+}
+
+// snapshot encodes v for the audit log, a snapshot that cannot be encoded is stored as null
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	if message, ok := v.(proto.Message); ok {
+		return protojson.Marshal(message)
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+type fakeWriter struct {
+	logs []models.CreateAuditLogParams
+	err  error
+}
+
+func (w *fakeWriter) CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error) {
+	if err := ctx.Err(); err != nil {
+		return models.AuditLog{}, err
+	}
+	if w.err != nil {
+		return models.AuditLog{}, w.err
+	}
+
+	w.logs = append(w.logs, arg)
+	return models.AuditLog{Actor: arg.Actor, Action: arg.Action}, nil
+}
+
+func scrape(t *testing.T, registry *prometheus.Registry) string {
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestRecord(t *testing.T) {
+	writer := &fakeWriter{}
+	recorder := NewRecorder(writer, prometheus.NewRegistry())
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	before := &pb.User{Username: "ada", FullName: "Ada Lovelace"}
+	after := &pb.User{Username: "ada", FullName: "Ada King"}
+	recorder.Record(ctx, "ada", ActionUpdateUser, EntityUser, "ada", before, after)
+
+	require.Len(t, writer.logs, 1)
+	log := writer.logs[0]
+	require.Equal(t, "ada", log.Actor)
+	require.Equal(t, ActionUpdateUser, log.Action)
+	require.Equal(t, EntityUser, log.Entity)
+	require.Equal(t, "ada", log.EntityID)
+	require.Equal(t, "req-1", log.RequestID)
+	require.JSONEq(t, `{"username":"ada","fullName":"Ada Lovelace"}`, string(log.Before))
+	require.JSONEq(t, `{"username":"ada","fullName":"Ada King"}`, string(log.After))
+}
+
+func TestRecordSnapshots(t *testing.T) {
+	writer := &fakeWriter{}
+	recorder := NewRecorder(writer, prometheus.NewRegistry())
+
+	recorder.Record(context.Background(), "bob", ActionReadTransfer, EntityTransfer, "7", nil, map[string]int64{"id": 7})
+
+	require.Len(t, writer.logs, 1)
+	require.Nil(t, writer.logs[0].Before)
+	require.JSONEq(t, `{"id":7}`, string(writer.logs[0].After))
+}
+
+func TestRecordOutlivesRequest(t *testing.T) {
+	writer := &fakeWriter{}
+	recorder := NewRecorder(writer, prometheus.NewRegistry())
+
+	// the caller went away after the operation was done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.Record(ctx, "bob", ActionSetOverdraftLimit, EntityAccount, "7", nil, nil)
+
+	require.Len(t, writer.logs, 1)
+}
+
+func TestRecordDropped(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewRecorder(&fakeWriter{err: errors.New("connection refused")}, registry)
+
+	recorder.Record(context.Background(), "bob", ActionReverseTransfer, EntityTransfer, "7", nil, nil)
+	recorder.Record(context.Background(), "bob", ActionReverseTransfer, EntityTransfer, "8", nil, nil)
+
+	require.Contains(t, scrape(t, registry), "audit_logs_dropped_total 2")
+}
+
+func TestRecordNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	require.NotPanics(t, func() {
+		recorder.Record(context.Background(), "bob", ActionReadTransfer, EntityTransfer, "7", nil, nil)
+	})
+}
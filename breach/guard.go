@@ -0,0 +1,67 @@
+package breach
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrBreached is returned for a password seen in more known breaches than the guard allows
+var ErrBreached = errors.New("has appeared in known data breaches, choose a different password")
+
+// Checker counts how many times a password appears in known breaches
+type Checker interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// Guard refuses passwords seen more than threshold times. It fails open: when the checker
+// cannot answer the password is let through, as an outage of a third party API must not stop
+// people from signing up. Neither the password nor its hash is ever logged.
+type Guard struct {
+	checker     Checker
+	threshold   int
+	rejected    prometheus.Counter
+	unavailable prometheus.Counter
+}
+
+// NewGuard creates a guard asking checker, with its metrics registered with registerer
+func NewGuard(checker Checker, threshold int, registerer prometheus.Registerer) *Guard {
+	g := &Guard{
+		checker:   checker,
+		threshold: threshold,
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "breached_passwords_rejected_total",
+			Help: "Number of new passwords refused for appearing in known data breaches.",
+		}),
+		unavailable: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "breached_password_checks_skipped_total",
+			Help: "Number of new passwords let through because the breach check could not be made.",
+		}),
+	}
+
+	registerer.MustRegister(g.rejected, g.unavailable)
+	return g
+}
+
+// Check returns ErrBreached when password was seen more than the threshold. Checking with a nil
+// Guard lets every password through.
+func (g *Guard) Check(ctx context.Context, password string) error {
+	if g == nil {
+		return nil
+	}
+
+	count, err := g.checker.Count(ctx, password)
+	if err != nil {
+		g.unavailable.Inc()
+		log.Ctx(ctx).Warn().Err(err).Msg("breached password check failed, letting the password through")
+		return nil
+	}
+
+	if count > g.threshold {
+		g.rejected.Inc()
+		return ErrBreached
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package breach
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChecker struct {
+	counts map[string]int
+	err    error
+}
+
+func (c fakeChecker) Count(ctx context.Context, password string) (int, error) {
+	return c.counts[password], c.err
+}
+
+func TestGuard(t *testing.T) {
+	guard := NewGuard(fakeChecker{counts: map[string]int{"password": 100, "rare": 3}}, 5, prometheus.NewRegistry())
+
+	require.ErrorIs(t, guard.Check(context.Background(), "password"), ErrBreached)
+	require.NoError(t, guard.Check(context.Background(), "rare"))
+	require.NoError(t, guard.Check(context.Background(), "unseen"))
+	require.Equal(t, 1.0, testutil.ToFloat64(guard.rejected))
+}
+
+func TestGuardFailsOpen(t *testing.T) {
+	guard := NewGuard(fakeChecker{err: errors.New("connection refused")}, 0, prometheus.NewRegistry())
+
+	require.NoError(t, guard.Check(context.Background(), "password"))
+	require.Equal(t, 1.0, testutil.ToFloat64(guard.unavailable))
+	require.Zero(t, testutil.ToFloat64(guard.rejected))
+}
+
+func TestNilGuard(t *testing.T) {
+	var guard *Guard
+	require.NoError(t, guard.Check(context.Background(), "password"))
+}
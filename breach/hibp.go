@@ -0,0 +1,149 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEndpoint is the Have I Been Pwned range API
+	DefaultEndpoint = "https://api.pwnedpasswords.com"
+	// prefixLength is how many hex characters of the hash are sent, the rest never leaves the process
+	prefixLength = 5
+	// maxCachedPrefixes bounds the cache, there are about a million prefixes
+	maxCachedPrefixes = 10000
+)
+
+// HIBPClient counts breaches through the Have I Been Pwned range API. Only the first five
+// characters of the password's SHA-1 hash are sent, the API answers with the suffixes of every
+// breached hash sharing them and the client looks for the rest of the hash among those.
+// Answers are kept for cacheTTL.
+type HIBPClient struct {
+	endpoint   string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedRange // by prefix
+}
+
+type cachedRange struct {
+	counts    map[string]int // by hash suffix
+	expiresAt time.Time
+}
+
+func NewHIBPClient(endpoint string, httpClient *http.Client, cacheTTL time.Duration) *HIBPClient {
+	return &HIBPClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+		cacheTTL:   cacheTTL,
+		cache:      make(map[string]cachedRange),
+	}
+}
+
+func (c *HIBPClient) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	counts, err := c.rangeOf(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return counts[suffix], nil
+}
+
+// rangeOf returns the breach counts of the hashes starting with prefix, from the cache when
+// it has them
+func (c *HIBPClient) rangeOf(ctx context.Context, prefix string) (map[string]int, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[prefix]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.counts, nil
+	}
+
+	counts, err := c.fetch(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxCachedPrefixes {
+		c.evict()
+	}
+	c.cache[prefix] = cachedRange{counts: counts, expiresAt: time.Now().Add(c.cacheTTL)}
+	return counts, nil
+}
+
+// evict drops the expired ranges, or any one range when none has expired. c.mu must be held.
+func (c *HIBPClient) evict() {
+	now := time.Now()
+	for prefix, cached := range c.cache {
+		if now.After(cached.expiresAt) {
+			delete(c.cache, prefix)
+		}
+	}
+	if len(c.cache) < maxCachedPrefixes {
+		return
+	}
+	for prefix := range c.cache {
+		delete(c.cache, prefix)
+		return
+	}
+}
+
+func (c *HIBPClient) fetch(ctx context.Context, prefix string) (map[string]int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/range/"+prefix, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create breach range request: %w", err)
+	}
+	// padding hides the real size of the answer from anyone watching the connection
+	req.Header.Set("Add-Padding", "true")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		// the url.Error would carry the prefix into the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("cannot get breach range: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("breach range API answered %s", res.Status)
+	}
+
+	// each line is a hash suffix and how many times it was seen, e.g. 0018A45C4D1DEF81644B54AB7F969B88D65:10
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		suffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n == 0 { // padding lines have a count of 0
+			continue
+		}
+		counts[strings.ToUpper(suffix)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read breach range: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,64 @@
+package breach
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestHIBPClient(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "/range/5BAA6", r.URL.Path)
+		require.Equal(t, "true", r.Header.Get("Add-Padding"))
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:10\r\n%s:9659365\r\nFFFFF45C4D1DEF81644B54AB7F969B88D65:0\r\n", passwordSuffix)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewHIBPClient(server.URL+"/", server.Client(), time.Minute)
+
+	count, err := client.Count(context.Background(), "password")
+	require.NoError(t, err)
+	require.Equal(t, 9659365, count)
+
+	// the range is cached, so a password sharing the prefix makes no request
+	_, err = client.Count(context.Background(), "password")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestHIBPClientNotBreached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:10\r\n")
+	}))
+	t.Cleanup(server.Close)
+
+	count, err := NewHIBPClient(server.URL, server.Client(), time.Minute).Count(context.Background(), "correct horse battery staple")
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestHIBPClientUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewHIBPClient(server.URL, server.Client(), time.Minute).Count(context.Background(), "password")
+	require.Error(t, err)
+
+	server.Close()
+	_, err = NewHIBPClient(server.URL, server.Client(), time.Minute).Count(context.Background(), "password")
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "5BAA6")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/zde37/Swift_Bank/config"
+	"github.com/zde37/Swift_Bank/database"
+)
+
+const usage = `usage: swift-bank [command]
+
+commands:
+  all                    run the API and the worker in one process, the default
+  api                    run the gRPC server and the HTTP gateway
+  worker                 run the task processor, the scheduler and the outbox relay
+  migrate up             apply the migrations the database is missing
+  migrate down [steps]   undo the last steps migrations, 1 by default
+  migrate version        print the version of the last migration applied
+`
+
+// errHelp asks for the usage
+var errHelp = errors.New("help requested")
+
+// mode is what one process runs, so the API and the worker can be scaled apart
+type mode struct {
+	name   string
+	api    bool // the gRPC server and its HTTP gateway
+	worker bool // the task processor, the scheduler and the outbox relay
+}
+
+var modes = map[string]mode{
+	"all":    {name: "all", api: true, worker: true},
+	"api":    {name: "api", api: true},
+	"worker": {name: "worker", worker: true},
+}
+
+// command is what the arguments of the process ask for, a mode to run or a migration
+type command struct {
+	mode    mode
+	migrate []string // the arguments after migrate, nil when not migrating
+}
+
+func parseCommand(args []string) (command, error) {
+	if len(args) == 0 {
+		return command{mode: modes["all"]}, nil
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		return command{}, errHelp
+	case "migrate":
+		if err := validateMigrateArgs(args[1:]); err != nil {
+			return command{}, err
+		}
+		return command{migrate: args[1:]}, nil
+	}
+
+	m, ok := modes[args[0]]
+	if !ok {
+		return command{}, fmt.Errorf("unknown command %q", args[0])
+	}
+	if len(args) > 1 {
+		return command{}, fmt.Errorf("%s takes no arguments", args[0])
+	}
+
+	return command{mode: m}, nil
+}
+
+func validateMigrateArgs(args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate needs up, down or version")
+	}
+
+	switch args[0] {
+	case "up", "version":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate %s takes no arguments", args[0])
+		}
+	case "down":
+		if _, err := migrateDownSteps(args[1:]); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
+
+// migrateDownSteps is how many migrations down undoes. One unless told otherwise, undoing every
+// migration drops every table.
+func migrateDownSteps(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return 1, nil
+	case 1:
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			return 0, fmt.Errorf("migrate down steps must be a positive number, got %q", args[0])
+		}
+		return steps, nil
+	}
+
+	return 0, errors.New("migrate down takes at most one argument")
+}
+
+// runMigrateCommand runs migrate with args checked by validateMigrateArgs, the version is
+// written to out. Unlike starting the API it migrates even with SKIP_MIGRATIONS set, running it
+// is asking for it.
+func runMigrateCommand(config config.Config, args []string, out io.Writer) error {
+	switch args[0] {
+	case "up":
+		return database.Migrate(config.MigrationURL, config.Dsn, config.MigrationForceDirty)
+	case "down":
+		steps, err := migrateDownSteps(args[1:])
+		if err != nil {
+			return err
+		}
+		return database.MigrateDown(config.MigrationURL, config.Dsn, steps)
+	}
+
+	version, dirty, err := database.MigrationVersion(config.MigrationURL, config.Dsn)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version == database.NilVersion:
+		_, err = fmt.Fprintln(out, "no migration applied")
+	case dirty:
+		_, err = fmt.Fprintf(out, "%d (dirty)\n", version)
+	default:
+		_, err = fmt.Fprintln(out, version)
+	}
+	return err
+}
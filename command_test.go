@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/config"
+)
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		args    []string
+		mode    string
+		migrate []string
+		err     string
+	}{
+		{args: nil, mode: "all"},
+		{args: []string{"all"}, mode: "all"},
+		{args: []string{"api"}, mode: "api"},
+		{args: []string{"worker"}, mode: "worker"},
+		{args: []string{"migrate", "up"}, migrate: []string{"up"}},
+		{args: []string{"migrate", "down"}, migrate: []string{"down"}},
+		{args: []string{"migrate", "down", "3"}, migrate: []string{"down", "3"}},
+		{args: []string{"migrate", "version"}, migrate: []string{"version"}},
+		{args: []string{"serve"}, err: `unknown command "serve"`},
+		{args: []string{"api", "worker"}, err: "api takes no arguments"},
+		{args: []string{"migrate"}, err: "migrate needs up, down or version"},
+		{args: []string{"migrate", "sideways"}, err: `unknown migrate command "sideways"`},
+		{args: []string{"migrate", "up", "2"}, err: "migrate up takes no arguments"},
+		{args: []string{"migrate", "down", "0"}, err: `migrate down steps must be a positive number, got "0"`},
+		{args: []string{"migrate", "down", "all"}, err: `migrate down steps must be a positive number, got "all"`},
+		{args: []string{"migrate", "down", "1", "2"}, err: "migrate down takes at most one argument"},
+	}
+
+	for _, tc := range testCases {
+		command, err := parseCommand(tc.args)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, tc.args)
+			continue
+		}
+
+		require.NoError(t, err, tc.args)
+		require.Equal(t, tc.mode, command.mode.name, tc.args)
+		require.Equal(t, tc.migrate, command.migrate, tc.args)
+	}
+
+	_, err := parseCommand([]string{"--help"})
+	require.ErrorIs(t, err, errHelp)
+}
+
+func TestModes(t *testing.T) {
+	require.True(t, modes["all"].api && modes["all"].worker)
+	require.True(t, modes["api"].api && !modes["api"].worker)
+	require.True(t, !modes["worker"].api && modes["worker"].worker)
+}
+
+func TestRunMigrateCommand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1_first.up.sql", "1_first.down.sql", "2_second.up.sql", "2_second.down.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+
+	// the stub database starts empty every time it is opened
+	c := config.Config{MigrationURL: "file://" + dir, Dsn: "stub://"}
+
+	var out bytes.Buffer
+	require.NoError(t, runMigrateCommand(c, []string{"version"}, &out))
+	require.Equal(t, "no migration applied\n", out.String())
+
+	require.NoError(t, runMigrateCommand(c, []string{"up"}, &out))
+
+	// nothing to undo in an empty database
+	require.Error(t, runMigrateCommand(c, []string{"down"}, &out))
+
+	c.MigrationURL = "file://" + filepath.Join(dir, "missing")
+	require.ErrorContains(t, runMigrateCommand(c, []string{"up"}, &out), "cannot open migrations")
+}
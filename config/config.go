@@ -1,40 +1,170 @@
 package config
 
 import (
+	"errors"
+	"reflect"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Dsn                  string        `mapstructure:"DSN"`
-	HttpServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GrpcServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	JwtSecretKey         string        `mapstructure:"JWT_SECRET_KEY"`
-	RefreshJwtSecretKey  string        `mapstructure:"REFRESH_JWT_SECRET_KEY"`
-	MigrationURL         string        `mapstructure:"MIGRATION_URL"`
-	Environment          string        `mapstructure:"ENVIRONMENT"`
-	EmailAddress         string        `mapstructure:"EMAIL_ADDRESS"`
-	EmailSender          string        `mapstructure:"EMAIL_SENDER"`
-	EmailPassword        string        `mapstructure:"EMAIL_PASSWORD"`
+	Dsn                      string        `mapstructure:"DSN"`
+	DsnFile                  string        `mapstructure:"DSN_FILE"`                // a file holding the DSN, for mounted secrets; set it or DSN
+	ReplicaDsn               string        `mapstructure:"REPLICA_DSN"`             // read replica for list and statement queries, every query goes to DSN when empty
+	ReadYourWritesWindow     time.Duration `mapstructure:"READ_YOUR_WRITES_WINDOW"` // data written by an instance is read from the primary for this long, so the replica can catch up
+	DBMaxConns               int32         `mapstructure:"DB_MAX_CONNS"`            // per pool, the DSN or pgxpool default when 0
+	DBMinConns               int32         `mapstructure:"DB_MIN_CONNS"`            // kept open even when idle
+	DBMaxConnLifetime        time.Duration `mapstructure:"DB_MAX_CONN_LIFETIME"`
+	DBMaxConnIdleTime        time.Duration `mapstructure:"DB_MAX_CONN_IDLE_TIME"`
+	DBHealthCheckPeriod      time.Duration `mapstructure:"DB_HEALTH_CHECK_PERIOD"`  // how often idle connections are checked
+	TransferAdvisoryLocks    bool          `mapstructure:"TRANSFER_ADVISORY_LOCKS"` // transfers queue on advisory locks of their accounts instead of contending for the rows
+	HttpServerAddress        string        `mapstructure:"HTTP_SERVER_ADDRESS"`     // host:port, or unix:///path/to.sock for a unix socket
+	HttpRedirectAddress      string        `mapstructure:"HTTP_REDIRECT_ADDRESS"`   // redirects to the gateway when it serves HTTPS, off when empty
+	GrpcServerAddress        string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	UnixSocketMode           string        `mapstructure:"UNIX_SOCKET_MODE"` // octal permissions of the unix:// server sockets, 0660 when empty
+	GrpcReflection           bool          `mapstructure:"GRPC_REFLECTION"`  // lets clients like evans list and describe the RPCs
+	PprofAddress             string        `mapstructure:"PPROF_ADDRESS"`    // loopback address net/http/pprof is served on, e.g. 127.0.0.1:6060, off when empty
+	GatewayMode              string        `mapstructure:"GATEWAY_MODE"`     // inprocess calls the server directly, proxy dials GRPC_SERVER_ADDRESS; inprocess when empty
+	MetricsServerAddress     string        `mapstructure:"METRICS_SERVER_ADDRESS"`
+	MetricsToken             string        `mapstructure:"METRICS_TOKEN"`    // bearer token prometheus must send for /metrics, open when empty
+	SwaggerUsername          string        `mapstructure:"SWAGGER_USERNAME"` // basic auth of /swagger/ outside development, open when empty
+	SwaggerPassword          string        `mapstructure:"SWAGGER_PASSWORD"`
+	TLSCertFile              string        `mapstructure:"TLS_CERT_FILE"` // plaintext is served when empty
+	TLSKeyFile               string        `mapstructure:"TLS_KEY_FILE"`
+	RedisAddress             string        `mapstructure:"REDIS_ADDRESS"`
+	RedisPassword            string        `mapstructure:"REDIS_PASSWORD"`
+	RedisPasswordFile        string        `mapstructure:"REDIS_PASSWORD_FILE"`
+	RedisDB                  int           `mapstructure:"REDIS_DB"`
+	RedisTLSEnabled          bool          `mapstructure:"REDIS_TLS_ENABLED"`
+	RedisCAFile              string        `mapstructure:"REDIS_CA_FILE"`            // the system roots verify the server when empty
+	RedisSentinelMaster      string        `mapstructure:"REDIS_SENTINEL_MASTER"`    // connect through sentinel, REDIS_ADDRESS is not used
+	RedisSentinelAddresses   string        `mapstructure:"REDIS_SENTINEL_ADDRESSES"` // comma separated
+	EntityCacheTTL           time.Duration `mapstructure:"ENTITY_CACHE_TTL"`         // how long users and accounts are cached in redis, 0 turns the cache off
+	TokenSymmetricKey        string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenSymmetricKeyFile    string        `mapstructure:"TOKEN_SYMMETRIC_KEY_FILE"`
+	AccessTokenDuration      time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration     time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	JwtSecretKey             string        `mapstructure:"JWT_SECRET_KEY"`
+	RefreshJwtSecretKey      string        `mapstructure:"REFRESH_JWT_SECRET_KEY"`
+	PasswordMinLength        int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordCharClasses      string        `mapstructure:"PASSWORD_CHAR_CLASSES"` // e.g. "lower,upper,digit,symbol", none are required when empty
+	PasswordRejectUserInfo   bool          `mapstructure:"PASSWORD_REJECT_USER_INFO"`
+	PasswordRejectCommon     bool          `mapstructure:"PASSWORD_REJECT_COMMON"`
+	PasswordBreachCheck      bool          `mapstructure:"PASSWORD_BREACH_CHECK"` // refuse new passwords found in known breaches, asks the HIBP range API
+	PasswordBreachURL        string        `mapstructure:"PASSWORD_BREACH_URL"`
+	PasswordBreachThreshold  int           `mapstructure:"PASSWORD_BREACH_THRESHOLD"` // passwords seen more times than this are refused
+	PasswordBreachTimeout    time.Duration `mapstructure:"PASSWORD_BREACH_TIMEOUT"`
+	EmailChangeGracePeriod   time.Duration `mapstructure:"EMAIL_CHANGE_GRACE_PERIOD"` // recovery mail goes to the old address this long after an unverified change
+	RequireVerifiedEmail     bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`    // unverified users can neither log in nor move money
+	MigrationURL             string        `mapstructure:"MIGRATION_URL"`
+	SkipMigrations           bool          `mapstructure:"SKIP_MIGRATIONS"`       // leave migrating to a separate job
+	MigrationForceDirty      bool          `mapstructure:"MIGRATION_FORCE_DIRTY"` // run a migration that failed halfway again instead of refusing to start
+	Environment              string        `mapstructure:"ENVIRONMENT"`
+	EmailAddress             string        `mapstructure:"EMAIL_ADDRESS"`
+	EmailSender              string        `mapstructure:"EMAIL_SENDER"`
+	EmailPassword            string        `mapstructure:"EMAIL_PASSWORD"`
+	EmailPasswordFile        string        `mapstructure:"EMAIL_PASSWORD_FILE"`
+	MailProvider             string        `mapstructure:"MAIL_PROVIDER"` // gmail, smtp, sendgrid or ses
+	SMTPHost                 string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                 int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername             string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword             string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPUseTLS               bool          `mapstructure:"SMTP_USE_TLS"` // implicit tls, STARTTLS is used otherwise when offered
+	SMTPTimeout              time.Duration `mapstructure:"SMTP_TIMEOUT"`
+	SendGridAPIKey           string        `mapstructure:"SENDGRID_API_KEY"`
+	SESRegion                string        `mapstructure:"SES_REGION"`
+	SESAccessKeyID           string        `mapstructure:"SES_ACCESS_KEY_ID"`
+	SESSecretAccessKey       string        `mapstructure:"SES_SECRET_ACCESS_KEY"`
+	MaxAttachmentSize        int64         `mapstructure:"MAX_ATTACHMENT_SIZE"` // bytes, 0 for no limit
+	ExchangeRateMaxAge       time.Duration `mapstructure:"EXCHANGE_RATE_MAX_AGE"`
+	ExchangeRateURL          string        `mapstructure:"EXCHANGE_RATE_URL"`
+	ExchangeRateRefresh      time.Duration `mapstructure:"EXCHANGE_RATE_REFRESH_INTERVAL"`
+	WebhookMaxRetry          int           `mapstructure:"WEBHOOK_MAX_RETRY"`
+	LargeTransferThresholds  string        `mapstructure:"LARGE_TRANSFER_THRESHOLDS"`      // e.g. "USD:10000,EUR:9000"
+	SavingsTransferLimit     int           `mapstructure:"SAVINGS_MONTHLY_TRANSFER_LIMIT"` // outgoing transfers a savings account may make a month, 0 for no limit
+	InterestRates            string        `mapstructure:"INTEREST_RATES"`                 // yearly percent savings earn, e.g. "USD:2.5,EUR:1.75", none when empty
+	TwilioAccountSID         string        `mapstructure:"TWILIO_ACCOUNT_SID"`             // SMS notifications are off when empty
+	TwilioAuthToken          string        `mapstructure:"TWILIO_AUTH_TOKEN"`
+	TwilioFromNumber         string        `mapstructure:"TWILIO_FROM_NUMBER"`
+	PurgeSessions            bool          `mapstructure:"PURGE_SESSIONS"`
+	PurgeVerifyEmails        bool          `mapstructure:"PURGE_VERIFY_EMAILS"`
+	PurgeIdempotencyKeys     bool          `mapstructure:"PURGE_IDEMPOTENCY_KEYS"`
+	DeletedAccountRetention  int           `mapstructure:"DELETED_ACCOUNT_RETENTION_YEARS"` // in years, 0 never purges deleted accounts
+	QueueCriticalWeight      int           `mapstructure:"QUEUE_CRITICAL_WEIGHT"`
+	QueueDefaultWeight       int           `mapstructure:"QUEUE_DEFAULT_WEIGHT"`
+	QueueLowWeight           int           `mapstructure:"QUEUE_LOW_WEIGHT"`
+	DrainTimeout             time.Duration `mapstructure:"DRAIN_TIMEOUT"` // how long servers and workers get to finish on shutdown
+	OutboxRelayInterval      time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	HealthCheckInterval      time.Duration `mapstructure:"HEALTH_CHECK_INTERVAL"`
+	CurrencyRefreshInterval  time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	HealthCheckTimeout       time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"` // per check, a dependency slower than this counts as down
+	OTLPEndpoint             string        `mapstructure:"OTLP_ENDPOINT"`        // host:port of the trace collector, tracing is off when empty
+	OTLPInsecure             bool          `mapstructure:"OTLP_INSECURE"`
+	TraceSampleRatio         float64       `mapstructure:"TRACE_SAMPLE_RATIO"`     // fraction of requests traced
+	RateLimitDefault         string        `mapstructure:"RATE_LIMIT_DEFAULT"`     // requests/period per user or IP, e.g. "60/1m"
+	RateLimits               string        `mapstructure:"RATE_LIMITS"`            // per RPC, e.g. "LoginUser:5/1m,TransferMoney:30/1m"
+	RPCTimeout               time.Duration `mapstructure:"RPC_TIMEOUT"`            // how long an RPC may run, 0 for no limit
+	RPCTimeouts              string        `mapstructure:"RPC_TIMEOUTS"`           // RPCs that need longer, e.g. "GetStatement:25s"
+	GrpcMaxRecvMsgSize       int           `mapstructure:"GRPC_MAX_RECV_MSG_SIZE"` // bytes
+	GrpcMaxSendMsgSize       int           `mapstructure:"GRPC_MAX_SEND_MSG_SIZE"` // bytes
+	GrpcMaxConnectionIdle    time.Duration `mapstructure:"GRPC_MAX_CONNECTION_IDLE"`
+	GrpcKeepaliveTime        time.Duration `mapstructure:"GRPC_KEEPALIVE_TIME"`
+	GrpcKeepaliveTimeout     time.Duration `mapstructure:"GRPC_KEEPALIVE_TIMEOUT"`
+	LegacyPathsSunset        string        `mapstructure:"LEGACY_PATHS_SUNSET"`         // date like 2027-01-01 the old /sb/api/v1 paths are served until, off when empty
+	CORSAllowedOrigins       string        `mapstructure:"CORS_ALLOWED_ORIGINS"`        // e.g. "https://app.swiftbank.com", "*" allows any, CORS is off when empty
+	GrpcMaxConcurrentStreams uint32        `mapstructure:"GRPC_MAX_CONCURRENT_STREAMS"` // per connection
+	BehindProxy              bool          `mapstructure:"BEHIND_PROXY"`                // true when a reverse proxy that sets X-Forwarded-For fronts the servers
+	ExportMaxRows            int           `mapstructure:"EXPORT_MAX_ROWS"`             // larger transaction exports are emailed instead of downloaded, 0 for no limit
+	StorageDir               string        `mapstructure:"STORAGE_DIR"`                 // where generated files like PDF statements are kept
+	StorageBaseURL           string        `mapstructure:"STORAGE_BASE_URL"`            // public URL of the gateway's /v1/files, signed download links point below it
+	StatementURLTTL          time.Duration `mapstructure:"STATEMENT_URL_TTL"`           // how long a statement download link works
+	SlowRequestThreshold     time.Duration `mapstructure:"SLOW_REQUEST_THRESHOLD"`      // requests slower than this are logged at warn level, 0 for never
+	SlowQueryThreshold       time.Duration `mapstructure:"SLOW_QUERY_THRESHOLD"`        // database queries slower than this are logged, 0 for never
+	DBLogLevel               string        `mapstructure:"DB_LOG_LEVEL"`                // debug logs every query, error only the failed ones, off when empty
+	LogLevel                 string        `mapstructure:"LOG_LEVEL"`                   // level logged from at startup, info when empty. SIGUSR1 toggles debug
 }
 
+// LoadConfig reads app.env in path and the environment, a variable set in both is taken from the
+// environment. The file is optional, so a container can be configured by its environment alone.
+// Secrets with a *_FILE variable set are read from that file.
 func LoadConfig(path string) (config Config, err error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+	v := viper.New()
+	v.AddConfigPath(path)
+	v.SetConfigName("app")
+	v.SetConfigType("env")
 
-	viper.AutomaticEnv()
+	// every field is bound to its variable, AutomaticEnv alone only sees the keys the file has
+	for _, key := range envKeys() {
+		if err := v.BindEnv(key); err != nil {
+			return config, err
+		}
+	}
 
-	err = viper.ReadInConfig()
+	err = v.ReadInConfig()
 	if err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, err
+		}
+	}
+
+	if err = v.Unmarshal(&config); err != nil {
 		return config, err
 	}
 
-	err = viper.Unmarshal(&config)
+	err = config.readSecretFiles()
 	return config, err
 }
+
+// envKeys returns the variable of every Config field, from its mapstructure tag
+func envKeys() []string {
+	fields := reflect.VisibleFields(reflect.TypeOf(Config{}))
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if key := field.Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
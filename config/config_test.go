@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	t.Setenv("DSN", "postgresql://root:secret@db:5432/Swift_Bank_DB")
+	t.Setenv("ACCESS_TOKEN_DURATION", "15m")
+	t.Setenv("PURGE_SESSIONS", "true")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("TRACE_SAMPLE_RATIO", "0.25")
+
+	config, err := LoadConfig(t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, "postgresql://root:secret@db:5432/Swift_Bank_DB", config.Dsn)
+	require.Equal(t, 15*time.Minute, config.AccessTokenDuration)
+	require.True(t, config.PurgeSessions)
+	require.Equal(t, 2525, config.SMTPPort)
+	require.Equal(t, 0.25, config.TraceSampleRatio)
+	require.Empty(t, config.RedisAddress)
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	dir := t.TempDir()
+	file := "DSN=postgresql://from-file\nHTTP_SERVER_ADDRESS=0.0.0.0:8080\nDRAIN_TIMEOUT=20s"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte(file), 0o600))
+
+	t.Setenv("DSN", "postgresql://from-env")
+	t.Setenv("DRAIN_TIMEOUT", "5s")
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	require.Equal(t, "postgresql://from-env", config.Dsn)
+	require.Equal(t, 5*time.Second, config.DrainTimeout)
+	require.Equal(t, "0.0.0.0:8080", config.HttpServerAddress)
+}
+
+func TestLoadConfigParseErrors(t *testing.T) {
+	testCases := []struct {
+		key   string
+		value string
+	}{
+		{key: "ACCESS_TOKEN_DURATION", value: "15 minutes"},
+		{key: "PURGE_SESSIONS", value: "maybe"},
+		{key: "SMTP_PORT", value: "smtp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+
+			_, err := LoadConfig(t.TempDir())
+			require.ErrorContains(t, err, "'"+tc.key+"'")
+		})
+	}
+}
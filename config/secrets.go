@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// secret is a value that can be read from a file instead, like a Docker or Kubernetes secret
+type secret struct {
+	name  string
+	value *string
+	file  string
+}
+
+func (c *Config) secretFields() []secret {
+	return []secret{
+		{"DSN", &c.Dsn, c.DsnFile},
+		{"TOKEN_SYMMETRIC_KEY", &c.TokenSymmetricKey, c.TokenSymmetricKeyFile},
+		{"EMAIL_PASSWORD", &c.EmailPassword, c.EmailPasswordFile},
+		{"REDIS_PASSWORD", &c.RedisPassword, c.RedisPasswordFile},
+	}
+}
+
+// readSecretFiles sets every secret with a *_FILE variable from its file. The trailing newline
+// editors and kubectl leave is dropped.
+func (c *Config) readSecretFiles() error {
+	var errs []error
+	for _, s := range c.secretFields() {
+		if s.file == "" {
+			continue
+		}
+		if *s.value != "" {
+			errs = append(errs, fmt.Errorf("%s and %s_FILE are both set, keep one", s.name, s.name))
+			continue
+		}
+
+		content, err := os.ReadFile(s.file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read %s_FILE: %w", s.name, err))
+			continue
+		}
+		*s.value = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Secrets holds the secrets of a loaded config. Those read from files are read again by Reload,
+// so a rotated mounted secret can be picked up without a restart.
+type Secrets struct {
+	mu     sync.RWMutex
+	config Config
+}
+
+// Secrets returns the secrets of c for Reload to read again
+func (c Config) Secrets() *Secrets {
+	return &Secrets{config: c}
+}
+
+// Reload reads the secret files again, and returns the names of the secrets that changed. On an
+// error every secret keeps its value.
+func (s *Secrets) Reload() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reloaded := s.config
+	for _, secret := range reloaded.secretFields() {
+		if secret.file != "" {
+			*secret.value = ""
+		}
+	}
+	if err := reloaded.readSecretFiles(); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	previous := s.config.secretFields()
+	for i, secret := range reloaded.secretFields() {
+		if *secret.value != *previous[i].value {
+			changed = append(changed, secret.name)
+		}
+	}
+
+	s.config = reloaded
+	return changed, nil
+}
+
+func (s *Secrets) Dsn() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.Dsn
+}
+
+func (s *Secrets) TokenSymmetricKey() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.TokenSymmetricKey
+}
+
+func (s *Secrets) RedisPassword() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.RedisPassword
+}
+
+// secretSuffixes end the variables holding credentials, a new one should end in one of them too
+var secretSuffixes = []string{"DSN", "_PASSWORD", "_TOKEN", "_KEY", "_KEY_ID"}
+
+// masked stands in for a secret that is set
+const masked = "****"
+
+// Summary returns the value of every variable, secrets masked, for operators to see what an
+// instance runs with. An unset secret stays empty, so it shows that it is missing.
+func (c Config) Summary() map[string]string {
+	value := reflect.ValueOf(c)
+	summary := make(map[string]string, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+
+		field := fmt.Sprint(value.Field(i).Interface())
+		if field != "" && value.Field(i).Kind() == reflect.String && isSecret(name) {
+			field = masked
+		}
+		summary[name] = field
+	}
+	return summary
+}
+
+func isSecret(name string) bool {
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/listen"
+)
+
+// development is the Environment that relaxes what a local setup has no use for, like mail credentials
+const development = "development"
+
+// tokenKeyLength is the length the PASETO token key must have
+const tokenKeyLength = 32
+
+// Validate checks the config is complete enough to start with. Every problem is reported at once,
+// joined into one error, so they can all be fixed in one pass. Problems are named by the
+// variable to set rather than the field.
+func (c Config) Validate() error {
+	var errs []error
+	problem := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Dsn == "" {
+		problem("DSN is required")
+	}
+	if c.MigrationURL == "" && !c.SkipMigrations {
+		problem("MIGRATION_URL is required unless SKIP_MIGRATIONS is set")
+	}
+	if len(c.TokenSymmetricKey) != tokenKeyLength {
+		problem("TOKEN_SYMMETRIC_KEY must be exactly %d characters, got %d", tokenKeyLength, len(c.TokenSymmetricKey))
+	}
+
+	required := []struct {
+		name, value string
+		unix        bool // may be a unix socket
+	}{
+		{"HTTP_SERVER_ADDRESS", c.HttpServerAddress, true},
+		{"GRPC_SERVER_ADDRESS", c.GrpcServerAddress, true},
+		{"METRICS_SERVER_ADDRESS", c.MetricsServerAddress, false},
+	}
+	for _, address := range required {
+		if address.value == "" {
+			problem("%s is required", address.name)
+		} else if path, ok := listen.UnixPath(address.value); ok && address.unix {
+			if path == "" {
+				problem("%s must have a socket path like unix:///run/swift_bank.sock", address.name)
+			}
+		} else if err := checkHostPort(address.value); err != nil {
+			problem("%s %v", address.name, err)
+		}
+	}
+	if _, err := listen.ParseMode(c.UnixSocketMode); err != nil {
+		problem("UNIX_SOCKET_MODE %v", err)
+	}
+	if _, ok := listen.UnixPath(c.HttpServerAddress); ok && c.HttpRedirectAddress != "" {
+		problem("HTTP_REDIRECT_ADDRESS needs HTTP_SERVER_ADDRESS to be host:port")
+	}
+
+	optional := []struct{ name, value string }{
+		{"HTTP_REDIRECT_ADDRESS", c.HttpRedirectAddress},
+		{"OTLP_ENDPOINT", c.OTLPEndpoint},
+	}
+	for _, address := range optional {
+		if address.value == "" {
+			continue
+		}
+		if err := checkHostPort(address.value); err != nil {
+			problem("%s %v", address.name, err)
+		}
+	}
+
+	switch c.GatewayMode {
+	case "", "inprocess", "proxy":
+	default:
+		problem("GATEWAY_MODE must be inprocess or proxy, got %q", c.GatewayMode)
+	}
+
+	if c.PprofAddress != "" {
+		if err := checkHostPort(c.PprofAddress); err != nil {
+			problem("PPROF_ADDRESS %v", err)
+		} else if !isLoopback(c.PprofAddress) {
+			problem("PPROF_ADDRESS must be a loopback address like 127.0.0.1:6060, got %q", c.PprofAddress)
+		}
+	}
+
+	errs = append(errs, c.validateRedis()...)
+
+	if c.AccessTokenDuration <= 0 {
+		problem("ACCESS_TOKEN_DURATION must be positive")
+	}
+	if c.RefreshTokenDuration < c.AccessTokenDuration {
+		problem("REFRESH_TOKEN_DURATION must be at least ACCESS_TOKEN_DURATION")
+	}
+	errs = append(errs, negativeDurations(c)...)
+
+	if c.SavingsTransferLimit < 0 {
+		problem("SAVINGS_MONTHLY_TRANSFER_LIMIT must not be negative, got %d", c.SavingsTransferLimit)
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		problem("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	if c.SwaggerUsername != "" && c.SwaggerPassword == "" {
+		problem("SWAGGER_PASSWORD is required with SWAGGER_USERNAME")
+	}
+
+	if c.TwilioAccountSID != "" && (c.TwilioAuthToken == "" || c.TwilioFromNumber == "") {
+		problem("TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER are required with TWILIO_ACCOUNT_SID")
+	}
+
+	// the worker always runs and sends mail, a local setup may go without
+	if c.Environment != development {
+		errs = append(errs, c.validateMail()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateMail checks the credentials of the configured mail provider are there. The providers
+// are the mail package's, which cannot be imported here as its tests load the config.
+func (c Config) validateMail() []error {
+	var errs []error
+	require := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required with MAIL_PROVIDER %s", name, c.MailProvider))
+		}
+	}
+
+	require("EMAIL_ADDRESS", c.EmailAddress)
+	switch c.MailProvider {
+	case "", "gmail":
+		require("EMAIL_PASSWORD", c.EmailPassword)
+	case "smtp":
+		require("SMTP_HOST", c.SMTPHost)
+		if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT must be from 1 - 65535, got %d", c.SMTPPort))
+		}
+	case "sendgrid":
+		require("SENDGRID_API_KEY", c.SendGridAPIKey)
+	case "ses":
+		require("SES_REGION", c.SESRegion)
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_PROVIDER must be gmail, smtp, sendgrid or ses, got %q", c.MailProvider))
+	}
+
+	return errs
+}
+
+// validateRedis checks redis can be reached, directly or through sentinel
+func (c Config) validateRedis() []error {
+	var errs []error
+
+	if c.RedisSentinelMaster == "" {
+		if c.RedisAddress == "" {
+			errs = append(errs, errors.New("REDIS_ADDRESS is required"))
+		} else if err := checkHostPort(c.RedisAddress); err != nil {
+			errs = append(errs, fmt.Errorf("REDIS_ADDRESS %v", err))
+		}
+	} else {
+		addresses := 0
+		for _, address := range strings.Split(c.RedisSentinelAddresses, ",") {
+			if address = strings.TrimSpace(address); address == "" {
+				continue
+			}
+			addresses++
+			if err := checkHostPort(address); err != nil {
+				errs = append(errs, fmt.Errorf("REDIS_SENTINEL_ADDRESSES %v", err))
+			}
+		}
+		if addresses == 0 {
+			errs = append(errs, errors.New("REDIS_SENTINEL_ADDRESSES is required with REDIS_SENTINEL_MASTER"))
+		}
+	}
+
+	if c.RedisDB < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_DB must not be negative, got %d", c.RedisDB))
+	}
+	if c.RedisCAFile != "" && !c.RedisTLSEnabled {
+		errs = append(errs, errors.New("REDIS_CA_FILE needs REDIS_TLS_ENABLED"))
+	}
+
+	return errs
+}
+
+// negativeDurations reports every duration set below zero, none of them has a use for one
+func negativeDurations(c Config) []error {
+	var errs []error
+	value := reflect.ValueOf(c)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type != reflect.TypeOf(c.DrainTimeout) {
+			continue
+		}
+		if value.Field(i).Int() < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", field.Tag.Get("mapstructure")))
+		}
+	}
+	return errs
+}
+
+// isLoopback tells whether a host:port address can only be reached from the same machine
+func isLoopback(address string) bool {
+	host, _, _ := net.SplitHostPort(address)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkHostPort accepts addresses like "0.0.0.0:8080" or "redis:6379", the host may be left out
+func checkHostPort(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("must be host:port, got %q", address)
+	}
+
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("has an invalid port in %q", address)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package currencies keeps the currencies table in memory. helpers.ISSupportedCurrency and the
+// money package read what it last loaded, so a currency added in the table is usable without a
+// deploy.
+package currencies
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+// Source is the subset of the service the cache loads the currencies from
+type Source interface {
+	ListCurrencies(ctx context.Context) ([]models.Currency, error)
+}
+
+// Cache loads the currencies from its source. A change made through this process is seen at once
+// when it calls Refresh, other instances see it on their next periodic refresh.
+type Cache struct {
+	source Source
+}
+
+func NewCache(source Source) *Cache {
+	return &Cache{
+		source: source,
+	}
+}
+
+// Refresh loads the currencies again. Every currency is registered with the money package, so
+// balances in a disabled one stay readable, only the enabled ones are supported. A nil cache does
+// nothing, the built in currencies stay supported.
+func (c *Cache) Refresh(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+
+	currencies, err := c.source.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	enabled := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		money.RegisterCurrency(currency.Code, int(currency.MinorUnitExponent))
+		if currency.Enabled {
+			enabled = append(enabled, currency.Code)
+		}
+	}
+	helpers.SetSupportedCurrencies(enabled)
+
+	return nil
+}
+
+// Run refreshes the currencies every interval until ctx is cancelled. A failed refresh keeps the
+// currencies loaded before.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("failed to refresh currencies")
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package currencies
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+type fakeSource struct {
+	currencies []models.Currency
+	err        error
+}
+
+func (s *fakeSource) ListCurrencies(ctx context.Context) ([]models.Currency, error) {
+	return s.currencies, s.err
+}
+
+func TestCacheRefresh(t *testing.T) {
+	t.Cleanup(func() { helpers.SetSupportedCurrencies([]string{helpers.USD, helpers.EUR, helpers.CAD}) })
+
+	source := &fakeSource{currencies: []models.Currency{
+		{Code: helpers.USD, MinorUnitExponent: 2, Enabled: true},
+		{Code: "NGN", MinorUnitExponent: 2, Enabled: true},
+		{Code: helpers.CAD, MinorUnitExponent: 2, Enabled: false},
+	}}
+	cache := NewCache(source)
+
+	require.NoError(t, cache.Refresh(context.Background()))
+	require.True(t, helpers.ISSupportedCurrency("NGN"))
+	require.True(t, helpers.ISSupportedCurrency(helpers.USD))
+	require.False(t, helpers.ISSupportedCurrency(helpers.EUR))
+
+	// a disabled currency takes nothing new, but its balances can still be read
+	require.False(t, helpers.ISSupportedCurrency(helpers.CAD))
+	require.Equal(t, "12.50", money.New(1250, helpers.CAD).String())
+
+	amount, err := money.FromDecimalString("100.25", "NGN")
+	require.NoError(t, err)
+	require.Equal(t, int64(10025), amount.Units)
+
+	// a failed refresh keeps what was loaded
+	source.err = errors.New("database is down")
+	require.Error(t, cache.Refresh(context.Background()))
+	require.True(t, helpers.ISSupportedCurrency("NGN"))
+}
+
+func TestNilCacheRefresh(t *testing.T) {
+	var cache *Cache
+	require.NoError(t, cache.Refresh(context.Background()))
+	require.True(t, helpers.ISSupportedCurrency(helpers.USD))
+}
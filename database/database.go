@@ -2,30 +2,58 @@ package database
 
 import (
 	"context"
-	"sync"
 
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type PostgresClient struct {
-	Pool *pgxpool.Pool
-	once sync.Once
+// DB is a database the service talks to. Client is the real one, tests can substitute a fake.
+type DB interface {
+	Ping(ctx context.Context) error
+	Pool() *pgxpool.Pool
+	Close()
 }
 
-func (p *PostgresClient) NewPostgresClient(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
-	var err error
-	p.once.Do(func() {
-		p.Pool, err = pgxpool.New(ctx, dsn)
-		if err != nil {
-			return
-		}
-	})
+// Client owns the connection pool to one database, a primary or a read replica
+type Client struct {
+	pool *pgxpool.Pool
+}
+
+var _ DB = (*Client)(nil)
+
+// NewPostgresClient creates the pool of the database at dsn, tuned by poolConfig. Every query is
+// traced by otelpgx and by any extra tracers. Connections are made in the background or when
+// first used, so a database that is down doesn't fail it, Ping does.
+func NewPostgresClient(ctx context.Context, dsn string, poolConfig PoolConfig, extraTracers ...pgx.QueryTracer) (*Client, error) {
+	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
 	}
-	return p.Pool, err
+	if err := poolConfig.apply(config); err != nil {
+		return nil, err
+	}
+
+	// queries become spans of the request that ran them, using the global tracer provider
+	config.ConnConfig.Tracer = append(tracers{otelpgx.NewTracer()}, extraTracers...)
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{pool: pool}, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
+func (c *Client) Pool() *pgxpool.Pool {
+	return c.pool
 }
 
-func (p *PostgresClient) PingDB(ctx context.Context) error {
-	return p.Pool.Ping(ctx)
+// Close closes the connections of the pool, waiting for the ones in use to be released
+func (c *Client) Close() {
+	c.pool.Close()
 }
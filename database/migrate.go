@@ -0,0 +1,144 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/rs/zerolog/log"
+)
+
+// Migrate applies the migrations at migrationURL the database at dsn is missing. A dirty
+// database, left by a migration that failed halfway, is refused unless forceDirty is set, then
+// the failed migration is run again.
+func Migrate(migrationURL, dsn string, forceDirty bool) error {
+	migration, migrations, err := openMigration(migrationURL, dsn)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	return migrateUp(migration, migrations, forceDirty)
+}
+
+// MigrateDown undoes the last steps migrations applied to the database at dsn
+func MigrateDown(migrationURL, dsn string, steps int) error {
+	migration, _, err := openMigration(migrationURL, dsn)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	if err := migration.Steps(-steps); err != nil {
+		return fmt.Errorf("failed to run migrate down: %w", err)
+	}
+
+	version, _, err := migrationVersion(migration)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("version", version).Int("steps", steps).Msg("DB migrated down successfully")
+
+	return nil
+}
+
+// MigrationVersion returns the version of the last migration applied to the database at dsn,
+// NilVersion when there is none, and whether it failed halfway
+func MigrationVersion(migrationURL, dsn string) (int, bool, error) {
+	migration, _, err := openMigration(migrationURL, dsn)
+	if err != nil {
+		return 0, false, err
+	}
+	defer migration.Close()
+
+	return migrationVersion(migration)
+}
+
+// NilVersion is the version of a database no migration has been applied to
+const NilVersion = migratedb.NilVersion
+
+// openMigration returns migrate for the database at dsn, and the migrations it reads which
+// closing it closes too
+func openMigration(migrationURL, dsn string) (*migrate.Migrate, source.Driver, error) {
+	migrations, err := source.Open(migrationURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open migrations: %w", err)
+	}
+
+	migration, err := migrate.NewWithSourceInstance("migrations", migrations, dsn)
+	if err != nil {
+		migrations.Close()
+		return nil, nil, fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+
+	return migration, migrations, nil
+}
+
+func migrateUp(migration *migrate.Migrate, migrations source.Driver, forceDirty bool) error {
+	version, dirty, err := migrationVersion(migration)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("version", version).Bool("dirty", dirty).Msg("database schema before migrating")
+
+	if dirty {
+		if !forceDirty {
+			return fmt.Errorf("migration %d failed and left the database dirty, fix it and force the version by hand or allow forcing to run it again", version)
+		}
+		if err := forceBefore(migration, migrations, version); err != nil {
+			return err
+		}
+	}
+
+	if err := migration.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrate up: %w", err)
+	}
+
+	version, _, err = migrationVersion(migration)
+	if err != nil {
+		return err
+	}
+	log.Info().Int("version", version).Msg("DB migrated successfully")
+
+	return nil
+}
+
+// forceBefore marks the migration before version as the last one applied, so the next Up runs
+// version again. That is only safe because postgres runs each migration file in one
+// transaction, a failed one left nothing behind to trip over.
+func forceBefore(migration *migrate.Migrate, migrations source.Driver, version int) error {
+	previous := NilVersion
+	prev, err := migrations.Prev(uint(version))
+	switch {
+	case err == nil:
+		previous = int(prev)
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("cannot find the migration before %d: %w", version, err)
+	}
+
+	log.Warn().Int("version", version).Int("forced_to", previous).Msg("forcing dirty database to run the failed migration again")
+	if err := migration.Force(previous); err != nil {
+		return fmt.Errorf("cannot force migration version %d: %w", previous, err)
+	}
+
+	return nil
+}
+
+// migrationVersion returns the version of the last migration applied, NilVersion when
+// there is none
+func migrationVersion(migration *migrate.Migrate) (int, bool, error) {
+	version, dirty, err := migration.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot read migration version: %w", err)
+	}
+
+	return int(version), dirty, nil
+}
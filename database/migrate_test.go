@@ -0,0 +1,59 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubMigration returns migrate running two migrations against a stub database dirty at version
+func newStubMigration(t *testing.T, version int) (*migrate.Migrate, source.Driver, *stub.Stub) {
+	dir := t.TempDir()
+	for _, name := range []string{"1_first.up.sql", "2_second.up.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+
+	migrations, err := source.Open("file://" + dir)
+	require.NoError(t, err)
+
+	db, err := stub.WithInstance(nil, &stub.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.SetVersion(version, true))
+
+	migration, err := migrate.NewWithInstance("file", migrations, "stub", db)
+	require.NoError(t, err)
+
+	return migration, migrations, db.(*stub.Stub)
+}
+
+func TestMigrateUpRefusesDirtyDatabase(t *testing.T) {
+	migration, migrations, db := newStubMigration(t, 2)
+
+	err := migrateUp(migration, migrations, false)
+	require.ErrorContains(t, err, "migration 2 failed and left the database dirty")
+	require.Empty(t, db.MigrationSequence)
+	require.True(t, db.IsDirty)
+}
+
+func TestMigrateUpForcesDirtyDatabase(t *testing.T) {
+	migration, migrations, db := newStubMigration(t, 2)
+
+	require.NoError(t, migrateUp(migration, migrations, true))
+	require.Equal(t, []string{"2_second.up.sql"}, db.MigrationSequence)
+	require.Equal(t, 2, db.CurrentVersion)
+	require.False(t, db.IsDirty)
+}
+
+func TestMigrateUpForcesDirtyFirstMigration(t *testing.T) {
+	migration, migrations, db := newStubMigration(t, 1)
+
+	require.NoError(t, migrateUp(migration, migrations, true))
+	require.Equal(t, []string{"1_first.up.sql", "2_second.up.sql"}, db.MigrationSequence)
+	require.Equal(t, 2, db.CurrentVersion)
+	require.False(t, db.IsDirty)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+DROP INDEX IF EXISTS "transactions_created_at_idx";
@@ -0,0 +1,3 @@
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+CREATE INDEX ON "transactions" ("created_at");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transactions_description_tsv_idx";
+
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "description_tsv";
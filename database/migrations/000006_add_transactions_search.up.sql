@@ -0,0 +1,4 @@
+ALTER TABLE "transactions" ADD COLUMN "description_tsv" tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', coalesce("description", ''))) STORED;
+
+CREATE INDEX "transactions_description_tsv_idx" ON "transactions" USING GIN ("description_tsv");
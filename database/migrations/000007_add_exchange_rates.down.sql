@@ -0,0 +1,5 @@
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "exchange_rate";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "to_currency";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "to_amount";
+
+DROP TABLE IF EXISTS "exchange_rates";
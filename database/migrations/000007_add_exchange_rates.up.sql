@@ -0,0 +1,20 @@
+CREATE TABLE "exchange_rates" (
+  "base" varchar NOT NULL,
+  "quote" varchar NOT NULL,
+  "rate" float NOT NULL,
+  "fetched_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("base", "quote")
+);
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'price of one unit of base in quote';
+
+ALTER TABLE "transactions" ADD COLUMN "to_amount" float;
+ALTER TABLE "transactions" ADD COLUMN "to_currency" varchar;
+ALTER TABLE "transactions" ADD COLUMN "exchange_rate" float NOT NULL DEFAULT 1;
+
+UPDATE "transactions" SET "to_amount" = "amount", "to_currency" = "currency";
+
+ALTER TABLE "transactions" ALTER COLUMN "to_amount" SET NOT NULL;
+ALTER TABLE "transactions" ALTER COLUMN "to_currency" SET NOT NULL;
+
+COMMENT ON COLUMN "transactions"."to_amount" IS 'amount credited to the receiver, in to_currency';
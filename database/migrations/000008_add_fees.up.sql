@@ -0,0 +1,13 @@
+CREATE TABLE "fees" (
+  "currency" varchar PRIMARY KEY,
+  "flat" float NOT NULL DEFAULT 0,
+  "percentage" float NOT NULL DEFAULT 0,
+  "fee_account_id" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "fees"."percentage" IS 'percent of the transfer amount, 1.5 means 1.5%';
+
+COMMENT ON COLUMN "fees"."fee_account_id" IS 'internal account credited with the fee, must hold the same currency';
+
+ALTER TABLE "fees" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,18 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" float NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "overdraft_limit_non_negative" CHECK ("overdraft_limit" >= 0);
+
+CREATE TABLE "overdraft_limit_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "old_limit" float NOT NULL,
+  "new_limit" float NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "overdraft_limit_changes" ("account_id");
+
+ALTER TABLE "overdraft_limit_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "overdraft_limit_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
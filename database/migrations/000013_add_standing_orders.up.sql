@@ -0,0 +1,47 @@
+CREATE TABLE "standing_orders" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "currency" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "frequency" varchar NOT NULL,
+  "start_date" timestamptz NOT NULL,
+  "end_date" timestamptz,
+  "next_run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "consecutive_failures" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "standing_order_executions" (
+  "id" bigserial PRIMARY KEY,
+  "standing_order_id" bigint NOT NULL,
+  "run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "failure_reason" varchar NOT NULL DEFAULT '',
+  "transaction_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "standing_orders" ("owner");
+
+CREATE INDEX ON "standing_orders" ("status", "next_run_at");
+
+CREATE UNIQUE INDEX ON "standing_order_executions" ("standing_order_id", "run_at");
+
+COMMENT ON COLUMN "standing_orders"."frequency" IS 'daily, weekly or monthly';
+
+COMMENT ON COLUMN "standing_orders"."status" IS 'active, paused, suspended or completed';
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_order_executions" ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "standing_order_executions" ADD FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_reversals";
+
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "reversal_of";
@@ -0,0 +1,20 @@
+ALTER TABLE "transactions" ADD COLUMN "reversal_of" bigint UNIQUE;
+
+ALTER TABLE "transactions" ADD FOREIGN KEY ("reversal_of") REFERENCES "transactions" ("id");
+
+COMMENT ON COLUMN "transactions"."reversal_of" IS 'the transaction this one reverses, a transaction can be reversed once';
+
+CREATE TABLE "transfer_reversals" (
+  "id" bigserial PRIMARY KEY,
+  "transaction_id" bigint UNIQUE NOT NULL,
+  "reversal_id" bigint UNIQUE NOT NULL,
+  "reversed_by" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("reversal_id") REFERENCES "transactions" ("id");
+
+ALTER TABLE "transfer_reversals" ADD FOREIGN KEY ("reversed_by") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+ALTER TABLE "beneficiaries" DROP COLUMN IF EXISTS "bic";
+
+ALTER TABLE "beneficiaries" DROP COLUMN IF EXISTS "iban";
@@ -0,0 +1,5 @@
+ALTER TABLE "beneficiaries" ADD COLUMN "iban" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "beneficiaries" ADD COLUMN "bic" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "beneficiaries" ("owner", "iban");
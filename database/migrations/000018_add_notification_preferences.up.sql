@@ -0,0 +1,11 @@
+CREATE TABLE "notification_preferences" (
+  "username" varchar PRIMARY KEY,
+  "email_transfers" boolean NOT NULL DEFAULT true,
+  "email_logins" boolean NOT NULL DEFAULT true,
+  "email_statements" boolean NOT NULL DEFAULT true,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "notification_preferences" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+INSERT INTO "notification_preferences" ("username") SELECT "username" FROM "users";
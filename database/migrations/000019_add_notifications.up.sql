@@ -0,0 +1,15 @@
+CREATE TABLE "notifications" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "title" varchar NOT NULL,
+  "body" varchar NOT NULL,
+  "read_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "notifications" ("username", "created_at" DESC, "id" DESC);
+
+CREATE INDEX ON "notifications" ("username") WHERE "read_at" IS NULL;
+
+ALTER TABLE "notifications" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
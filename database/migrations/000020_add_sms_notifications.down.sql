@@ -0,0 +1,3 @@
+ALTER TABLE "notification_preferences" DROP COLUMN IF EXISTS "prefer_sms";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "phone_number";
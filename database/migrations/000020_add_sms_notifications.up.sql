@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "phone_number" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "notification_preferences" ADD COLUMN "prefer_sms" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,16 @@
+CREATE TABLE "failed_notifications" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "channel" varchar NOT NULL,
+  "recipient" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "error" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "failed_notifications" ("username");
+
+CREATE INDEX ON "failed_notifications" ("created_at");
+
+ALTER TABLE "failed_notifications" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
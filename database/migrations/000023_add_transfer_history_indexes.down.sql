@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transactions_from_account_id_created_at_id_idx";
+
+DROP INDEX IF EXISTS "transactions_to_account_id_created_at_id_idx";
@@ -0,0 +1,3 @@
+CREATE INDEX ON "transactions" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transactions" ("to_account_id", "created_at", "id");
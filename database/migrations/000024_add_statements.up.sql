@@ -0,0 +1,22 @@
+CREATE TABLE "statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "year" int NOT NULL,
+  "month" int NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "object_key" varchar NOT NULL DEFAULT '',
+  "failure_reason" varchar NOT NULL DEFAULT '',
+  "requested_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "statements" ("account_id", "year", "month");
+
+COMMENT ON COLUMN "statements"."status" IS 'pending, ready or failed';
+
+COMMENT ON COLUMN "statements"."object_key" IS 'where the PDF is kept in object storage, empty until ready';
+
+ALTER TABLE "statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "statements" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
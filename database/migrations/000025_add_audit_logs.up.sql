@@ -0,0 +1,34 @@
+CREATE TABLE "audit_logs" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "entity" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "before" jsonb,
+  "after" jsonb,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_logs" ("entity", "entity_id", "created_at");
+
+COMMENT ON TABLE "audit_logs" IS 'append only, rows are never updated or deleted';
+
+COMMENT ON COLUMN "audit_logs"."actor" IS 'username of who did it, not a foreign key so the log outlives the user';
+
+REVOKE UPDATE, DELETE, TRUNCATE ON "audit_logs" FROM PUBLIC;
+
+-- the owner of a table keeps every privilege on it, so the trigger stops it changing the log as well
+CREATE FUNCTION "audit_logs_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_logs_append_only"
+  BEFORE UPDATE OR DELETE ON "audit_logs"
+  FOR EACH ROW EXECUTE FUNCTION "audit_logs_append_only"();
+
+CREATE TRIGGER "audit_logs_no_truncate"
+  BEFORE TRUNCATE ON "audit_logs"
+  FOR EACH STATEMENT EXECUTE FUNCTION "audit_logs_append_only"();
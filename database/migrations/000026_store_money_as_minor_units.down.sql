@@ -0,0 +1,48 @@
+DROP VIEW IF EXISTS "transactions_decimal";
+
+DROP VIEW IF EXISTS "entries_decimal";
+
+DROP VIEW IF EXISTS "accounts_decimal";
+
+CREATE FUNCTION "account_currency"(account_id bigint) RETURNS varchar AS $$
+  SELECT "currency" FROM "accounts" WHERE "id" = account_id;
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE "entries" ALTER COLUMN "amount" TYPE float
+  USING "amount" / 10::numeric ^ currency_exponent(account_currency("account_id"));
+
+ALTER TABLE "overdraft_limit_changes" ALTER COLUMN "old_limit" TYPE float
+  USING "old_limit" / 10::numeric ^ currency_exponent(account_currency("account_id"));
+
+ALTER TABLE "overdraft_limit_changes" ALTER COLUMN "new_limit" TYPE float
+  USING "new_limit" / 10::numeric ^ currency_exponent(account_currency("account_id"));
+
+DROP FUNCTION "account_currency";
+
+ALTER TABLE "accounts"
+  ALTER COLUMN "balance" TYPE float USING "balance" / 10::numeric ^ currency_exponent("currency"),
+  ALTER COLUMN "overdraft_limit" TYPE float USING "overdraft_limit" / 10::numeric ^ currency_exponent("currency");
+
+ALTER TABLE "transactions"
+  ALTER COLUMN "amount" TYPE float USING "amount" / 10::numeric ^ currency_exponent("currency"),
+  ALTER COLUMN "fee" TYPE float USING "fee" / 10::numeric ^ currency_exponent("currency"),
+  ALTER COLUMN "to_amount" TYPE float USING "to_amount" / 10::numeric ^ currency_exponent("to_currency");
+
+ALTER TABLE "fees" ALTER COLUMN "flat" TYPE float
+  USING "flat" / 10::numeric ^ currency_exponent("currency");
+
+ALTER TABLE "scheduled_transfers" ALTER COLUMN "amount" TYPE float
+  USING "amount" / 10::numeric ^ currency_exponent("currency");
+
+ALTER TABLE "standing_orders" ALTER COLUMN "amount" TYPE float
+  USING "amount" / 10::numeric ^ currency_exponent("currency");
+
+COMMENT ON COLUMN "accounts"."balance" IS NULL;
+
+COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
+
+COMMENT ON COLUMN "transactions"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "transactions"."to_amount" IS 'amount credited to the receiver, in to_currency';
+
+DROP FUNCTION IF EXISTS "currency_exponent";
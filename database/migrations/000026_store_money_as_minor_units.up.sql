@@ -0,0 +1,73 @@
+-- decimal places of the minor unit of a currency, kept in step with the money package
+CREATE FUNCTION "currency_exponent"(currency varchar) RETURNS integer AS $$
+  SELECT CASE currency WHEN 'JPY' THEN 0 WHEN 'BHD' THEN 3 ELSE 2 END;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- ALTER COLUMN ... USING takes no subqueries, so the rows that only know their account look the currency up through this
+CREATE FUNCTION "account_currency"(account_id bigint) RETURNS varchar AS $$
+  SELECT "currency" FROM "accounts" WHERE "id" = account_id;
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE "entries" ALTER COLUMN "amount" TYPE bigint
+  USING round("amount"::numeric * 10::numeric ^ currency_exponent(account_currency("account_id")))::bigint;
+
+ALTER TABLE "overdraft_limit_changes" ALTER COLUMN "old_limit" TYPE bigint
+  USING round("old_limit"::numeric * 10::numeric ^ currency_exponent(account_currency("account_id")))::bigint;
+
+ALTER TABLE "overdraft_limit_changes" ALTER COLUMN "new_limit" TYPE bigint
+  USING round("new_limit"::numeric * 10::numeric ^ currency_exponent(account_currency("account_id")))::bigint;
+
+DROP FUNCTION "account_currency";
+
+ALTER TABLE "accounts" ALTER COLUMN "overdraft_limit" DROP DEFAULT;
+
+ALTER TABLE "accounts"
+  ALTER COLUMN "balance" TYPE bigint USING round("balance"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint,
+  ALTER COLUMN "overdraft_limit" TYPE bigint USING round("overdraft_limit"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint;
+
+ALTER TABLE "accounts" ALTER COLUMN "overdraft_limit" SET DEFAULT 0;
+
+ALTER TABLE "transactions"
+  ALTER COLUMN "amount" TYPE bigint USING round("amount"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint,
+  ALTER COLUMN "fee" TYPE bigint USING round("fee"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint,
+  ALTER COLUMN "to_amount" TYPE bigint USING round("to_amount"::numeric * 10::numeric ^ currency_exponent("to_currency"))::bigint;
+
+ALTER TABLE "fees" ALTER COLUMN "flat" DROP DEFAULT;
+
+ALTER TABLE "fees" ALTER COLUMN "flat" TYPE bigint
+  USING round("flat"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint;
+
+ALTER TABLE "fees" ALTER COLUMN "flat" SET DEFAULT 0;
+
+ALTER TABLE "scheduled_transfers" ALTER COLUMN "amount" TYPE bigint
+  USING round("amount"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint;
+
+ALTER TABLE "standing_orders" ALTER COLUMN "amount" TYPE bigint
+  USING round("amount"::numeric * 10::numeric ^ currency_exponent("currency"))::bigint;
+
+COMMENT ON COLUMN "accounts"."balance" IS 'in minor units of currency, cents for USD';
+
+COMMENT ON COLUMN "entries"."amount" IS 'in minor units of the account currency, can be negative or positive';
+
+COMMENT ON COLUMN "transactions"."amount" IS 'in minor units of currency, must be positive';
+
+COMMENT ON COLUMN "transactions"."to_amount" IS 'amount credited to the receiver, in minor units of to_currency';
+
+-- the amounts in major units, as they were stored before, for reports and queries written against the old columns
+CREATE VIEW "accounts_decimal" AS
+  SELECT "id", "owner", "currency", "created_at",
+    "balance" / 10::numeric ^ currency_exponent("currency") AS "balance",
+    "overdraft_limit" / 10::numeric ^ currency_exponent("currency") AS "overdraft_limit"
+  FROM "accounts";
+
+CREATE VIEW "entries_decimal" AS
+  SELECT e."id", e."account_id", e."created_at", a."currency",
+    e."amount" / 10::numeric ^ currency_exponent(a."currency") AS "amount"
+  FROM "entries" e JOIN "accounts" a ON a."id" = e."account_id";
+
+CREATE VIEW "transactions_decimal" AS
+  SELECT "id", "from_account_id", "to_account_id", "currency", "to_currency", "exchange_rate", "created_at",
+    "amount" / 10::numeric ^ currency_exponent("currency") AS "amount",
+    "fee" / 10::numeric ^ currency_exponent("currency") AS "fee",
+    "to_amount" / 10::numeric ^ currency_exponent("to_currency") AS "to_amount"
+  FROM "transactions";
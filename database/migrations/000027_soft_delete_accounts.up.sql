@@ -0,0 +1,10 @@
+ALTER TABLE "accounts" ADD COLUMN "deleted_at" timestamptz;
+
+-- a deleted account no longer stops its owner opening another one in the currency
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "deleted_at" IS NULL;
+
+CREATE INDEX ON "accounts" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+COMMENT ON COLUMN "accounts"."deleted_at" IS 'set when the account is deleted, the row stays for the history of its entries and transfers';
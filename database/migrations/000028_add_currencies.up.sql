@@ -0,0 +1,14 @@
+CREATE TABLE "currencies" (
+  "code" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "minor_unit_exponent" integer NOT NULL CHECK ("minor_unit_exponent" BETWEEN 0 AND 4),
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."enabled" IS 'a disabled currency takes no new accounts or transfers, existing balances stay readable';
+
+INSERT INTO "currencies" ("code", "name", "minor_unit_exponent") VALUES
+  ('USD', 'US Dollar', 2),
+  ('EUR', 'Euro', 2),
+  ('CAD', 'Canadian Dollar', 2);
@@ -0,0 +1,12 @@
+ALTER TABLE "fees"
+  DROP CONSTRAINT IF EXISTS "fees_percentage_non_negative",
+  DROP CONSTRAINT IF EXISTS "fees_flat_non_negative";
+
+ALTER TABLE "standing_orders" DROP CONSTRAINT IF EXISTS "standing_orders_amount_positive";
+
+ALTER TABLE "scheduled_transfers" DROP CONSTRAINT IF EXISTS "scheduled_transfers_amount_positive";
+
+ALTER TABLE "transactions"
+  DROP CONSTRAINT IF EXISTS "transactions_fee_non_negative",
+  DROP CONSTRAINT IF EXISTS "transactions_to_amount_positive",
+  DROP CONSTRAINT IF EXISTS "transactions_amount_positive";
@@ -0,0 +1,13 @@
+-- the edge validates amounts before they get here, these catch whatever path forgets to
+ALTER TABLE "transactions"
+  ADD CONSTRAINT "transactions_amount_positive" CHECK ("amount" > 0),
+  ADD CONSTRAINT "transactions_to_amount_positive" CHECK ("to_amount" > 0),
+  ADD CONSTRAINT "transactions_fee_non_negative" CHECK ("fee" >= 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "standing_orders" ADD CONSTRAINT "standing_orders_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "fees"
+  ADD CONSTRAINT "fees_flat_non_negative" CHECK ("flat" >= 0),
+  ADD CONSTRAINT "fees_percentage_non_negative" CHECK ("percentage" >= 0);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "verify_phones";
+
+DROP INDEX IF EXISTS "users_phone_number_idx";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "is_phone_verified";
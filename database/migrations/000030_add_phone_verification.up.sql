@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "is_phone_verified" bool NOT NULL DEFAULT false;
+
+-- phone numbers may be shared, the index only makes finding the other users of one cheap
+CREATE INDEX ON "users" ("phone_number") WHERE "phone_number" <> '';
+
+CREATE TABLE "verify_phones" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "phone_number" varchar NOT NULL,
+  "secret_code" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '10 minutes')
+);
+
+ALTER TABLE "verify_phones" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "verify_phones" ("username");
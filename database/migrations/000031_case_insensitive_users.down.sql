@@ -0,0 +1,3 @@
+-- the lowercased usernames and emails and the cascading foreign keys are kept
+DROP INDEX IF EXISTS "users_lower_email_key";
+DROP INDEX IF EXISTS "users_lower_username_key";
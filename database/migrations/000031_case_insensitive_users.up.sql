@@ -0,0 +1,39 @@
+-- usernames and emails that only differ in case belong to different people, they can't be merged
+-- here. The migration stops and names them so they can be renamed by hand before it is run again.
+DO $$
+DECLARE
+  collisions text;
+BEGIN
+  SELECT string_agg(kind || ' ' || name, ', ') INTO collisions FROM (
+    SELECT 'username' AS kind, lower("username") AS name FROM "users" GROUP BY lower("username") HAVING count(*) > 1
+    UNION ALL
+    SELECT 'email', lower("email") FROM "users" GROUP BY lower("email") HAVING count(*) > 1
+  ) AS c;
+
+  IF collisions IS NOT NULL THEN
+    RAISE EXCEPTION 'users differing only in case must be renamed first: %', collisions;
+  END IF;
+END $$;
+
+-- the foreign keys to users follow a renamed username, so mixed case rows can be lowercased
+DO $$
+DECLARE
+  fk record;
+BEGIN
+  FOR fk IN
+    SELECT c.conrelid::regclass AS tbl, c.conname, a.attname AS col
+    FROM pg_constraint c
+    JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+    WHERE c.contype = 'f' AND c.confrelid = 'users'::regclass
+  LOOP
+    EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', fk.tbl, fk.conname);
+    EXECUTE format('ALTER TABLE %s ADD CONSTRAINT %I FOREIGN KEY (%I) REFERENCES "users" ("username") ON UPDATE CASCADE',
+      fk.tbl, fk.conname, fk.col);
+  END LOOP;
+END $$;
+
+UPDATE "users" SET "username" = lower("username") WHERE "username" <> lower("username");
+UPDATE "users" SET "email" = lower("email") WHERE "email" <> lower("email");
+
+CREATE UNIQUE INDEX "users_lower_username_key" ON "users" (lower("username"));
+CREATE UNIQUE INDEX "users_lower_email_key" ON "users" (lower("email"));
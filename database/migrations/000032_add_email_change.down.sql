@@ -0,0 +1,2 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_changed_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "previous_email";
@@ -0,0 +1,3 @@
+-- the last verified email stays usable for account recovery for a while after it is changed
+ALTER TABLE "users" ADD COLUMN "previous_email" varchar NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "email_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
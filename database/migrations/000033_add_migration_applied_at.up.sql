@@ -0,0 +1,2 @@
+-- migrate writes only version and dirty, the default records when a migration finished
+ALTER TABLE "schema_migrations" ADD COLUMN "applied_at" timestamptz NOT NULL DEFAULT (now());
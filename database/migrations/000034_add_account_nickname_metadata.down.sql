@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "accounts_owner_nickname_key";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "metadata";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "nickname";
@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar;
+ALTER TABLE "accounts" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+-- like the currency, a nickname is free again once the account holding it is deleted
+CREATE UNIQUE INDEX "accounts_owner_nickname_key" ON "accounts" ("owner", "nickname") WHERE "deleted_at" IS NULL;
+
+COMMENT ON COLUMN "accounts"."metadata" IS 'string keys and values set by the owner, keys starting with sb_ are reserved for the bank';
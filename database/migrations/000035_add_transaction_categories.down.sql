@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "entry_tags";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "category";
+
+DROP TABLE IF EXISTS "categories";
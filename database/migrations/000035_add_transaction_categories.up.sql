@@ -0,0 +1,35 @@
+CREATE TABLE "categories" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "categories" IS 'the categories an entry can be filed under, more can be inserted at any time';
+
+INSERT INTO "categories" ("name", "description") VALUES
+  ('groceries', 'Food and household supplies'),
+  ('dining', 'Restaurants, cafes and takeaway'),
+  ('housing', 'Rent, mortgage and repairs'),
+  ('utilities', 'Energy, water, phone and internet'),
+  ('transport', 'Fuel, fares and car costs'),
+  ('health', 'Medical care and pharmacy'),
+  ('entertainment', 'Leisure, events and subscriptions'),
+  ('shopping', 'Clothing and other goods'),
+  ('travel', 'Flights, hotels and holidays'),
+  ('income', 'Salary and other money received'),
+  ('savings', 'Money put aside'),
+  ('other', 'Anything else');
+
+-- each side of a transfer is its own entry, the sender and the receiver file it as they see it
+ALTER TABLE "entries" ADD COLUMN "category" varchar REFERENCES "categories" ("name");
+
+CREATE INDEX ON "entries" ("account_id", "category", "created_at") WHERE "category" IS NOT NULL;
+
+CREATE TABLE "entry_tags" (
+  "entry_id" bigint NOT NULL REFERENCES "entries" ("id") ON DELETE CASCADE,
+  "tag" varchar NOT NULL,
+  PRIMARY KEY ("entry_id", "tag")
+);
+
+-- filtering by tag starts from the tag, the primary key serves loading the tags of an entry
+CREATE INDEX ON "entry_tags" ("tag", "entry_id");
@@ -0,0 +1,5 @@
+COMMENT ON COLUMN "transactions"."description" IS NULL;
+
+ALTER TABLE "standing_orders" DROP CONSTRAINT IF EXISTS "standing_orders_description_length";
+ALTER TABLE "scheduled_transfers" DROP CONSTRAINT IF EXISTS "scheduled_transfers_description_length";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_description_length";
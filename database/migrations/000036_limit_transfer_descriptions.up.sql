@@ -0,0 +1,6 @@
+-- memos written before the limit are kept, NOT VALID only checks the rows written from now on
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_description_length" CHECK (char_length("description") <= 140) NOT VALID;
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_description_length" CHECK (char_length("description") <= 140) NOT VALID;
+ALTER TABLE "standing_orders" ADD CONSTRAINT "standing_orders_description_length" CHECK (char_length("description") <= 140) NOT VALID;
+
+COMMENT ON COLUMN "transactions"."description" IS 'the memo of the sender, at most 140 characters without control characters';
@@ -0,0 +1,10 @@
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "savings_no_overdraft";
+
+-- fails while an owner still has a checking and a savings account in the same currency
+DROP INDEX IF EXISTS "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "deleted_at" IS NULL;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "account_type";
+
+DROP TYPE IF EXISTS "account_type";
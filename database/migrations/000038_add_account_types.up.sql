@@ -0,0 +1,13 @@
+CREATE TYPE "account_type" AS ENUM ('checking', 'savings');
+
+-- the accounts opened before types existed are all checking accounts
+ALTER TABLE "accounts" ADD COLUMN "account_type" "account_type" NOT NULL DEFAULT 'checking';
+
+-- an owner may keep a savings account next to the checking account in a currency
+DROP INDEX "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency", "account_type") WHERE "deleted_at" IS NULL;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "savings_no_overdraft" CHECK ("account_type" = 'checking' OR "overdraft_limit" = 0);
+
+COMMENT ON COLUMN "accounts"."account_type" IS 'set when the account is opened, savings accounts cannot be overdrawn and make a limited number of transfers a month';
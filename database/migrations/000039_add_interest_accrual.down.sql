@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "interest_accruals";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "accrued_interest";
+
+-- the category stays, paid interest entries are still filed under it
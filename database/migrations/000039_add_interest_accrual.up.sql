@@ -0,0 +1,21 @@
+ALTER TABLE "accounts" ADD COLUMN "accrued_interest" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "accounts"."accrued_interest" IS 'interest accrued since it was last paid, in minor units of the account currency';
+
+-- one accrual per account and day, a rerun of the nightly job finds the day taken and pays nothing
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "rate" numeric NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "interest_accruals_account_date_key" UNIQUE ("account_id", "accrual_date")
+);
+
+COMMENT ON COLUMN "interest_accruals"."rate" IS 'the yearly rate in percent the amount was accrued at';
+
+INSERT INTO "categories" ("name", "description") VALUES
+  ('interest', 'Interest paid on savings')
+ON CONFLICT ("name") DO NOTHING;
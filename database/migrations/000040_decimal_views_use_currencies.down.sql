@@ -0,0 +1,28 @@
+DROP VIEW IF EXISTS "transactions_decimal";
+
+DROP VIEW IF EXISTS "entries_decimal";
+
+DROP VIEW IF EXISTS "accounts_decimal";
+
+-- the down migration of 000026 still needs it
+CREATE FUNCTION "currency_exponent"(currency varchar) RETURNS integer AS $$
+  SELECT CASE currency WHEN 'JPY' THEN 0 WHEN 'BHD' THEN 3 ELSE 2 END;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE VIEW "accounts_decimal" AS
+  SELECT "id", "owner", "currency", "created_at",
+    "balance" / 10::numeric ^ currency_exponent("currency") AS "balance",
+    "overdraft_limit" / 10::numeric ^ currency_exponent("currency") AS "overdraft_limit"
+  FROM "accounts";
+
+CREATE VIEW "entries_decimal" AS
+  SELECT e."id", e."account_id", e."created_at", a."currency",
+    e."amount" / 10::numeric ^ currency_exponent(a."currency") AS "amount"
+  FROM "entries" e JOIN "accounts" a ON a."id" = e."account_id";
+
+CREATE VIEW "transactions_decimal" AS
+  SELECT "id", "from_account_id", "to_account_id", "currency", "to_currency", "exchange_rate", "created_at",
+    "amount" / 10::numeric ^ currency_exponent("currency") AS "amount",
+    "fee" / 10::numeric ^ currency_exponent("currency") AS "fee",
+    "to_amount" / 10::numeric ^ currency_exponent("to_currency") AS "to_amount"
+  FROM "transactions";
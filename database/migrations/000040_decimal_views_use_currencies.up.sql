@@ -0,0 +1,31 @@
+-- currency_exponent only knew the currencies of its time, currencies added to the table since were
+-- scaled as if they had two decimals. The views read the exponent from the table instead, an amount
+-- in a currency the table doesn't have is NULL rather than wrong.
+DROP VIEW IF EXISTS "transactions_decimal";
+
+DROP VIEW IF EXISTS "entries_decimal";
+
+DROP VIEW IF EXISTS "accounts_decimal";
+
+DROP FUNCTION IF EXISTS "currency_exponent";
+
+CREATE VIEW "accounts_decimal" AS
+  SELECT a."id", a."owner", a."currency", a."created_at",
+    a."balance" / 10::numeric ^ c."minor_unit_exponent" AS "balance",
+    a."overdraft_limit" / 10::numeric ^ c."minor_unit_exponent" AS "overdraft_limit"
+  FROM "accounts" a LEFT JOIN "currencies" c ON c."code" = a."currency";
+
+CREATE VIEW "entries_decimal" AS
+  SELECT e."id", e."account_id", e."created_at", a."currency",
+    e."amount" / 10::numeric ^ c."minor_unit_exponent" AS "amount"
+  FROM "entries" e JOIN "accounts" a ON a."id" = e."account_id"
+  LEFT JOIN "currencies" c ON c."code" = a."currency";
+
+CREATE VIEW "transactions_decimal" AS
+  SELECT t."id", t."from_account_id", t."to_account_id", t."currency", t."to_currency", t."exchange_rate", t."created_at",
+    t."amount" / 10::numeric ^ c."minor_unit_exponent" AS "amount",
+    t."fee" / 10::numeric ^ c."minor_unit_exponent" AS "fee",
+    t."to_amount" / 10::numeric ^ tc."minor_unit_exponent" AS "to_amount"
+  FROM "transactions" t
+  LEFT JOIN "currencies" c ON c."code" = t."currency"
+  LEFT JOIN "currencies" tc ON tc."code" = t."to_currency";
@@ -0,0 +1 @@
+ALTER TABLE "interest_accruals" DROP COLUMN IF EXISTS "paid_at";
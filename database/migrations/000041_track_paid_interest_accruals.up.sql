@@ -0,0 +1,8 @@
+-- interest is paid by accrual, whatever a closed month left unpaid is paid on the next run
+ALTER TABLE "interest_accruals" ADD COLUMN "paid_at" timestamptz;
+
+-- the job used to pay every accrual of a closed month on the first
+UPDATE "interest_accruals" SET "paid_at" = "created_at"
+WHERE "accrual_date" < date_trunc('month', now() AT TIME ZONE 'UTC')::date;
+
+CREATE INDEX ON "interest_accruals" ("account_id", "accrual_date") WHERE "paid_at" IS NULL;
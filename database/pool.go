@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxPoolConns keeps a typo from opening more connections than any postgres accepts
+const maxPoolConns = 10000
+
+// PoolConfig tunes a connection pool. A zero field keeps what the DSN says, or the pgxpool
+// default when it says nothing.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32         // kept open even when idle
+	MaxConnLifetime   time.Duration // a connection is closed once it is this old
+	MaxConnIdleTime   time.Duration // an idle connection is closed after this long
+	HealthCheckPeriod time.Duration // how often idle connections are checked
+
+	// CurrentDSN, when set, gives the DSN new connections take their password from, so a rotated
+	// password is used without a restart. Open connections keep theirs until they are closed.
+	CurrentDSN func() string
+}
+
+// Validate rejects numbers a pool can't work with
+func (c PoolConfig) Validate() error {
+	if c.MaxConns < 0 || c.MaxConns > maxPoolConns {
+		return fmt.Errorf("max conns must be between 0 and %d, got %d", maxPoolConns, c.MaxConns)
+	}
+	if c.MinConns < 0 {
+		return fmt.Errorf("min conns must not be negative, got %d", c.MinConns)
+	}
+	if c.MaxConns != 0 && c.MinConns > c.MaxConns {
+		return fmt.Errorf("min conns must be at most max conns %d, got %d", c.MaxConns, c.MinConns)
+	}
+	if c.MaxConnLifetime < 0 {
+		return fmt.Errorf("max conn lifetime must not be negative, got %s", c.MaxConnLifetime)
+	}
+	if c.MaxConnIdleTime < 0 {
+		return fmt.Errorf("max conn idle time must not be negative, got %s", c.MaxConnIdleTime)
+	}
+	if c.HealthCheckPeriod < 0 {
+		return fmt.Errorf("health check period must not be negative, got %s", c.HealthCheckPeriod)
+	}
+
+	return nil
+}
+
+// apply sets the fields of c that aren't zero on config
+func (c PoolConfig) apply(config *pgxpool.Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.MaxConns != 0 {
+		config.MaxConns = c.MaxConns
+	}
+	if c.MinConns != 0 {
+		config.MinConns = c.MinConns
+	}
+	if c.MaxConnLifetime != 0 {
+		config.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime != 0 {
+		config.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	if c.HealthCheckPeriod != 0 {
+		config.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	if c.CurrentDSN != nil {
+		config.BeforeConnect = func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+			current, err := pgx.ParseConfig(c.CurrentDSN())
+			if err != nil {
+				return fmt.Errorf("cannot parse current DSN: %w", err)
+			}
+			connConfig.Password = current.Password
+			return nil
+		}
+	}
+
+	// the max may come from the DSN or the default
+	if config.MinConns > config.MaxConns {
+		return fmt.Errorf("min conns must be at most max conns %d, got %d", config.MaxConns, config.MinConns)
+	}
+
+	return nil
+}
+
+// PoolStats is how a connection pool is being used
+type PoolStats struct {
+	AcquiredConns      int32   `json:"acquired_conns"`
+	IdleConns          int32   `json:"idle_conns"`
+	TotalConns         int32   `json:"total_conns"`
+	MaxConns           int32   `json:"max_conns"`
+	EmptyAcquireCount  int64   `json:"empty_acquire_count"`  // acquires that had to wait for a connection
+	AcquireWaitSeconds float64 `json:"acquire_wait_seconds"` // spent acquiring connections, in total
+}
+
+func poolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		AcquiredConns:      stat.AcquiredConns(),
+		IdleConns:          stat.IdleConns(),
+		TotalConns:         stat.TotalConns(),
+		MaxConns:           stat.MaxConns(),
+		EmptyAcquireCount:  stat.EmptyAcquireCount(),
+		AcquireWaitSeconds: stat.AcquireDuration().Seconds(),
+	}
+}
+
+// Stats shows the use of the pool next to its status in the health checks
+func (c *Client) Stats() any {
+	return poolStats(c.pool)
+}
+
+// poolCollector reads the stats of a pool when it is scraped
+type poolCollector struct {
+	pool                *pgxpool.Pool
+	acquiredConns       *prometheus.Desc
+	idleConns           *prometheus.Desc
+	totalConns          *prometheus.Desc
+	maxConns            *prometheus.Desc
+	emptyAcquires       *prometheus.Desc
+	acquireWaitDuration *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool, name string) *poolCollector {
+	labels := prometheus.Labels{"pool": name}
+	return &poolCollector{
+		pool:                pool,
+		acquiredConns:       prometheus.NewDesc("db_pool_acquired_connections", "Connections of the pool in use.", nil, labels),
+		idleConns:           prometheus.NewDesc("db_pool_idle_connections", "Idle connections of the pool.", nil, labels),
+		totalConns:          prometheus.NewDesc("db_pool_total_connections", "Open connections of the pool, including the ones being opened.", nil, labels),
+		maxConns:            prometheus.NewDesc("db_pool_max_connections", "Most connections the pool opens.", nil, labels),
+		emptyAcquires:       prometheus.NewDesc("db_pool_empty_acquires_total", "Number of acquires that had to wait for a connection.", nil, labels),
+		acquireWaitDuration: prometheus.NewDesc("db_pool_acquire_wait_seconds_total", "Time spent acquiring connections.", nil, labels),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.emptyAcquires
+	ch <- c.acquireWaitDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := poolStats(c.pool)
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stats.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stats.MaxConns))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stats.EmptyAcquireCount))
+	ch <- prometheus.MustNewConstMetric(c.acquireWaitDuration, prometheus.CounterValue, stats.AcquireWaitSeconds)
+}
+
+// RegisterMetrics registers gauges of the use of the pool with registerer, labelled with name so
+// the pools of several databases can share a registry. They are read from the pool on every
+// scrape.
+func (c *Client) RegisterMetrics(registerer prometheus.Registerer, name string) {
+	registerer.MustRegister(newPoolCollector(c.pool, name))
+}
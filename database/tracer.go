@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// tracers lets a connection have more than the one tracer pgx allows. Every tracer sees the
+// queries, the batch, copy, prepare and connect traces go to the ones that implement them.
+type tracers []pgx.QueryTracer
+
+// each tracer gets back the context it returned from its start, so one can't lose another's span
+type tracerContextKey struct{ index int }
+
+func (t tracers) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for i, tracer := range t {
+		ctx = context.WithValue(ctx, tracerContextKey{i}, tracer.TraceQueryStart(ctx, conn, data))
+	}
+	return ctx
+}
+
+func (t tracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i, tracer := range t {
+		tracer.TraceQueryEnd(tracerContext(ctx, i), conn, data)
+	}
+}
+
+func (t tracers) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.BatchTracer); ok {
+			ctx = context.WithValue(ctx, tracerContextKey{i}, tracer.TraceBatchStart(ctx, conn, data))
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.BatchTracer); ok {
+			tracer.TraceBatchQuery(tracerContext(ctx, i), conn, data)
+		}
+	}
+}
+
+func (t tracers) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.BatchTracer); ok {
+			tracer.TraceBatchEnd(tracerContext(ctx, i), conn, data)
+		}
+	}
+}
+
+func (t tracers) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.CopyFromTracer); ok {
+			ctx = context.WithValue(ctx, tracerContextKey{i}, tracer.TraceCopyFromStart(ctx, conn, data))
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.CopyFromTracer); ok {
+			tracer.TraceCopyFromEnd(tracerContext(ctx, i), conn, data)
+		}
+	}
+}
+
+func (t tracers) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.PrepareTracer); ok {
+			ctx = context.WithValue(ctx, tracerContextKey{i}, tracer.TracePrepareStart(ctx, conn, data))
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.PrepareTracer); ok {
+			tracer.TracePrepareEnd(tracerContext(ctx, i), conn, data)
+		}
+	}
+}
+
+func (t tracers) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.ConnectTracer); ok {
+			ctx = context.WithValue(ctx, tracerContextKey{i}, tracer.TraceConnectStart(ctx, data))
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	for i, tracer := range t {
+		if tracer, ok := tracer.(pgx.ConnectTracer); ok {
+			tracer.TraceConnectEnd(tracerContext(ctx, i), data)
+		}
+	}
+}
+
+func tracerContext(ctx context.Context, index int) context.Context {
+	if traced, ok := ctx.Value(tracerContextKey{index}).(context.Context); ok {
+		return traced
+	}
+	return ctx
+}
@@ -0,0 +1,142 @@
+// Package doc serves the swagger UI and the OpenAPI spec of the gateway, embedded in the binary
+package doc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Spec is the OpenAPI spec generated from the proto files by make proto
+//
+//go:embed swagger/swift_bank.swagger.json
+var Spec []byte
+
+//go:embed swagger
+var files embed.FS
+
+const (
+	// SwaggerPath is where the swagger UI is served
+	SwaggerPath = "/swagger/"
+	// SpecPath serves Spec as it is, for tooling
+	SpecPath = SwaggerPath + "doc.json"
+
+	// the UI assets only change with a release, the pages and the spec are checked every time
+	assetCacheControl = "public, max-age=86400"
+	pageCacheControl  = "no-cache"
+)
+
+// asset is an embedded file along with what it is served with
+type asset struct {
+	content     string
+	contentType string
+	etag        string
+}
+
+// Handler serves the swagger UI below SwaggerPath and the spec at SpecPath. Every response
+// carries an ETag, so a client revalidating a page gets a 304 until the next release. When
+// username is set the routes need basic auth with username and password.
+func Handler(username, password string) http.Handler {
+	assets := make(map[string]asset)
+	err := fs.WalkDir(files, "swagger", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		assets[SwaggerPath+strings.TrimPrefix(name, "swagger/")] = newAsset(name, content)
+		return nil
+	})
+	if err != nil {
+		panic(err) // the files are embedded, reading them cannot fail
+	}
+	assets[SpecPath] = newAsset(SpecPath, Spec)
+	assets[SwaggerPath] = assets[SwaggerPath+"index.html"]
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		asset, ok := assets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", asset.contentType)
+		w.Header().Set("ETag", asset.etag)
+		if isPage(r.URL.Path) {
+			w.Header().Set("Cache-Control", pageCacheControl)
+		} else {
+			w.Header().Set("Cache-Control", assetCacheControl)
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(asset.content))
+	})
+
+	if username != "" {
+		handler = basicAuth(username, password, handler)
+	}
+	return handler
+}
+
+func newAsset(name string, content []byte) asset {
+	sum := sha256.Sum256(content)
+	return asset{
+		content:     string(content),
+		contentType: contentType(name),
+		etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+}
+
+// contentType covers the types of the swagger UI files
+func contentType(name string) string {
+	switch path.Ext(name) {
+	case ".html":
+		return "text/html; charset=utf-8"
+	case ".css":
+		return "text/css; charset=utf-8"
+	case ".js":
+		return "text/javascript; charset=utf-8"
+	case ".json", ".map":
+		return "application/json"
+	case ".png":
+		return "image/png"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// isPage tells the paths that change with the spec rather than with the swagger UI version
+func isPage(urlPath string) bool {
+	switch urlPath {
+	case SwaggerPath, SwaggerPath + "index.html", SwaggerPath + "swagger-initializer.js", SpecPath:
+		return true
+	}
+	return strings.HasSuffix(urlPath, ".swagger.json")
+}
+
+func basicAuth(username, password string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="swagger", charset="UTF-8"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,136 @@
+package doc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serve(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHandlerServesEveryFile(t *testing.T) {
+	handler := Handler("", "")
+
+	entries, err := os.ReadDir("swagger")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	for _, entry := range entries {
+		t.Run(entry.Name(), func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("swagger", entry.Name()))
+			require.NoError(t, err)
+
+			recorder := serve(handler, httptest.NewRequest(http.MethodGet, SwaggerPath+entry.Name(), nil))
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, content, recorder.Body.Bytes())
+			require.NotEmpty(t, recorder.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandlerHeaders(t *testing.T) {
+	handler := Handler("", "")
+
+	testCases := []struct {
+		path         string
+		contentType  string
+		cacheControl string
+	}{
+		{path: SwaggerPath, contentType: "text/html; charset=utf-8", cacheControl: pageCacheControl},
+		{path: SwaggerPath + "index.html", contentType: "text/html; charset=utf-8", cacheControl: pageCacheControl},
+		{path: SwaggerPath + "swagger-initializer.js", contentType: "text/javascript; charset=utf-8", cacheControl: pageCacheControl},
+		{path: SwaggerPath + "swift_bank.swagger.json", contentType: "application/json", cacheControl: pageCacheControl},
+		{path: SpecPath, contentType: "application/json", cacheControl: pageCacheControl},
+		{path: SwaggerPath + "swagger-ui-bundle.js", contentType: "text/javascript; charset=utf-8", cacheControl: assetCacheControl},
+		{path: SwaggerPath + "swagger-ui.css", contentType: "text/css; charset=utf-8", cacheControl: assetCacheControl},
+		{path: SwaggerPath + "favicon-32x32.png", contentType: "image/png", cacheControl: assetCacheControl},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			recorder := serve(handler, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, tc.contentType, recorder.Header().Get("Content-Type"))
+			require.Equal(t, tc.cacheControl, recorder.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestHandlerSpec(t *testing.T) {
+	recorder := serve(Handler("", ""), httptest.NewRequest(http.MethodGet, SpecPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, Spec, recorder.Body.Bytes())
+
+	var spec struct {
+		Swagger string                     `json:"swagger"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	require.Equal(t, "2.0", spec.Swagger)
+	require.Contains(t, spec.Paths, "/v1/create_user")
+}
+
+func TestHandlerRevalidation(t *testing.T) {
+	handler := Handler("", "")
+
+	recorder := serve(handler, httptest.NewRequest(http.MethodGet, SpecPath, nil))
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	request := httptest.NewRequest(http.MethodGet, SpecPath, nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder = serve(handler, request)
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Empty(t, recorder.Body.Bytes())
+}
+
+func TestHandlerUnknown(t *testing.T) {
+	handler := Handler("", "")
+
+	recorder := serve(handler, httptest.NewRequest(http.MethodGet, SwaggerPath+"missing.js", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = serve(handler, httptest.NewRequest(http.MethodPost, SpecPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, "GET, HEAD", recorder.Header().Get("Allow"))
+}
+
+func TestHandlerBasicAuth(t *testing.T) {
+	handler := Handler("docs", "secret")
+
+	testCases := []struct {
+		name     string
+		username string
+		password string
+		code     int
+	}{
+		{name: "NoCredentials", code: http.StatusUnauthorized},
+		{name: "WrongPassword", username: "docs", password: "guess", code: http.StatusUnauthorized},
+		{name: "WrongUsername", username: "admin", password: "secret", code: http.StatusUnauthorized},
+		{name: "OK", username: "docs", password: "secret", code: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, SpecPath, nil)
+			if tc.username != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+
+			recorder := serve(handler, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusUnauthorized {
+				require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Basic")
+			}
+		})
+	}
+}
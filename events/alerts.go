@@ -0,0 +1,15 @@
+package events
+
+import (
+	"context"
+
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// TransferAlerter queues alerts about transfers above a per-currency threshold
+type TransferAlerter interface {
+	// LargeTransferThreshold returns the amount, in minor units, above which transfers in currency
+	// are alerted on
+	LargeTransferThreshold(currency string) (int64, bool)
+	AlertLargeTransfer(ctx context.Context, transfer models.Transaction) error
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// Publisher publishes account activity so it can be pushed to connected clients
+type Publisher interface {
+	PublishEntry(ctx context.Context, entry models.Entry) error
+}
+
+// Subscriber delivers account activity published by any replica. The returned
+// channel is closed once ctx is done or the underlying subscription ends.
+type Subscriber interface {
+	SubscribeEntries(ctx context.Context, accountID int64) (<-chan models.Entry, error)
+}
+
+// RedisBroker fans out entries through Redis pub/sub, one channel per account
+type RedisBroker struct {
+	client redis.UniversalClient
+}
+
+func NewRedisBroker(client redis.UniversalClient) *RedisBroker {
+	return &RedisBroker{
+		client: client,
+	}
+}
+
+func entriesChannel(accountID int64) string {
+	return fmt.Sprintf("swift_bank:entries:%d", accountID)
+}
+
+func (b *RedisBroker) PublishEntry(ctx context.Context, entry models.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	if err := b.client.Publish(ctx, entriesChannel(entry.AccountID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish entry: %w", err)
+	}
+
+	return nil
+}
+
+func (b *RedisBroker) SubscribeEntries(ctx context.Context, accountID int64) (<-chan models.Entry, error) {
+	sub := b.client.Subscribe(ctx, entriesChannel(accountID))
+
+	// wait for the subscription to be confirmed so no entry published after we return is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to entries: %w", err)
+	}
+
+	entries := make(chan models.Entry)
+	go func() {
+		defer close(entries)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var entry models.Entry
+				if err := json.Unmarshal([]byte(msg.Payload), &entry); err != nil {
+					log.Error().Err(err).Str("channel", msg.Channel).Msg("failed to unmarshal entry")
+					continue
+				}
+
+				select {
+				case entries <- entry:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+func newTestBroker(t *testing.T) *RedisBroker {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisBroker(client)
+}
+
+func TestPublishSubscribeEntries(t *testing.T) {
+	broker := newTestBroker(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accountID := helpers.RandomInt(1, 1000)
+	entries, err := broker.SubscribeEntries(ctx, accountID)
+	require.NoError(t, err)
+
+	// an entry for another account must not be delivered
+	require.NoError(t, broker.PublishEntry(ctx, models.Entry{ID: 1, AccountID: accountID + 1, Amount: 5}))
+
+	entry := models.Entry{ID: 2, AccountID: accountID, Amount: 10, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, broker.PublishEntry(ctx, entry))
+
+	select {
+	case got := <-entries:
+		require.Equal(t, entry, got)
+	case <-time.After(time.Second):
+		t.Fatal("entry was not delivered")
+	}
+}
+
+func TestSubscribeEntriesClosesOnCancel(t *testing.T) {
+	broker := newTestBroker(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, err := broker.SubscribeEntries(ctx, 1)
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-entries:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed after cancel")
+	}
+}
@@ -0,0 +1,12 @@
+package events
+
+import (
+	"context"
+
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// WebhookDispatcher queues an event for delivery to one webhook subscription
+type WebhookDispatcher interface {
+	DispatchWebhook(ctx context.Context, webhookID int64, event models.WebhookEvent) error
+}
@@ -0,0 +1,72 @@
+// Package export writes the transaction history of an account as CSV, for both the gateway's
+// streamed download and the emailed export
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+// BOM starts every export so spreadsheet apps read it as UTF-8 rather than in the local code page
+const BOM = "\ufeff"
+
+var header = []string{"date", "type", "counterparty", "amount", "currency", "running balance", "memo"}
+
+// CSVWriter writes ledger rows as CSV. Rows go through a small buffer, call Flush to hand what was
+// written to the underlying writer.
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter writes the BOM and the header row to w
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	if _, err := io.WriteString(w, BOM); err != nil {
+		return nil, err
+	}
+
+	cw := &CSVWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (cw *CSVWriter) Write(row models.LedgerRow) error {
+	counterparty := ""
+	if row.Counterparty != 0 {
+		counterparty = strconv.FormatInt(row.Counterparty, 10)
+	}
+
+	return cw.w.Write([]string{
+		row.Date.UTC().Format(time.RFC3339),
+		row.Type,
+		counterparty,
+		money.New(row.Amount, row.Currency).String(),
+		row.Currency,
+		money.New(row.Balance, row.Currency).String(),
+		sanitizeMemo(row.Memo),
+	})
+}
+
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// sanitizeMemo drops stray BOMs, which would otherwise show up as an invisible character in the
+// cell, and keeps spreadsheet apps from evaluating a memo that looks like a formula
+func sanitizeMemo(memo string) string {
+	memo = strings.ReplaceAll(memo, BOM, "")
+	if memo != "" && strings.ContainsRune("=+-@\t\r", rune(memo[0])) {
+		return "'" + memo
+	}
+
+	return memo
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+func writeRows(t *testing.T, rows ...models.LedgerRow) string {
+	var buf bytes.Buffer
+	cw, err := NewCSVWriter(&buf)
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		require.NoError(t, cw.Write(row))
+	}
+	require.NoError(t, cw.Flush())
+
+	return buf.String()
+}
+
+func readRecords(t *testing.T, data string) [][]string {
+	require.True(t, strings.HasPrefix(data, BOM))
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, BOM))).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestCSVWriterHeaderOnly(t *testing.T) {
+	data := writeRows(t)
+	require.Equal(t, BOM+"date,type,counterparty,amount,currency,running balance,memo\n", data)
+}
+
+func TestCSVWriterRows(t *testing.T) {
+	date := time.Date(2024, 3, 5, 14, 30, 0, 0, time.FixedZone("CET", 3600))
+	data := writeRows(t,
+		models.LedgerRow{Date: date, Type: models.LedgerRowDeposit, Amount: 10000, Currency: "EUR", Balance: 10000},
+		models.LedgerRow{Date: date, Type: models.LedgerRowTransferOut, Counterparty: 42, Amount: -2550, Currency: "EUR", Balance: 7450, Memo: "rent"},
+	)
+
+	records := readRecords(t, data)
+	require.Equal(t, [][]string{
+		header,
+		{"2024-03-05T13:30:00Z", "deposit", "", "100.00", "EUR", "100.00", ""},
+		{"2024-03-05T13:30:00Z", "transfer_out", "42", "-25.50", "EUR", "74.50", "rent"},
+	}, records)
+}
+
+func TestCSVWriterQuoting(t *testing.T) {
+	memos := []string{
+		`dinner, drinks`,
+		`the "good" wine`,
+		"first line\nsecond line",
+		"café ünïcode ✓",
+	}
+
+	var rows []models.LedgerRow
+	for _, memo := range memos {
+		rows = append(rows, models.LedgerRow{Type: models.LedgerRowTransferIn, Memo: memo})
+	}
+	data := writeRows(t, rows...)
+
+	require.Contains(t, data, `"dinner, drinks"`)
+	require.Contains(t, data, `"the ""good"" wine"`)
+	require.Contains(t, data, "\"first line\nsecond line\"")
+
+	records := readRecords(t, data)
+	require.Len(t, records, len(memos)+1)
+	for i, memo := range memos {
+		require.Equal(t, memo, records[i+1][6])
+	}
+}
+
+func TestCSVWriterBOM(t *testing.T) {
+	// a BOM that made it into a memo is dropped, so the file only has the leading one
+	data := writeRows(t, models.LedgerRow{Memo: BOM + "refund" + BOM})
+	require.Equal(t, 1, strings.Count(data, BOM))
+	require.True(t, strings.HasPrefix(data, BOM+"date,"))
+
+	records := readRecords(t, data)
+	require.Equal(t, "refund", records[1][6])
+}
+
+func TestCSVWriterFormulaMemo(t *testing.T) {
+	testCases := []struct {
+		memo string
+		want string
+	}{
+		{memo: "=HYPERLINK(\"http://example.com\")", want: "'=HYPERLINK(\"http://example.com\")"},
+		{memo: "+1 for lunch", want: "'+1 for lunch"},
+		{memo: "-5 discount", want: "'-5 discount"},
+		{memo: "@everyone", want: "'@everyone"},
+		{memo: "lunch = 5", want: "lunch = 5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.memo, func(t *testing.T) {
+			records := readRecords(t, writeRows(t, models.LedgerRow{Amount: -500, Currency: "USD", Memo: tc.memo}))
+			require.Equal(t, tc.want, records[1][6])
+			require.Equal(t, "-5.00", records[1][3]) // amounts are left alone
+		})
+	}
+}
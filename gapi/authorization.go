@@ -2,11 +2,19 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
 	"github.com/zde37/Swift_Bank/token"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -14,18 +22,118 @@ const (
 	authorizationBearer = "bearer"
 )
 
-func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
+var (
+	anyUser    = []string{helpers.BankerRole, helpers.DepositorRole}
+	bankerOnly = []string{helpers.BankerRole}
+)
+
+// methodRoles lists the roles allowed to call each RPC, no roles makes it public. RPCs missing
+// from it cannot be called at all, so a new one has to be added here before it can be used.
+var methodRoles = map[string][]string{
+	pb.SwiftBank_CreateUser_FullMethodName:              nil,
+	pb.SwiftBank_LoginUser_FullMethodName:               nil,
+	pb.SwiftBank_VerifyEmail_FullMethodName:             nil,
+	pb.SwiftBank_ResendVerificationEmail_FullMethodName: nil,
+
+	pb.SwiftBank_UpdateUser_FullMethodName:                    anyUser,
+	pb.SwiftBank_VerifyPhone_FullMethodName:                   anyUser,
+	pb.SwiftBank_GetNotificationPreferences_FullMethodName:    anyUser,
+	pb.SwiftBank_UpdateNotificationPreferences_FullMethodName: anyUser,
+	pb.SwiftBank_ListNotifications_FullMethodName:             anyUser,
+	pb.SwiftBank_MarkNotificationRead_FullMethodName:          anyUser,
+	pb.SwiftBank_UnreadNotificationCount_FullMethodName:       anyUser,
+	pb.SwiftBank_GetStatement_FullMethodName:                  anyUser,
+	pb.SwiftBank_RequestStatement_FullMethodName:              anyUser,
+	pb.SwiftBank_ListStatements_FullMethodName:                anyUser,
+	pb.SwiftBank_TransferMoney_FullMethodName:                 anyUser,
+	pb.SwiftBank_GetTransfer_FullMethodName:                   anyUser,
+	pb.SwiftBank_ListTransfers_FullMethodName:                 anyUser,
+	pb.SwiftBank_ScheduleTransfer_FullMethodName:              anyUser,
+	pb.SwiftBank_CancelScheduledTransfer_FullMethodName:       anyUser,
+	pb.SwiftBank_CreateStandingOrder_FullMethodName:           anyUser,
+	pb.SwiftBank_ListStandingOrders_FullMethodName:            anyUser,
+	pb.SwiftBank_PauseStandingOrder_FullMethodName:            anyUser,
+	pb.SwiftBank_ResumeStandingOrder_FullMethodName:           anyUser,
+	pb.SwiftBank_DeleteStandingOrder_FullMethodName:           anyUser,
+	pb.SwiftBank_AddBeneficiary_FullMethodName:                anyUser,
+	pb.SwiftBank_ListBeneficiaries_FullMethodName:             anyUser,
+	pb.SwiftBank_DeleteBeneficiary_FullMethodName:             anyUser,
+	pb.SwiftBank_CreateWebhook_FullMethodName:                 anyUser,
+	pb.SwiftBank_ListWebhooks_FullMethodName:                  anyUser,
+	pb.SwiftBank_DeleteWebhook_FullMethodName:                 anyUser,
+	pb.SwiftBank_TestWebhook_FullMethodName:                   anyUser,
+	pb.SwiftBank_CreateAccount_FullMethodName:                 anyUser,
+	pb.SwiftBank_UpdateAccount_FullMethodName:                 anyUser,
+	pb.SwiftBank_SetTransactionCategory_FullMethodName:        anyUser,
+	pb.SwiftBank_GetBalance_FullMethodName:                    anyUser,
+	pb.SwiftBank_GetAccountSummary_FullMethodName:             anyUser,
+	pb.SwiftBank_StreamTransactions_FullMethodName:            anyUser,
+	pb.SwiftBank_SearchTransactions_FullMethodName:            anyUser,
+	pb.SwiftBank_ExportTransactions_FullMethodName:            anyUser,
+
+	pb.SwiftBank_ReverseTransfer_FullMethodName:    bankerOnly,
+	pb.SwiftBank_SetOverdraftLimit_FullMethodName:  bankerOnly,
+	pb.SwiftBank_BulkCreateAccounts_FullMethodName: bankerOnly,
+	pb.SwiftBank_ListFailedTasks_FullMethodName:    bankerOnly,
+	pb.SwiftBank_RetryFailedTask_FullMethodName:    bankerOnly,
+	pb.SwiftBank_ListAuditLogs_FullMethodName:      bankerOnly,
+	pb.SwiftBank_AddCurrency_FullMethodName:        bankerOnly,
+	pb.SwiftBank_DisableCurrency_FullMethodName:    bankerOnly,
+	pb.SwiftBank_SetLogLevel_FullMethodName:        bankerOnly,
+	pb.SwiftBank_MigrationStatus_FullMethodName:    bankerOnly,
+	pb.SwiftBank_ServerInfo_FullMethodName:         bankerOnly,
+}
+
+// swiftBankMethodPrefix starts the full name of every RPC of the banking service. Other services
+// on the same server, like health checks and reflection, are not guarded by methodRoles.
+var swiftBankMethodPrefix = "/" + pb.SwiftBank_ServiceDesc.ServiceName + "/"
+
+type authPayloadKey struct{}
+
+// authPayloadFromContext returns the access token payload of the caller, put in the context by
+// the auth interceptor for every RPC that is not public
+func authPayloadFromContext(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, fmt.Errorf("missing access token")
+	}
+
+	return payload, nil
+}
+
+// authorize makes sure the caller may call method, and returns ctx with their token payload.
+// The returned error is already a gRPC status.
+func (s *Server) authorize(ctx context.Context, method, authHeader string) (context.Context, error) {
+	if !strings.HasPrefix(method, swiftBankMethodPrefix) {
+		return ctx, nil
+	}
+
+	accessibleRoles, ok := methodRoles[method]
 	if !ok {
-		return nil, fmt.Errorf("missing metadata")
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not open to any role", method)
+	}
+
+	if len(accessibleRoles) == 0 {
+		return ctx, nil
 	}
 
-	values := md.Get(authorizationHeader) // returns an array
-	if len(values) == 0 {
+	payload, err := s.verifyAccessToken(authHeader)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if !hasPermission(payload.Role, accessibleRoles) {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+
+	return context.WithValue(ctx, authPayloadKey{}, payload), nil
+}
+
+func (s *Server) verifyAccessToken(authHeader string) (*token.Payload, error) {
+	if authHeader == "" {
 		return nil, fmt.Errorf("missing authorization header")
 	}
 
-	authHeader := values[0]              // the header is the first value of the array
 	fields := strings.Fields(authHeader) // split the header i.e Bearer 23443n
 	if len(fields) < 2 {
 		return nil, fmt.Errorf("invalid authorization header format")
@@ -39,8 +147,138 @@ func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
 	accessToken := fields[1]
 	payload, err := s.tokenMaker.VerifyToken(accessToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid access token: %s", accessToken)
+		return nil, fmt.Errorf("invalid access token: %w", err) // the token itself must not end up in the logs
 	}
 
 	return payload, nil
 }
+
+// GrpcAuth verifies the access token once for every RPC, ahead of the handler
+func (s *Server) GrpcAuth(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	ctx, err = s.authorize(ctx, info.FullMethod, incomingHeader(ctx, authorizationHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) GrpcStreamAuth(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := s.authorize(stream.Context(), info.FullMethod, incomingHeader(stream.Context(), authorizationHeader))
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// HttpAuth does the same for the gateway, whose requests skip the gRPC interceptors. Requests
+// that are not for an RPC are left to the mux.
+func (s *Server) HttpAuth(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := rpcMethodForRequest(r)
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, err := s.authorize(r.Context(), method, r.Header.Get(authorizationHeader))
+		if err != nil {
+			writeHTTPError(w, r, err)
+			return
+		}
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func hasPermission(userRole string, accessibleRoles []string) bool {
+	for _, role := range accessibleRoles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeAccount loads the account and makes sure it belongs to the authenticated user.
+// The returned error is already a gRPC status.
+func (s *Server) authorizeAccount(ctx context.Context, payload *token.Payload, accountID int64) (models.Account, error) {
+	account, err := s.service.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Account{}, status.Errorf(codes.NotFound, "account not found")
+		}
+		return models.Account{}, dbErrorToStatus(fmt.Errorf("failed to get account: %w", err))
+	}
+
+	if account.Owner != payload.UserName {
+		return models.Account{}, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	return account, nil
+}
+
+// authorizeStandingOrder loads the standing order and makes sure it belongs to the authenticated user.
+// The returned error is already a gRPC status.
+func (s *Server) authorizeStandingOrder(ctx context.Context, payload *token.Payload, id int64) (models.StandingOrder, error) {
+	order, err := s.service.GetStandingOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.StandingOrder{}, status.Errorf(codes.NotFound, "standing order not found")
+		}
+		return models.StandingOrder{}, dbErrorToStatus(fmt.Errorf("failed to get standing order: %w", err))
+	}
+
+	if order.Owner != payload.UserName {
+		return models.StandingOrder{}, status.Errorf(codes.PermissionDenied, "standing order does not belong to the authenticated user")
+	}
+
+	return order, nil
+}
+
+// authorizeBeneficiary loads the beneficiary and makes sure it was saved by the authenticated user.
+// The returned error is already a gRPC status.
+func (s *Server) authorizeBeneficiary(ctx context.Context, payload *token.Payload, id int64) (models.Beneficiary, error) {
+	beneficiary, err := s.service.GetBeneficiary(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Beneficiary{}, status.Errorf(codes.NotFound, "beneficiary not found")
+		}
+		return models.Beneficiary{}, dbErrorToStatus(fmt.Errorf("failed to get beneficiary: %w", err))
+	}
+
+	if beneficiary.Owner != payload.UserName {
+		return models.Beneficiary{}, status.Errorf(codes.PermissionDenied, "beneficiary does not belong to the authenticated user")
+	}
+
+	return beneficiary, nil
+}
+
+// authorizeWebhook loads the webhook and makes sure it was created by the authenticated user.
+// The returned error is already a gRPC status.
+func (s *Server) authorizeWebhook(ctx context.Context, payload *token.Payload, id int64) (models.Webhook, error) {
+	webhook, err := s.service.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Webhook{}, status.Errorf(codes.NotFound, "webhook not found")
+		}
+		return models.Webhook{}, dbErrorToStatus(fmt.Errorf("failed to get webhook: %w", err))
+	}
+
+	if webhook.Owner != payload.UserName {
+		return models.Webhook{}, status.Errorf(codes.PermissionDenied, "webhook does not belong to the authenticated user")
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,150 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodRolesCoverEveryRPC(t *testing.T) {
+	var methods []string
+	for _, method := range pb.SwiftBank_ServiceDesc.Methods {
+		methods = append(methods, swiftBankMethodPrefix+method.MethodName)
+	}
+	for _, stream := range pb.SwiftBank_ServiceDesc.Streams {
+		methods = append(methods, swiftBankMethodPrefix+stream.StreamName)
+	}
+
+	for _, method := range methods {
+		require.Contains(t, methodRoles, method, "%s is denied to everyone", method)
+	}
+	require.Len(t, methodRoles, len(methods))
+}
+
+func TestGrpcAuth(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	withToken := func(username, role string) context.Context {
+		accessToken, _, err := server.tokenMaker.CreateToken(username, role, time.Minute)
+		require.NoError(t, err)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer "+accessToken))
+	}
+
+	testCases := []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		code       codes.Code
+		wantCaller string // username the handler sees, empty for none
+	}{
+		{
+			name:   "Public",
+			ctx:    context.Background(),
+			method: pb.SwiftBank_LoginUser_FullMethodName,
+			code:   codes.OK,
+		},
+		{
+			name:       "Authorized",
+			ctx:        withToken("ada", helpers.DepositorRole),
+			method:     pb.SwiftBank_GetBalance_FullMethodName,
+			code:       codes.OK,
+			wantCaller: "ada",
+		},
+		{
+			name:   "NoToken",
+			ctx:    context.Background(),
+			method: pb.SwiftBank_GetBalance_FullMethodName,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "InvalidToken",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer nope")),
+			method: pb.SwiftBank_GetBalance_FullMethodName,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "WrongRole",
+			ctx:    withToken("ada", helpers.DepositorRole),
+			method: pb.SwiftBank_ReverseTransfer_FullMethodName,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "UnlistedMethod",
+			ctx:    withToken("ada", helpers.BankerRole),
+			method: "/pb.SwiftBank/DropAllAccounts",
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "OtherService",
+			ctx:    context.Background(),
+			method: "/grpc.health.v1.Health/Check",
+			code:   codes.OK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			_, err := server.GrpcAuth(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, req any) (any, error) {
+				called = true
+
+				payload, err := authPayloadFromContext(ctx)
+				if tc.wantCaller == "" {
+					require.Error(t, err)
+					return nil, nil
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tc.wantCaller, payload.UserName)
+				return nil, nil
+			})
+
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, tc.code == codes.OK, called)
+		})
+	}
+}
+
+func TestHttpAuth(t *testing.T) {
+	server := newTestServer(t, nil)
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	var caller *token.Payload
+	handler := server.HttpAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, _ = authPayloadFromContext(r.Context())
+	}))
+
+	serve := func(method, target, authHeader string) int {
+		caller = nil
+		request := httptest.NewRequest(method, target, nil)
+		if authHeader != "" {
+			request.Header.Set("Authorization", authHeader)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/accounts/1/balance", "Bearer "+accessToken))
+	require.Equal(t, "ada", caller.UserName)
+
+	require.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/v1/accounts/1/balance", ""))
+	require.Equal(t, http.StatusForbidden, serve(http.MethodPatch, "/v1/accounts/1/overdraft_limit", "Bearer "+accessToken))
+
+	// public RPCs and anything that is not an RPC go through without a token
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/v1/login_user", ""))
+	require.Nil(t, caller)
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/swagger/index.html", ""))
+}
@@ -0,0 +1,186 @@
+package gapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+)
+
+// startTestGateway serves the gateway with the marshalers and compression used in main
+func startTestGateway(t *testing.T, server *Server) *httptest.Server {
+	grpcMux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(MIMEProtobuf, &ProtoMarshaler{}),
+	)
+	conn := NewInProcessConn(server, server.GrpcValidate)
+	require.NoError(t, pb.RegisterSwiftBankHandlerClient(context.Background(), grpcMux, pb.NewSwiftBankClient(conn)))
+
+	mux := http.NewServeMux()
+	mux.Handle("/", grpcMux)
+	mux.Handle("/swagger/logo.png", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "\x89PNG\r\n\x1a\n")
+	}))
+
+	gateway := httptest.NewServer(HttpCompression(server.HttpAuth(mux)))
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func listBeneficiaries(t *testing.T, gateway *httptest.Server, accessToken string, header http.Header) *http.Response {
+	request, err := http.NewRequest(http.MethodGet, gateway.URL+"/v1/beneficiaries", nil)
+	require.NoError(t, err)
+	request.Header = header
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// keep the transport from asking for and decoding gzip itself
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestHttpCompression(t *testing.T) {
+	beneficiaries := make([]models.Beneficiary, 20)
+	for i := range beneficiaries {
+		beneficiaries[i] = models.Beneficiary{
+			ID:        int64(i + 1),
+			Owner:     "ada",
+			AccountID: int64(i + 100),
+			Nickname:  helpers.RandomOwner(),
+			CreatedAt: time.Now(),
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().ListBeneficiaries(gomock.Any(), gomock.Any()).AnyTimes().Return(beneficiaries, nil)
+
+	server := newTestServer(t, service)
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	gateway := startTestGateway(t, server)
+
+	plain := listBeneficiaries(t, gateway, accessToken, http.Header{})
+	require.Equal(t, http.StatusOK, plain.StatusCode)
+	require.Empty(t, plain.Header.Get("Content-Encoding"))
+	require.Equal(t, "application/json", plain.Header.Get("Content-Type"))
+	plainBody, err := io.ReadAll(plain.Body)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(len(plainBody)), plain.Header.Get("Content-Length"))
+
+	compressed := listBeneficiaries(t, gateway, accessToken, http.Header{"Accept-Encoding": {"gzip, deflate"}})
+	require.Equal(t, http.StatusOK, compressed.StatusCode)
+	require.Equal(t, "gzip", compressed.Header.Get("Content-Encoding"))
+	require.Equal(t, "application/json", compressed.Header.Get("Content-Type"))
+	require.Equal(t, "Accept-Encoding", compressed.Header.Get("Vary"))
+	compressedBody, err := io.ReadAll(compressed.Body)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(len(compressedBody)), compressed.Header.Get("Content-Length"))
+	require.Less(t, len(compressedBody), len(plainBody))
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressedBody))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, plainBody, decompressed)
+
+	// a client can refuse gzip
+	refused := listBeneficiaries(t, gateway, accessToken, http.Header{"Accept-Encoding": {"gzip;q=0"}})
+	require.Empty(t, refused.Header.Get("Content-Encoding"))
+
+	// images are already compressed
+	request, err := http.NewRequest(http.MethodGet, gateway.URL+"/swagger/logo.png", nil)
+	require.NoError(t, err)
+	request.Header.Set("Accept-Encoding", "gzip")
+	image, err := (&http.Client{Transport: &http.Transport{DisableCompression: true}}).Do(request)
+	require.NoError(t, err)
+	defer image.Body.Close()
+	require.Empty(t, image.Header.Get("Content-Encoding"))
+	require.Equal(t, "image/png", image.Header.Get("Content-Type"))
+}
+
+func TestHttpProtobufNegotiation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		ListBeneficiaries(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return([]models.Beneficiary{{ID: 1, Owner: "ada", AccountID: 7, Nickname: "rent"}}, nil)
+
+	server := newTestServer(t, service)
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	gateway := startTestGateway(t, server)
+
+	for _, header := range []http.Header{
+		{"Accept": {MIMEProtobuf}},
+		{"Accept": {MIMEProtobuf}, "Accept-Encoding": {"gzip"}},
+	} {
+		response := listBeneficiaries(t, gateway, accessToken, header)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, MIMEProtobuf, response.Header.Get("Content-Type"))
+
+		var body io.Reader = response.Body
+		if response.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(response.Body)
+			require.NoError(t, err)
+			body = reader
+		} else {
+			require.NotEmpty(t, response.Header.Get("Content-Length"))
+		}
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		var rsp pb.ListBeneficiariesResponse
+		require.NoError(t, proto.Unmarshal(data, &rsp))
+		require.Len(t, rsp.GetBeneficiaries(), 1)
+		require.Equal(t, "rent", rsp.GetBeneficiaries()[0].GetNickname())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	require.True(t, acceptsGzip("gzip"))
+	require.True(t, acceptsGzip("deflate, GZIP;q=0.5"))
+	require.True(t, acceptsGzip("*"))
+	require.False(t, acceptsGzip(""))
+	require.False(t, acceptsGzip("br, deflate"))
+	require.False(t, acceptsGzip("gzip;q=0"))
+}
+
+func TestHttpLoggerRecordsCompressedSize(t *testing.T) {
+	logs := captureLogs(t)
+	body := strings.Repeat(`{"nickname":"rent"}`, 100)
+
+	handler := RequestLogger{}.HttpLogger(HttpCompression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	})))
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/beneficiaries", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	require.Less(t, recorder.Body.Len(), len(body))
+	require.Contains(t, logs.String(), fmt.Sprintf(`"response_size":%d`, recorder.Body.Len()))
+}
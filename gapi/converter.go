@@ -1,11 +1,28 @@
 package gapi
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
 	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// amountUnits returns the minor units of a decimal amount of currency. The validators of a request
+// have accepted the amount already, the error is for what they and money still disagree on.
+func amountUnits(value, currency string) (int64, error) {
+	amount, err := money.FromDecimalString(value, currency)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Units, nil
+}
+
 func convertUser(user models.User) *pb.User {
 	return &pb.User{
 		Username:          user.UserName,
@@ -13,5 +30,255 @@ func convertUser(user models.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		PhoneNumber:       user.PhoneNumber,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	}
+}
+
+func convertAccount(account models.Account) *pb.Account {
+	return &pb.Account{
+		Id:              account.ID,
+		Owner:           account.Owner,
+		Balance:         money.New(account.Balance, account.Currency).String(),
+		Currency:        account.Currency,
+		CreatedAt:       timestamppb.New(account.CreatedAt),
+		OverdraftLimit:  money.New(account.OverdraftLimit, account.Currency).String(),
+		Nickname:        account.Nickname,
+		Metadata:        account.Metadata,
+		AccountType:     account.Type,
+		AccruedInterest: money.New(account.AccruedInterest, account.Currency).String(),
+	}
+}
+
+func convertTransfer(transfer models.Transaction) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, transfer.Currency).String(),
+		Fee:           money.New(transfer.Fee, transfer.Currency).String(),
+		Currency:      transfer.Currency,
+		Description:   transfer.Description,
+		ToAmount:      money.New(transfer.ToAmount, transfer.ToCurrency).String(),
+		ToCurrency:    transfer.ToCurrency,
+		ExchangeRate:  transfer.ExchangeRate,
+		ReversalOf:    transfer.ReversalOf,
+		ReversedBy:    transfer.ReversedBy,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		Category:      transfer.Category,
+		Tags:          transfer.Tags,
+	}
+}
+
+// convertEntry needs the currency of the entry's account, entries don't carry it
+func convertEntry(entry models.Entry, currency string) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    money.New(entry.Amount, currency).String(),
+		Currency:  currency,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+		Category:  entry.Category,
+		Tags:      entry.Tags,
+	}
+}
+
+func convertStatement(st models.Statement) *pb.GetStatementResponse {
+	rsp := &pb.GetStatementResponse{
+		Account:        convertAccount(st.Account),
+		Year:           int32(st.Year),
+		Month:          int32(st.Month),
+		OpeningBalance: money.New(st.OpeningBalance, st.Account.Currency).String(),
+		ClosingBalance: money.New(st.ClosingBalance, st.Account.Currency).String(),
+	}
+
+	for _, row := range st.Rows {
+		rsp.Rows = append(rsp.Rows, &pb.StatementRow{
+			EntryId:   row.EntryID,
+			Amount:    money.New(row.Amount, st.Account.Currency).String(),
+			Balance:   money.New(row.Balance, st.Account.Currency).String(),
+			CreatedAt: timestamppb.New(row.CreatedAt),
+			Category:  row.Category,
+		})
 	}
+
+	rsp.Categories = convertCategoryTotals(st.Categories, st.Account.Currency)
+
+	for _, transfer := range st.Transfers {
+		rsp.Transfers = append(rsp.Transfers, convertTransfer(transfer))
+	}
+
+	return rsp
+}
+
+func convertCategoryTotals(totals []models.CategoryTotal, currency string) []*pb.CategoryTotal {
+	var categories []*pb.CategoryTotal
+	for _, total := range totals {
+		categories = append(categories, &pb.CategoryTotal{
+			Category: total.Category,
+			In:       money.New(total.In, currency).String(),
+			Out:      money.New(total.Out, currency).String(),
+			Count:    int32(total.Count),
+		})
+	}
+
+	return categories
+}
+
+func convertScheduledTransfer(transfer models.ScheduledTransfer) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, transfer.Currency).String(),
+		Currency:      transfer.Currency,
+		Description:   transfer.Description,
+		ExecuteAt:     timestamppb.New(transfer.ExecuteAt),
+		Status:        transfer.Status,
+		FailureReason: transfer.FailureReason,
+		TransactionId: transfer.TransactionID,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertStandingOrder(order models.StandingOrder) *pb.StandingOrder {
+	rsp := &pb.StandingOrder{
+		Id:                  order.ID,
+		FromAccountId:       order.FromAccountID,
+		ToAccountId:         order.ToAccountID,
+		Amount:              money.New(order.Amount, order.Currency).String(),
+		Currency:            order.Currency,
+		Description:         order.Description,
+		Frequency:           order.Frequency,
+		StartDate:           timestamppb.New(order.StartDate),
+		NextRunAt:           timestamppb.New(order.NextRunAt),
+		Status:              order.Status,
+		ConsecutiveFailures: order.ConsecutiveFailures,
+		CreatedAt:           timestamppb.New(order.CreatedAt),
+	}
+	if order.EndDate != nil {
+		rsp.EndDate = timestamppb.New(*order.EndDate)
+	}
+
+	return rsp
+}
+
+func convertBeneficiary(beneficiary models.Beneficiary) *pb.Beneficiary {
+	return &pb.Beneficiary{
+		Id:        beneficiary.ID,
+		AccountId: beneficiary.AccountID,
+		Nickname:  beneficiary.Nickname,
+		Iban:      beneficiary.IBAN,
+		Bic:       beneficiary.BIC,
+		CreatedAt: timestamppb.New(beneficiary.CreatedAt),
+	}
+}
+
+func convertWebhook(webhook models.Webhook) *pb.Webhook {
+	return &pb.Webhook{
+		Id:                  webhook.ID,
+		Url:                 webhook.URL,
+		Events:              webhook.Events,
+		Active:              webhook.Active,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		CreatedAt:           timestamppb.New(webhook.CreatedAt),
+	}
+}
+
+func convertCurrency(currency models.Currency) *pb.Currency {
+	return &pb.Currency{
+		Code:              currency.Code,
+		Name:              currency.Name,
+		MinorUnitExponent: currency.MinorUnitExponent,
+		Enabled:           currency.Enabled,
+		CreatedAt:         timestamppb.New(currency.CreatedAt),
+	}
+}
+
+func convertNotificationPreferences(preferences models.NotificationPreferences) *pb.NotificationPreferences {
+	return &pb.NotificationPreferences{
+		EmailTransfers:  preferences.EmailTransfers,
+		EmailLogins:     preferences.EmailLogins,
+		EmailStatements: preferences.EmailStatements,
+		UpdatedAt:       timestamppb.New(preferences.UpdatedAt),
+		PreferSms:       preferences.PreferSMS,
+	}
+}
+
+func convertNotification(notification models.Notification) *pb.Notification {
+	rsp := &pb.Notification{
+		Id:        notification.ID,
+		Kind:      notification.Kind,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Read:      notification.ReadAt != nil,
+		CreatedAt: timestamppb.New(notification.CreatedAt),
+	}
+	if notification.ReadAt != nil {
+		rsp.ReadAt = timestamppb.New(*notification.ReadAt)
+	}
+
+	return rsp
+}
+
+func convertFailedTask(task worker.FailedTask) *pb.FailedTask {
+	return &pb.FailedTask{
+		Id:           task.ID,
+		Queue:        task.Queue,
+		Type:         task.Type,
+		Payload:      task.Payload,
+		LastError:    task.LastError,
+		LastFailedAt: timestamppb.New(task.LastFailedAt),
+		Retried:      int32(task.Retried),
+		MaxRetry:     int32(task.MaxRetry),
+	}
+}
+
+// convertStatementDocument converts a statement, with its download link when there is one
+func convertStatementDocument(document models.StatementDocument, url string, expiresAt time.Time) *pb.StatementDocument {
+	rsp := &pb.StatementDocument{
+		Id:            document.ID,
+		AccountId:     document.AccountID,
+		Year:          int32(document.Year),
+		Month:         int32(document.Month),
+		Status:        document.Status,
+		FailureReason: document.FailureReason,
+		CreatedAt:     timestamppb.New(document.CreatedAt),
+		UpdatedAt:     timestamppb.New(document.UpdatedAt),
+	}
+
+	if url != "" {
+		rsp.DownloadUrl = url
+		rsp.DownloadUrlExpiresAt = timestamppb.New(expiresAt)
+	}
+
+	return rsp
+}
+
+func convertAuditLog(log models.AuditLog) *pb.AuditLog {
+	return &pb.AuditLog{
+		Id:        log.ID,
+		Actor:     log.Actor,
+		Action:    log.Action,
+		Entity:    log.Entity,
+		EntityId:  log.EntityID,
+		Before:    convertSnapshot(log.Before),
+		After:     convertSnapshot(log.After),
+		RequestId: log.RequestID,
+		CreatedAt: timestamppb.New(log.CreatedAt),
+	}
+}
+
+// convertSnapshot turns a JSON snapshot of the audit log into a value, nil when there is none
+func convertSnapshot(snapshot json.RawMessage) *structpb.Value {
+	if snapshot == nil {
+		return nil
+	}
+
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(snapshot, value); err != nil {
+		return nil
+	}
+
+	return value
 }
@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/pb"
+)
+
+func TestAmountUnits(t *testing.T) {
+	units, err := amountUnits("12.34", helpers.USD)
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), units)
+
+	// an amount money can't take is an error, not zero
+	_, err = amountUnits("1.234", helpers.USD)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+	_, err = amountUnits("1.00", "XYZ")
+	require.ErrorIs(t, err, money.ErrUnknownCurrency)
+}
+
+func TestStatementFromResponseBadAmount(t *testing.T) {
+	rsp := &pb.GetStatementResponse{
+		Account:        &pb.Account{Currency: helpers.USD, Balance: "10.00"},
+		OpeningBalance: "1.005",
+		ClosingBalance: "10.00",
+	}
+
+	_, err := statementFromResponse(rsp)
+	require.ErrorIs(t, err, money.ErrTooPrecise)
+
+	_, err = NewCSVMarshaler(&ProtoMarshaler{}).Marshal(rsp)
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE"
+	corsMaxAge       = "600" // seconds a browser may cache a preflight answer
+)
+
+var (
+	corsAllowHeaders  = strings.Join([]string{"Authorization", "Content-Type", idempotencyKeyHeader, requestid.Header}, ", ")
+	corsExposeHeaders = requestid.Header
+)
+
+// CORS lets browser apps on the allowed origins call the gateway. Only a literal "*" in the list
+// allows every origin.
+type CORS struct {
+	allowAny bool
+	origins  map[string]bool
+}
+
+// NewCORS parses a comma separated list of origins like "https://app.swiftbank.com"
+func NewCORS(allowedOrigins string) (*CORS, error) {
+	cors := &CORS{origins: map[string]bool{}}
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "":
+			continue
+		case origin == "*":
+			cors.allowAny = true
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid CORS origin %q, must be like https://app.example.com", origin)
+		}
+		cors.origins[u.Scheme+"://"+u.Host] = true
+	}
+
+	return cors, nil
+}
+
+// Enabled reports whether any origin is allowed
+func (c *CORS) Enabled() bool {
+	return c.allowAny || len(c.origins) > 0
+}
+
+func (c *CORS) allowed(origin string) bool {
+	return c.allowAny || c.origins[origin]
+}
+
+// isPreflight reports whether r is a browser asking whether it may send a cross-origin request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// Handler adds the CORS headers for allowed origins and answers preflight requests itself, so
+// they never reach the auth middleware or the gateway mux
+func (c *CORS) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		allowed := c.allowed(origin)
+
+		if isPreflight(r) {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+			header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			header.Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
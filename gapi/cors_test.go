@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+func newTestCORSHandler(t *testing.T, allowedOrigins string) (http.Handler, *Server, *bool) {
+	cors, err := NewCORS(allowedOrigins)
+	require.NoError(t, err)
+
+	server := newTestServer(t, nil)
+	reached := new(bool)
+	handler := HttpRequestID(RequestLogger{}.HttpLogger(cors.Handler(server.HttpAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*reached = true
+	})))))
+	return handler, server, reached
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	handler, server, reached := newTestCORSHandler(t, "https://app.swiftbank.com, http://localhost:3000")
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/accounts/1/balance", nil)
+	request.Header.Set("Origin", "http://localhost:3000")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.True(t, *reached)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "http://localhost:3000", recorder.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, requestid.Header, recorder.Header().Get("Access-Control-Expose-Headers"))
+	require.Contains(t, recorder.Header().Values("Vary"), "Origin")
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	handler, _, reached := newTestCORSHandler(t, "https://app.swiftbank.com")
+
+	// the request itself is served, the browser withholds the response from the page
+	request := httptest.NewRequest(http.MethodPost, "/v1/login_user", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.True(t, *reached)
+	require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	*reached = false
+	request = httptest.NewRequest(http.MethodOptions, "/v1/login_user", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.False(t, *reached)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	logs := captureLogs(t)
+	handler, _, reached := newTestCORSHandler(t, "https://app.swiftbank.com")
+
+	// no token, a preflight must not be turned away by the auth middleware
+	request := httptest.NewRequest(http.MethodOptions, "/v1/transfer_money", nil)
+	request.Header.Set("Origin", "https://app.swiftbank.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	request.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.False(t, *reached)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, "https://app.swiftbank.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	require.Contains(t, recorder.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+	require.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	require.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
+	require.NotEmpty(t, recorder.Header().Get("Access-Control-Max-Age"))
+
+	require.Contains(t, logs.String(), `"level":"debug"`)
+	require.NotContains(t, logs.String(), `"level":"info"`)
+}
+
+func TestCORSWildcard(t *testing.T) {
+	handler, _, _ := newTestCORSHandler(t, "*")
+
+	request := httptest.NewRequest(http.MethodOptions, "/v1/login_user", nil)
+	request.Header.Set("Origin", "https://anywhere.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, "https://anywhere.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestNewCORS(t *testing.T) {
+	cors, err := NewCORS("")
+	require.NoError(t, err)
+	require.False(t, cors.Enabled())
+
+	cors, err = NewCORS("https://app.swiftbank.com/")
+	require.NoError(t, err)
+	require.True(t, cors.allowed("https://app.swiftbank.com"))
+	require.False(t, cors.allowed("https://swiftbank.com"))
+
+	for _, origins := range []string{"app.swiftbank.com", "ftp://swiftbank.com", "https://swiftbank.com/app", "https://"} {
+		_, err := NewCORS(origins)
+		require.Error(t, err, origins)
+	}
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// dbErrorToStatus maps an error from the service layer to the status that best describes its
+// database cause. Postgres errors carry their code and constraint in an ErrorInfo detail, and
+// errors that already have a status keep it.
+func dbErrorToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code := codes.Internal
+	switch pgErr.Code {
+	case uniqueViolation:
+		code = codes.AlreadyExists
+	case foreignKeyViolation:
+		code = codes.FailedPrecondition
+	}
+
+	metadata := map[string]string{"code": pgErr.Code}
+	if pgErr.ConstraintName != "" {
+		metadata["constraint"] = pgErr.ConstraintName
+	}
+
+	st := status.New(code, err.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "DATABASE_ERROR",
+		Domain:   "postgres",
+		Metadata: metadata,
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
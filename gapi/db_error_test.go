@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDBErrorToStatus(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		code       codes.Code
+		httpStatus int
+		constraint string
+	}{
+		{
+			name:       "NoRows",
+			err:        pgx.ErrNoRows,
+			code:       codes.NotFound,
+			httpStatus: http.StatusNotFound,
+		},
+		{
+			name:       "UniqueViolation",
+			err:        &pgconn.PgError{Code: uniqueViolation, ConstraintName: "beneficiaries_owner_nickname_key"},
+			code:       codes.AlreadyExists,
+			httpStatus: http.StatusConflict,
+			constraint: "beneficiaries_owner_nickname_key",
+		},
+		{
+			name:       "ForeignKeyViolation",
+			err:        &pgconn.PgError{Code: foreignKeyViolation, ConstraintName: "transfers_from_account_id_fkey"},
+			code:       codes.FailedPrecondition,
+			httpStatus: http.StatusPreconditionFailed,
+			constraint: "transfers_from_account_id_fkey",
+		},
+		{
+			name:       "CheckViolation",
+			err:        &pgconn.PgError{Code: "23514", ConstraintName: "accounts_balance_check"},
+			code:       codes.Internal,
+			httpStatus: http.StatusInternalServerError,
+			constraint: "accounts_balance_check",
+		},
+		{
+			name:       "DeadlineExceeded",
+			err:        context.DeadlineExceeded,
+			code:       codes.DeadlineExceeded,
+			httpStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "Other",
+			err:        errors.New("connection reset"),
+			code:       codes.Internal,
+			httpStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "AlreadyAStatus",
+			err:        status.Error(codes.PermissionDenied, "not your account"),
+			code:       codes.PermissionDenied,
+			httpStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := dbErrorToStatus(fmt.Errorf("failed to do it: %w", tc.err))
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.code, st.Code())
+			require.Contains(t, st.Message(), "failed to do it")
+
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if detail, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = detail
+				}
+			}
+			if tc.constraint == "" {
+				require.Nil(t, info)
+			} else {
+				require.NotNil(t, info)
+				require.Equal(t, tc.constraint, info.GetMetadata()["constraint"])
+			}
+
+			// and what the gateway answers with
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/v1/transfers/1", nil)
+			ctx := runtime.NewServerMetadataContext(request.Context(), runtime.ServerMetadata{})
+			HTTPErrors{}.ErrorHandler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, recorder, request, err)
+			require.Equal(t, tc.httpStatus, recorder.Code)
+		})
+	}
+
+	require.NoError(t, dbErrorToStatus(nil))
+}
@@ -25,6 +25,19 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 	return statusDetails.Err()
 }
 
+// alreadyExistsError is AlreadyExists with the fields holding a taken value as violations
+func alreadyExistsError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusExists := status.New(codes.AlreadyExists, "already exists")
+
+	statusDetails, err := statusExists.WithDetails(badRequest)
+	if err != nil {
+		return statusExists.Err()
+	}
+
+	return statusDetails.Err()
+}
+
 func unauthenticatedError(err error) error  {
 	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
 }
\ No newline at end of file
@@ -0,0 +1,166 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const internalErrorMessage = "internal server error"
+
+// ErrorEnvelope is the body of every error the gateway answers with
+type ErrorEnvelope struct {
+	Code      codes.Code    `json:"code"`
+	Message   string        `json:"message"`
+	Details   []ErrorDetail `json:"details"`
+	RequestID string        `json:"request_id"`
+}
+
+// ErrorDetail is a field violation, or the reason and metadata of an errdetails.ErrorInfo
+type ErrorDetail struct {
+	Field       string            `json:"field,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// HTTPErrors writes the gateway's errors as an ErrorEnvelope. When HideInternal is set the text
+// of Internal and Unknown errors is only logged, so database and driver messages stay private.
+type HTTPErrors struct {
+	HideInternal bool
+}
+
+// ErrorHandler reports errors of requests that ran out of time as DeadlineExceeded, and answers
+// FailedPrecondition with 412 rather than the gateway's 400, or 403 when the user has to verify
+// their email first
+func (e HTTPErrors) ErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+		err = status.Error(codes.DeadlineExceeded, "request took too long")
+	}
+
+	httpStatus := 0
+	if status.Code(err) == codes.FailedPrecondition {
+		httpStatus = http.StatusPreconditionFailed
+		if errorReason(status.Convert(err)) == reasonEmailNotVerified {
+			httpStatus = http.StatusForbidden
+		}
+	}
+
+	writeErrorEnvelope(w, r, err, httpStatus, e.HideInternal)
+}
+
+// RoutingErrorHandler answers requests no route matches, like an unknown path or method
+func (e HTTPErrors) RoutingErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, httpStatus int) {
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusMethodNotAllowed:
+		code = codes.Unimplemented
+	}
+
+	err := status.Errorf(code, "%s %s: %s", r.Method, r.URL.Path, http.StatusText(httpStatus))
+	writeErrorEnvelope(w, r, err, httpStatus, e.HideInternal)
+}
+
+// writeHTTPError answers a request the gateway never got to with the envelope it gives every
+// other error
+func writeHTTPError(w http.ResponseWriter, r *http.Request, err error) {
+	writeErrorEnvelope(w, r, err, 0, true)
+}
+
+// writeErrorEnvelope writes err with httpStatus, or the status its code maps to when 0
+func writeErrorEnvelope(w http.ResponseWriter, r *http.Request, err error, httpStatus int, hideInternal bool) {
+	var statusErr *runtime.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		httpStatus = statusErr.HTTPStatus
+		err = statusErr.Err
+	}
+
+	st := status.Convert(err)
+	if httpStatus == 0 {
+		httpStatus = runtime.HTTPStatusFromCode(st.Code())
+	}
+
+	id := requestid.FromContext(r.Context())
+	if id == "" {
+		id = r.Header.Get(requestid.Header)
+	}
+
+	envelope := ErrorEnvelope{
+		Code:      st.Code(),
+		Message:   st.Message(),
+		Details:   []ErrorDetail{},
+		RequestID: id,
+	}
+
+	if hideInternal && (st.Code() == codes.Internal || st.Code() == codes.Unknown) {
+		log.Error().
+			Str("request_id", id).
+			Str("path", r.URL.Path).
+			Str("error", st.Message()).
+			Msg("internal error hidden from the response")
+		envelope.Message = internalErrorMessage
+	} else {
+		envelope.Details = errorDetails(st)
+	}
+
+	body, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		httpStatus = http.StatusInternalServerError
+		body = []byte(fmt.Sprintf(`{"code":%d,"message":%q,"details":[],"request_id":%q}`, codes.Internal, internalErrorMessage, id))
+	}
+
+	w.Header().Del("Content-Length") // a gateway error can follow headers prepared for a response
+	w.Header().Set("Content-Type", "application/json")
+	if st.Code() == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	w.WriteHeader(httpStatus)
+	if _, err := w.Write(body); err != nil {
+		log.Error().Err(err).Msg("failed to write error response")
+	}
+}
+
+// errorReason returns the reason of the first ErrorInfo in st's details
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func errorDetails(st *status.Status) []ErrorDetail {
+	details := []ErrorDetail{}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				details = append(details, ErrorDetail{
+					Field:       violation.GetField(),
+					Description: violation.GetDescription(),
+				})
+			}
+		case *errdetails.ErrorInfo:
+			details = append(details, ErrorDetail{
+				Reason:   detail.GetReason(),
+				Metadata: detail.GetMetadata(),
+			})
+		}
+	}
+
+	return details
+}
@@ -0,0 +1,111 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/requestid"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+)
+
+// newErrorTestGateway builds the gateway the way runGatewayServer does, with the error handlers
+// under test
+func newErrorTestGateway(t *testing.T, server *Server, httpErrors HTTPErrors) http.Handler {
+	grpcMux := runtime.NewServeMux(
+		runtime.WithErrorHandler(httpErrors.ErrorHandler),
+		runtime.WithRoutingErrorHandler(httpErrors.RoutingErrorHandler),
+	)
+	require.NoError(t, pb.RegisterSwiftBankHandlerServer(context.Background(), grpcMux, server))
+
+	return HttpRequestID(RequestLogger{}.HttpLogger(server.HttpAuth(grpcMux)))
+}
+
+func serveErrorEnvelope(t *testing.T, handler http.Handler, request *http.Request) (int, ErrorEnvelope) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var envelope ErrorEnvelope
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+	require.Equal(t, recorder.Header().Get(requestid.Header), envelope.RequestID)
+	return recorder.Code, envelope
+}
+
+func TestHTTPErrorsValidationFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+
+	handler := newErrorTestGateway(t, newTestServer(t, service), HTTPErrors{HideInternal: true})
+
+	body := `{"username":"Not Valid","password":"secret123","full_name":"Ada Lovelace","email":"not-an-email"}`
+	request := httptest.NewRequest(http.MethodPost, "/v1/create_user", strings.NewReader(body))
+	code, envelope := serveErrorEnvelope(t, handler, request)
+
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, codes.InvalidArgument, envelope.Code)
+	require.Equal(t, "invalid parameters", envelope.Message)
+	require.NotEmpty(t, envelope.RequestID)
+
+	fields := map[string]string{}
+	for _, detail := range envelope.Details {
+		fields[detail.Field] = detail.Description
+	}
+	require.Len(t, fields, 2)
+	require.Contains(t, fields, "username")
+	require.Contains(t, fields, "email")
+}
+
+func TestHTTPErrorsInternalFailure(t *testing.T) {
+	for _, hideInternal := range []bool{true, false} {
+		ctrl := gomock.NewController(t)
+		service := mockedproviders.NewMockServiceProvider(ctrl)
+		service.EXPECT().
+			GetTransaction(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(models.Transaction{}, errors.New("pq: relation \"transfers\" is locked"))
+
+		server := newTestServer(t, service)
+		accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.BankerRole, time.Minute)
+		require.NoError(t, err)
+		handler := newErrorTestGateway(t, server, HTTPErrors{HideInternal: hideInternal})
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/transfers/1", nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+		request.Header.Set(requestid.Header, "0123456789abcdef0123456789abcdef")
+		code, envelope := serveErrorEnvelope(t, handler, request)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Equal(t, codes.Internal, envelope.Code)
+		require.Equal(t, "0123456789abcdef0123456789abcdef", envelope.RequestID)
+		require.Empty(t, envelope.Details)
+		if hideInternal {
+			require.Equal(t, internalErrorMessage, envelope.Message)
+		} else {
+			require.Contains(t, envelope.Message, "is locked") // developers see what went wrong
+		}
+	}
+}
+
+func TestHTTPErrorsUnknownRoute(t *testing.T) {
+	handler := newErrorTestGateway(t, newTestServer(t, nil), HTTPErrors{HideInternal: true})
+
+	code, envelope := serveErrorEnvelope(t, handler, httptest.NewRequest(http.MethodGet, "/v1/nothing_here", nil))
+	require.Equal(t, http.StatusNotFound, code)
+	require.Equal(t, codes.NotFound, envelope.Code)
+	require.Contains(t, envelope.Message, "/v1/nothing_here")
+	require.Empty(t, envelope.Details)
+}
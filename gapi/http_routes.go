@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpRoute is the HTTP binding of one RPC, as declared by its google.api.http option
+type httpRoute struct {
+	verb     string
+	segments []string // path variables are "*", matching any single segment
+	method   string   // full method name, like info.FullMethod of a gRPC call
+}
+
+// httpRoutes lets HTTP middleware know which RPC a gateway request is for before the gateway
+// has routed it
+var httpRoutes = loadHTTPRoutes()
+
+func loadHTTPRoutes() []httpRoute {
+	var routes []httpRoute
+
+	services := pb.File_service_swift_bank_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		service := services.Get(i)
+		methods := service.Methods()
+		for j := 0; j < methods.Len(); j++ {
+			method := methods.Get(j)
+			rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+
+			fullMethod := "/" + string(service.FullName()) + "/" + string(method.Name())
+			for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				verb, path := httpRulePattern(binding)
+				if verb == "" {
+					continue
+				}
+
+				segments := strings.Split(strings.Trim(path, "/"), "/")
+				for k, segment := range segments {
+					if strings.HasPrefix(segment, "{") {
+						segments[k] = "*"
+					}
+				}
+
+				routes = append(routes, httpRoute{verb: verb, segments: segments, method: fullMethod})
+			}
+		}
+	}
+
+	return routes
+}
+
+func httpRulePattern(rule *annotations.HttpRule) (verb, path string) {
+	switch {
+	case rule.GetGet() != "":
+		return http.MethodGet, rule.GetGet()
+	case rule.GetPost() != "":
+		return http.MethodPost, rule.GetPost()
+	case rule.GetPut() != "":
+		return http.MethodPut, rule.GetPut()
+	case rule.GetPatch() != "":
+		return http.MethodPatch, rule.GetPatch()
+	case rule.GetDelete() != "":
+		return http.MethodDelete, rule.GetDelete()
+	}
+
+	return "", ""
+}
+
+// rpcMethodForRequest returns the full name of the RPC the gateway serves r with. A literal segment
+// wins over a path variable, like the gateway's own routing.
+func rpcMethodForRequest(r *http.Request) (string, bool) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	method, fewestVariables := "", len(segments)+1
+	for _, route := range httpRoutes {
+		if route.verb != r.Method || len(route.segments) != len(segments) {
+			continue
+		}
+
+		variables, matched := 0, true
+		for i, segment := range route.segments {
+			if segment == "*" {
+				variables++
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched && variables < fewestVariables {
+			method, fewestVariables = route.method, variables
+		}
+	}
+
+	return method, method != ""
+}
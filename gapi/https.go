@@ -0,0 +1,50 @@
+package gapi
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// HSTS tells browsers to only reach the gateway over HTTPS from now on. It is only added when
+// the gateway serves TLS, a plaintext response cannot set it.
+func HSTS(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// HTTPSPort returns the port of the address the gateway listens on. A unix socket has none, also
+// when systemd activated it for a gateway configured with a host:port.
+func HTTPSPort(addr net.Addr) (string, bool) {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return "", false
+	}
+
+	return strconv.Itoa(tcpAddr.Port), true
+}
+
+// RedirectToHTTPS sends every request to the same host and path on httpsPort. The permanent
+// redirect keeps the method and body, so API clients still posting to plain HTTP are redirected too.
+func RedirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := url.URL{
+			Scheme:   "https",
+			Host:     host,
+			Path:     r.URL.Path,
+			RawQuery: r.URL.RawQuery,
+		}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,62 @@
+package gapi
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHSTS(t *testing.T) {
+	handler := HSTS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/swagger/", nil))
+	require.Equal(t, http.StatusTeapot, recorder.Code)
+	require.Equal(t, "max-age=63072000; includeSubDomains", recorder.Header().Get("Strict-Transport-Security"))
+}
+
+func TestHTTPSPort(t *testing.T) {
+	port, ok := HTTPSPort(&net.TCPAddr{IP: net.IPv4zero, Port: 8443})
+	require.True(t, ok)
+	require.Equal(t, "8443", port)
+
+	_, ok = HTTPSPort(&net.UnixAddr{Name: "/run/swift_bank/http.sock", Net: "unix"})
+	require.False(t, ok)
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	testCases := []struct {
+		name      string
+		httpsPort string
+		url       string
+		location  string
+	}{
+		{
+			name:      "DefaultPort",
+			httpsPort: "443",
+			url:       "http://bank.example/v1/accounts?page_size=5",
+			location:  "https://bank.example/v1/accounts?page_size=5",
+		},
+		{
+			name:      "OtherPort",
+			httpsPort: "8443",
+			url:       "http://bank.example:8080/swagger/",
+			location:  "https://bank.example:8443/swagger/",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			RedirectToHTTPS(tc.httpsPort).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tc.url, nil))
+
+			require.Equal(t, http.StatusPermanentRedirect, recorder.Code)
+			require.Equal(t, tc.location, recorder.Header().Get("Location"))
+		})
+	}
+}
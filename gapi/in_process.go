@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// InProcessConn calls the unary RPCs of a server in the same process, through an interceptor
+// like a gRPC server would. A gateway registered with a client on it gets the checks done by the
+// interceptor, which the generated in-process handlers skip. Streams are not supported, as with
+// the generated handlers.
+type InProcessConn struct {
+	server      pb.SwiftBankServer
+	interceptor grpc.UnaryServerInterceptor
+	methods     map[string]grpc.MethodDesc // by full method name
+}
+
+// NewInProcessConn returns a connection to server calling it through interceptor
+func NewInProcessConn(server pb.SwiftBankServer, interceptor grpc.UnaryServerInterceptor) *InProcessConn {
+	methods := make(map[string]grpc.MethodDesc, len(pb.SwiftBank_ServiceDesc.Methods))
+	for _, method := range pb.SwiftBank_ServiceDesc.Methods {
+		methods["/"+pb.SwiftBank_ServiceDesc.ServiceName+"/"+method.MethodName] = method
+	}
+
+	return &InProcessConn{
+		server:      server,
+		interceptor: interceptor,
+		methods:     methods,
+	}
+}
+
+func (c *InProcessConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	desc, ok := c.methods[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	// the gateway sends the request headers as outgoing metadata, the handlers read them as incoming
+	md, _ := metadata.FromOutgoingContext(ctx)
+	ctx = metadata.NewIncomingContext(ctx, md)
+	var stream runtime.ServerTransportStream
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &stream)
+
+	resp, err := desc.Handler(c.server, ctx, func(req any) error {
+		proto.Merge(req.(proto.Message), args.(proto.Message))
+		return nil
+	}, c.interceptor)
+
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case grpc.HeaderCallOption:
+			*opt.HeaderAddr = stream.Header()
+		case grpc.TrailerCallOption:
+			*opt.TrailerAddr = stream.Trailer()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	proto.Merge(reply.(proto.Message), resp.(proto.Message))
+	return nil
+}
+
+func (c *InProcessConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "streaming calls are not supported in process")
+}
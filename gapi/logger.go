@@ -1,17 +1,33 @@
 package gapi
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/health"
+	"github.com/zde37/Swift_Bank/requestid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-func GrpcLogger(
+// RequestLogger writes a log line for every request. One slower than SlowThreshold is logged at
+// warn level, a zero threshold never counts a request as slow.
+type RequestLogger struct {
+	SlowThreshold time.Duration
+}
+
+func (l RequestLogger) isSlow(duration time.Duration) bool {
+	return l.SlowThreshold > 0 && duration > l.SlowThreshold
+}
+
+func (l RequestLogger) GrpcLogger(
 	ctx context.Context,
 	req any,
 	info *grpc.UnaryServerInfo,
@@ -29,10 +45,24 @@ func GrpcLogger(
 	logger := log.Info()
 	if err != nil {
 		logger = log.Error().Err(err)
+	} else if l.isSlow(duration) {
+		logger = log.Warn().Bool("slow", true)
+	}
+
+	// only the fields loggedFields allows are written, see redact.go
+	rule := loggedFields[info.FullMethod]
+	if summary := summarizeMessage(req, rule.request); summary != "" {
+		logger = logger.Str("request", summary)
+	}
+	if err == nil {
+		if summary := summarizeMessage(result, rule.response); summary != "" {
+			logger = logger.Str("response", summary)
+		}
 	}
 
 	logger.Str("protocol", "gRPC").
 		Str("method", info.FullMethod).
+		Str("request_id", requestid.FromContext(ctx)).
 		Int("status_code", int(statusCode)).
 		Str("status_text", statusCode.String()).
 		Dur("duration", duration).
@@ -41,10 +71,44 @@ func GrpcLogger(
 	return result, err
 }
 
+// GrpcStreamLogger never counts a stream as slow, streams stay open for as long as the client wants
+func (l RequestLogger) GrpcStreamLogger(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	startTime := time.Now()
+	err := handler(srv, stream) // forward the stream to the handler, this returns once the stream ends.
+	duration := time.Since(startTime)
+
+	statusCode := codes.Unknown
+	if st, ok := status.FromError(err); ok {
+		statusCode = st.Code()
+	}
+
+	logger := log.Info()
+	if err != nil {
+		logger = log.Error().Err(err)
+	}
+
+	logger.Str("protocol", "gRPC").
+		Str("method", info.FullMethod).
+		Str("request_id", requestid.FromContext(stream.Context())).
+		Bool("server_stream", info.IsServerStream).
+		Int("status_code", int(statusCode)).
+		Str("status_text", statusCode.String()).
+		Dur("duration", duration).
+		Msg("received a gRPC stream")
+
+	return err
+}
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 	StatusCode int
 	Body       []byte
+	Size       int // bytes written, after any compression
 }
 
 func (rec *ResponseRecorder) WriteHeader(statusCode int) {
@@ -54,12 +118,34 @@ func (rec *ResponseRecorder) WriteHeader(statusCode int) {
 
 func (rec *ResponseRecorder) Write(body []byte) (int, error) {
 	rec.Body = body
-	return rec.ResponseWriter.Write(body)
+	n, err := rec.ResponseWriter.Write(body)
+	rec.Size += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, e.g. to flush a streamed response
+func (rec *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
 }
 
-func HttpLogger(handler http.Handler) http.Handler {
+// maxPeekedBody is the largest request body HttpLogger reads to summarize, a larger one is not
+const maxPeekedBody = 64 << 10
+
+// responseSummaryKey holds the response summary of an HTTP request, filled in by RecordResponse
+type responseSummaryKey struct{}
+
+// HttpLogger must run after HttpRequestID, so the log line of the request carries its id. The
+// request and response are summarized the way GrpcLogger does, the response only when the gateway
+// is built with runtime.WithForwardResponseOption(RecordResponse).
+func (l RequestLogger) HttpLogger(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
+		id := requestid.FromContext(r.Context())
+
+		requestSummary := summarizeHTTPRequest(r)
+		responseSummary := new(string)
+		r = r.WithContext(context.WithValue(r.Context(), responseSummaryKey{}, responseSummary))
+
 		rec := &ResponseRecorder{
 			ResponseWriter: w,
 			StatusCode:     http.StatusOK,
@@ -68,17 +154,77 @@ func HttpLogger(handler http.Handler) http.Handler {
 		duration := time.Since(startTime)
 
 		logger := log.Info()
-		if rec.StatusCode != http.StatusOK {
-			logger = log.Error().Bytes("body", rec.Body)
+		if health.IsProbe(r.URL.Path) {
+			logger = log.Debug() // probes come every few seconds and a failing dependency is logged by the health checks
+		} else if isPreflight(r) {
+			logger = log.Debug() // browsers send one ahead of most cross-origin calls
+		} else if rec.StatusCode != http.StatusOK {
+			logger = log.Error()
+			if rec.Header().Get("Content-Encoding") == "" {
+				logger = logger.Str("body", truncateSummary(string(rec.Body))) // a compressed body is unreadable in the logs
+			}
+		} else if l.isSlow(duration) {
+			logger = log.Warn().Bool("slow", true)
+		}
+
+		if requestSummary != "" {
+			logger = logger.Str("request", requestSummary)
+		}
+		if *responseSummary != "" {
+			logger = logger.Str("response", *responseSummary)
 		}
 
 		logger.Str("protocol", "http").
 			Str("method", r.Method).
-			Str("path", r.RequestURI).
+			Str("path", redactQuery(r.RequestURI)).
+			Str("request_id", id).
 			Int("status_code", rec.StatusCode).
+			Int("response_size", rec.Size).
 			Str("status_text", http.StatusText(rec.StatusCode)).
 			Dur("duration", duration).
 			Msg("received a HTTP request")
 
 	})
 }
+
+// summarizeHTTPRequest summarizes the JSON body of a gateway request, and puts it back for the
+// handler. Fields sent in the path or query are not summarized, the path is logged already.
+func summarizeHTTPRequest(r *http.Request) string {
+	method, ok := rpcMethodForRequest(r)
+	if !ok || r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	fields := loggedFields[method].request
+	if len(fields) == 0 {
+		return ""
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, maxPeekedBody+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(peeked), r.Body), Closer: r.Body}
+	if err != nil || len(peeked) > maxPeekedBody {
+		return ""
+	}
+
+	return summarizeJSON(peeked, fields)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RecordResponse passes the summary of a gateway response back to HttpLogger. It never fails
+// the response.
+func RecordResponse(ctx context.Context, _ http.ResponseWriter, resp proto.Message) error {
+	summary, ok := ctx.Value(responseSummaryKey{}).(*string)
+	if !ok {
+		return nil
+	}
+
+	if method, ok := runtime.RPCMethod(ctx); ok {
+		*summary = summarizeMessage(resp, loggedFields[method].response)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package gapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+)
+
+const loginPassword = "correct horse battery staple"
+
+type loginDistributor struct {
+	worker.TaskDistributor
+}
+
+func (loginDistributor) DistributeTaskNotifyNewLogin(ctx context.Context, payload *worker.PayloadNotifyNewLogin, opts ...asynq.Option) error {
+	return nil
+}
+
+// newLoginTestServer serves a LoginUser for ada, who logs in with loginPassword
+func newLoginTestServer(t *testing.T) (*Server, models.User) {
+	user := models.User{UserName: "ada", FullName: "Ada Lovelace", Email: "ada@example.com", Role: helpers.DepositorRole}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		LoginUser(gomock.Any(), gomock.Eq(models.LoginUserRequest{UserName: user.UserName, Password: loginPassword})).
+		Times(1).
+		Return(user, nil)
+	service.EXPECT().
+		NewSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, session models.Session) (models.Session, error) {
+			session.ID = uuid.New()
+			return session, nil
+		})
+
+	server := newTestServer(t, service)
+	server.taskDistributor = loginDistributor{}
+	return server, user
+}
+
+// requireNothingSensitive makes sure the logs show who logged in but none of their secrets
+func requireNothingSensitive(t *testing.T, logs string, user models.User) {
+	require.Contains(t, logs, `\"username\":\"ada\"`)
+	require.Contains(t, logs, `access_token_expires_at`)
+	require.NotContains(t, logs, loginPassword)
+	require.NotContains(t, logs, user.Email)
+	require.NotContains(t, logs, user.FullName)
+	require.NotContains(t, logs, "v2.local.") // the tokens
+}
+
+func TestGrpcLoggerLoginUser(t *testing.T) {
+	logs := captureLogs(t)
+	server, user := newLoginTestServer(t)
+	client := startTestServer(t, server, grpc.ChainUnaryInterceptor(RequestLogger{}.GrpcLogger, server.GrpcAuth))
+
+	res, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.UserName, Password: loginPassword})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetAccessToken())
+
+	require.Contains(t, logs.String(), `"request":`)
+	require.Contains(t, logs.String(), `"response":`)
+	requireNothingSensitive(t, logs.String(), user)
+	require.NotContains(t, logs.String(), res.GetAccessToken())
+}
+
+func TestHttpLoggerLoginUser(t *testing.T) {
+	logs := captureLogs(t)
+	server, user := newLoginTestServer(t)
+
+	grpcMux := runtime.NewServeMux(runtime.WithForwardResponseOption(RecordResponse))
+	require.NoError(t, pb.RegisterSwiftBankHandlerServer(context.Background(), grpcMux, server))
+	gateway := httptest.NewServer(RequestLogger{}.HttpLogger(server.HttpAuth(grpcMux)))
+	t.Cleanup(gateway.Close)
+
+	// JSON names are accepted as well as proto names
+	body := `{"username":"ada","password":"` + loginPassword + `","fullName":"` + user.FullName + `"}`
+	res, err := http.Post(gateway.URL+"/v1/login_user", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode) // the handler got the body HttpLogger read
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "accessToken")
+
+	requireNothingSensitive(t, logs.String(), user)
+}
+
+func TestHttpLoggerRedactsQuery(t *testing.T) {
+	logs := captureLogs(t)
+	handler := RequestLogger{}.HttpLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/files/statements/7/2024-03.pdf?expires=1700000000&signature=abc123", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	require.Contains(t, logs.String(), "expires=1700000000")
+	require.NotContains(t, logs.String(), "abc123")
+}
+
+func TestGrpcLoggerSlowRequest(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SwiftBank_GetBalance_FullMethodName}
+	slowHandler := func(ctx context.Context, req any) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}
+
+	logs := captureLogs(t)
+	_, err := RequestLogger{SlowThreshold: 10 * time.Millisecond}.GrpcLogger(context.Background(), nil, info, slowHandler)
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), `"level":"warn"`)
+	require.Contains(t, logs.String(), `"slow":true`)
+
+	// a zero threshold never counts a request as slow
+	logs.Reset()
+	_, err = RequestLogger{}.GrpcLogger(context.Background(), nil, info, slowHandler)
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), `"level":"info"`)
+	require.NotContains(t, logs.String(), `"slow"`)
+}
+
+func TestHttpLoggerSlowRequest(t *testing.T) {
+	logs := captureLogs(t)
+	handler := RequestLogger{SlowThreshold: 10 * time.Millisecond}.HttpLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/accounts/7", nil))
+	require.Contains(t, logs.String(), `"level":"warn"`)
+	require.Contains(t, logs.String(), `"slow":true`)
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/config"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T, service *mockedproviders.MockServiceProvider) *Server {
+	symmetricKey := helpers.RandomString(32)
+	tokenMaker, err := token.NewPastoMaker(symmetricKey)
+	require.NoError(t, err)
+
+	server, err := NewServer(config.Config{TokenSymmetricKey: symmetricKey}, service, tokenMaker, nil, nil, nil, nil, nil, nil, nil, nil, BuildInfo{})
+	require.NoError(t, err)
+	return server
+}
+
+// startTestServer serves server in process with opts, and returns a client connected to it
+func startTestServer(t *testing.T, server *Server, opts ...grpc.ServerOption) pb.SwiftBankClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterSwiftBankServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSwiftBankClient(conn)
+}
+
+// newContextWithBearerToken returns an outgoing context carrying an access token for username
+func newContextWithBearerToken(t *testing.T, server *Server, username, role string) context.Context {
+	accessToken, _, err := server.tokenMaker.CreateToken(username, role, time.Minute)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, "Bearer "+accessToken)
+}
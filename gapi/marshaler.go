@@ -0,0 +1,127 @@
+package gapi
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/statement"
+)
+
+const (
+	MIMETextCSV  = "text/csv"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// ProtoMarshaler sends binary protobuf to gateway clients that ask for it with
+// 'Accept: application/x-protobuf', and reads request bodies sent with that content type.
+type ProtoMarshaler struct {
+	runtime.ProtoMarshaller
+}
+
+func (*ProtoMarshaler) ContentType(_ any) string {
+	return MIMEProtobuf
+}
+
+// CSVMarshaler renders statement responses as CSV for gateway requests sent with
+// 'Accept: text/csv'. Any other message (e.g. an error status) is delegated to the fallback marshaler.
+// The rows are not streamed, GetStatement is unary and the gateway marshals the whole response
+// into one buffer, a month of rows is small enough for that.
+type CSVMarshaler struct {
+	runtime.Marshaler
+}
+
+func NewCSVMarshaler(fallback runtime.Marshaler) *CSVMarshaler {
+	return &CSVMarshaler{
+		Marshaler: fallback,
+	}
+}
+
+func (m *CSVMarshaler) ContentType(v any) string {
+	if _, ok := v.(*pb.GetStatementResponse); ok {
+		return MIMETextCSV
+	}
+	return m.Marshaler.ContentType(v)
+}
+
+func (m *CSVMarshaler) Marshal(v any) ([]byte, error) {
+	rsp, ok := v.(*pb.GetStatementResponse)
+	if !ok {
+		return m.Marshaler.Marshal(v)
+	}
+
+	st, err := statementFromResponse(rsp)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := statement.WriteCSV(&buf, st); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *CSVMarshaler) NewEncoder(w io.Writer) runtime.Encoder {
+	fallback := m.Marshaler.NewEncoder(w)
+	return runtime.EncoderFunc(func(v any) error {
+		if rsp, ok := v.(*pb.GetStatementResponse); ok {
+			st, err := statementFromResponse(rsp)
+			if err != nil {
+				return err
+			}
+			return statement.WriteCSV(w, st)
+		}
+		return fallback.Encode(v)
+	})
+}
+
+func statementFromResponse(rsp *pb.GetStatementResponse) (models.Statement, error) {
+	// the amounts were written by GetStatement, the first one that doesn't parse fails the response
+	currency := rsp.GetAccount().GetCurrency()
+	var err error
+	units := func(value string) int64 {
+		amount, amountErr := amountUnits(value, currency)
+		if err == nil {
+			err = amountErr
+		}
+		return amount
+	}
+
+	st := models.Statement{
+		Account: models.Account{
+			ID:        rsp.GetAccount().GetId(),
+			Owner:     rsp.GetAccount().GetOwner(),
+			Balance:   units(rsp.GetAccount().GetBalance()),
+			Currency:  currency,
+			CreatedAt: rsp.GetAccount().GetCreatedAt().AsTime(),
+		},
+		Year:           int(rsp.GetYear()),
+		Month:          int(rsp.GetMonth()),
+		OpeningBalance: units(rsp.GetOpeningBalance()),
+		ClosingBalance: units(rsp.GetClosingBalance()),
+	}
+
+	for _, row := range rsp.GetRows() {
+		st.Rows = append(st.Rows, models.StatementRow{
+			EntryID:   row.GetEntryId(),
+			Amount:    units(row.GetAmount()),
+			Balance:   units(row.GetBalance()),
+			CreatedAt: row.GetCreatedAt().AsTime(),
+			Category:  row.GetCategory(),
+		})
+	}
+
+	for _, total := range rsp.GetCategories() {
+		st.Categories = append(st.Categories, models.CategoryTotal{
+			Category: total.GetCategory(),
+			In:       units(total.GetIn()),
+			Out:      units(total.GetOut()),
+			Count:    int(total.GetCount()),
+		})
+	}
+
+	return st, err
+}
@@ -2,7 +2,10 @@ package gapi
 
 import (
 	"context"
+	"strings"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/zde37/Swift_Bank/requestid"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -11,35 +14,101 @@ const (
 	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader            = "user-agent"
 	xForwardedForHeader        = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
+	traceparentHeader          = "traceparent" // W3C trace context
+	tracestateHeader           = "tracestate"
 )
 
+// HeaderMatcher forwards the HTTP headers the handlers read on top of the gateway defaults
+func HeaderMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case idempotencyKeyHeader:
+		return idempotencyKeyHeader, true
+	case requestid.Header:
+		return requestid.Header, true
+	case traceparentHeader, tracestateHeader:
+		return strings.ToLower(key), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// ProxyHeaderMatcher is HeaderMatcher for a gateway that dials the gRPC server. The access token
+// is forwarded too, as the server's interceptors check it.
+func ProxyHeaderMatcher(key string) (string, bool) {
+	if strings.ToLower(key) == authorizationHeader {
+		return authorizationHeader, true
+	}
+
+	return HeaderMatcher(key)
+}
+
+// incomingHeader returns the first value of an incoming metadata key
+func incomingHeader(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
 type Metadata struct {
 	UserAgent string
 	ClientIP  string
 }
 
+// extractMetadata reads the user agent and address a request came from. The gateway passes the
+// HTTP User-Agent under its own key, since gRPC sets user-agent itself. The in-process gateway has
+// no peer but appends the remote address to X-Forwarded-For itself.
 func (server *Server) extractMetadata(ctx context.Context) *Metadata {
 	mtdt := &Metadata{}
 
+	var forwardedFor []string
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		// for HTTP client requests
 		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
-		}
-
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
-		}
-
-		// for gRPC client requests
-		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+		} else if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
 		}
+
+		forwardedFor = md.Get(xForwardedForHeader)
 	}
 
-	// for gRPC client requests
+	var remoteAddr string
 	if p, ok := peer.FromContext(ctx); ok {
-		mtdt.ClientIP = p.Addr.String()
+		remoteAddr = p.Addr.String()
 	}
+
+	mtdt.ClientIP = clientAddress(forwardedFor, remoteAddr, server.config.BehindProxy)
 	return mtdt
 }
+
+// clientAddress picks the client out of the hops a request went through, its X-Forwarded-For
+// entries followed by the address it came from, if any. The client is the last hop, or
+// behindProxy, the hop the proxy added before it. Entries further left are whatever the client
+// sent and are never trusted.
+func clientAddress(forwardedFor []string, remoteAddr string, behindProxy bool) string {
+	var hops []string
+	for _, value := range forwardedFor {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hostOnly(hop))
+			}
+		}
+	}
+	if remoteAddr != "" {
+		hops = append(hops, hostOnly(remoteAddr))
+	}
+
+	n := len(hops)
+	switch {
+	case n == 0:
+		return ""
+	case behindProxy && n > 1:
+		return hops[n-2]
+	default:
+		return hops[n-1]
+	}
+}
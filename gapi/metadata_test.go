@@ -0,0 +1,145 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/config"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	testCases := []struct {
+		header  string
+		key     string
+		matched bool
+	}{
+		{header: "Idempotency-Key", key: "idempotency-key", matched: true},
+		{header: "X-Request-Id", key: "x-request-id", matched: true},
+		{header: "Traceparent", key: "traceparent", matched: true},
+		{header: "Tracestate", key: "tracestate", matched: true},
+		{header: "Grpc-Metadata-Foo", key: "Foo", matched: true},
+		{header: "X-Forwarded-Proto", matched: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			key, matched := HeaderMatcher(tc.header)
+			require.Equal(t, tc.matched, matched)
+			if tc.matched {
+				require.Equal(t, tc.key, key)
+			}
+		})
+	}
+}
+
+func TestProxyHeaderMatcher(t *testing.T) {
+	key, matched := ProxyHeaderMatcher("Authorization")
+	require.True(t, matched)
+	require.Equal(t, "authorization", key)
+
+	key, matched = ProxyHeaderMatcher("X-Request-Id")
+	require.True(t, matched)
+	require.Equal(t, "x-request-id", key)
+
+	// in process the token stays out of the metadata, HttpAuth has already checked it
+	key, _ = HeaderMatcher("Authorization")
+	require.NotEqual(t, "authorization", key)
+}
+
+func TestExtractMetadataGrpc(t *testing.T) {
+	testCases := []struct {
+		name        string
+		md          metadata.MD
+		behindProxy bool
+		clientIP    string
+	}{
+		{
+			name:     "Peer",
+			md:       metadata.Pairs(userAgentHeader, "grpc-go/1.64.0"),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:     "ForwardedForIgnored",
+			md:       metadata.Pairs(userAgentHeader, "grpc-go/1.64.0", xForwardedForHeader, "198.51.100.1"),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:        "BehindProxy",
+			md:          metadata.Pairs(userAgentHeader, "grpc-go/1.64.0", xForwardedForHeader, "192.0.2.9, 198.51.100.1"),
+			behindProxy: true,
+			clientIP:    "198.51.100.1",
+		},
+		{
+			name:        "BehindProxyWithoutForwardedFor",
+			md:          metadata.Pairs(userAgentHeader, "grpc-go/1.64.0"),
+			behindProxy: true,
+			clientIP:    "203.0.113.7",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{config: config.Config{BehindProxy: tc.behindProxy}}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 50123}})
+
+			mtdt := server.extractMetadata(ctx)
+			require.Equal(t, "grpc-go/1.64.0", mtdt.UserAgent)
+			require.Equal(t, tc.clientIP, mtdt.ClientIP)
+		})
+	}
+}
+
+func TestExtractMetadataGateway(t *testing.T) {
+	testCases := []struct {
+		name         string
+		forwardedFor string
+		behindProxy  bool
+		clientIP     string
+	}{
+		{
+			name:     "RemoteAddr",
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:         "ForwardedForIgnored",
+			forwardedFor: "198.51.100.1",
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "BehindProxy",
+			forwardedFor: "192.0.2.9, 198.51.100.1",
+			behindProxy:  true,
+			clientIP:     "198.51.100.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{config: config.Config{BehindProxy: tc.behindProxy}}
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/login_user", nil)
+			request.RemoteAddr = "203.0.113.7:50123"
+			request.Header.Set("User-Agent", "Mozilla/5.0")
+			if tc.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+
+			// the context the gateway hands its handlers, carrying the HTTP headers as metadata
+			ctx, err := runtime.AnnotateIncomingContext(request.Context(), runtime.NewServeMux(), request, "/pb.SwiftBank/LoginUser")
+			require.NoError(t, err)
+
+			mtdt := server.extractMetadata(ctx)
+			require.Equal(t, "Mozilla/5.0", mtdt.UserAgent)
+			require.Equal(t, tc.clientIP, mtdt.ClientIP)
+		})
+	}
+}
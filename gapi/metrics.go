@@ -0,0 +1,138 @@
+package gapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// routeOther labels HTTP requests that did not reach an API route, like swagger files or 404s
+const routeOther = "other"
+
+// Metrics records the requests served over gRPC and through the HTTP gateway
+type Metrics struct {
+	grpcRequests *prometheus.CounterVec
+	grpcErrors   *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+}
+
+// NewMetrics creates the request metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Number of unary gRPC requests, by full method.",
+		}, []string{"method"}),
+		grpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_errors_total",
+			Help: "Number of unary gRPC requests that failed, by full method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_request_duration_seconds",
+			Help:    "Time taken to serve a unary gRPC request, by full method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP gateway requests, by route, method and status code.",
+		}, []string{"route", "method", "status_code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to serve an HTTP gateway request, by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+
+	registerer.MustRegister(m.grpcRequests, m.grpcErrors, m.grpcDuration, m.httpRequests, m.httpDuration)
+	return m
+}
+
+func (m *Metrics) GrpcMetrics(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	startTime := time.Now()
+	result, err := handler(ctx, req)
+
+	m.grpcRequests.WithLabelValues(info.FullMethod).Inc()
+	m.grpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		m.grpcErrors.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	}
+
+	return result, err
+}
+
+// routeKey holds the route an HTTP request was matched to, filled in by RecordRoute
+type routeKey struct{}
+
+// HttpMetrics is labelled by route pattern rather than path, so ids in the path don't create a
+// series per resource. The gateway must be built with runtime.WithMetadata(RecordRoute).
+func (m *Metrics) HttpMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+
+		route := new(string)
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+
+		rec := &ResponseRecorder{
+			ResponseWriter: w,
+			StatusCode:     http.StatusOK,
+		}
+		handler.ServeHTTP(rec, r)
+
+		if *route == "" {
+			*route = routeOther
+		}
+		m.httpRequests.WithLabelValues(*route, r.Method, strconv.Itoa(rec.StatusCode)).Inc()
+		m.httpDuration.WithLabelValues(*route, r.Method).Observe(time.Since(startTime).Seconds())
+	})
+}
+
+// RecordRoute passes the route pattern the gateway matched back to HttpMetrics. It adds no metadata.
+func RecordRoute(ctx context.Context, r *http.Request) metadata.MD {
+	route, ok := r.Context().Value(routeKey{}).(*string)
+	if !ok {
+		return nil
+	}
+
+	if pattern, ok := runtime.HTTPPathPattern(ctx); ok {
+		*route = pattern
+	}
+
+	return nil
+}
+
+// MetricsHandler serves the metrics in gatherer. When token is set, scrapes must send it as a
+// bearer token.
+func MetricsHandler(gatherer prometheus.Gatherer, token string) http.Handler {
+	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	if token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields := strings.Fields(r.Header.Get(authorizationHeader))
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationBearer ||
+			subtle.ConstantTimeCompare([]byte(fields[1]), []byte(token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
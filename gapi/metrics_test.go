@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrapeMetrics(t *testing.T, registry *prometheus.Registry, token string) *http.Response {
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	MetricsHandler(registry, "secret").ServeHTTP(recorder, request)
+	return recorder.Result()
+}
+
+func scrapeBody(t *testing.T, registry *prometheus.Registry) string {
+	response := scrapeMetrics(t, registry, "secret")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestGrpcMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		VerifyEmailTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.VerifyEmailTxResult{User: models.User{IsEmailVerified: true}}, nil)
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	client := startTestServer(t, newTestServer(t, service), grpc.ChainUnaryInterceptor(RequestLogger{}.GrpcLogger, metrics.GrpcMetrics))
+
+	_, err := client.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+		EmailId:    1,
+		SecretCode: helpers.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	_, err = client.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	body := scrapeBody(t, registry)
+	require.Contains(t, body, `grpc_requests_total{method="/pb.SwiftBank/VerifyEmail"} 2`)
+	require.Contains(t, body, `grpc_errors_total{code="InvalidArgument",method="/pb.SwiftBank/VerifyEmail"} 1`)
+	require.Contains(t, body, `grpc_request_duration_seconds_count{method="/pb.SwiftBank/VerifyEmail"} 2`)
+}
+
+func TestHttpMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	grpcMux := runtime.NewServeMux(runtime.WithMetadata(RecordRoute))
+	require.NoError(t, pb.RegisterSwiftBankHandlerServer(context.Background(), grpcMux, newTestServer(t, service)))
+
+	mux := http.NewServeMux()
+	mux.Handle("/", grpcMux)
+	handler := metrics.HttpMetrics(mux)
+
+	// both transfers are unauthenticated, but are counted under the same route
+	for _, path := range []string{"/v1/transfers/1", "/v1/transfers/2", "/swagger/index.html"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrapeBody(t, registry)
+	require.Contains(t, body, `http_requests_total{method="GET",route="/v1/transfers/{id}",status_code="401"} 2`)
+	require.Contains(t, body, `http_requests_total{method="GET",route="other",status_code="404"} 1`)
+	require.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/v1/transfers/{id}"} 2`)
+}
+
+func TestMetricsHandlerToken(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	NewMetrics(registry)
+
+	require.Equal(t, http.StatusUnauthorized, scrapeMetrics(t, registry, "").StatusCode)
+	require.Equal(t, http.StatusUnauthorized, scrapeMetrics(t, registry, "wrong").StatusCode)
+	require.Equal(t, http.StatusOK, scrapeMetrics(t, registry, "secret").StatusCode)
+}
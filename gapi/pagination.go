@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"github.com/zde37/Swift_Bank/pagination"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const defaultPageSize = 10
+
+// encodePageToken returns the next_page_token of a list response. filters are what the
+// request was scoped and filtered by, the token is rejected for anything else.
+func (server *Server) encodePageToken(cursor pagination.Cursor, filters ...string) string {
+	if cursor.Direction == 0 {
+		cursor.Direction = pagination.Forward
+	}
+
+	return server.pageTokens.Encode(cursor, pagination.HashFilters(filters...))
+}
+
+// decodePageToken returns the cursor of a page_token, or an InvalidArgument status when it was
+// tampered with or created for other filters
+func (server *Server) decodePageToken(token string, filters ...string) (pagination.Cursor, error) {
+	cursor, err := server.pageTokens.Decode(token, pagination.HashFilters(filters...))
+	if err != nil {
+		return pagination.Cursor{}, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	return cursor, nil
+}
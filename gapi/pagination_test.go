@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListPageTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+
+	beneficiaries := make([]models.Beneficiary, 3)
+	for i := range beneficiaries {
+		beneficiaries[i] = models.Beneficiary{ID: int64(i + 1), Owner: "ada", AccountID: int64(i + 10), Nickname: helpers.RandomOwner()}
+	}
+	gomock.InOrder(
+		service.EXPECT().
+			ListBeneficiaries(gomock.Any(), gomock.Eq(models.ListBeneficiariesParams{Owner: "ada", Limit: 3, Offset: 0})).
+			Return(beneficiaries, nil),
+		service.EXPECT().
+			ListBeneficiaries(gomock.Any(), gomock.Eq(models.ListBeneficiariesParams{Owner: "ada", Limit: 3, Offset: 2})).
+			Return(beneficiaries[2:], nil),
+	)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ada := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	first, err := client.ListBeneficiaries(ada, &pb.ListBeneficiariesRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, first.GetBeneficiaries(), 2)
+	require.NotEmpty(t, first.GetNextPageToken())
+
+	second, err := client.ListBeneficiaries(ada, &pb.ListBeneficiariesRequest{PageSize: 2, PageToken: first.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, second.GetBeneficiaries(), 1)
+	require.Empty(t, second.GetNextPageToken())
+
+	// another user can't page through ada's list with ada's token
+	bob := newContextWithBearerToken(t, server, "bob", helpers.DepositorRole)
+	_, err = client.ListBeneficiaries(bob, &pb.ListBeneficiariesRequest{PageSize: 2, PageToken: first.GetNextPageToken()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// nor is it accepted by another list
+	_, err = client.ListWebhooks(ada, &pb.ListWebhooksRequest{PageSize: 2, PageToken: first.GetNextPageToken()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// and a forged offset token is refused
+	_, err = client.ListBeneficiaries(ada, &pb.ListBeneficiariesRequest{PageToken: "b2Zmc2V0OjEwMDA"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
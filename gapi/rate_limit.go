@@ -0,0 +1,141 @@
+package gapi
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/ratelimit"
+	"github.com/zde37/Swift_Bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimiter limits the requests each user makes to every RPC. Requests without a valid access
+// token are counted by client IP instead.
+type RateLimiter struct {
+	limiter     ratelimit.Limiter
+	limits      ratelimit.Limits
+	tokenMaker  token.Maker
+	behindProxy bool // gateway clients are taken from X-Forwarded-For like session metadata
+}
+
+func NewRateLimiter(tokenMaker token.Maker, limiter ratelimit.Limiter, limits ratelimit.Limits, behindProxy bool) *RateLimiter {
+	return &RateLimiter{
+		limiter:     limiter,
+		limits:      limits,
+		tokenMaker:  tokenMaker,
+		behindProxy: behindProxy,
+	}
+}
+
+func (rl *RateLimiter) GrpcRateLimit(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	var authHeader, clientIP string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationHeader); len(values) > 0 {
+			authHeader = values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = hostOnly(p.Addr.String())
+	}
+
+	if err := rl.allow(ctx, path.Base(info.FullMethod), authHeader, clientIP); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// HttpRateLimit applies the same limits to the gateway, whose requests skip the gRPC interceptors.
+// Requests that are not for an RPC, like swagger files, are not limited.
+func (rl *RateLimiter) HttpRateLimit(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := rpcMethodForRequest(r)
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		clientIP := clientAddress(r.Header.Values(xForwardedForHeader), r.RemoteAddr, rl.behindProxy)
+		if err := rl.allow(r.Context(), path.Base(method), r.Header.Get(authorizationHeader), clientIP); err != nil {
+			for _, detail := range status.Convert(err).Details() {
+				if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryInfo.GetRetryDelay().AsDuration().Seconds()))))
+				}
+			}
+
+			writeHTTPError(w, r, err) // 429
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// allow returns a ResourceExhausted error, telling when to retry, once the caller went over the
+// limit of method. Redis being down lets the request through, it is not worth failing every
+// request for.
+func (rl *RateLimiter) allow(ctx context.Context, method, authHeader, clientIP string) error {
+	key := method + ":ip:" + clientIP
+	if username, ok := rl.username(authHeader); ok {
+		key = method + ":user:" + username
+	}
+
+	result, err := rl.limiter.Allow(ctx, key, rl.limits.For(method))
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("method", method).Msg("rate limiter unavailable, allowing request")
+		return nil
+	}
+
+	if result.Allowed {
+		return nil
+	}
+
+	st, err := status.New(codes.ResourceExhausted, "too many requests").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "too many requests")
+	}
+
+	return st.Err()
+}
+
+// username returns the user of a valid bearer token. Invalid tokens are left for the handler to reject.
+func (rl *RateLimiter) username(authHeader string) (string, bool) {
+	fields := strings.Fields(authHeader)
+	if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationBearer {
+		return "", false
+	}
+
+	payload, err := rl.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return "", false
+	}
+
+	return payload.UserName, true
+}
+
+// hostOnly strips the port from a client address
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
@@ -0,0 +1,182 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/ratelimit"
+	"github.com/zde37/Swift_Bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeLimiter allows a fixed number of requests per key and remembers the keys it saw
+type fakeLimiter struct {
+	allowed int
+	counts  map[string]int
+	err     error
+}
+
+func (l *fakeLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	if l.err != nil {
+		return ratelimit.Result{}, l.err
+	}
+
+	l.counts[key]++
+	if l.counts[key] > l.allowed {
+		return ratelimit.Result{RetryAfter: 1500 * time.Millisecond}, nil
+	}
+
+	return ratelimit.Result{Allowed: true}, nil
+}
+
+func newTestRateLimiter(t *testing.T, allowed int) (*RateLimiter, *fakeLimiter, string) {
+	symmetricKey := helpers.RandomString(32)
+	limiter := &fakeLimiter{allowed: allowed, counts: map[string]int{}}
+
+	tokenMaker, err := token.NewPastoMaker(symmetricKey)
+	require.NoError(t, err)
+
+	rateLimiter := NewRateLimiter(tokenMaker, limiter, ratelimit.Limits{
+		Default: ratelimit.Limit{Requests: 60, Period: time.Minute},
+	}, false)
+
+	return rateLimiter, limiter, symmetricKey
+}
+
+func TestGrpcRateLimit(t *testing.T) {
+	rateLimiter, limiter, _ := newTestRateLimiter(t, 1)
+
+	accessToken, _, err := rateLimiter.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SwiftBank/TransferMoney"}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	userCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, "Bearer "+accessToken))
+
+	resp, err := rateLimiter.GrpcRateLimit(userCtx, nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	_, err = rateLimiter.GrpcRateLimit(userCtx, nil, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	require.Equal(t, 1500*time.Millisecond, details[0].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+
+	// anonymous requests from the same address are counted apart from the user
+	_, err = rateLimiter.GrpcRateLimit(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]int{"TransferMoney:user:ada": 2, "TransferMoney:ip:10.0.0.1": 1}, limiter.counts)
+}
+
+func TestGrpcRateLimitUnavailable(t *testing.T) {
+	rateLimiter, limiter, _ := newTestRateLimiter(t, 0)
+	limiter.err = errors.New("connection refused")
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SwiftBank/LoginUser"}
+	_, err := rateLimiter.GrpcRateLimit(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestHttpRateLimit(t *testing.T) {
+	rateLimiter, limiter, _ := newTestRateLimiter(t, 1)
+	handler := rateLimiter.HttpRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, nil)
+		request.RemoteAddr = "10.0.0.1:5000"
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/v1/login_user").Code)
+
+	recorder := serve(http.MethodPost, "/v1/login_user")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, int(codes.ResourceExhausted), body.Code)
+	require.Equal(t, "too many requests", body.Message)
+
+	// requests that are not for an RPC are never limited
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/swagger/index.html").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/swagger/index.html").Code)
+
+	require.Equal(t, map[string]int{"LoginUser:ip:10.0.0.1": 2}, limiter.counts)
+}
+
+func TestHttpRateLimitForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name        string
+		behindProxy bool
+		key         string
+	}{
+		// a client can't pick its bucket by sending X-Forwarded-For
+		{name: "Direct", key: "LoginUser:ip:10.0.0.1"},
+		{name: "BehindProxy", behindProxy: true, key: "LoginUser:ip:203.0.113.7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rateLimiter, limiter, _ := newTestRateLimiter(t, 1)
+			rateLimiter.behindProxy = tc.behindProxy
+			handler := rateLimiter.HttpRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/login_user", nil)
+			request.RemoteAddr = "10.0.0.1:5000"
+			request.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			require.Equal(t, map[string]int{tc.key: 1}, limiter.counts)
+		})
+	}
+}
+
+func TestRPCMethodForRequest(t *testing.T) {
+	testCases := []struct {
+		method string
+		target string
+		rpc    string
+	}{
+		{method: http.MethodPost, target: "/v1/login_user", rpc: pb.SwiftBank_LoginUser_FullMethodName},
+		{method: http.MethodGet, target: "/v1/transfers/12", rpc: pb.SwiftBank_GetTransfer_FullMethodName},
+		{method: http.MethodPost, target: "/v1/transfers/12/reverse", rpc: pb.SwiftBank_ReverseTransfer_FullMethodName},
+		{method: http.MethodGet, target: "/v1/accounts/3/balance?currency=USD", rpc: pb.SwiftBank_GetBalance_FullMethodName},
+		{method: http.MethodGet, target: "/v1/notifications/unread_count", rpc: pb.SwiftBank_UnreadNotificationCount_FullMethodName},
+		{method: http.MethodDelete, target: "/v1/login_user"},
+		{method: http.MethodGet, target: "/healthz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			rpc, ok := rpcMethodForRequest(httptest.NewRequest(tc.method, tc.target, nil))
+			require.Equal(t, tc.rpc != "", ok)
+			require.Equal(t, tc.rpc, rpc)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errPanic is what the client sees of a panic, the details only go to the log
+var errPanic = status.Error(codes.Internal, "internal server error")
+
+func logPanic(protocol, method, id string, p any) {
+	log.Error().
+		Str("protocol", protocol).
+		Str("method", method).
+		Str("request_id", id).
+		Str("panic", fmt.Sprint(p)).
+		Bytes("stack", debug.Stack()).
+		Msg("recovered from panic")
+}
+
+// panicRequestID returns the request id the client sent. Recovery runs ahead of RequestID, so
+// an id generated by the server is not known here.
+func panicRequestID(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return id
+	}
+
+	return incomingHeader(ctx, requestid.Header)
+}
+
+// GrpcRecovery turns a panic in the handler, or any interceptor after it, into an Internal
+// error. It has to come first in the chain.
+func GrpcRecovery(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic("gRPC", info.FullMethod, panicRequestID(ctx), p)
+			resp, err = nil, errPanic
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func GrpcStreamRecovery(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic("gRPC", info.FullMethod, panicRequestID(stream.Context()), p)
+			err = errPanic
+		}
+	}()
+
+	return handler(srv, stream)
+}
+
+// HttpRecovery answers 500 when the gateway panics. It wraps HttpLogger, which shares the
+// request headers, so the request id it assigned can still be logged.
+func HttpRecovery(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p) // net/http uses it to abort the response on purpose
+			}
+
+			logPanic("http", r.Method+" "+r.URL.Path, r.Header.Get(requestid.Header), p)
+			writeHTTPError(w, r, errPanic)
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// captureLogs sends the global logger to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var logs bytes.Buffer
+	defaultLogger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	t.Cleanup(func() { log.Logger = defaultLogger })
+
+	return &logs
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGrpcRecovery(t *testing.T) {
+	logs := captureLogs(t)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SwiftBank/GetBalance"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.Header, "req-1"))
+
+	// chained the way runGrpcServer does, with recovery ahead of the logger
+	resp, err := GrpcRecovery(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		return RequestLogger{}.GrpcLogger(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			var balances map[string]float64
+			balances["USD"] = 1 // nil map
+			return balances, nil
+		})
+	})
+	require.Nil(t, resp)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, "internal server error", status.Convert(err).Message())
+
+	require.Contains(t, logs.String(), `"message":"recovered from panic"`)
+	require.Contains(t, logs.String(), `"panic":"assignment to entry in nil map"`)
+	require.Contains(t, logs.String(), `"request_id":"req-1"`)
+	require.Contains(t, logs.String(), `"stack":`)
+}
+
+func TestGrpcStreamRecovery(t *testing.T) {
+	logs := captureLogs(t)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/pb.SwiftBank/StreamTransactions", IsServerStream: true}
+	stream := &fakeServerStream{ctx: context.Background()}
+
+	err := GrpcStreamRecovery(nil, stream, info, func(srv any, stream grpc.ServerStream) error {
+		panic("subscriber closed")
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Contains(t, logs.String(), `"panic":"subscriber closed"`)
+	require.Contains(t, logs.String(), `"method":"/pb.SwiftBank/StreamTransactions"`)
+}
+
+func TestHttpRecovery(t *testing.T) {
+	logs := captureLogs(t)
+
+	handler := HttpRecovery(RequestLogger{}.HttpLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("statement template missing")
+	})))
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/accounts/1/statement", nil)
+	request.Header.Set(requestid.Header, "req-2")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "internal server error")
+	require.Contains(t, logs.String(), `"panic":"statement template missing"`)
+	require.Contains(t, logs.String(), `"request_id":"req-2"`)
+}
+
+func TestHttpRecoveryAbortHandler(t *testing.T) {
+	handler := HttpRecovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,314 @@
+package gapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxLoggedSummary is the most of a request or response summary written to a log line
+const maxLoggedSummary = 2048
+
+// redacted replaces the value of every sensitive field in the logs
+const redacted = "[REDACTED]"
+
+// sensitiveNames mask any field whose name contains one of them, even when it is allowed. Users
+// put private things in the memo of a transfer, so descriptions are never logged either.
+var sensitiveNames = []string{"password", "secret", "token", "signature", "description", "memo"}
+
+// logRule names the fields of an RPC that may be logged, by their proto names. A dotted path
+// picks a field of a message, or of every message in a list.
+type logRule struct {
+	request  []string
+	response []string
+}
+
+// loggedFields lists what the request logs may show of each RPC. Anything not named here is left
+// out, so a new RPC logs neither its request nor its response until it is added.
+var loggedFields = map[string]logRule{
+	pb.SwiftBank_CreateUser_FullMethodName: {
+		request:  []string{"username"},
+		response: []string{"user.username", "user.created_at"},
+	},
+	pb.SwiftBank_LoginUser_FullMethodName: {
+		request:  []string{"username"},
+		response: []string{"user.username", "session_id", "access_token_expires_at", "refresh_token_expires_at"},
+	},
+	pb.SwiftBank_VerifyEmail_FullMethodName: {
+		request:  []string{"email_id"},
+		response: []string{"is_verified"},
+	},
+	pb.SwiftBank_UpdateUser_FullMethodName: {
+		request:  []string{"username", "update_mask"},
+		response: []string{"user.username", "user.password_changed_at"},
+	},
+	pb.SwiftBank_GetNotificationPreferences_FullMethodName: {
+		response: []string{"preferences"},
+	},
+	pb.SwiftBank_UpdateNotificationPreferences_FullMethodName: {
+		request:  []string{"email_transfers", "email_logins", "email_statements", "prefer_sms"},
+		response: []string{"preferences"},
+	},
+	pb.SwiftBank_ListNotifications_FullMethodName:       {request: []string{"page_size"}},
+	pb.SwiftBank_MarkNotificationRead_FullMethodName:    {request: []string{"id"}},
+	pb.SwiftBank_UnreadNotificationCount_FullMethodName: {response: []string{"count"}},
+	pb.SwiftBank_GetStatement_FullMethodName:            {request: []string{"account_id", "year", "month"}},
+	pb.SwiftBank_RequestStatement_FullMethodName: {
+		request:  []string{"account_id", "year", "month"},
+		response: []string{"statement.id", "statement.status"},
+	},
+	pb.SwiftBank_ListStatements_FullMethodName: {request: []string{"account_id", "page_size"}},
+	pb.SwiftBank_TransferMoney_FullMethodName: {
+		request:  []string{"from_account_id", "to_account_id", "to_beneficiary_id", "amount", "currency"},
+		response: []string{"transfer.id", "fee", "total_debit"},
+	},
+	pb.SwiftBank_GetTransfer_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_ListTransfers_FullMethodName: {
+		request: []string{"account_id", "start_time", "end_time", "min_amount", "max_amount", "currency", "direction", "page_size"},
+	},
+	pb.SwiftBank_ReverseTransfer_FullMethodName: {
+		request:  []string{"transfer_id"},
+		response: []string{"original.id", "reversal.id"},
+	},
+	pb.SwiftBank_ScheduleTransfer_FullMethodName: {
+		request:  []string{"from_account_id", "to_account_id", "amount", "currency", "execute_at"},
+		response: []string{"scheduled_transfer.id"},
+	},
+	pb.SwiftBank_CancelScheduledTransfer_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_CreateStandingOrder_FullMethodName: {
+		request:  []string{"from_account_id", "to_account_id", "amount", "currency", "frequency", "start_date", "end_date"},
+		response: []string{"standing_order.id"},
+	},
+	pb.SwiftBank_ListStandingOrders_FullMethodName:  {request: []string{"page_size"}},
+	pb.SwiftBank_PauseStandingOrder_FullMethodName:  {request: []string{"id"}},
+	pb.SwiftBank_ResumeStandingOrder_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_DeleteStandingOrder_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_AddBeneficiary_FullMethodName: {
+		request:  []string{"account_id"},
+		response: []string{"beneficiary.id"},
+	},
+	pb.SwiftBank_ListBeneficiaries_FullMethodName: {request: []string{"page_size"}},
+	pb.SwiftBank_DeleteBeneficiary_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_CreateWebhook_FullMethodName: {
+		request:  []string{"events"},
+		response: []string{"webhook.id"},
+	},
+	pb.SwiftBank_ListWebhooks_FullMethodName:  {request: []string{"page_size"}},
+	pb.SwiftBank_DeleteWebhook_FullMethodName: {request: []string{"id"}},
+	pb.SwiftBank_TestWebhook_FullMethodName: {
+		request:  []string{"id"},
+		response: []string{"delivered", "status_code"},
+	},
+	pb.SwiftBank_SetOverdraftLimit_FullMethodName:      {request: []string{"account_id", "limit"}},
+	pb.SwiftBank_UpdateAccount_FullMethodName:          {request: []string{"account_id", "update_mask"}},
+	pb.SwiftBank_SetTransactionCategory_FullMethodName: {request: []string{"entry_id", "category", "update_mask"}},
+	pb.SwiftBank_GetBalance_FullMethodName:             {request: []string{"account_id", "display_currency"}},
+	pb.SwiftBank_GetAccountSummary_FullMethodName:      {request: []string{"account_id", "year", "month"}},
+	pb.SwiftBank_SearchTransactions_FullMethodName:     {request: []string{"account_id", "page_size"}},
+	pb.SwiftBank_ExportTransactions_FullMethodName: {
+		request:  []string{"account_id", "from", "to"},
+		response: []string{"queued"},
+	},
+	pb.SwiftBank_ListFailedTasks_FullMethodName: {request: []string{"queue", "page_size"}},
+	pb.SwiftBank_RetryFailedTask_FullMethodName: {request: []string{"queue", "id"}},
+	pb.SwiftBank_ListAuditLogs_FullMethodName:   {request: []string{"entity", "entity_id", "page_size"}},
+}
+
+// summarizeMessage returns the loggable fields of a request or response, empty when none are
+func summarizeMessage(message any, fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	m, ok := message.(proto.Message)
+	if !ok {
+		return ""
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return ""
+	}
+
+	return summarizeJSON(data, fields)
+}
+
+// summarizeJSON does the same for a JSON body, which may use either proto or JSON field names
+func summarizeJSON(data []byte, fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // keeps ids as they were sent
+	var body map[string]any
+	if err := decoder.Decode(&body); err != nil {
+		return ""
+	}
+
+	picked, ok := pickFields(normalizeKeys(body), fieldTree(fields)).(map[string]any)
+	if !ok || len(picked) == 0 {
+		return ""
+	}
+
+	summary, err := json.Marshal(maskSensitive(picked))
+	if err != nil {
+		return ""
+	}
+
+	return truncateSummary(string(summary))
+}
+
+// fieldTree turns dotted paths into nested maps, a nil map keeps the whole field
+func fieldTree(paths []string) map[string]any {
+	tree := map[string]any{}
+	for _, path := range paths {
+		node := tree
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+
+			child, ok := node[name].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+
+	return tree
+}
+
+func pickFields(value any, tree map[string]any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		picked := map[string]any{}
+		for name, subtree := range tree {
+			field, ok := v[name]
+			if !ok {
+				continue
+			}
+
+			if subtree, ok := subtree.(map[string]any); ok {
+				picked[name] = pickFields(field, subtree)
+			} else {
+				picked[name] = field
+			}
+		}
+		return picked
+	case []any:
+		picked := make([]any, len(v))
+		for i, element := range v {
+			picked[i] = pickFields(element, tree)
+		}
+		return picked
+	default:
+		return v
+	}
+}
+
+func maskSensitive(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			if isSensitive(name) {
+				v[name] = redacted
+			} else {
+				v[name] = maskSensitive(field)
+			}
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = maskSensitive(element)
+		}
+	}
+
+	return value
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKeys renames the JSON names protojson also accepts, like fullName, to proto names
+func normalizeKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for name, field := range v {
+			normalized[snakeCase(name)] = normalizeKeys(field)
+		}
+		return normalized
+	case []any:
+		for i, element := range v {
+			v[i] = normalizeKeys(element)
+		}
+	}
+
+	return value
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func truncateSummary(summary string) string {
+	if len(summary) <= maxLoggedSummary {
+		return summary
+	}
+
+	end := maxLoggedSummary
+	for end > 0 && !utf8.RuneStart(summary[end]) {
+		end-- // don't cut a character in half
+	}
+	return summary[:end] + "...(truncated)"
+}
+
+// redactQuery masks the sensitive query parameters of a request URI, like the signature of a
+// file link or a page token
+func redactQuery(requestURI string) string {
+	path, rawQuery, ok := strings.Cut(requestURI, "?")
+	if !ok {
+		return requestURI
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path + "?" + redacted
+	}
+
+	for name := range query {
+		if isSensitive(name) {
+			query[name] = []string{redacted}
+		}
+	}
+
+	return path + "?" + query.Encode()
+}
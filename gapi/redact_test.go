@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestSummarizeMessage(t *testing.T) {
+	res := &pb.LoginUserResponse{
+		User:         &pb.User{Username: "ada", Email: "ada@example.com"},
+		SessionId:    "5b7c",
+		AccessToken:  "v2.local.access",
+		RefreshToken: "v2.local.refresh",
+	}
+
+	summary := summarizeMessage(res, []string{"user.username", "session_id"})
+	require.JSONEq(t, `{"user":{"username":"ada"},"session_id":"5b7c"}`, summary)
+
+	// sensitive fields are masked even when they are allowed
+	summary = summarizeMessage(res, []string{"user", "access_token"})
+	require.JSONEq(t, `{"user":{"username":"ada","email":"ada@example.com"},"access_token":"[REDACTED]"}`, summary)
+
+	// nothing allowed, nothing logged
+	require.Empty(t, summarizeMessage(res, nil))
+	require.Empty(t, summarizeMessage(&pb.LoginUserResponse{}, []string{"session_id"}))
+	require.Empty(t, summarizeMessage("not a message", []string{"session_id"}))
+}
+
+func TestSummarizeJSON(t *testing.T) {
+	body := `{"fromAccountId":"7","amount":12.5,"description":"rent","transfers":[{"id":1,"secret":"x"},{"id":2}]}`
+
+	summary := summarizeJSON([]byte(body), []string{"from_account_id", "amount", "transfers.id", "transfers.secret"})
+	require.JSONEq(t, `{"from_account_id":"7","amount":12.5,"transfers":[{"id":1,"secret":"[REDACTED]"},{"id":2}]}`, summary)
+
+	// the memo of a transfer is masked even when a rule names it, or the whole transfer
+	summary = summarizeJSON([]byte(body), []string{"amount", "description"})
+	require.JSONEq(t, `{"amount":12.5,"description":"[REDACTED]"}`, summary)
+	summary = summarizeMessage(&pb.GetTransferResponse{Transfer: &pb.Transfer{Id: 1, Description: "rent"}}, []string{"transfer"})
+	require.JSONEq(t, `{"transfer":{"id":"1","description":"[REDACTED]"}}`, summary)
+
+	require.Empty(t, summarizeJSON([]byte(`{"amount":`), []string{"amount"}))
+	require.Empty(t, summarizeJSON([]byte(`[1,2]`), []string{"amount"}))
+}
+
+func TestSummaryTruncated(t *testing.T) {
+	summary := summarizeMessage(&pb.ListAuditLogsRequest{EntityId: strings.Repeat("é", maxLoggedSummary)}, []string{"entity_id"})
+	require.True(t, strings.HasSuffix(summary, "...(truncated)"))
+	require.LessOrEqual(t, len(summary), maxLoggedSummary+len("...(truncated)"))
+	require.True(t, strings.ToValidUTF8(summary, "") == summary)
+}
+
+func TestRedactQuery(t *testing.T) {
+	require.Equal(t, "/v1/transfers", redactQuery("/v1/transfers"))
+	require.Equal(t, "/v1/transfers?page_size=5&page_token=%5BREDACTED%5D", redactQuery("/v1/transfers?page_token=abc&page_size=5"))
+}
+
+// TestLoggedFieldsExist keeps loggedFields from naming fields that were renamed or removed, which
+// would silently stop being logged
+func TestLoggedFieldsExist(t *testing.T) {
+	methods := pb.File_service_swift_bank_proto.Services().ByName("SwiftBank").Methods()
+	for method, rule := range loggedFields {
+		name := method[strings.LastIndex(method, "/")+1:]
+		descriptor := methods.ByName(protoreflect.Name(name))
+		require.NotNil(t, descriptor, method)
+
+		for _, path := range rule.request {
+			requireFieldPath(t, descriptor.Input(), path)
+		}
+		for _, path := range rule.response {
+			requireFieldPath(t, descriptor.Output(), path)
+		}
+	}
+}
+
+func requireFieldPath(t *testing.T, message protoreflect.MessageDescriptor, path string) {
+	for _, name := range strings.Split(path, ".") {
+		require.NotNil(t, message, path)
+		field := message.Fields().ByName(protoreflect.Name(name))
+		require.NotNil(t, field, "%s has no field %s", message.FullName(), path)
+		message = field.Message()
+	}
+}
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// withRequestID stores the request id the client sent, or a new one if it sent none or an
+// unusable one, in ctx and echoes it back in the response header
+func withRequestID(ctx context.Context, setHeader func(metadata.MD) error) context.Context {
+	id := incomingHeader(ctx, requestid.Header)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+
+	_ = setHeader(metadata.Pairs(requestid.Header, id))
+	return requestid.NewContext(ctx, id)
+}
+
+// HttpRequestID gives a gateway request its id, like RequestID does for gRPC. The in-process
+// gateway calls the handlers without going through the gRPC interceptors, so the id is put in
+// the request context here, and in the header HeaderMatcher forwards as metadata.
+func HttpRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+			r.Header.Set(requestid.Header, id)
+		}
+
+		w.Header().Set(requestid.Header, id)
+		handler.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// RequestID must run before GrpcLogger, so the log line of the request carries its id
+func RequestID(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	ctx = withRequestID(ctx, func(md metadata.MD) error {
+		return grpc.SetHeader(ctx, md)
+	})
+
+	return handler(ctx, req)
+}
+
+// contextStream replaces the context of a stream, to pass on values added by an interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func StreamRequestID(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx := withRequestID(stream.Context(), stream.SetHeader)
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHttpRequestID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "Incoming", incoming: "checkout-42", keep: true},
+		{name: "Missing"},
+		{name: "TooLong", incoming: strings.Repeat("a", 129)},
+		{name: "BreaksLogLines", incoming: "abc\"}\n{\"level\":\"fatal"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			var handlerID, forwardedID string
+			handler := HttpRequestID(RequestLogger{}.HttpLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerID = requestid.FromContext(r.Context())
+
+				// what the gateway hands to the gRPC handlers as incoming metadata
+				ctx, err := runtime.AnnotateIncomingContext(r.Context(), runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(HeaderMatcher)), r, pb.SwiftBank_GetBalance_FullMethodName)
+				require.NoError(t, err)
+				forwardedID = incomingHeader(ctx, requestid.Header)
+			})))
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/accounts/1/balance", nil)
+			if tc.incoming != "" {
+				request.Header.Set(requestid.Header, tc.incoming)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			id := recorder.Header().Get(requestid.Header)
+			if tc.keep {
+				require.Equal(t, tc.incoming, id)
+			} else {
+				_, err := uuid.Parse(id)
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, id, handlerID)
+			require.Equal(t, id, forwardedID)
+			require.Contains(t, logs.String(), fmt.Sprintf(`"request_id":%q`, id))
+		})
+	}
+}
+
+func TestRequestIDGrpcLogger(t *testing.T) {
+	logs := captureLogs(t)
+
+	// an id forwarded by the gateway arrives as incoming metadata
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.Header, "checkout-43"))
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SwiftBank_GetBalance_FullMethodName}
+	chain := func(ctx context.Context, req any) (any, error) {
+		return RequestLogger{}.GrpcLogger(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+	}
+
+	_, err := RequestID(ctx, nil, info, chain)
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), `"request_id":"checkout-43"`)
+}
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) AddBeneficiary(ctx context.Context, req *pb.AddBeneficiaryRequest) (*pb.AddBeneficiaryResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateAddBeneficiaryRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	beneficiary, err := server.service.AddBeneficiary(ctx, models.Beneficiary{
+		Owner:     authPayload.UserName,
+		AccountID: req.GetAccountId(),
+		Nickname:  strings.TrimSpace(req.GetNickname()),
+		IBAN:      helpers.NormalizeIBAN(req.GetIban()),
+		BIC:       req.GetBic(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, service.ErrDuplicateNickname) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to add beneficiary: %w", err))
+	}
+
+	return &pb.AddBeneficiaryResponse{
+		Beneficiary: convertBeneficiary(beneficiary),
+	}, nil
+}
+
+func validateAddBeneficiaryRequest(req *pb.AddBeneficiaryRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateNickname(req.GetNickname()); err != nil {
+		violations = append(violations, fieldViolation("nickname", err))
+	}
+
+	if req.Iban != nil {
+		if err := val.ValidateIBAN(req.GetIban()); err != nil {
+			violations = append(violations, fieldViolation("iban", err))
+		}
+	}
+
+	if req.Bic != nil {
+		if err := val.ValidateBIC(req.GetBic()); err != nil {
+			violations = append(violations, fieldViolation("bic", err))
+		}
+	}
+
+	return violations
+}
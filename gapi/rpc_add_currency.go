@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) AddCurrency(ctx context.Context, req *pb.AddCurrencyRequest) (*pb.AddCurrencyResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateAddCurrencyRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	currency, err := server.service.AddCurrency(ctx, models.Currency{
+		Code:              req.GetCode(),
+		Name:              strings.TrimSpace(req.GetName()),
+		MinorUnitExponent: req.GetMinorUnitExponent(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrCurrencyExponentMismatch) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to add currency: %w", err))
+	}
+
+	server.refreshCurrencies(ctx)
+	server.audit.Record(ctx, authPayload.UserName, audit.ActionAddCurrency, audit.EntityCurrency, currency.Code, nil, convertCurrency(currency))
+
+	rsp := &pb.AddCurrencyResponse{
+		Currency: convertCurrency(currency),
+	}
+
+	return rsp, nil
+}
+
+func validateAddCurrencyRequest(req *pb.AddCurrencyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateCurrencyCode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+
+	if err := val.ValidateCurrencyName(req.GetName()); err != nil {
+		violations = append(violations, fieldViolation("name", err))
+	}
+
+	if err := val.ValidateMinorUnitExponent(req.GetMinorUnitExponent()); err != nil {
+		violations = append(violations, fieldViolation("minor_unit_exponent", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/currencies"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAddCurrencyAPI(t *testing.T) {
+	t.Cleanup(func() { helpers.SetSupportedCurrencies([]string{helpers.USD, helpers.EUR, helpers.CAD}) })
+
+	banker := helpers.RandomOwner()
+	errExponentMismatch := service.ErrCurrencyExponentMismatch
+	naira := models.Currency{Code: "NGN", Name: "Naira", MinorUnitExponent: 2, Enabled: true}
+	enabled := []models.Currency{
+		{Code: helpers.USD, MinorUnitExponent: 2, Enabled: true},
+		naira,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.AddCurrencyRequest
+		role          string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.AddCurrencyResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.AddCurrencyRequest{Code: "NGN", Name: " Naira ", MinorUnitExponent: 2},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().AddCurrency(gomock.Any(), gomock.Eq(models.Currency{Code: "NGN", Name: "Naira", MinorUnitExponent: 2})).
+					Times(1).
+					Return(naira, nil)
+				service.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(enabled, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.AddCurrencyResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "NGN", res.GetCurrency().GetCode())
+				require.True(t, res.GetCurrency().GetEnabled())
+
+				// the cache was refreshed, the currency can be used straight away
+				require.True(t, helpers.ISSupportedCurrency("NGN"))
+			},
+		},
+		{
+			name: "ExponentMismatch",
+			req:  &pb.AddCurrencyRequest{Code: "NGN", Name: "Naira", MinorUnitExponent: 0},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().AddCurrency(gomock.Any(), gomock.Any()).Times(1).Return(models.Currency{}, errExponentMismatch)
+				service.EXPECT().ListCurrencies(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.AddCurrencyResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "Depositor",
+			req:  &pb.AddCurrencyRequest{Code: "NGN", Name: "Naira", MinorUnitExponent: 2},
+			role: helpers.DepositorRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().AddCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.AddCurrencyResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidRequest",
+			req:  &pb.AddCurrencyRequest{Code: "ngn", Name: " ", MinorUnitExponent: 5},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().AddCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.AddCurrencyResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolation(t, err, "code")
+				requireFieldViolation(t, err, "name")
+				requireFieldViolation(t, err, "minor_unit_exponent")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			server.currencies = currencies.NewCache(service)
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			res, err := client.AddCurrency(newContextWithBearerToken(t, server, banker, tc.role), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// maxBulkAccounts bounds the rows of one import, bigger migrations are split across requests
+const maxBulkAccounts = 50000
+
+// bulkImportRecord is what the audit log keeps of an import
+type bulkImportRecord struct {
+	BestEffort bool    `json:"bestEffort"`
+	AccountIDs []int64 `json:"accountIds"` // the accounts created
+	Rejected   int32   `json:"rejected"`
+}
+
+func (server *Server) BulkCreateAccounts(ctx context.Context, req *pb.BulkCreateAccountsRequest) (*pb.BulkCreateAccountsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateBulkCreateAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// a row that doesn't validate is rejected on its own, the import goes on as best_effort says
+	rows := make([]models.BulkAccountRow, len(req.GetAccounts()))
+	for i, account := range req.GetAccounts() {
+		account.Owner = val.NormalizeUsername(account.GetOwner())
+		rows[i] = models.BulkAccountRow{
+			Owner:    account.GetOwner(),
+			Currency: account.GetCurrency(),
+			Reason:   validateBulkAccountRow(account),
+		}
+		if rows[i].Reason == "" && account.GetBalance() != "" {
+			balance, err := amountUnits(account.GetBalance(), account.GetCurrency())
+			if err != nil {
+				rows[i].Reason = fmt.Sprintf("balance: %s", err)
+			}
+			rows[i].Balance = balance
+		}
+	}
+
+	results, err := server.service.BulkCreateAccountsTx(ctx, models.BulkCreateAccountsParams{
+		Rows:       rows,
+		BestEffort: req.GetBestEffort(),
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create accounts: %w", err))
+	}
+
+	rsp := &pb.BulkCreateAccountsResponse{
+		Results: make([]*pb.BulkAccountResult, len(results)),
+	}
+	record := bulkImportRecord{BestEffort: req.GetBestEffort()}
+	for i, result := range results {
+		rsp.Results[i] = &pb.BulkAccountResult{
+			Row:    int32(i),
+			Status: result.Status,
+			Reason: result.Reason,
+		}
+
+		switch result.Status {
+		case models.BulkAccountCreated:
+			rsp.Results[i].Account = convertAccount(result.Account)
+			rsp.Created++
+			record.AccountIDs = append(record.AccountIDs, result.Account.ID)
+		case models.BulkAccountRejected:
+			rsp.Rejected++
+		}
+	}
+	record.Rejected = rsp.Rejected
+
+	if rsp.Created > 0 {
+		server.audit.Record(ctx, authPayload.UserName, audit.ActionBulkCreateAccounts, audit.EntityAccount, "", nil, record)
+	}
+
+	return rsp, nil
+}
+
+func validateBulkCreateAccountsRequest(req *pb.BulkCreateAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if n := len(req.GetAccounts()); n == 0 || n > maxBulkAccounts {
+		violations = append(violations, fieldViolation("accounts", fmt.Errorf("must have from 1 to %d rows", maxBulkAccounts)))
+	}
+
+	return violations
+}
+
+// validateBulkAccountRow returns why the row is rejected, or an empty string when it is valid
+func validateBulkAccountRow(row *pb.BulkAccountRow) string {
+	var problems []string
+	if err := val.ValidateUsername(row.GetOwner()); err != nil {
+		problems = append(problems, "owner "+err.Error())
+	}
+
+	if err := val.ValidateCurrency(row.GetCurrency()); err != nil {
+		problems = append(problems, "currency "+err.Error())
+	} else if row.GetBalance() != "" {
+		if err := val.ValidateOpeningBalance(row.GetBalance(), row.GetCurrency()); err != nil {
+			problems = append(problems, "balance "+err.Error())
+		}
+	}
+
+	return strings.Join(problems, ", ")
+}
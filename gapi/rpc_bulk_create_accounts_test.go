@@ -0,0 +1,150 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBulkCreateAccountsAPI(t *testing.T) {
+	banker := helpers.RandomOwner()
+	owner := helpers.RandomOwner()
+	rows := []*pb.BulkAccountRow{
+		{Owner: owner, Currency: "USD", Balance: "10.50"},
+		{Owner: owner, Currency: "XYZ"},
+		{Owner: owner, Currency: "EUR", Balance: "-1"},
+		{Owner: owner, Currency: "CAD"},
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.BulkCreateAccountsRequest
+		role          string
+		buildStubs    func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams)
+		checkResponse func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams)
+	}{
+		{
+			name: "BestEffort",
+			req:  &pb.BulkCreateAccountsRequest{Accounts: rows, BestEffort: true},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams) {
+				service.EXPECT().BulkCreateAccountsTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, params models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+						*arg = params
+						return []models.BulkAccountResult{
+							{Status: models.BulkAccountCreated, Account: models.Account{ID: 1, Owner: owner, Balance: 1050, Currency: "USD"}},
+							{Status: models.BulkAccountRejected, Reason: params.Rows[1].Reason},
+							{Status: models.BulkAccountRejected, Reason: params.Rows[2].Reason},
+							{Status: models.BulkAccountRejected, Reason: "owner already has a CAD account"},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams) {
+				require.NoError(t, err)
+
+				// rows that don't validate reach the service already rejected
+				require.True(t, arg.BestEffort)
+				require.Len(t, arg.Rows, 4)
+				require.Equal(t, models.BulkAccountRow{Owner: owner, Currency: "USD", Balance: 1050}, arg.Rows[0])
+				require.Equal(t, "currency must be a supported currency", arg.Rows[1].Reason)
+				require.Equal(t, "balance must not be negative", arg.Rows[2].Reason)
+				require.Empty(t, arg.Rows[3].Reason)
+
+				require.Equal(t, int32(1), res.GetCreated())
+				require.Equal(t, int32(3), res.GetRejected())
+				require.Len(t, res.GetResults(), 4)
+				require.Equal(t, models.BulkAccountCreated, res.GetResults()[0].GetStatus())
+				require.Equal(t, "10.50", res.GetResults()[0].GetAccount().GetBalance())
+				require.Equal(t, int32(3), res.GetResults()[3].GetRow())
+				require.Equal(t, "owner already has a CAD account", res.GetResults()[3].GetReason())
+				require.Nil(t, res.GetResults()[3].GetAccount())
+			},
+		},
+		{
+			name: "AllOrNothing",
+			req:  &pb.BulkCreateAccountsRequest{Accounts: rows},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams) {
+				service.EXPECT().BulkCreateAccountsTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, params models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+						*arg = params
+						return []models.BulkAccountResult{
+							{Status: models.BulkAccountSkipped},
+							{Status: models.BulkAccountRejected, Reason: params.Rows[1].Reason},
+							{Status: models.BulkAccountRejected, Reason: params.Rows[2].Reason},
+							{Status: models.BulkAccountSkipped},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams) {
+				require.NoError(t, err)
+				require.False(t, arg.BestEffort)
+				require.Zero(t, res.GetCreated())
+				require.Equal(t, int32(2), res.GetRejected())
+				require.Equal(t, models.BulkAccountSkipped, res.GetResults()[0].GetStatus())
+			},
+		},
+		{
+			name: "Depositor",
+			req:  &pb.BulkCreateAccountsRequest{Accounts: rows},
+			role: helpers.DepositorRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams) {
+				service.EXPECT().BulkCreateAccountsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NoRows",
+			req:  &pb.BulkCreateAccountsRequest{},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams) {
+				service.EXPECT().BulkCreateAccountsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolation(t, err, "accounts")
+			},
+		},
+		{
+			name: "Conflict",
+			req:  &pb.BulkCreateAccountsRequest{Accounts: rows[:1]},
+			role: helpers.BankerRole,
+			buildStubs: func(service *mockedproviders.MockServiceProvider, arg *models.BulkCreateAccountsParams) {
+				// an account created while the import ran
+				service.EXPECT().BulkCreateAccountsTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &pgconn.PgError{Code: uniqueViolation, ConstraintName: "owner_currency_key"})
+			},
+			checkResponse: func(t *testing.T, res *pb.BulkCreateAccountsResponse, err error, arg models.BulkCreateAccountsParams) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+
+			var arg models.BulkCreateAccountsParams
+			tc.buildStubs(service, &arg)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			res, err := client.BulkCreateAccounts(newContextWithBearerToken(t, server, banker, tc.role), tc.req)
+			tc.checkResponse(t, res, err, arg)
+		})
+	}
+}
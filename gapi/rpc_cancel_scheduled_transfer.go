@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CancelScheduledTransfer(ctx context.Context, req *pb.CancelScheduledTransferRequest) (*pb.CancelScheduledTransferResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateCancelScheduledTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.service.GetScheduledTransfer(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get scheduled transfer: %w", err))
+	}
+
+	if transfer.CreatedBy != authPayload.UserName {
+		return nil, status.Errorf(codes.PermissionDenied, "scheduled transfer does not belong to the authenticated user")
+	}
+
+	// the worker may claim the transfer between the read above and the cancel, the database decides who wins
+	transfer, err = server.service.CancelScheduledTransfer(ctx, transfer.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrScheduledTransferDone) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to cancel scheduled transfer: %w", err))
+	}
+
+	return &pb.CancelScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(transfer),
+	}, nil
+}
+
+func validateCancelScheduledTransferRequest(req *pb.CancelScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateScheduledTransferId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateCreateAccountRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.service.CreateAccount(ctx, models.CreateAccountRequest{Currency: req.GetCurrency(), Type: req.GetAccountType()}, authPayload.UserName)
+	if err != nil {
+		var existsErr *service.AccountExistsError
+		if errors.As(err, &existsErr) {
+			return nil, accountExistsError(existsErr)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create account: %w", err))
+	}
+
+	return &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}, nil
+}
+
+// accountExistsError is AlreadyExists with the id of the existing account in an ErrorInfo, for
+// clients to send the user to it
+func accountExistsError(err *service.AccountExistsError) error {
+	st := status.New(codes.AlreadyExists, err.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: "ACCOUNT_EXISTS",
+		Domain: "swiftbank",
+		Metadata: map[string]string{
+			"account_id":   strconv.FormatInt(err.AccountID, 10),
+			"currency":     err.Currency,
+			"account_type": err.Type,
+		},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if req.GetAccountType() != "" {
+		if err := val.ValidateAccountType(req.GetAccountType()); err != nil {
+			violations = append(violations, fieldViolation("account_type", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	owner := helpers.RandomOwner()
+	account := models.Account{ID: 42, Owner: owner, Currency: helpers.USD, Type: models.AccountChecking}
+	savings := models.Account{ID: 43, Owner: owner, Currency: helpers.USD, Type: models.AccountSavings}
+	existsErr := &service.AccountExistsError{AccountID: account.ID, Currency: helpers.USD, Type: models.AccountChecking}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: helpers.USD},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(models.CreateAccountRequest{Currency: helpers.USD}), gomock.Eq(owner)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+				require.Equal(t, "0.00", res.GetAccount().GetBalance())
+				require.Equal(t, models.AccountChecking, res.GetAccount().GetAccountType())
+			},
+		},
+		{
+			name: "Savings",
+			req:  &pb.CreateAccountRequest{Currency: helpers.USD, AccountType: models.AccountSavings},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(models.CreateAccountRequest{Currency: helpers.USD, Type: models.AccountSavings}), gomock.Eq(owner)).
+					Times(1).
+					Return(savings, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, models.AccountSavings, res.GetAccount().GetAccountType())
+			},
+		},
+		{
+			name: "AccountExists",
+			req:  &pb.CreateAccountRequest{Currency: helpers.USD},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.Account{}, existsErr)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				st := status.Convert(err)
+				require.Equal(t, codes.AlreadyExists, st.Code())
+				require.Contains(t, st.Message(), "42")
+
+				require.Len(t, st.Details(), 1)
+				info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+				require.True(t, ok)
+				require.Equal(t, "ACCOUNT_EXISTS", info.GetReason())
+				require.Equal(t, "42", info.GetMetadata()["account_id"])
+				require.Equal(t, models.AccountChecking, info.GetMetadata()["account_type"])
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "XYZ"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().CreateAccount(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolation(t, err, "currency")
+			},
+		},
+		{
+			name: "InvalidAccountType",
+			req:  &pb.CreateAccountRequest{Currency: helpers.USD, AccountType: "credit"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().CreateAccount(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireFieldViolation(t, err, "account_type")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			res, err := client.CreateAccount(newContextWithBearerToken(t, server, owner, helpers.DepositorRole), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
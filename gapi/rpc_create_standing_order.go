@@ -0,0 +1,121 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateStandingOrder(ctx context.Context, req *pb.CreateStandingOrderRequest) (*pb.CreateStandingOrderResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateCreateStandingOrderRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.requireVerifiedEmail(ctx, authPayload.UserName); err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	amount, err := amountUnits(req.GetAmount(), req.GetCurrency())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+	}
+
+	order := models.StandingOrder{
+		Owner:         authPayload.UserName,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount,
+		Currency:      req.GetCurrency(),
+		Description:   val.NormalizeDescription(req.GetDescription()),
+		Frequency:     req.GetFrequency(),
+		StartDate:     startOfDay(req.GetStartDate().AsTime()),
+	}
+	if req.EndDate != nil {
+		endDate := startOfDay(req.GetEndDate().AsTime())
+		order.EndDate = &endDate
+	}
+
+	order, err = server.service.CreateStandingOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create standing order: %w", err))
+	}
+
+	return &pb.CreateStandingOrderResponse{
+		StandingOrder: convertStandingOrder(order),
+	}, nil
+}
+
+// startOfDay returns midnight UTC of the day t falls on, standing orders run on whole days
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func validateCreateStandingOrderRequest(req *pb.CreateStandingOrderRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := val.ValidateAccountId(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must differ from from_account_id")))
+	}
+
+	if err := val.ValidateAmount(req.GetAmount(), req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateDescription(val.NormalizeDescription(req.GetDescription())); err != nil {
+		violations = append(violations, fieldViolation("description", err))
+	}
+
+	if err := val.ValidateFrequency(req.GetFrequency()); err != nil {
+		violations = append(violations, fieldViolation("frequency", err))
+	}
+
+	if req.GetStartDate() == nil {
+		violations = append(violations, fieldViolation("start_date", fmt.Errorf("must be set")))
+		return violations
+	}
+
+	startDate := startOfDay(req.GetStartDate().AsTime())
+	if err := val.ValidateStartDate(startDate); err != nil {
+		violations = append(violations, fieldViolation("start_date", err))
+	}
+
+	if req.EndDate != nil && startOfDay(req.GetEndDate().AsTime()).Before(startDate) {
+		violations = append(violations, fieldViolation("end_date", fmt.Errorf("must not be before start_date")))
+	}
+
+	return violations
+}
@@ -2,14 +2,12 @@ package gapi
 
 import (
 	"context"
-	"errors"
-	"time"
+	"fmt"
 
-	"github.com/hibiken/asynq"
-	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/zde37/Swift_Bank/helpers"
 	"github.com/zde37/Swift_Bank/models"
 	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/repository"
 	"github.com/zde37/Swift_Bank/val"
 	"github.com/zde37/Swift_Bank/worker"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -18,10 +16,17 @@ import (
 )
 
 func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	if violations := validateCreateUserRequest(req); violations != nil {
+	req.Username = val.NormalizeUsername(req.GetUsername())
+	req.Email = val.NormalizeEmail(req.GetEmail())
+
+	if violations := validateCreateUserRequest(req, server.passwordPolicy); violations != nil {
 		return nil, invalidArgumentError(violations)
 	}
 
+	if err := server.passwordGuard.Check(ctx, req.GetPassword()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("password", err)})
+	}
+
 	hashedPassword, err := helpers.HashPassword(req.GetPassword()) // use 'GetPassword' because this function checks if the request object is nil
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
@@ -33,28 +38,42 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 			HashedPassword: hashedPassword,
 			FullName:       req.GetFullName(),
 			Email:          req.GetEmail(),
+			PhoneNumber:    val.NormalizePhoneNumber(req.GetPhoneNumber()),
 		},
-		AfterCreate: func(user models.User) error {
-			taskPayload := &worker.PayloadSendVerifyEmail{
-				Username: user.UserName,
+		SecretCode: helpers.RandomString(32),
+		AfterCreate: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+			// the relay enqueues the email once the user is committed
+			message, err := worker.NewOutboxMessage(ctx, worker.TaskSendVerifyEmail, &worker.PayloadSendVerifyEmail{
+				Username:      user.UserName,
+				VerifyEmailID: verifyEmail.ID,
+			}, worker.QueueCritical, 10)
+			if err != nil {
+				return nil, err
 			}
-			opts := []asynq.Option{
-				asynq.MaxRetry(10),
-				asynq.ProcessIn(10 * time.Second), // 10 seconds delay
-				asynq.Queue(worker.QueueCritical),
+			messages := []models.OutboxMessage{message}
+
+			if user.PhoneNumber != "" {
+				message, err = worker.NewOutboxMessage(ctx, worker.TaskSendVerifyPhone, &worker.PayloadSendVerifyPhone{
+					Username: user.UserName,
+				}, worker.QueueCritical, 3)
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, message)
 			}
-			return server.taskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+			return messages, nil
 		},
 	})
 	
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-
-			return nil, status.Errorf(codes.AlreadyExists, "%s: %s", getPgxErrorName(pgErr), err)
-
+		if field, ok := repository.TakenUserField(err); ok {
+			return nil, alreadyExistsError([]*errdetails.BadRequest_FieldViolation{fieldViolation(field, fmt.Errorf("%s is already taken", field))})
 		}
-		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create user: %w", err))
+	}
+
+	if createdUser.PhoneNumber != "" {
+		server.logSharedPhoneNumber(ctx, createdUser.UserName, createdUser.PhoneNumber)
 	}
 
 	return &pb.CreateUserResponse{
@@ -62,19 +81,12 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	}, nil
 }
 
-func getPgxErrorName(pgErr *pgconn.PgError) string {
-	if pgErr.ConstraintName == "users_email_key" {
-		return "email address already exists"
-	}
-	return "username already exists"
-}
-
-func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+func validateCreateUserRequest(req *pb.CreateUserRequest, policy val.PasswordPolicy) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := val.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
 	}
 
-	if err := val.ValidatePassword(req.GetPassword()); err != nil {
+	if err := val.ValidatePassword(req.GetPassword(), policy, req.GetUsername(), req.GetEmail()); err != nil {
 		violations = append(violations, fieldViolation("password", err))
 	}
 
@@ -86,5 +98,11 @@ func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdeta
 		violations = append(violations, fieldViolation("email", err))
 	}
 
+	if req.GetPhoneNumber() != "" {
+		if err := val.ValidatePhoneNumber(val.NormalizePhoneNumber(req.GetPhoneNumber())); err != nil {
+			violations = append(violations, fieldViolation("phone_number", err))
+		}
+	}
+
 	return violations
 }
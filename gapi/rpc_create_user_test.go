@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateUserTaken(t *testing.T) {
+	req := &pb.CreateUserRequest{
+		Username: helpers.RandomOwner(),
+		FullName: "Ada Lovelace",
+		Email:    helpers.RandomEmail(),
+		Password: "Sw1ft-Bank-Passw0rd!",
+	}
+
+	testCases := []struct {
+		name       string
+		constraint string
+		field      string // empty when the constraint isn't one of a user field
+	}{
+		{name: "Username", constraint: "users_pkey", field: "username"},
+		{name: "UsernameOtherCase", constraint: "users_lower_username_key", field: "username"},
+		{name: "Email", constraint: "users_email_key", field: "email"},
+		{name: "EmailOtherCase", constraint: "users_lower_email_key", field: "email"},
+		{name: "OtherConstraint", constraint: "verify_emails_pkey"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			service.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(models.User{}, &pgconn.PgError{Code: uniqueViolation, ConstraintName: tc.constraint})
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server)
+
+			_, err := client.CreateUser(context.Background(), req)
+			require.Equal(t, codes.AlreadyExists, status.Code(err))
+			if tc.field != "" {
+				requireStatusViolation(t, err, codes.AlreadyExists, tc.field)
+			}
+		})
+	}
+}
+
+func TestCreateUserTakenGateway(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		CreateUserTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.User{}, &pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_lower_email_key"})
+
+	server := newTestServer(t, service)
+	gateway := startTestGateway(t, server)
+
+	body := `{"username":"` + helpers.RandomOwner() + `","full_name":"Ada Lovelace","email":"` + helpers.RandomEmail() + `","password":"Sw1ft-Bank-Passw0rd!"}`
+	response, err := http.Post(gateway.URL+"/v1/create_user", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusConflict, response.StatusCode)
+
+	var answer struct {
+		Details []struct {
+			FieldViolations []struct {
+				Field string `json:"field"`
+			} `json:"fieldViolations"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&answer))
+	require.Len(t, answer.Details, 1)
+	require.Equal(t, "email", answer.Details[0].FieldViolations[0].Field)
+}
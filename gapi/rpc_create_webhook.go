@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateCreateWebhookRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	webhook, err := server.service.CreateWebhook(ctx, models.Webhook{
+		Owner:  authPayload.UserName,
+		URL:    req.GetUrl(),
+		Events: req.GetEvents(),
+		Secret: req.GetSecret(),
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create webhook: %w", err))
+	}
+
+	return &pb.CreateWebhookResponse{
+		Webhook: convertWebhook(webhook),
+	}, nil
+}
+
+func validateCreateWebhookRequest(req *pb.CreateWebhookRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateWebhookURL(req.GetUrl()); err != nil {
+		violations = append(violations, fieldViolation("url", err))
+	}
+
+	if err := val.ValidateWebhookEvents(req.GetEvents()); err != nil {
+		violations = append(violations, fieldViolation("events", err))
+	}
+
+	if err := val.ValidateWebhookSecret(req.GetSecret()); err != nil {
+		violations = append(violations, fieldViolation("secret", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DeleteStandingOrder(ctx context.Context, req *pb.DeleteStandingOrderRequest) (*pb.DeleteStandingOrderResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateDeleteStandingOrderRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err := server.authorizeStandingOrder(ctx, authPayload, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := server.service.DeleteStandingOrder(ctx, req.GetId()); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "standing order not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to delete standing order: %w", err))
+	}
+
+	return &pb.DeleteStandingOrderResponse{}, nil
+}
+
+func validateDeleteStandingOrderRequest(req *pb.DeleteStandingOrderRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateStandingOrderId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateDeleteWebhookRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err := server.authorizeWebhook(ctx, authPayload, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := server.service.DeleteWebhook(ctx, req.GetId()); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "webhook not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to delete webhook: %w", err))
+	}
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+func validateDeleteWebhookRequest(req *pb.DeleteWebhookRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateWebhookId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
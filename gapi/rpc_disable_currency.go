@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DisableCurrency(ctx context.Context, req *pb.DisableCurrencyRequest) (*pb.DisableCurrencyResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := val.ValidateCurrencyCode(req.GetCode()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("code", err)})
+	}
+
+	currency, err := server.service.DisableCurrency(ctx, req.GetCode())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "currency not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to disable currency: %w", err))
+	}
+
+	server.refreshCurrencies(ctx)
+	server.audit.Record(ctx, authPayload.UserName, audit.ActionDisableCurrency, audit.EntityCurrency, currency.Code, nil, convertCurrency(currency))
+
+	rsp := &pb.DisableCurrencyResponse{
+		Currency: convertCurrency(currency),
+	}
+
+	return rsp, nil
+}
+
+// refreshCurrencies applies a change to the currencies table in this process at once, other
+// instances pick it up on their next periodic refresh. The change is already saved, so a failed
+// refresh only delays it here too.
+func (server *Server) refreshCurrencies(ctx context.Context) {
+	if err := server.currencies.Refresh(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to refresh currencies")
+	}
+}
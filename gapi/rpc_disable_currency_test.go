@@ -0,0 +1,50 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/currencies"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDisableCurrencyAPI(t *testing.T) {
+	t.Cleanup(func() { helpers.SetSupportedCurrencies([]string{helpers.USD, helpers.EUR, helpers.CAD}) })
+
+	banker := helpers.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().DisableCurrency(gomock.Any(), gomock.Eq(helpers.CAD)).
+		Times(1).
+		Return(models.Currency{Code: helpers.CAD, MinorUnitExponent: 2}, nil)
+	service.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]models.Currency{
+		{Code: helpers.USD, MinorUnitExponent: 2, Enabled: true},
+		{Code: helpers.CAD, MinorUnitExponent: 2},
+	}, nil)
+	service.EXPECT().DisableCurrency(gomock.Any(), gomock.Eq("XYZ")).Times(1).Return(models.Currency{}, pgx.ErrNoRows)
+
+	server := newTestServer(t, service)
+	server.currencies = currencies.NewCache(service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, banker, helpers.BankerRole)
+
+	res, err := client.DisableCurrency(ctx, &pb.DisableCurrencyRequest{Code: helpers.CAD})
+	require.NoError(t, err)
+	require.False(t, res.GetCurrency().GetEnabled())
+
+	// no new accounts or transfers in it, its balances still read
+	require.False(t, helpers.ISSupportedCurrency(helpers.CAD))
+	require.Equal(t, "1.00", convertAccount(models.Account{Balance: 100, Currency: helpers.CAD}).GetBalance())
+
+	_, err = client.DisableCurrency(ctx, &pb.DisableCurrencyRequest{Code: "XYZ"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
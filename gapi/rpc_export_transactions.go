@@ -0,0 +1,212 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/export"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportFlushRows is how many rows of a streamed export are sent to the client at a time
+const exportFlushRows = 100
+
+func (server *Server) ExportTransactions(ctx context.Context, req *pb.ExportTransactionsRequest) (*pb.ExportTransactionsResponse, error) {
+	arg, queued, err := server.prepareExport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if queued {
+		return &pb.ExportTransactionsResponse{Queued: true}, nil
+	}
+
+	var csv bytes.Buffer
+	if err := server.writeExport(ctx, arg, &csv, func() {}); err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to export transactions: %w", err))
+	}
+
+	return &pb.ExportTransactionsResponse{Csv: csv.Bytes()}, nil
+}
+
+// HttpExportTransactions serves ExportTransactions on the gateway in place of the generated handler,
+// which would hold the whole export in memory. Rows are streamed to the client as they are read.
+// It has to be routed to on the pattern
+//
+//	GET /v1/accounts/{account_id}/transactions/export
+func (server *Server) HttpExportTransactions(w http.ResponseWriter, r *http.Request) {
+	req, violations := exportRequestFromHTTP(r)
+	if violations != nil {
+		writeHTTPError(w, r, invalidArgumentError(violations))
+		return
+	}
+
+	arg, queued, err := server.prepareExport(r.Context(), req)
+	if err != nil {
+		writeHTTPError(w, r, err)
+		return
+	}
+
+	if queued {
+		body, _ := protojson.Marshal(&pb.ExportTransactionsResponse{Queued: true})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(body)
+		return
+	}
+
+	filename := fmt.Sprintf("transactions-%d-%s-%s.csv", arg.Account.ID, arg.From.UTC().Format(time.DateOnly), arg.To.UTC().Format(time.DateOnly))
+	w.Header().Set("Content-Type", MIMETextCSV+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	err = server.writeExport(r.Context(), arg, w, func() { rc.Flush() })
+	if err != nil {
+		// the status is already sent, cutting the connection is the only way left to tell the
+		// client its file is incomplete
+		log.Ctx(r.Context()).Error().Err(err).Int64("account_id", arg.Account.ID).Msg("failed to stream transaction export")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportRequestFromHTTP reads the request the way the gateway would, the account from the path and
+// the period from RFC 3339 query parameters
+func exportRequestFromHTTP(r *http.Request) (req *pb.ExportTransactionsRequest, violations []*errdetails.BadRequest_FieldViolation) {
+	req = &pb.ExportTransactionsRequest{}
+
+	accountID, err := strconv.ParseInt(r.PathValue("account_id"), 10, 64)
+	if err != nil {
+		violations = append(violations, fieldViolation("account_id", fmt.Errorf("must be an integer")))
+	}
+	req.AccountId = accountID
+
+	for _, param := range []struct {
+		name  string
+		value **timestamppb.Timestamp
+	}{{"from", &req.From}, {"to", &req.To}} {
+		value := r.URL.Query().Get(param.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			violations = append(violations, fieldViolation(param.name, fmt.Errorf("must be an RFC 3339 timestamp")))
+			continue
+		}
+		*param.value = timestamppb.New(t)
+	}
+
+	return req, violations
+}
+
+// prepareExport authorizes and validates req. Exports with more than ExportMaxRows rows are handed to
+// the worker to be emailed, which queued reports.
+func (server *Server) prepareExport(ctx context.Context, req *pb.ExportTransactionsRequest) (arg models.ExportTransactionsParams, queued bool, err error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return arg, false, unauthenticatedError(err)
+	}
+
+	if violations := validateExportTransactionsRequest(req); violations != nil {
+		return arg, false, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return arg, false, err
+	}
+
+	arg = models.ExportTransactionsParams{
+		Account: account,
+		From:    req.GetFrom().AsTime(),
+		To:      req.GetTo().AsTime(),
+	}
+
+	if server.config.ExportMaxRows <= 0 {
+		return arg, false, nil
+	}
+
+	count, err := server.service.CountExportRows(ctx, arg)
+	if err != nil {
+		return arg, false, dbErrorToStatus(fmt.Errorf("failed to count export rows: %w", err))
+	}
+
+	if count <= int64(server.config.ExportMaxRows) {
+		return arg, false, nil
+	}
+
+	taskPayload := &worker.PayloadEmailTransactionExport{
+		Username:  authPayload.UserName,
+		AccountID: account.ID,
+		From:      arg.From,
+		To:        arg.To,
+	}
+	if err := server.taskDistributor.DistributeTaskEmailTransactionExport(ctx, taskPayload, asynq.MaxRetry(5)); err != nil {
+		return arg, false, status.Errorf(codes.Internal, "failed to queue transaction export: %s", err)
+	}
+
+	return arg, true, nil
+}
+
+// writeExport writes the export to w as CSV, calling flush every exportFlushRows rows
+func (server *Server) writeExport(ctx context.Context, arg models.ExportTransactionsParams, w io.Writer, flush func()) error {
+	cw, err := export.NewCSVWriter(w)
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	err = server.service.ExportTransactions(ctx, arg, func(row models.LedgerRow) error {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+
+		if rows++; rows%exportFlushRows == 0 {
+			if err := cw.Flush(); err != nil {
+				return err
+			}
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return cw.Flush()
+}
+
+func validateExportTransactionsRequest(req *pb.ExportTransactionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.GetFrom() == nil {
+		violations = append(violations, fieldViolation("from", fmt.Errorf("must be set")))
+	}
+
+	if req.GetTo() == nil {
+		violations = append(violations, fieldViolation("to", fmt.Errorf("must be set")))
+	} else if req.GetFrom() != nil && !req.GetTo().AsTime().After(req.GetFrom().AsTime()) {
+		violations = append(violations, fieldViolation("to", fmt.Errorf("must be after from")))
+	}
+
+	return violations
+}
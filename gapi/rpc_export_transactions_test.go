@@ -0,0 +1,215 @@
+package gapi
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/export"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportDistributor records the exports queued through it
+type exportDistributor struct {
+	worker.TaskDistributor
+	payloads []*worker.PayloadEmailTransactionExport
+}
+
+func (d *exportDistributor) DistributeTaskEmailTransactionExport(ctx context.Context, payload *worker.PayloadEmailTransactionExport, opts ...asynq.Option) error {
+	d.payloads = append(d.payloads, payload)
+	return nil
+}
+
+var (
+	exportAccount = models.Account{ID: 7, Owner: "ada", Currency: helpers.USD, Balance: 100}
+	exportFrom    = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	exportTo      = time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	exportArg     = models.ExportTransactionsParams{Account: exportAccount, From: exportFrom, To: exportTo}
+)
+
+// expectExport makes the service export n rows, the last one failing with err if it is not nil
+func expectExport(service *mockedproviders.MockServiceProvider, n int, err error) {
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(exportAccount.ID)).Times(1).Return(exportAccount, nil)
+	service.EXPECT().CountExportRows(gomock.Any(), gomock.Eq(exportArg)).Times(1).Return(int64(n), nil)
+	service.EXPECT().
+		ExportTransactions(gomock.Any(), gomock.Eq(exportArg), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.ExportTransactionsParams, fn func(models.LedgerRow) error) error {
+			for i := 1; i <= n; i++ {
+				row := models.LedgerRow{EntryID: int64(i), Date: exportFrom, Type: models.LedgerRowDeposit, Amount: 1, Currency: helpers.USD, Balance: int64(i), Memo: fmt.Sprintf("row %d, of %d", i, n)}
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+			return err
+		})
+}
+
+func newExportTestServer(t *testing.T, service *mockedproviders.MockServiceProvider, maxRows int) (*Server, *exportDistributor) {
+	server := newTestServer(t, service)
+	server.config.ExportMaxRows = maxRows
+
+	distributor := &exportDistributor{}
+	server.taskDistributor = distributor
+	return server, distributor
+}
+
+func TestExportTransactionsGrpc(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	expectExport(service, 2, nil)
+
+	server, distributor := newExportTestServer(t, service, 10)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	res, err := client.ExportTransactions(ctx, &pb.ExportTransactionsRequest{AccountId: exportAccount.ID, From: timestamppb.New(exportFrom), To: timestamppb.New(exportTo)})
+	require.NoError(t, err)
+	require.False(t, res.GetQueued())
+	require.Empty(t, distributor.payloads)
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(res.GetCsv()), export.BOM))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, "row 2, of 2", records[2][6])
+}
+
+func TestExportTransactionsGrpcQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(exportAccount.ID)).Times(1).Return(exportAccount, nil)
+	service.EXPECT().CountExportRows(gomock.Any(), gomock.Eq(exportArg)).Times(1).Return(int64(11), nil)
+	service.EXPECT().ExportTransactions(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	server, distributor := newExportTestServer(t, service, 10)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	res, err := client.ExportTransactions(ctx, &pb.ExportTransactionsRequest{AccountId: exportAccount.ID, From: timestamppb.New(exportFrom), To: timestamppb.New(exportTo)})
+	require.NoError(t, err)
+	require.True(t, res.GetQueued())
+	require.Empty(t, res.GetCsv())
+
+	require.Len(t, distributor.payloads, 1)
+	require.Equal(t, worker.PayloadEmailTransactionExport{Username: "ada", AccountID: exportAccount.ID, From: exportFrom, To: exportTo}, *distributor.payloads[0])
+}
+
+func TestExportTransactionsGrpcInvalidPeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server, _ := newExportTestServer(t, service, 10)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	_, err := client.ExportTransactions(ctx, &pb.ExportTransactionsRequest{AccountId: exportAccount.ID, From: timestamppb.New(exportTo), To: timestamppb.New(exportFrom)})
+	requireFieldViolation(t, err, "to")
+
+	_, err = client.ExportTransactions(ctx, &pb.ExportTransactionsRequest{AccountId: exportAccount.ID})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// newExportTestGateway routes the export the way main does, behind the gateway's auth
+func newExportTestGateway(server *Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+APIPathPrefix+"accounts/{account_id}/transactions/export", server.HttpExportTransactions)
+	return server.HttpAuth(mux)
+}
+
+func newExportRequest(t *testing.T, server *Server, query string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d/transactions/export?%s", exportAccount.ID, query), nil)
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	return request
+}
+
+const exportQuery = "from=2024-01-01T00:00:00Z&to=2024-04-01T00:00:00Z"
+
+func TestExportTransactionsHTTPStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	rows := exportFlushRows*2 + 1
+	expectExport(service, rows, nil)
+
+	server, _ := newExportTestServer(t, service, 1000)
+	recorder := httptest.NewRecorder()
+	newExportTestGateway(server).ServeHTTP(recorder, newExportRequest(t, server, exportQuery))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="transactions-7-2024-01-01-2024-04-01.csv"`, recorder.Header().Get("Content-Disposition"))
+	require.True(t, recorder.Flushed)
+
+	body := recorder.Body.String()
+	require.True(t, strings.HasPrefix(body, export.BOM+"date,type,counterparty,amount,currency,running balance,memo\n"))
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, export.BOM))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, rows+1)
+	require.Equal(t, fmt.Sprintf("row %d, of %d", rows, rows), records[rows][6])
+}
+
+func TestExportTransactionsHTTPQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(exportAccount.ID)).Times(1).Return(exportAccount, nil)
+	service.EXPECT().CountExportRows(gomock.Any(), gomock.Eq(exportArg)).Times(1).Return(int64(5000), nil)
+	service.EXPECT().ExportTransactions(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	server, distributor := newExportTestServer(t, service, 1000)
+	recorder := httptest.NewRecorder()
+	newExportTestGateway(server).ServeHTTP(recorder, newExportRequest(t, server, exportQuery))
+
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"queued":true}`, recorder.Body.String())
+	require.Len(t, distributor.payloads, 1)
+}
+
+func TestExportTransactionsHTTPInvalidQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server, _ := newExportTestServer(t, service, 1000)
+	handler := HttpRequestID(newExportTestGateway(server))
+
+	code, envelope := serveErrorEnvelope(t, handler, newExportRequest(t, server, "from=yesterday&to=2024-04-01T00:00:00Z"))
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, codes.InvalidArgument, envelope.Code)
+	require.Len(t, envelope.Details, 1)
+	require.Equal(t, "from", envelope.Details[0].Field)
+}
+
+func TestExportTransactionsHTTPFailsMidStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	expectExport(service, 3, fmt.Errorf("connection reset"))
+
+	server, _ := newExportTestServer(t, service, 1000)
+	recorder := httptest.NewRecorder()
+
+	// the status is already out, the connection is cut instead
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		newExportTestGateway(server).ServeHTTP(recorder, newExportRequest(t, server, exportQuery))
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,62 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) GetAccountSummary(ctx context.Context, req *pb.GetAccountSummaryRequest) (*pb.GetAccountSummaryResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := server.service.GetAccountSummary(ctx, models.GetAccountSummaryParams{
+		Account: account,
+		Year:    int(req.GetYear()),
+		Month:   int(req.GetMonth()),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get account summary: %w", err))
+	}
+
+	return convertAccountSummary(summary), nil
+}
+
+func convertAccountSummary(summary models.AccountSummary) *pb.GetAccountSummaryResponse {
+	rsp := &pb.GetAccountSummaryResponse{
+		AccountId:        summary.AccountID,
+		Currency:         summary.Currency,
+		Year:             int32(summary.Year),
+		Month:            int32(summary.Month),
+		PeriodStart:      timestamppb.New(summary.PeriodStart),
+		PeriodEnd:        timestamppb.New(summary.PeriodEnd),
+		Inflow:           money.New(summary.Inflow, summary.Currency).String(),
+		Outflow:          money.New(summary.Outflow, summary.Currency).String(),
+		NetChange:        money.New(summary.NetChange(), summary.Currency).String(),
+		TransactionCount: summary.Count,
+		Categories:       convertCategoryTotals(summary.Categories, summary.Currency),
+	}
+	if summary.Largest != nil {
+		rsp.LargestTransaction = convertEntry(*summary.Largest, summary.Currency)
+	}
+
+	return rsp
+}
@@ -0,0 +1,159 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAccountSummaryAPI(t *testing.T) {
+	account := models.Account{ID: 7, Owner: helpers.RandomOwner(), Currency: helpers.USD}
+	start := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC) // the account was opened that day
+	end := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		req           *pb.GetAccountSummaryRequest
+		owner         string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.GetAccountSummaryResponse, err error)
+	}{
+		{
+			name:  "OK",
+			req:   &pb.GetAccountSummaryRequest{AccountId: account.ID, Year: 2024, Month: 3},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.GetAccountSummaryParams{Account: account, Year: 2024, Month: 3}
+				service.EXPECT().GetAccountSummary(gomock.Any(), gomock.Eq(arg)).Times(1).Return(models.AccountSummary{
+					AccountID:   account.ID,
+					Currency:    account.Currency,
+					Year:        2024,
+					Month:       3,
+					PeriodStart: start,
+					PeriodEnd:   end,
+					Inflow:      150000,
+					Outflow:     98050,
+					Count:       4,
+					Largest:     &models.Entry{ID: 11, AccountID: account.ID, Amount: 150000, Category: "income"},
+					Categories: []models.CategoryTotal{
+						{Category: "housing", Out: 90000, Count: 1},
+						{In: 150000, Out: 8050, Count: 3},
+					},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountSummaryResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, start, res.GetPeriodStart().AsTime())
+				require.Equal(t, end, res.GetPeriodEnd().AsTime())
+				require.Equal(t, "1500.00", res.GetInflow())
+				require.Equal(t, "980.50", res.GetOutflow())
+				require.Equal(t, "519.50", res.GetNetChange())
+				require.Equal(t, int64(4), res.GetTransactionCount())
+				require.Equal(t, int64(11), res.GetLargestTransaction().GetId())
+				require.Equal(t, "income", res.GetLargestTransaction().GetCategory())
+				require.Len(t, res.GetCategories(), 2)
+				require.Equal(t, "900.00", res.GetCategories()[0].GetOut())
+				require.Empty(t, res.GetCategories()[1].GetCategory())
+			},
+		},
+		{
+			name:  "NoTransactions",
+			req:   &pb.GetAccountSummaryRequest{AccountId: account.ID, Year: 2024, Month: 2},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				service.EXPECT().GetAccountSummary(gomock.Any(), gomock.Any()).Times(1).Return(models.AccountSummary{
+					AccountID:   account.ID,
+					Currency:    account.Currency,
+					Year:        2024,
+					Month:       2,
+					PeriodStart: start,
+					PeriodEnd:   start,
+					Categories:  []models.CategoryTotal{},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountSummaryResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "0.00", res.GetNetChange())
+				require.Nil(t, res.GetLargestTransaction())
+				require.Empty(t, res.GetCategories())
+			},
+		},
+		{
+			name:  "NotOwner",
+			req:   &pb.GetAccountSummaryRequest{AccountId: account.ID, Year: 2024, Month: 3},
+			owner: helpers.RandomOwner(),
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				service.EXPECT().GetAccountSummary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountSummaryResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, tc.owner, helpers.DepositorRole)
+			res, err := client.GetAccountSummary(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetAccountSummaryValidate(t *testing.T) {
+	next := time.Now().UTC().AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name       string
+		req        *pb.GetAccountSummaryRequest
+		violations []violation
+	}{
+		{
+			name: "Nothing",
+			req:  &pb.GetAccountSummaryRequest{},
+			violations: []violation{
+				{Field: "account_id", Description: "must be a positive integer"},
+				{Field: "year", Description: "must be between 1970 and 9999"},
+				{Field: "month", Description: "must be between 1 and 12"},
+			},
+		},
+		{
+			name: "FutureMonth",
+			req:  &pb.GetAccountSummaryRequest{AccountId: 1, Year: int32(next.Year()), Month: int32(next.Month())},
+			violations: []violation{
+				{Field: "month", Description: "must not be in the future"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl)) // no call reaches the service
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, "alice", helpers.DepositorRole)
+			_, err := client.GetAccountSummary(ctx, tc.req)
+			requireViolations(t, err, tc.violations)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateGetBalanceRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := server.service.GetBalance(ctx, models.GetBalanceParams{
+		Account:         account,
+		DisplayCurrency: req.GetDisplayCurrency(),
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get balance: %w", err))
+	}
+
+	rsp := &pb.GetBalanceResponse{
+		AccountId:            balance.AccountID,
+		Balance:              money.New(balance.Balance, balance.Currency).String(),
+		Currency:             balance.Currency,
+		DisplayCurrency:      balance.DisplayCurrency,
+		ConvertedBalance:     money.New(balance.ConvertedBalance, balance.DisplayCurrency).String(),
+		Rate:                 balance.Rate,
+		ConvertedUnavailable: balance.ConvertedUnavailable,
+	}
+	if !balance.RateTimestamp.IsZero() {
+		rsp.RateTimestamp = timestamppb.New(balance.RateTimestamp)
+	}
+
+	return rsp, nil
+}
+
+func validateGetBalanceRequest(req *pb.GetBalanceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.DisplayCurrency != "" {
+		if err := val.ValidateCurrency(req.GetDisplayCurrency()); err != nil {
+			violations = append(violations, fieldViolation("display_currency", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,24 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/pb"
+)
+
+func (server *Server) GetNotificationPreferences(ctx context.Context, req *pb.GetNotificationPreferencesRequest) (*pb.GetNotificationPreferencesResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	preferences, err := server.service.GetNotificationPreferences(ctx, authPayload.UserName)
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get notification preferences: %w", err))
+	}
+
+	return &pb.GetNotificationPreferencesResponse{
+		Preferences: convertNotificationPreferences(preferences),
+	}, nil
+}
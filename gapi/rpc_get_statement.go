@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.GetStatementResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateGetStatementRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := server.service.GetStatement(ctx, models.GetStatementParams{
+		Account: account,
+		Year:    int(req.GetYear()),
+		Month:   int(req.GetMonth()),
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get statement: %w", err))
+	}
+
+	return convertStatement(st), nil
+}
+
+func validateGetStatementRequest(req *pb.GetStatementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateYear(req.GetYear()); err != nil {
+		violations = append(violations, fieldViolation("year", err))
+	}
+
+	if err := val.ValidateMonth(req.GetMonth()); err != nil {
+		violations = append(violations, fieldViolation("month", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateGetTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.service.GetTransaction(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get transfer: %w", err))
+	}
+
+	// depositors see the transfers of their own accounts, on either side
+	if authPayload.Role != helpers.BankerRole {
+		if _, err := server.authorizeAccount(ctx, authPayload, transfer.FromAccountID); err != nil {
+			if _, err := server.authorizeAccount(ctx, authPayload, transfer.ToAccountID); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		// bankers see any transfer, which is recorded as they may be looking at someone else's
+		server.audit.Record(ctx, authPayload.UserName, audit.ActionReadTransfer, audit.EntityTransfer, strconv.FormatInt(transfer.ID, 10), nil, nil)
+	}
+
+	return &pb.GetTransferResponse{
+		Transfer: convertTransfer(transfer),
+	}, nil
+}
+
+func validateGetTransferRequest(req *pb.GetTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTransferId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
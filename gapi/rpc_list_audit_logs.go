@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListAuditLogs(ctx context.Context, req *pb.ListAuditLogsRequest) (*pb.ListAuditLogsResponse, error) {
+	if violations := validateListAuditLogsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListAuditLogs", req.GetEntity(), req.GetEntityId()}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	logs, err := server.service.ListAuditLogs(ctx, models.ListAuditLogsParams{
+		Entity:   req.GetEntity(),
+		EntityID: req.GetEntityId(),
+		Limit:    pageSize + 1,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list audit logs: %w", err))
+	}
+
+	rsp := &pb.ListAuditLogsResponse{}
+	if len(logs) > int(pageSize) {
+		logs = logs[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, entry := range logs {
+		rsp.AuditLogs = append(rsp.AuditLogs, convertAuditLog(entry))
+	}
+
+	return rsp, nil
+}
+
+func validateListAuditLogsRequest(req *pb.ListAuditLogsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	entity := req.GetEntity()
+	if entity != audit.EntityUser && entity != audit.EntityAccount && entity != audit.EntityTransfer {
+		violations = append(violations, fieldViolation("entity", fmt.Errorf("must be %s, %s or %s", audit.EntityUser, audit.EntityAccount, audit.EntityTransfer)))
+	}
+
+	if req.GetEntityId() != "" {
+		if err := val.ValidateString(req.GetEntityId(), 1, 100); err != nil {
+			violations = append(violations, fieldViolation("entity_id", err))
+		}
+	}
+
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,153 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListAuditLogs(t *testing.T) {
+	logs := []models.AuditLog{
+		{ID: 3, Actor: "bob", Action: audit.ActionSetOverdraftLimit, Entity: audit.EntityAccount, EntityID: "7",
+			Before: json.RawMessage(`{"id":"7","overdraftLimit":0}`), After: json.RawMessage(`{"id":"7","overdraftLimit":500}`)},
+		{ID: 2, Actor: "bob", Action: audit.ActionSetOverdraftLimit, Entity: audit.EntityAccount, EntityID: "7"},
+		{ID: 1, Actor: "bob", Action: audit.ActionSetOverdraftLimit, Entity: audit.EntityAccount, EntityID: "7"},
+	}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		ListAuditLogs(gomock.Any(), gomock.Eq(models.ListAuditLogsParams{Entity: audit.EntityAccount, EntityID: "7", Limit: 3, Offset: 0})).
+		Times(1).
+		Return(logs, nil)
+	service.EXPECT().
+		ListAuditLogs(gomock.Any(), gomock.Eq(models.ListAuditLogsParams{Entity: audit.EntityAccount, EntityID: "7", Limit: 3, Offset: 2})).
+		Times(1).
+		Return(logs[2:], nil)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "bob", helpers.BankerRole)
+
+	req := &pb.ListAuditLogsRequest{Entity: audit.EntityAccount, EntityId: "7", PageSize: 2}
+	res, err := client.ListAuditLogs(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.GetAuditLogs(), 2)
+	require.NotEmpty(t, res.GetNextPageToken())
+
+	first := res.GetAuditLogs()[0]
+	require.Equal(t, audit.ActionSetOverdraftLimit, first.GetAction())
+	require.Equal(t, float64(0), first.GetBefore().GetStructValue().GetFields()["overdraftLimit"].GetNumberValue())
+	require.Equal(t, float64(500), first.GetAfter().GetStructValue().GetFields()["overdraftLimit"].GetNumberValue())
+	require.Nil(t, res.GetAuditLogs()[1].GetBefore())
+
+	// the page token is bound to the entity it was issued for
+	_, err = client.ListAuditLogs(ctx, &pb.ListAuditLogsRequest{Entity: audit.EntityAccount, EntityId: "8", PageToken: res.GetNextPageToken()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	req.PageToken = res.GetNextPageToken()
+	res, err = client.ListAuditLogs(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.GetAuditLogs(), 1)
+	require.Empty(t, res.GetNextPageToken())
+}
+
+func TestListAuditLogsInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	ctx := newContextWithBearerToken(t, server, "bob", helpers.BankerRole)
+	_, err := client.ListAuditLogs(ctx, &pb.ListAuditLogsRequest{Entity: "session"})
+	requireFieldViolation(t, err, "entity")
+
+	ctx = newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+	_, err = client.ListAuditLogs(ctx, &pb.ListAuditLogsRequest{Entity: audit.EntityUser, EntityId: "ada"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestSetOverdraftLimitAudited(t *testing.T) {
+	account := models.Account{ID: 7, Owner: "ada", Currency: helpers.USD}
+	updated := account
+	updated.OverdraftLimit = 50000
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	service.EXPECT().
+		SetOverdraftLimitTx(gomock.Any(), gomock.Eq(models.SetOverdraftLimitParams{AccountID: 7, Limit: 50000, ChangedBy: "bob"})).
+		Times(1).
+		Return(updated, nil)
+	var recorded models.CreateAuditLogParams
+	service.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error) {
+			recorded = arg
+			return models.AuditLog{}, nil
+		})
+
+	server := newTestServer(t, service)
+	server.audit = audit.NewRecorder(service, prometheus.NewRegistry())
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "bob", helpers.BankerRole)
+
+	res, err := client.SetOverdraftLimit(ctx, &pb.SetOverdraftLimitRequest{AccountId: 7, Limit: "500"})
+	require.NoError(t, err)
+	require.Equal(t, "500.00", res.GetAccount().GetOverdraftLimit())
+
+	require.Equal(t, "bob", recorded.Actor)
+	require.Equal(t, audit.ActionSetOverdraftLimit, recorded.Action)
+	require.Equal(t, audit.EntityAccount, recorded.Entity)
+	require.Equal(t, "7", recorded.EntityID)
+
+	var before, after map[string]any
+	require.NoError(t, json.Unmarshal(recorded.Before, &before))
+	require.NoError(t, json.Unmarshal(recorded.After, &after))
+	require.Equal(t, "0.00", before["overdraftLimit"])
+	require.Equal(t, "500.00", after["overdraftLimit"])
+}
+
+func TestAuditFailureDoesNotFailOperation(t *testing.T) {
+	user := models.User{UserName: "ada", FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	password := "a new secret"
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+	var recorded models.CreateAuditLogParams
+	service.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error) {
+			recorded = arg
+			return models.AuditLog{}, errors.New("connection refused")
+		})
+
+	server := newTestServer(t, service)
+	server.audit = audit.NewRecorder(service, prometheus.NewRegistry())
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+
+	_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Username: user.UserName, Password: &password})
+	require.NoError(t, err)
+	require.Equal(t, audit.ActionChangePassword, recorded.Action)
+	require.NotContains(t, string(recorded.After), "ashed") // the password hash is never recorded
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListBeneficiaries(ctx context.Context, req *pb.ListBeneficiariesRequest) (*pb.ListBeneficiariesResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListBeneficiariesRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListBeneficiaries", authPayload.UserName}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	beneficiaries, err := server.service.ListBeneficiaries(ctx, models.ListBeneficiariesParams{
+		Owner:  authPayload.UserName,
+		Limit:  pageSize + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list beneficiaries: %w", err))
+	}
+
+	rsp := &pb.ListBeneficiariesResponse{}
+	if len(beneficiaries) > int(pageSize) {
+		beneficiaries = beneficiaries[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, beneficiary := range beneficiaries {
+		rsp.Beneficiaries = append(rsp.Beneficiaries, convertBeneficiary(beneficiary))
+	}
+
+	return rsp, nil
+}
+
+func validateListBeneficiariesRequest(req *pb.ListBeneficiariesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,77 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListFailedTasks(ctx context.Context, req *pb.ListFailedTasksRequest) (*pb.ListFailedTasksResponse, error) {
+	if violations := validateListFailedTasksRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListFailedTasks", req.GetQueue()}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra task to find out whether there is a next page
+	tasks, err := server.taskInspector.ListFailedTasks(req.GetQueue(), int(offset), int(pageSize)+1)
+	if err != nil {
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			// nothing was ever enqueued on it
+			return &pb.ListFailedTasksResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to list failed tasks: %s", err)
+	}
+
+	rsp := &pb.ListFailedTasksResponse{}
+	if len(tasks) > int(pageSize) {
+		tasks = tasks[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, task := range tasks {
+		rsp.Tasks = append(rsp.Tasks, convertFailedTask(task))
+	}
+
+	return rsp, nil
+}
+
+func validateListFailedTasksRequest(req *pb.ListFailedTasksRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateQueue(req.GetQueue()); err != nil {
+		violations = append(violations, fieldViolation("queue", err))
+	}
+
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
+
+func validateQueue(queue string) error {
+	if queue != worker.QueueCritical && queue != worker.QueueDefault && queue != worker.QueueLow {
+		return fmt.Errorf("must be %s, %s or %s", worker.QueueCritical, worker.QueueDefault, worker.QueueLow)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.ListNotificationsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListNotificationsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListNotifications", authPayload.UserName}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	notifications, err := server.service.ListNotifications(ctx, models.ListNotificationsParams{
+		Username:        authPayload.UserName,
+		BeforeCreatedAt: cursor.LastTime,
+		BeforeID:        cursor.LastID,
+		Limit:           pageSize + 1,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list notifications: %w", err))
+	}
+
+	rsp := &pb.ListNotificationsResponse{}
+	if len(notifications) > int(pageSize) {
+		notifications = notifications[:pageSize]
+		last := notifications[len(notifications)-1]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{LastID: last.ID, LastTime: last.CreatedAt}, filters...)
+	}
+
+	for _, notification := range notifications {
+		rsp.Notifications = append(rsp.Notifications, convertNotification(notification))
+	}
+
+	return rsp, nil
+}
+
+func validateListNotificationsRequest(req *pb.ListNotificationsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
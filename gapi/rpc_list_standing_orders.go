@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListStandingOrders(ctx context.Context, req *pb.ListStandingOrdersRequest) (*pb.ListStandingOrdersResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListStandingOrdersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListStandingOrders", authPayload.UserName}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	orders, err := server.service.ListStandingOrders(ctx, models.ListStandingOrdersParams{
+		Owner:  authPayload.UserName,
+		Limit:  pageSize + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list standing orders: %w", err))
+	}
+
+	rsp := &pb.ListStandingOrdersResponse{}
+	if len(orders) > int(pageSize) {
+		orders = orders[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, order := range orders {
+		rsp.StandingOrders = append(rsp.StandingOrders, convertStandingOrder(order))
+	}
+
+	return rsp, nil
+}
+
+func validateListStandingOrdersRequest(req *pb.ListStandingOrdersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
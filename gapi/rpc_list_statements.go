@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListStatements(ctx context.Context, req *pb.ListStatementsRequest) (*pb.ListStatementsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListStatementsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []string{"ListStatements", authPayload.UserName, strconv.FormatInt(account.ID, 10)}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	documents, err := server.service.ListStatementDocuments(ctx, models.ListStatementDocumentsParams{
+		AccountID: account.ID,
+		Limit:     pageSize + 1,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list statements: %w", err))
+	}
+
+	rsp := &pb.ListStatementsResponse{}
+	if len(documents) > int(pageSize) {
+		documents = documents[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, document := range documents {
+		rsp.Statements = append(rsp.Statements, server.convertStatementDocumentWithURL(ctx, document))
+	}
+
+	return rsp, nil
+}
+
+// convertStatementDocumentWithURL signs a download link for ready statements. A statement whose
+// file cannot be signed is listed without one rather than failing the whole list.
+func (server *Server) convertStatementDocumentWithURL(ctx context.Context, document models.StatementDocument) *pb.StatementDocument {
+	if document.Status != models.StatementDocumentReady || server.files == nil {
+		return convertStatementDocument(document, "", time.Time{})
+	}
+
+	url, expiresAt, err := server.files.SignedURL(ctx, document.ObjectKey, server.config.StatementURLTTL)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int64("statement_id", document.ID).Msg("failed to sign statement url")
+		return convertStatementDocument(document, "", time.Time{})
+	}
+
+	return convertStatementDocument(document, url, expiresAt)
+}
+
+func validateListStatementsRequest(req *pb.ListStatementsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,193 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListTransfersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	// without a currency filter the bounds are in the currency of the account
+	currency := req.GetCurrency()
+	if currency == "" {
+		currency = account.Currency
+	}
+	if violations := validateAmountRange(req, currency); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := models.ListTransfersParams{
+		AccountID: req.GetAccountId(),
+		Direction: req.GetDirection(),
+		Currency:  req.GetCurrency(),
+		Category:  req.GetCategory(),
+		Tag:       strings.ToLower(strings.TrimSpace(req.GetTag())), // tags are stored normalized
+	}
+	if req.StartTime != nil {
+		arg.StartTime = sql.NullTime{Time: req.GetStartTime().AsTime(), Valid: true}
+	}
+	if req.EndTime != nil {
+		arg.EndTime = sql.NullTime{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+	if req.GetMinAmount() != "" {
+		minAmount, err := amountUnits(req.GetMinAmount(), currency)
+		if err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("min_amount", err)})
+		}
+		arg.MinAmount = sql.NullInt64{Int64: minAmount, Valid: true}
+	}
+	if req.GetMaxAmount() != "" {
+		maxAmount, err := amountUnits(req.GetMaxAmount(), currency)
+		if err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("max_amount", err)})
+		}
+		arg.MaxAmount = sql.NullInt64{Int64: maxAmount, Valid: true}
+	}
+
+	// a page token only continues the listing it came from
+	filters := []string{
+		"ListTransfers", authPayload.UserName, strconv.FormatInt(arg.AccountID, 10), arg.Direction, arg.Currency, arg.Category, arg.Tag,
+		formatNullTime(arg.StartTime), formatNullTime(arg.EndTime), formatNullInt(arg.MinAmount), formatNullInt(arg.MaxAmount),
+	}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	arg.BeforeCreatedAt, arg.BeforeID = cursor.LastTime, cursor.LastID
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	arg.Limit = pageSize + 1
+	transfers, err := server.service.ListTransfers(ctx, arg)
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list transfers: %w", err))
+	}
+
+	rsp := &pb.ListTransfersResponse{}
+	if len(transfers) > int(pageSize) {
+		transfers = transfers[:pageSize]
+		last := transfers[len(transfers)-1]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{LastID: last.ID, LastTime: last.CreatedAt}, filters...)
+	}
+
+	for _, transfer := range transfers {
+		rsp.Transfers = append(rsp.Transfers, convertTransfer(transfer))
+	}
+
+	return rsp, nil
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func formatNullInt(i sql.NullInt64) string {
+	if !i.Valid {
+		return ""
+	}
+
+	return strconv.FormatInt(i.Int64, 10)
+}
+
+func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.GetEndTime().AsTime().After(req.GetStartTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", fmt.Errorf("must be after start_time")))
+	}
+
+	if req.GetCurrency() != "" {
+		if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+			violations = append(violations, fieldViolation("currency", err))
+		}
+	}
+
+	if req.GetDirection() != "" {
+		if err := val.ValidateTransferDirection(req.GetDirection()); err != nil {
+			violations = append(violations, fieldViolation("direction", err))
+		}
+	}
+
+	if req.GetCategory() != "" {
+		if err := val.ValidateCategory(req.GetCategory()); err != nil {
+			violations = append(violations, fieldViolation("category", err))
+		}
+	}
+
+	if tag := strings.ToLower(strings.TrimSpace(req.GetTag())); tag != "" {
+		if err := val.ValidateTags([]string{tag}); err != nil {
+			violations = append(violations, fieldViolation("tag", err))
+		}
+	}
+
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
+
+// validateAmountRange checks the amount bounds, which are in currency
+func validateAmountRange(req *pb.ListTransfersRequest, currency string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetMinAmount() != "" {
+		if err := val.ValidateAmount(req.GetMinAmount(), currency); err != nil {
+			violations = append(violations, fieldViolation("min_amount", err))
+		}
+	}
+
+	if req.GetMaxAmount() != "" {
+		if err := val.ValidateAmount(req.GetMaxAmount(), currency); err != nil {
+			violations = append(violations, fieldViolation("max_amount", err))
+		}
+	}
+
+	if violations == nil && req.GetMinAmount() != "" && req.GetMaxAmount() != "" {
+		minAmount, minErr := amountUnits(req.GetMinAmount(), currency)
+		maxAmount, maxErr := amountUnits(req.GetMaxAmount(), currency)
+		switch {
+		case minErr != nil:
+			violations = append(violations, fieldViolation("min_amount", minErr))
+		case maxErr != nil:
+			violations = append(violations, fieldViolation("max_amount", maxErr))
+		case minAmount > maxAmount:
+			violations = append(violations, fieldViolation("min_amount", fmt.Errorf("must not be greater than max_amount")))
+		}
+	}
+
+	return violations
+}
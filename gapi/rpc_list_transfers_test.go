@@ -0,0 +1,189 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListTransfersPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+
+	account := models.Account{ID: 7, Owner: "ada", Currency: helpers.USD}
+	createdAt := time.Now().Add(-time.Hour).UTC()
+	transfers := []models.Transaction{
+		{ID: 30, FromAccountID: account.ID, ToAccountID: 8, Amount: 20, Currency: helpers.USD, CreatedAt: createdAt},
+		{ID: 29, FromAccountID: account.ID, ToAccountID: 8, Amount: 20, Currency: helpers.USD, CreatedAt: createdAt},
+		{ID: 28, FromAccountID: account.ID, ToAccountID: 8, Amount: 20, Currency: helpers.USD, CreatedAt: createdAt},
+	}
+
+	first := models.ListTransfersParams{
+		AccountID: account.ID,
+		Direction: models.TransferDirectionSent,
+		MinAmount: sql.NullInt64{Int64: 2000, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 2000, Valid: true},
+		Limit:     3,
+	}
+	second := first
+	second.BeforeCreatedAt, second.BeforeID = createdAt, 29
+
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+	gomock.InOrder(
+		service.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(first)).Return(transfers, nil),
+		service.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(second)).Return(transfers[2:], nil),
+	)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ada := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	req := &pb.ListTransfersRequest{AccountId: account.ID, Direction: models.TransferDirectionSent, MinAmount: "20", MaxAmount: "20.00", PageSize: 2}
+	page, err := client.ListTransfers(ada, req)
+	require.NoError(t, err)
+	require.Len(t, page.GetTransfers(), 2)
+	require.NotEmpty(t, page.GetNextPageToken())
+
+	req.PageToken = page.GetNextPageToken()
+	page, err = client.ListTransfers(ada, req)
+	require.NoError(t, err)
+	require.Len(t, page.GetTransfers(), 1)
+	require.Equal(t, int64(28), page.GetTransfers()[0].GetId())
+	require.Empty(t, page.GetNextPageToken())
+
+	// the token doesn't carry over to other filters
+	req.MaxAmount = "30"
+	_, err = client.ListTransfers(ada, req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	account := models.Account{ID: 7, Owner: "ada", Currency: helpers.USD}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListTransfersRequest
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.ListTransfersResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Currency: helpers.USD},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := models.ListTransfersParams{AccountID: account.ID, Currency: helpers.USD, Limit: defaultPageSize + 1}
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]models.Transaction{{ID: 1}}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 1)
+				require.Empty(t, res.GetNextPageToken())
+			},
+		},
+		{
+			name: "CategoryAndTag",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Category: "groceries", Tag: " Weekly "},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := models.ListTransfersParams{AccountID: account.ID, Category: "groceries", Tag: "weekly", Limit: defaultPageSize + 1}
+				transfers := []models.Transaction{{ID: 1, Category: "groceries", Tags: []string{"weekly"}}}
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 1)
+				require.Equal(t, "groceries", res.GetTransfers()[0].GetCategory())
+				require.Equal(t, []string{"weekly"}, res.GetTransfers()[0].GetTags())
+			},
+		},
+		{
+			name: "InvalidCategory",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Category: "Food & Drink"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireFieldViolation(t, err, "category")
+			},
+		},
+		{
+			name: "MinAboveMax",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, MinAmount: "30", MaxAmount: "20"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireFieldViolation(t, err, "min_amount")
+			},
+		},
+		{
+			name: "AmountTooPrecise",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, MaxAmount: "10.005"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireFieldViolation(t, err, "max_amount")
+			},
+		},
+		{
+			name: "InvalidDirection",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Direction: "sideways"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireFieldViolation(t, err, "direction")
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Currency: "XYZ"},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireFieldViolation(t, err, "currency")
+			},
+		},
+		{
+			name: "OtherOwner",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				other := account
+				other.Owner = "bob"
+				service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(other, nil)
+				service.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			res, err := client.ListTransfers(newContextWithBearerToken(t, server, "ada", helpers.DepositorRole), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListWebhooksRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"ListWebhooks", authPayload.UserName}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	webhooks, err := server.service.ListWebhooks(ctx, models.ListWebhooksParams{
+		Owner:  authPayload.UserName,
+		Limit:  pageSize + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to list webhooks: %w", err))
+	}
+
+	rsp := &pb.ListWebhooksResponse{}
+	if len(webhooks) > int(pageSize) {
+		webhooks = webhooks[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, webhook := range webhooks {
+		rsp.Webhooks = append(rsp.Webhooks, convertWebhook(webhook))
+	}
+
+	return rsp, nil
+}
+
+func validateListWebhooksRequest(req *pb.ListWebhooksRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
@@ -3,41 +3,47 @@ package gapi
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
 	"github.com/zde37/Swift_Bank/models"
 	"github.com/zde37/Swift_Bank/pb"
 	"github.com/zde37/Swift_Bank/val"
-	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"github.com/zde37/Swift_Bank/worker"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
-	if violations := validateLoginUserRequest(req); violations != nil {
-		return nil, invalidArgumentError(violations)
-	}
+	req.Username = val.NormalizeUsername(req.GetUsername())
 
 	user, err := server.service.LoginUser(ctx, models.LoginUserRequest{
 		UserName: req.GetUsername(),
 		Password: req.GetPassword(),
 	})
 	if err != nil {
+		// a missing user is reported like a wrong password so usernames can't be probed
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			return nil, status.Errorf(codes.Internal, "a db error occurred: %s", err)
-
+		if errors.As(err, &pgErr) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, dbErrorToStatus(fmt.Errorf("failed to login user: %w", err))
 		}
 		return nil, status.Errorf(codes.InvalidArgument, "failed to login user: %s", err)
 	}
 
-	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(req.GetUsername(), server.config.AccessTokenDuration)
+	// checked after the password, so the answer tells nothing to someone who doesn't know it
+	if server.config.RequireVerifiedEmail && !user.IsEmailVerified {
+		return nil, emailNotVerifiedError()
+	}
+
+	accessToken, accessTokenPayload, err := server.tokenMaker.CreateToken(user.UserName, user.Role, server.config.AccessTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
 	}
 
-	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(req.GetUsername(), server.config.RefreshTokenDuration)
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(user.UserName, user.Role, server.config.RefreshTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
 	}
@@ -53,7 +59,19 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		ExpiresAt:    refreshTokenPayload.ExpiredAt,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create session: %w", err))
+	}
+
+	// a failure to queue the new device check must not keep the user from logging in
+	taskPayload := &worker.PayloadNotifyNewLogin{
+		SessionID: session.ID,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(worker.QueueDefault),
+	}
+	if err := server.taskDistributor.DistributeTaskNotifyNewLogin(ctx, taskPayload, opts...); err != nil {
+		log.Error().Err(err).Str("username", session.UserName).Msg("failed to queue new login notification")
 	}
 
 	return &pb.LoginUserResponse{
@@ -65,15 +83,3 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		User:                  convertUser(user),
 	}, nil
 }
-
-func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := val.ValidateUsername(req.GetUsername()); err != nil {
-		violations = append(violations, fieldViolation("username", err))
-	}
-
-	if err := val.ValidatePassword(req.GetPassword()); err != nil {
-		violations = append(violations, fieldViolation("password", err))
-	}
-
-	return violations
-}
@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) MarkNotificationRead(ctx context.Context, req *pb.MarkNotificationReadRequest) (*pb.MarkNotificationReadResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateMarkNotificationReadRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	notification, err := server.service.GetNotification(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "notification not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get notification: %w", err))
+	}
+
+	// other users' notifications are reported as missing so their ids can't be probed
+	if notification.Username != authPayload.UserName {
+		return nil, status.Errorf(codes.NotFound, "notification not found")
+	}
+
+	if notification.ReadAt == nil {
+		notification, err = server.service.MarkNotificationRead(ctx, notification.ID)
+		if err != nil {
+			return nil, dbErrorToStatus(fmt.Errorf("failed to mark notification read: %w", err))
+		}
+	}
+
+	return &pb.MarkNotificationReadResponse{
+		Notification: convertNotification(notification),
+	}, nil
+}
+
+func validateMarkNotificationReadRequest(req *pb.MarkNotificationReadRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateNotificationId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
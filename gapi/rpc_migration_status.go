@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) MigrationStatus(ctx context.Context, req *pb.MigrationStatusRequest) (*pb.MigrationStatusResponse, error) {
+	migration, err := server.service.GetMigrationStatus(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no migration has been applied")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get migration status: %w", err))
+	}
+
+	return &pb.MigrationStatusResponse{
+		Version:   migration.Version,
+		Dirty:     migration.Dirty,
+		AppliedAt: timestamppb.New(migration.AppliedAt),
+	}, nil
+}
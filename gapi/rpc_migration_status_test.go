@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMigrationStatusAPI(t *testing.T) {
+	appliedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	gomock.InOrder(
+		service.EXPECT().GetMigrationStatus(gomock.Any()).Times(1).
+			Return(models.MigrationStatus{Version: 33, Dirty: true, AppliedAt: appliedAt}, nil),
+		service.EXPECT().GetMigrationStatus(gomock.Any()).Times(1).
+			Return(models.MigrationStatus{}, pgx.ErrNoRows),
+	)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	banker := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.BankerRole)
+	depositor := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.DepositorRole)
+
+	_, err := client.MigrationStatus(depositor, &pb.MigrationStatusRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	res, err := client.MigrationStatus(banker, &pb.MigrationStatusRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int64(33), res.GetVersion())
+	require.True(t, res.GetDirty())
+	require.Equal(t, appliedAt, res.GetAppliedAt().AsTime())
+
+	_, err = client.MigrationStatus(banker, &pb.MigrationStatusRequest{})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/statement"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// statementPDFMaxRetry bounds the attempts to render a statement before it is marked failed
+const statementPDFMaxRetry = 3
+
+func (server *Server) RequestStatement(ctx context.Context, req *pb.RequestStatementRequest) (*pb.RequestStatementResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateRequestStatementRequest(req, time.Now()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := server.service.RequestStatementTx(ctx, models.RequestStatementTxParams{
+		AccountID:   account.ID,
+		Year:        int(req.GetYear()),
+		Month:       int(req.GetMonth()),
+		RequestedBy: authPayload.UserName,
+		AfterRequest: func(document models.StatementDocument) ([]models.OutboxMessage, error) {
+			// the relay enqueues the rendering once the request is committed
+			message, err := worker.NewOutboxMessage(ctx, worker.TaskGenerateStatementPDF, &worker.PayloadGenerateStatementPDF{
+				StatementID: document.ID,
+			}, worker.QueueLow, statementPDFMaxRetry)
+			if err != nil {
+				return nil, err
+			}
+			return []models.OutboxMessage{message}, nil
+		},
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to request statement: %w", err))
+	}
+
+	return &pb.RequestStatementResponse{
+		Statement: convertStatementDocument(document, "", time.Time{}),
+	}, nil
+}
+
+func validateRequestStatementRequest(req *pb.RequestStatementRequest, now time.Time) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateYear(req.GetYear()); err != nil {
+		violations = append(violations, fieldViolation("year", err))
+	}
+
+	if err := val.ValidateMonth(req.GetMonth()); err != nil {
+		violations = append(violations, fieldViolation("month", err))
+	}
+
+	if violations == nil {
+		if start, _ := statement.Period(int(req.GetYear()), int(req.GetMonth())); start.After(now) {
+			violations = append(violations, fieldViolation("month", fmt.Errorf("must not be in the future")))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,135 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/storage"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var statementAccount = models.Account{ID: 7, Owner: "ada", Currency: helpers.USD}
+
+func TestRequestStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(statementAccount.ID)).Times(1).Return(statementAccount, nil)
+	service.EXPECT().
+		RequestStatementTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.RequestStatementTxParams) (models.StatementDocument, error) {
+			require.Equal(t, int64(7), arg.AccountID)
+			require.Equal(t, 2024, arg.Year)
+			require.Equal(t, 3, arg.Month)
+			require.Equal(t, "ada", arg.RequestedBy)
+
+			document := models.StatementDocument{ID: 3, AccountID: arg.AccountID, Year: arg.Year, Month: arg.Month, Status: models.StatementDocumentPending}
+			messages, err := arg.AfterRequest(document)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			require.Equal(t, worker.TaskGenerateStatementPDF, messages[0].TaskType)
+			require.Equal(t, worker.QueueLow, messages[0].Queue)
+			require.Equal(t, int32(statementPDFMaxRetry), messages[0].MaxRetry)
+
+			var payload worker.PayloadGenerateStatementPDF
+			require.NoError(t, json.Unmarshal(messages[0].Payload, &payload))
+			require.Equal(t, int64(3), payload.StatementID)
+
+			return document, nil
+		})
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	res, err := client.RequestStatement(ctx, &pb.RequestStatementRequest{AccountId: 7, Year: 2024, Month: 3})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.GetStatement().GetId())
+	require.Equal(t, models.StatementDocumentPending, res.GetStatement().GetStatus())
+	require.Empty(t, res.GetStatement().GetDownloadUrl())
+}
+
+func TestRequestStatementInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(statementAccount.ID)).Times(1).Return(statementAccount, nil)
+	service.EXPECT().RequestStatementTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, service)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	next := time.Now().UTC().AddDate(0, 1, 0)
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+	_, err := client.RequestStatement(ctx, &pb.RequestStatementRequest{AccountId: 7, Year: int32(next.Year()), Month: int32(next.Month())})
+	requireFieldViolation(t, err, "month")
+
+	_, err = client.RequestStatement(ctx, &pb.RequestStatementRequest{AccountId: 7, Year: 2024, Month: 13})
+	requireFieldViolation(t, err, "month")
+
+	ctx = newContextWithBearerToken(t, server, "mallory", helpers.DepositorRole)
+	_, err = client.RequestStatement(ctx, &pb.RequestStatementRequest{AccountId: 7, Year: 2024, Month: 3})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestListStatements(t *testing.T) {
+	files, err := storage.NewFileStore(t.TempDir(), "https://bank.example/v1/files", helpers.RandomString(32))
+	require.NoError(t, err)
+	require.NoError(t, files.Put(context.Background(), "statements/7/2024-03.pdf", "application/pdf", []byte("%PDF-1.4")))
+
+	documents := []models.StatementDocument{
+		{ID: 3, AccountID: 7, Year: 2024, Month: 3, Status: models.StatementDocumentReady, ObjectKey: "statements/7/2024-03.pdf"},
+		{ID: 2, AccountID: 7, Year: 2024, Month: 2, Status: models.StatementDocumentFailed, FailureReason: "the PDF could not be generated after 4 attempts"},
+		{ID: 1, AccountID: 7, Year: 2024, Month: 1, Status: models.StatementDocumentPending},
+	}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(statementAccount.ID)).Times(2).Return(statementAccount, nil)
+	service.EXPECT().
+		ListStatementDocuments(gomock.Any(), gomock.Eq(models.ListStatementDocumentsParams{AccountID: 7, Limit: 3, Offset: 0})).
+		Times(1).
+		Return(documents, nil)
+	service.EXPECT().
+		ListStatementDocuments(gomock.Any(), gomock.Eq(models.ListStatementDocumentsParams{AccountID: 7, Limit: 3, Offset: 2})).
+		Times(1).
+		Return(documents[2:], nil)
+
+	server := newTestServer(t, service)
+	server.files = files
+	server.config.StatementURLTTL = time.Hour
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+
+	res, err := client.ListStatements(ctx, &pb.ListStatementsRequest{AccountId: 7, PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, res.GetStatements(), 2)
+	require.NotEmpty(t, res.GetNextPageToken())
+
+	ready := res.GetStatements()[0]
+	require.True(t, strings.HasPrefix(ready.GetDownloadUrl(), "https://bank.example/v1/files/statements/7/2024-03.pdf?"))
+	require.WithinDuration(t, time.Now().Add(time.Hour), ready.GetDownloadUrlExpiresAt().AsTime(), 2*time.Second)
+
+	failed := res.GetStatements()[1]
+	require.Equal(t, models.StatementDocumentFailed, failed.GetStatus())
+	require.Equal(t, documents[1].FailureReason, failed.GetFailureReason())
+	require.Empty(t, failed.GetDownloadUrl())
+	require.Nil(t, failed.GetDownloadUrlExpiresAt())
+
+	res, err = client.ListStatements(ctx, &pb.ListStatementsRequest{AccountId: 7, PageSize: 2, PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, res.GetStatements(), 1)
+	require.Empty(t, res.GetNextPageToken())
+	require.Empty(t, res.GetStatements()[0].GetDownloadUrl())
+}
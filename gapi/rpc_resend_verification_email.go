@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// ResendVerificationEmail sends a new link to an unverified user. It needs no login, as unverified
+// users cannot log in when verified emails are required, and it answers the same for unknown and
+// already verified addresses so it cannot be used to find out who banks here.
+func (server *Server) ResendVerificationEmail(ctx context.Context, req *pb.ResendVerificationEmailRequest) (*pb.ResendVerificationEmailResponse, error) {
+	req.Email = val.NormalizeEmail(req.GetEmail())
+
+	if violations := validateResendVerificationEmailRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.service.GetUserByEmail(ctx, req.GetEmail())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &pb.ResendVerificationEmailResponse{}, nil
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get user: %w", err))
+	}
+
+	if user.IsEmailVerified {
+		return &pb.ResendVerificationEmailResponse{}, nil
+	}
+
+	// without a verify email id the task creates a new code
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+	if err := server.taskDistributor.DistributeTaskSendVerifyEmail(ctx, &worker.PayloadSendVerifyEmail{Username: user.UserName}, opts...); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("username", user.UserName).Msg("failed to queue verification email")
+	}
+
+	return &pb.ResendVerificationEmailResponse{}, nil
+}
+
+func validateResendVerificationEmailRequest(req *pb.ResendVerificationEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
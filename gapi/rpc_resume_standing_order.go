@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ResumeStandingOrder(ctx context.Context, req *pb.ResumeStandingOrderRequest) (*pb.ResumeStandingOrderResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateResumeStandingOrderRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err := server.authorizeStandingOrder(ctx, authPayload, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	order, err := server.service.ResumeStandingOrder(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, service.ErrStandingOrderStatus) || errors.Is(err, service.ErrStandingOrderEnded) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to resume standing order: %w", err))
+	}
+
+	return &pb.ResumeStandingOrderResponse{
+		StandingOrder: convertStandingOrder(order),
+	}, nil
+}
+
+func validateResumeStandingOrderRequest(req *pb.ResumeStandingOrderRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateStandingOrderId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
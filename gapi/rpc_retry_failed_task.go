@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hibiken/asynq"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RetryFailedTask(ctx context.Context, req *pb.RetryFailedTaskRequest) (*pb.RetryFailedTaskResponse, error) {
+	if violations := validateRetryFailedTaskRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.taskInspector.RetryFailedTask(req.GetQueue(), req.GetId()); err != nil {
+		if errors.Is(err, asynq.ErrQueueNotFound) || errors.Is(err, asynq.ErrTaskNotFound) {
+			return nil, status.Errorf(codes.NotFound, "failed task not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retry task: %s", err)
+	}
+
+	return &pb.RetryFailedTaskResponse{}, nil
+}
+
+func validateRetryFailedTaskRequest(req *pb.RetryFailedTaskRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateQueue(req.GetQueue()); err != nil {
+		violations = append(violations, fieldViolation("queue", err))
+	}
+
+	if err := val.ValidateTaskId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateReverseTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := server.service.ReverseTransfer(ctx, models.ReverseTransferParams{
+		TransactionID: req.GetTransferId(),
+		ReversedBy:    authPayload.UserName,
+		Reason:        strings.TrimSpace(req.GetReason()),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		if errors.Is(err, service.ErrTransferAlreadyReversed) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		if errors.Is(err, service.ErrReversalNotCovered) || errors.Is(err, service.ErrReverseReversal) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to reverse transfer: %w", err))
+	}
+
+	// the original could only be reversed because nothing had reversed it yet
+	before := result.Original
+	before.ReversedBy = 0
+	server.audit.Record(ctx, authPayload.UserName, audit.ActionReverseTransfer, audit.EntityTransfer, strconv.FormatInt(before.ID, 10), convertTransfer(before), convertTransfer(result.Original))
+
+	return &pb.ReverseTransferResponse{
+		Original: convertTransfer(result.Original),
+		Reversal: convertTransfer(result.Reversal.Transaction),
+	}, nil
+}
+
+func validateReverseTransferRequest(req *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTransferId(req.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	if err := val.ValidateReversalReason(req.GetReason()); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+
+	return violations
+}
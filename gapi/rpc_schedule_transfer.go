@@ -0,0 +1,113 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ScheduleTransfer(ctx context.Context, req *pb.ScheduleTransferRequest) (*pb.ScheduleTransferResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateScheduleTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.requireVerifiedEmail(ctx, authPayload.UserName); err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	amount, err := amountUnits(req.GetAmount(), req.GetCurrency())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+	}
+
+	transfer, err := server.service.ScheduleTransferTx(ctx, models.CreateScheduledTransferTxParams{
+		Transfer: models.ScheduledTransfer{
+			FromAccountID: req.GetFromAccountId(),
+			ToAccountID:   req.GetToAccountId(),
+			Amount:        amount,
+			Currency:      req.GetCurrency(),
+			Description:   val.NormalizeDescription(req.GetDescription()),
+			ExecuteAt:     req.GetExecuteAt().AsTime(),
+			CreatedBy:     authPayload.UserName,
+		},
+		AfterCreate: func(transfer models.ScheduledTransfer) error {
+			taskPayload := &worker.PayloadExecuteScheduledTransfer{
+				ID: transfer.ID,
+			}
+			opts := []asynq.Option{
+				asynq.MaxRetry(10),
+				asynq.ProcessIn(time.Until(transfer.ExecuteAt)),
+				asynq.Queue(worker.QueueCritical),
+				asynq.TaskID(fmt.Sprintf("scheduled-transfer-%d", transfer.ID)),
+			}
+			return server.taskDistributor.DistributeTaskExecuteScheduledTransfer(ctx, taskPayload, opts...)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to schedule transfer: %w", err))
+	}
+
+	return &pb.ScheduleTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(transfer),
+	}, nil
+}
+
+func validateScheduleTransferRequest(req *pb.ScheduleTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := val.ValidateAccountId(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must differ from from_account_id")))
+	}
+
+	if err := val.ValidateAmount(req.GetAmount(), req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateDescription(val.NormalizeDescription(req.GetDescription())); err != nil {
+		violations = append(violations, fieldViolation("description", err))
+	}
+
+	if req.GetExecuteAt() == nil {
+		violations = append(violations, fieldViolation("execute_at", fmt.Errorf("must be set")))
+	} else if err := val.ValidateExecuteAt(req.GetExecuteAt().AsTime()); err != nil {
+		violations = append(violations, fieldViolation("execute_at", err))
+	}
+
+	return violations
+}
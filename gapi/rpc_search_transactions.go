@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pagination"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) SearchTransactions(ctx context.Context, req *pb.SearchTransactionsRequest) (*pb.SearchTransactionsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateSearchTransactionsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	filters := []string{"SearchTransactions", authPayload.UserName, strconv.FormatInt(req.GetAccountId(), 10), req.GetQuery()}
+	cursor, err := server.decodePageToken(req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor.Offset
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	transfers, err := server.service.SearchTransactions(ctx, models.SearchTransactionsParams{
+		Owner:     authPayload.UserName,
+		AccountID: req.GetAccountId(),
+		Query:     req.GetQuery(),
+		Limit:     pageSize + 1,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to search transactions: %w", err))
+	}
+
+	rsp := &pb.SearchTransactionsResponse{}
+	if len(transfers) > int(pageSize) {
+		transfers = transfers[:pageSize]
+		rsp.NextPageToken = server.encodePageToken(pagination.Cursor{Offset: offset + pageSize}, filters...)
+	}
+
+	for _, transfer := range transfers {
+		rsp.Transfers = append(rsp.Transfers, convertTransfer(transfer))
+	}
+
+	return rsp, nil
+}
+
+func validateSearchTransactionsRequest(req *pb.SearchTransactionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateSearchQuery(req.GetQuery()); err != nil {
+		violations = append(violations, fieldViolation("query", err))
+	}
+
+	if req.GetAccountId() != 0 {
+		if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+			violations = append(violations, fieldViolation("account_id", err))
+		}
+	}
+
+	if req.GetPageSize() != 0 {
+		if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ServerInfo describes the instance answering: its build, uptime, configuration with the secrets
+// masked and Go runtime stats
+func (server *Server) ServerInfo(ctx context.Context, req *pb.ServerInfoRequest) (*pb.ServerInfoResponse, error) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	rsp := &pb.ServerInfoResponse{
+		Version: server.build.Version,
+		Commit:  server.build.Commit,
+		Config:  server.config.Summary(),
+		Runtime: &pb.RuntimeStats{
+			GoVersion:      runtime.Version(),
+			Goroutines:     int32(runtime.NumGoroutine()),
+			NumCpu:         int32(runtime.NumCPU()),
+			Gomaxprocs:     int32(runtime.GOMAXPROCS(0)),
+			HeapAllocBytes: memStats.HeapAlloc,
+			HeapSysBytes:   memStats.HeapSys,
+			NumGc:          memStats.NumGC,
+		},
+	}
+	if !server.build.StartedAt.IsZero() {
+		rsp.StartedAt = timestamppb.New(server.build.StartedAt)
+		rsp.Uptime = durationpb.New(time.Since(server.build.StartedAt).Round(time.Second))
+	}
+
+	return rsp, nil
+}
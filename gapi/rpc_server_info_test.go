@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerInfoAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl))
+	server.config.Environment = "production"
+	server.build = BuildInfo{Version: "1.4.0", Commit: "abc1234", StartedAt: time.Now().Add(-time.Hour)}
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	_, err := client.ServerInfo(context.Background(), &pb.ServerInfoRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	depositor := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.DepositorRole)
+	_, err = client.ServerInfo(depositor, &pb.ServerInfoRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	banker := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.BankerRole)
+	rsp, err := client.ServerInfo(banker, &pb.ServerInfoRequest{})
+	require.NoError(t, err)
+
+	require.Equal(t, "1.4.0", rsp.GetVersion())
+	require.Equal(t, "abc1234", rsp.GetCommit())
+	require.True(t, server.build.StartedAt.Equal(rsp.GetStartedAt().AsTime()))
+	require.InDelta(t, time.Hour.Seconds(), rsp.GetUptime().AsDuration().Seconds(), 5)
+
+	require.Equal(t, "production", rsp.GetConfig()["ENVIRONMENT"])
+	require.Equal(t, "****", rsp.GetConfig()["TOKEN_SYMMETRIC_KEY"])
+	require.NotContains(t, rsp.GetConfig(), "")
+
+	require.Equal(t, runtime.Version(), rsp.GetRuntime().GetGoVersion())
+	require.Positive(t, rsp.GetRuntime().GetGoroutines())
+	require.Positive(t, rsp.GetRuntime().GetHeapAllocBytes())
+}
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetLogLevel changes the log level of this instance only, each replica keeps its own
+func (server *Server) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateSetLogLevelRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if server.logLevels == nil {
+		return nil, status.Errorf(codes.Unimplemented, "log level cannot be changed on this server")
+	}
+
+	level, err := zerolog.ParseLevel(req.GetLevel())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("level", err)})
+	}
+
+	duration := req.GetDuration().AsDuration()
+	revertAt := server.logLevels.Set(level, duration)
+	// logged at the level the service always logs at, or it may not show
+	log.WithLevel(zerolog.NoLevel).
+		Str("actor", authPayload.UserName).
+		Str("level", level.String()).
+		Dur("duration", duration).
+		Msg("log level changed")
+
+	rsp := &pb.SetLogLevelResponse{
+		Level:    level.String(),
+		RevertAt: timestamppb.New(revertAt),
+	}
+
+	return rsp, nil
+}
+
+func validateSetLogLevelRequest(req *pb.SetLogLevelRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateLogLevel(req.GetLevel()); err != nil {
+		violations = append(violations, fieldViolation("level", err))
+	}
+
+	if err := req.GetDuration().CheckValid(); err != nil {
+		violations = append(violations, fieldViolation("duration", err))
+	} else if err := val.ValidateLogLevelDuration(req.GetDuration().AsDuration()); err != nil {
+		violations = append(violations, fieldViolation("duration", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/loglevel"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSetLogLevelAPI(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	ctrl := gomock.NewController(t)
+	server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl))
+	server.logLevels = loglevel.NewController(zerolog.InfoLevel)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	banker := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.BankerRole)
+	depositor := newContextWithBearerToken(t, server, helpers.RandomOwner(), helpers.DepositorRole)
+
+	testCases := []struct {
+		name string
+		req  *pb.SetLogLevelRequest
+		code codes.Code
+	}{
+		{name: "UnknownLevel", req: &pb.SetLogLevelRequest{Level: "verbose", Duration: durationpb.New(time.Minute)}, code: codes.InvalidArgument},
+		{name: "NoDuration", req: &pb.SetLogLevelRequest{Level: "debug"}, code: codes.InvalidArgument},
+		{name: "DurationTooLong", req: &pb.SetLogLevelRequest{Level: "debug", Duration: durationpb.New(48 * time.Hour)}, code: codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.SetLogLevel(banker, tc.req)
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+		})
+	}
+
+	_, err := client.SetLogLevel(depositor, &pb.SetLogLevelRequest{Level: "debug", Duration: durationpb.New(time.Minute)})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+
+	res, err := client.SetLogLevel(banker, &pb.SetLogLevelRequest{Level: "debug", Duration: durationpb.New(100 * time.Millisecond)})
+	require.NoError(t, err)
+	require.Equal(t, "debug", res.GetLevel())
+	require.WithinDuration(t, time.Now().Add(100*time.Millisecond), res.GetRevertAt().AsTime(), time.Second)
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+
+	require.Eventually(t, func() bool { return zerolog.GlobalLevel() == zerolog.InfoLevel }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) SetOverdraftLimit(ctx context.Context, req *pb.SetOverdraftLimitRequest) (*pb.SetOverdraftLimitResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateSetOverdraftLimitRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	before, err := server.service.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get account: %w", err))
+	}
+
+	// the limit is in the currency of the account
+	if err := val.ValidateOverdraftLimit(req.GetLimit(), before.Currency); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("limit", err)})
+	}
+	limit, err := amountUnits(req.GetLimit(), before.Currency)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("limit", err)})
+	}
+
+	account, err := server.service.SetOverdraftLimitTx(ctx, models.SetOverdraftLimitParams{
+		AccountID: req.GetAccountId(),
+		Limit:     limit,
+		ChangedBy: authPayload.UserName,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, service.ErrSavingsOverdraft) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to set overdraft limit: %w", err))
+	}
+
+	server.audit.Record(ctx, authPayload.UserName, audit.ActionSetOverdraftLimit, audit.EntityAccount, strconv.FormatInt(account.ID, 10), convertAccount(before), convertAccount(account))
+
+	rsp := &pb.SetOverdraftLimitResponse{
+		Account: convertAccount(account),
+	}
+
+	return rsp, nil
+}
+
+func validateSetOverdraftLimitRequest(req *pb.SetOverdraftLimitRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if req.GetLimit() == "" {
+		violations = append(violations, fieldViolation("limit", fmt.Errorf("must be set")))
+	}
+
+	return violations
+}
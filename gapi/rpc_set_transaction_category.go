@@ -0,0 +1,104 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) SetTransactionCategory(ctx context.Context, req *pb.SetTransactionCategoryRequest) (*pb.SetTransactionCategoryResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	entry, err := server.service.GetEntry(ctx, req.GetEntryId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "entry not found")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get entry: %w", err))
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, entry.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := models.SetEntryCategoryParams{
+		EntryID: entry.ID,
+	}
+	fields := setTransactionCategoryFields(req)
+	if fields["category"] {
+		arg.Category = sql.NullString{String: req.GetCategory(), Valid: true}
+	}
+	if fields["tags"] {
+		arg.Tags = val.NormalizeTags(req.GetTags())
+		if arg.Tags == nil {
+			arg.Tags = []string{} // named by the mask and left empty, the tags are removed
+		}
+	}
+
+	entry, err = server.service.SetEntryCategory(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "entry not found")
+		}
+		if errors.Is(err, service.ErrUnknownCategory) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to set entry category: %w", err))
+	}
+
+	rsp := &pb.SetTransactionCategoryResponse{
+		Entry: convertEntry(entry, account.Currency),
+	}
+
+	return rsp, nil
+}
+
+// categorizableEntryFields are the update_mask paths SetTransactionCategory accepts
+var categorizableEntryFields = map[string]bool{
+	"category": true,
+	"tags":     true,
+}
+
+// setTransactionCategoryPaths returns the update_mask paths of req, or without a mask the fields
+// set in req. Like the metadata of UpdateAccount, removing all tags takes a mask.
+func setTransactionCategoryPaths(req *pb.SetTransactionCategoryRequest) []string {
+	if req.UpdateMask != nil {
+		return req.GetUpdateMask().GetPaths()
+	}
+
+	var paths []string
+	if req.Category != nil {
+		paths = append(paths, "category")
+	}
+	if len(req.GetTags()) > 0 {
+		paths = append(paths, "tags")
+	}
+
+	return paths
+}
+
+// setTransactionCategoryFields returns the fields the request changes, GrpcValidate already
+// rejected the paths naming other fields
+func setTransactionCategoryFields(req *pb.SetTransactionCategoryRequest) map[string]bool {
+	paths := setTransactionCategoryPaths(req)
+	fields := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if categorizableEntryFields[path] {
+			fields[path] = true
+		}
+	}
+	return fields
+}
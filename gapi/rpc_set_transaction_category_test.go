@@ -0,0 +1,194 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestSetTransactionCategoryAPI(t *testing.T) {
+	account := models.Account{ID: 7, Owner: helpers.RandomOwner(), Currency: helpers.USD}
+	entry := models.Entry{ID: 42, AccountID: account.ID, Amount: -1050}
+	groceries := "groceries"
+	empty := ""
+
+	testCases := []struct {
+		name          string
+		req           *pb.SetTransactionCategoryRequest
+		owner         string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error)
+	}{
+		{
+			name:  "OK",
+			req:   &pb.SetTransactionCategoryRequest{EntryId: entry.ID, Category: &groceries, Tags: []string{"Weekly", "market", "weekly"}},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetEntry(gomock.Any(), entry.ID).Times(1).Return(entry, nil)
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.SetEntryCategoryParams{
+					EntryID:  entry.ID,
+					Category: sql.NullString{String: "groceries", Valid: true},
+					Tags:     []string{"market", "weekly"},
+				}
+				updated := entry
+				updated.Category = "groceries"
+				updated.Tags = arg.Tags
+				service.EXPECT().SetEntryCategory(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "groceries", res.GetEntry().GetCategory())
+				require.Equal(t, []string{"market", "weekly"}, res.GetEntry().GetTags())
+				require.Equal(t, "-10.50", res.GetEntry().GetAmount())
+			},
+		},
+		{
+			name: "ClearBoth",
+			req: &pb.SetTransactionCategoryRequest{
+				EntryId:    entry.ID,
+				Category:   &empty,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"category", "tags"}},
+			},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetEntry(gomock.Any(), entry.ID).Times(1).Return(entry, nil)
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.SetEntryCategoryParams{
+					EntryID:  entry.ID,
+					Category: sql.NullString{Valid: true},
+					Tags:     []string{},
+				}
+				service.EXPECT().SetEntryCategory(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entry, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, res.GetEntry().GetCategory())
+			},
+		},
+		{
+			name:  "NotOwner",
+			req:   &pb.SetTransactionCategoryRequest{EntryId: entry.ID, Category: &groceries},
+			owner: helpers.RandomOwner(),
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetEntry(gomock.Any(), entry.ID).Times(1).Return(entry, nil)
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				service.EXPECT().SetEntryCategory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:  "EntryNotFound",
+			req:   &pb.SetTransactionCategoryRequest{EntryId: entry.ID, Category: &groceries},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetEntry(gomock.Any(), entry.ID).Times(1).Return(models.Entry{}, pgx.ErrNoRows)
+				service.EXPECT().SetEntryCategory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:  "UnknownCategory",
+			req:   &pb.SetTransactionCategoryRequest{EntryId: entry.ID, Category: &groceries},
+			owner: account.Owner,
+			buildStubs: func(svc *mockedproviders.MockServiceProvider) {
+				svc.EXPECT().GetEntry(gomock.Any(), entry.ID).Times(1).Return(entry, nil)
+				svc.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				svc.EXPECT().SetEntryCategory(gomock.Any(), gomock.Any()).Times(1).Return(models.Entry{}, service.ErrUnknownCategory)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetTransactionCategoryResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, tc.owner, helpers.DepositorRole)
+			res, err := client.SetTransactionCategory(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestSetTransactionCategoryValidate(t *testing.T) {
+	upper := "Groceries"
+
+	testCases := []struct {
+		name       string
+		req        *pb.SetTransactionCategoryRequest
+		violations []violation
+	}{
+		{
+			name: "Nothing",
+			req:  &pb.SetTransactionCategoryRequest{},
+			violations: []violation{
+				{Field: "entry_id", Description: "must be a positive integer"},
+				{Field: "update_mask", Description: "must name at least one field to update"},
+			},
+		},
+		{
+			name: "Mask",
+			req:  &pb.SetTransactionCategoryRequest{EntryId: 1, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"amount"}}},
+			violations: []violation{
+				{Field: "update_mask", Description: `"amount" is not a field that can be updated`},
+			},
+		},
+		{
+			name: "Category",
+			req:  &pb.SetTransactionCategoryRequest{EntryId: 1, Category: &upper},
+			violations: []violation{
+				{Field: "category", Description: "must contain only lowercase letters, or underscore"},
+			},
+		},
+		{
+			name: "Tag",
+			req:  &pb.SetTransactionCategoryRequest{EntryId: 1, Tags: []string{"ok", "not ok"}},
+			violations: []violation{
+				{Field: "tags", Description: `tag "not ok" must contain only letters, digits, hyphen, or underscore`},
+			},
+		},
+		{
+			name: "TooManyTags",
+			req:  &pb.SetTransactionCategoryRequest{EntryId: 1, Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}},
+			violations: []violation{
+				{Field: "tags", Description: "must have at most 10 tags"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl)) // no call reaches the service
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, "alice", helpers.DepositorRole)
+			_, err := client.SetTransactionCategory(ctx, tc.req)
+			requireViolations(t, err, tc.violations)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"time"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// how often an open stream re-checks that the access token it was opened with is still valid
+var streamTokenCheckInterval = 30 * time.Second
+
+func (server *Server) StreamTransactions(req *pb.StreamTransactionsRequest, stream pb.SwiftBank_StreamTransactionsServer) error {
+	ctx := stream.Context()
+
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return unauthenticatedError(err)
+	}
+
+	if violations := validateStreamTransactionsRequest(req); violations != nil {
+		return invalidArgumentError(violations)
+	}
+
+	account, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return err
+	}
+
+	entries, err := server.subscriber.SubscribeEntries(ctx, account.ID)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to subscribe to transactions: %s", err)
+	}
+
+	ticker := time.NewTicker(streamTokenCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil // client went away
+		case <-ticker.C:
+			if err := authPayload.Valid(); err != nil {
+				return unauthenticatedError(err)
+			}
+		case entry, ok := <-entries:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return status.Errorf(codes.Unavailable, "transaction feed closed")
+			}
+
+			err := stream.Send(&pb.StreamTransactionsResponse{
+				Entry: convertEntry(entry, account.Currency),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func validateStreamTransactionsRequest(req *pb.StreamTransactionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/webhook"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// TestWebhook posts a ping event right away. The outcome is reported in the response
+// and, unlike real deliveries, never counts towards disabling the webhook.
+func (server *Server) TestWebhook(ctx context.Context, req *pb.TestWebhookRequest) (*pb.TestWebhookResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateTestWebhookRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hook, err := server.authorizeWebhook(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := webhook.NewEvent(models.WebhookEventPing, map[string]int64{"webhook_id": hook.ID})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to create ping event: %w", err))
+	}
+
+	rsp := &pb.TestWebhookResponse{}
+	code, err := server.webhookSender.Send(ctx, hook, event)
+	rsp.StatusCode = int32(code)
+	if err != nil {
+		rsp.Error = err.Error()
+		return rsp, nil
+	}
+
+	rsp.Delivered = true
+	return rsp, nil
+}
+
+func validateTestWebhookRequest(req *pb.TestWebhookRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateWebhookId(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,205 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/rates"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) TransferMoney(ctx context.Context, req *pb.TransferMoneyRequest) (*pb.TransferMoneyResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	// the request field wins over the header
+	idempotencyKey := req.GetIdempotencyKey()
+	if idempotencyKey == "" {
+		idempotencyKey = incomingHeader(ctx, idempotencyKeyHeader)
+	}
+
+	if violations := validateTransferMoneyRequest(req, idempotencyKey); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if err := server.requireVerifiedEmail(ctx, authPayload.UserName); err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	toAccountID := req.GetToAccountId()
+	if req.GetToBeneficiaryId() != 0 || req.GetToIban() != "" {
+		var beneficiary models.Beneficiary
+		field := "to_beneficiary_id"
+		if req.GetToIban() != "" {
+			field = "to_iban"
+			beneficiary, err = server.service.GetBeneficiaryByIBAN(ctx, authPayload.UserName, helpers.NormalizeIBAN(req.GetToIban()))
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, status.Errorf(codes.NotFound, "no beneficiary saved with this iban")
+				}
+				return nil, dbErrorToStatus(fmt.Errorf("failed to get beneficiary: %w", err))
+			}
+		} else {
+			beneficiary, err = server.authorizeBeneficiary(ctx, authPayload, req.GetToBeneficiaryId())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if beneficiary.AccountID == fromAccount.ID {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+				fieldViolation(field, fmt.Errorf("must not be the sending account")),
+			})
+		}
+		toAccountID = beneficiary.AccountID
+	}
+
+	amount, err := amountUnits(req.GetAmount(), req.GetCurrency())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+	}
+
+	arg := models.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		Currency:      req.GetCurrency(),
+		Description:   val.NormalizeDescription(req.GetDescription()),
+		Category:      req.GetCategory(),
+		Tags:          val.NormalizeTags(req.GetTags()),
+	}
+	if idempotencyKey != "" {
+		arg.Idempotency = &models.IdempotencyKey{
+			UserName: authPayload.UserName,
+			Key:      idempotencyKey,
+		}
+	}
+
+	result, err := server.service.TransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, service.ErrIdempotencyKeyReused) || errors.Is(err, service.ErrIdempotencyKeyInFlight) {
+			return nil, status.Errorf(codes.Aborted, "%s", err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, service.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, service.ErrTransferLimitReached) {
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		}
+		if errors.Is(err, service.ErrUnknownCategory) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, rates.ErrRateUnavailable) || errors.Is(err, rates.ErrRateStale) || errors.Is(err, service.ErrCurrencyDisabled) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to transfer money: %w", err))
+	}
+
+	// the money has moved, a notification that cannot be queued must not fail the transfer.
+	// The task id makes a replayed request queue it only once.
+	taskPayload := &worker.PayloadNotifyIncomingTransfer{
+		TransactionID: result.Transaction.ID,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(worker.QueueDefault),
+		asynq.TaskID(fmt.Sprintf("incoming-transfer-%d", result.Transaction.ID)),
+	}
+	if err := server.taskDistributor.DistributeTaskNotifyIncomingTransfer(ctx, taskPayload, opts...); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		log.Error().Err(err).Int64("transaction_id", result.Transaction.ID).Msg("failed to queue incoming transfer notification")
+	}
+
+	rsp := &pb.TransferMoneyResponse{
+		Transfer:    convertTransfer(result.Transaction),
+		FromAccount: convertAccount(result.FromAccount),
+		FromEntry:   convertEntry(result.FromEntry, result.FromAccount.Currency),
+		Fee:         money.New(result.Transaction.Fee, result.Transaction.Currency).String(),
+		TotalDebit:  money.New(result.Transaction.Amount+result.Transaction.Fee, result.Transaction.Currency).String(),
+	}
+	if result.FeeEntry.ID != 0 {
+		rsp.FeeEntry = convertEntry(result.FeeEntry, result.FromAccount.Currency)
+	}
+
+	return rsp, nil
+}
+
+func validateTransferMoneyRequest(req *pb.TransferMoneyRequest, idempotencyKey string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountId(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	// the recipient is either a raw account id or one of the caller's beneficiaries, by id or iban
+	switch req.GetRecipient().(type) {
+	case *pb.TransferMoneyRequest_ToBeneficiaryId:
+		if err := val.ValidateBeneficiaryId(req.GetToBeneficiaryId()); err != nil {
+			violations = append(violations, fieldViolation("to_beneficiary_id", err))
+		}
+	case *pb.TransferMoneyRequest_ToIban:
+		if err := val.ValidateIBAN(req.GetToIban()); err != nil {
+			violations = append(violations, fieldViolation("to_iban", err))
+		}
+	default:
+		if err := val.ValidateAccountId(req.GetToAccountId()); err != nil {
+			violations = append(violations, fieldViolation("to_account_id", err))
+		} else if req.GetToAccountId() == req.GetFromAccountId() {
+			violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must differ from from_account_id")))
+		}
+	}
+
+	if err := val.ValidateAmount(req.GetAmount(), req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateDescription(val.NormalizeDescription(req.GetDescription())); err != nil {
+		violations = append(violations, fieldViolation("description", err))
+	}
+
+	if idempotencyKey != "" {
+		if err := val.ValidateIdempotencyKey(idempotencyKey); err != nil {
+			violations = append(violations, fieldViolation("idempotency_key", err))
+		}
+	}
+
+	if req.GetCategory() != "" {
+		if err := val.ValidateCategory(req.GetCategory()); err != nil {
+			violations = append(violations, fieldViolation("category", err))
+		}
+	}
+
+	if err := val.ValidateTags(val.NormalizeTags(req.GetTags())); err != nil {
+		violations = append(violations, fieldViolation("tags", err))
+	}
+
+	return violations
+}
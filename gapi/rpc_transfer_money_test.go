@@ -0,0 +1,108 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// incomingTransferDistributor accepts the notification TransferMoney queues
+type incomingTransferDistributor struct {
+	worker.TaskDistributor
+}
+
+func (d *incomingTransferDistributor) DistributeTaskNotifyIncomingTransfer(ctx context.Context, payload *worker.PayloadNotifyIncomingTransfer, opts ...asynq.Option) error {
+	return nil
+}
+
+func TestTransferMoneyDescription(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), IsEmailVerified: true}
+	fromAccount := models.Account{ID: 1, Owner: user.UserName, Currency: helpers.USD, Balance: 10000}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service := mockedproviders.NewMockServiceProvider(ctrl)
+		service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		arg := models.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   2,
+			Amount:        1000,
+			Currency:      helpers.USD,
+			Description:   "rent for March",
+		}
+		service.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(models.TransferTxResult{
+			Transaction: models.Transaction{ID: 9, FromAccountID: 1, ToAccountID: 2, Amount: 1000, Currency: helpers.USD, Description: arg.Description},
+			FromAccount: fromAccount,
+		}, nil)
+
+		server := newTestServer(t, service)
+		server.taskDistributor = &incomingTransferDistributor{}
+		client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+		res, err := client.TransferMoney(newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole), &pb.TransferMoneyRequest{
+			FromAccountId: fromAccount.ID,
+			Recipient:     &pb.TransferMoneyRequest_ToAccountId{ToAccountId: 2},
+			Amount:        "10.00",
+			Currency:      helpers.USD,
+			Description:   " rent for\nMarch\x07 ",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "rent for March", res.GetTransfer().GetDescription())
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service := mockedproviders.NewMockServiceProvider(ctrl)
+		service.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+		server := newTestServer(t, service)
+		client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+		_, err := client.TransferMoney(newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole), &pb.TransferMoneyRequest{
+			FromAccountId: fromAccount.ID,
+			Recipient:     &pb.TransferMoneyRequest_ToAccountId{ToAccountId: 2},
+			Amount:        "10.00",
+			Currency:      helpers.USD,
+			Description:   strings.Repeat("x", 141),
+		})
+		requireFieldViolation(t, err, "description")
+	})
+}
+
+func TestTransferMoneySavingsLimit(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), IsEmailVerified: true}
+	savings := models.Account{ID: 1, Owner: user.UserName, Currency: helpers.USD, Balance: 10000, Type: models.AccountSavings}
+
+	ctrl := gomock.NewController(t)
+	svc := mockedproviders.NewMockServiceProvider(ctrl)
+	svc.EXPECT().GetAccount(gomock.Any(), gomock.Eq(savings.ID)).Times(1).Return(savings, nil)
+	svc.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(models.TransferTxResult{}, fmt.Errorf("%w: savings accounts can make 6 a month", service.ErrTransferLimitReached))
+
+	server := newTestServer(t, svc)
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	_, err := client.TransferMoney(newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole), &pb.TransferMoneyRequest{
+		FromAccountId: savings.ID,
+		Recipient:     &pb.TransferMoneyRequest_ToAccountId{ToAccountId: 2},
+		Amount:        "10.00",
+		Currency:      helpers.USD,
+	})
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Contains(t, st.Message(), "6 a month")
+}
@@ -0,0 +1,24 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/pb"
+)
+
+func (server *Server) UnreadNotificationCount(ctx context.Context, req *pb.UnreadNotificationCountRequest) (*pb.UnreadNotificationCountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	count, err := server.service.CountUnreadNotifications(ctx, authPayload.UserName)
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to count unread notifications: %w", err))
+	}
+
+	return &pb.UnreadNotificationCountResponse{
+		Count: count,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	before, err := server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := models.UpdateAccountDetailsParams{
+		AccountID: req.GetAccountId(),
+	}
+	fields := updateAccountFields(req)
+	if fields["nickname"] {
+		arg.Nickname = sql.NullString{String: strings.TrimSpace(req.GetNickname()), Valid: true}
+	}
+	if fields["metadata"] {
+		arg.Metadata = req.GetMetadata()
+		if arg.Metadata == nil {
+			arg.Metadata = map[string]string{} // named by the mask and left empty, the metadata is cleared
+		}
+	}
+
+	account, err := server.service.UpdateAccountDetails(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, service.ErrAccountNicknameExists) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to update account: %w", err))
+	}
+
+	server.audit.Record(ctx, authPayload.UserName, audit.ActionUpdateAccount, audit.EntityAccount, strconv.FormatInt(account.ID, 10), convertAccount(before), convertAccount(account))
+
+	rsp := &pb.UpdateAccountResponse{
+		Account: convertAccount(account),
+	}
+
+	return rsp, nil
+}
+
+// updatableAccountFields are the update_mask paths UpdateAccount accepts
+var updatableAccountFields = map[string]bool{
+	"nickname": true,
+	"metadata": true,
+}
+
+// updateAccountPaths returns the update_mask paths of req. Without a mask, the fields set in req
+// are the ones to change, like for UpdateUser. Empty metadata can't be told from metadata left
+// out, clearing it takes a mask.
+func updateAccountPaths(req *pb.UpdateAccountRequest) []string {
+	if req.UpdateMask != nil {
+		return req.GetUpdateMask().GetPaths()
+	}
+
+	var paths []string
+	if req.Nickname != nil {
+		paths = append(paths, "nickname")
+	}
+	if len(req.GetMetadata()) > 0 {
+		paths = append(paths, "metadata")
+	}
+
+	return paths
+}
+
+// updateAccountFields returns the updatable fields the request changes, GrpcValidate already
+// rejected the paths naming other fields
+func updateAccountFields(req *pb.UpdateAccountRequest) map[string]bool {
+	paths := updateAccountPaths(req)
+	fields := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if updatableAccountFields[path] {
+			fields[path] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,215 @@
+package gapi
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUpdateAccountAPI(t *testing.T) {
+	account := models.Account{ID: 7, Owner: helpers.RandomOwner(), Currency: "USD", Nickname: "Old"}
+	nickname := " Holidays "
+	empty := ""
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateAccountRequest
+		owner         string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.UpdateAccountResponse, err error)
+	}{
+		{
+			name:  "OK",
+			req:   &pb.UpdateAccountRequest{AccountId: account.ID, Nickname: &nickname, Metadata: map[string]string{"budget": "travel"}},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.UpdateAccountDetailsParams{
+					AccountID: account.ID,
+					Nickname:  sql.NullString{String: "Holidays", Valid: true},
+					Metadata:  map[string]string{"budget": "travel"},
+				}
+				updated := account
+				updated.Nickname = "Holidays"
+				updated.Metadata = arg.Metadata
+				service.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Holidays", res.GetAccount().GetNickname())
+				require.Equal(t, map[string]string{"budget": "travel"}, res.GetAccount().GetMetadata())
+			},
+		},
+		{
+			name: "ClearBoth",
+			req: &pb.UpdateAccountRequest{
+				AccountId:  account.ID,
+				Nickname:   &empty,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"nickname", "metadata"}},
+			},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.UpdateAccountDetailsParams{
+					AccountID: account.ID,
+					Nickname:  sql.NullString{Valid: true},
+					Metadata:  map[string]string{},
+				}
+				updated := account
+				updated.Nickname = ""
+				service.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, res.GetAccount().GetNickname())
+			},
+		},
+		{
+			name:  "MetadataOnly",
+			req:   &pb.UpdateAccountRequest{AccountId: account.ID, Metadata: map[string]string{"color": "blue"}},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				arg := models.UpdateAccountDetailsParams{
+					AccountID: account.ID,
+					Metadata:  map[string]string{"color": "blue"},
+				}
+				service.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "NotOwner",
+			req:   &pb.UpdateAccountRequest{AccountId: account.ID, Nickname: &nickname},
+			owner: helpers.RandomOwner(),
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				service.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:  "NotFound",
+			req:   &pb.UpdateAccountRequest{AccountId: account.ID, Nickname: &nickname},
+			owner: account.Owner,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+				service.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:  "NicknameTaken",
+			req:   &pb.UpdateAccountRequest{AccountId: account.ID, Nickname: &nickname},
+			owner: account.Owner,
+			buildStubs: func(svc *mockedproviders.MockServiceProvider) {
+				svc.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				svc.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(1).Return(models.Account{}, service.ErrAccountNicknameExists)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateAccountResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, tc.owner, helpers.DepositorRole)
+			res, err := client.UpdateAccount(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestUpdateAccountValidate(t *testing.T) {
+	long := strings.Repeat("a", 51)
+	blank := "   "
+
+	testCases := []struct {
+		name       string
+		req        *pb.UpdateAccountRequest
+		violations []violation
+	}{
+		{
+			name: "Nothing",
+			req:  &pb.UpdateAccountRequest{},
+			violations: []violation{
+				{Field: "account_id", Description: "must be a positive integer"},
+				{Field: "update_mask", Description: "must name at least one field to update"},
+			},
+		},
+		{
+			name: "Mask",
+			req:  &pb.UpdateAccountRequest{AccountId: 1, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"balance"}}},
+			violations: []violation{
+				{Field: "update_mask", Description: `"balance" is not a field that can be updated`},
+			},
+		},
+		{
+			name: "Nickname",
+			req:  &pb.UpdateAccountRequest{AccountId: 1, Nickname: &long},
+			violations: []violation{
+				{Field: "nickname", Description: "must contain from 1 - 50 characters"},
+			},
+		},
+		{
+			name: "BlankNickname",
+			req:  &pb.UpdateAccountRequest{AccountId: 1, Nickname: &blank},
+			violations: []violation{
+				{Field: "nickname", Description: "must contain from 1 - 50 characters"},
+			},
+		},
+		{
+			name: "ReservedKey",
+			req:  &pb.UpdateAccountRequest{AccountId: 1, Metadata: map[string]string{"SB_tier": "gold", "note": "x"}},
+			violations: []violation{
+				{Field: "metadata", Description: `key "SB_tier" is reserved, keys must not start with sb_`},
+			},
+		},
+		{
+			name: "MetadataTooLarge",
+			req:  &pb.UpdateAccountRequest{AccountId: 1, Metadata: map[string]string{"note": strings.Repeat("x", 4096)}},
+			violations: []violation{
+				{Field: "metadata", Description: "must take at most 4096 bytes as JSON, it takes 4107"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl)) // no call reaches the service
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, "alice", helpers.DepositorRole)
+			_, err := client.UpdateAccount(ctx, tc.req)
+			requireViolations(t, err, tc.violations)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+)
+
+func (server *Server) UpdateNotificationPreferences(ctx context.Context, req *pb.UpdateNotificationPreferencesRequest) (*pb.UpdateNotificationPreferencesResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	preferences, err := server.service.UpdateNotificationPreferences(ctx, models.UpdateNotificationPreferencesParams{
+		Username: authPayload.UserName,
+		EmailTransfers: sql.NullBool{
+			Bool:  req.GetEmailTransfers(),
+			Valid: req.EmailTransfers != nil,
+		},
+		EmailLogins: sql.NullBool{
+			Bool:  req.GetEmailLogins(),
+			Valid: req.EmailLogins != nil,
+		},
+		EmailStatements: sql.NullBool{
+			Bool:  req.GetEmailStatements(),
+			Valid: req.EmailStatements != nil,
+		},
+		PreferSMS: sql.NullBool{
+			Bool:  req.GetPreferSms(),
+			Valid: req.PreferSms != nil,
+		},
+	})
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to update notification preferences: %w", err))
+	}
+
+	return &pb.UpdateNotificationPreferencesResponse{
+		Preferences: convertNotificationPreferences(preferences),
+	}, nil
+}
@@ -3,53 +3,94 @@ package gapi
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/zde37/Swift_Bank/audit"
 	"github.com/zde37/Swift_Bank/helpers"
 	"github.com/zde37/Swift_Bank/models"
 	"github.com/zde37/Swift_Bank/pb"
 	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	authPayload, err := server.authorizeUser(ctx)
+	authPayload, err := authPayloadFromContext(ctx)
 	if err != nil {
 		return nil, unauthenticatedError(err)
 	}
 
-	if violations := validateUpdateUserRequest(req); violations != nil {
-		return nil, invalidArgumentError(violations)
+	// the token carries the stored, canonical username
+	req.Username = val.NormalizeUsername(req.GetUsername())
+	if req.Email != nil {
+		email := val.NormalizeEmail(req.GetEmail())
+		req.Email = &email
 	}
 
+	fields := updateUserFields(req)
+
 	if authPayload.UserName != req.Username {
 		return nil, status.Errorf(codes.PermissionDenied, "cannot update other users info")
 	}
 
+	user, err := server.service.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		return nil, dbErrorToStatus(fmt.Errorf("failed to get user: %w", err))
+	}
+
+	if fields["password"] {
+		if err := val.ValidatePassword(req.GetPassword(), server.passwordPolicy, user.UserName, user.Email); err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("password", err)})
+		}
+		if err := server.passwordGuard.Check(ctx, req.GetPassword()); err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("password", err)})
+		}
+	}
+
+	// fields already holding the requested value are left out, so an update that changes
+	// nothing is caught before it reaches the database
 	arg := models.UpdateUserParams{
 		UserName: req.GetUsername(),
-		FullName: sql.NullString{
+	}
+
+	if fields["full_name"] && req.GetFullName() != user.FullName {
+		arg.FullName = sql.NullString{
 			String: req.GetFullName(),
-			Valid:  req.FullName != nil,
-		},
-		Email: sql.NullString{
+			Valid:  true,
+		}
+	}
+
+	if fields["email"] && req.GetEmail() != user.Email {
+		arg.Email = sql.NullString{
 			String: req.GetEmail(),
-			Valid:  req.Email != nil,
-		},
+			Valid:  true,
+		}
 	}
 
-	if req.Password != nil {
+	// a new number has to be verified again before SMS is sent to it
+	phoneNumber := val.NormalizePhoneNumber(req.GetPhoneNumber())
+	if fields["phone_number"] && phoneNumber != user.PhoneNumber {
+		arg.PhoneNumber = sql.NullString{
+			String: phoneNumber,
+			Valid:  true,
+		}
+		arg.IsPhoneVerified = sql.NullBool{
+			Bool:  false,
+			Valid: true,
+		}
+	}
+
+	if fields["password"] {
 		hashedPassword, err := helpers.HashPassword(req.GetPassword()) // use 'GetPassword' because this function checks if the request object is nil
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
 		}
 		arg.HashedPassword = sql.NullString{
 			String: hashedPassword,
-			Valid:  req.Password != nil, // doesn't hurt to double check
+			Valid:  true,
 		}
 
 		arg.PasswordChangedAt = sql.NullTime{
@@ -58,43 +99,98 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	UpdatedUser, err := server.service.UpdateUser(ctx, arg)
+	if !arg.FullName.Valid && !arg.Email.Valid && !arg.PhoneNumber.Valid && !arg.HashedPassword.Valid {
+		return nil, status.Errorf(codes.InvalidArgument, "request changes nothing")
+	}
+
+	UpdatedUser, err := server.updateUser(ctx, arg)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			return nil, status.Errorf(codes.NotFound, "a db error occurred: %s", err)
+		return nil, dbErrorToStatus(fmt.Errorf("failed to update user: %w", err))
+	}
 
-		}
-		return nil, status.Errorf(codes.Internal, "failed to Update user: %s", err)
+	if arg.PhoneNumber.Valid && UpdatedUser.PhoneNumber != "" {
+		server.sendPhoneVerification(ctx, UpdatedUser.UserName)
+		server.logSharedPhoneNumber(ctx, UpdatedUser.UserName, UpdatedUser.PhoneNumber)
+	}
+
+	action := audit.ActionUpdateUser
+	if arg.HashedPassword.Valid {
+		action = audit.ActionChangePassword
 	}
+	server.audit.Record(ctx, authPayload.UserName, action, audit.EntityUser, user.UserName, convertUser(user), convertUser(UpdatedUser))
 
 	return &pb.UpdateUserResponse{
-		User: convertUser(UpdatedUser),
+		User:                 convertUser(UpdatedUser),
+		VerificationRequired: arg.Email.Valid,
 	}, nil
 }
 
-func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := val.ValidateUsername(req.GetUsername()); err != nil {
-		violations = append(violations, fieldViolation("username", err))
+// updateUser stores the update. A new email is unverified until the link sent to it is followed,
+// the link goes out through the outbox in the same transaction.
+func (server *Server) updateUser(ctx context.Context, arg models.UpdateUserParams) (models.User, error) {
+	if !arg.Email.Valid {
+		return server.service.UpdateUser(ctx, arg)
 	}
 
-	if req.Password != nil {
-		if err := val.ValidatePassword(req.GetPassword()); err != nil {
-			violations = append(violations, fieldViolation("password", err))
-		}
+	result, err := server.service.UpdateUserTx(ctx, models.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		SecretCode:       helpers.RandomString(32),
+		AfterEmailChange: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+			message, err := worker.NewOutboxMessage(ctx, worker.TaskSendVerifyEmail, &worker.PayloadSendVerifyEmail{
+				Username:      user.UserName,
+				VerifyEmailID: verifyEmail.ID,
+			}, worker.QueueCritical, 10)
+			if err != nil {
+				return nil, err
+			}
+			return []models.OutboxMessage{message}, nil
+		},
+	})
+	return result.User, err
+}
+
+// updatableUserFields are the update_mask paths UpdateUser accepts
+var updatableUserFields = map[string]bool{
+	"password":     true,
+	"full_name":    true,
+	"email":        true,
+	"phone_number": true,
+}
+
+// updateUserPaths returns the update_mask paths of req. Without a mask, the fields set in req are
+// the ones to change; this is also how a gateway PATCH gets its mask, as the JSON body only sets
+// the fields it names.
+func updateUserPaths(req *pb.UpdateUserRequest) []string {
+	if req.UpdateMask != nil {
+		return req.GetUpdateMask().GetPaths()
 	}
 
+	var paths []string
+	if req.Password != nil {
+		paths = append(paths, "password")
+	}
 	if req.FullName != nil {
-		if err := val.ValidateFullName(req.GetFullName()); err != nil {
-			violations = append(violations, fieldViolation("full_name", err))
-		}
+		paths = append(paths, "full_name")
 	}
-
 	if req.Email != nil {
-		if err := val.ValidateEmail(req.GetEmail()); err != nil {
-			violations = append(violations, fieldViolation("email", err))
-		}
+		paths = append(paths, "email")
 	}
+	if req.PhoneNumber != nil {
+		paths = append(paths, "phone_number")
+	}
+
+	return paths
+}
 
-	return violations
+// updateUserFields returns the updatable fields the request changes, GrpcValidate already
+// rejected the paths naming other fields
+func updateUserFields(req *pb.UpdateUserRequest) map[string]bool {
+	paths := updateUserPaths(req)
+	fields := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if updatableUserFields[path] {
+			fields[path] = true
+		}
+	}
+	return fields
 }
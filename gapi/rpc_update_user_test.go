@@ -0,0 +1,466 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/breach"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUpdateUserAPI(t *testing.T) {
+	user := models.User{
+		UserName: helpers.RandomOwner(),
+		FullName: "Ada Lovelace",
+		Email:    helpers.RandomEmail(),
+	}
+	newName := "Ada King"
+	takenEmail := helpers.RandomEmail()
+	newPhone := "+14155552671"
+	mixedCaseEmail := "Ada.King@Example.com"
+	sameEmail := strings.ToUpper(user.Email)
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateUserRequest
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		buildContext  func(t *testing.T, server *Server) context.Context
+		checkResponse func(t *testing.T, res *pb.UpdateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, FullName: &newName},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				arg := models.UpdateUserParams{
+					UserName: user.UserName,
+					FullName: sql.NullString{String: newName, Valid: true},
+				}
+				updated := user
+				updated.FullName = newName
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.UserName, res.GetUser().GetUsername())
+				require.Equal(t, newName, res.GetUser().GetFullName())
+				require.False(t, res.GetVerificationRequired())
+			},
+		},
+		{
+			name: "MixedCase",
+			req:  &pb.UpdateUserRequest{Username: strings.ToUpper(user.UserName), Email: &mixedCaseEmail},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				arg := models.UpdateUserParams{
+					UserName: user.UserName,
+					Email:    sql.NullString{String: strings.ToLower(mixedCaseEmail), Valid: true},
+				}
+				updated := user
+				updated.Email = arg.Email.String
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, txArg models.UpdateUserTxParams) (models.UpdateUserTxResult, error) {
+						require.Equal(t, arg, txArg.UpdateUserParams)
+						require.Len(t, txArg.SecretCode, 32)
+
+						verifyEmail := models.VerifyEmails{ID: 9, Username: updated.UserName, Email: updated.Email}
+						messages, err := txArg.AfterEmailChange(updated, verifyEmail)
+						require.NoError(t, err)
+						require.Len(t, messages, 1)
+						require.Equal(t, worker.TaskSendVerifyEmail, messages[0].TaskType)
+						require.Contains(t, string(messages[0].Payload), `"verify_email_id":9`)
+
+						return models.UpdateUserTxResult{User: updated, VerifyEmail: verifyEmail}, nil
+					})
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, strings.ToLower(mixedCaseEmail), res.GetUser().GetEmail())
+				require.True(t, res.GetVerificationRequired())
+			},
+		},
+		{
+			name: "SameEmail",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, Email: &sameEmail},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "OtherUser",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, FullName: &newName},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, "someone_else", helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, FullName: &newName},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, FullName: &newName},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(models.User{}, pgx.ErrNoRows)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "DuplicateEmail",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, Email: &takenEmail},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				service.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.UpdateUserTxResult{}, &pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_lower_email_key"})
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidEmail",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, Email: new(string)},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "MaskLimitsFields",
+			req: &pb.UpdateUserRequest{
+				Username:    user.UserName,
+				FullName:    &newName,
+				PhoneNumber: &newPhone,
+				UpdateMask:  &fieldmaskpb.FieldMask{Paths: []string{"phone_number"}},
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				arg := models.UpdateUserParams{
+					UserName:        user.UserName,
+					PhoneNumber:     sql.NullString{String: newPhone, Valid: true},
+					IsPhoneVerified: sql.NullBool{Bool: false, Valid: true},
+				}
+				updated := user
+				updated.PhoneNumber = newPhone
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+				service.EXPECT().CountUsersWithPhoneNumber(gomock.Any(), newPhone, user.UserName).Times(1).Return(int64(0), nil)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.FullName, res.GetUser().GetFullName())
+				require.Equal(t, newPhone, res.GetUser().GetPhoneNumber())
+			},
+		},
+		{
+			name: "MaskedFieldUnset",
+			req: &pb.UpdateUserRequest{
+				Username:   user.UserName,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name"}},
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				requireFieldViolation(t, err, "full_name")
+			},
+		},
+		{
+			name: "UnknownMaskPath",
+			req: &pb.UpdateUserRequest{
+				Username:   user.UserName,
+				FullName:   &newName,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name", "role"}},
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				requireFieldViolation(t, err, "update_mask")
+			},
+		},
+		{
+			name: "EmptyMask",
+			req: &pb.UpdateUserRequest{
+				Username:   user.UserName,
+				FullName:   &newName,
+				UpdateMask: &fieldmaskpb.FieldMask{},
+			},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				requireFieldViolation(t, err, "update_mask")
+			},
+		},
+		{
+			name: "NoFields",
+			req:  &pb.UpdateUserRequest{Username: user.UserName},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				requireFieldViolation(t, err, "update_mask")
+			},
+		},
+		{
+			name: "ChangesNothing",
+			req:  &pb.UpdateUserRequest{Username: user.UserName, FullName: &user.FullName, Email: &user.Email},
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+				service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			server.taskDistributor = &phoneDistributor{}
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			res, err := client.UpdateUser(tc.buildContext(t, server), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// TestUpdateUserPasswordPolicy checks a new password is held to the email on file, which the
+// request does not carry
+func TestUpdateUserPasswordPolicy(t *testing.T) {
+	user := models.User{
+		UserName: helpers.RandomOwner(),
+		FullName: "Ada Lovelace",
+		Email:    "countess.ada@example.com",
+	}
+	password := "countess.ada!1"
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, service)
+	server.passwordPolicy = val.PasswordPolicy{MinLength: 8, RejectUserInfo: true}
+	client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+	ctx := newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+	_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Username: user.UserName, Password: &password})
+	requireFieldViolation(t, err, "password")
+}
+
+type breachedChecker map[string]int
+
+func (c breachedChecker) Count(ctx context.Context, password string) (int, error) {
+	return c[password], nil
+}
+
+func TestUpdateUserBreachedPassword(t *testing.T) {
+	user := models.User{
+		UserName: helpers.RandomOwner(),
+		FullName: "Ada Lovelace",
+		Email:    helpers.RandomEmail(),
+	}
+	password := "letmein123"
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	service.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, service)
+	server.passwordGuard = breach.NewGuard(breachedChecker{password: 42}, 0, prometheus.NewRegistry())
+	client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+	ctx := newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+	_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Username: user.UserName, Password: &password})
+	requireFieldViolation(t, err, "password")
+}
+
+func requireFieldViolation(t *testing.T, err error, field string) {
+	requireStatusViolation(t, err, codes.InvalidArgument, field)
+}
+
+// requireStatusViolation checks err has code and names field in a BadRequest detail
+func requireStatusViolation(t *testing.T, err error, code codes.Code, field string) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code())
+
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				if violation.GetField() == field {
+					return
+				}
+			}
+		}
+	}
+	t.Fatalf("no violation of %s in %v", field, st.Details())
+}
+
+func TestUpdateUserGateway(t *testing.T) {
+	user := models.User{
+		UserName: helpers.RandomOwner(),
+		FullName: "Ada Lovelace",
+		Email:    helpers.RandomEmail(),
+	}
+	newName := "Ada King"
+
+	testCases := []struct {
+		name string
+		path string
+		body string
+		arg  models.UpdateUserParams
+	}{
+		{
+			name: "MaskFromBody",
+			path: "/v1/users/" + user.UserName,
+			body: `{"fullName":"Ada King"}`,
+			arg: models.UpdateUserParams{
+				UserName: user.UserName,
+				FullName: sql.NullString{String: newName, Valid: true},
+			},
+		},
+		{
+			name: "MaskInBody",
+			path: "/v1/users/" + user.UserName,
+			body: `{"fullName":"Ada King","phoneNumber":"+14155552671","updateMask":"fullName"}`,
+			arg: models.UpdateUserParams{
+				UserName: user.UserName,
+				FullName: sql.NullString{String: newName, Valid: true},
+			},
+		},
+		{
+			name: "OldPath",
+			path: "/v1/update_user",
+			body: `{"username":"` + user.UserName + `","full_name":"Ada King"}`,
+			arg: models.UpdateUserParams{
+				UserName: user.UserName,
+				FullName: sql.NullString{String: newName, Valid: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+			updated := user
+			updated.FullName = newName
+			service.EXPECT().UpdateUser(gomock.Any(), gomock.Eq(tc.arg)).Times(1).Return(updated, nil)
+
+			server := newTestServer(t, service)
+			gateway := startTestGateway(t, server)
+			accessToken, _, err := server.tokenMaker.CreateToken(user.UserName, helpers.DepositorRole, time.Minute)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, gateway.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			response, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			require.Equal(t, http.StatusOK, response.StatusCode)
+		})
+	}
+}
@@ -2,13 +2,12 @@ package gapi
 
 import (
 	"context"
+	"fmt"
  
 	"github.com/zde37/Swift_Bank/models"
 	"github.com/zde37/Swift_Bank/pb"
 	"github.com/zde37/Swift_Bank/val" 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (server *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
@@ -21,7 +20,7 @@ func (server *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailReques
 		SecretCode: req.GetSecretCode(),
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to verify email")
+		return nil, dbErrorToStatus(fmt.Errorf("failed to verify email: %w", err))
 	}
 
 	return &pb.VerifyEmailResponse{
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) VerifyPhone(ctx context.Context, req *pb.VerifyPhoneRequest) (*pb.VerifyPhoneResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := val.ValidatePhoneCode(req.GetSecretCode()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("secret_code", err)})
+	}
+
+	result, err := server.service.VerifyPhoneTx(ctx, models.VerifyPhoneTxParams{
+		Username:   authPayload.UserName,
+		SecretCode: req.GetSecretCode(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrWrongPhoneCode) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("secret_code", err)})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "no usable phone verification code, update your phone number to get a new one")
+		}
+		return nil, dbErrorToStatus(fmt.Errorf("failed to verify phone number: %w", err))
+	}
+
+	return &pb.VerifyPhoneResponse{
+		User: convertUser(result.User),
+	}, nil
+}
+
+// sendPhoneVerification queues the SMS with the code for username's new phone number. A failure
+// is only logged, the number is saved either way and stays unverified.
+func (server *Server) sendPhoneVerification(ctx context.Context, username string) {
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(worker.QueueCritical),
+	}
+	if err := server.taskDistributor.DistributeTaskSendVerifyPhone(ctx, &worker.PayloadSendVerifyPhone{Username: username}, opts...); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("username", username).Msg("failed to queue phone verification")
+	}
+}
+
+// logSharedPhoneNumber notes when other users gave the same phone number. Sharing one is allowed,
+// a family may, but it is worth a look from fraud checks.
+func (server *Server) logSharedPhoneNumber(ctx context.Context, username, phoneNumber string) {
+	others, err := server.service.CountUsersWithPhoneNumber(ctx, phoneNumber, username)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("username", username).Msg("failed to look for other users of phone number")
+		return
+	}
+
+	if others > 0 {
+		log.Ctx(ctx).Warn().Str("username", username).Int64("other_users", others).Msg("phone number is shared with other users")
+	}
+}
@@ -0,0 +1,154 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type phoneDistributor struct {
+	worker.TaskDistributor
+	payloads []*worker.PayloadSendVerifyPhone
+}
+
+func (d *phoneDistributor) DistributeTaskSendVerifyPhone(ctx context.Context, payload *worker.PayloadSendVerifyPhone, opts ...asynq.Option) error {
+	d.payloads = append(d.payloads, payload)
+	return nil
+}
+
+func TestVerifyPhoneAPI(t *testing.T) {
+	user := models.User{
+		UserName:    helpers.RandomOwner(),
+		FullName:    "Ada Lovelace",
+		Email:       helpers.RandomEmail(),
+		PhoneNumber: "+14155552671",
+	}
+	errWrongPhoneCode := service.ErrWrongPhoneCode
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(service *mockedproviders.MockServiceProvider)
+		checkResponse func(t *testing.T, res *pb.VerifyPhoneResponse, err error)
+	}{
+		{
+			name: "OK",
+			code: "123456",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				verified := user
+				verified.IsPhoneVerified = true
+				service.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Eq(models.VerifyPhoneTxParams{Username: user.UserName, SecretCode: "123456"})).
+					Times(1).
+					Return(models.VerifyPhoneTxResult{User: verified}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetUser().GetIsPhoneVerified())
+			},
+		},
+		{
+			name: "WrongCode",
+			code: "654321",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(1).Return(models.VerifyPhoneTxResult{}, errWrongPhoneCode)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				requireFieldViolation(t, err, "secret_code")
+			},
+		},
+		{
+			name: "NoUsableCode",
+			code: "123456",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(1).Return(models.VerifyPhoneTxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: "12ab56",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				requireFieldViolation(t, err, "secret_code")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			ctx := newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+			res, err := client.VerifyPhone(ctx, &pb.VerifyPhoneRequest{SecretCode: tc.code})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// TestUpdateUserPhoneNumber checks a typed number is stored normalized, unverified, and that a code is sent to it
+func TestUpdateUserPhoneNumber(t *testing.T) {
+	user := models.User{
+		UserName:        helpers.RandomOwner(),
+		FullName:        "Ada Lovelace",
+		Email:           helpers.RandomEmail(),
+		PhoneNumber:     "+442071838750",
+		IsPhoneVerified: true,
+	}
+	typed := "+1 415-555-2671"
+	normalized := "+14155552671"
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	updated := user
+	updated.PhoneNumber = normalized
+	updated.IsPhoneVerified = false
+	service.EXPECT().
+		UpdateUser(gomock.Any(), gomock.Eq(models.UpdateUserParams{
+			UserName:        user.UserName,
+			PhoneNumber:     sql.NullString{String: normalized, Valid: true},
+			IsPhoneVerified: sql.NullBool{Bool: false, Valid: true},
+		})).
+		Times(1).
+		Return(updated, nil)
+	// a shared number is only logged
+	service.EXPECT().CountUsersWithPhoneNumber(gomock.Any(), normalized, user.UserName).Times(1).Return(int64(2), nil)
+
+	server := newTestServer(t, service)
+	distributor := &phoneDistributor{}
+	server.taskDistributor = distributor
+	client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+	ctx := newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole)
+	res, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Username: user.UserName, PhoneNumber: &typed})
+	require.NoError(t, err)
+	require.Equal(t, normalized, res.GetUser().GetPhoneNumber())
+	require.False(t, res.GetUser().GetIsPhoneVerified())
+
+	require.Len(t, distributor.payloads, 1)
+	require.Equal(t, user.UserName, distributor.payloads[0].Username)
+}
@@ -2,11 +2,21 @@ package gapi
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/breach"
 	"github.com/zde37/Swift_Bank/config"
+	"github.com/zde37/Swift_Bank/currencies"
+	"github.com/zde37/Swift_Bank/events"
+	"github.com/zde37/Swift_Bank/loglevel"
+	"github.com/zde37/Swift_Bank/pagination"
 	"github.com/zde37/Swift_Bank/pb"
 	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/storage"
 	"github.com/zde37/Swift_Bank/token"
+	"github.com/zde37/Swift_Bank/val"
+	"github.com/zde37/Swift_Bank/webhook"
 	"github.com/zde37/Swift_Bank/worker"
 )
 
@@ -17,13 +27,38 @@ type Server struct {
 	tokenMaker      token.Maker
 	config          config.Config
 	taskDistributor worker.TaskDistributor
+	subscriber      events.Subscriber
+	webhookSender   *webhook.Sender
+	taskInspector   worker.TaskInspector
+	pageTokens      *pagination.Codec
+	files           storage.Store
+	audit           *audit.Recorder
+	currencies      *currencies.Cache
+	passwordPolicy  val.PasswordPolicy
+	passwordGuard   *breach.Guard // nil when breached passwords are not checked
+	logLevels       *loglevel.Controller
+	build           BuildInfo
+	rules           *val.Rules
 }
 
-// NewServer creates a new gRPC server
-func NewServer(c config.Config, s service.ServiceProvider, taskDistributor worker.TaskDistributor) (*Server, error) {
-	tokenMaker, err := token.NewPastoMaker(c.TokenSymmetricKey)
+// BuildInfo tells which build is running and since when
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	StartedAt time.Time
+}
+
+// NewServer creates a new gRPC server. tokenMaker makes and checks access tokens, it is shared so
+// a rotated key reaches every server.
+func NewServer(c config.Config, s service.ServiceProvider, tokenMaker token.Maker, taskDistributor worker.TaskDistributor, taskInspector worker.TaskInspector, subscriber events.Subscriber, files storage.Store, recorder *audit.Recorder, currencyCache *currencies.Cache, passwordGuard *breach.Guard, logLevels *loglevel.Controller, build BuildInfo) (*Server, error) {
+	pageTokens, err := pagination.NewCodec(c.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create page token codec: %w", err)
+	}
+
+	passwordPolicy, err := val.NewPasswordPolicy(c.PasswordMinLength, c.PasswordCharClasses, c.PasswordRejectUserInfo, c.PasswordRejectCommon)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create token maker: %w", err)
+		return nil, fmt.Errorf("invalid password policy: %w", err)
 	}
 
 	server := &Server{
@@ -31,7 +66,19 @@ func NewServer(c config.Config, s service.ServiceProvider, taskDistributor worke
 		config:          c,
 		tokenMaker:      tokenMaker,
 		taskDistributor: taskDistributor,
+		subscriber:      subscriber,
+		webhookSender:   webhook.NewSender(webhook.DefaultTimeout),
+		taskInspector:   taskInspector,
+		pageTokens:      pageTokens,
+		files:           files,
+		audit:           recorder,
+		currencies:      currencyCache,
+		passwordPolicy:  passwordPolicy,
+		passwordGuard:   passwordGuard,
+		logLevels:       logLevels,
+		build:           build,
 	}
+	server.rules = server.requestRules()
 
 	return server, nil
 }
@@ -0,0 +1,117 @@
+package gapi
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	defaultMaxMsgSize           = 16 << 20 // a year of statement entries fits comfortably
+	defaultMaxConnectionIdle    = 15 * time.Minute
+	defaultKeepaliveTime        = 2 * time.Minute
+	defaultKeepaliveTimeout     = 20 * time.Second
+	defaultMaxConcurrentStreams = 100
+
+	minMsgSize           = 1 << 10
+	maxMsgSize           = 256 << 20
+	minKeepaliveTime     = 10 * time.Second
+	maxConcurrentStreams = 10000
+
+	// a client may ping this often, the gateway in proxy mode pings at gatewayKeepaliveTime
+	minClientPingInterval   = 20 * time.Second
+	gatewayKeepaliveTime    = 30 * time.Second
+	gatewayKeepaliveTimeout = 10 * time.Second
+)
+
+// ServerLimits bounds what a single client connection can ask of the gRPC server. A zero field
+// uses the default.
+//
+// A gateway in process calls the server directly, so none of these apply to its requests. A
+// gateway in proxy mode dials with DialOptions, which match them.
+type ServerLimits struct {
+	MaxRecvMsgSize       int           // bytes
+	MaxSendMsgSize       int           // bytes
+	MaxConnectionIdle    time.Duration // an idle connection is closed after this long
+	KeepaliveTime        time.Duration // the server pings a connection that has been quiet this long
+	KeepaliveTimeout     time.Duration // and closes it if the ping is not answered in time
+	MaxConcurrentStreams uint32        // per connection
+}
+
+// Validate rejects values that would make the server unusable or unprotected
+func (limits ServerLimits) Validate() error {
+	if err := validateMsgSize("max receive message size", limits.MaxRecvMsgSize); err != nil {
+		return err
+	}
+	if err := validateMsgSize("max send message size", limits.MaxSendMsgSize); err != nil {
+		return err
+	}
+
+	if limits.MaxConnectionIdle < 0 {
+		return fmt.Errorf("max connection idle must not be negative, got %s", limits.MaxConnectionIdle)
+	}
+	if limits.KeepaliveTime != 0 && limits.KeepaliveTime < minKeepaliveTime {
+		return fmt.Errorf("keepalive time must be at least %s, got %s", minKeepaliveTime, limits.KeepaliveTime)
+	}
+	if limits.KeepaliveTimeout < 0 {
+		return fmt.Errorf("keepalive timeout must not be negative, got %s", limits.KeepaliveTimeout)
+	}
+	if limits.MaxConcurrentStreams > maxConcurrentStreams {
+		return fmt.Errorf("max concurrent streams must be at most %d, got %d", maxConcurrentStreams, limits.MaxConcurrentStreams)
+	}
+
+	return nil
+}
+
+func validateMsgSize(name string, size int) error {
+	if size != 0 && (size < minMsgSize || size > maxMsgSize) {
+		return fmt.Errorf("%s must be between %d and %d bytes, got %d", name, minMsgSize, maxMsgSize, size)
+	}
+
+	return nil
+}
+
+// ServerOptions returns the grpc options that apply the limits
+func (limits ServerLimits) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(orDefault(limits.MaxRecvMsgSize, defaultMaxMsgSize)),
+		grpc.MaxSendMsgSize(orDefault(limits.MaxSendMsgSize, defaultMaxMsgSize)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: orDefault(limits.MaxConnectionIdle, defaultMaxConnectionIdle),
+			Time:              orDefault(limits.KeepaliveTime, defaultKeepaliveTime),
+			Timeout:           orDefault(limits.KeepaliveTimeout, defaultKeepaliveTimeout),
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             minClientPingInterval,
+			PermitWithoutStream: true,
+		}),
+		grpc.MaxConcurrentStreams(orDefault(limits.MaxConcurrentStreams, defaultMaxConcurrentStreams)),
+	}
+}
+
+// DialOptions returns the options of a gateway dialing a server with these limits. Its calls wait
+// for the server to be ready rather than fail while it starts or restarts.
+func (limits ServerLimits) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(orDefault(limits.MaxSendMsgSize, defaultMaxMsgSize)),
+			grpc.MaxCallSendMsgSize(orDefault(limits.MaxRecvMsgSize, defaultMaxMsgSize)),
+			grpc.WaitForReady(true),
+		),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                gatewayKeepaliveTime,
+			Timeout:             gatewayKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+}
+
+func orDefault[T comparable](value, defaultValue T) T {
+	var zero T
+	if value == zero {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerLimitsValidate(t *testing.T) {
+	require.NoError(t, ServerLimits{}.Validate())
+	require.NoError(t, ServerLimits{
+		MaxRecvMsgSize:       16 << 20,
+		MaxSendMsgSize:       16 << 20,
+		MaxConnectionIdle:    15 * time.Minute,
+		KeepaliveTime:        2 * time.Minute,
+		KeepaliveTimeout:     20 * time.Second,
+		MaxConcurrentStreams: 100,
+	}.Validate())
+
+	for _, limits := range []ServerLimits{
+		{MaxRecvMsgSize: 10},
+		{MaxRecvMsgSize: -1},
+		{MaxSendMsgSize: 1 << 30},
+		{MaxConnectionIdle: -time.Second},
+		{KeepaliveTime: time.Second},
+		{KeepaliveTimeout: -time.Second},
+		{MaxConcurrentStreams: 1_000_000},
+	} {
+		require.Error(t, limits.Validate(), "%+v", limits)
+	}
+}
+
+func TestServerLimitsMessageSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+
+	beneficiaries := make([]models.Beneficiary, 50)
+	for i := range beneficiaries {
+		beneficiaries[i] = models.Beneficiary{ID: int64(i + 1), Owner: "ada", AccountID: int64(i + 1), Nickname: helpers.RandomString(30)}
+	}
+	service.EXPECT().ListBeneficiaries(gomock.Any(), gomock.Any()).Times(1).Return(beneficiaries, nil)
+	service.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, service)
+	limits := ServerLimits{MaxRecvMsgSize: 1 << 10, MaxSendMsgSize: 1 << 10}
+	opts := append(limits.ServerOptions(), grpc.UnaryInterceptor(server.GrpcAuth))
+	client := startTestServer(t, server, opts...)
+
+	// the request is rejected before it reaches the handler
+	_, err := client.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+		EmailId:    1,
+		SecretCode: strings.Repeat("a", 2<<10),
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// and so is a response above the send limit
+	ctx := newContextWithBearerToken(t, server, "ada", helpers.DepositorRole)
+	_, err = client.ListBeneficiaries(ctx, &pb.ListBeneficiariesRequest{PageSize: 50})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,117 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Timeouts bounds how long each RPC may run, so a stuck query cannot hold a handler forever
+type Timeouts struct {
+	Default time.Duration
+	Methods map[string]time.Duration // by method name, like "GetStatement"
+}
+
+// ParseTimeouts parses a list like "GetStatement:30s" of the RPCs that get longer than defaultTimeout
+func ParseTimeouts(defaultTimeout time.Duration, methodTimeouts string) (Timeouts, error) {
+	timeouts := Timeouts{Default: defaultTimeout, Methods: map[string]time.Duration{}}
+	if strings.TrimSpace(methodTimeouts) == "" {
+		return timeouts, nil
+	}
+
+	for _, pair := range strings.Split(methodTimeouts, ",") {
+		method, value, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || method == "" {
+			return Timeouts{}, fmt.Errorf("invalid method timeout %q", pair)
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return Timeouts{}, fmt.Errorf("invalid method timeout %q", pair)
+		}
+
+		timeouts.Methods[method] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// For returns the timeout of a full method name, 0 means none
+func (t Timeouts) For(fullMethod string) time.Duration {
+	if timeout, ok := t.Methods[path.Base(fullMethod)]; ok {
+		return timeout
+	}
+
+	return t.Default
+}
+
+func logTimeout(method string, elapsed, timeout time.Duration) {
+	log.Warn().
+		Str("method", method).
+		Dur("elapsed", elapsed).
+		Dur("timeout", timeout).
+		Msg("request timed out")
+}
+
+// GrpcTimeout gives the handler a context that expires after the timeout of the RPC. A handler
+// that fails once it expired answers DeadlineExceeded, whatever error the expiry caused. One
+// that still succeeded keeps its response, the work is done by then.
+func (t Timeouts) GrpcTimeout(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	timeout := t.For(info.FullMethod)
+	if timeout <= 0 {
+		return handler(ctx, req)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	resp, err = handler(ctx, req)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logTimeout(info.FullMethod, time.Since(startTime), timeout)
+		return nil, status.Errorf(codes.DeadlineExceeded, "request took longer than %s", timeout)
+	}
+
+	return resp, err
+}
+
+// HttpTimeout does the same for the gateway, whose requests skip the gRPC interceptors. The
+// gateway must be built with runtime.WithErrorHandler(HTTPErrors.ErrorHandler) to answer 504.
+func (t Timeouts) HttpTimeout(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := rpcMethodForRequest(r)
+		timeout := t.For(method)
+		if !ok || timeout <= 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		rec := &ResponseRecorder{
+			ResponseWriter: w,
+			StatusCode:     http.StatusOK,
+		}
+
+		startTime := time.Now()
+		handler.ServeHTTP(rec, r.WithContext(ctx))
+		if rec.StatusCode == http.StatusGatewayTimeout {
+			logTimeout(method, time.Since(startTime), timeout)
+		}
+	})
+}
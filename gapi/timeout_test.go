@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// slowHandler sleeps for delay and fails like a database call would if the deadline passed first
+func slowHandler(delay time.Duration) grpc.UnaryHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		select {
+		case <-time.After(delay):
+			return "done", nil
+		case <-ctx.Done():
+			return nil, status.Errorf(codes.Internal, "failed to get account: %s", ctx.Err())
+		}
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts(5*time.Second, "GetStatement:25s, SearchTransactions:15s")
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, timeouts.For(pb.SwiftBank_GetBalance_FullMethodName))
+	require.Equal(t, 25*time.Second, timeouts.For(pb.SwiftBank_GetStatement_FullMethodName))
+	require.Equal(t, 15*time.Second, timeouts.For(pb.SwiftBank_SearchTransactions_FullMethodName))
+
+	for _, value := range []string{"GetStatement", "GetStatement:soon", ":5s", "GetStatement:-1s"} {
+		_, err := ParseTimeouts(5*time.Second, value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestGrpcTimeout(t *testing.T) {
+	logs := captureLogs(t)
+	timeouts := Timeouts{
+		Default: 20 * time.Millisecond,
+		Methods: map[string]time.Duration{"GetStatement": time.Second},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SwiftBank_GetBalance_FullMethodName}
+	_, err := timeouts.GrpcTimeout(context.Background(), nil, info, slowHandler(time.Second))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Contains(t, logs.String(), `"message":"request timed out"`)
+	require.Contains(t, logs.String(), `"method":"/pb.SwiftBank/GetBalance"`)
+
+	// statements get longer
+	info = &grpc.UnaryServerInfo{FullMethod: pb.SwiftBank_GetStatement_FullMethodName}
+	resp, err := timeouts.GrpcTimeout(context.Background(), nil, info, slowHandler(50*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, "done", resp)
+}
+
+func TestGrpcTimeoutLateSuccess(t *testing.T) {
+	timeouts := Timeouts{Default: 10 * time.Millisecond}
+
+	// a handler that ignores its context and finishes keeps its response
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SwiftBank_TransferMoney_FullMethodName}
+	resp, err := timeouts.GrpcTimeout(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		time.Sleep(30 * time.Millisecond)
+		return "transferred", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "transferred", resp)
+}
+
+func TestHttpTimeout(t *testing.T) {
+	logs := captureLogs(t)
+	timeouts := Timeouts{Default: 20 * time.Millisecond}
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(HTTPErrors{}.ErrorHandler))
+
+	handler := timeouts.HttpTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := slowHandler(time.Second)(r.Context(), nil)
+		HTTPErrors{}.ErrorHandler(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/accounts/1/balance", nil))
+	require.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	require.Contains(t, logs.String(), `"method":"/pb.SwiftBank/GetBalance"`)
+}
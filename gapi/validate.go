@@ -0,0 +1,194 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/statement"
+	"github.com/zde37/Swift_Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// GrpcValidate answers InvalidArgument, with a violation per bad field, to requests breaking the
+// rules of their message, before the handler sees them. Handlers only check what needs more
+// than the request, like the stored user.
+func (server *Server) GrpcValidate(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	if msg, ok := req.(proto.Message); ok {
+		if violations := server.rules.Check(msg); violations != nil {
+			return nil, invalidArgumentError(fieldViolations(violations))
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+func fieldViolations(violations []val.Violation) []*errdetails.BadRequest_FieldViolation {
+	fields := make([]*errdetails.BadRequest_FieldViolation, len(violations))
+	for i, violation := range violations {
+		fields[i] = fieldViolation(violation.Field, violation.Err)
+	}
+	return fields
+}
+
+// requestRules returns the rules GrpcValidate checks. They see requests as sent, so values the
+// handler normalizes are normalized the same way before they are checked.
+func (server *Server) requestRules() *val.Rules {
+	rules := val.NewRules()
+
+	val.AddRule(rules, func(req *pb.LoginUserRequest) (violations []val.Violation) {
+		if err := val.ValidateUsername(val.NormalizeUsername(req.GetUsername())); err != nil {
+			violations = append(violations, val.Violation{Field: "username", Err: err})
+		}
+
+		// existing passwords predate the configured policy, so login only holds them to the default
+		if err := val.ValidatePassword(req.GetPassword(), val.DefaultPasswordPolicy, "", ""); err != nil {
+			violations = append(violations, val.Violation{Field: "password", Err: err})
+		}
+
+		return violations
+	})
+
+	// a field named by the mask is checked even when the request leaves it unset, as the mask
+	// says to change it
+	val.AddRule(rules, func(req *pb.UpdateUserRequest) (violations []val.Violation) {
+		username := val.NormalizeUsername(req.GetUsername())
+		if err := val.ValidateUsername(username); err != nil {
+			violations = append(violations, val.Violation{Field: "username", Err: err})
+		}
+
+		paths := updateUserPaths(req)
+		if len(paths) == 0 {
+			violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("must name at least one field to update")})
+		}
+		for _, path := range paths {
+			if !updatableUserFields[path] {
+				violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("%q is not a field that can be updated", path)})
+			}
+		}
+		fields := updateUserFields(req)
+
+		if fields["password"] {
+			// the new email is checked here, the one on file once the handler loaded the user
+			var email string
+			if fields["email"] {
+				email = val.NormalizeEmail(req.GetEmail())
+			}
+			if err := val.ValidatePassword(req.GetPassword(), server.passwordPolicy, username, email); err != nil {
+				violations = append(violations, val.Violation{Field: "password", Err: err})
+			}
+		}
+
+		if fields["full_name"] {
+			if err := val.ValidateFullName(req.GetFullName()); err != nil {
+				violations = append(violations, val.Violation{Field: "full_name", Err: err})
+			}
+		}
+
+		if fields["email"] {
+			if err := val.ValidateEmail(val.NormalizeEmail(req.GetEmail())); err != nil {
+				violations = append(violations, val.Violation{Field: "email", Err: err})
+			}
+		}
+
+		if fields["phone_number"] && req.GetPhoneNumber() != "" {
+			if err := val.ValidatePhoneNumber(val.NormalizePhoneNumber(req.GetPhoneNumber())); err != nil {
+				violations = append(violations, val.Violation{Field: "phone_number", Err: err})
+			}
+		}
+
+		return violations
+	})
+
+	val.AddRule(rules, func(req *pb.UpdateAccountRequest) (violations []val.Violation) {
+		if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+			violations = append(violations, val.Violation{Field: "account_id", Err: err})
+		}
+
+		paths := updateAccountPaths(req)
+		if len(paths) == 0 {
+			violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("must name at least one field to update")})
+		}
+		for _, path := range paths {
+			if !updatableAccountFields[path] {
+				violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("%q is not a field that can be updated", path)})
+			}
+		}
+		fields := updateAccountFields(req)
+
+		// an empty nickname removes it
+		if fields["nickname"] && req.GetNickname() != "" {
+			if err := val.ValidateNickname(req.GetNickname()); err != nil {
+				violations = append(violations, val.Violation{Field: "nickname", Err: err})
+			}
+		}
+
+		if fields["metadata"] {
+			if err := val.ValidateAccountMetadata(req.GetMetadata()); err != nil {
+				violations = append(violations, val.Violation{Field: "metadata", Err: err})
+			}
+		}
+
+		return violations
+	})
+
+	val.AddRule(rules, func(req *pb.GetAccountSummaryRequest) (violations []val.Violation) {
+		if err := val.ValidateAccountId(req.GetAccountId()); err != nil {
+			violations = append(violations, val.Violation{Field: "account_id", Err: err})
+		}
+
+		if err := val.ValidateYear(req.GetYear()); err != nil {
+			violations = append(violations, val.Violation{Field: "year", Err: err})
+		}
+
+		if err := val.ValidateMonth(req.GetMonth()); err != nil {
+			violations = append(violations, val.Violation{Field: "month", Err: err})
+		} else if start, _ := statement.Period(int(req.GetYear()), int(req.GetMonth())); start.After(time.Now()) {
+			violations = append(violations, val.Violation{Field: "month", Err: fmt.Errorf("must not be in the future")})
+		}
+
+		return violations
+	})
+
+	val.AddRule(rules, func(req *pb.SetTransactionCategoryRequest) (violations []val.Violation) {
+		if err := val.ValidateEntryId(req.GetEntryId()); err != nil {
+			violations = append(violations, val.Violation{Field: "entry_id", Err: err})
+		}
+
+		paths := setTransactionCategoryPaths(req)
+		if len(paths) == 0 {
+			violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("must name at least one field to update")})
+		}
+		for _, path := range paths {
+			if !categorizableEntryFields[path] {
+				violations = append(violations, val.Violation{Field: "update_mask", Err: fmt.Errorf("%q is not a field that can be updated", path)})
+			}
+		}
+		fields := setTransactionCategoryFields(req)
+
+		// an empty category removes it
+		if fields["category"] && req.GetCategory() != "" {
+			if err := val.ValidateCategory(req.GetCategory()); err != nil {
+				violations = append(violations, val.Violation{Field: "category", Err: err})
+			}
+		}
+
+		if fields["tags"] {
+			if err := val.ValidateTags(val.NormalizeTags(req.GetTags())); err != nil {
+				violations = append(violations, val.Violation{Field: "tags", Err: err})
+			}
+		}
+
+		return violations
+	})
+
+	return rules
+}
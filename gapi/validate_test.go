@@ -0,0 +1,180 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+type violation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// requireViolations checks err is InvalidArgument with exactly the violations want, in order
+func requireViolations(t *testing.T, err error, want []violation) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, "invalid parameters", st.Message())
+	require.Len(t, st.Details(), 1)
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	got := make([]violation, len(badRequest.GetFieldViolations()))
+	for i, v := range badRequest.GetFieldViolations() {
+		got[i] = violation{Field: v.GetField(), Description: v.GetDescription()}
+	}
+	require.Equal(t, want, got)
+}
+
+func TestGrpcValidate(t *testing.T) {
+	short := "ab"
+	badEmail := "not an email"
+	badPhone := "12345"
+	badName := "R2-D2"
+
+	testCases := []struct {
+		name       string
+		call       func(ctx context.Context, client pb.SwiftBankClient) error
+		violations []violation
+	}{
+		{
+			name: "LoginUser",
+			call: func(ctx context.Context, client pb.SwiftBankClient) error {
+				_, err := client.LoginUser(ctx, &pb.LoginUserRequest{Username: "a-b", Password: "123"})
+				return err
+			},
+			violations: []violation{
+				{Field: "username", Description: "must contain lowercase letters, numbers, or underscore"},
+				{Field: "password", Description: "must contain from 6 - 100 characters"},
+			},
+		},
+		{
+			name: "LoginUserNormalized",
+			call: func(ctx context.Context, client pb.SwiftBankClient) error {
+				_, err := client.LoginUser(ctx, &pb.LoginUserRequest{Username: " Alice ", Password: "1"})
+				return err
+			},
+			violations: []violation{
+				{Field: "password", Description: "must contain from 6 - 100 characters"},
+			},
+		},
+		{
+			name: "UpdateUserFields",
+			call: func(ctx context.Context, client pb.SwiftBankClient) error {
+				_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{
+					Username:    "alice",
+					Password:    &short,
+					FullName:    &badName,
+					Email:       &badEmail,
+					PhoneNumber: &badPhone,
+				})
+				return err
+			},
+			violations: []violation{
+				{Field: "password", Description: "must contain from 6 - 100 characters"},
+				{Field: "full_name", Description: "must contain only letters, or spaces"},
+				{Field: "email", Description: "invalid email address"},
+				{Field: "phone_number", Description: "must be in E.164 format, a + followed by 7 to 15 digits"},
+			},
+		},
+		{
+			name: "UpdateUserMask",
+			call: func(ctx context.Context, client pb.SwiftBankClient) error {
+				_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{
+					Username:   "alice",
+					UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"role", "full_name", "balance"}},
+				})
+				return err
+			},
+			violations: []violation{
+				{Field: "update_mask", Description: `"role" is not a field that can be updated`},
+				{Field: "update_mask", Description: `"balance" is not a field that can be updated`},
+				{Field: "full_name", Description: "must contain from 3 - 100 characters"},
+			},
+		},
+		{
+			name: "UpdateUserNothing",
+			call: func(ctx context.Context, client pb.SwiftBankClient) error {
+				_, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Username: "x"})
+				return err
+			},
+			violations: []violation{
+				{Field: "username", Description: "must contain from 3 - 100 characters"},
+				{Field: "update_mask", Description: "must name at least one field to update"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl)) // no call reaches the service
+			client := startTestServer(t, server, grpc.ChainUnaryInterceptor(server.GrpcAuth, server.GrpcValidate))
+
+			ctx := newContextWithBearerToken(t, server, "alice", helpers.DepositorRole)
+			requireViolations(t, tc.call(ctx, client), tc.violations)
+		})
+	}
+}
+
+func TestGrpcValidateWithoutRules(t *testing.T) {
+	server := &Server{rules: (&Server{}).requestRules()}
+
+	called := false
+	_, err := server.GrpcValidate(context.Background(), &pb.ServerInfoRequest{}, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestGatewayValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server := newTestServer(t, mockedproviders.NewMockServiceProvider(ctrl))
+	gateway := startTestGateway(t, server)
+
+	response, err := http.Post(gateway.URL+"/v1/login_user", "application/json", strings.NewReader(`{"username":"a-b","password":"123"}`))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	var body struct {
+		Details []struct {
+			FieldViolations []violation `json:"fieldViolations"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	require.Len(t, body.Details, 1)
+	require.Equal(t, []violation{
+		{Field: "username", Description: "must contain lowercase letters, numbers, or underscore"},
+		{Field: "password", Description: "must contain from 6 - 100 characters"},
+	}, body.Details[0].FieldViolations)
+}
+
+func TestInProcessConnUnknownMethod(t *testing.T) {
+	conn := NewInProcessConn(&Server{}, nil)
+
+	err := conn.Invoke(context.Background(), "/pb.SwiftBank/Unknown", &pb.ServerInfoRequest{}, &pb.ServerInfoResponse{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = conn.NewStream(context.Background(), &grpc.StreamDesc{}, "/pb.SwiftBank/StreamTransactions")
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
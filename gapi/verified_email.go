@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reasonEmailNotVerified is the ErrorInfo reason of requests refused until the user verifies
+// their email, the gateway answers it with 403
+const reasonEmailNotVerified = "EMAIL_NOT_VERIFIED"
+
+// emailNotVerifiedError is FailedPrecondition with reasonEmailNotVerified in an ErrorInfo, for
+// clients to offer sending the link again
+func emailNotVerifiedError() error {
+	st := status.New(codes.FailedPrecondition, "email address is not verified, follow the link sent to it first")
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reasonEmailNotVerified,
+		Domain: "swiftbank",
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// requireVerifiedEmail refuses username when verified emails are required and theirs is not.
+// LoginUser already refuses them, this catches tokens issued before the requirement was turned on.
+func (server *Server) requireVerifiedEmail(ctx context.Context, username string) error {
+	if !server.config.RequireVerifiedEmail {
+		return nil
+	}
+
+	user, err := server.service.GetUser(ctx, username)
+	if err != nil {
+		return dbErrorToStatus(fmt.Errorf("failed to get user: %w", err))
+	}
+	if !user.IsEmailVerified {
+		return emailNotVerifiedError()
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifyEmailDistributor records the tasks queued by login and ResendVerificationEmail
+type verifyEmailDistributor struct {
+	worker.TaskDistributor
+	payloads []*worker.PayloadSendVerifyEmail
+}
+
+func (d *verifyEmailDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *worker.PayloadSendVerifyEmail, opts ...asynq.Option) error {
+	d.payloads = append(d.payloads, payload)
+	return nil
+}
+
+func (d *verifyEmailDistributor) DistributeTaskNotifyNewLogin(ctx context.Context, payload *worker.PayloadNotifyNewLogin, opts ...asynq.Option) error {
+	return nil
+}
+
+func requireEmailNotVerified(t *testing.T, err error) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, reasonEmailNotVerified, info.GetReason())
+}
+
+func TestLoginUserRequireVerifiedEmail(t *testing.T) {
+	testCases := []struct {
+		name          string
+		require       bool
+		verified      bool
+		checkResponse func(t *testing.T, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name:     "OffUnverified",
+			require:  false,
+			verified: false,
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+			},
+		},
+		{
+			name:     "OnVerified",
+			require:  true,
+			verified: true,
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+			},
+		},
+		{
+			name:     "OnUnverified",
+			require:  true,
+			verified: false,
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				requireEmailNotVerified(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := models.User{
+				UserName:        helpers.RandomOwner(),
+				Email:           helpers.RandomEmail(),
+				Role:            helpers.DepositorRole,
+				IsEmailVerified: tc.verified,
+			}
+
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			service.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+
+			sessions := 1
+			if tc.require && !tc.verified {
+				sessions = 0
+			}
+			service.EXPECT().
+				NewSession(gomock.Any(), gomock.Any()).
+				Times(sessions).
+				DoAndReturn(func(ctx context.Context, session models.Session) (models.Session, error) {
+					return session, nil
+				})
+
+			server := newTestServer(t, service)
+			server.config.RequireVerifiedEmail = tc.require
+			server.taskDistributor = &verifyEmailDistributor{}
+			client := startTestServer(t, server)
+
+			res, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.UserName, Password: "secret"})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// TestTransferMoneyRequireVerifiedEmail checks a token issued before verified emails were
+// required cannot move money
+func TestTransferMoneyRequireVerifiedEmail(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), Email: helpers.RandomEmail()}
+	req := &pb.TransferMoneyRequest{
+		FromAccountId: 1,
+		Recipient:     &pb.TransferMoneyRequest_ToAccountId{ToAccountId: 2},
+		Amount:        "10.00",
+		Currency:      "USD",
+	}
+
+	t.Run("On", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service := mockedproviders.NewMockServiceProvider(ctrl)
+		service.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+		service.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+		server := newTestServer(t, service)
+		server.config.RequireVerifiedEmail = true
+		client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+		_, err := client.TransferMoney(newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole), req)
+		requireEmailNotVerified(t, err)
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service := mockedproviders.NewMockServiceProvider(ctrl)
+		service.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+		service.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+
+		server := newTestServer(t, service)
+		client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+		_, err := client.TransferMoney(newContextWithBearerToken(t, server, user.UserName, helpers.DepositorRole), req)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestHTTPErrorsEmailNotVerified(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), Email: helpers.RandomEmail(), Role: helpers.DepositorRole}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+
+	server := newTestServer(t, service)
+	server.config.RequireVerifiedEmail = true
+	handler := newErrorTestGateway(t, server, HTTPErrors{HideInternal: true})
+
+	body := `{"username":"` + user.UserName + `","password":"secret"}`
+	request := httptest.NewRequest(http.MethodPost, "/v1/login_user", strings.NewReader(body))
+	code, envelope := serveErrorEnvelope(t, handler, request)
+
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, codes.FailedPrecondition, envelope.Code)
+	require.Len(t, envelope.Details, 1)
+	require.Equal(t, reasonEmailNotVerified, envelope.Details[0].Reason)
+}
+
+func TestResendVerificationEmailAPI(t *testing.T) {
+	unverified := models.User{UserName: helpers.RandomOwner(), Email: helpers.RandomEmail()}
+	verified := models.User{UserName: helpers.RandomOwner(), Email: helpers.RandomEmail(), IsEmailVerified: true}
+
+	testCases := []struct {
+		name       string
+		email      string
+		buildStubs func(service *mockedproviders.MockServiceProvider)
+		code       codes.Code
+		queued     []*worker.PayloadSendVerifyEmail
+	}{
+		{
+			name:  "Unverified",
+			email: strings.ToUpper(unverified.Email),
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(unverified.Email)).Times(1).Return(unverified, nil)
+			},
+			code:   codes.OK,
+			queued: []*worker.PayloadSendVerifyEmail{{Username: unverified.UserName}},
+		},
+		{
+			name:  "Verified",
+			email: verified.Email,
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(verified.Email)).Times(1).Return(verified, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:  "UnknownEmail",
+			email: "nobody@example.com",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(models.User{}, pgx.ErrNoRows)
+			},
+			code: codes.OK,
+		},
+		{
+			name:  "InvalidEmail",
+			email: "not-an-email",
+			buildStubs: func(service *mockedproviders.MockServiceProvider) {
+				service.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := mockedproviders.NewMockServiceProvider(ctrl)
+			tc.buildStubs(service)
+
+			server := newTestServer(t, service)
+			distributor := &verifyEmailDistributor{}
+			server.taskDistributor = distributor
+			client := startTestServer(t, server, grpc.UnaryInterceptor(server.GrpcAuth))
+
+			_, err := client.ResendVerificationEmail(context.Background(), &pb.ResendVerificationEmailRequest{Email: tc.email})
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, tc.queued, distributor.payloads)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	APIPathPrefix    = "/v1/"
+	legacyPathPrefix = "/sb/api/v1/" // where the routes lived before they were versioned
+)
+
+// LegacyPaths keeps serving the routes at their old paths until sunset. The request is handled as
+// if it was sent to the /v1 path, and the response tells the client where the route moved and
+// when the old path goes away.
+func LegacyPaths(sunset time.Time, handler http.Handler) http.Handler {
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, legacyPathPrefix) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = APIPathPrefix + strings.TrimPrefix(r.URL.Path, legacyPathPrefix)
+		r2.URL.RawPath = ""
+
+		header := w.Header()
+		header.Set("Deprecation", "true")
+		header.Set("Sunset", sunsetHeader)
+		header.Set("Link", "<"+r2.URL.Path+`>; rel="successor-version"`)
+		handler.ServeHTTP(w, r2)
+	})
+}
+
+// VersionHandler answers /v1/version with the version and commit the binary was built from
+func VersionHandler(version, commit string) http.Handler {
+	body, _ := json.Marshal(map[string]string{
+		"version": version,
+		"commit":  commit,
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeHTTPError(w, r, &runtime.HTTPStatusError{
+				HTTPStatus: http.StatusMethodNotAllowed,
+				Err:        status.Error(codes.Unimplemented, http.StatusText(http.StatusMethodNotAllowed)),
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
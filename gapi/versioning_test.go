@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLegacyPaths(t *testing.T) {
+	transfer := models.Transaction{ID: 7, FromAccountID: 1, ToAccountID: 2, Amount: 25, Currency: helpers.USD}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetTransaction(gomock.Any(), gomock.Eq(transfer.ID)).Times(2).Return(transfer, nil)
+
+	server := newTestServer(t, service)
+	accessToken, _, err := server.tokenMaker.CreateToken("ada", helpers.BankerRole, time.Minute)
+	require.NoError(t, err)
+
+	grpcMux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterSwiftBankHandlerServer(context.Background(), grpcMux, server))
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	handler := LegacyPaths(sunset, server.HttpAuth(grpcMux))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	current := get("/v1/transfers/7")
+	require.Equal(t, http.StatusOK, current.Code)
+	require.Empty(t, current.Header().Get("Deprecation"))
+	require.Empty(t, current.Header().Get("Sunset"))
+
+	// the old path reaches the same RPC, through the same auth
+	legacy := get("/sb/api/v1/transfers/7")
+	require.Equal(t, http.StatusOK, legacy.Code)
+	require.Equal(t, current.Body.String(), legacy.Body.String())
+	require.Equal(t, "true", legacy.Header().Get("Deprecation"))
+	require.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", legacy.Header().Get("Sunset"))
+	require.Equal(t, `</v1/transfers/7>; rel="successor-version"`, legacy.Header().Get("Link"))
+
+	// and is rejected without a token like the new one
+	request := httptest.NewRequest(http.MethodGet, "/sb/api/v1/transfers/7", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestVersionHandler(t *testing.T) {
+	handler := VersionHandler("1.4.0", "3f2c1ab")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/version", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, map[string]string{"version": "1.4.0", "commit": "3f2c1ab"}, body)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/version", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -0,0 +1,162 @@
+// Package health checks the dependencies the service cannot work without and reports the
+// result through the standard gRPC health service
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Pinger is a dependency that can tell whether it is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc lets a plain function, like a redis ping, be used as a Pinger
+type PingerFunc func(ctx context.Context) error
+
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// StatsReporter is a dependency with figures worth showing next to its status, like the use of
+// a connection pool
+type StatsReporter interface {
+	Stats() any
+}
+
+// Optional marks a dependency the service can do without, like a read replica. It is still
+// pinged, but its failure only degrades the service: the error is reported as a DegradedError
+// and Healthy ignores it.
+func Optional(pinger Pinger) Pinger {
+	return optionalPinger{pinger}
+}
+
+type optionalPinger struct {
+	Pinger
+}
+
+func (p optionalPinger) Stats() any {
+	if reporter, ok := p.Pinger.(StatsReporter); ok {
+		return reporter.Stats()
+	}
+	return nil
+}
+
+func (p optionalPinger) Ping(ctx context.Context) error {
+	if err := p.Pinger.Ping(ctx); err != nil {
+		return &DegradedError{Err: err}
+	}
+	return nil
+}
+
+// DegradedError is the failure of an optional dependency
+type DegradedError struct {
+	Err error
+}
+
+func (e *DegradedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DegradedError) Unwrap() error {
+	return e.Err
+}
+
+// IsDegraded reports whether err is the failure of an optional dependency
+func IsDegraded(err error) bool {
+	var degraded *DegradedError
+	return errors.As(err, &degraded)
+}
+
+// Checker pings every dependency, giving each of them at most timeout to answer
+type Checker struct {
+	pingers map[string]Pinger
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration, pingers map[string]Pinger) *Checker {
+	return &Checker{
+		pingers: pingers,
+		timeout: timeout,
+	}
+}
+
+// Check pings the dependencies concurrently and returns the error of each one keyed by its
+// name, nil for the ones that answered
+func (c *Checker) Check(ctx context.Context) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]error, len(c.pingers))
+	)
+	for name, pinger := range c.pingers {
+		wg.Add(1)
+		go func(name string, pinger Pinger) {
+			defer wg.Done()
+
+			err := pinger.Ping(ctx)
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, pinger)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Healthy reports whether every dependency that isn't optional answered
+func Healthy(results map[string]error) bool {
+	for _, err := range results {
+		if err != nil && !IsDegraded(err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Watch checks the dependencies every interval until ctx is cancelled, and sets the overall
+// status of server to SERVING while all of them answer and NOT_SERVING otherwise
+func (c *Checker) Watch(ctx context.Context, server *grpchealth.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	serving := true
+	for {
+		results := c.Check(ctx)
+		for name, err := range results {
+			if err != nil {
+				log.Warn().Err(err).Str("dependency", name).Msg("health check failed")
+			}
+		}
+
+		if healthy := Healthy(results); healthy != serving {
+			log.Info().Bool("serving", healthy).Msg("serving status changed")
+			serving = healthy
+		}
+
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if !serving {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		server.SetServingStatus("", status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
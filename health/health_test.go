@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakePinger fails while its err is set
+type fakePinger struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (p *fakePinger) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+func (p *fakePinger) fail(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+func (p *fakePinger) recover() {
+	p.fail(nil)
+}
+
+func TestCheck(t *testing.T) {
+	postgres := &fakePinger{}
+	redis := &fakePinger{}
+	redis.fail(errors.New("connection refused"))
+
+	checker := NewChecker(time.Second, map[string]Pinger{"postgres": postgres, "redis": redis})
+
+	results := checker.Check(context.Background())
+	require.Len(t, results, 2)
+	require.NoError(t, results["postgres"])
+	require.EqualError(t, results["redis"], "connection refused")
+	require.False(t, Healthy(results))
+
+	redis.recover()
+	require.True(t, Healthy(checker.Check(context.Background())))
+}
+
+func TestCheckOptional(t *testing.T) {
+	replica := &fakePinger{}
+	replica.fail(errors.New("connection refused"))
+
+	checker := NewChecker(time.Second, map[string]Pinger{"postgres": &fakePinger{}, "postgres_replica": Optional(replica)})
+
+	results := checker.Check(context.Background())
+	require.EqualError(t, results["postgres_replica"], "connection refused")
+	require.True(t, IsDegraded(results["postgres_replica"]))
+	require.True(t, Healthy(results))
+}
+
+func TestCheckTimeout(t *testing.T) {
+	slow := PingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	checker := NewChecker(20*time.Millisecond, map[string]Pinger{"postgres": slow})
+
+	start := time.Now()
+	results := checker.Check(context.Background())
+	require.ErrorIs(t, results["postgres"], context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestWatch(t *testing.T) {
+	postgres := &fakePinger{}
+	checker := NewChecker(time.Second, map[string]Pinger{"postgres": postgres})
+	server := grpchealth.NewServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.Watch(ctx, server, 10*time.Millisecond)
+		close(done)
+	}()
+
+	requireStatus(t, server, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	postgres.fail(errors.New("connection refused"))
+	requireStatus(t, server, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	postgres.recover()
+	requireStatus(t, server, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch did not return after the context was cancelled")
+	}
+}
+
+func requireStatus(t *testing.T, server *grpchealth.Server, want grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		res, err := server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		return err == nil && res.Status == want
+	}, time.Second, 5*time.Millisecond)
+}
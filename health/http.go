@@ -0,0 +1,85 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// IsProbe reports whether path is one of the probe endpoints, which are polled often enough
+// that they are left out of the access log
+func IsProbe(path string) bool {
+	return path == LivenessPath || path == ReadinessPath
+}
+
+type dependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Stats  any    `json:"stats,omitempty"`
+}
+
+type readiness struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyStatus `json:"dependencies"`
+}
+
+type liveness struct {
+	Status   string `json:"status"`
+	LogLevel string `json:"log_level"`
+}
+
+// LivenessHandler answers as long as the process can serve HTTP at all, with the log level in
+// effect so a level left changed shows
+func LivenessHandler(logLevel func() zerolog.Level) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(liveness{Status: "ok", LogLevel: logLevel().String()})
+	})
+}
+
+// ReadinessHandler checks the dependencies on every request and answers 503 when any of them
+// is down, with the status of each one in the body, and the stats of those that report them. An
+// optional dependency being down still answers 200, with a degraded status.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := c.Check(r.Context())
+
+		body := readiness{
+			Status:       "ok",
+			Dependencies: make(map[string]dependencyStatus, len(results)),
+		}
+		for name, err := range results {
+			switch {
+			case IsDegraded(err):
+				body.Status = "degraded"
+				body.Dependencies[name] = dependencyStatus{Status: "down", Error: err.Error()}
+			case err != nil:
+				body.Dependencies[name] = dependencyStatus{Status: "down", Error: err.Error()}
+			default:
+				body.Dependencies[name] = dependencyStatus{Status: "ok"}
+			}
+
+			if reporter, ok := c.pingers[name].(StatsReporter); ok {
+				status := body.Dependencies[name]
+				status.Stats = reporter.Stats()
+				body.Dependencies[name] = status
+			}
+		}
+
+		statusCode := http.StatusOK
+		if !Healthy(results) {
+			body.Status = "unavailable"
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(body)
+	})
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	logLevel := func() zerolog.Level { return zerolog.DebugLevel }
+	LivenessHandler(logLevel).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status":"ok","log_level":"debug"}`, recorder.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		name          string
+		redisErr      error
+		replicaErr    error
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				body := decodeReadiness(t, recorder)
+				require.Equal(t, "ok", body.Status)
+				require.Equal(t, dependencyStatus{Status: "ok"}, body.Dependencies["postgres"])
+				require.Equal(t, dependencyStatus{Status: "ok"}, body.Dependencies["redis"])
+			},
+		},
+		{
+			name:     "DependencyDown",
+			redisErr: errors.New("connection refused"),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+				body := decodeReadiness(t, recorder)
+				require.Equal(t, "unavailable", body.Status)
+				require.Equal(t, dependencyStatus{Status: "ok"}, body.Dependencies["postgres"])
+				require.Equal(t, dependencyStatus{Status: "down", Error: "connection refused"}, body.Dependencies["redis"])
+			},
+		},
+		{
+			name:       "OptionalDependencyDown",
+			replicaErr: errors.New("connection refused"),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				body := decodeReadiness(t, recorder)
+				require.Equal(t, "degraded", body.Status)
+				require.Equal(t, dependencyStatus{Status: "ok"}, body.Dependencies["redis"])
+				require.Equal(t, dependencyStatus{Status: "down", Error: "connection refused"}, body.Dependencies["postgres_replica"])
+			},
+		},
+		{
+			name:       "BothDown",
+			redisErr:   errors.New("connection refused"),
+			replicaErr: errors.New("connection refused"),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, "unavailable", decodeReadiness(t, recorder).Status)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			redis := &fakePinger{}
+			redis.fail(tc.redisErr)
+			replica := &fakePinger{}
+			replica.fail(tc.replicaErr)
+			checker := NewChecker(time.Second, map[string]Pinger{"postgres": &fakePinger{}, "redis": redis, "postgres_replica": Optional(replica)})
+
+			recorder := httptest.NewRecorder()
+			checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+
+			require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func decodeReadiness(t *testing.T, recorder *httptest.ResponseRecorder) readiness {
+	var body readiness
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return body
+}
+
+// statsPinger answers with stats
+type statsPinger struct {
+	fakePinger
+}
+
+func (p *statsPinger) Stats() any {
+	return map[string]int{"acquired_conns": 3}
+}
+
+func TestReadinessHandlerStats(t *testing.T) {
+	checker := NewChecker(time.Second, map[string]Pinger{"postgres": &statsPinger{}, "postgres_replica": Optional(&statsPinger{}), "redis": &fakePinger{}})
+
+	recorder := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := decodeReadiness(t, recorder)
+	require.Equal(t, map[string]any{"acquired_conns": float64(3)}, body.Dependencies["postgres"].Stats)
+	require.Equal(t, map[string]any{"acquired_conns": float64(3)}, body.Dependencies["postgres_replica"].Stats)
+	require.Nil(t, body.Dependencies["redis"].Stats)
+}
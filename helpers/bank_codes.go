@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ibanLengths is the length of an IBAN in each country that issues them
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24,
+	"ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24, "SC": 31,
+	"SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24,
+	"TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+var (
+	isIBANFormat = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`).MatchString
+	// 4 letter bank code, 2 letter country, 2 character location and an optional 3 character branch
+	isBICFormat = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`).MatchString
+)
+
+// NormalizeIBAN removes the spaces of the printed form and upper-cases the IBAN
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// IBANLength returns the IBAN length of country and whether the country issues IBANs at all
+func IBANLength(country string) (int, bool) {
+	length, ok := ibanLengths[country]
+	return length, ok
+}
+
+// IsIBANChecksumValid runs the ISO 13616 mod-97 check on a normalized IBAN
+func IsIBANChecksumValid(iban string) bool {
+	if len(iban) < 5 || !isIBANFormat(iban) {
+		return false
+	}
+
+	// move the country code and check digits to the end and turn letters into 10 to 35
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// IsValidIBAN reports whether iban, in printed or electronic form, has the right length for its country and a valid checksum
+func IsValidIBAN(iban string) bool {
+	iban = NormalizeIBAN(iban)
+	if len(iban) < 2 {
+		return false
+	}
+
+	length, ok := IBANLength(iban[:2])
+	return ok && len(iban) == length && IsIBANChecksumValid(iban)
+}
+
+// IsValidBIC reports whether bic is a structurally valid 8 or 11 character BIC/SWIFT code
+func IsValidBIC(bic string) bool {
+	return isBICFormat(bic)
+}
@@ -1,5 +1,14 @@
 package helpers
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/zde37/Swift_Bank/money"
+)
+
 // Constants for all supported currencies
 const (
 	USD = "USD"
@@ -7,10 +16,75 @@ const (
 	CAD = "CAD"
 )
 
+// supportedCurrencies is the set of enabled currencies, replaced as a whole by
+// SetSupportedCurrencies. It starts with the currencies every deployment has.
+var supportedCurrencies atomic.Pointer[map[string]bool]
+
+func init() {
+	SetSupportedCurrencies([]string{USD, EUR, CAD})
+}
+
+// SetSupportedCurrencies replaces the currencies ISSupportedCurrency accepts, the currencies cache
+// calls it whenever it loads the currencies table
+func SetSupportedCurrencies(codes []string) {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	supportedCurrencies.Store(&set)
+}
+
+// ISSupportedCurrency reports whether currency is enabled, new accounts and transfers may use it
 func ISSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
+	return (*supportedCurrencies.Load())[currency]
+}
+
+// ParseCurrencyAmounts parses a list like "USD:10000,EUR:9000.50" into an amount per currency, in
+// minor units
+func ParseCurrencyAmounts(value string) (map[string]int64, error) {
+	amounts := map[string]int64{}
+	if strings.TrimSpace(value) == "" {
+		return amounts, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		currency, amount, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || !ISSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+
+		parsed, err := money.FromDecimalString(amount, currency)
+		if err != nil || parsed.Units <= 0 {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+
+		amounts[currency] = parsed.Units
+	}
+
+	return amounts, nil
+}
+
+// ParseInterestRates parses a list like "USD:2.5,EUR:1.75" into a yearly interest rate per
+// currency, in percent. A rate must be above 0 and at most 100.
+func ParseInterestRates(value string) (map[string]float64, error) {
+	rates := map[string]float64{}
+	if strings.TrimSpace(value) == "" {
+		return rates, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		currency, rate, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || !ISSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid interest rate %q", pair)
+		}
+
+		parsed, err := strconv.ParseFloat(rate, 64)
+		if err != nil || !(parsed > 0 && parsed <= 100) {
+			return nil, fmt.Errorf("invalid interest rate %q", pair)
+		}
+
+		rates[currency] = parsed
 	}
-	return false
+
+	return rates, nil
 }
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrencyAmounts(t *testing.T) {
+	amounts, err := ParseCurrencyAmounts("USD:10000, EUR:9000.50")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{USD: 1000000, EUR: 900050}, amounts)
+
+	amounts, err = ParseCurrencyAmounts("")
+	require.NoError(t, err)
+	require.Empty(t, amounts)
+
+	for _, value := range []string{"USD", "XYZ:10", "USD:-1", "USD:ten", "USD:1.005"} {
+		_, err := ParseCurrencyAmounts(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseInterestRates(t *testing.T) {
+	rates, err := ParseInterestRates("USD:2.5, EUR:1.75")
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{USD: 2.5, EUR: 1.75}, rates)
+
+	rates, err = ParseInterestRates("")
+	require.NoError(t, err)
+	require.Empty(t, rates)
+
+	for _, value := range []string{"USD", "XYZ:1", "USD:0", "USD:-1", "USD:101", "USD:NaN", "USD:high"} {
+		_, err := ParseInterestRates(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestSetSupportedCurrencies(t *testing.T) {
+	t.Cleanup(func() { SetSupportedCurrencies([]string{USD, EUR, CAD}) })
+
+	require.True(t, ISSupportedCurrency(USD))
+	require.False(t, ISSupportedCurrency("NGN"))
+
+	SetSupportedCurrencies([]string{USD, "NGN"})
+	require.True(t, ISSupportedCurrency("NGN"))
+	require.False(t, ISSupportedCurrency(EUR))
+}
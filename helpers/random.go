@@ -35,7 +35,7 @@ func RandomEmail() string {
 }
 
 func RandomMoney() int64  {
-	return RandomInt(0, 1000)
+	return RandomInt(1, 1000)
 }
 
 func RandomFee() int64  {
@@ -9,3 +9,17 @@ var ValidCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var ValidIBAN validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if iban, ok := fieldLevel.Field().Interface().(string); ok {
+		return IsValidIBAN(iban)
+	}
+	return false
+}
+
+var ValidBIC validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if bic, ok := fieldLevel.Field().Interface().(string); ok {
+		return IsValidBIC(bic)
+	}
+	return false
+}
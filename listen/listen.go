@@ -0,0 +1,172 @@
+// Package listen opens the listeners of the servers: TCP, unix sockets, or sockets passed on by
+// systemd socket activation
+package listen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// unixScheme prefixes the addresses of unix sockets, like unix:///run/swift_bank/grpc.sock
+const unixScheme = "unix://"
+
+// DefaultMode is the permission of the unix sockets when none is configured, the owner and its group can connect
+const DefaultMode fs.FileMode = 0o660
+
+// listenFDsStart is the first descriptor systemd passes, the ones before are stdin, stdout and stderr
+const listenFDsStart = 3
+
+var (
+	mu           sync.Mutex
+	loaded       bool
+	activated    map[string]net.Listener
+	activatedErr error
+)
+
+// Listen returns the socket activated under name when the process was started by systemd with
+// one, see FileDescriptorName in systemd.socket. Otherwise it listens on address, a unix socket
+// with mode as its permissions when address is like unix:///path/to.sock and TCP for anything else.
+// A socket file left behind by a server that did not shut down is removed first. The file is
+// removed again when the listener is closed.
+func Listen(name, address string, mode fs.FileMode) (net.Listener, error) {
+	mu.Lock()
+	if !loaded {
+		activated, activatedErr = activatedListeners()
+		loaded = true
+	}
+	listener, ok := activated[name]
+	delete(activated, name)
+	err := activatedErr
+	mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return listener, nil
+	}
+
+	if path, ok := UnixPath(address); ok {
+		return listenUnix(path, mode)
+	}
+	return net.Listen("tcp", address)
+}
+
+// UnixPath returns the path of the socket file when address is a unix socket address
+func UnixPath(address string) (string, bool) {
+	return strings.CutPrefix(address, unixScheme)
+}
+
+// ParseMode reads socket permissions written in octal, like 0660. Empty means DefaultMode.
+func ParseMode(mode string) (fs.FileMode, error) {
+	if mode == "" {
+		return DefaultMode, nil
+	}
+
+	n, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || n > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("must be octal permissions like 0660, got %q", mode)
+	}
+	return fs.FileMode(n), nil
+}
+
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("cannot set the permissions of %s: %w", path, err)
+	}
+
+	return listener, nil
+}
+
+// removeStale removes the socket file at path when no server answers on it anymore. A socket
+// still in use, or a file that is no socket, is left alone and reported.
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", path)
+	}
+
+	return os.Remove(path)
+}
+
+// activatedListeners takes over the sockets systemd passed to this process, keyed by their name.
+// The variables are unset so processes started from this one don't take them too.
+func activatedListeners() (map[string]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	files := make([]*os.File, count)
+	for i := range files {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		files[i] = os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+	}
+
+	return fileListeners(files, names)
+}
+
+// fileListeners turns files into listeners named by names, in the same order. The files are
+// closed, the listeners hold their own copy of the descriptors.
+func fileListeners(files []*os.File, names []string) (map[string]net.Listener, error) {
+	listeners := make(map[string]net.Listener, len(files))
+	for i, file := range files {
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			return nil, fmt.Errorf("activated socket %d is not a listening socket: %w", i, err)
+		}
+
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		listeners[name] = listener
+	}
+
+	return listeners, nil
+}
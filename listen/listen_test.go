@@ -0,0 +1,144 @@
+package listen
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	listener, err := Listen("grpc", unixScheme+path, 0o600)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket, info.Mode().Type())
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+
+	// the socket is in use, a second server must not take it over
+	_, err = Listen("grpc", unixScheme+path, 0o600)
+	require.ErrorContains(t, err, "in use")
+
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestListenUnixStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	// a server that crashed leaves its socket file behind
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	listener, err := Listen("grpc", unixScheme+path, DefaultMode)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestListenUnixNotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := Listen("grpc", unixScheme+path, DefaultMode)
+	require.ErrorContains(t, err, "not a socket")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(content))
+}
+
+func TestListenTCP(t *testing.T) {
+	listener, err := Listen("http", "127.0.0.1:0", DefaultMode)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	require.Equal(t, "tcp", listener.Addr().Network())
+}
+
+func TestFileListeners(t *testing.T) {
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer grpcListener.Close()
+	httpListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: filepath.Join(t.TempDir(), "http.sock"), Net: "unix"})
+	require.NoError(t, err)
+	defer httpListener.Close()
+
+	grpcFile, err := grpcListener.(*net.TCPListener).File()
+	require.NoError(t, err)
+	httpFile, err := httpListener.File()
+	require.NoError(t, err)
+
+	listeners, err := fileListeners([]*os.File{grpcFile, httpFile}, []string{"grpc", "http"})
+	require.NoError(t, err)
+	require.Len(t, listeners, 2)
+	defer listeners["grpc"].Close()
+	defer listeners["http"].Close()
+
+	require.Equal(t, grpcListener.Addr().String(), listeners["grpc"].Addr().String())
+	require.Equal(t, httpListener.Addr().String(), listeners["http"].Addr().String())
+
+	// the activated listener accepts on the socket systemd opened
+	go func() {
+		conn, err := net.Dial("tcp", grpcListener.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := listeners["grpc"].Accept()
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestFileListenersNotListening(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "file")
+	require.NoError(t, err)
+
+	_, err = fileListeners([]*os.File{file}, []string{"grpc"})
+	require.Error(t, err)
+}
+
+func TestParseMode(t *testing.T) {
+	testCases := []struct {
+		mode string
+		want os.FileMode
+		ok   bool
+	}{
+		{mode: "", want: DefaultMode, ok: true},
+		{mode: "0600", want: 0o600, ok: true},
+		{mode: "660", want: 0o660, ok: true},
+		{mode: "0777", want: 0o777, ok: true},
+		{mode: "01777"},
+		{mode: "0689"},
+		{mode: "rw-rw----"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			mode, err := ParseMode(tc.mode)
+			if !tc.ok {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, mode)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package loglevel changes the level of the global logger while the service runs
+package loglevel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Controller owns zerolog's global level. A level set for a while goes back to the base level on
+// its own, so debug logging turned on to look into a problem cannot be left on by mistake.
+type Controller struct {
+	base zerolog.Level
+
+	mu         sync.Mutex
+	timer      *time.Timer
+	generation int // tells a revert that fires late it was replaced
+}
+
+// NewController sets the global level to base and returns a controller for it
+func NewController(base zerolog.Level) *Controller {
+	zerolog.SetGlobalLevel(base)
+	return &Controller{base: base}
+}
+
+// Level is the level in effect. zerolog keeps it in an atomic, so any goroutine can read it.
+func (c *Controller) Level() zerolog.Level {
+	return zerolog.GlobalLevel()
+}
+
+// Set changes the level for duration and returns when the base level comes back. It replaces a
+// revert still pending.
+func (c *Controller) Set(level zerolog.Level, duration time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation := c.replace()
+	zerolog.SetGlobalLevel(level)
+	c.timer = time.AfterFunc(duration, func() { c.revert(generation) })
+
+	return time.Now().Add(duration)
+}
+
+// Toggle switches between debug and info, for as long as it is not toggled or set again
+func (c *Controller) Toggle() zerolog.Level {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.replace()
+	level := zerolog.DebugLevel
+	if zerolog.GlobalLevel() <= zerolog.DebugLevel {
+		level = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(level)
+
+	return level
+}
+
+// replace stops the pending revert and returns the generation of the change replacing it. c.mu
+// must be held.
+func (c *Controller) replace() int {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.generation++
+	return c.generation
+}
+
+func (c *Controller) revert(generation int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the timer may have fired just as it was stopped
+	if generation != c.generation {
+		return
+	}
+	c.timer = nil
+	zerolog.SetGlobalLevel(c.base)
+	log.WithLevel(zerolog.NoLevel).Str("level", c.base.String()).Msg("log level reverted")
+}
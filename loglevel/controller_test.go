@@ -0,0 +1,74 @@
+package loglevel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControllerSetReverts(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	c := NewController(zerolog.InfoLevel)
+	require.Equal(t, zerolog.InfoLevel, c.Level())
+
+	revertAt := c.Set(zerolog.DebugLevel, 50*time.Millisecond)
+	require.Equal(t, zerolog.DebugLevel, c.Level())
+	require.WithinDuration(t, time.Now().Add(50*time.Millisecond), revertAt, time.Second)
+
+	require.Eventually(t, func() bool { return c.Level() == zerolog.InfoLevel }, time.Second, 10*time.Millisecond)
+}
+
+func TestControllerSetReplacesPendingRevert(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	c := NewController(zerolog.InfoLevel)
+	c.Set(zerolog.DebugLevel, 50*time.Millisecond)
+	c.Set(zerolog.WarnLevel, time.Hour)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, zerolog.WarnLevel, c.Level())
+}
+
+func TestControllerToggle(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	c := NewController(zerolog.InfoLevel)
+	require.Equal(t, zerolog.DebugLevel, c.Toggle())
+	require.Equal(t, zerolog.DebugLevel, c.Level())
+	require.Equal(t, zerolog.InfoLevel, c.Toggle())
+	require.Equal(t, zerolog.InfoLevel, c.Level())
+
+	// a toggle cancels the revert of an earlier Set
+	c.Set(zerolog.WarnLevel, 50*time.Millisecond)
+	require.Equal(t, zerolog.DebugLevel, c.Toggle())
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, zerolog.DebugLevel, c.Level())
+}
+
+func TestControllerConcurrentUse(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	c := NewController(zerolog.InfoLevel)
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < 100; j++ {
+				c.Set(zerolog.DebugLevel, time.Millisecond)
+				c.Toggle()
+				c.Level()
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	require.Eventually(t, func() bool {
+		level := c.Level()
+		return level == zerolog.InfoLevel || level == zerolog.DebugLevel
+	}, time.Second, 10*time.Millisecond)
+}
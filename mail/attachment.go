@@ -0,0 +1,84 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// ErrAttachmentTooLarge is returned before anything is sent when the attachments of an email
+// are over the limit set with WithAttachmentLimit
+var ErrAttachmentTooLarge = errors.New("attachments too large")
+
+// Attachment is a file sent along with an email. ContentType is guessed from the extension of
+// Name when empty.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentFromFile reads the file at path into an Attachment
+func AttachmentFromFile(path string) (Attachment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	return Attachment{
+		Name: filepath.Base(path),
+		Data: data,
+	}, nil
+}
+
+func (a Attachment) contentType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+
+	if contentType := mime.TypeByExtension(filepath.Ext(a.Name)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+type attachmentLimitSender struct {
+	sender  EmailSender
+	maxSize int64
+}
+
+// WithAttachmentLimit rejects emails whose attachments add up to more than maxSize bytes
+// before handing them to sender. A maxSize of zero or less disables the check.
+func WithAttachmentLimit(sender EmailSender, maxSize int64) EmailSender {
+	if maxSize <= 0 {
+		return sender
+	}
+
+	return &attachmentLimitSender{
+		sender:  sender,
+		maxSize: maxSize,
+	}
+}
+
+func (s *attachmentLimitSender) SendEmail(
+	subject string,
+	content string,
+	textContent string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachments []Attachment,
+) error {
+	var size int64
+	for _, a := range attachments {
+		size += int64(len(a.Data))
+	}
+
+	if size > s.maxSize {
+		return fmt.Errorf("%w: %d bytes attached, the limit is %d", ErrAttachmentTooLarge, size, s.maxSize)
+	}
+
+	return s.sender.SendEmail(subject, content, textContent, to, cc, bcc, attachments)
+}
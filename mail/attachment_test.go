@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingSender counts the emails that made it through to it
+type countingSender struct {
+	sent int
+}
+
+func (s *countingSender) SendEmail(subject, content, textContent string, to, cc, bcc []string, attachments []Attachment) error {
+	s.sent++
+	return nil
+}
+
+func TestWithAttachmentLimit(t *testing.T) {
+	next := &countingSender{}
+	sender := WithAttachmentLimit(next, 10)
+	to := []string{"to@swiftbank.test"}
+
+	err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", to, nil, nil,
+		[]Attachment{{Name: "a.csv", Data: make([]byte, 6)}, {Name: "b.csv", Data: make([]byte, 4)}})
+	require.NoError(t, err)
+	require.Equal(t, 1, next.sent)
+
+	err = sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", to, nil, nil,
+		[]Attachment{{Name: "a.csv", Data: make([]byte, 6)}, {Name: "b.csv", Data: make([]byte, 5)}})
+	require.True(t, errors.Is(err, ErrAttachmentTooLarge))
+	require.ErrorContains(t, err, "11 bytes attached, the limit is 10")
+	require.Equal(t, 1, next.sent)
+}
+
+func TestWithAttachmentLimitDisabled(t *testing.T) {
+	next := &countingSender{}
+	require.Same(t, next, WithAttachmentLimit(next, 0))
+}
+
+func TestAttachmentContentType(t *testing.T) {
+	require.Equal(t, "text/csv", Attachment{Name: "statement.csv", ContentType: "text/csv"}.contentType())
+	require.Equal(t, "application/pdf", Attachment{Name: "statement.pdf"}.contentType())
+	require.Equal(t, "application/octet-stream", Attachment{Name: "statement"}.contentType())
+}
@@ -0,0 +1,84 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+
+	"github.com/jordan-wright/email"
+)
+
+// mail providers that can be picked with the MAIL_PROVIDER config value
+const (
+	ProviderGmail    = "gmail"
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderSES      = "ses"
+)
+
+// ProviderError is returned when a mail provider rejects a message. Code holds the provider's own
+// error code when it sends one.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: status %d: %s: %s", e.Provider, e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Temporary reports whether sending the same message again may succeed
+func (e *ProviderError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// newEmail builds the message shared by every sender
+func newEmail(from, subject, content, textContent string, to, cc, bcc []string, attachments []Attachment) (*email.Email, error) {
+	e := email.NewEmail()
+	e.From = from
+	e.Subject = subject
+	e.HTML = []byte(content)
+	if textContent != "" {
+		e.Text = []byte(textContent)
+	}
+	e.To = to
+	e.Cc = cc
+	e.Bcc = bcc
+
+	for _, a := range attachments {
+		_, err := e.Attach(bytes.NewReader(a.Data), a.Name, a.contentType())
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach file %s: %w", a.Name, err)
+		}
+	}
+
+	return e, nil
+}
+
+// IsPermanent reports whether sending the same email again is bound to fail, e.g. because the
+// recipient does not exist. Network errors and anything not recognised count as transient.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrAttachmentTooLarge) {
+		return true
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return !providerErr.Temporary()
+	}
+
+	// smtp replies, 4xx asks to try again later while 5xx is final
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 500
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPermanent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{name: "UNKNOWN", err: errors.New("connection reset by peer")},
+		{name: "SMTP 421", err: &textproto.Error{Code: 421, Msg: "try again later"}},
+		{name: "SMTP 451", err: &textproto.Error{Code: 451, Msg: "local error"}},
+		{name: "SMTP 550", err: &textproto.Error{Code: 550, Msg: "no such user"}, permanent: true},
+		{name: "SMTP 553", err: &textproto.Error{Code: 553, Msg: "mailbox name not allowed"}, permanent: true},
+		{name: "PROVIDER 429", err: &ProviderError{StatusCode: 429}},
+		{name: "PROVIDER 503", err: &ProviderError{StatusCode: 503}},
+		{name: "PROVIDER 400", err: &ProviderError{StatusCode: 400}, permanent: true},
+		{name: "ATTACHMENT TOO LARGE", err: ErrAttachmentTooLarge, permanent: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.permanent, IsPermanent(fmt.Errorf("failed to send email: %w", tc.err)))
+		})
+	}
+}
@@ -3,8 +3,6 @@ package mail
 import (
 	"fmt"
 	"net/smtp"
-
-	"github.com/jordan-wright/email"
 )
 
 const (
@@ -12,14 +10,17 @@ const (
 	smtpServerAddress = "smtp.gmail.com:587"
 )
 
+// EmailSender sends an HTML email. When textContent is not empty it is sent along as the plain
+// text alternative of the HTML.
 type EmailSender interface {
 	SendEmail(
 		subject string,
 		content string,
+		textContent string,
 		to []string,
 		cc []string,
 		bcc []string,
-		attachFiles []string,
+		attachments []Attachment,
 	) error
 }
 
@@ -40,24 +41,16 @@ func NewGmailSender(name, fromEmailAddress, fromEmailPassword string) EmailSende
 func (g *GmailSender) SendEmail(
 	subject string,
 	content string,
+	textContent string,
 	to []string,
 	cc []string,
 	bcc []string,
-	attachFiles []string,
+	attachments []Attachment,
 ) error {
-	e := email.NewEmail()
-	e.From = fmt.Sprintf("%s <%s>", g.name, g.fromEmailAddress)
-	e.Subject = subject
-	e.HTML = []byte(content)
-	e.To = to
-	e.Cc = cc
-	e.Bcc = bcc
-
-	for _, f := range attachFiles {
-		_, err := e.AttachFile(f)
-		if err != nil {
-			return fmt.Errorf("failed to attach file %s: %w", f, err)
-		}
+	from := fmt.Sprintf("%s <%s>", g.name, g.fromEmailAddress)
+	e, err := newEmail(from, subject, content, textContent, to, cc, bcc, attachments)
+	if err != nil {
+		return err
 	}
 
 	smtpAuth := smtp.PlainAuth("", g.fromEmailAddress, g.fromEmailPassword, smtpAuthAddress)
@@ -22,8 +22,9 @@ func TestSendEmail(t *testing.T) {
 	<p>This is a test message from <a href="https://github.com/zde37">ZDE</a></p>
 	`
 	to := []string{""} // provide an email address to send to
-	attachFiles := []string{"../README.md"}
+	readme, err := AttachmentFromFile("../README.md")
+	require.NoError(t, err)
 
-	err = sender.SendEmail(subject, content, to, nil, nil, attachFiles)
+	err = sender.SendEmail(subject, content, "", to, nil, nil, []Attachment{readme})
 	require.NoError(t, err)
 }
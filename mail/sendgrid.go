@@ -0,0 +1,154 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const sendGridBaseURL = "https://api.sendgrid.com"
+
+// SendGridSender sends emails through the SendGrid v3 mail send API
+type SendGridSender struct {
+	baseURL          string
+	name             string
+	fromEmailAddress string
+	apiKey           string
+	httpClient       *http.Client
+}
+
+func NewSendGridSender(name, fromEmailAddress, apiKey string) EmailSender {
+	return &SendGridSender{
+		baseURL:          sendGridBaseURL,
+		name:             name,
+		fromEmailAddress: fromEmailAddress,
+		apiKey:           apiKey,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	Cc  []sendGridAddress `json:"cc,omitempty"`
+	Bcc []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Type     string `json:"type"`
+	Filename string `json:"filename"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+type sendGridErrorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Field   string `json:"field"`
+	} `json:"errors"`
+}
+
+func (s *SendGridSender) SendEmail(
+	subject string,
+	content string,
+	textContent string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachments []Attachment,
+) error {
+	message := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{
+			To:  sendGridAddresses(to),
+			Cc:  sendGridAddresses(cc),
+			Bcc: sendGridAddresses(bcc),
+		}},
+		From:    sendGridAddress{Email: s.fromEmailAddress, Name: s.name},
+		Subject: subject,
+	}
+	// SendGrid wants the plain text part before the html one
+	if textContent != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/plain", Value: textContent})
+	}
+	message.Content = append(message.Content, sendGridContent{Type: "text/html", Value: content})
+
+	for _, a := range attachments {
+		message.Attachments = append(message.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(a.Data),
+			Type:     a.contentType(),
+			Filename: a.Name,
+		})
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return nil
+	}
+
+	providerErr := &ProviderError{Provider: ProviderSendGrid, StatusCode: rsp.StatusCode, Message: http.StatusText(rsp.StatusCode)}
+	var errRsp sendGridErrorResponse
+	if data, err := io.ReadAll(rsp.Body); err == nil && json.Unmarshal(data, &errRsp) == nil && len(errRsp.Errors) > 0 {
+		messages := make([]string, 0, len(errRsp.Errors))
+		for _, e := range errRsp.Errors {
+			if e.Field != "" {
+				messages = append(messages, e.Field+": "+e.Message)
+				continue
+			}
+			messages = append(messages, e.Message)
+		}
+		providerErr.Message = strings.Join(messages, "; ")
+	}
+
+	return fmt.Errorf("failed to send email: %w", providerErr)
+}
+
+func sendGridAddresses(emails []string) []sendGridAddress {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	addresses := make([]sendGridAddress, len(emails))
+	for i, e := range emails {
+		addresses[i] = sendGridAddress{Email: e}
+	}
+	return addresses
+}
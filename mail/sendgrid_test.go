@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSendGridSender(t *testing.T, handler http.HandlerFunc) *SendGridSender {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	sender := NewSendGridSender("Swift Bank", "no-reply@swiftbank.test", "test-key").(*SendGridSender)
+	sender.baseURL = server.URL
+	return sender
+}
+
+func TestSendGridSenderSendEmail(t *testing.T) {
+	var got sendGridMessage
+	sender := newTestSendGridSender(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v3/mail/send", r.URL.Path)
+		require.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"},
+		[]string{"cc@swiftbank.test"}, []string{"bcc@swiftbank.test"}, []Attachment{{Name: "README.md", Data: []byte("# Swift Bank")}})
+	require.NoError(t, err)
+
+	require.Equal(t, "no-reply@swiftbank.test", got.From.Email)
+	require.Equal(t, "Swift Bank", got.From.Name)
+	require.Equal(t, "A test email", got.Subject)
+	require.Equal(t, []sendGridContent{
+		{Type: "text/plain", Value: "Hello"},
+		{Type: "text/html", Value: "<h1>Hello</h1>"},
+	}, got.Content)
+	require.Len(t, got.Personalizations, 1)
+	require.Equal(t, []sendGridAddress{{Email: "to@swiftbank.test"}}, got.Personalizations[0].To)
+	require.Equal(t, []sendGridAddress{{Email: "cc@swiftbank.test"}}, got.Personalizations[0].Cc)
+	require.Equal(t, []sendGridAddress{{Email: "bcc@swiftbank.test"}}, got.Personalizations[0].Bcc)
+
+	require.Len(t, got.Attachments, 1)
+	require.Equal(t, "README.md", got.Attachments[0].Filename)
+	require.Equal(t, "text/markdown; charset=utf-8", got.Attachments[0].Type)
+	data, err := base64.StdEncoding.DecodeString(got.Attachments[0].Content)
+	require.NoError(t, err)
+	require.Equal(t, "# Swift Bank", string(data))
+}
+
+func TestSendGridSenderProviderError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		message   string
+		temporary bool
+	}{
+		{
+			name:    "BAD REQUEST",
+			status:  http.StatusBadRequest,
+			body:    `{"errors":[{"message":"Does not contain a valid address.","field":"personalizations.0.to.0.email"}]}`,
+			message: "personalizations.0.to.0.email: Does not contain a valid address.",
+		},
+		{
+			name:      "RATE LIMITED",
+			status:    http.StatusTooManyRequests,
+			body:      `{"errors":[{"message":"too many requests"}]}`,
+			message:   "too many requests",
+			temporary: true,
+		},
+		{
+			name:      "UNAVAILABLE",
+			status:    http.StatusServiceUnavailable,
+			message:   "Service Unavailable",
+			temporary: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			sender := newTestSendGridSender(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+
+			err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"}, nil, nil, nil)
+			require.Error(t, err)
+
+			var providerErr *ProviderError
+			require.True(t, errors.As(err, &providerErr))
+			require.Equal(t, ProviderSendGrid, providerErr.Provider)
+			require.Equal(t, tc.status, providerErr.StatusCode)
+			require.Equal(t, tc.message, providerErr.Message)
+			require.Equal(t, tc.temporary, providerErr.Temporary())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SESCredentials are the AWS keys used to sign requests to SES
+type SESCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// SESCredentialsProvider returns the keys to sign the next request with, so rotated keys are picked up
+type SESCredentialsProvider interface {
+	Retrieve(ctx context.Context) (SESCredentials, error)
+}
+
+// StaticSESCredentials always returns the same keys
+type StaticSESCredentials SESCredentials
+
+func (c StaticSESCredentials) Retrieve(ctx context.Context) (SESCredentials, error) {
+	return SESCredentials(c), nil
+}
+
+// SESSender sends emails through the Amazon SES v2 API as raw MIME messages
+type SESSender struct {
+	baseURL          string
+	region           string
+	name             string
+	fromEmailAddress string
+	credentials      SESCredentialsProvider
+	httpClient       *http.Client
+	now              func() time.Time
+}
+
+func NewSESSender(name, fromEmailAddress, region string, credentials SESCredentialsProvider) EmailSender {
+	return &SESSender{
+		baseURL:          fmt.Sprintf("https://email.%s.amazonaws.com", region),
+		region:           region,
+		name:             name,
+		fromEmailAddress: fromEmailAddress,
+		credentials:      credentials,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		now:              time.Now,
+	}
+}
+
+type sesDestination struct {
+	ToAddresses  []string `json:"ToAddresses,omitempty"`
+	CcAddresses  []string `json:"CcAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string         `json:"FromEmailAddress"`
+	Destination      sesDestination `json:"Destination"`
+	Content          struct {
+		Raw struct {
+			Data []byte `json:"Data"` // encoded as base64 by encoding/json
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+type sesErrorResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *SESSender) SendEmail(
+	subject string,
+	content string,
+	textContent string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachments []Attachment,
+) error {
+	from := fmt.Sprintf("%s <%s>", s.name, s.fromEmailAddress)
+	e, err := newEmail(from, subject, content, textContent, to, cc, bcc, attachments)
+	if err != nil {
+		return err
+	}
+
+	raw, err := e.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	var message sesSendEmailRequest
+	message.FromEmailAddress = from
+	message.Destination = sesDestination{ToAddresses: to, CcAddresses: cc, BccAddresses: bcc}
+	message.Content.Raw.Data = raw
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	ctx := context.Background()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	credentials, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve ses credentials: %w", err)
+	}
+	signSESRequest(req, body, credentials, s.region, s.now().UTC())
+
+	rsp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return nil
+	}
+
+	providerErr := &ProviderError{
+		Provider:   ProviderSES,
+		StatusCode: rsp.StatusCode,
+		Code:       sesErrorCode(rsp.Header.Get("X-Amzn-ErrorType")),
+		Message:    http.StatusText(rsp.StatusCode),
+	}
+	var errRsp sesErrorResponse
+	if data, err := io.ReadAll(rsp.Body); err == nil && json.Unmarshal(data, &errRsp) == nil && errRsp.Message != "" {
+		providerErr.Message = errRsp.Message
+	}
+
+	return fmt.Errorf("failed to send email: %w", providerErr)
+}
+
+// sesErrorCode strips the extra details AWS appends to the error type, e.g. "MessageRejected:http://..."
+func sesErrorCode(errorType string) string {
+	code, _, _ := strings.Cut(errorType, ":")
+	return code
+}
+
+// signSESRequest adds an AWS signature version 4 to req
+func signSESRequest(req *http.Request, body []byte, credentials SESCredentials, region string, now time.Time) {
+	const service = "ses"
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	signedHeaders := []string{"content-type", "host", "x-amz-date"}
+	if credentials.SessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	// Encode sorts by key, AWS also wants spaces as %20
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSESSender(t *testing.T, handler http.HandlerFunc) *SESSender {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	credentials := StaticSESCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+	sender := NewSESSender("Swift Bank", "no-reply@swiftbank.test", "us-east-1", credentials).(*SESSender)
+	sender.baseURL = server.URL
+	sender.now = func() time.Time { return time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC) }
+	return sender
+}
+
+func TestSESSenderSendEmail(t *testing.T) {
+	var got sesSendEmailRequest
+	sender := newTestSESSender(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		require.Equal(t, "20240305T143000Z", r.Header.Get("X-Amz-Date"))
+		require.Equal(t, "token", r.Header.Get("X-Amz-Security-Token"))
+
+		authorization := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(authorization,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240305/us-east-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature="))
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"MessageId":"0100018e"}`))
+	})
+
+	err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"},
+		[]string{"cc@swiftbank.test"}, []string{"bcc@swiftbank.test"}, []Attachment{{Name: "README.md", Data: []byte("# Swift Bank")}})
+	require.NoError(t, err)
+
+	require.Equal(t, "Swift Bank <no-reply@swiftbank.test>", got.FromEmailAddress)
+	require.Equal(t, []string{"to@swiftbank.test"}, got.Destination.ToAddresses)
+	require.Equal(t, []string{"cc@swiftbank.test"}, got.Destination.CcAddresses)
+	require.Equal(t, []string{"bcc@swiftbank.test"}, got.Destination.BccAddresses)
+
+	raw := string(got.Content.Raw.Data)
+	require.Contains(t, raw, "Subject: A test email")
+	require.Contains(t, raw, "Cc: <cc@swiftbank.test>")
+	require.Contains(t, raw, "multipart/alternative")
+	require.Contains(t, raw, "Content-Type: text/plain")
+	require.Contains(t, raw, "Content-Type: text/html")
+	require.Contains(t, raw, `filename="README.md"`)
+	require.NotContains(t, raw, "bcc@swiftbank.test")
+}
+
+func TestSESSenderProviderError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		errorType string
+		code      string
+		temporary bool
+	}{
+		{
+			name:      "MESSAGE REJECTED",
+			status:    http.StatusBadRequest,
+			errorType: "MessageRejected:http://internal.amazon.com/coral/com.amazonaws.sesv2/",
+			code:      "MessageRejected",
+		},
+		{
+			name:      "THROTTLED",
+			status:    http.StatusTooManyRequests,
+			errorType: "TooManyRequestsException",
+			code:      "TooManyRequestsException",
+			temporary: true,
+		},
+		{
+			name:      "INTERNAL",
+			status:    http.StatusInternalServerError,
+			errorType: "InternalFailure",
+			code:      "InternalFailure",
+			temporary: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			sender := newTestSESSender(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Amzn-ErrorType", tc.errorType)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"message":"something went wrong"}`))
+			})
+
+			err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"}, nil, nil, nil)
+			require.Error(t, err)
+
+			var providerErr *ProviderError
+			require.True(t, errors.As(err, &providerErr))
+			require.Equal(t, ProviderSES, providerErr.Provider)
+			require.Equal(t, tc.code, providerErr.Code)
+			require.Equal(t, "something went wrong", providerErr.Message)
+			require.Equal(t, tc.temporary, providerErr.Temporary())
+		})
+	}
+}
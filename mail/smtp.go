@@ -0,0 +1,144 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// DefaultSMTPTimeout bounds the whole exchange with the smtp server, dial included
+const DefaultSMTPTimeout = 10 * time.Second
+
+// SMTPSender sends emails through any smtp server. With useTLS the connection is encrypted from
+// the start (usually port 465), otherwise STARTTLS is used whenever the server offers it.
+type SMTPSender struct {
+	name             string
+	fromEmailAddress string
+	host             string
+	port             int
+	username         string
+	password         string
+	useTLS           bool
+	timeout          time.Duration
+	tlsConfig        *tls.Config
+}
+
+func NewSMTPSender(name, fromEmailAddress, host string, port int, username, password string, useTLS bool, timeout time.Duration) EmailSender {
+	if timeout <= 0 {
+		timeout = DefaultSMTPTimeout
+	}
+
+	return &SMTPSender{
+		name:             name,
+		fromEmailAddress: fromEmailAddress,
+		host:             host,
+		port:             port,
+		username:         username,
+		password:         password,
+		useTLS:           useTLS,
+		timeout:          timeout,
+		tlsConfig:        &tls.Config{ServerName: host},
+	}
+}
+
+func (s *SMTPSender) SendEmail(
+	subject string,
+	content string,
+	textContent string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachments []Attachment,
+) error {
+	from := fmt.Sprintf("%s <%s>", s.name, s.fromEmailAddress)
+	e, err := newEmail(from, subject, content, textContent, to, cc, bcc, attachments)
+	if err != nil {
+		return err
+	}
+
+	message, err := e.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if !s.useTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(s.tlsConfig); err != nil {
+				return fmt.Errorf("failed to start tls: %w", err)
+			}
+		}
+	}
+
+	// relays such as MailHog accept mail without authentication
+	if s.username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+				return fmt.Errorf("failed to authenticate: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(s.fromEmailAddress); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+
+	for _, rcpt := range append(append(append([]string{}, to...), cc...), bcc...) {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTPSender) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.useTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	// a server that stops responding must not hold the worker forever
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,131 @@
+package mail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one session and records the commands it received
+func fakeSMTPServer(t *testing.T) (host string, port int, commands <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session []string
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP fake")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			session = append(session, line)
+
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				reply("235 2.7.0 Authentication successful")
+			case "MAIL", "RCPT":
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var message strings.Builder
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					message.WriteString(data)
+				}
+				session = append(session, message.String())
+				reply("250 OK queued")
+			case "QUIT":
+				reply("221 Bye")
+				received <- session
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+		received <- session
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestSMTPSenderSendEmail(t *testing.T) {
+	host, port, commands := fakeSMTPServer(t)
+
+	sender := NewSMTPSender("Swift Bank", "no-reply@swiftbank.test", host, port, "relay-user", "relay-pass", false, time.Second)
+	err := sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"},
+		[]string{"cc@swiftbank.test"}, []string{"bcc@swiftbank.test"},
+		[]Attachment{{Name: "statement.csv", ContentType: "text/csv", Data: []byte("date,amount\n2024-03-05,10.00\n")}})
+	require.NoError(t, err)
+
+	session := <-commands
+	require.Len(t, session, 9)
+	require.True(t, strings.HasPrefix(session[0], "EHLO "))
+
+	require.True(t, strings.HasPrefix(session[1], "AUTH PLAIN "))
+	auth, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(session[1], "AUTH PLAIN "))
+	require.NoError(t, err)
+	require.Equal(t, "\x00relay-user\x00relay-pass", string(auth))
+
+	require.Equal(t, "MAIL FROM:<no-reply@swiftbank.test>", session[2])
+	require.Equal(t, "RCPT TO:<to@swiftbank.test>", session[3])
+	require.Equal(t, "RCPT TO:<cc@swiftbank.test>", session[4])
+	require.Equal(t, "RCPT TO:<bcc@swiftbank.test>", session[5])
+	require.Equal(t, "DATA", session[6])
+	require.Equal(t, "QUIT", session[8])
+
+	message := session[7]
+	require.Contains(t, message, "Content-Type: multipart/mixed")
+	require.Contains(t, message, "Content-Type: multipart/alternative")
+	require.Contains(t, message, "Content-Type: text/csv")
+	require.Contains(t, message, `Content-Disposition: attachment;`)
+	require.Contains(t, message, `filename="statement.csv"`)
+	require.Contains(t, message, base64.StdEncoding.EncodeToString([]byte("date,amount\n2024-03-05,10.00\n")))
+}
+
+func TestSMTPSenderTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// accept the connection but never send the greeting
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	sender := NewSMTPSender("Swift Bank", "no-reply@swiftbank.test", addr.IP.String(), addr.Port, "", "", false, 100*time.Millisecond)
+
+	start := time.Now()
+	err = sender.SendEmail("A test email", "<h1>Hello</h1>", "Hello", []string{"to@swiftbank.test"}, nil, nil, nil)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,135 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// names of the embedded email templates
+const (
+	TemplateVerifyEmail            = "verify_email"
+	TemplatePasswordReset          = "password_reset"
+	TemplateTransferAlert          = "transfer_alert"
+	TemplateStatementReady         = "statement_ready"
+	TemplateExportReady            = "transaction_export_ready"
+	TemplateStatementPDF           = "statement_pdf_ready"
+	TemplateStandingOrderSuspended = "standing_order_suspended"
+)
+
+// VerifyEmailData is the data of TemplateVerifyEmail
+type VerifyEmailData struct {
+	FullName  string
+	VerifyURL string
+}
+
+// PasswordResetData is the data of TemplatePasswordReset
+type PasswordResetData struct {
+	FullName  string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// TransferAlertData is the data of TemplateTransferAlert
+type TransferAlertData struct {
+	FullName      string
+	Amount        string // a decimal, like "10.50"
+	Currency      string
+	FromAccountID int64
+	RecipientName string
+	ToAccountID   int64
+	SentAt        string
+	Description   string // the memo of the transfer, may be empty
+}
+
+// StatementReadyData is the data of TemplateStatementReady
+type StatementReadyData struct {
+	FullName  string
+	AccountID int64
+	Period    string
+}
+
+// ExportReadyData is the data of TemplateExportReady
+type ExportReadyData struct {
+	FullName  string
+	AccountID int64
+	From      string
+	To        string
+}
+
+// StatementPDFData is the data of TemplateStatementPDF
+type StatementPDFData struct {
+	FullName    string
+	AccountID   int64
+	Period      string
+	DownloadURL string
+	ExpiresAt   string
+}
+
+// StandingOrderSuspendedData is the data of TemplateStandingOrderSuspended
+type StandingOrderSuspendedData struct {
+	FullName      string
+	Frequency     string
+	Amount        string // a decimal, like "10.50"
+	Currency      string
+	FromAccountID int64
+	ToAccountID   int64
+	Failures      int32 // the runs in a row that failed
+}
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// every template file defines "subject", "html" and "text". The html part is parsed with
+// html/template so values are escaped, the subject and text parts with text/template so they are not.
+var (
+	htmlTemplates = map[string]*htmltemplate.Template{}
+	textTemplates = map[string]*texttemplate.Template{}
+)
+
+func init() {
+	files, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".html")
+		htmlTemplates[name] = htmltemplate.Must(htmltemplate.New(name).Option("missingkey=error").ParseFS(templateFS, file))
+		textTemplates[name] = texttemplate.Must(texttemplate.New(name).Option("missingkey=error").ParseFS(templateFS, file))
+	}
+}
+
+// RenderTemplate renders the subject and the html and plain text bodies of the named template
+func RenderTemplate(name string, data any) (subject, htmlBody, textBody string, err error) {
+	htmlTmpl, ok := htmlTemplates[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template %q", name)
+	}
+	textTmpl := textTemplates[name]
+
+	var buf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := htmlTmpl.ExecuteTemplate(&buf, "html", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s html: %w", name, err)
+	}
+	htmlBody = buf.String()
+
+	buf.Reset()
+	if err := textTmpl.ExecuteTemplate(&buf, "text", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+	textBody = buf.String()
+
+	return subject, htmlBody, textBody, nil
+}
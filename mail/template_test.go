@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// templateTestData holds the data every embedded template is rendered with
+var templateTestData = map[string]any{
+	TemplateVerifyEmail: VerifyEmailData{
+		FullName:  "Ada O'Brien",
+		VerifyURL: "http://localhost:8080/v1/verify_email?email_id=1&secret_code=abc",
+	},
+	TemplatePasswordReset: PasswordResetData{
+		FullName:  "Ada O'Brien",
+		ResetURL:  "http://localhost:8080/reset?token=abc",
+		ExpiresIn: "15 minutes",
+	},
+	TemplateTransferAlert: TransferAlertData{
+		FullName:      "Ada O'Brien",
+		Amount:        "25000.00",
+		Currency:      "USD",
+		FromAccountID: 1,
+		RecipientName: "Charles Babbage",
+		ToAccountID:   2,
+		SentAt:        "Tue, 05 Mar 2024 14:30:00 UTC",
+	},
+	TemplateStatementReady: StatementReadyData{
+		FullName:  "Ada O'Brien",
+		AccountID: 1,
+		Period:    "March 2024",
+	},
+	TemplateExportReady: ExportReadyData{
+		FullName:  "Ada O'Brien",
+		AccountID: 1,
+		From:      "2024-01-01",
+		To:        "2024-03-31",
+	},
+	TemplateStatementPDF: StatementPDFData{
+		FullName:    "Ada O'Brien",
+		AccountID:   1,
+		Period:      "March 2024",
+		DownloadURL: "https://bank.example/v1/files/statements/1/2024-03.pdf?expires=1&signature=a",
+		ExpiresAt:   "Tue, 02 Apr 2024 14:30:00 UTC",
+	},
+	TemplateStandingOrderSuspended: StandingOrderSuspendedData{
+		FullName:      "Ada O'Brien",
+		Frequency:     "monthly",
+		Amount:        "120.00",
+		Currency:      "USD",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Failures:      3,
+	},
+}
+
+func TestRenderEveryTemplate(t *testing.T) {
+	for name := range htmlTemplates {
+		_, ok := templateTestData[name]
+		require.True(t, ok, "template %s has no test data", name)
+	}
+
+	for name, data := range templateTestData {
+		t.Run(name, func(t *testing.T) {
+			subject, htmlBody, textBody, err := RenderTemplate(name, data)
+			require.NoError(t, err)
+			require.NotEmpty(t, subject)
+			require.NotEmpty(t, htmlBody)
+			require.NotEmpty(t, textBody)
+
+			// only the html part is escaped
+			require.Contains(t, htmlBody, "O&#39;Brien")
+			require.NotContains(t, textBody, "&#39;")
+			require.NotContains(t, textBody, "<p>")
+		})
+	}
+}
+
+func TestRenderTemplateTransferAlert(t *testing.T) {
+	subject, htmlBody, textBody, err := RenderTemplate(TemplateTransferAlert, templateTestData[TemplateTransferAlert])
+	require.NoError(t, err)
+	require.Equal(t, "Large transfer from your account", subject)
+	require.Contains(t, htmlBody, "25000.00 USD")
+	require.Equal(t, "A transfer of 25000.00 USD was sent from your account 1 to Charles Babbage (account 2) on Tue, 05 Mar 2024 14:30:00 UTC.", textBody)
+	require.NotContains(t, htmlBody, "Memo")
+
+	data := templateTestData[TemplateTransferAlert].(TransferAlertData)
+	data.Description = "deposit for <b>the</b> flat"
+	_, htmlBody, textBody, err = RenderTemplate(TemplateTransferAlert, data)
+	require.NoError(t, err)
+	require.Contains(t, htmlBody, "<p>Memo: deposit for &lt;b&gt;the&lt;/b&gt; flat</p>")
+	require.True(t, strings.HasSuffix(textBody, " Memo: deposit for <b>the</b> flat"))
+}
+
+func TestRenderTemplateUnknownName(t *testing.T) {
+	_, _, _, err := RenderTemplate("does_not_exist", VerifyEmailData{})
+	require.ErrorContains(t, err, "unknown email template")
+}
+
+func TestRenderTemplateMissingField(t *testing.T) {
+	// the verify email template needs VerifyURL, which the transfer alert data does not have
+	_, _, _, err := RenderTemplate(TemplateVerifyEmail, templateTestData[TemplateTransferAlert])
+	require.Error(t, err)
+
+	_, _, _, err = RenderTemplate(TemplateVerifyEmail, map[string]any{"FullName": "Ada"})
+	require.Error(t, err)
+}
@@ -2,175 +2,992 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/hibiken/asynq"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/rakyll/statik/fs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/zde37/Swift_Bank/api"
+	"github.com/zde37/Swift_Bank/audit"
+	"github.com/zde37/Swift_Bank/breach"
 	"github.com/zde37/Swift_Bank/config"
+	"github.com/zde37/Swift_Bank/currencies"
 	"github.com/zde37/Swift_Bank/database"
-	_ "github.com/zde37/Swift_Bank/doc/statik"
+	"github.com/zde37/Swift_Bank/doc"
+	"github.com/zde37/Swift_Bank/events"
 	"github.com/zde37/Swift_Bank/gapi"
+	"github.com/zde37/Swift_Bank/health"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/listen"
+	"github.com/zde37/Swift_Bank/loglevel"
 	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/notify"
 	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/ratelimit"
+	"github.com/zde37/Swift_Bank/rates"
+	"github.com/zde37/Swift_Bank/redisconn"
 	"github.com/zde37/Swift_Bank/repository"
 	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/storage"
+	"github.com/zde37/Swift_Bank/tlscert"
+	"github.com/zde37/Swift_Bank/token"
+	"github.com/zde37/Swift_Bank/tracing"
 	"github.com/zde37/Swift_Bank/worker"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// set at build time with -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+// startedAt is when the process started, ServerInfo reports the uptime from it
+var startedAt = time.Now()
+
 func main() {
-	// load config
-	config, err := config.LoadConfig(".")
+	command, err := parseCommand(os.Args[1:])
+	if errors.Is(err, errHelp) {
+		fmt.Fprint(os.Stdout, usage)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, usage)
+		os.Exit(2)
+	}
+
+	config := loadConfig()
+	logLevels := setupLogger(config)
+
+	if command.migrate != nil {
+		if err := runMigrateCommand(config, command.migrate, os.Stdout); err != nil {
+			log.Fatal().Err(err).Msg("migrate failed")
+		}
+		return
+	}
+	mode := command.mode
+	log.Info().Str("mode", mode.name).Msg("starting")
+
+	// interrupts cancel ctx, which drains the servers and workers before main returns
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go toggleLogLevel(ctx, logLevels)
+
+	// the tracer provider has to be in place before the database pool and servers pick it up
+	flushTracing := setupTracing(ctx, config)
+	defer flushTracing()
+
+	secrets := config.Secrets()
+	db, replicaDB, replica := openDatabases(ctx, config, secrets)
+	defer db.Close()
+	if replicaDB != nil {
+		defer replicaDB.Close()
+	}
+
+	// the worker leaves migrating to the API, two modes racing for the lock gain nothing
+	switch {
+	case !mode.api:
+	case config.SkipMigrations:
+		log.Info().Msg("skipping DB migration, it is left to a separate job")
+	default:
+		if err := database.Migrate(config.MigrationURL, config.Dsn, config.MigrationForceDirty); err != nil {
+			log.Fatal().Err(err).Msg("failed to migrate DB")
+		}
+	}
+
+	redisClient, redisOpt := newRedis(ctx, config, secrets)
+	defer redisClient.Close()
+
+	// redis pub/sub carries live account activity between replicas
+	broker := events.NewRedisBroker(redisClient)
+	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+
+	// the worker, request and database metrics are served together, on the metrics server and the gateway
+	registry := prometheus.NewRegistry()
+	db.RegisterMetrics(registry, "primary")
+	if replicaDB != nil {
+		replicaDB.RegisterMetrics(registry, "replica")
+	}
+
+	repository := repository.NewRepository(db, registry, replica, config.TransferAdvisoryLocks)
+	rateProvider := rates.NewStoreProvider(repository.R, config.ExchangeRateMaxAge)
+
+	// the currencies table decides what the thresholds below and every request may use
+	currencyCache := currencies.NewCache(repository.R)
+	if err := currencyCache.Refresh(ctx); err != nil {
+		log.Fatal().Err(err).Msg("cannot load currencies")
+	}
+
+	webhookDispatcher := worker.NewWebhookDispatcher(taskDistributor, config.WebhookMaxRetry)
+	alertThresholds, err := helpers.ParseCurrencyAmounts(config.LargeTransferThresholds)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid large transfer thresholds")
+	}
+	transferAlerter := worker.NewTransferAlertDispatcher(taskDistributor, alertThresholds)
+	service := service.NewService(repository.R, broker, rateProvider, webhookDispatcher, transferAlerter, newEntityCache(config, redisClient, registry), config.SavingsTransferLimit)
+
+	limits, err := ratelimit.ParseLimits(config.RateLimitDefault, config.RateLimits)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid rate limits")
+	}
+	tokenMaker, err := token.NewRotatingMaker(config.TokenSymmetricKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create token maker")
+	}
+	rateLimiter := gapi.NewRateLimiter(tokenMaker, ratelimit.NewRedisLimiter(redisClient), limits, config.BehindProxy)
+	go reloadSecrets(ctx, secrets, tokenMaker)
+
+	timeouts, err := gapi.ParseTimeouts(config.RPCTimeout, config.RPCTimeouts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid RPC timeouts")
+	}
+
+	grpcLimits := gapi.ServerLimits{
+		MaxRecvMsgSize:       config.GrpcMaxRecvMsgSize,
+		MaxSendMsgSize:       config.GrpcMaxSendMsgSize,
+		MaxConnectionIdle:    config.GrpcMaxConnectionIdle,
+		KeepaliveTime:        config.GrpcKeepaliveTime,
+		KeepaliveTimeout:     config.GrpcKeepaliveTimeout,
+		MaxConcurrentStreams: config.GrpcMaxConcurrentStreams,
+	}
+	if err := grpcLimits.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid gRPC server limits")
+	}
+
+	pingers := map[string]health.Pinger{
+		"postgres": db,
+		"redis": health.PingerFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+	}
+	if replicaDB != nil {
+		pingers["postgres_replica"] = health.Optional(replicaDB)
+	}
+
+	// statements are written by the worker and downloaded through the gateway
+	files, err := storage.NewFileStore(config.StorageDir, config.StorageBaseURL, config.TokenSymmetricKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create file store")
+	}
+
+	// the deferred closes run once everything using the pool and redis has stopped
+	if err := runServers(ctx, mode, config, registry, redisOpt, repository.R, service.S, tokenMaker, taskDistributor, broker, pingers, rateLimiter, timeouts, grpcLimits, files, currencyCache, logLevels); err != nil {
+		log.Error().Err(err).Msg("server stopped with an error")
+	}
+
+	// runGinServer(config, db)
+}
+
+// loadConfig loads the config from app.env and the environment, and refuses to go on with an
+// invalid one
+func loadConfig() config.Config {
+	c, err := config.LoadConfig(".")
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load config")
 	}
+	if err := c.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
 
+	return c
+}
+
+// setupLogger sets the global logger up for the environment and returns what changes its level
+func setupLogger(config config.Config) *loglevel.Controller {
 	if config.Environment == "development" {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
-	// Load database
-	ctx := context.Background()
-	PostgresClient := database.PostgresClient{}
-	pool, err := PostgresClient.NewPostgresClient(ctx, config.Dsn)
+	logLevel := zerolog.InfoLevel
+	if config.LogLevel != "" {
+		var err error
+		logLevel, err = zerolog.ParseLevel(config.LogLevel)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid log level")
+		}
+	}
+
+	return loglevel.NewController(logLevel)
+}
+
+// setupTracing installs the global tracer provider, and returns what exports the spans left
+// when the process stops
+func setupTracing(ctx context.Context, config config.Config) func() {
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Endpoint:    config.OTLPEndpoint,
+		Insecure:    config.OTLPInsecure,
+		SampleRatio: config.TraceSampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create new postgres client")
+		log.Fatal().Err(err).Msg("failed to set up tracing")
+	}
+
+	return func() {
+		// ctx is cancelled by now, so the remaining spans get a fresh deadline to be exported
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
+	}
+}
+
+// openDatabases connects to the primary, and to the read replica when there is one. The replica
+// only takes reads, the service runs on the primary alone while it is down.
+func openDatabases(ctx context.Context, config config.Config, secrets *config.Secrets) (*database.Client, *database.Client, *repository.Replica) {
+	dbLogLevel, err := zerolog.ParseLevel(config.DBLogLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid database log level")
 	}
 
-	defer pool.Close()
+	poolConfig := database.PoolConfig{
+		MaxConns:          config.DBMaxConns,
+		MinConns:          config.DBMinConns,
+		MaxConnLifetime:   config.DBMaxConnLifetime,
+		MaxConnIdleTime:   config.DBMaxConnIdleTime,
+		HealthCheckPeriod: config.DBHealthCheckPeriod,
+	}
+	if err := poolConfig.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid database pool config")
+	}
 
-	if err = PostgresClient.PingDB(ctx); err != nil {
+	slowQueryTracer, queryLogger := repository.NewSlowQueryTracer(config.SlowQueryThreshold), repository.NewQueryLogger(dbLogLevel)
+	primaryPoolConfig := poolConfig
+	if config.DsnFile != "" {
+		primaryPoolConfig.CurrentDSN = secrets.Dsn
+	}
+	db, err := database.NewPostgresClient(ctx, config.Dsn, primaryPoolConfig, slowQueryTracer, queryLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create new postgres client")
+	}
+
+	if err = db.Ping(ctx); err != nil {
 		log.Fatal().Err(err).Msg("failed to ping DB")
 	}
 
-	runDBMigration(config.MigrationURL, config.Dsn)
+	if config.ReplicaDsn == "" {
+		return db, nil, nil
+	}
 
-	repository := repository.NewRepository(pool)
-	service := service.NewService(repository.R)
+	replicaDB, err := database.NewPostgresClient(ctx, config.ReplicaDsn, poolConfig, slowQueryTracer, queryLogger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create read replica client")
+	}
 
-	redisOpt := asynq.RedisClientOpt{
-		Addr: config.RedisAddress,
+	if err := replicaDB.Ping(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to ping read replica")
 	}
 
-	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+	return db, replicaDB, &repository.Replica{DB: replicaDB, ReadYourWritesWindow: config.ReadYourWritesWindow}
+}
 
-	go runTaskProcessor(redisOpt, repository.R, config)
-	go runGatewayServer(config, service.S, taskDistributor)
-	runGrpcServer(config, service.S, taskDistributor)
+// redisOptions is how config reaches redis, directly or through sentinel
+func redisOptions(config config.Config) redisconn.Options {
+	return redisconn.Options{
+		Address:           config.RedisAddress,
+		Password:          config.RedisPassword,
+		DB:                config.RedisDB,
+		TLS:               config.RedisTLSEnabled,
+		CAFile:            config.RedisCAFile,
+		SentinelMaster:    config.RedisSentinelMaster,
+		SentinelAddresses: redisconn.SplitAddresses(config.RedisSentinelAddresses),
+	}
+}
 
-	// runGinServer(config, pool)
+// newEntityCache caches users and accounts in redis, nil when ENTITY_CACHE_TTL turns it off
+func newEntityCache(config config.Config, redisClient *redis.Client, registry prometheus.Registerer) *service.EntityCache {
+	if config.EntityCacheTTL <= 0 {
+		return nil
+	}
+	return service.NewEntityCache(service.NewRedisCache(redisClient), config.EntityCacheTTL, registry)
 }
 
-func runDBMigration(migrationURL, dbSource string) {
-	migration, err := migrate.New(migrationURL, dbSource)
+// newRedis returns the redis client and the options asynq connects with, both built from the
+// same options. Redis is pinged, so it being out of reach stops the start. asynq takes a fixed
+// password, the worker and scheduler need a restart to use a rotated one.
+func newRedis(ctx context.Context, config config.Config, secrets *config.Secrets) (*redis.Client, asynq.RedisConnOpt) {
+	options := redisOptions(config)
+	redisOpt, err := options.AsynqOpt()
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create new migrate instance")
+		log.Fatal().Err(err).Msg("invalid redis options")
+	}
+	if err := redisconn.Ping(ctx, redisOpt); err != nil {
+		log.Fatal().Err(err).Msg("failed to ping redis")
 	}
 
-	if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatal().Err(err).Msg("failed to run migrate up")
+	var credentials func() (string, string)
+	if config.RedisPasswordFile != "" {
+		credentials = func() (string, string) {
+			return "", secrets.RedisPassword()
+		}
+	}
+	redisClient, err := options.NewClient(credentials)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create redis client")
 	}
 
-	log.Info().Msg("DB migrated successfully")
+	return redisClient, redisOpt
 }
 
-func runTaskProcessor(redisOpt asynq.RedisClientOpt, repo repository.RepositoryProvider, config config.Config)  {
-	mailer := mail.NewGmailSender(config.EmailSender, config.EmailAddress, config.EmailPassword)
-	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, repo, mailer)
+// runServers runs what mode needs until ctx is cancelled or one of them fails, then gives each
+// of them config.DrainTimeout to stop. Readiness is checked by pinging the dependencies, a
+// worker is also not ready until its task processor has started.
+func runServers(ctx context.Context, mode mode, config config.Config, registry *prometheus.Registry, redisOpt asynq.RedisConnOpt, repo repository.RepositoryProvider,
+	service service.ServiceProvider, tokenMaker token.Maker, taskDistributor worker.TaskDistributor, subscriber events.Subscriber, pingers map[string]health.Pinger, rateLimiter *gapi.RateLimiter, timeouts gapi.Timeouts, grpcLimits gapi.ServerLimits, files *storage.FileStore, currencyCache *currencies.Cache, logLevels *loglevel.Controller) error {
+	waitGroup, ctx := errgroup.WithContext(ctx)
+
+	checks := make(map[string]health.Pinger, len(pingers)+1)
+	for name, pinger := range pingers {
+		checks[name] = pinger
+	}
+
+	if mode.worker {
+		checks["task_processor"] = runTaskProcessor(ctx, waitGroup, redisOpt, repo, service, config, registry, files)
+		runScheduler(ctx, waitGroup, redisOpt, config)
+		runOutboxRelay(ctx, waitGroup, repo, taskDistributor, config)
+	}
+	runCurrencyRefresh(ctx, waitGroup, currencyCache, config)
+
+	checker := health.NewChecker(config.HealthCheckTimeout, checks)
+	runMetricsServer(ctx, waitGroup, config, registry, checker, logLevels)
+	if config.PprofAddress != "" {
+		runPprofServer(ctx, waitGroup, config)
+	}
+
+	if mode.api {
+		serverMetrics := gapi.NewMetrics(registry)
+		auditRecorder := audit.NewRecorder(service, registry) // shared, its metric can only be registered once
+		passwordGuard := newPasswordGuard(config, registry)
+		tlsConfig := loadTLSConfig(ctx, waitGroup, config)
+		taskInspector := worker.NewRedisTaskInspector(redisOpt)
+
+		// one server behind both listeners, so whatever state it keeps is the same for both
+		server, err := gapi.NewServer(config, service, tokenMaker, taskDistributor, taskInspector, subscriber, files, auditRecorder, currencyCache, passwordGuard, logLevels, gapi.BuildInfo{Version: version, Commit: commit, StartedAt: startedAt})
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot create server")
+		}
+
+		runGatewayServer(ctx, waitGroup, config, tlsConfig, server, checker, serverMetrics, registry, rateLimiter, timeouts, grpcLimits, files, logLevels)
+		runGrpcServer(ctx, waitGroup, config, tlsConfig, server, checker, serverMetrics, rateLimiter, timeouts, grpcLimits)
+	}
+
+	return waitGroup.Wait()
+}
+
+// newPasswordGuard returns the breached password check, or nil when config leaves it off
+func newPasswordGuard(config config.Config, registry *prometheus.Registry) *breach.Guard {
+	if !config.PasswordBreachCheck {
+		return nil
+	}
+
+	endpoint := config.PasswordBreachURL
+	if endpoint == "" {
+		endpoint = breach.DefaultEndpoint
+	}
+	client := breach.NewHIBPClient(endpoint, &http.Client{Timeout: config.PasswordBreachTimeout}, time.Hour)
+	return breach.NewGuard(client, config.PasswordBreachThreshold, registry)
+}
+
+// reloadSecrets reads the secrets mounted from files again on SIGHUP. A new token key makes the
+// tokens from then on, new database and redis connections use a new password.
+func reloadSecrets(ctx context.Context, secrets *config.Secrets, tokenMaker *token.RotatingMaker) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+
+		changed, err := secrets.Reload()
+		if err != nil {
+			log.Error().Err(err).Msg("cannot reload secrets, keeping the current ones")
+			continue
+		}
+
+		for _, name := range changed {
+			switch name {
+			case "TOKEN_SYMMETRIC_KEY":
+				if err := tokenMaker.Rotate(secrets.TokenSymmetricKey()); err != nil {
+					log.Error().Err(err).Msg("cannot rotate token key, keeping the current one")
+					continue
+				}
+			case "EMAIL_PASSWORD":
+				log.Warn().Msg("email password changed, the worker needs a restart to use it")
+				continue
+			}
+			log.Info().Str("secret", name).Msg("secret reloaded")
+		}
+	}
+}
+
+// toggleLogLevel switches between debug and info logging on SIGUSR1
+func toggleLogLevel(ctx context.Context, logLevels *loglevel.Controller) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			level := logLevels.Toggle()
+			log.WithLevel(zerolog.NoLevel).Str("level", level.String()).Msg("log level toggled")
+		}
+	}
+}
+
+// runTaskProcessor processes the queued tasks until ctx is cancelled. The pinger it returns fails
+// unless the processor is running.
+func runTaskProcessor(ctx context.Context, waitGroup *errgroup.Group, redisOpt asynq.RedisConnOpt, repo repository.RepositoryProvider, service service.ServiceProvider, config config.Config, registry *prometheus.Registry, files storage.Store) health.Pinger {
+	var mailer mail.EmailSender
+	switch config.MailProvider {
+	case "", mail.ProviderGmail:
+		mailer = mail.NewGmailSender(config.EmailSender, config.EmailAddress, config.EmailPassword)
+	case mail.ProviderSMTP:
+		mailer = mail.NewSMTPSender(config.EmailSender, config.EmailAddress, config.SMTPHost, config.SMTPPort,
+			config.SMTPUsername, config.SMTPPassword, config.SMTPUseTLS, config.SMTPTimeout)
+	case mail.ProviderSendGrid:
+		mailer = mail.NewSendGridSender(config.EmailSender, config.EmailAddress, config.SendGridAPIKey)
+	case mail.ProviderSES:
+		credentials := mail.StaticSESCredentials{
+			AccessKeyID:     config.SESAccessKeyID,
+			SecretAccessKey: config.SESSecretAccessKey,
+		}
+		mailer = mail.NewSESSender(config.EmailSender, config.EmailAddress, config.SESRegion, credentials)
+	default:
+		log.Fatal().Str("provider", config.MailProvider).Msg("unknown mail provider")
+	}
+	mailer = mail.WithAttachmentLimit(mailer, config.MaxAttachmentSize)
+
+	notifiers := []notify.Notifier{notify.NewEmailNotifier(mailer)}
+	if config.TwilioAccountSID != "" {
+		twilio := notify.NewTwilioClient(config.TwilioAccountSID, config.TwilioAuthToken, &http.Client{Timeout: 10 * time.Second})
+		notifiers = append(notifiers, notify.NewSMSNotifier(twilio, config.TwilioFromNumber))
+	}
+
+	weights := worker.QueueWeights{
+		Critical: config.QueueCriticalWeight,
+		Default:  config.QueueDefaultWeight,
+		Low:      config.QueueLowWeight,
+	}
+	metrics := worker.NewMetrics(registry)
+
+	// the rates of a base are fetched once per refresh, the refresh then reads its pairs from the cache
+	var rateProvider rates.Provider
+	if config.ExchangeRateURL != "" {
+		rateProvider = rates.NewHTTPProvider(config.ExchangeRateURL, &http.Client{Timeout: 10 * time.Second}, config.ExchangeRateRefresh/2)
+	}
+
+	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, weights, config.DrainTimeout, metrics, repo, service, mailer, notify.New(notifiers...), files, config.StatementURLTTL, rateProvider)
 	log.Info().Msg("start task processor")
 	if err := taskProcessor.Start(); err != nil {
 		log.Fatal().Err(err).Msg("failed to start task processor")
 	}
+	var running atomic.Bool
+	running.Store(true)
+
+	inspector := asynq.NewInspector(redisOpt)
+	waitGroup.Go(func() error {
+		defer inspector.Close()
+		metrics.PollQueueDepth(ctx, inspector, queueDepthPollInterval)
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown task processor")
+
+		// waits up to the drain timeout for running tasks, unfinished ones go back on their queue
+		running.Store(false)
+		taskProcessor.Shutdown()
+		log.Info().Msg("task processor is stopped")
+		return nil
+	})
+
+	return health.PingerFunc(func(ctx context.Context) error {
+		if !running.Load() {
+			return errTaskProcessorStopped
+		}
+		return nil
+	})
 }
 
-func runGrpcServer(config config.Config, service service.ServiceProvider, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, service, taskDistributor)
+var errTaskProcessorStopped = errors.New("task processor is stopped")
+
+// queueDepthPollInterval is how often the queue_depth metric is read from redis
+const queueDepthPollInterval = 15 * time.Second
+
+// runMetricsServer serves the metrics for prometheus to scrape on their own address, which can
+// be kept off the public network. The probes are served there too, a worker has no other
+// listener for them.
+func runMetricsServer(ctx context.Context, waitGroup *errgroup.Group, config config.Config, registry *prometheus.Registry, checker *health.Checker, logLevels *loglevel.Controller) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", gapi.MetricsHandler(registry, config.MetricsToken))
+	mux.Handle(health.LivenessPath, health.LivenessHandler(logLevels.Level))
+	mux.Handle(health.ReadinessPath, checker.ReadinessHandler())
+
+	listener, err := net.Listen("tcp", config.MetricsServerAddress)
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create server")
+		log.Fatal().Err(err).Msg("cannot create listener")
 	}
 
-	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger) // create a unary server interceptor
-	grpcServer := grpc.NewServer(grpcLogger)
-	pb.RegisterSwiftBankServer(grpcServer, server)
-	reflection.Register(grpcServer) // it allows the grpc client to explore what RPCs are available on the server and how to call them (i.e self documentation for the server)
+	metricsServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
 
-	// create a new listener
-	listener, err := net.Listen("tcp", config.GrpcServerAddress)
+	runHTTPServer(ctx, waitGroup, "metrics server", metricsServer, listener, config.DrainTimeout)
+}
+
+// runPprofServer serves net/http/pprof on config.PprofAddress, which Validate keeps to loopback
+func runPprofServer(ctx context.Context, waitGroup *errgroup.Group, config config.Config) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", config.PprofAddress)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create listener")
 	}
 
-	log.Info().Msgf("start gRPC server at: %s", listener.Addr().String())
-	if err := grpcServer.Serve(listener); err != nil {
-		log.Fatal().Err(err).Msg("cannot start gRPC server")
+	// no write timeout, a CPU profile or trace takes as long as asked for
+	pprofServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
 	}
+
+	runHTTPServer(ctx, waitGroup, "pprof server", pprofServer, listener, config.DrainTimeout)
 }
 
-func runGatewayServer(config config.Config, service service.ServiceProvider, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, service, taskDistributor)
+// runScheduler enqueues the periodic tasks until ctx is cancelled
+func runScheduler(ctx context.Context, waitGroup *errgroup.Group, redisOpt asynq.RedisConnOpt, config config.Config) {
+	// without a provider the rates are whatever was saved to the table by other means
+	var rateRefresh time.Duration
+	if config.ExchangeRateURL != "" {
+		rateRefresh = config.ExchangeRateRefresh
+	}
+
+	interestRates, err := helpers.ParseInterestRates(config.InterestRates)
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create server")
+		log.Fatal().Err(err).Msg("invalid interest rates")
 	}
 
+	scheduler, err := worker.NewScheduler(redisOpt, worker.CleanupTasks{
+		Sessions:                     config.PurgeSessions,
+		VerifyEmails:                 config.PurgeVerifyEmails,
+		IdempotencyKeys:              config.PurgeIdempotencyKeys,
+		DeletedAccountRetentionYears: config.DeletedAccountRetention,
+	}, rateRefresh, interestRates)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create scheduler")
+	}
+
+	log.Info().Msg("start scheduler")
+	if err := scheduler.Start(); err != nil {
+		log.Fatal().Err(err).Msg("failed to start scheduler")
+	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown scheduler")
+
+		scheduler.Shutdown()
+		log.Info().Msg("scheduler is stopped")
+		return nil
+	})
+}
+
+func runOutboxRelay(ctx context.Context, waitGroup *errgroup.Group, repo repository.RepositoryProvider, taskDistributor worker.TaskDistributor, config config.Config) {
+	relay := worker.NewOutboxRelay(repo, taskDistributor, config.OutboxRelayInterval)
+
+	waitGroup.Go(func() error {
+		log.Info().Msg("start outbox relay")
+		err := relay.Run(ctx)
+		log.Info().Msg("outbox relay is stopped")
+		return err
+	})
+}
+
+func runCurrencyRefresh(ctx context.Context, waitGroup *errgroup.Group, currencyCache *currencies.Cache, config config.Config) {
+	waitGroup.Go(func() error {
+		log.Info().Msg("start currency refresh")
+		err := currencyCache.Run(ctx, config.CurrencyRefreshInterval)
+		log.Info().Msg("currency refresh is stopped")
+		return err
+	})
+}
+
+// loadTLSConfig returns the server TLS config when a certificate is configured, or nil to serve
+// plaintext. The certificate is read again on SIGHUP, so it can be renewed without a restart.
+func loadTLSConfig(ctx context.Context, waitGroup *errgroup.Group, config config.Config) *tls.Config {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		if config.Environment != "development" {
+			log.Warn().Msg("no TLS certificate configured, serving plaintext")
+		}
+		return nil
+	}
+
+	reloader, err := tlscert.NewReloader(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load TLS certificate")
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	waitGroup.Go(func() error {
+		defer signal.Stop(signals)
+		reloader.Watch(ctx, signals)
+		return nil
+	})
+
+	return reloader.ServerConfig()
+}
+
+func runGrpcServer(ctx context.Context, waitGroup *errgroup.Group, config config.Config, tlsConfig *tls.Config, server *gapi.Server, checker *health.Checker, metrics *gapi.Metrics, rateLimiter *gapi.RateLimiter, timeouts gapi.Timeouts, limits gapi.ServerLimits) {
+	grpcServer, healthServer := newGrpcServer(config, tlsConfig, server, metrics, rateLimiter, timeouts, limits)
+
+	listener := serverListener(config, "grpc", config.GrpcServerAddress)
+
+	waitGroup.Go(func() error {
+		checker.Watch(ctx, healthServer, config.HealthCheckInterval)
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		log.Info().Bool("tls", tlsConfig != nil).Msgf("start gRPC server at: %s", listener.Addr().String())
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Error().Err(err).Msg("gRPC server failed to serve")
+			return err
+		}
+
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown gRPC server")
+
+		// health watchers see NOT_SERVING and stop sending traffic while the open calls drain
+		healthServer.Shutdown()
+
+		// GracefulStop waits for open streams too, so it gets the same deadline as the other servers
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(config.DrainTimeout):
+			log.Warn().Msg("gRPC server did not drain in time, closing remaining connections")
+			grpcServer.Stop()
+		}
+
+		log.Info().Msg("gRPC server is stopped")
+		return nil
+	})
+}
+
+// serverListener returns the socket systemd activated as name, or a listener on address, which
+// is a unix socket when it starts with unix://. Closing the listener on shutdown removes its socket file.
+func serverListener(config config.Config, name, address string) net.Listener {
+	mode, err := listen.ParseMode(config.UnixSocketMode)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid unix socket mode")
+	}
+
+	listener, err := listen.Listen(name, address, mode)
+	if err != nil {
+		log.Fatal().Err(err).Str("address", address).Msg("cannot create listener")
+	}
+	return listener
+}
+
+// newGrpcServer returns a gRPC server for server with its interceptors, along with its health service
+func newGrpcServer(config config.Config, tlsConfig *tls.Config, server *gapi.Server, metrics *gapi.Metrics, rateLimiter *gapi.RateLimiter, timeouts gapi.Timeouts, limits gapi.ServerLimits) (*grpc.Server, *grpchealth.Server) {
+	requestLogger := gapi.RequestLogger{SlowThreshold: config.SlowRequestThreshold}
+	grpcLogger := grpc.ChainUnaryInterceptor( // create a unary server interceptor
+		gapi.GrpcRecovery, // first, so a panic in any of the others is caught too
+		gapi.RequestID,
+		requestLogger.GrpcLogger,
+		metrics.GrpcMetrics,
+		rateLimiter.GrpcRateLimit,
+		server.GrpcAuth,
+		server.GrpcValidate,
+		timeouts.GrpcTimeout, // last, so the deadline only covers the handler
+	)
+	grpcStreamLogger := grpc.ChainStreamInterceptor( // create a stream server interceptor
+		gapi.GrpcStreamRecovery,
+		gapi.StreamRequestID,
+		requestLogger.GrpcStreamLogger,
+		server.GrpcStreamAuth,
+	)
+	opts := []grpc.ServerOption{grpcLogger, grpcStreamLogger, grpc.StatsHandler(otelgrpc.NewServerHandler())}
+	opts = append(opts, limits.ServerOptions()...)
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterSwiftBankServer(grpcServer, server)
+	healthServer := grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	if config.GrpcReflection {
+		reflection.Register(grpcServer) // it allows the grpc client to explore what RPCs are available on the server and how to call them (i.e self documentation for the server)
+	}
+
+	return grpcServer, healthServer
+}
+
+func runGatewayServer(ctx context.Context, waitGroup *errgroup.Group, config config.Config, tlsConfig *tls.Config, server *gapi.Server, checker *health.Checker, metrics *gapi.Metrics, registry *prometheus.Registry, rateLimiter *gapi.RateLimiter, timeouts gapi.Timeouts, limits gapi.ServerLimits, files *storage.FileStore, logLevels *loglevel.Controller) {
+	handler := newGatewayHandler(ctx, config, tlsConfig, server, checker, metrics, registry, rateLimiter, timeouts, limits, files, logLevels)
+
+	listener := serverListener(config, "http", config.HttpServerAddress)
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second, // statements can take a while to build
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	runHTTPServer(ctx, waitGroup, "HTTP gateway server", httpServer, listener, config.DrainTimeout)
+
+	if tlsConfig != nil && config.HttpRedirectAddress != "" {
+		runRedirectServer(ctx, waitGroup, config, listener.Addr())
+	}
+}
+
+// newGatewayHandler returns the HTTP gateway to server, with the health, metrics and file routes
+// beside it and its middleware around them. In proxy mode the RPCs go through a connection to
+// config.GrpcServerAddress, which is closed once ctx is done; the other routes are still served
+// by server.
+func newGatewayHandler(ctx context.Context, config config.Config, tlsConfig *tls.Config, server *gapi.Server, checker *health.Checker, metrics *gapi.Metrics, registry *prometheus.Registry, rateLimiter *gapi.RateLimiter, timeouts gapi.Timeouts, limits gapi.ServerLimits, files *storage.FileStore, logLevels *loglevel.Controller) http.Handler {
 	// set json response to use snake case
-	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+	jsonMarshaler := &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
 		},
 		UnmarshalOptions: protojson.UnmarshalOptions{
 			DiscardUnknown: true,
 		},
-	})
-	grpcMux := runtime.NewServeMux(jsonOption)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	if err := pb.RegisterSwiftBankHandlerServer(ctx, grpcMux, server); err != nil {
-		log.Fatal().Err(err).Msg("cannot register handler server")
+	}
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonMarshaler)
+	csvOption := runtime.WithMarshalerOption(gapi.MIMETextCSV, gapi.NewCSVMarshaler(jsonMarshaler)) // statements as CSV
+	protoOption := runtime.WithMarshalerOption(gapi.MIMEProtobuf, &gapi.ProtoMarshaler{})
+	proxy := config.GatewayMode == gatewayProxy
+	headerMatcher := gapi.HeaderMatcher
+	if proxy {
+		headerMatcher = gapi.ProxyHeaderMatcher
+	}
+	headerOption := runtime.WithIncomingHeaderMatcher(headerMatcher)
+	routeOption := runtime.WithMetadata(gapi.RecordRoute)                    // lets the HTTP metrics label requests by route
+	responseOption := runtime.WithForwardResponseOption(gapi.RecordResponse) // lets the HTTP logger summarize responses
+	httpErrors := gapi.HTTPErrors{HideInternal: config.Environment != "development"}
+	errorOption := runtime.WithErrorHandler(httpErrors.ErrorHandler)
+	routingErrorOption := runtime.WithRoutingErrorHandler(httpErrors.RoutingErrorHandler)
+	grpcMux := runtime.NewServeMux(jsonOption, csvOption, protoOption, headerOption, routeOption, responseOption, errorOption, routingErrorOption)
+
+	if proxy {
+		opts := append(limits.DialOptions(), grpc.WithTransportCredentials(gatewayCredentials(config, tlsConfig)))
+		if err := pb.RegisterSwiftBankHandlerFromEndpoint(ctx, grpcMux, config.GrpcServerAddress, opts); err != nil {
+			log.Fatal().Err(err).Msg("cannot register handler from endpoint")
+		}
+	} else {
+		// the other interceptors have HTTP twins around the mux, validation needs the decoded request
+		conn := gapi.NewInProcessConn(server, server.GrpcValidate)
+		if err := pb.RegisterSwiftBankHandlerClient(ctx, grpcMux, pb.NewSwiftBankClient(conn)); err != nil {
+			log.Fatal().Err(err).Msg("cannot register in process handler")
+		}
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.Handle(health.LivenessPath, health.LivenessHandler(logLevels.Level))
+	mux.Handle(health.ReadinessPath, checker.ReadinessHandler())
+	mux.Handle("/metrics", gapi.MetricsHandler(registry, config.MetricsToken))
+	mux.Handle(gapi.APIPathPrefix+"version", gapi.VersionHandler(version, commit))
+	// streamed instead of going through the generated handler, which buffers the whole export
+	mux.HandleFunc("GET "+gapi.APIPathPrefix+"accounts/{account_id}/transactions/export", server.HttpExportTransactions)
+	// the signed links of statements, they carry their own authorization
+	mux.Handle("GET "+gapi.APIPathPrefix+"files/", http.StripPrefix(strings.TrimSuffix(gapi.APIPathPrefix, "/")+"/files", files))
+
+	// a local setup keeps the swagger UI open
+	swaggerUsername := config.SwaggerUsername
+	if config.Environment == "development" {
+		swaggerUsername = ""
+	}
+	mux.Handle(doc.SwaggerPath, doc.Handler(swaggerUsername, config.SwaggerPassword))
+
+	// the gateway calls the handlers in process, so its span is the parent of their database queries
+	var handler http.Handler = otelhttp.NewHandler(rateLimiter.HttpRateLimit(server.HttpAuth(timeouts.HttpTimeout(mux))), "http gateway", otelhttp.WithFilter(func(r *http.Request) bool {
+		return !health.IsProbe(r.URL.Path) && r.URL.Path != "/metrics"
+	}))
+
+	cors, err := gapi.NewCORS(config.CORSAllowedOrigins)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid CORS origins")
+	}
+	if cors.Enabled() {
+		handler = cors.Handler(handler) // outside auth and rate limiting, preflights carry no token
+	}
+	if config.LegacyPathsSunset != "" {
+		sunset, err := time.Parse(time.DateOnly, config.LegacyPathsSunset)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid legacy paths sunset")
+		}
+		handler = gapi.LegacyPaths(sunset, handler) // ahead of everything that resolves the RPC from the path
+	}
+	handler = gapi.HttpCompression(handler)
+	if tlsConfig != nil {
+		handler = gapi.HSTS(handler)
+	}
+
+	requestLogger := gapi.RequestLogger{SlowThreshold: config.SlowRequestThreshold}
+	return gapi.HttpRecovery(gapi.HttpRequestID(requestLogger.HttpLogger(metrics.HttpMetrics(handler)))) // add the http gateway logger
+}
+
+// gatewayProxy is the config.GatewayMode of a gateway dialing the gRPC server, any other calls it in process
+const gatewayProxy = "proxy"
 
-	// serve swagger file with statik
-	statikFs, err := fs.New()
+// gatewayCredentials returns the credentials of the gateway's connection to the gRPC server, TLS
+// when the server serves it. The system roots are trusted, along with the server's own
+// certificate in case it is self-signed.
+func gatewayCredentials(config config.Config, tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig == nil {
+		return insecure.NewCredentials()
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	certificate, err := os.ReadFile(config.TLSCertFile)
 	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create statik fs")
+		log.Fatal().Err(err).Msg("cannot read TLS certificate")
 	}
+	roots.AppendCertsFromPEM(certificate)
+
+	return credentials.NewTLS(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+}
 
-	swaggerHandler := http.StripPrefix("/swagger/", http.FileServer(statikFs))
-	mux.Handle("/swagger/", swaggerHandler)
+// runRedirectServer sends plain HTTP requests on to the HTTPS gateway listening on gatewayAddr. A
+// gateway without a port to send them to gets no redirect server.
+func runRedirectServer(ctx context.Context, waitGroup *errgroup.Group, config config.Config, gatewayAddr net.Addr) {
+	httpsPort, ok := gapi.HTTPSPort(gatewayAddr)
+	if !ok {
+		log.Warn().Str("address", gatewayAddr.String()).Msg("HTTP gateway is not listening on a TCP port, not starting the HTTP redirect server")
+		return
+	}
 
-	// create a new listener
-	listener, err := net.Listen("tcp", config.HttpServerAddress)
+	listener, err := net.Listen("tcp", config.HttpRedirectAddress)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create listener")
 	}
 
-	log.Info().Msgf("start HTTP gateway server at: %s", listener.Addr().String())
-	handler := gapi.HttpLogger(mux) // add the http gateway logger
-
-	if err := http.Serve(listener, handler); err != nil {
-		log.Fatal().Err(err).Msg("cannot start HTTP gateway server")
+	redirectServer := &http.Server{
+		Handler:           gapi.RedirectToHTTPS(httpsPort),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      5 * time.Second,
+		IdleTimeout:       time.Minute,
 	}
+
+	runHTTPServer(ctx, waitGroup, "HTTP redirect server", redirectServer, listener, config.DrainTimeout)
+}
+
+// runHTTPServer serves on listener, over TLS when the server has a TLS config, until ctx is
+// cancelled. Open requests then get drainTimeout to finish.
+func runHTTPServer(ctx context.Context, waitGroup *errgroup.Group, name string, server *http.Server, listener net.Listener, drainTimeout time.Duration) {
+	waitGroup.Go(func() error {
+		log.Info().Bool("tls", server.TLSConfig != nil).Msgf("start %s at: %s", name, listener.Addr().String())
+
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ServeTLS(listener, "", "") // the certificate comes from the TLS config
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msgf("%s failed to serve", name)
+			return err
+		}
+
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msgf("graceful shutdown %s", name)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn().Err(err).Msgf("%s did not drain in time, closing remaining connections", name)
+			server.Close()
+		}
+
+		log.Info().Msgf("%s is stopped", name)
+		return nil
+	})
 }
 
-func runGinServer(config config.Config, pool *pgxpool.Pool) {
-	repository := repository.NewRepository(pool)
-	service := service.NewService(repository.R)
+func runGinServer(config config.Config, db database.DB) {
+	repository := repository.NewRepository(db, prometheus.NewRegistry(), nil, config.TransferAdvisoryLocks) // the gin server serves no metrics
+	redisClient, err := redisOptions(config).NewClient(nil)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create redis client")
+	}
+	redisOpt, err := redisOptions(config).AsynqOpt()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid redis options")
+	}
+	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+	webhookDispatcher := worker.NewWebhookDispatcher(taskDistributor, config.WebhookMaxRetry)
+	alertThresholds, err := helpers.ParseCurrencyAmounts(config.LargeTransferThresholds)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid large transfer thresholds")
+	}
+	transferAlerter := worker.NewTransferAlertDispatcher(taskDistributor, alertThresholds)
+	entityCache := newEntityCache(config, redisClient, prometheus.NewRegistry())
+	service := service.NewService(repository.R, events.NewRedisBroker(redisClient), rates.NewStoreProvider(repository.R, config.ExchangeRateMaxAge), webhookDispatcher, transferAlerter, entityCache, config.SavingsTransferLimit)
 	handler, err := api.NewHandler(config, service.S)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load handler")
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/config"
+	"github.com/zde37/Swift_Bank/currencies"
+	"github.com/zde37/Swift_Bank/events"
+	"github.com/zde37/Swift_Bank/gapi"
+	"github.com/zde37/Swift_Bank/health"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/loglevel"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"github.com/zde37/Swift_Bank/ratelimit"
+	"github.com/zde37/Swift_Bank/requestid"
+	"github.com/zde37/Swift_Bank/storage"
+	"github.com/zde37/Swift_Bank/token"
+	"github.com/zde37/Swift_Bank/worker"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRunServersStopsOnCancel(t *testing.T) {
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			testRunServersStopsOnCancel(t, mode)
+		})
+	}
+}
+
+func testRunServersStopsOnCancel(t *testing.T, mode mode) {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	config := config.Config{
+		HttpServerAddress:    "127.0.0.1:0",
+		GrpcServerAddress:    "127.0.0.1:0",
+		MetricsServerAddress: "127.0.0.1:0",
+		RedisAddress:         redisServer.Addr(),
+		TokenSymmetricKey:    helpers.RandomString(32),
+		EmailSender:          "Swift Bank",
+		DrainTimeout:         time.Second,
+		OutboxRelayInterval:  50 * time.Millisecond,
+		HealthCheckInterval:  50 * time.Millisecond,
+		HealthCheckTimeout:   time.Second,
+		QueueCriticalWeight:  6,
+		QueueDefaultWeight:   3,
+		QueueLowWeight:       1,
+	}
+	config.CurrencyRefreshInterval = 50 * time.Millisecond
+	redisOpt := asynq.RedisClientOpt{Addr: redisServer.Addr()}
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	repo.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(0, nil)
+	repo.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return([]models.Currency{
+		{Code: helpers.USD, MinorUnitExponent: 2, Enabled: true},
+		{Code: helpers.EUR, MinorUnitExponent: 2, Enabled: true},
+		{Code: helpers.CAD, MinorUnitExponent: 2, Enabled: true},
+	}, nil)
+
+	pingers := map[string]health.Pinger{
+		"redis": health.PingerFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+	}
+
+	tokenMaker, err := token.NewRotatingMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	rateLimiter := gapi.NewRateLimiter(tokenMaker, ratelimit.NewRedisLimiter(redisClient), ratelimit.Limits{
+		Default: ratelimit.Limit{Requests: 60, Period: time.Minute},
+	}, config.BehindProxy)
+
+	files, err := storage.NewFileStore(t.TempDir(), "http://127.0.0.1/v1/files", config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- runServers(ctx, mode, config, prometheus.NewRegistry(), redisOpt, repo, service, tokenMaker, worker.NewRedisTaskDistributor(redisOpt), events.NewRedisBroker(redisClient), pingers, rateLimiter, gapi.Timeouts{Default: 5 * time.Second}, gapi.ServerLimits{}, files, currencies.NewCache(repo), loglevel.NewController(zerolog.GlobalLevel()))
+	}()
+
+	// let everything start before asking it to stop
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("servers did not stop after the context was cancelled")
+	}
+}
+
+// testAPI is a gRPC server around one gapi.Server, along with what gateways to it need
+type testAPI struct {
+	config      config.Config
+	server      *gapi.Server
+	tokenMaker  token.Maker
+	logLevels   *loglevel.Controller
+	metrics     *gapi.Metrics
+	registry    *prometheus.Registry
+	rateLimiter *gapi.RateLimiter
+	timeouts    gapi.Timeouts
+	files       *storage.FileStore
+	grpcServer  *grpc.Server
+	conn        *grpc.ClientConn
+}
+
+// startTestAPI starts the api with its config changed by configure, when given
+func startTestAPI(t *testing.T, service *mockedproviders.MockServiceProvider, configure ...func(c *config.Config)) *testAPI {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	api := &testAPI{
+		config:    config.Config{TokenSymmetricKey: helpers.RandomString(32)},
+		logLevels: loglevel.NewController(zerolog.InfoLevel),
+		registry:  prometheus.NewRegistry(),
+		timeouts:  gapi.Timeouts{Default: 5 * time.Second},
+	}
+	for _, change := range configure {
+		change(&api.config)
+	}
+	tokenMaker, err := token.NewRotatingMaker(api.config.TokenSymmetricKey)
+	require.NoError(t, err)
+	api.tokenMaker = tokenMaker
+
+	api.server, err = gapi.NewServer(api.config, service, tokenMaker, nil, nil, nil, nil, nil, nil, nil, api.logLevels, gapi.BuildInfo{Version: "test"})
+	require.NoError(t, err)
+
+	api.metrics = gapi.NewMetrics(api.registry)
+	api.rateLimiter = gapi.NewRateLimiter(tokenMaker, ratelimit.NewRedisLimiter(redisClient), ratelimit.Limits{
+		Default: ratelimit.Limit{Requests: 60, Period: time.Minute},
+	}, api.config.BehindProxy)
+	api.files, err = storage.NewFileStore(t.TempDir(), "http://127.0.0.1/v1/files", api.config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	api.grpcServer, _ = newGrpcServer(api.config, nil, api.server, api.metrics, api.rateLimiter, api.timeouts, gapi.ServerLimits{})
+	if api.config.GrpcServerAddress == "" {
+		api.config.GrpcServerAddress = "127.0.0.1:0"
+	}
+	listener := serverListener(api.config, "grpc", api.config.GrpcServerAddress)
+	go api.grpcServer.Serve(listener)
+	t.Cleanup(api.grpcServer.Stop)
+	if listener.Addr().Network() == "tcp" {
+		api.config.GrpcServerAddress = listener.Addr().String()
+	}
+
+	api.conn, err = grpc.NewClient(api.config.GrpcServerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { api.conn.Close() })
+
+	return api
+}
+
+// gateway serves a gateway in mode in front of the api
+func (api *testAPI) gateway(t *testing.T, mode string) *httptest.Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	config := api.config
+	config.GatewayMode = mode
+	checker := health.NewChecker(time.Second, nil)
+	gateway := httptest.NewServer(newGatewayHandler(ctx, config, nil, api.server, checker, api.metrics, api.registry, api.rateLimiter, api.timeouts, gapi.ServerLimits{}, api.files, api.logLevels))
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func (api *testAPI) accessToken(t *testing.T, username, role string) string {
+	accessToken, _, err := api.tokenMaker.CreateToken(username, role, time.Minute)
+	require.NoError(t, err)
+	return accessToken
+}
+
+func TestGrpcAndGatewayShareServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := startTestAPI(t, mockedproviders.NewMockServiceProvider(ctrl))
+	gateway := api.gateway(t, "inprocess")
+	accessToken := api.accessToken(t, "admin", helpers.BankerRole)
+
+	liveLogLevel := func() string {
+		response, err := http.Get(gateway.URL + health.LivenessPath)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		var body struct {
+			LogLevel string `json:"log_level"`
+		}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		return body.LogLevel
+	}
+
+	// changed through gRPC, seen through the gateway
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+accessToken)
+	_, err := pb.NewSwiftBankClient(api.conn).SetLogLevel(ctx, &pb.SetLogLevelRequest{Level: "debug", Duration: durationpb.New(time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, "debug", liveLogLevel())
+
+	// and the other way round
+	request, err := http.NewRequest(http.MethodPost, gateway.URL+"/v1/admin/log_level", strings.NewReader(`{"level":"warn","duration":"60s"}`))
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, zerolog.WarnLevel, api.logLevels.Level())
+}
+
+func TestGatewayModes(t *testing.T) {
+	account := models.Account{ID: 7, Owner: "alice", Balance: 12345, Currency: helpers.USD}
+
+	ctrl := gomock.NewController(t)
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetAccount(gomock.Any(), account.ID).AnyTimes().Return(account, nil)
+	service.EXPECT().GetAccount(gomock.Any(), int64(8)).AnyTimes().Return(models.Account{}, pgx.ErrNoRows)
+	service.EXPECT().GetBalance(gomock.Any(), gomock.Any()).AnyTimes().Return(models.Balance{
+		AccountID:        account.ID,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		DisplayCurrency:  account.Currency,
+		ConvertedBalance: account.Balance,
+		Rate:             1,
+	}, nil)
+
+	api := startTestAPI(t, service)
+	inProcess := api.gateway(t, "inprocess")
+	proxy := api.gateway(t, "proxy")
+
+	owner := api.accessToken(t, account.Owner, helpers.DepositorRole)
+	stranger := api.accessToken(t, "mallory", helpers.DepositorRole)
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		accessToken string
+		status      int
+	}{
+		{name: "OK", method: http.MethodGet, path: "/v1/accounts/7/balance", accessToken: owner, status: http.StatusOK},
+		{name: "NotOwner", method: http.MethodGet, path: "/v1/accounts/7/balance", accessToken: stranger, status: http.StatusForbidden},
+		{name: "NotFound", method: http.MethodGet, path: "/v1/accounts/8/balance", accessToken: owner, status: http.StatusNotFound},
+		{name: "NoToken", method: http.MethodGet, path: "/v1/accounts/7/balance", status: http.StatusUnauthorized},
+		{name: "InvalidArgument", method: http.MethodPost, path: "/v1/create_user", body: `{"username":"a"}`, status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			call := func(gateway *httptest.Server) (int, string) {
+				request, err := http.NewRequest(tc.method, gateway.URL+tc.path, strings.NewReader(tc.body))
+				require.NoError(t, err)
+				request.Header.Set(requestid.Header, "gateway-modes-test")
+				if tc.accessToken != "" {
+					request.Header.Set("Authorization", "Bearer "+tc.accessToken)
+				}
+
+				response, err := http.DefaultClient.Do(request)
+				require.NoError(t, err)
+				defer response.Body.Close()
+				require.Equal(t, "gateway-modes-test", response.Header.Get(requestid.Header))
+
+				body, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				return response.StatusCode, string(body)
+			}
+
+			inProcessStatus, inProcessBody := call(inProcess)
+			proxyStatus, proxyBody := call(proxy)
+			require.Equal(t, tc.status, inProcessStatus)
+			require.Equal(t, inProcessStatus, proxyStatus)
+			require.JSONEq(t, inProcessBody, proxyBody)
+		})
+	}
+}
+
+func TestGrpcReflection(t *testing.T) {
+	listServices := func(t *testing.T, api *testAPI) ([]string, error) {
+		stream, err := grpc_reflection_v1.NewServerReflectionClient(api.conn).ServerReflectionInfo(context.Background())
+		require.NoError(t, err)
+		defer stream.CloseSend()
+
+		err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		})
+		require.NoError(t, err)
+
+		rsp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		var services []string
+		for _, service := range rsp.GetListServicesResponse().GetService() {
+			services = append(services, service.GetName())
+		}
+		return services, nil
+	}
+
+	t.Run("Off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		api := startTestAPI(t, mockedproviders.NewMockServiceProvider(ctrl))
+
+		_, err := listServices(t, api)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("On", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		api := startTestAPI(t, mockedproviders.NewMockServiceProvider(ctrl), func(c *config.Config) { c.GrpcReflection = true })
+
+		services, err := listServices(t, api)
+		require.NoError(t, err)
+		require.Contains(t, services, pb.SwiftBank_ServiceDesc.ServiceName)
+	})
+}
+
+func TestGrpcOverUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "grpc.sock")
+
+	ctrl := gomock.NewController(t)
+	api := startTestAPI(t, mockedproviders.NewMockServiceProvider(ctrl), func(c *config.Config) {
+		c.GrpcServerAddress = "unix://" + socket
+		c.UnixSocketMode = "0600"
+	})
+	accessToken := api.accessToken(t, "admin", helpers.BankerRole)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket, info.Mode().Type())
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+accessToken)
+	rsp, err := pb.NewSwiftBankClient(api.conn).ServerInfo(ctx, &pb.ServerInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "test", rsp.GetVersion())
+
+	// a gateway in proxy mode dials the socket too
+	request, err := http.NewRequest(http.MethodGet, api.gateway(t, "proxy").URL+"/v1/admin/server_info", nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	api.grpcServer.GracefulStop()
+	_, err = os.Stat(socket)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -12,6 +12,7 @@ package mockedproviders
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	models "github.com/zde37/Swift_Bank/models"
@@ -41,19 +42,154 @@ func (m *MockRepositoryProvider) EXPECT() *MockRepositoryProviderMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterest mocks base method.
+func (m *MockRepositoryProvider) AccrueInterest(arg0 context.Context, arg1 models.InterestAccrual) (models.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterest", arg0, arg1)
+	ret0, _ := ret[0].(models.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterest indicates an expected call of AccrueInterest.
+func (mr *MockRepositoryProviderMockRecorder) AccrueInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterest", reflect.TypeOf((*MockRepositoryProvider)(nil).AccrueInterest), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
-func (m *MockRepositoryProvider) AddAccountBalance(arg0 context.Context, arg1 int64, arg2 float64) (models.Account, error) {
+func (m *MockRepositoryProvider) AddAccountBalance(arg0 context.Context, arg1 models.AddAccountBalanceParams) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddAccountBalance indicates an expected call of AddAccountBalance.
-func (mr *MockRepositoryProviderMockRecorder) AddAccountBalance(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockRepositoryProviderMockRecorder) AddAccountBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockRepositoryProvider)(nil).AddAccountBalance), arg0, arg1)
+}
+
+// AddCurrency mocks base method.
+func (m *MockRepositoryProvider) AddCurrency(arg0 context.Context, arg1 models.Currency) (models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCurrency", arg0, arg1)
+	ret0, _ := ret[0].(models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCurrency indicates an expected call of AddCurrency.
+func (mr *MockRepositoryProviderMockRecorder) AddCurrency(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCurrency", reflect.TypeOf((*MockRepositoryProvider)(nil).AddCurrency), arg0, arg1)
+}
+
+// BulkCreateAccountsTx mocks base method.
+func (m *MockRepositoryProvider) BulkCreateAccountsTx(arg0 context.Context, arg1 models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateAccountsTx", arg0, arg1)
+	ret0, _ := ret[0].([]models.BulkAccountResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkCreateAccountsTx indicates an expected call of BulkCreateAccountsTx.
+func (mr *MockRepositoryProviderMockRecorder) BulkCreateAccountsTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateAccountsTx", reflect.TypeOf((*MockRepositoryProvider)(nil).BulkCreateAccountsTx), arg0, arg1)
+}
+
+// CancelScheduledTransfer mocks base method.
+func (m *MockRepositoryProvider) CancelScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockRepositoryProviderMockRecorder) CancelScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockRepositoryProvider)(nil).CancelScheduledTransfer), arg0, arg1)
+}
+
+// ClaimScheduledTransfer mocks base method.
+func (m *MockRepositoryProvider) ClaimScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduledTransfer indicates an expected call of ClaimScheduledTransfer.
+func (mr *MockRepositoryProviderMockRecorder) ClaimScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduledTransfer", reflect.TypeOf((*MockRepositoryProvider)(nil).ClaimScheduledTransfer), arg0, arg1)
+}
+
+// CountEntriesBetween mocks base method.
+func (m *MockRepositoryProvider) CountEntriesBetween(arg0 context.Context, arg1 int64, arg2, arg3 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEntriesBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEntriesBetween indicates an expected call of CountEntriesBetween.
+func (mr *MockRepositoryProviderMockRecorder) CountEntriesBetween(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEntriesBetween", reflect.TypeOf((*MockRepositoryProvider)(nil).CountEntriesBetween), arg0, arg1, arg2, arg3)
+}
+
+// CountOutgoingTransfers mocks base method.
+func (m *MockRepositoryProvider) CountOutgoingTransfers(arg0 context.Context, arg1 int64, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOutgoingTransfers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOutgoingTransfers indicates an expected call of CountOutgoingTransfers.
+func (mr *MockRepositoryProviderMockRecorder) CountOutgoingTransfers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOutgoingTransfers", reflect.TypeOf((*MockRepositoryProvider)(nil).CountOutgoingTransfers), arg0, arg1, arg2)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockRepositoryProvider) CountUnreadNotifications(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockRepositoryProviderMockRecorder) CountUnreadNotifications(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockRepositoryProvider)(nil).AddAccountBalance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockRepositoryProvider)(nil).CountUnreadNotifications), arg0, arg1)
+}
+
+// CountUsersWithPhoneNumber mocks base method.
+func (m *MockRepositoryProvider) CountUsersWithPhoneNumber(arg0 context.Context, arg1, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsersWithPhoneNumber", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsersWithPhoneNumber indicates an expected call of CountUsersWithPhoneNumber.
+func (mr *MockRepositoryProviderMockRecorder) CountUsersWithPhoneNumber(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsersWithPhoneNumber", reflect.TypeOf((*MockRepositoryProvider)(nil).CountUsersWithPhoneNumber), arg0, arg1, arg2)
 }
 
 // CreateAccount mocks base method.
@@ -71,6 +207,36 @@ func (mr *MockRepositoryProviderMockRecorder) CreateAccount(arg0, arg1 any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateAuditLog mocks base method.
+func (m *MockRepositoryProvider) CreateAuditLog(arg0 context.Context, arg1 models.CreateAuditLogParams) (models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockRepositoryProviderMockRecorder) CreateAuditLog(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateAuditLog), arg0, arg1)
+}
+
+// CreateBeneficiary mocks base method.
+func (m *MockRepositoryProvider) CreateBeneficiary(arg0 context.Context, arg1 models.Beneficiary) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBeneficiary indicates an expected call of CreateBeneficiary.
+func (mr *MockRepositoryProviderMockRecorder) CreateBeneficiary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBeneficiary", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateBeneficiary), arg0, arg1)
+}
+
 // CreateEntry mocks base method.
 func (m *MockRepositoryProvider) CreateEntry(arg0 context.Context, arg1 models.Entry) (models.Entry, error) {
 	m.ctrl.T.Helper()
@@ -86,6 +252,51 @@ func (mr *MockRepositoryProviderMockRecorder) CreateEntry(arg0, arg1 any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateEntry), arg0, arg1)
 }
 
+// CreateFailedNotification mocks base method.
+func (m *MockRepositoryProvider) CreateFailedNotification(arg0 context.Context, arg1 models.FailedNotification) (models.FailedNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFailedNotification", arg0, arg1)
+	ret0, _ := ret[0].(models.FailedNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFailedNotification indicates an expected call of CreateFailedNotification.
+func (mr *MockRepositoryProviderMockRecorder) CreateFailedNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFailedNotification", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateFailedNotification), arg0, arg1)
+}
+
+// CreateNotification mocks base method.
+func (m *MockRepositoryProvider) CreateNotification(arg0 context.Context, arg1 models.Notification) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockRepositoryProviderMockRecorder) CreateNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateNotification), arg0, arg1)
+}
+
+// CreateScheduledTransferTx mocks base method.
+func (m *MockRepositoryProvider) CreateScheduledTransferTx(arg0 context.Context, arg1 models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransferTx indicates an expected call of CreateScheduledTransferTx.
+func (mr *MockRepositoryProviderMockRecorder) CreateScheduledTransferTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferTx", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateScheduledTransferTx), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockRepositoryProvider) CreateSession(arg0 context.Context, arg1 models.Session) (models.Session, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +312,21 @@ func (mr *MockRepositoryProviderMockRecorder) CreateSession(arg0, arg1 any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateSession), arg0, arg1)
 }
 
+// CreateStandingOrder mocks base method.
+func (m *MockRepositoryProvider) CreateStandingOrder(arg0 context.Context, arg1 models.StandingOrder) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStandingOrder indicates an expected call of CreateStandingOrder.
+func (mr *MockRepositoryProviderMockRecorder) CreateStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStandingOrder", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateStandingOrder), arg0, arg1)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockRepositoryProvider) CreateTransaction(arg0 context.Context, arg1 models.Transaction) (models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -161,6 +387,36 @@ func (mr *MockRepositoryProviderMockRecorder) CreateVerifyEmail(arg0, arg1 any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyEmail", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateVerifyEmail), arg0, arg1)
 }
 
+// CreateVerifyPhone mocks base method.
+func (m *MockRepositoryProvider) CreateVerifyPhone(arg0 context.Context, arg1 models.VerifyPhones) (models.VerifyPhones, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerifyPhone", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyPhones)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVerifyPhone indicates an expected call of CreateVerifyPhone.
+func (mr *MockRepositoryProviderMockRecorder) CreateVerifyPhone(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyPhone", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateVerifyPhone), arg0, arg1)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockRepositoryProvider) CreateWebhook(arg0 context.Context, arg1 models.Webhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockRepositoryProviderMockRecorder) CreateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockRepositoryProvider)(nil).CreateWebhook), arg0, arg1)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockRepositoryProvider) DeleteAccount(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -175,206 +431,1133 @@ func (mr *MockRepositoryProviderMockRecorder) DeleteAccount(arg0, arg1 any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteAccount), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockRepositoryProvider) GetAccount(arg0 context.Context, arg1 int64) (models.Account, error) {
+// DeleteBeneficiary mocks base method.
+func (m *MockRepositoryProvider) DeleteBeneficiary(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(models.Account)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockRepositoryProviderMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+// DeleteBeneficiary indicates an expected call of DeleteBeneficiary.
+func (mr *MockRepositoryProviderMockRecorder) DeleteBeneficiary(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBeneficiary", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteBeneficiary), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockRepositoryProvider) GetAccountForUpdate(arg0 context.Context, arg1 int64) (models.Account, error) {
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockRepositoryProvider) DeleteExpiredIdempotencyKeys(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(models.Account)
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", arg0)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockRepositoryProviderMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockRepositoryProviderMockRecorder) DeleteExpiredIdempotencyKeys(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteExpiredIdempotencyKeys), arg0)
 }
 
-// GetEntry mocks base method.
-func (m *MockRepositoryProvider) GetEntry(arg0 context.Context, arg1 int64) (models.Entry, error) {
+// DeleteExpiredSessions mocks base method.
+func (m *MockRepositoryProvider) DeleteExpiredSessions(arg0 context.Context, arg1 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(models.Entry)
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockRepositoryProviderMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockRepositoryProviderMockRecorder) DeleteExpiredSessions(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockRepositoryProvider)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteExpiredSessions), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockRepositoryProvider) GetSession(arg0 context.Context, arg1 uuid.UUID) (models.Session, error) {
+// DeleteStaleVerifyEmails mocks base method.
+func (m *MockRepositoryProvider) DeleteStaleVerifyEmails(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(models.Session)
+	ret := m.ctrl.Call(m, "DeleteStaleVerifyEmails", arg0)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockRepositoryProviderMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+// DeleteStaleVerifyEmails indicates an expected call of DeleteStaleVerifyEmails.
+func (mr *MockRepositoryProviderMockRecorder) DeleteStaleVerifyEmails(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockRepositoryProvider)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleVerifyEmails", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteStaleVerifyEmails), arg0)
 }
 
-// GetTransaction mocks base method.
-func (m *MockRepositoryProvider) GetTransaction(arg0 context.Context, arg1 int64) (models.Transaction, error) {
+// DeleteStandingOrder mocks base method.
+func (m *MockRepositoryProvider) DeleteStandingOrder(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
-	ret0, _ := ret[0].(models.Transaction)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetTransaction indicates an expected call of GetTransaction.
-func (mr *MockRepositoryProviderMockRecorder) GetTransaction(arg0, arg1 any) *gomock.Call {
+// DeleteStandingOrder indicates an expected call of DeleteStandingOrder.
+func (mr *MockRepositoryProviderMockRecorder) DeleteStandingOrder(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockRepositoryProvider)(nil).GetTransaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStandingOrder", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteStandingOrder), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockRepositoryProvider) GetUser(arg0 context.Context, arg1 string) (models.User, error) {
+// DeleteWebhook mocks base method.
+func (m *MockRepositoryProvider) DeleteWebhook(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockRepositoryProviderMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockRepositoryProviderMockRecorder) DeleteWebhook(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepositoryProvider)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepositoryProvider)(nil).DeleteWebhook), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockRepositoryProvider) ListAccounts(arg0 context.Context, arg1 string, arg2, arg3 int32) ([]models.Account, error) {
+// DisableCurrency mocks base method.
+func (m *MockRepositoryProvider) DisableCurrency(arg0 context.Context, arg1 string) (models.Currency, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].([]models.Account)
+	ret := m.ctrl.Call(m, "DisableCurrency", arg0, arg1)
+	ret0, _ := ret[0].(models.Currency)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockRepositoryProviderMockRecorder) ListAccounts(arg0, arg1, arg2, arg3 any) *gomock.Call {
+// DisableCurrency indicates an expected call of DisableCurrency.
+func (mr *MockRepositoryProviderMockRecorder) DisableCurrency(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockRepositoryProvider)(nil).ListAccounts), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableCurrency", reflect.TypeOf((*MockRepositoryProvider)(nil).DisableCurrency), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockRepositoryProvider) ListEntries(arg0 context.Context, arg1, arg2, arg3 int64) ([]models.Entry, error) {
+// FinishScheduledTransfer mocks base method.
+func (m *MockRepositoryProvider) FinishScheduledTransfer(arg0 context.Context, arg1 models.FinishScheduledTransferParams) (models.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].([]models.Entry)
+	ret := m.ctrl.Call(m, "FinishScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockRepositoryProviderMockRecorder) ListEntries(arg0, arg1, arg2, arg3 any) *gomock.Call {
+// FinishScheduledTransfer indicates an expected call of FinishScheduledTransfer.
+func (mr *MockRepositoryProviderMockRecorder) FinishScheduledTransfer(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockRepositoryProvider)(nil).ListEntries), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishScheduledTransfer", reflect.TypeOf((*MockRepositoryProvider)(nil).FinishScheduledTransfer), arg0, arg1)
 }
 
-// ListTransactions mocks base method.
-func (m *MockRepositoryProvider) ListTransactions(arg0 context.Context, arg1, arg2, arg3, arg4 int64) ([]models.Transaction, error) {
+// FinishStatementDocument mocks base method.
+func (m *MockRepositoryProvider) FinishStatementDocument(arg0 context.Context, arg1 models.FinishStatementDocumentParams) (models.StatementDocument, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransactions", arg0, arg1, arg2, arg3, arg4)
-	ret0, _ := ret[0].([]models.Transaction)
+	ret := m.ctrl.Call(m, "FinishStatementDocument", arg0, arg1)
+	ret0, _ := ret[0].(models.StatementDocument)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransactions indicates an expected call of ListTransactions.
-func (mr *MockRepositoryProviderMockRecorder) ListTransactions(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+// FinishStatementDocument indicates an expected call of FinishStatementDocument.
+func (mr *MockRepositoryProviderMockRecorder) FinishStatementDocument(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockRepositoryProvider)(nil).ListTransactions), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishStatementDocument", reflect.TypeOf((*MockRepositoryProvider)(nil).FinishStatementDocument), arg0, arg1)
 }
 
-// ListUsers mocks base method.
-func (m *MockRepositoryProvider) ListUsers(arg0 context.Context, arg1, arg2 int32) ([]models.User, error) {
+// ForEachLedgerRow mocks base method.
+func (m *MockRepositoryProvider) ForEachLedgerRow(arg0 context.Context, arg1 int64, arg2, arg3 time.Time, arg4 func(models.LedgerRow) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1, arg2)
-	ret0, _ := ret[0].([]models.User)
+	ret := m.ctrl.Call(m, "ForEachLedgerRow", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachLedgerRow indicates an expected call of ForEachLedgerRow.
+func (mr *MockRepositoryProviderMockRecorder) ForEachLedgerRow(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachLedgerRow", reflect.TypeOf((*MockRepositoryProvider)(nil).ForEachLedgerRow), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetAccount mocks base method.
+func (m *MockRepositoryProvider) GetAccount(arg0 context.Context, arg1 int64) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUsers indicates an expected call of ListUsers.
-func (mr *MockRepositoryProviderMockRecorder) ListUsers(arg0, arg1, arg2 any) *gomock.Call {
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockRepositoryProviderMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockRepositoryProvider)(nil).ListUsers), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccount), arg0, arg1)
 }
 
-// TransferTx mocks base method.
-func (m *MockRepositoryProvider) TransferTx(arg0 context.Context, arg1 models.TransferTxParams) (models.TransferTxResult, error) {
+// GetAccountByCurrency mocks base method.
+func (m *MockRepositoryProvider) GetAccountByCurrency(arg0 context.Context, arg1, arg2, arg3 string) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
-	ret0, _ := ret[0].(models.TransferTxResult)
+	ret := m.ctrl.Call(m, "GetAccountByCurrency", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TransferTx indicates an expected call of TransferTx.
-func (mr *MockRepositoryProviderMockRecorder) TransferTx(arg0, arg1 any) *gomock.Call {
+// GetAccountByCurrency indicates an expected call of GetAccountByCurrency.
+func (mr *MockRepositoryProviderMockRecorder) GetAccountByCurrency(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockRepositoryProvider)(nil).TransferTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByCurrency", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccountByCurrency), arg0, arg1, arg2, arg3)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockRepositoryProvider) UpdateAccount(arg0 context.Context, arg1 int64, arg2 float64) (models.Account, error) {
+// GetAccountForUpdate mocks base method.
+func (m *MockRepositoryProvider) GetAccountForUpdate(arg0 context.Context, arg1 int64) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockRepositoryProviderMockRecorder) UpdateAccount(arg0, arg1, arg2 any) *gomock.Call {
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockRepositoryProviderMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateAccount), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccountForUpdate), arg0, arg1)
 }
 
-// UpdateUser mocks base method.
-func (m *MockRepositoryProvider) UpdateUser(arg0 context.Context, arg1 models.UpdateUserParams) (models.User, error) {
+// GetAccountIncludingDeleted mocks base method.
+func (m *MockRepositoryProvider) GetAccountIncludingDeleted(arg0 context.Context, arg1 int64) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
-	ret0, _ := ret[0].(models.User)
+	ret := m.ctrl.Call(m, "GetAccountIncludingDeleted", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockRepositoryProviderMockRecorder) UpdateUser(arg0, arg1 any) *gomock.Call {
+// GetAccountIncludingDeleted indicates an expected call of GetAccountIncludingDeleted.
+func (mr *MockRepositoryProviderMockRecorder) GetAccountIncludingDeleted(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountIncludingDeleted", reflect.TypeOf((*MockRepositoryProvider)(nil).GetAccountIncludingDeleted), arg0, arg1)
 }
 
-// VerifyEmailTx mocks base method.
-func (m *MockRepositoryProvider) VerifyEmailTx(arg0 context.Context, arg1 models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
+// GetBalanceAt mocks base method.
+func (m *MockRepositoryProvider) GetBalanceAt(arg0 context.Context, arg1 int64, arg2 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
-	ret0, _ := ret[0].(models.VerifyEmailTxResult)
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockRepositoryProviderMockRecorder) GetBalanceAt(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockRepositoryProvider)(nil).GetBalanceAt), arg0, arg1, arg2)
+}
+
+// GetBeneficiary mocks base method.
+func (m *MockRepositoryProvider) GetBeneficiary(arg0 context.Context, arg1 int64) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiary indicates an expected call of GetBeneficiary.
+func (mr *MockRepositoryProviderMockRecorder) GetBeneficiary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiary", reflect.TypeOf((*MockRepositoryProvider)(nil).GetBeneficiary), arg0, arg1)
+}
+
+// GetBeneficiaryByIBAN mocks base method.
+func (m *MockRepositoryProvider) GetBeneficiaryByIBAN(arg0 context.Context, arg1, arg2 string) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaryByIBAN", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaryByIBAN indicates an expected call of GetBeneficiaryByIBAN.
+func (mr *MockRepositoryProviderMockRecorder) GetBeneficiaryByIBAN(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaryByIBAN", reflect.TypeOf((*MockRepositoryProvider)(nil).GetBeneficiaryByIBAN), arg0, arg1, arg2)
+}
+
+// GetEntry mocks base method.
+func (m *MockRepositoryProvider) GetEntry(arg0 context.Context, arg1 int64) (models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockRepositoryProviderMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockRepositoryProvider)(nil).GetEntry), arg0, arg1)
+}
+
+// GetExchangeRate mocks base method.
+func (m *MockRepositoryProvider) GetExchangeRate(arg0 context.Context, arg1, arg2 string) (models.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRate indicates an expected call of GetExchangeRate.
+func (mr *MockRepositoryProviderMockRecorder) GetExchangeRate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRate", reflect.TypeOf((*MockRepositoryProvider)(nil).GetExchangeRate), arg0, arg1, arg2)
+}
+
+// GetFeePolicy mocks base method.
+func (m *MockRepositoryProvider) GetFeePolicy(arg0 context.Context, arg1 string) (models.FeePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(models.FeePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeePolicy indicates an expected call of GetFeePolicy.
+func (mr *MockRepositoryProviderMockRecorder) GetFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeePolicy", reflect.TypeOf((*MockRepositoryProvider)(nil).GetFeePolicy), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockRepositoryProvider) GetIdempotencyKey(arg0 context.Context, arg1, arg2 string) (models.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockRepositoryProviderMockRecorder) GetIdempotencyKey(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockRepositoryProvider)(nil).GetIdempotencyKey), arg0, arg1, arg2)
+}
+
+// GetMigrationStatus mocks base method.
+func (m *MockRepositoryProvider) GetMigrationStatus(arg0 context.Context) (models.MigrationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrationStatus", arg0)
+	ret0, _ := ret[0].(models.MigrationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigrationStatus indicates an expected call of GetMigrationStatus.
+func (mr *MockRepositoryProviderMockRecorder) GetMigrationStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrationStatus", reflect.TypeOf((*MockRepositoryProvider)(nil).GetMigrationStatus), arg0)
+}
+
+// GetNotification mocks base method.
+func (m *MockRepositoryProvider) GetNotification(arg0 context.Context, arg1 int64) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockRepositoryProviderMockRecorder) GetNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockRepositoryProvider)(nil).GetNotification), arg0, arg1)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockRepositoryProvider) GetNotificationPreferences(arg0 context.Context, arg1 string) (models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockRepositoryProviderMockRecorder) GetNotificationPreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockRepositoryProvider)(nil).GetNotificationPreferences), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockRepositoryProvider) GetScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockRepositoryProviderMockRecorder) GetScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockRepositoryProvider)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockRepositoryProvider) GetSession(arg0 context.Context, arg1 uuid.UUID) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockRepositoryProviderMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockRepositoryProvider)(nil).GetSession), arg0, arg1)
+}
+
+// GetStandingOrder mocks base method.
+func (m *MockRepositoryProvider) GetStandingOrder(arg0 context.Context, arg1 int64) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStandingOrder indicates an expected call of GetStandingOrder.
+func (mr *MockRepositoryProviderMockRecorder) GetStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandingOrder", reflect.TypeOf((*MockRepositoryProvider)(nil).GetStandingOrder), arg0, arg1)
+}
+
+// GetStatementDocument mocks base method.
+func (m *MockRepositoryProvider) GetStatementDocument(arg0 context.Context, arg1 int64) (models.StatementDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatementDocument", arg0, arg1)
+	ret0, _ := ret[0].(models.StatementDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatementDocument indicates an expected call of GetStatementDocument.
+func (mr *MockRepositoryProviderMockRecorder) GetStatementDocument(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementDocument", reflect.TypeOf((*MockRepositoryProvider)(nil).GetStatementDocument), arg0, arg1)
+}
+
+// GetTransaction mocks base method.
+func (m *MockRepositoryProvider) GetTransaction(arg0 context.Context, arg1 int64) (models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
+	ret0, _ := ret[0].(models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockRepositoryProviderMockRecorder) GetTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockRepositoryProvider)(nil).GetTransaction), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockRepositoryProvider) GetUser(arg0 context.Context, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockRepositoryProviderMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepositoryProvider)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockRepositoryProvider) GetUserByEmail(arg0 context.Context, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockRepositoryProviderMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockRepositoryProvider)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetVerifyEmail mocks base method.
+func (m *MockRepositoryProvider) GetVerifyEmail(arg0 context.Context, arg1 int64) (models.VerifyEmails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyEmails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVerifyEmail indicates an expected call of GetVerifyEmail.
+func (mr *MockRepositoryProviderMockRecorder) GetVerifyEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerifyEmail", reflect.TypeOf((*MockRepositoryProvider)(nil).GetVerifyEmail), arg0, arg1)
+}
+
+// GetWebhook mocks base method.
+func (m *MockRepositoryProvider) GetWebhook(arg0 context.Context, arg1 int64) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0, arg1)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockRepositoryProviderMockRecorder) GetWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockRepositoryProvider)(nil).GetWebhook), arg0, arg1)
+}
+
+// HasOtherSessionFromDevice mocks base method.
+func (m *MockRepositoryProvider) HasOtherSessionFromDevice(arg0 context.Context, arg1, arg2 string, arg3 uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasOtherSessionFromDevice", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasOtherSessionFromDevice indicates an expected call of HasOtherSessionFromDevice.
+func (mr *MockRepositoryProviderMockRecorder) HasOtherSessionFromDevice(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasOtherSessionFromDevice", reflect.TypeOf((*MockRepositoryProvider)(nil).HasOtherSessionFromDevice), arg0, arg1, arg2, arg3)
+}
+
+// ListAccounts mocks base method.
+func (m *MockRepositoryProvider) ListAccounts(arg0 context.Context, arg1 models.ListAccountsParams) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockRepositoryProviderMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockRepositoryProvider)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAuditLogs mocks base method.
+func (m *MockRepositoryProvider) ListAuditLogs(arg0 context.Context, arg1 models.ListAuditLogsParams) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockRepositoryProviderMockRecorder) ListAuditLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockRepositoryProvider)(nil).ListAuditLogs), arg0, arg1)
+}
+
+// ListBeneficiaries mocks base method.
+func (m *MockRepositoryProvider) ListBeneficiaries(arg0 context.Context, arg1 models.ListBeneficiariesParams) ([]models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBeneficiaries", arg0, arg1)
+	ret0, _ := ret[0].([]models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBeneficiaries indicates an expected call of ListBeneficiaries.
+func (mr *MockRepositoryProviderMockRecorder) ListBeneficiaries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBeneficiaries", reflect.TypeOf((*MockRepositoryProvider)(nil).ListBeneficiaries), arg0, arg1)
+}
+
+// ListCurrencies mocks base method.
+func (m *MockRepositoryProvider) ListCurrencies(arg0 context.Context) ([]models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrencies", arg0)
+	ret0, _ := ret[0].([]models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrencies indicates an expected call of ListCurrencies.
+func (mr *MockRepositoryProviderMockRecorder) ListCurrencies(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrencies", reflect.TypeOf((*MockRepositoryProvider)(nil).ListCurrencies), arg0)
+}
+
+// ListDueStandingOrders mocks base method.
+func (m *MockRepositoryProvider) ListDueStandingOrders(arg0 context.Context, arg1 time.Time, arg2 int32) ([]models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueStandingOrders", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueStandingOrders indicates an expected call of ListDueStandingOrders.
+func (mr *MockRepositoryProviderMockRecorder) ListDueStandingOrders(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueStandingOrders", reflect.TypeOf((*MockRepositoryProvider)(nil).ListDueStandingOrders), arg0, arg1, arg2)
+}
+
+// ListEntries mocks base method.
+func (m *MockRepositoryProvider) ListEntries(arg0 context.Context, arg1 models.ListEntriesParams) ([]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockRepositoryProviderMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockRepositoryProvider)(nil).ListEntries), arg0, arg1)
+}
+
+// ListEntriesBetween mocks base method.
+func (m *MockRepositoryProvider) ListEntriesBetween(arg0 context.Context, arg1 int64, arg2, arg3 time.Time) ([]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntriesBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntriesBetween indicates an expected call of ListEntriesBetween.
+func (mr *MockRepositoryProviderMockRecorder) ListEntriesBetween(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesBetween", reflect.TypeOf((*MockRepositoryProvider)(nil).ListEntriesBetween), arg0, arg1, arg2, arg3)
+}
+
+// ListInterestAccounts mocks base method.
+func (m *MockRepositoryProvider) ListInterestAccounts(arg0 context.Context, arg1 models.ListInterestAccountsParams) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccounts indicates an expected call of ListInterestAccounts.
+func (mr *MockRepositoryProviderMockRecorder) ListInterestAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccounts", reflect.TypeOf((*MockRepositoryProvider)(nil).ListInterestAccounts), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockRepositoryProvider) ListNotifications(arg0 context.Context, arg1 models.ListNotificationsParams) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockRepositoryProviderMockRecorder) ListNotifications(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockRepositoryProvider)(nil).ListNotifications), arg0, arg1)
+}
+
+// ListStandingOrderExecutions mocks base method.
+func (m *MockRepositoryProvider) ListStandingOrderExecutions(arg0 context.Context, arg1 int64) ([]models.StandingOrderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStandingOrderExecutions", arg0, arg1)
+	ret0, _ := ret[0].([]models.StandingOrderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStandingOrderExecutions indicates an expected call of ListStandingOrderExecutions.
+func (mr *MockRepositoryProviderMockRecorder) ListStandingOrderExecutions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStandingOrderExecutions", reflect.TypeOf((*MockRepositoryProvider)(nil).ListStandingOrderExecutions), arg0, arg1)
+}
+
+// ListStandingOrders mocks base method.
+func (m *MockRepositoryProvider) ListStandingOrders(arg0 context.Context, arg1 models.ListStandingOrdersParams) ([]models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStandingOrders", arg0, arg1)
+	ret0, _ := ret[0].([]models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStandingOrders indicates an expected call of ListStandingOrders.
+func (mr *MockRepositoryProviderMockRecorder) ListStandingOrders(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStandingOrders", reflect.TypeOf((*MockRepositoryProvider)(nil).ListStandingOrders), arg0, arg1)
+}
+
+// ListStatementDocuments mocks base method.
+func (m *MockRepositoryProvider) ListStatementDocuments(arg0 context.Context, arg1 models.ListStatementDocumentsParams) ([]models.StatementDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatementDocuments", arg0, arg1)
+	ret0, _ := ret[0].([]models.StatementDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatementDocuments indicates an expected call of ListStatementDocuments.
+func (mr *MockRepositoryProviderMockRecorder) ListStatementDocuments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementDocuments", reflect.TypeOf((*MockRepositoryProvider)(nil).ListStatementDocuments), arg0, arg1)
+}
+
+// ListSubscribedWebhooks mocks base method.
+func (m *MockRepositoryProvider) ListSubscribedWebhooks(arg0 context.Context, arg1 []string, arg2 string) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscribedWebhooks", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscribedWebhooks indicates an expected call of ListSubscribedWebhooks.
+func (mr *MockRepositoryProviderMockRecorder) ListSubscribedWebhooks(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscribedWebhooks", reflect.TypeOf((*MockRepositoryProvider)(nil).ListSubscribedWebhooks), arg0, arg1, arg2)
+}
+
+// ListTransactions mocks base method.
+func (m *MockRepositoryProvider) ListTransactions(arg0 context.Context, arg1, arg2, arg3, arg4 int64) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockRepositoryProviderMockRecorder) ListTransactions(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockRepositoryProvider)(nil).ListTransactions), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListTransactionsBetween mocks base method.
+func (m *MockRepositoryProvider) ListTransactionsBetween(arg0 context.Context, arg1 int64, arg2, arg3 time.Time) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactionsBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactionsBetween indicates an expected call of ListTransactionsBetween.
+func (mr *MockRepositoryProviderMockRecorder) ListTransactionsBetween(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactionsBetween", reflect.TypeOf((*MockRepositoryProvider)(nil).ListTransactionsBetween), arg0, arg1, arg2, arg3)
+}
+
+// ListTransfers mocks base method.
+func (m *MockRepositoryProvider) ListTransfers(arg0 context.Context, arg1 models.ListTransfersParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockRepositoryProviderMockRecorder) ListTransfers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockRepositoryProvider)(nil).ListTransfers), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockRepositoryProvider) ListUsers(arg0 context.Context, arg1, arg2 int32) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockRepositoryProviderMockRecorder) ListUsers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockRepositoryProvider)(nil).ListUsers), arg0, arg1, arg2)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockRepositoryProvider) ListWebhooks(arg0 context.Context, arg1 models.ListWebhooksParams) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockRepositoryProviderMockRecorder) ListWebhooks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockRepositoryProvider)(nil).ListWebhooks), arg0, arg1)
+}
+
+// MarkNotificationRead mocks base method.
+func (m *MockRepositoryProvider) MarkNotificationRead(arg0 context.Context, arg1 int64) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockRepositoryProviderMockRecorder) MarkNotificationRead(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockRepositoryProvider)(nil).MarkNotificationRead), arg0, arg1)
+}
+
+// PauseStandingOrder mocks base method.
+func (m *MockRepositoryProvider) PauseStandingOrder(arg0 context.Context, arg1 int64) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseStandingOrder indicates an expected call of PauseStandingOrder.
+func (mr *MockRepositoryProviderMockRecorder) PauseStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseStandingOrder", reflect.TypeOf((*MockRepositoryProvider)(nil).PauseStandingOrder), arg0, arg1)
+}
+
+// PostAccruedInterest mocks base method.
+func (m *MockRepositoryProvider) PostAccruedInterest(arg0 context.Context, arg1 int64, arg2 time.Time) (models.Account, models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostAccruedInterest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(models.Entry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PostAccruedInterest indicates an expected call of PostAccruedInterest.
+func (mr *MockRepositoryProviderMockRecorder) PostAccruedInterest(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostAccruedInterest", reflect.TypeOf((*MockRepositoryProvider)(nil).PostAccruedInterest), arg0, arg1, arg2)
+}
+
+// PurgeDeletedAccounts mocks base method.
+func (m *MockRepositoryProvider) PurgeDeletedAccounts(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedAccounts", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedAccounts indicates an expected call of PurgeDeletedAccounts.
+func (mr *MockRepositoryProviderMockRecorder) PurgeDeletedAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedAccounts", reflect.TypeOf((*MockRepositoryProvider)(nil).PurgeDeletedAccounts), arg0, arg1)
+}
+
+// RecordStandingOrderRunTx mocks base method.
+func (m *MockRepositoryProvider) RecordStandingOrderRunTx(arg0 context.Context, arg1 models.RecordStandingOrderRunParams) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordStandingOrderRunTx", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordStandingOrderRunTx indicates an expected call of RecordStandingOrderRunTx.
+func (mr *MockRepositoryProviderMockRecorder) RecordStandingOrderRunTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordStandingOrderRunTx", reflect.TypeOf((*MockRepositoryProvider)(nil).RecordStandingOrderRunTx), arg0, arg1)
+}
+
+// RecordWebhookDelivery mocks base method.
+func (m *MockRepositoryProvider) RecordWebhookDelivery(arg0 context.Context, arg1 int64, arg2 bool, arg3 int32) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebhookDelivery", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordWebhookDelivery indicates an expected call of RecordWebhookDelivery.
+func (mr *MockRepositoryProviderMockRecorder) RecordWebhookDelivery(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebhookDelivery", reflect.TypeOf((*MockRepositoryProvider)(nil).RecordWebhookDelivery), arg0, arg1, arg2, arg3)
+}
+
+// RelayOutboxTx mocks base method.
+func (m *MockRepositoryProvider) RelayOutboxTx(arg0 context.Context, arg1 int32, arg2 func(models.OutboxMessage) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayOutboxTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelayOutboxTx indicates an expected call of RelayOutboxTx.
+func (mr *MockRepositoryProviderMockRecorder) RelayOutboxTx(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayOutboxTx", reflect.TypeOf((*MockRepositoryProvider)(nil).RelayOutboxTx), arg0, arg1, arg2)
+}
+
+// RequestStatementTx mocks base method.
+func (m *MockRepositoryProvider) RequestStatementTx(arg0 context.Context, arg1 models.RequestStatementTxParams) (models.StatementDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestStatementTx", arg0, arg1)
+	ret0, _ := ret[0].(models.StatementDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestStatementTx indicates an expected call of RequestStatementTx.
+func (mr *MockRepositoryProviderMockRecorder) RequestStatementTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestStatementTx", reflect.TypeOf((*MockRepositoryProvider)(nil).RequestStatementTx), arg0, arg1)
+}
+
+// ResumeStandingOrder mocks base method.
+func (m *MockRepositoryProvider) ResumeStandingOrder(arg0 context.Context, arg1 int64, arg2 time.Time) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeStandingOrder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeStandingOrder indicates an expected call of ResumeStandingOrder.
+func (mr *MockRepositoryProviderMockRecorder) ResumeStandingOrder(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeStandingOrder", reflect.TypeOf((*MockRepositoryProvider)(nil).ResumeStandingOrder), arg0, arg1, arg2)
+}
+
+// ReverseTransferTx mocks base method.
+func (m *MockRepositoryProvider) ReverseTransferTx(arg0 context.Context, arg1 models.ReverseTransferTxParams) (models.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(models.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransferTx indicates an expected call of ReverseTransferTx.
+func (mr *MockRepositoryProviderMockRecorder) ReverseTransferTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTx", reflect.TypeOf((*MockRepositoryProvider)(nil).ReverseTransferTx), arg0, arg1)
+}
+
+// SearchTransactions mocks base method.
+func (m *MockRepositoryProvider) SearchTransactions(arg0 context.Context, arg1 models.SearchTransactionsParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTransactions indicates an expected call of SearchTransactions.
+func (mr *MockRepositoryProviderMockRecorder) SearchTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTransactions", reflect.TypeOf((*MockRepositoryProvider)(nil).SearchTransactions), arg0, arg1)
+}
+
+// SetEntryCategory mocks base method.
+func (m *MockRepositoryProvider) SetEntryCategory(arg0 context.Context, arg1 models.SetEntryCategoryParams) (models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEntryCategory", arg0, arg1)
+	ret0, _ := ret[0].(models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEntryCategory indicates an expected call of SetEntryCategory.
+func (mr *MockRepositoryProviderMockRecorder) SetEntryCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEntryCategory", reflect.TypeOf((*MockRepositoryProvider)(nil).SetEntryCategory), arg0, arg1)
+}
+
+// SetOverdraftLimitTx mocks base method.
+func (m *MockRepositoryProvider) SetOverdraftLimitTx(arg0 context.Context, arg1 models.SetOverdraftLimitParams) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimitTx", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimitTx indicates an expected call of SetOverdraftLimitTx.
+func (mr *MockRepositoryProviderMockRecorder) SetOverdraftLimitTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimitTx", reflect.TypeOf((*MockRepositoryProvider)(nil).SetOverdraftLimitTx), arg0, arg1)
+}
+
+// SumEntriesSince mocks base method.
+func (m *MockRepositoryProvider) SumEntriesSince(arg0 context.Context, arg1 int64, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesSince", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesSince indicates an expected call of SumEntriesSince.
+func (mr *MockRepositoryProviderMockRecorder) SumEntriesSince(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesSince", reflect.TypeOf((*MockRepositoryProvider)(nil).SumEntriesSince), arg0, arg1, arg2)
+}
+
+// SummarizeEntries mocks base method.
+func (m *MockRepositoryProvider) SummarizeEntries(arg0 context.Context, arg1 models.SummarizeEntriesParams) (models.AccountSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SummarizeEntries", arg0, arg1)
+	ret0, _ := ret[0].(models.AccountSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SummarizeEntries indicates an expected call of SummarizeEntries.
+func (mr *MockRepositoryProviderMockRecorder) SummarizeEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeEntries", reflect.TypeOf((*MockRepositoryProvider)(nil).SummarizeEntries), arg0, arg1)
+}
+
+// TransferTx mocks base method.
+func (m *MockRepositoryProvider) TransferTx(arg0 context.Context, arg1 models.TransferTxParams) (models.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
+	ret0, _ := ret[0].(models.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTx indicates an expected call of TransferTx.
+func (mr *MockRepositoryProviderMockRecorder) TransferTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockRepositoryProvider)(nil).TransferTx), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockRepositoryProvider) UpdateAccount(arg0 context.Context, arg1, arg2 int64) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockRepositoryProviderMockRecorder) UpdateAccount(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateAccount), arg0, arg1, arg2)
+}
+
+// UpdateAccountDetails mocks base method.
+func (m *MockRepositoryProvider) UpdateAccountDetails(arg0 context.Context, arg1 models.UpdateAccountDetailsParams) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountDetails", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountDetails indicates an expected call of UpdateAccountDetails.
+func (mr *MockRepositoryProviderMockRecorder) UpdateAccountDetails(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountDetails", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateAccountDetails), arg0, arg1)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockRepositoryProvider) UpdateNotificationPreferences(arg0 context.Context, arg1 models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockRepositoryProviderMockRecorder) UpdateNotificationPreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateNotificationPreferences), arg0, arg1)
+}
+
+// UpdateUser mocks base method.
+func (m *MockRepositoryProvider) UpdateUser(arg0 context.Context, arg1 models.UpdateUserParams) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockRepositoryProviderMockRecorder) UpdateUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateUser), arg0, arg1)
+}
+
+// UpdateUserTx mocks base method.
+func (m *MockRepositoryProvider) UpdateUserTx(arg0 context.Context, arg1 models.UpdateUserTxParams) (models.UpdateUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(models.UpdateUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTx indicates an expected call of UpdateUserTx.
+func (mr *MockRepositoryProviderMockRecorder) UpdateUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTx", reflect.TypeOf((*MockRepositoryProvider)(nil).UpdateUserTx), arg0, arg1)
+}
+
+// UpsertExchangeRate mocks base method.
+func (m *MockRepositoryProvider) UpsertExchangeRate(arg0 context.Context, arg1 models.ExchangeRate) (models.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertExchangeRate", arg0, arg1)
+	ret0, _ := ret[0].(models.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertExchangeRate indicates an expected call of UpsertExchangeRate.
+func (mr *MockRepositoryProviderMockRecorder) UpsertExchangeRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertExchangeRate", reflect.TypeOf((*MockRepositoryProvider)(nil).UpsertExchangeRate), arg0, arg1)
+}
+
+// UpsertFeePolicy mocks base method.
+func (m *MockRepositoryProvider) UpsertFeePolicy(arg0 context.Context, arg1 models.FeePolicy) (models.FeePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(models.FeePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertFeePolicy indicates an expected call of UpsertFeePolicy.
+func (mr *MockRepositoryProviderMockRecorder) UpsertFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertFeePolicy", reflect.TypeOf((*MockRepositoryProvider)(nil).UpsertFeePolicy), arg0, arg1)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockRepositoryProvider) VerifyEmailTx(arg0 context.Context, arg1 models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyEmailTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -384,3 +1567,18 @@ func (mr *MockRepositoryProviderMockRecorder) VerifyEmailTx(arg0, arg1 any) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockRepositoryProvider)(nil).VerifyEmailTx), arg0, arg1)
 }
+
+// VerifyPhoneTx mocks base method.
+func (m *MockRepositoryProvider) VerifyPhoneTx(arg0 context.Context, arg1 models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPhoneTx", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyPhoneTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPhoneTx indicates an expected call of VerifyPhoneTx.
+func (mr *MockRepositoryProviderMockRecorder) VerifyPhoneTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPhoneTx", reflect.TypeOf((*MockRepositoryProvider)(nil).VerifyPhoneTx), arg0, arg1)
+}
@@ -12,6 +12,7 @@ package mockedproviders
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	models "github.com/zde37/Swift_Bank/models"
@@ -41,19 +42,139 @@ func (m *MockServiceProvider) EXPECT() *MockServiceProviderMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterest mocks base method.
+func (m *MockServiceProvider) AccrueInterest(arg0 context.Context, arg1 models.AccrueInterestParams) (models.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterest", arg0, arg1)
+	ret0, _ := ret[0].(models.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterest indicates an expected call of AccrueInterest.
+func (mr *MockServiceProviderMockRecorder) AccrueInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterest", reflect.TypeOf((*MockServiceProvider)(nil).AccrueInterest), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
-func (m *MockServiceProvider) AddAccountBalance(arg0 context.Context, arg1 int64, arg2 float64) (models.Account, error) {
+func (m *MockServiceProvider) AddAccountBalance(arg0 context.Context, arg1 models.AddAccountBalanceParams) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddAccountBalance indicates an expected call of AddAccountBalance.
-func (mr *MockServiceProviderMockRecorder) AddAccountBalance(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockServiceProviderMockRecorder) AddAccountBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockServiceProvider)(nil).AddAccountBalance), arg0, arg1)
+}
+
+// AddBeneficiary mocks base method.
+func (m *MockServiceProvider) AddBeneficiary(arg0 context.Context, arg1 models.Beneficiary) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBeneficiary indicates an expected call of AddBeneficiary.
+func (mr *MockServiceProviderMockRecorder) AddBeneficiary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBeneficiary", reflect.TypeOf((*MockServiceProvider)(nil).AddBeneficiary), arg0, arg1)
+}
+
+// AddCurrency mocks base method.
+func (m *MockServiceProvider) AddCurrency(arg0 context.Context, arg1 models.Currency) (models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCurrency", arg0, arg1)
+	ret0, _ := ret[0].(models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCurrency indicates an expected call of AddCurrency.
+func (mr *MockServiceProviderMockRecorder) AddCurrency(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCurrency", reflect.TypeOf((*MockServiceProvider)(nil).AddCurrency), arg0, arg1)
+}
+
+// BulkCreateAccountsTx mocks base method.
+func (m *MockServiceProvider) BulkCreateAccountsTx(arg0 context.Context, arg1 models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateAccountsTx", arg0, arg1)
+	ret0, _ := ret[0].([]models.BulkAccountResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkCreateAccountsTx indicates an expected call of BulkCreateAccountsTx.
+func (mr *MockServiceProviderMockRecorder) BulkCreateAccountsTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateAccountsTx", reflect.TypeOf((*MockServiceProvider)(nil).BulkCreateAccountsTx), arg0, arg1)
+}
+
+// CancelScheduledTransfer mocks base method.
+func (m *MockServiceProvider) CancelScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockServiceProviderMockRecorder) CancelScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockServiceProvider)(nil).CancelScheduledTransfer), arg0, arg1)
+}
+
+// CountExportRows mocks base method.
+func (m *MockServiceProvider) CountExportRows(arg0 context.Context, arg1 models.ExportTransactionsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExportRows", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExportRows indicates an expected call of CountExportRows.
+func (mr *MockServiceProviderMockRecorder) CountExportRows(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockServiceProvider)(nil).AddAccountBalance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExportRows", reflect.TypeOf((*MockServiceProvider)(nil).CountExportRows), arg0, arg1)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockServiceProvider) CountUnreadNotifications(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockServiceProviderMockRecorder) CountUnreadNotifications(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockServiceProvider)(nil).CountUnreadNotifications), arg0, arg1)
+}
+
+// CountUsersWithPhoneNumber mocks base method.
+func (m *MockServiceProvider) CountUsersWithPhoneNumber(arg0 context.Context, arg1, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsersWithPhoneNumber", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsersWithPhoneNumber indicates an expected call of CountUsersWithPhoneNumber.
+func (mr *MockServiceProviderMockRecorder) CountUsersWithPhoneNumber(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsersWithPhoneNumber", reflect.TypeOf((*MockServiceProvider)(nil).CountUsersWithPhoneNumber), arg0, arg1, arg2)
 }
 
 // CreateAccount mocks base method.
@@ -71,6 +192,21 @@ func (mr *MockServiceProviderMockRecorder) CreateAccount(arg0, arg1, arg2 any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockServiceProvider)(nil).CreateAccount), arg0, arg1, arg2)
 }
 
+// CreateAuditLog mocks base method.
+func (m *MockServiceProvider) CreateAuditLog(arg0 context.Context, arg1 models.CreateAuditLogParams) (models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockServiceProviderMockRecorder) CreateAuditLog(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockServiceProvider)(nil).CreateAuditLog), arg0, arg1)
+}
+
 // CreateEntry mocks base method.
 func (m *MockServiceProvider) CreateEntry(arg0 context.Context, arg1 models.Entry) (models.Entry, error) {
 	m.ctrl.T.Helper()
@@ -86,6 +222,36 @@ func (mr *MockServiceProviderMockRecorder) CreateEntry(arg0, arg1 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockServiceProvider)(nil).CreateEntry), arg0, arg1)
 }
 
+// CreateNotification mocks base method.
+func (m *MockServiceProvider) CreateNotification(arg0 context.Context, arg1 models.Notification) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockServiceProviderMockRecorder) CreateNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockServiceProvider)(nil).CreateNotification), arg0, arg1)
+}
+
+// CreateStandingOrder mocks base method.
+func (m *MockServiceProvider) CreateStandingOrder(arg0 context.Context, arg1 models.StandingOrder) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStandingOrder indicates an expected call of CreateStandingOrder.
+func (mr *MockServiceProviderMockRecorder) CreateStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).CreateStandingOrder), arg0, arg1)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockServiceProvider) CreateTransaction(arg0 context.Context, arg1 models.Transaction) (models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +312,36 @@ func (mr *MockServiceProviderMockRecorder) CreateVerifyEmail(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyEmail", reflect.TypeOf((*MockServiceProvider)(nil).CreateVerifyEmail), arg0, arg1)
 }
 
+// CreateVerifyPhone mocks base method.
+func (m *MockServiceProvider) CreateVerifyPhone(arg0 context.Context, arg1 models.VerifyPhones) (models.VerifyPhones, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerifyPhone", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyPhones)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVerifyPhone indicates an expected call of CreateVerifyPhone.
+func (mr *MockServiceProviderMockRecorder) CreateVerifyPhone(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyPhone", reflect.TypeOf((*MockServiceProvider)(nil).CreateVerifyPhone), arg0, arg1)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockServiceProvider) CreateWebhook(arg0 context.Context, arg1 models.Webhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockServiceProviderMockRecorder) CreateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockServiceProvider)(nil).CreateWebhook), arg0, arg1)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockServiceProvider) DeleteAccount(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -160,6 +356,107 @@ func (mr *MockServiceProviderMockRecorder) DeleteAccount(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockServiceProvider)(nil).DeleteAccount), arg0, arg1)
 }
 
+// DeleteBeneficiary mocks base method.
+func (m *MockServiceProvider) DeleteBeneficiary(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBeneficiary indicates an expected call of DeleteBeneficiary.
+func (mr *MockServiceProviderMockRecorder) DeleteBeneficiary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBeneficiary", reflect.TypeOf((*MockServiceProvider)(nil).DeleteBeneficiary), arg0, arg1)
+}
+
+// DeleteStandingOrder mocks base method.
+func (m *MockServiceProvider) DeleteStandingOrder(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStandingOrder indicates an expected call of DeleteStandingOrder.
+func (mr *MockServiceProviderMockRecorder) DeleteStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).DeleteStandingOrder), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockServiceProvider) DeleteWebhook(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockServiceProviderMockRecorder) DeleteWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockServiceProvider)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// DisableCurrency mocks base method.
+func (m *MockServiceProvider) DisableCurrency(arg0 context.Context, arg1 string) (models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableCurrency", arg0, arg1)
+	ret0, _ := ret[0].(models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableCurrency indicates an expected call of DisableCurrency.
+func (mr *MockServiceProviderMockRecorder) DisableCurrency(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableCurrency", reflect.TypeOf((*MockServiceProvider)(nil).DisableCurrency), arg0, arg1)
+}
+
+// ExecuteScheduledTransfer mocks base method.
+func (m *MockServiceProvider) ExecuteScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteScheduledTransfer indicates an expected call of ExecuteScheduledTransfer.
+func (mr *MockServiceProviderMockRecorder) ExecuteScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScheduledTransfer", reflect.TypeOf((*MockServiceProvider)(nil).ExecuteScheduledTransfer), arg0, arg1)
+}
+
+// ExecuteStandingOrder mocks base method.
+func (m *MockServiceProvider) ExecuteStandingOrder(arg0 context.Context, arg1 models.StandingOrder) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteStandingOrder indicates an expected call of ExecuteStandingOrder.
+func (mr *MockServiceProviderMockRecorder) ExecuteStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).ExecuteStandingOrder), arg0, arg1)
+}
+
+// ExportTransactions mocks base method.
+func (m *MockServiceProvider) ExportTransactions(arg0 context.Context, arg1 models.ExportTransactionsParams, arg2 func(models.LedgerRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTransactions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportTransactions indicates an expected call of ExportTransactions.
+func (mr *MockServiceProviderMockRecorder) ExportTransactions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTransactions", reflect.TypeOf((*MockServiceProvider)(nil).ExportTransactions), arg0, arg1, arg2)
+}
+
 // FetchSession mocks base method.
 func (m *MockServiceProvider) FetchSession(arg0 context.Context, arg1 uuid.UUID) (models.Session, error) {
 	m.ctrl.T.Helper()
@@ -205,6 +502,81 @@ func (mr *MockServiceProviderMockRecorder) GetAccountForUpdate(arg0, arg1 any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockServiceProvider)(nil).GetAccountForUpdate), arg0, arg1)
 }
 
+// GetAccountIncludingDeleted mocks base method.
+func (m *MockServiceProvider) GetAccountIncludingDeleted(arg0 context.Context, arg1 int64) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountIncludingDeleted", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountIncludingDeleted indicates an expected call of GetAccountIncludingDeleted.
+func (mr *MockServiceProviderMockRecorder) GetAccountIncludingDeleted(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountIncludingDeleted", reflect.TypeOf((*MockServiceProvider)(nil).GetAccountIncludingDeleted), arg0, arg1)
+}
+
+// GetAccountSummary mocks base method.
+func (m *MockServiceProvider) GetAccountSummary(arg0 context.Context, arg1 models.GetAccountSummaryParams) (models.AccountSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountSummary", arg0, arg1)
+	ret0, _ := ret[0].(models.AccountSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountSummary indicates an expected call of GetAccountSummary.
+func (mr *MockServiceProviderMockRecorder) GetAccountSummary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountSummary", reflect.TypeOf((*MockServiceProvider)(nil).GetAccountSummary), arg0, arg1)
+}
+
+// GetBalance mocks base method.
+func (m *MockServiceProvider) GetBalance(arg0 context.Context, arg1 models.GetBalanceParams) (models.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", arg0, arg1)
+	ret0, _ := ret[0].(models.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockServiceProviderMockRecorder) GetBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockServiceProvider)(nil).GetBalance), arg0, arg1)
+}
+
+// GetBeneficiary mocks base method.
+func (m *MockServiceProvider) GetBeneficiary(arg0 context.Context, arg1 int64) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiary indicates an expected call of GetBeneficiary.
+func (mr *MockServiceProviderMockRecorder) GetBeneficiary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiary", reflect.TypeOf((*MockServiceProvider)(nil).GetBeneficiary), arg0, arg1)
+}
+
+// GetBeneficiaryByIBAN mocks base method.
+func (m *MockServiceProvider) GetBeneficiaryByIBAN(arg0 context.Context, arg1, arg2 string) (models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaryByIBAN", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaryByIBAN indicates an expected call of GetBeneficiaryByIBAN.
+func (mr *MockServiceProviderMockRecorder) GetBeneficiaryByIBAN(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaryByIBAN", reflect.TypeOf((*MockServiceProvider)(nil).GetBeneficiaryByIBAN), arg0, arg1, arg2)
+}
+
 // GetEntry mocks base method.
 func (m *MockServiceProvider) GetEntry(arg0 context.Context, arg1 int64) (models.Entry, error) {
 	m.ctrl.T.Helper()
@@ -220,6 +592,96 @@ func (mr *MockServiceProviderMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockServiceProvider)(nil).GetEntry), arg0, arg1)
 }
 
+// GetMigrationStatus mocks base method.
+func (m *MockServiceProvider) GetMigrationStatus(arg0 context.Context) (models.MigrationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrationStatus", arg0)
+	ret0, _ := ret[0].(models.MigrationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigrationStatus indicates an expected call of GetMigrationStatus.
+func (mr *MockServiceProviderMockRecorder) GetMigrationStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrationStatus", reflect.TypeOf((*MockServiceProvider)(nil).GetMigrationStatus), arg0)
+}
+
+// GetNotification mocks base method.
+func (m *MockServiceProvider) GetNotification(arg0 context.Context, arg1 int64) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockServiceProviderMockRecorder) GetNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockServiceProvider)(nil).GetNotification), arg0, arg1)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockServiceProvider) GetNotificationPreferences(arg0 context.Context, arg1 string) (models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockServiceProviderMockRecorder) GetNotificationPreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockServiceProvider)(nil).GetNotificationPreferences), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockServiceProvider) GetScheduledTransfer(arg0 context.Context, arg1 int64) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockServiceProviderMockRecorder) GetScheduledTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockServiceProvider)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetStandingOrder mocks base method.
+func (m *MockServiceProvider) GetStandingOrder(arg0 context.Context, arg1 int64) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStandingOrder indicates an expected call of GetStandingOrder.
+func (mr *MockServiceProviderMockRecorder) GetStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).GetStandingOrder), arg0, arg1)
+}
+
+// GetStatement mocks base method.
+func (m *MockServiceProvider) GetStatement(arg0 context.Context, arg1 models.GetStatementParams) (models.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", arg0, arg1)
+	ret0, _ := ret[0].(models.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockServiceProviderMockRecorder) GetStatement(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockServiceProvider)(nil).GetStatement), arg0, arg1)
+}
+
 // GetTransaction mocks base method.
 func (m *MockServiceProvider) GetTransaction(arg0 context.Context, arg1 int64) (models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -250,34 +712,184 @@ func (mr *MockServiceProviderMockRecorder) GetUser(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockServiceProvider)(nil).GetUser), arg0, arg1)
 }
 
+// GetUserByEmail mocks base method.
+func (m *MockServiceProvider) GetUserByEmail(arg0 context.Context, arg1 string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockServiceProviderMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockServiceProvider)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetWebhook mocks base method.
+func (m *MockServiceProvider) GetWebhook(arg0 context.Context, arg1 int64) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0, arg1)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockServiceProviderMockRecorder) GetWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockServiceProvider)(nil).GetWebhook), arg0, arg1)
+}
+
 // ListAccounts mocks base method.
-func (m *MockServiceProvider) ListAccounts(arg0 context.Context, arg1 string, arg2, arg3 int32) ([]models.Account, error) {
+func (m *MockServiceProvider) ListAccounts(arg0 context.Context, arg1 models.ListAccountsParams) ([]models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
 	ret0, _ := ret[0].([]models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockServiceProviderMockRecorder) ListAccounts(arg0, arg1, arg2, arg3 any) *gomock.Call {
+func (mr *MockServiceProviderMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockServiceProvider)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAuditLogs mocks base method.
+func (m *MockServiceProvider) ListAuditLogs(arg0 context.Context, arg1 models.ListAuditLogsParams) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockServiceProviderMockRecorder) ListAuditLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockServiceProvider)(nil).ListAuditLogs), arg0, arg1)
+}
+
+// ListBeneficiaries mocks base method.
+func (m *MockServiceProvider) ListBeneficiaries(arg0 context.Context, arg1 models.ListBeneficiariesParams) ([]models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBeneficiaries", arg0, arg1)
+	ret0, _ := ret[0].([]models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBeneficiaries indicates an expected call of ListBeneficiaries.
+func (mr *MockServiceProviderMockRecorder) ListBeneficiaries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBeneficiaries", reflect.TypeOf((*MockServiceProvider)(nil).ListBeneficiaries), arg0, arg1)
+}
+
+// ListCurrencies mocks base method.
+func (m *MockServiceProvider) ListCurrencies(arg0 context.Context) ([]models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrencies", arg0)
+	ret0, _ := ret[0].([]models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrencies indicates an expected call of ListCurrencies.
+func (mr *MockServiceProviderMockRecorder) ListCurrencies(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrencies", reflect.TypeOf((*MockServiceProvider)(nil).ListCurrencies), arg0)
+}
+
+// ListDueStandingOrders mocks base method.
+func (m *MockServiceProvider) ListDueStandingOrders(arg0 context.Context, arg1 time.Time, arg2 int32) ([]models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueStandingOrders", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueStandingOrders indicates an expected call of ListDueStandingOrders.
+func (mr *MockServiceProviderMockRecorder) ListDueStandingOrders(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockServiceProvider)(nil).ListAccounts), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueStandingOrders", reflect.TypeOf((*MockServiceProvider)(nil).ListDueStandingOrders), arg0, arg1, arg2)
 }
 
 // ListEntries mocks base method.
-func (m *MockServiceProvider) ListEntries(arg0 context.Context, arg1, arg2, arg3 int64) ([]models.Entry, error) {
+func (m *MockServiceProvider) ListEntries(arg0 context.Context, arg1 models.ListEntriesParams) ([]models.Entry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
 	ret0, _ := ret[0].([]models.Entry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListEntries indicates an expected call of ListEntries.
-func (mr *MockServiceProviderMockRecorder) ListEntries(arg0, arg1, arg2, arg3 any) *gomock.Call {
+func (mr *MockServiceProviderMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockServiceProvider)(nil).ListEntries), arg0, arg1)
+}
+
+// ListInterestAccounts mocks base method.
+func (m *MockServiceProvider) ListInterestAccounts(arg0 context.Context, arg1 models.ListInterestAccountsParams) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccounts indicates an expected call of ListInterestAccounts.
+func (mr *MockServiceProviderMockRecorder) ListInterestAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccounts", reflect.TypeOf((*MockServiceProvider)(nil).ListInterestAccounts), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockServiceProvider) ListNotifications(arg0 context.Context, arg1 models.ListNotificationsParams) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockServiceProviderMockRecorder) ListNotifications(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockServiceProvider)(nil).ListEntries), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockServiceProvider)(nil).ListNotifications), arg0, arg1)
+}
+
+// ListStandingOrders mocks base method.
+func (m *MockServiceProvider) ListStandingOrders(arg0 context.Context, arg1 models.ListStandingOrdersParams) ([]models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStandingOrders", arg0, arg1)
+	ret0, _ := ret[0].([]models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStandingOrders indicates an expected call of ListStandingOrders.
+func (mr *MockServiceProviderMockRecorder) ListStandingOrders(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStandingOrders", reflect.TypeOf((*MockServiceProvider)(nil).ListStandingOrders), arg0, arg1)
+}
+
+// ListStatementDocuments mocks base method.
+func (m *MockServiceProvider) ListStatementDocuments(arg0 context.Context, arg1 models.ListStatementDocumentsParams) ([]models.StatementDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatementDocuments", arg0, arg1)
+	ret0, _ := ret[0].([]models.StatementDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatementDocuments indicates an expected call of ListStatementDocuments.
+func (mr *MockServiceProviderMockRecorder) ListStatementDocuments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementDocuments", reflect.TypeOf((*MockServiceProvider)(nil).ListStatementDocuments), arg0, arg1)
 }
 
 // ListTransactions mocks base method.
@@ -295,6 +907,21 @@ func (mr *MockServiceProviderMockRecorder) ListTransactions(arg0, arg1, arg2, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockServiceProvider)(nil).ListTransactions), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ListTransfers mocks base method.
+func (m *MockServiceProvider) ListTransfers(arg0 context.Context, arg1 models.ListTransfersParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockServiceProviderMockRecorder) ListTransfers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockServiceProvider)(nil).ListTransfers), arg0, arg1)
+}
+
 // ListUsers mocks base method.
 func (m *MockServiceProvider) ListUsers(arg0 context.Context, arg1, arg2 int32) ([]models.User, error) {
 	m.ctrl.T.Helper()
@@ -310,6 +937,21 @@ func (mr *MockServiceProviderMockRecorder) ListUsers(arg0, arg1, arg2 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockServiceProvider)(nil).ListUsers), arg0, arg1, arg2)
 }
 
+// ListWebhooks mocks base method.
+func (m *MockServiceProvider) ListWebhooks(arg0 context.Context, arg1 models.ListWebhooksParams) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockServiceProviderMockRecorder) ListWebhooks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockServiceProvider)(nil).ListWebhooks), arg0, arg1)
+}
+
 // LoginUser mocks base method.
 func (m *MockServiceProvider) LoginUser(arg0 context.Context, arg1 models.LoginUserRequest) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -325,6 +967,21 @@ func (mr *MockServiceProviderMockRecorder) LoginUser(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockServiceProvider)(nil).LoginUser), arg0, arg1)
 }
 
+// MarkNotificationRead mocks base method.
+func (m *MockServiceProvider) MarkNotificationRead(arg0 context.Context, arg1 int64) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", arg0, arg1)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockServiceProviderMockRecorder) MarkNotificationRead(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockServiceProvider)(nil).MarkNotificationRead), arg0, arg1)
+}
+
 // NewSession mocks base method.
 func (m *MockServiceProvider) NewSession(arg0 context.Context, arg1 models.Session) (models.Session, error) {
 	m.ctrl.T.Helper()
@@ -340,6 +997,156 @@ func (mr *MockServiceProviderMockRecorder) NewSession(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSession", reflect.TypeOf((*MockServiceProvider)(nil).NewSession), arg0, arg1)
 }
 
+// PauseStandingOrder mocks base method.
+func (m *MockServiceProvider) PauseStandingOrder(arg0 context.Context, arg1 int64) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseStandingOrder indicates an expected call of PauseStandingOrder.
+func (mr *MockServiceProviderMockRecorder) PauseStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).PauseStandingOrder), arg0, arg1)
+}
+
+// PostAccruedInterest mocks base method.
+func (m *MockServiceProvider) PostAccruedInterest(arg0 context.Context, arg1 int64, arg2 time.Time) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostAccruedInterest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostAccruedInterest indicates an expected call of PostAccruedInterest.
+func (mr *MockServiceProviderMockRecorder) PostAccruedInterest(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostAccruedInterest", reflect.TypeOf((*MockServiceProvider)(nil).PostAccruedInterest), arg0, arg1, arg2)
+}
+
+// RecordWebhookDelivery mocks base method.
+func (m *MockServiceProvider) RecordWebhookDelivery(arg0 context.Context, arg1 int64, arg2 bool) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebhookDelivery", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordWebhookDelivery indicates an expected call of RecordWebhookDelivery.
+func (mr *MockServiceProviderMockRecorder) RecordWebhookDelivery(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebhookDelivery", reflect.TypeOf((*MockServiceProvider)(nil).RecordWebhookDelivery), arg0, arg1, arg2)
+}
+
+// RequestStatementTx mocks base method.
+func (m *MockServiceProvider) RequestStatementTx(arg0 context.Context, arg1 models.RequestStatementTxParams) (models.StatementDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestStatementTx", arg0, arg1)
+	ret0, _ := ret[0].(models.StatementDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestStatementTx indicates an expected call of RequestStatementTx.
+func (mr *MockServiceProviderMockRecorder) RequestStatementTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestStatementTx", reflect.TypeOf((*MockServiceProvider)(nil).RequestStatementTx), arg0, arg1)
+}
+
+// ResumeStandingOrder mocks base method.
+func (m *MockServiceProvider) ResumeStandingOrder(arg0 context.Context, arg1 int64) (models.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(models.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeStandingOrder indicates an expected call of ResumeStandingOrder.
+func (mr *MockServiceProviderMockRecorder) ResumeStandingOrder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeStandingOrder", reflect.TypeOf((*MockServiceProvider)(nil).ResumeStandingOrder), arg0, arg1)
+}
+
+// ReverseTransfer mocks base method.
+func (m *MockServiceProvider) ReverseTransfer(arg0 context.Context, arg1 models.ReverseTransferParams) (models.ReverseTransferResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.ReverseTransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransfer indicates an expected call of ReverseTransfer.
+func (mr *MockServiceProviderMockRecorder) ReverseTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransfer", reflect.TypeOf((*MockServiceProvider)(nil).ReverseTransfer), arg0, arg1)
+}
+
+// ScheduleTransferTx mocks base method.
+func (m *MockServiceProvider) ScheduleTransferTx(arg0 context.Context, arg1 models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleTransferTx indicates an expected call of ScheduleTransferTx.
+func (mr *MockServiceProviderMockRecorder) ScheduleTransferTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleTransferTx", reflect.TypeOf((*MockServiceProvider)(nil).ScheduleTransferTx), arg0, arg1)
+}
+
+// SearchTransactions mocks base method.
+func (m *MockServiceProvider) SearchTransactions(arg0 context.Context, arg1 models.SearchTransactionsParams) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTransactions indicates an expected call of SearchTransactions.
+func (mr *MockServiceProviderMockRecorder) SearchTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTransactions", reflect.TypeOf((*MockServiceProvider)(nil).SearchTransactions), arg0, arg1)
+}
+
+// SetEntryCategory mocks base method.
+func (m *MockServiceProvider) SetEntryCategory(arg0 context.Context, arg1 models.SetEntryCategoryParams) (models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEntryCategory", arg0, arg1)
+	ret0, _ := ret[0].(models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEntryCategory indicates an expected call of SetEntryCategory.
+func (mr *MockServiceProviderMockRecorder) SetEntryCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEntryCategory", reflect.TypeOf((*MockServiceProvider)(nil).SetEntryCategory), arg0, arg1)
+}
+
+// SetOverdraftLimitTx mocks base method.
+func (m *MockServiceProvider) SetOverdraftLimitTx(arg0 context.Context, arg1 models.SetOverdraftLimitParams) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimitTx", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimitTx indicates an expected call of SetOverdraftLimitTx.
+func (mr *MockServiceProviderMockRecorder) SetOverdraftLimitTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimitTx", reflect.TypeOf((*MockServiceProvider)(nil).SetOverdraftLimitTx), arg0, arg1)
+}
+
 // TransferTx mocks base method.
 func (m *MockServiceProvider) TransferTx(arg0 context.Context, arg1 models.TransferTxParams) (models.TransferTxResult, error) {
 	m.ctrl.T.Helper()
@@ -356,7 +1163,7 @@ func (mr *MockServiceProviderMockRecorder) TransferTx(arg0, arg1 any) *gomock.Ca
 }
 
 // UpdateAccount mocks base method.
-func (m *MockServiceProvider) UpdateAccount(arg0 context.Context, arg1 int64, arg2 float64) (models.Account, error) {
+func (m *MockServiceProvider) UpdateAccount(arg0 context.Context, arg1, arg2 int64) (models.Account, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1, arg2)
 	ret0, _ := ret[0].(models.Account)
@@ -370,6 +1177,36 @@ func (mr *MockServiceProviderMockRecorder) UpdateAccount(arg0, arg1, arg2 any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockServiceProvider)(nil).UpdateAccount), arg0, arg1, arg2)
 }
 
+// UpdateAccountDetails mocks base method.
+func (m *MockServiceProvider) UpdateAccountDetails(arg0 context.Context, arg1 models.UpdateAccountDetailsParams) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountDetails", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountDetails indicates an expected call of UpdateAccountDetails.
+func (mr *MockServiceProviderMockRecorder) UpdateAccountDetails(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountDetails", reflect.TypeOf((*MockServiceProvider)(nil).UpdateAccountDetails), arg0, arg1)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockServiceProvider) UpdateNotificationPreferences(arg0 context.Context, arg1 models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockServiceProviderMockRecorder) UpdateNotificationPreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockServiceProvider)(nil).UpdateNotificationPreferences), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockServiceProvider) UpdateUser(arg0 context.Context, arg1 models.UpdateUserParams) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -385,6 +1222,21 @@ func (mr *MockServiceProviderMockRecorder) UpdateUser(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockServiceProvider)(nil).UpdateUser), arg0, arg1)
 }
 
+// UpdateUserTx mocks base method.
+func (m *MockServiceProvider) UpdateUserTx(arg0 context.Context, arg1 models.UpdateUserTxParams) (models.UpdateUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(models.UpdateUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTx indicates an expected call of UpdateUserTx.
+func (mr *MockServiceProviderMockRecorder) UpdateUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTx", reflect.TypeOf((*MockServiceProvider)(nil).UpdateUserTx), arg0, arg1)
+}
+
 // VerifyEmailTx mocks base method.
 func (m *MockServiceProvider) VerifyEmailTx(arg0 context.Context, arg1 models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
 	m.ctrl.T.Helper()
@@ -399,3 +1251,18 @@ func (mr *MockServiceProviderMockRecorder) VerifyEmailTx(arg0, arg1 any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockServiceProvider)(nil).VerifyEmailTx), arg0, arg1)
 }
+
+// VerifyPhoneTx mocks base method.
+func (m *MockServiceProvider) VerifyPhoneTx(arg0 context.Context, arg1 models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPhoneTx", arg0, arg1)
+	ret0, _ := ret[0].(models.VerifyPhoneTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPhoneTx indicates an expected call of VerifyPhoneTx.
+func (mr *MockServiceProviderMockRecorder) VerifyPhoneTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPhoneTx", reflect.TypeOf((*MockServiceProvider)(nil).VerifyPhoneTx), arg0, arg1)
+}
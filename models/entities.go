@@ -2,26 +2,108 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   float64   `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              int64             `json:"id"`
+	Owner           string            `json:"owner"`
+	Balance         int64             `json:"balance"` // amounts are in minor units of the currency, see package money
+	Currency        string            `json:"currency"`
+	OverdraftLimit  int64             `json:"overdraft_limit"`    // how far below zero the balance may go
+	Type            string            `json:"account_type"`       // AccountChecking or AccountSavings, set when it is opened
+	Nickname        string            `json:"nickname,omitempty"` // unique among the accounts of its owner
+	Metadata        map[string]string `json:"metadata,omitempty"` // the owner's, keys starting with sb_ are reserved
+	AccruedInterest int64             `json:"accrued_interest"`   // accrued since it was last paid into the balance
+	CreatedAt       time.Time         `json:"created_at"`
+	DeletedAt       *time.Time        `json:"deleted_at,omitempty"` // nil while the account is open
+}
+
+// Types of an account
+const (
+	AccountChecking = "checking"
+	AccountSavings  = "savings"
+)
+
+// EarnsInterest tells whether interest accrues on the account, only savings accounts earn it
+func (a Account) EarnsInterest() bool {
+	return a.Type == AccountSavings
+}
+
+// CategoryInterest is the category the entries paying out accrued interest are filed under
+const CategoryInterest = "interest"
+
+// InterestAccrual is a day of interest accrued on an account, at most one per account and day
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"` // the day the interest was earned, midnight UTC
+	Balance     int64     `json:"balance"`      // the balance it was accrued on
+	Rate        float64   `json:"rate"`         // yearly, in percent
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AccrueInterestParams accrues a day of interest on the balance of Account at the yearly Rate, in
+// percent
+type AccrueInterestParams struct {
+	Account Account
+	Date    time.Time
+	Rate    float64
+}
+
+// ListInterestAccountsParams pages through the open accounts that earn interest by id, the page
+// after AfterID
+type ListInterestAccountsParams struct {
+	AfterID int64
+	Limit   int32
 }
 
 type Entry struct {
 	ID        int64     `json:"id"`
 	AccountID int64     `json:"account_id"`
-	Amount    float64   `json:"amount"` // can be negative or positive
+	Amount    int64     `json:"amount"`             // can be negative or positive, in the account's currency
+	Category  string    `json:"category,omitempty"` // one of the categories table, empty when uncategorized
+	Tags      []string  `json:"tags,omitempty"`     // the owner's own labels, sorted
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ListAccountsParams pages the open accounts of an owner, an empty Type doesn't filter
+type ListAccountsParams struct {
+	Owner  string
+	Type   string
+	Limit  int32
+	Offset int32
+}
+
+// ListEntriesParams pages the entries of an account, an empty Category or Tag doesn't filter
+type ListEntriesParams struct {
+	AccountID int64
+	Category  string
+	Tag       string
+	Limit     int64
+	Offset    int64
+}
+
+// SetEntryCategoryParams files an entry after the fact, a field left unset stays as it is
+type SetEntryCategoryParams struct {
+	EntryID  int64
+	Category sql.NullString // an empty string removes it
+	Tags     []string       // replaces the tags when not nil
+}
+
+// AddAccountBalanceParams deposits a positive Amount or withdraws a negative one, posting an
+// entry filed under Category and Tags
+type AddAccountBalanceParams struct {
+	AccountID int64
+	Amount    int64
+	Category  string
+	Tags      []string
+}
+
 type VerifyEmails struct {
 	ID         int64     `json:"id"`
 	Username   string    `json:"username"`
@@ -32,14 +114,34 @@ type VerifyEmails struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// VerifyPhones holds a code sent by SMS to confirm a user's phone number. Only the latest code
+// of a user can be used, and only a few wrong guesses are allowed.
+type VerifyPhones struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	PhoneNumber string    `json:"phone_number"`
+	SecretCode  string    `json:"secret_code"`
+	Attempts    int32     `json:"attempts"`
+	IsUsed      bool      `json:"is_used"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiredAt   time.Time `json:"expired_at"`
+}
+
 type Transaction struct {
 	ID            int64     `json:"id"`
 	FromAccountID int64     `json:"from_account_id"`
 	ToAccountID   int64     `json:"to_account_id"`
 	Currency      string    `json:"currency"`
 	Description   string    `json:"description"`
-	Amount        float64   `json:"amount"` // must be positive
-	Fee           float64   `json:"fee"`    // must be positive
+	Amount        int64     `json:"amount"`    // must be positive
+	Fee           int64     `json:"fee"`       // must be positive, in Currency
+	ToAmount      int64     `json:"to_amount"` // in ToCurrency
+	ToCurrency    string    `json:"to_currency"`
+	ExchangeRate  float64   `json:"exchange_rate"`
+	ReversalOf    int64     `json:"reversal_of"`        // the transaction this one reverses, zero otherwise
+	ReversedBy    int64     `json:"reversed_by"`        // the reversing transaction, only loaded by GetTransaction
+	Category      string    `json:"category,omitempty"` // of the entry of the listed account, only loaded by ListTransfers
+	Tags          []string  `json:"tags,omitempty"`     // likewise
 	CreatedAt     time.Time `json:"created_at"`
 }
 
@@ -49,13 +151,30 @@ type User struct {
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
 	IsEmailVerified   bool      `json:"is_email_verified"`
+	Role              string    `json:"role"`
+	PhoneNumber       string    `json:"phone_number"` // E.164, empty when not given
+	IsPhoneVerified   bool      `json:"is_phone_verified"`
+	PreviousEmail     string    `json:"-"` // the last verified email, kept when it is changed
+	EmailChangedAt    time.Time `json:"-"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+// RecoveryEmail is where account recovery mail like a password reset goes. After a change the
+// new email is only trusted once verified, until then the previous one is used for grace.
+func (u User) RecoveryEmail(now time.Time, grace time.Duration) string {
+	if !u.IsEmailVerified && u.PreviousEmail != "" && now.Before(u.EmailChangedAt.Add(grace)) {
+		return u.PreviousEmail
+	}
+	return u.Email
+}
+
+// CreateUserTxParams creates the user together with their first verify email. The messages
+// AfterCreate returns are stored in the outbox, an error rolls everything back.
 type CreateUserTxParams struct {
 	User
-	AfterCreate func(user User) error
+	SecretCode  string
+	AfterCreate func(user User, verifyEmail VerifyEmails) ([]OutboxMessage, error)
 }
 
 type VerifyEmailTxParams struct {
@@ -68,6 +187,31 @@ type VerifyEmailTxResult struct {
 	VerifyEmail VerifyEmails
 }
 
+type VerifyPhoneTxParams struct {
+	Username   string
+	SecretCode string
+}
+
+type VerifyPhoneTxResult struct {
+	User        User
+	VerifyPhone VerifyPhones
+}
+
+// UpdateUserTxParams updates the user. A changed email is marked unverified and gets a verify
+// email with SecretCode, the messages AfterEmailChange returns for it are stored in the outbox.
+// An error rolls everything back.
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	SecretCode       string
+	AfterEmailChange func(user User, verifyEmail VerifyEmails) ([]OutboxMessage, error)
+}
+
+// UpdateUserTxResult holds the verify email created for a changed email, zero otherwise
+type UpdateUserTxResult struct {
+	User        User
+	VerifyEmail VerifyEmails
+}
+
 type UpdateUserParams struct {
 	UserName          string         `json:"username"`
 	HashedPassword    sql.NullString `json:"-"`
@@ -75,6 +219,8 @@ type UpdateUserParams struct {
 	Email             sql.NullString `json:"email"`
 	IsEmailVerified   sql.NullBool   `json:"is_email_verified"`
 	PasswordChangedAt sql.NullTime   `json:"password_changed_at"`
+	PhoneNumber       sql.NullString `json:"phone_number"`
+	IsPhoneVerified   sql.NullBool   `json:"is_phone_verified"`
 }
 
 type Session struct {
@@ -89,12 +235,39 @@ type Session struct {
 }
 
 type TransferTxParams struct {
-	FromAccountID int64   `json:"from_account_id"`
-	ToAccountID   int64   `json:"to_account_id"`
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`
-	Description   string  `json:"description"`
-	Fee           float64 `json:"fee"`
+	FromAccountID int64    `json:"from_account_id"`
+	ToAccountID   int64    `json:"to_account_id"`
+	Amount        int64    `json:"amount"`
+	Currency      string   `json:"currency"`
+	Description   string   `json:"description"`
+	Fee           int64    `json:"fee"`
+	ToAmount      int64    `json:"to_amount"`      // amount credited to the receiver, defaults to Amount
+	ToCurrency    string   `json:"to_currency"`    // receiver currency, defaults to Currency
+	ExchangeRate  float64  `json:"exchange_rate"`  // defaults to 1
+	FeeAccountID  int64    `json:"fee_account_id"` // receives Fee, when zero the fee is recorded but not charged
+	ReversalOf    int64    `json:"reversal_of"`    // set when this transfer reverses an earlier one
+	Category      string   `json:"category"`       // files the sender's entry, empty leaves it uncategorized
+	Tags          []string `json:"tags"`           // the sender's labels on its entry
+
+	Idempotency          *IdempotencyKey `json:"-"` // optional, retries with the same key get the first result back
+	SavingsTransferLimit int             `json:"-"` // outgoing transfers a savings sender may make a month, 0 for no limit
+}
+
+type ReverseTransferParams struct {
+	TransactionID int64
+	ReversedBy    string
+	Reason        string
+}
+
+type ReverseTransferResult struct {
+	Original Transaction      `json:"original"`
+	Reversal TransferTxResult `json:"reversal"`
+}
+
+type ReverseTransferTxParams struct {
+	Transfer   TransferTxParams // the compensating transfer, ReversalOf points at the original
+	ReversedBy string
+	Reason     string
 }
 
 type TransferTxResult struct {
@@ -103,4 +276,568 @@ type TransferTxResult struct {
 	FromAccount Account     `json:"from_account"`
 	ToEntry     Entry       `json:"to_entry"`
 	FromEntry   Entry       `json:"from_entry"`
+	FeeEntry    Entry       `json:"fee_entry"` // sender's fee debit, empty when no fee was charged
+}
+
+type GetStatementParams struct {
+	Account Account
+	Year    int
+	Month   int
+}
+
+type StatementRow struct {
+	EntryID   int64     `json:"entry_id"`
+	Amount    int64     `json:"amount"`
+	Balance   int64     `json:"balance"` // running balance after this entry
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryTotal sums the rows of a statement filed under one category, an empty category
+// holds the uncategorized rows
+type CategoryTotal struct {
+	Category string `json:"category"`
+	In       int64  `json:"in"`  // money received
+	Out      int64  `json:"out"` // money spent, as a positive amount
+	Count    int    `json:"count"`
+}
+
+type Statement struct {
+	Account        Account         `json:"account"`
+	Year           int             `json:"year"`
+	Month          int             `json:"month"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Rows           []StatementRow  `json:"rows"`
+	Transfers      []Transaction   `json:"transfers"`
+	Categories     []CategoryTotal `json:"categories"` // by category name, the uncategorized rows last
+}
+
+type GetAccountSummaryParams struct {
+	Account Account
+	Year    int
+	Month   int
+}
+
+// SummarizeEntriesParams selects the entries of an account posted within [From, To)
+type SummarizeEntriesParams struct {
+	AccountID int64
+	From      time.Time
+	To        time.Time
+}
+
+// AccountSummary is the cash flow of an account over a month, in the currency of the account
+type AccountSummary struct {
+	AccountID   int64           `json:"account_id"`
+	Currency    string          `json:"currency"`
+	Year        int             `json:"year"`
+	Month       int             `json:"month"`
+	PeriodStart time.Time       `json:"period_start"` // the later of the month start and the opening of the account
+	PeriodEnd   time.Time       `json:"period_end"`   // exclusive, now for the current month
+	Inflow      int64           `json:"inflow"`
+	Outflow     int64           `json:"outflow"` // a positive amount
+	Count       int64           `json:"count"`
+	Largest     *Entry          `json:"largest,omitempty"` // by absolute amount, nil without entries
+	Categories  []CategoryTotal `json:"categories"`        // by category name, the uncategorized entries last
+}
+
+// NetChange is how much the balance moved over the period
+func (s AccountSummary) NetChange() int64 {
+	return s.Inflow - s.Outflow
+}
+
+// Statuses of a statement document
+const (
+	StatementDocumentPending = "pending"
+	StatementDocumentReady   = "ready"
+	StatementDocumentFailed  = "failed"
+)
+
+// StatementDocument is a monthly statement rendered as a PDF and kept in object storage
+type StatementDocument struct {
+	ID            int64     `json:"id"`
+	AccountID     int64     `json:"account_id"`
+	Year          int       `json:"year"`
+	Month         int       `json:"month"`
+	Status        string    `json:"status"`
+	ObjectKey     string    `json:"object_key"` // empty until ready
+	FailureReason string    `json:"failure_reason"`
+	RequestedBy   string    `json:"requested_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RequestStatementTxParams asks for the PDF of a month. A statement that is already pending is
+// returned as is, any other one is rendered again. The messages AfterRequest returns for the
+// rendering are stored in the outbox, it is not called for pending statements.
+type RequestStatementTxParams struct {
+	AccountID    int64
+	Year         int
+	Month        int
+	RequestedBy  string
+	AfterRequest func(document StatementDocument) ([]OutboxMessage, error)
+}
+
+type FinishStatementDocumentParams struct {
+	ID            int64
+	Status        string // ready or failed
+	ObjectKey     string
+	FailureReason string
+}
+
+type ListStatementDocumentsParams struct {
+	AccountID int64
+	Limit     int32
+	Offset    int32
+}
+
+// AuditLog records who did what to which entity. Before and After are JSON snapshots of the
+// entity, either can be null when there is nothing to show.
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type CreateAuditLogParams struct {
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  string
+	Before    json.RawMessage
+	After     json.RawMessage
+	RequestID string
+}
+
+type ListAuditLogsParams struct {
+	Entity   string
+	EntityID string // all entities of the kind when empty
+	Limit    int32
+	Offset   int32
+}
+
+type SearchTransactionsParams struct {
+	Owner     string
+	AccountID int64 // optional, zero searches every account of the owner
+	Query     string
+	Limit     int32
+	Offset    int32
+}
+
+// which side of a transfer an account is on
+const (
+	TransferDirectionAll      = "all"
+	TransferDirectionSent     = "sent"
+	TransferDirectionReceived = "received"
+)
+
+// ListTransfersParams pages the transfers of an account newest first, like ListNotificationsParams.
+// Unset bounds and an empty Currency don't filter; amount and currency are the transfer's as sent.
+type ListTransfersParams struct {
+	AccountID       int64
+	Direction       string        // TransferDirectionAll when empty
+	StartTime       sql.NullTime  // inclusive
+	EndTime         sql.NullTime  // exclusive
+	MinAmount       sql.NullInt64 // inclusive, in minor units
+	MaxAmount       sql.NullInt64 // inclusive, in minor units
+	Currency        string
+	Category        string // of the entry of the account, empty doesn't filter
+	Tag             string // likewise
+	BeforeCreatedAt time.Time
+	BeforeID        int64
+	Limit           int32
+}
+
+// kinds of ledger rows in a transaction export
+const (
+	LedgerRowDeposit     = "deposit"
+	LedgerRowWithdrawal  = "withdrawal"
+	LedgerRowTransferIn  = "transfer_in"
+	LedgerRowTransferOut = "transfer_out"
+	LedgerRowFee         = "fee"
+	LedgerRowInterest    = "interest"
+)
+
+// LedgerRow is an entry of an account along with the transfer it was posted for, if any
+type LedgerRow struct {
+	EntryID      int64
+	Date         time.Time
+	Type         string
+	Counterparty int64 // the other account of a transfer, zero otherwise
+	Amount       int64 // signed, in Currency
+	Currency     string
+	Balance      int64 // right after this entry
+	Memo         string
+}
+
+// ExportTransactionsParams selects the entries of Account posted within [From, To)
+type ExportTransactionsParams struct {
+	Account Account
+	From    time.Time
+	To      time.Time
+}
+
+type GetBalanceParams struct {
+	Account         Account
+	DisplayCurrency string // optional, empty means the account currency
+}
+
+type Balance struct {
+	AccountID            int64     `json:"account_id"`
+	Balance              int64     `json:"balance"`
+	Currency             string    `json:"currency"`
+	DisplayCurrency      string    `json:"display_currency"`
+	ConvertedBalance     int64     `json:"converted_balance"` // in DisplayCurrency
+	Rate                 float64   `json:"rate"`
+	RateTimestamp        time.Time `json:"rate_timestamp"`
+	ConvertedUnavailable bool      `json:"converted_unavailable"`
+}
+
+type ExchangeRate struct {
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type FeePolicy struct {
+	Currency     string    `json:"currency"`
+	Flat         int64     `json:"flat"` // in Currency
+	Percentage   float64   `json:"percentage"`
+	FeeAccountID int64     `json:"fee_account_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Currency is a currency accounts can be opened and money moved in, while it is enabled
+type Currency struct {
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	MinorUnitExponent int32     `json:"minor_unit_exponent"` // decimal places of the minor unit
+	Enabled           bool      `json:"enabled"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// MigrationStatus is the row migrate keeps in schema_migrations. A dirty migration failed halfway.
+type MigrationStatus struct {
+	Version   int64     `json:"version"`
+	Dirty     bool      `json:"dirty"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+type SetOverdraftLimitParams struct {
+	AccountID int64
+	Limit     int64
+	ChangedBy string
+}
+
+// UpdateAccountDetailsParams changes what the owner keeps on an account, a field left unset stays
+// as it is
+type UpdateAccountDetailsParams struct {
+	AccountID int64
+	Nickname  sql.NullString    // an empty string removes it
+	Metadata  map[string]string // replaces the metadata when not nil
+}
+
+// statuses of a row of a bulk account import
+const (
+	BulkAccountCreated  = "created"
+	BulkAccountRejected = "rejected"
+	BulkAccountSkipped  = "skipped" // accepted, but not created because the import was all or nothing and another row was rejected
+)
+
+type BulkAccountRow struct {
+	Owner    string
+	Currency string
+	Balance  int64
+	// Reason is why the row was rejected before it reached the database, empty when it wasn't
+	Reason string
+}
+
+type BulkCreateAccountsParams struct {
+	Rows []BulkAccountRow
+	// BestEffort creates the accepted rows even when others are rejected, otherwise a single
+	// rejected row creates none
+	BestEffort bool
+}
+
+// BulkAccountResult is the outcome of the row at the same index of BulkCreateAccountsParams.Rows
+type BulkAccountResult struct {
+	Status  string
+	Reason  string
+	Account Account // set when created
+}
+
+type IdempotencyKey struct {
+	UserName    string    `json:"username"`
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	Response    []byte    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Statuses of a scheduled transfer
+const (
+	ScheduledTransferPending   = "pending"
+	ScheduledTransferExecuting = "executing"
+	ScheduledTransferExecuted  = "executed"
+	ScheduledTransferFailed    = "failed"
+	ScheduledTransferCanceled  = "canceled"
+)
+
+type ScheduledTransfer struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Description   string    `json:"description"`
+	ExecuteAt     time.Time `json:"execute_at"`
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason"`
+	TransactionID int64     `json:"transaction_id"` // zero until executed
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type CreateScheduledTransferTxParams struct {
+	Transfer    ScheduledTransfer
+	AfterCreate func(transfer ScheduledTransfer) error // enqueues the execution, an error rolls the insert back
+}
+
+type FinishScheduledTransferParams struct {
+	ID            int64
+	Status        string // executed or failed
+	TransactionID int64
+	FailureReason string
+}
+
+// Statuses of a standing order
+const (
+	StandingOrderActive    = "active"
+	StandingOrderPaused    = "paused"
+	StandingOrderSuspended = "suspended" // too many failed runs in a row
+	StandingOrderCompleted = "completed" // past its end date
+
+	StandingOrderRunExecuted = "executed"
+	StandingOrderRunFailed   = "failed"
+)
+
+type StandingOrder struct {
+	ID                  int64      `json:"id"`
+	Owner               string     `json:"owner"`
+	FromAccountID       int64      `json:"from_account_id"`
+	ToAccountID         int64      `json:"to_account_id"`
+	Amount              int64      `json:"amount"`
+	Currency            string     `json:"currency"`
+	Description         string     `json:"description"`
+	Frequency           string     `json:"frequency"`
+	StartDate           time.Time  `json:"start_date"`
+	EndDate             *time.Time `json:"end_date"` // nil runs forever
+	NextRunAt           time.Time  `json:"next_run_at"`
+	Status              string     `json:"status"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type StandingOrderExecution struct {
+	ID              int64     `json:"id"`
+	StandingOrderID int64     `json:"standing_order_id"`
+	RunAt           time.Time `json:"run_at"`
+	Status          string    `json:"status"` // executed or failed
+	FailureReason   string    `json:"failure_reason"`
+	TransactionID   int64     `json:"transaction_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ListStandingOrdersParams struct {
+	Owner  string
+	Limit  int32
+	Offset int32
+}
+
+type RecordStandingOrderRunParams struct {
+	Execution   StandingOrderExecution
+	NextRunAt   time.Time
+	Completed   bool  // the next run is past the end date
+	MaxFailures int32 // consecutive failures that suspend the order
+}
+
+// Beneficiary is an account a user saved to send money to by nickname
+type Beneficiary struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	AccountID int64     `json:"account_id"`
+	Nickname  string    `json:"nickname"`
+	IBAN      string    `json:"iban"` // normalized, empty when not provided
+	BIC       string    `json:"bic"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListBeneficiariesParams struct {
+	Owner  string
+	Limit  int32
+	Offset int32
+}
+
+// account events a webhook can subscribe to
+const (
+	WebhookEventTransferCompleted   = "transfer.completed"
+	WebhookEventDepositCompleted    = "deposit.completed"
+	WebhookEventWithdrawalCompleted = "withdrawal.completed"
+	WebhookEventPing                = "ping" // only sent on request, never subscribed to
+)
+
+// Webhook is a partner endpoint that account events of its owner are posted to
+type Webhook struct {
+	ID                  int64     `json:"id"`
+	Owner               string    `json:"owner"`
+	URL                 string    `json:"url"`
+	Events              []string  `json:"events"`
+	Secret              string    `json:"-"` // signs every delivery
+	Active              bool      `json:"active"`
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type ListWebhooksParams struct {
+	Owner  string
+	Limit  int32
+	Offset int32
+}
+
+// WebhookEvent is the JSON body posted to subscribed endpoints
+type WebhookEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// BalanceChange is the data of deposit and withdrawal events. Like every amount sent to webhooks
+// the amounts are decimal strings in major units, like "10.50".
+type BalanceChange struct {
+	AccountID int64  `json:"account_id"`
+	Amount    string `json:"amount"`
+	Currency  string `json:"currency"`
+	Balance   string `json:"balance"`
+}
+
+// TransferCompleted is the data of transfer completed events
+type TransferCompleted struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Currency      string    `json:"currency"`
+	Description   string    `json:"description"`
+	Amount        string    `json:"amount"`
+	Fee           string    `json:"fee"`
+	ToAmount      string    `json:"to_amount"`
+	ToCurrency    string    `json:"to_currency"`
+	ExchangeRate  float64   `json:"exchange_rate"`
+	ReversalOf    int64     `json:"reversal_of"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// optional emails a user can opt out of, transactional ones like email verification are always sent
+const (
+	NotificationTransfers  = "transfers"
+	NotificationLogins     = "logins"
+	NotificationStatements = "statements"
+)
+
+type NotificationPreferences struct {
+	Username        string    `json:"username"`
+	EmailTransfers  bool      `json:"email_transfers"`
+	EmailLogins     bool      `json:"email_logins"`
+	EmailStatements bool      `json:"email_statements"`
+	PreferSMS       bool      `json:"prefer_sms"` // send optional notifications by SMS when a phone number is set
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Allows reports whether the user wants emails of the notification kind
+func (p NotificationPreferences) Allows(notification string) bool {
+	switch notification {
+	case NotificationTransfers:
+		return p.EmailTransfers
+	case NotificationLogins:
+		return p.EmailLogins
+	case NotificationStatements:
+		return p.EmailStatements
+	}
+	return true
+}
+
+type UpdateNotificationPreferencesParams struct {
+	Username        string
+	EmailTransfers  sql.NullBool
+	EmailLogins     sql.NullBool
+	EmailStatements sql.NullBool
+	PreferSMS       sql.NullBool
+}
+
+// kinds of notifications, the last two are only sent by email and never reach the inbox
+const (
+	NotificationKindIncomingTransfer        = "incoming_transfer"
+	NotificationKindNewLogin                = "new_login"
+	NotificationKindScheduledTransferFailed = "scheduled_transfer_failed"
+	NotificationKindStandingOrderSuspended  = "standing_order_suspended"
+	NotificationKindLargeTransfer           = "large_transfer"
+	NotificationKindVerifyEmail             = "verify_email"
+	NotificationKindVerifyPhone             = "verify_phone"
+	NotificationKindStatement               = "statement"
+	NotificationKindTransactionExport       = "transaction_export"
+)
+
+// Notification is an entry of a user's in-app inbox
+type Notification struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"` // nil while unread
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// OutboxMessage is a task stored in the same transaction as the change that causes it. The
+// relay hands it to the task queue once the transaction has committed.
+type OutboxMessage struct {
+	ID        int64        `json:"id"`
+	TaskType  string       `json:"task_type"`
+	Payload   []byte       `json:"payload"`
+	Queue     string       `json:"queue"`
+	MaxRetry  int32        `json:"max_retry"`
+	CreatedAt time.Time    `json:"created_at"`
+	SentAt    sql.NullTime `json:"sent_at"`
+}
+
+// FailedNotification records a notification that was given up on because the provider rejected it
+// for good, e.g. an invalid recipient
+type FailedNotification struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Kind      string    `json:"kind"`
+	Channel   string    `json:"channel"`
+	Recipient string    `json:"recipient"`
+	Subject   string    `json:"subject"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListNotificationsParams pages newest first. A zero BeforeID starts from the newest notification,
+// otherwise only notifications older than (BeforeCreatedAt, BeforeID) are returned.
+type ListNotificationsParams struct {
+	Username        string
+	BeforeCreatedAt time.Time
+	BeforeID        int64
+	Limit           int32
 }
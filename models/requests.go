@@ -2,19 +2,48 @@ package models
 
 type CreateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"account_type" binding:"omitempty,oneof=checking savings"` // checking when left out
 }
 
 type GetAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+type GetAccountQuery struct {
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
 type ListAccountRequest struct {
 	PageID   int32 `form:"page_id" binding:"required,min=1"`
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
 }
 
+// PageRequest picks a page of a listing, page_id counts from 1
+type PageRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// Offset is the number of rows before the page
+func (p PageRequest) Offset() int32 {
+	return (p.PageID - 1) * p.PageSize
+}
+
+// ListAccountsRequest pages the accounts of the user, an empty Type lists them all
+type ListAccountsRequest struct {
+	Type string `form:"account_type" binding:"omitempty,oneof=checking savings"`
+	PageRequest
+}
+
+type ListTransfersRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	PageRequest
+}
+
 type UpdateAccountRequest struct {
-	Balance float64 `json:"balance" binding:"required"`
+	Balance  int64    `json:"balance" binding:"required"` // in minor units of the account currency, like cents
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
 }
 
 type CreateUserRequest struct {
@@ -38,10 +67,11 @@ type GetUserRequest struct {
 }
 
 type TransferMoneyRequest struct {
-	FromAccountID int64   `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64   `json:"to_account_id" binding:"required,min=1"`
-	Description   string  `json:"description"`
-	Amount        float64 `json:"amount" binding:"required,gt=0"`
-	Fee           float64 `json:"fee" binding:"required,gt=0"`
-	Currency      string  `json:"currency" binding:"required,currency"`
+	FromAccountID int64    `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64    `json:"to_account_id" binding:"required,min=1"`
+	Description   string   `json:"description"`
+	Amount        int64    `json:"amount" binding:"required,gt=0"` // in minor units, like cents
+	Currency      string   `json:"currency" binding:"required,currency"`
+	Category      string   `json:"category"`
+	Tags          []string `json:"tags"`
 }
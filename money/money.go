@@ -0,0 +1,274 @@
+// Package money does the arithmetic of amounts of money. An amount is a whole number of the minor
+// unit of its currency, like cents, so nothing is ever lost to floating point rounding. Amounts
+// only meet floats when multiplied by a rate, and the result is rounded back to the minor unit.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrTooPrecise       = errors.New("amount has more decimal places than its currency")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// exponentsMu guards exponents, currencies added at runtime are registered in it
+var exponentsMu sync.RWMutex
+
+// exponents is the number of decimal places of the minor unit of each currency
+var exponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"CAD": 2,
+	"JPY": 0,
+	"BHD": 3,
+}
+
+// Exponent returns the number of decimal places of currency
+func Exponent(currency string) (int, bool) {
+	exponentsMu.RLock()
+	defer exponentsMu.RUnlock()
+
+	exponent, ok := exponents[currency]
+	return exponent, ok
+}
+
+// RegisterCurrency makes amounts of currency known, with exponent decimal places. A currency is
+// never unregistered, balances in it stay readable after it is disabled.
+func RegisterCurrency(currency string, exponent int) {
+	exponentsMu.Lock()
+	defer exponentsMu.Unlock()
+
+	exponents[currency] = exponent
+}
+
+// Amount is Units minor units of Currency
+type Amount struct {
+	Units    int64
+	Currency string
+}
+
+func New(units int64, currency string) Amount {
+	return Amount{Units: units, Currency: currency}
+}
+
+// FromDecimalString parses an amount written in major units, like "10.50" for USD. It may not
+// have more decimal places than the currency, "10.005" is no amount of USD.
+func FromDecimalString(value, currency string) (Amount, error) {
+	exponent, ok := Exponent(currency)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	digits, negative := strings.CutPrefix(value, "-")
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return Amount{}, fmt.Errorf("%w %q", ErrInvalidAmount, value)
+	}
+	if len(fraction) > exponent {
+		return Amount{}, fmt.Errorf("%w %q, %s has %d", ErrTooPrecise, value, currency, exponent)
+	}
+
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w %q", ErrOverflow, value)
+	}
+	if negative {
+		units = -units
+	}
+
+	return Amount{Units: units, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String writes the amount in major units with all the decimal places of its currency, like
+// "-0.50". It is what FromDecimalString reads.
+func (a Amount) String() string {
+	exponent, ok := Exponent(a.Currency)
+	if !ok {
+		return strconv.FormatInt(a.Units, 10)
+	}
+
+	sign := ""
+	units := strconv.FormatUint(absUnits(a.Units), 10)
+	if a.Units < 0 {
+		sign = "-"
+	}
+	if exponent == 0 {
+		return sign + units
+	}
+
+	if len(units) <= exponent {
+		units = strings.Repeat("0", exponent-len(units)+1) + units
+	}
+	point := len(units) - exponent
+	return sign + units[:point] + "." + units[point:]
+}
+
+func absUnits(units int64) uint64 {
+	if units < 0 {
+		return uint64(-(units + 1)) + 1 // math.MinInt64 has no positive int64
+	}
+	return uint64(units)
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.Currency != b.Currency {
+		return Amount{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+	}
+
+	sum := a.Units + b.Units
+	if (b.Units > 0 && sum < a.Units) || (b.Units < 0 && sum > a.Units) {
+		return Amount{}, ErrOverflow
+	}
+
+	return Amount{Units: sum, Currency: a.Currency}, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b.Units == math.MinInt64 {
+		return Amount{}, ErrOverflow
+	}
+	return a.Add(b.Neg())
+}
+
+// Compare returns -1, 0 or +1 as a is less than, equal to or greater than b
+func (a Amount) Compare(b Amount) (int, error) {
+	if a.Currency != b.Currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+	}
+
+	switch {
+	case a.Units < b.Units:
+		return -1, nil
+	case a.Units > b.Units:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (a Amount) Neg() Amount {
+	return Amount{Units: -a.Units, Currency: a.Currency}
+}
+
+func (a Amount) IsZero() bool {
+	return a.Units == 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a.Units < 0
+}
+
+// Percent returns percent of the amount, 1.5 meaning 1.5%, rounded half away from zero
+func (a Amount) Percent(percent float64) (Amount, error) {
+	return a.scale(percent, 0, 100, a.Currency, round)
+}
+
+// DailyInterest returns a day of interest at a yearly rate of percent, 2.5 meaning 2.5% a year,
+// on a 365 day year. It rounds half to even, the banker's rounding, so the half cents of many
+// accruals don't all lean the bank's way.
+func (a Amount) DailyInterest(percent float64) (Amount, error) {
+	return a.scale(percent, 0, 100*365, a.Currency, roundHalfEven)
+}
+
+// Convert turns the amount into currency at rate, the price of one unit of a's currency in
+// currency, rounded half away from zero to the minor unit of currency
+func (a Amount) Convert(rate float64, currency string) (Amount, error) {
+	from, ok := Exponent(a.Currency)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w %q", ErrUnknownCurrency, a.Currency)
+	}
+	to, ok := Exponent(currency)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	return a.scale(rate, to-from, 1, currency, round)
+}
+
+// scale multiplies the units by factor and 10^shift, divides them by divisor and rounds the
+// result with rounder. The factor is taken exactly as the float it is.
+func (a Amount) scale(factor float64, shift int, divisor int64, currency string, rounder func(*big.Rat) (int64, error)) (Amount, error) {
+	if math.IsNaN(factor) || math.IsInf(factor, 0) {
+		return Amount{}, fmt.Errorf("%w: factor %v", ErrInvalidAmount, factor)
+	}
+
+	result := new(big.Rat).SetFloat64(factor)
+	result.Mul(result, new(big.Rat).SetInt64(a.Units))
+	result.Quo(result, new(big.Rat).SetInt64(divisor))
+
+	power := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(shift))), nil))
+	if shift >= 0 {
+		result.Mul(result, power)
+	} else {
+		result.Quo(result, power)
+	}
+
+	units, err := rounder(result)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	return Amount{Units: units, Currency: currency}, nil
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// round rounds r to the nearest integer, half away from zero
+func round(r *big.Rat) (int64, error) {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	// |remainder| / denominator >= 1/2
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+// roundHalfEven rounds r to the nearest integer, half to the even neighbour
+func roundHalfEven(r *big.Rat) (int64, error) {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	switch new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(r.Denom()) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+		}
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
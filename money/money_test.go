@@ -0,0 +1,172 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDecimalString(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		units    int64
+	}{
+		{"10.50", "USD", 1050},
+		{"10.5", "USD", 1050},
+		{"10", "USD", 1000},
+		{"0.01", "EUR", 1},
+		{"-3.20", "CAD", -320},
+		{"1500", "JPY", 1500},
+		{"1.005", "BHD", 1005},
+		{"0.5", "BHD", 500},
+	}
+
+	for _, tc := range testCases {
+		amount, err := FromDecimalString(tc.value, tc.currency)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, Amount{Units: tc.units, Currency: tc.currency}, amount, tc.value)
+	}
+}
+
+func TestFromDecimalStringInvalid(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		err      error
+	}{
+		{"10.005", "USD", ErrTooPrecise},
+		{"1.5", "JPY", ErrTooPrecise},
+		{"1.0001", "BHD", ErrTooPrecise},
+		{"", "USD", ErrInvalidAmount},
+		{"-", "USD", ErrInvalidAmount},
+		{"10.", "USD", ErrInvalidAmount},
+		{".5", "USD", ErrInvalidAmount},
+		{"+1", "USD", ErrInvalidAmount},
+		{"1e3", "USD", ErrInvalidAmount},
+		{"1,000.00", "USD", ErrInvalidAmount},
+		{"92233720368547758.08", "USD", ErrOverflow},
+		{"10", "XYZ", ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		_, err := FromDecimalString(tc.value, tc.currency)
+		require.ErrorIs(t, err, tc.err, tc.value)
+	}
+}
+
+func TestString(t *testing.T) {
+	require.Equal(t, "10.50", New(1050, "USD").String())
+	require.Equal(t, "0.05", New(5, "EUR").String())
+	require.Equal(t, "-0.05", New(-5, "EUR").String())
+	require.Equal(t, "0.00", New(0, "USD").String())
+	require.Equal(t, "1500", New(1500, "JPY").String())
+	require.Equal(t, "-1.005", New(-1005, "BHD").String())
+	require.Equal(t, "-92233720368547758.08", New(math.MinInt64, "USD").String())
+
+	// String is what FromDecimalString reads
+	for _, amount := range []Amount{New(-1, "USD"), New(123456, "BHD"), New(math.MaxInt64, "CAD"), New(-7, "JPY")} {
+		parsed, err := FromDecimalString(amount.String(), amount.Currency)
+		require.NoError(t, err)
+		require.Equal(t, amount, parsed)
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	sum, err := New(1050, "USD").Add(New(-75, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(975, "USD"), sum)
+
+	difference, err := New(100, "USD").Sub(New(250, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(-150, "USD"), difference)
+
+	_, err = New(100, "USD").Add(New(100, "EUR"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = New(100, "USD").Sub(New(100, "EUR"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, "USD").Add(New(1, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = New(math.MinInt64, "USD").Sub(New(1, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = New(0, "USD").Sub(New(math.MinInt64, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestCompare(t *testing.T) {
+	for _, tc := range []struct {
+		a, b Amount
+		want int
+	}{
+		{New(1, "USD"), New(2, "USD"), -1},
+		{New(2, "USD"), New(2, "USD"), 0},
+		{New(3, "USD"), New(-2, "USD"), 1},
+	} {
+		got, err := tc.a.Compare(tc.b)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got)
+	}
+
+	_, err := New(1, "USD").Compare(New(1, "CAD"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestPercent(t *testing.T) {
+	fee, err := New(10000, "USD").Percent(1.5)
+	require.NoError(t, err)
+	require.Equal(t, New(150, "USD"), fee)
+
+	// 0.5% of 1.01 is 0.00505, rounded half away from zero
+	fee, err = New(101, "USD").Percent(0.5)
+	require.NoError(t, err)
+	require.Equal(t, New(1, "USD"), fee)
+
+	fee, err = New(-300, "USD").Percent(0.5)
+	require.NoError(t, err)
+	require.Equal(t, New(-2, "USD"), fee)
+}
+
+func TestDailyInterest(t *testing.T) {
+	interest, err := New(36500, "USD").DailyInterest(2)
+	require.NoError(t, err)
+	require.Equal(t, New(2, "USD"), interest)
+
+	// at 1% a day earns a 36500th of the balance, halves go to the even cent
+	for units, want := range map[int64]int64{18250: 0, 54750: 2, 91250: 2, 127750: 4, 127751: 4} {
+		interest, err := New(units, "USD").DailyInterest(1)
+		require.NoError(t, err)
+		require.Equal(t, New(want, "USD"), interest, units)
+	}
+
+	interest, err = New(-54750, "USD").DailyInterest(1)
+	require.NoError(t, err)
+	require.Equal(t, New(-2, "USD"), interest)
+}
+
+func TestConvert(t *testing.T) {
+	converted, err := New(3333, "USD").Convert(0.915, "EUR")
+	require.NoError(t, err)
+	require.Equal(t, New(3050, "EUR"), converted)
+
+	// across exponents
+	converted, err = New(1000, "USD").Convert(150.25, "JPY")
+	require.NoError(t, err)
+	require.Equal(t, New(1503, "JPY"), converted)
+
+	converted, err = New(1503, "JPY").Convert(0.0066, "USD")
+	require.NoError(t, err)
+	require.Equal(t, New(992, "USD"), converted)
+
+	converted, err = New(1000, "USD").Convert(0.376, "BHD")
+	require.NoError(t, err)
+	require.Equal(t, New(3760, "BHD"), converted)
+
+	_, err = New(1000, "USD").Convert(1, "XYZ")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+	_, err = New(1000, "USD").Convert(math.Inf(1), "EUR")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = New(math.MaxInt64, "USD").Convert(2, "EUR")
+	require.ErrorIs(t, err, ErrOverflow)
+}
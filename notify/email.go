@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zde37/Swift_Bank/mail"
+)
+
+// EmailNotifier sends notifications through an EmailSender
+type EmailNotifier struct {
+	sender mail.EmailSender
+}
+
+func NewEmailNotifier(sender mail.EmailSender) *EmailNotifier {
+	return &EmailNotifier{
+		sender: sender,
+	}
+}
+
+func (n *EmailNotifier) Supports(channel string) bool {
+	return channel == ChannelEmail
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, recipient Recipient, message Message, channel string) error {
+	if recipient.Email == "" {
+		return fmt.Errorf("recipient has no email address")
+	}
+
+	return n.sender.SendEmail(message.Subject, message.HTML, message.Text, []string{recipient.Email}, nil, nil, nil)
+}
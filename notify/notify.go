@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// channels a notification can be sent over
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Recipient holds the addresses of a user on every channel
+type Recipient struct {
+	Name  string
+	Email string
+	Phone string // E.164
+}
+
+// Message is a notification in every form a channel may need. Email sends HTML with Text as its
+// plain text alternative, SMS only sends Text.
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Notifier sends a message to a recipient over a channel
+type Notifier interface {
+	Supports(channel string) bool
+	Send(ctx context.Context, recipient Recipient, message Message, channel string) error
+}
+
+type multiNotifier []Notifier
+
+// New returns a notifier that hands every message to the first of notifiers supporting its channel
+func New(notifiers ...Notifier) Notifier {
+	return multiNotifier(notifiers)
+}
+
+func (m multiNotifier) Supports(channel string) bool {
+	for _, n := range m {
+		if n.Supports(channel) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiNotifier) Send(ctx context.Context, recipient Recipient, message Message, channel string) error {
+	for _, n := range m {
+		if n.Supports(channel) {
+			return n.Send(ctx, recipient, message, channel)
+		}
+	}
+	return fmt.Errorf("no notifier for channel %q", channel)
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeSMSClient records the messages it was asked to send
+type fakeSMSClient struct {
+	sent []string
+	err  error
+}
+
+func (c *fakeSMSClient) SendSMS(ctx context.Context, from, to, body string) error {
+	c.sent = append(c.sent, from+">"+to+":"+body)
+	return c.err
+}
+
+func TestNotifierPicksChannel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Eq("Subject"), gomock.Eq("<b>body</b>"), gomock.Eq("body"), gomock.Eq([]string{"ada@example.com"}), gomock.Nil(), gomock.Nil(), gomock.Nil()).
+		Times(1).
+		Return(nil)
+
+	sms := &fakeSMSClient{}
+	notifier := New(NewEmailNotifier(mailer), NewSMSNotifier(sms, "+15550000000"))
+	recipient := Recipient{Name: "Ada", Email: "ada@example.com", Phone: "+15551234567"}
+	message := Message{Subject: "Subject", HTML: "<b>body</b>", Text: "body"}
+
+	require.NoError(t, notifier.Send(context.Background(), recipient, message, ChannelEmail))
+	require.NoError(t, notifier.Send(context.Background(), recipient, message, ChannelSMS))
+	require.Equal(t, []string{"+15550000000>+15551234567:body"}, sms.sent)
+
+	require.Error(t, notifier.Send(context.Background(), recipient, message, "pigeon"))
+}
+
+func TestSMSNotifierNeedsPhone(t *testing.T) {
+	sms := &fakeSMSClient{}
+	err := NewSMSNotifier(sms, "+15550000000").Send(context.Background(), Recipient{Email: "ada@example.com"}, Message{Text: "body"}, ChannelSMS)
+	require.Error(t, err)
+	require.Empty(t, sms.sent)
+}
+
+func TestTwilioClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "AC123", user)
+		require.Equal(t, "token", password)
+		require.NoError(t, r.ParseForm())
+
+		if r.PostForm.Get("To") == "+15559999999" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 21211, "message": "Invalid 'To' Phone Number"}`))
+			return
+		}
+
+		require.Equal(t, "+15550000000", r.PostForm.Get("From"))
+		require.Equal(t, "hello", r.PostForm.Get("Body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewTwilioClient("AC123", "token", server.Client())
+	client.baseURL = server.URL
+
+	require.NoError(t, client.SendSMS(context.Background(), "+15550000000", "+15551234567", "hello"))
+
+	err := client.SendSMS(context.Background(), "+15550000000", "+15559999999", "hello")
+	require.ErrorContains(t, err, "twilio error 21211")
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SMSClient delivers a text message, it is what tests fake
+type SMSClient interface {
+	SendSMS(ctx context.Context, from, to, body string) error
+}
+
+// SMSNotifier sends notifications as text messages, the subject is left out
+type SMSNotifier struct {
+	client SMSClient
+	from   string
+}
+
+func NewSMSNotifier(client SMSClient, from string) *SMSNotifier {
+	return &SMSNotifier{
+		client: client,
+		from:   from,
+	}
+}
+
+func (n *SMSNotifier) Supports(channel string) bool {
+	return channel == ChannelSMS
+}
+
+func (n *SMSNotifier) Send(ctx context.Context, recipient Recipient, message Message, channel string) error {
+	if recipient.Phone == "" {
+		return fmt.Errorf("recipient has no phone number")
+	}
+
+	return n.client.SendSMS(ctx, n.from, recipient.Phone, message.Text)
+}
+
+const twilioBaseURL = "https://api.twilio.com"
+
+// TwilioClient sends text messages through the Twilio Messages API
+type TwilioClient struct {
+	baseURL    string
+	accountSID string
+	authToken  string
+	httpClient *http.Client
+}
+
+func NewTwilioClient(accountSID, authToken string, httpClient *http.Client) *TwilioClient {
+	return &TwilioClient{
+		baseURL:    twilioBaseURL,
+		accountSID: accountSID,
+		authToken:  authToken,
+		httpClient: httpClient,
+	}
+}
+
+func (c *TwilioClient) SendSMS(ctx context.Context, from, to, body string) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", c.baseURL, url.PathEscape(c.accountSID))
+	form := url.Values{
+		"From": {from},
+		"To":   {to},
+		"Body": {body},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.accountSID, c.authToken)
+
+	rsp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 64<<10))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("failed to send sms: twilio error %d: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("failed to send sms: twilio responded with status %d", rsp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        v5.26.1
+// source: rpc_add_beneficiary.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddBeneficiaryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId int64   `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Nickname  string  `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Iban      *string `protobuf:"bytes,3,opt,name=iban,proto3,oneof" json:"iban,omitempty"` // printed or electronic form
+	Bic       *string `protobuf:"bytes,4,opt,name=bic,proto3,oneof" json:"bic,omitempty"`
+}
+
+func (x *AddBeneficiaryRequest) Reset() {
+	*x = AddBeneficiaryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_add_beneficiary_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBeneficiaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBeneficiaryRequest) ProtoMessage() {}
+
+func (x *AddBeneficiaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_add_beneficiary_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBeneficiaryRequest.ProtoReflect.Descriptor instead.
+func (*AddBeneficiaryRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_add_beneficiary_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddBeneficiaryRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *AddBeneficiaryRequest) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *AddBeneficiaryRequest) GetIban() string {
+	if x != nil && x.Iban != nil {
+		return *x.Iban
+	}
+	return ""
+}
+
+func (x *AddBeneficiaryRequest) GetBic() string {
+	if x != nil && x.Bic != nil {
+		return *x.Bic
+	}
+	return ""
+}
+
+type AddBeneficiaryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Beneficiary *Beneficiary `protobuf:"bytes,1,opt,name=beneficiary,proto3" json:"beneficiary,omitempty"`
+}
+
+func (x *AddBeneficiaryResponse) Reset() {
+	*x = AddBeneficiaryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_add_beneficiary_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddBeneficiaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBeneficiaryResponse) ProtoMessage() {}
+
+func (x *AddBeneficiaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_add_beneficiary_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBeneficiaryResponse.ProtoReflect.Descriptor instead.
+func (*AddBeneficiaryResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_add_beneficiary_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddBeneficiaryResponse) GetBeneficiary() *Beneficiary {
+	if x != nil {
+		return x.Beneficiary
+	}
+	return nil
+}
+
+var File_rpc_add_beneficiary_proto protoreflect.FileDescriptor
+
+var file_rpc_add_beneficiary_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x64, 0x64, 0x5f, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69,
+	0x63, 0x69, 0x61, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a,
+	0x11, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x63, 0x69, 0x61, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0x93, 0x01, 0x0a, 0x15, 0x41, 0x64, 0x64, 0x42, 0x65, 0x6e, 0x65, 0x66, 0x69,
+	0x63, 0x69, 0x61, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6e,
+	0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e,
+	0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x04, 0x69, 0x62, 0x61, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x69, 0x62, 0x61, 0x6e, 0x88, 0x01, 0x01,
+	0x12, 0x15, 0x0a, 0x03, 0x62, 0x69, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52,
+	0x03, 0x62, 0x69, 0x63, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x69, 0x62, 0x61, 0x6e,
+	0x42, 0x06, 0x0a, 0x04, 0x5f, 0x62, 0x69, 0x63, 0x22, 0x4b, 0x0a, 0x16, 0x41, 0x64, 0x64, 0x42,
+	0x65, 0x6e, 0x65, 0x66, 0x69, 0x63, 0x69, 0x61, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x31, 0x0a, 0x0b, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x63, 0x69, 0x61, 0x72,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x65, 0x6e,
+	0x65, 0x66, 0x69, 0x63, 0x69, 0x61, 0x72, 0x79, 0x52, 0x0b, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69,
+	0x63, 0x69, 0x61, 0x72, 0x79, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x64, 0x65, 0x33, 0x37, 0x2f, 0x53, 0x77, 0x69, 0x66, 0x74, 0x5f,
+	0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_add_beneficiary_proto_rawDescOnce sync.Once
+	file_rpc_add_beneficiary_proto_rawDescData = file_rpc_add_beneficiary_proto_rawDesc
+)
+
+func file_rpc_add_beneficiary_proto_rawDescGZIP() []byte {
+	file_rpc_add_beneficiary_proto_rawDescOnce.Do(func() {
+		file_rpc_add_beneficiary_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_add_beneficiary_proto_rawDescData)
+	})
+	return file_rpc_add_beneficiary_proto_rawDescData
+}
+
+var file_rpc_add_beneficiary_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_add_beneficiary_proto_goTypes = []interface{}{
+	(*AddBeneficiaryRequest)(nil),  // 0: pb.AddBeneficiaryRequest
+	(*AddBeneficiaryResponse)(nil), // 1: pb.AddBeneficiaryResponse
+	(*Beneficiary)(nil),            // 2: pb.Beneficiary
+}
+var file_rpc_add_beneficiary_proto_depIdxs = []int32{
+	2, // 0: pb.AddBeneficiaryResponse.beneficiary:type_name -> pb.Beneficiary
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_add_beneficiary_proto_init() }
+func file_rpc_add_beneficiary_proto_init() {
+	if File_rpc_add_beneficiary_proto != nil {
+		return
+	}
+	file_beneficiary_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_add_beneficiary_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddBeneficiaryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_add_beneficiary_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddBeneficiaryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_add_beneficiary_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_add_beneficiary_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_add_beneficiary_proto_goTypes,
+		DependencyIndexes: file_rpc_add_beneficiary_proto_depIdxs,
+		MessageInfos:      file_rpc_add_beneficiary_proto_msgTypes,
+	}.Build()
+	File_rpc_add_beneficiary_proto = out.File
+	file_rpc_add_beneficiary_proto_rawDesc = nil
+	file_rpc_add_beneficiary_proto_goTypes = nil
+	file_rpc_add_beneficiary_proto_depIdxs = nil
+}
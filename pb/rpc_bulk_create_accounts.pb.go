@@ -0,0 +1,422 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        v5.26.1
+// source: rpc_bulk_create_accounts.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BulkAccountRow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner    string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Currency string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	Balance  string `protobuf:"bytes,3,opt,name=balance,proto3" json:"balance,omitempty"` // opening balance, a decimal in major units like "10.50", empty for zero
+}
+
+func (x *BulkAccountRow) Reset() {
+	*x = BulkAccountRow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_bulk_create_accounts_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BulkAccountRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkAccountRow) ProtoMessage() {}
+
+func (x *BulkAccountRow) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_bulk_create_accounts_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkAccountRow.ProtoReflect.Descriptor instead.
+func (*BulkAccountRow) Descriptor() ([]byte, []int) {
+	return file_rpc_bulk_create_accounts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BulkAccountRow) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *BulkAccountRow) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *BulkAccountRow) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+type BulkCreateAccountsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Accounts []*BulkAccountRow `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	// create the accepted rows even when others are rejected, by default a single rejected row
+	// creates none
+	BestEffort bool `protobuf:"varint,2,opt,name=best_effort,json=bestEffort,proto3" json:"best_effort,omitempty"`
+}
+
+func (x *BulkCreateAccountsRequest) Reset() {
+	*x = BulkCreateAccountsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_bulk_create_accounts_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BulkCreateAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateAccountsRequest) ProtoMessage() {}
+
+func (x *BulkCreateAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_bulk_create_accounts_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateAccountsRequest.ProtoReflect.Descriptor instead.
+func (*BulkCreateAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_bulk_create_accounts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BulkCreateAccountsRequest) GetAccounts() []*BulkAccountRow {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *BulkCreateAccountsRequest) GetBestEffort() bool {
+	if x != nil {
+		return x.BestEffort
+	}
+	return false
+}
+
+type BulkAccountResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Row     int32    `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`        // index of the row in the request
+	Status  string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`   // created, rejected or skipped (accepted, but not created because another row was rejected)
+	Reason  string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`   // why the row was rejected
+	Account *Account `protobuf:"bytes,4,opt,name=account,proto3" json:"account,omitempty"` // set when created
+}
+
+func (x *BulkAccountResult) Reset() {
+	*x = BulkAccountResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_bulk_create_accounts_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BulkAccountResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkAccountResult) ProtoMessage() {}
+
+func (x *BulkAccountResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_bulk_create_accounts_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkAccountResult.ProtoReflect.Descriptor instead.
+func (*BulkAccountResult) Descriptor() ([]byte, []int) {
+	return file_rpc_bulk_create_accounts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BulkAccountResult) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *BulkAccountResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BulkAccountResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BulkAccountResult) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+type BulkCreateAccountsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results  []*BulkAccountResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // one per row, in the order of the request
+	Created  int32                `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Rejected int32                `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`
+}
+
+func (x *BulkCreateAccountsResponse) Reset() {
+	*x = BulkCreateAccountsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_bulk_create_accounts_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BulkCreateAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateAccountsResponse) ProtoMessage() {}
+
+func (x *BulkCreateAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_bulk_create_accounts_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateAccountsResponse.ProtoReflect.Descriptor instead.
+func (*BulkCreateAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_bulk_create_accounts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BulkCreateAccountsResponse) GetResults() []*BulkAccountResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BulkCreateAccountsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *BulkCreateAccountsResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+var File_rpc_bulk_create_accounts_proto protoreflect.FileDescriptor
+
+var file_rpc_bulk_create_accounts_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x72, 0x70, 0x63, 0x5f, 0x62, 0x75, 0x6c, 0x6b, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x02, 0x70, 0x62, 0x1a, 0x0d, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x5c, 0x0a, 0x0e, 0x42, 0x75, 0x6c, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x52, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x22, 0x6c, 0x0a, 0x19, 0x42, 0x75, 0x6c, 0x6b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e,
+	0x0a, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x75, 0x6c, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x52, 0x6f, 0x77, 0x52, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x65, 0x66, 0x66, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x62, 0x65, 0x73, 0x74, 0x45, 0x66, 0x66, 0x6f, 0x72, 0x74, 0x22,
+	0x7c, 0x0a, 0x11, 0x42, 0x75, 0x6c, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x52, 0x07, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x83, 0x01,
+	0x0a, 0x1a, 0x42, 0x75, 0x6c, 0x6b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x70, 0x62, 0x2e, 0x42, 0x75, 0x6c, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x7a, 0x64, 0x65, 0x33, 0x37, 0x2f, 0x53, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x42, 0x61,
+	0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_bulk_create_accounts_proto_rawDescOnce sync.Once
+	file_rpc_bulk_create_accounts_proto_rawDescData = file_rpc_bulk_create_accounts_proto_rawDesc
+)
+
+func file_rpc_bulk_create_accounts_proto_rawDescGZIP() []byte {
+	file_rpc_bulk_create_accounts_proto_rawDescOnce.Do(func() {
+		file_rpc_bulk_create_accounts_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_bulk_create_accounts_proto_rawDescData)
+	})
+	return file_rpc_bulk_create_accounts_proto_rawDescData
+}
+
+var file_rpc_bulk_create_accounts_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_rpc_bulk_create_accounts_proto_goTypes = []interface{}{
+	(*BulkAccountRow)(nil),             // 0: pb.BulkAccountRow
+	(*BulkCreateAccountsRequest)(nil),  // 1: pb.BulkCreateAccountsRequest
+	(*BulkAccountResult)(nil),          // 2: pb.BulkAccountResult
+	(*BulkCreateAccountsResponse)(nil), // 3: pb.BulkCreateAccountsResponse
+	(*Account)(nil),                    // 4: pb.Account
+}
+var file_rpc_bulk_create_accounts_proto_depIdxs = []int32{
+	0, // 0: pb.BulkCreateAccountsRequest.accounts:type_name -> pb.BulkAccountRow
+	4, // 1: pb.BulkAccountResult.account:type_name -> pb.Account
+	2, // 2: pb.BulkCreateAccountsResponse.results:type_name -> pb.BulkAccountResult
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_rpc_bulk_create_accounts_proto_init() }
+func file_rpc_bulk_create_accounts_proto_init() {
+	if File_rpc_bulk_create_accounts_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_bulk_create_accounts_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BulkAccountRow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_bulk_create_accounts_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BulkCreateAccountsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_bulk_create_accounts_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BulkAccountResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_bulk_create_accounts_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BulkCreateAccountsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_bulk_create_accounts_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_bulk_create_accounts_proto_goTypes,
+		DependencyIndexes: file_rpc_bulk_create_accounts_proto_depIdxs,
+		MessageInfos:      file_rpc_bulk_create_accounts_proto_msgTypes,
+	}.Build()
+	File_rpc_bulk_create_accounts_proto = out.File
+	file_rpc_bulk_create_accounts_proto_rawDesc = nil
+	file_rpc_bulk_create_accounts_proto_goTypes = nil
+	file_rpc_bulk_create_accounts_proto_depIdxs = nil
+}
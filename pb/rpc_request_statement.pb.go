@@ -0,0 +1,233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        v5.26.1
+// source: rpc_request_statement.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RequestStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId int64 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Year      int32 `protobuf:"varint,2,opt,name=year,proto3" json:"year,omitempty"`
+	Month     int32 `protobuf:"varint,3,opt,name=month,proto3" json:"month,omitempty"`
+}
+
+func (x *RequestStatementRequest) Reset() {
+	*x = RequestStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_request_statement_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestStatementRequest) ProtoMessage() {}
+
+func (x *RequestStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_request_statement_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestStatementRequest.ProtoReflect.Descriptor instead.
+func (*RequestStatementRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_request_statement_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RequestStatementRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *RequestStatementRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *RequestStatementRequest) GetMonth() int32 {
+	if x != nil {
+		return x.Month
+	}
+	return 0
+}
+
+type RequestStatementResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Statement *StatementDocument `protobuf:"bytes,1,opt,name=statement,proto3" json:"statement,omitempty"`
+}
+
+func (x *RequestStatementResponse) Reset() {
+	*x = RequestStatementResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_request_statement_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestStatementResponse) ProtoMessage() {}
+
+func (x *RequestStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_request_statement_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestStatementResponse.ProtoReflect.Descriptor instead.
+func (*RequestStatementResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_request_statement_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RequestStatementResponse) GetStatement() *StatementDocument {
+	if x != nil {
+		return x.Statement
+	}
+	return nil
+}
+
+var File_rpc_request_statement_proto protoreflect.FileDescriptor
+
+var file_rpc_request_statement_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x72, 0x70, 0x63, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70,
+	0x62, 0x1a, 0x18, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x62, 0x0a, 0x17, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x6e,
+	0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x6f, 0x6e, 0x74, 0x68, 0x22,
+	0x4f, 0x0a, 0x18, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x42, 0x20, 0x5a, 0x1e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a,
+	0x64, 0x65, 0x33, 0x37, 0x2f, 0x53, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x42, 0x61, 0x6e, 0x6b, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_request_statement_proto_rawDescOnce sync.Once
+	file_rpc_request_statement_proto_rawDescData = file_rpc_request_statement_proto_rawDesc
+)
+
+func file_rpc_request_statement_proto_rawDescGZIP() []byte {
+	file_rpc_request_statement_proto_rawDescOnce.Do(func() {
+		file_rpc_request_statement_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_request_statement_proto_rawDescData)
+	})
+	return file_rpc_request_statement_proto_rawDescData
+}
+
+var file_rpc_request_statement_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_request_statement_proto_goTypes = []interface{}{
+	(*RequestStatementRequest)(nil),  // 0: pb.RequestStatementRequest
+	(*RequestStatementResponse)(nil), // 1: pb.RequestStatementResponse
+	(*StatementDocument)(nil),        // 2: pb.StatementDocument
+}
+var file_rpc_request_statement_proto_depIdxs = []int32{
+	2, // 0: pb.RequestStatementResponse.statement:type_name -> pb.StatementDocument
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_request_statement_proto_init() }
+func file_rpc_request_statement_proto_init() {
+	if File_rpc_request_statement_proto != nil {
+		return
+	}
+	file_statement_document_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_request_statement_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_request_statement_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestStatementResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_request_statement_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_request_statement_proto_goTypes,
+		DependencyIndexes: file_rpc_request_statement_proto_depIdxs,
+		MessageInfos:      file_rpc_request_statement_proto_msgTypes,
+	}.Build()
+	File_rpc_request_statement_proto = out.File
+	file_rpc_request_statement_proto_rawDesc = nil
+	file_rpc_request_statement_proto_goTypes = nil
+	file_rpc_request_statement_proto_depIdxs = nil
+}
@@ -0,0 +1,403 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        v5.26.1
+// source: rpc_transfer_money.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransferMoneyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromAccountId int64 `protobuf:"varint,1,opt,name=from_account_id,json=fromAccountId,proto3" json:"from_account_id,omitempty"`
+	// Types that are assignable to Recipient:
+	//	*TransferMoneyRequest_ToAccountId
+	//	*TransferMoneyRequest_ToBeneficiaryId
+	//	*TransferMoneyRequest_ToIban
+	Recipient   isTransferMoneyRequest_Recipient `protobuf_oneof:"recipient"`
+	Amount      string                           `protobuf:"bytes,9,opt,name=amount,proto3" json:"amount,omitempty"` // a decimal in major units, like "10.50"
+	Currency    string                           `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Description string                           `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"` // a memo of at most 140 characters, control characters are stripped
+	// optional, may also be sent as the Idempotency-Key header
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// optional, file your side of the transfer under one of the bank's categories and your own tags
+	Category string   `protobuf:"bytes,10,opt,name=category,proto3" json:"category,omitempty"`
+	Tags     []string `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *TransferMoneyRequest) Reset() {
+	*x = TransferMoneyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_transfer_money_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferMoneyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferMoneyRequest) ProtoMessage() {}
+
+func (x *TransferMoneyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_transfer_money_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferMoneyRequest.ProtoReflect.Descriptor instead.
+func (*TransferMoneyRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_transfer_money_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransferMoneyRequest) GetFromAccountId() int64 {
+	if x != nil {
+		return x.FromAccountId
+	}
+	return 0
+}
+
+func (m *TransferMoneyRequest) GetRecipient() isTransferMoneyRequest_Recipient {
+	if m != nil {
+		return m.Recipient
+	}
+	return nil
+}
+
+func (x *TransferMoneyRequest) GetToAccountId() int64 {
+	if x, ok := x.GetRecipient().(*TransferMoneyRequest_ToAccountId); ok {
+		return x.ToAccountId
+	}
+	return 0
+}
+
+func (x *TransferMoneyRequest) GetToBeneficiaryId() int64 {
+	if x, ok := x.GetRecipient().(*TransferMoneyRequest_ToBeneficiaryId); ok {
+		return x.ToBeneficiaryId
+	}
+	return 0
+}
+
+func (x *TransferMoneyRequest) GetToIban() string {
+	if x, ok := x.GetRecipient().(*TransferMoneyRequest_ToIban); ok {
+		return x.ToIban
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type isTransferMoneyRequest_Recipient interface {
+	isTransferMoneyRequest_Recipient()
+}
+
+type TransferMoneyRequest_ToAccountId struct {
+	ToAccountId int64 `protobuf:"varint,2,opt,name=to_account_id,json=toAccountId,proto3,oneof"`
+}
+
+type TransferMoneyRequest_ToBeneficiaryId struct {
+	ToBeneficiaryId int64 `protobuf:"varint,7,opt,name=to_beneficiary_id,json=toBeneficiaryId,proto3,oneof"` // one of the caller's saved payees
+}
+
+type TransferMoneyRequest_ToIban struct {
+	ToIban string `protobuf:"bytes,8,opt,name=to_iban,json=toIban,proto3,oneof"` // resolved through the caller's saved payees
+}
+
+func (*TransferMoneyRequest_ToAccountId) isTransferMoneyRequest_Recipient() {}
+
+func (*TransferMoneyRequest_ToBeneficiaryId) isTransferMoneyRequest_Recipient() {}
+
+func (*TransferMoneyRequest_ToIban) isTransferMoneyRequest_Recipient() {}
+
+type TransferMoneyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Transfer    *Transfer `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	FromAccount *Account  `protobuf:"bytes,2,opt,name=from_account,json=fromAccount,proto3" json:"from_account,omitempty"`
+	FromEntry   *Entry    `protobuf:"bytes,3,opt,name=from_entry,json=fromEntry,proto3" json:"from_entry,omitempty"`
+	Fee         string    `protobuf:"bytes,7,opt,name=fee,proto3" json:"fee,omitempty"`                                 // a decimal in major units, like "10.50"
+	TotalDebit  string    `protobuf:"bytes,8,opt,name=total_debit,json=totalDebit,proto3" json:"total_debit,omitempty"` // amount plus fee, in the sender's currency
+	FeeEntry    *Entry    `protobuf:"bytes,6,opt,name=fee_entry,json=feeEntry,proto3" json:"fee_entry,omitempty"`
+}
+
+func (x *TransferMoneyResponse) Reset() {
+	*x = TransferMoneyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_transfer_money_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferMoneyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferMoneyResponse) ProtoMessage() {}
+
+func (x *TransferMoneyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_transfer_money_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferMoneyResponse.ProtoReflect.Descriptor instead.
+func (*TransferMoneyResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_transfer_money_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TransferMoneyResponse) GetTransfer() *Transfer {
+	if x != nil {
+		return x.Transfer
+	}
+	return nil
+}
+
+func (x *TransferMoneyResponse) GetFromAccount() *Account {
+	if x != nil {
+		return x.FromAccount
+	}
+	return nil
+}
+
+func (x *TransferMoneyResponse) GetFromEntry() *Entry {
+	if x != nil {
+		return x.FromEntry
+	}
+	return nil
+}
+
+func (x *TransferMoneyResponse) GetFee() string {
+	if x != nil {
+		return x.Fee
+	}
+	return ""
+}
+
+func (x *TransferMoneyResponse) GetTotalDebit() string {
+	if x != nil {
+		return x.TotalDebit
+	}
+	return ""
+}
+
+func (x *TransferMoneyResponse) GetFeeEntry() *Entry {
+	if x != nil {
+		return x.FeeEntry
+	}
+	return nil
+}
+
+var File_rpc_transfer_money_proto protoreflect.FileDescriptor
+
+var file_rpc_transfer_money_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x72, 0x70, 0x63, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x6d,
+	0x6f, 0x6e, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x0d,
+	0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x65,
+	0x6e, 0x74, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xef, 0x02, 0x0a, 0x14, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x66, 0x72,
+	0x6f, 0x6d, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x74,
+	0x6f, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x00, 0x52, 0x0b, 0x74, 0x6f, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x2c, 0x0a, 0x11, 0x74, 0x6f, 0x5f, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x63, 0x69,
+	0x61, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0f,
+	0x74, 0x6f, 0x42, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x63, 0x69, 0x61, 0x72, 0x79, 0x49, 0x64, 0x12,
+	0x19, 0x0a, 0x07, 0x74, 0x6f, 0x5f, 0x69, 0x62, 0x61, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x06, 0x74, 0x6f, 0x49, 0x62, 0x61, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x20,
+	0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70,
+	0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x74,
+	0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x74,
+	0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x0b, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x72, 0x65, 0x63,
+	0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x82, 0x02, 0x0a,
+	0x15, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x65, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x08, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x12, 0x2e, 0x0a, 0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x28, 0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x09, 0x66, 0x72, 0x6f, 0x6d, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x65, 0x62, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x44, 0x65, 0x62, 0x69, 0x74, 0x12, 0x26, 0x0a,
+	0x09, 0x66, 0x65, 0x65, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x66, 0x65, 0x65,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10,
+	0x06, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x7a, 0x64, 0x65, 0x33, 0x37, 0x2f, 0x53, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x42, 0x61, 0x6e, 0x6b,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_transfer_money_proto_rawDescOnce sync.Once
+	file_rpc_transfer_money_proto_rawDescData = file_rpc_transfer_money_proto_rawDesc
+)
+
+func file_rpc_transfer_money_proto_rawDescGZIP() []byte {
+	file_rpc_transfer_money_proto_rawDescOnce.Do(func() {
+		file_rpc_transfer_money_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_transfer_money_proto_rawDescData)
+	})
+	return file_rpc_transfer_money_proto_rawDescData
+}
+
+var file_rpc_transfer_money_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_transfer_money_proto_goTypes = []interface{}{
+	(*TransferMoneyRequest)(nil),  // 0: pb.TransferMoneyRequest
+	(*TransferMoneyResponse)(nil), // 1: pb.TransferMoneyResponse
+	(*Transfer)(nil),              // 2: pb.Transfer
+	(*Account)(nil),               // 3: pb.Account
+	(*Entry)(nil),                 // 4: pb.Entry
+}
+var file_rpc_transfer_money_proto_depIdxs = []int32{
+	2, // 0: pb.TransferMoneyResponse.transfer:type_name -> pb.Transfer
+	3, // 1: pb.TransferMoneyResponse.from_account:type_name -> pb.Account
+	4, // 2: pb.TransferMoneyResponse.from_entry:type_name -> pb.Entry
+	4, // 3: pb.TransferMoneyResponse.fee_entry:type_name -> pb.Entry
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_rpc_transfer_money_proto_init() }
+func file_rpc_transfer_money_proto_init() {
+	if File_rpc_transfer_money_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	file_entry_proto_init()
+	file_transfer_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_transfer_money_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferMoneyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_transfer_money_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferMoneyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_transfer_money_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*TransferMoneyRequest_ToAccountId)(nil),
+		(*TransferMoneyRequest_ToBeneficiaryId)(nil),
+		(*TransferMoneyRequest_ToIban)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_transfer_money_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_transfer_money_proto_goTypes,
+		DependencyIndexes: file_rpc_transfer_money_proto_depIdxs,
+		MessageInfos:      file_rpc_transfer_money_proto_msgTypes,
+	}.Build()
+	File_rpc_transfer_money_proto = out.File
+	file_rpc_transfer_money_proto_rawDesc = nil
+	file_rpc_transfer_money_proto_goTypes = nil
+	file_rpc_transfer_money_proto_depIdxs = nil
+}
@@ -19,10 +19,56 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	SwiftBank_CreateUser_FullMethodName  = "/pb.SwiftBank/CreateUser"
-	SwiftBank_LoginUser_FullMethodName   = "/pb.SwiftBank/LoginUser"
-	SwiftBank_VerifyEmail_FullMethodName = "/pb.SwiftBank/VerifyEmail"
-	SwiftBank_UpdateUser_FullMethodName  = "/pb.SwiftBank/UpdateUser"
+	SwiftBank_CreateUser_FullMethodName                    = "/pb.SwiftBank/CreateUser"
+	SwiftBank_LoginUser_FullMethodName                     = "/pb.SwiftBank/LoginUser"
+	SwiftBank_VerifyEmail_FullMethodName                   = "/pb.SwiftBank/VerifyEmail"
+	SwiftBank_ResendVerificationEmail_FullMethodName       = "/pb.SwiftBank/ResendVerificationEmail"
+	SwiftBank_VerifyPhone_FullMethodName                   = "/pb.SwiftBank/VerifyPhone"
+	SwiftBank_UpdateUser_FullMethodName                    = "/pb.SwiftBank/UpdateUser"
+	SwiftBank_GetNotificationPreferences_FullMethodName    = "/pb.SwiftBank/GetNotificationPreferences"
+	SwiftBank_UpdateNotificationPreferences_FullMethodName = "/pb.SwiftBank/UpdateNotificationPreferences"
+	SwiftBank_ListNotifications_FullMethodName             = "/pb.SwiftBank/ListNotifications"
+	SwiftBank_MarkNotificationRead_FullMethodName          = "/pb.SwiftBank/MarkNotificationRead"
+	SwiftBank_UnreadNotificationCount_FullMethodName       = "/pb.SwiftBank/UnreadNotificationCount"
+	SwiftBank_GetStatement_FullMethodName                  = "/pb.SwiftBank/GetStatement"
+	SwiftBank_RequestStatement_FullMethodName              = "/pb.SwiftBank/RequestStatement"
+	SwiftBank_ListStatements_FullMethodName                = "/pb.SwiftBank/ListStatements"
+	SwiftBank_TransferMoney_FullMethodName                 = "/pb.SwiftBank/TransferMoney"
+	SwiftBank_GetTransfer_FullMethodName                   = "/pb.SwiftBank/GetTransfer"
+	SwiftBank_ListTransfers_FullMethodName                 = "/pb.SwiftBank/ListTransfers"
+	SwiftBank_ReverseTransfer_FullMethodName               = "/pb.SwiftBank/ReverseTransfer"
+	SwiftBank_ScheduleTransfer_FullMethodName              = "/pb.SwiftBank/ScheduleTransfer"
+	SwiftBank_CancelScheduledTransfer_FullMethodName       = "/pb.SwiftBank/CancelScheduledTransfer"
+	SwiftBank_CreateStandingOrder_FullMethodName           = "/pb.SwiftBank/CreateStandingOrder"
+	SwiftBank_ListStandingOrders_FullMethodName            = "/pb.SwiftBank/ListStandingOrders"
+	SwiftBank_PauseStandingOrder_FullMethodName            = "/pb.SwiftBank/PauseStandingOrder"
+	SwiftBank_ResumeStandingOrder_FullMethodName           = "/pb.SwiftBank/ResumeStandingOrder"
+	SwiftBank_DeleteStandingOrder_FullMethodName           = "/pb.SwiftBank/DeleteStandingOrder"
+	SwiftBank_AddBeneficiary_FullMethodName                = "/pb.SwiftBank/AddBeneficiary"
+	SwiftBank_ListBeneficiaries_FullMethodName             = "/pb.SwiftBank/ListBeneficiaries"
+	SwiftBank_DeleteBeneficiary_FullMethodName             = "/pb.SwiftBank/DeleteBeneficiary"
+	SwiftBank_CreateWebhook_FullMethodName                 = "/pb.SwiftBank/CreateWebhook"
+	SwiftBank_ListWebhooks_FullMethodName                  = "/pb.SwiftBank/ListWebhooks"
+	SwiftBank_DeleteWebhook_FullMethodName                 = "/pb.SwiftBank/DeleteWebhook"
+	SwiftBank_TestWebhook_FullMethodName                   = "/pb.SwiftBank/TestWebhook"
+	SwiftBank_CreateAccount_FullMethodName                 = "/pb.SwiftBank/CreateAccount"
+	SwiftBank_SetOverdraftLimit_FullMethodName             = "/pb.SwiftBank/SetOverdraftLimit"
+	SwiftBank_UpdateAccount_FullMethodName                 = "/pb.SwiftBank/UpdateAccount"
+	SwiftBank_SetTransactionCategory_FullMethodName        = "/pb.SwiftBank/SetTransactionCategory"
+	SwiftBank_BulkCreateAccounts_FullMethodName            = "/pb.SwiftBank/BulkCreateAccounts"
+	SwiftBank_GetBalance_FullMethodName                    = "/pb.SwiftBank/GetBalance"
+	SwiftBank_GetAccountSummary_FullMethodName             = "/pb.SwiftBank/GetAccountSummary"
+	SwiftBank_StreamTransactions_FullMethodName            = "/pb.SwiftBank/StreamTransactions"
+	SwiftBank_SearchTransactions_FullMethodName            = "/pb.SwiftBank/SearchTransactions"
+	SwiftBank_ExportTransactions_FullMethodName            = "/pb.SwiftBank/ExportTransactions"
+	SwiftBank_ListFailedTasks_FullMethodName               = "/pb.SwiftBank/ListFailedTasks"
+	SwiftBank_RetryFailedTask_FullMethodName               = "/pb.SwiftBank/RetryFailedTask"
+	SwiftBank_ListAuditLogs_FullMethodName                 = "/pb.SwiftBank/ListAuditLogs"
+	SwiftBank_AddCurrency_FullMethodName                   = "/pb.SwiftBank/AddCurrency"
+	SwiftBank_DisableCurrency_FullMethodName               = "/pb.SwiftBank/DisableCurrency"
+	SwiftBank_SetLogLevel_FullMethodName                   = "/pb.SwiftBank/SetLogLevel"
+	SwiftBank_MigrationStatus_FullMethodName               = "/pb.SwiftBank/MigrationStatus"
+	SwiftBank_ServerInfo_FullMethodName                    = "/pb.SwiftBank/ServerInfo"
 )
 
 // SwiftBankClient is the client API for SwiftBank service.
@@ -32,7 +78,54 @@ type SwiftBankClient interface {
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*CreateUserResponse, error)
 	LoginUser(ctx context.Context, in *LoginUserRequest, opts ...grpc.CallOption) (*LoginUserResponse, error)
 	VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*VerifyEmailResponse, error)
+	ResendVerificationEmail(ctx context.Context, in *ResendVerificationEmailRequest, opts ...grpc.CallOption) (*ResendVerificationEmailResponse, error)
+	VerifyPhone(ctx context.Context, in *VerifyPhoneRequest, opts ...grpc.CallOption) (*VerifyPhoneResponse, error)
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
+	GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error)
+	ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	MarkNotificationRead(ctx context.Context, in *MarkNotificationReadRequest, opts ...grpc.CallOption) (*MarkNotificationReadResponse, error)
+	UnreadNotificationCount(ctx context.Context, in *UnreadNotificationCountRequest, opts ...grpc.CallOption) (*UnreadNotificationCountResponse, error)
+	GetStatement(ctx context.Context, in *GetStatementRequest, opts ...grpc.CallOption) (*GetStatementResponse, error)
+	RequestStatement(ctx context.Context, in *RequestStatementRequest, opts ...grpc.CallOption) (*RequestStatementResponse, error)
+	ListStatements(ctx context.Context, in *ListStatementsRequest, opts ...grpc.CallOption) (*ListStatementsResponse, error)
+	TransferMoney(ctx context.Context, in *TransferMoneyRequest, opts ...grpc.CallOption) (*TransferMoneyResponse, error)
+	GetTransfer(ctx context.Context, in *GetTransferRequest, opts ...grpc.CallOption) (*GetTransferResponse, error)
+	ListTransfers(ctx context.Context, in *ListTransfersRequest, opts ...grpc.CallOption) (*ListTransfersResponse, error)
+	ReverseTransfer(ctx context.Context, in *ReverseTransferRequest, opts ...grpc.CallOption) (*ReverseTransferResponse, error)
+	ScheduleTransfer(ctx context.Context, in *ScheduleTransferRequest, opts ...grpc.CallOption) (*ScheduleTransferResponse, error)
+	CancelScheduledTransfer(ctx context.Context, in *CancelScheduledTransferRequest, opts ...grpc.CallOption) (*CancelScheduledTransferResponse, error)
+	CreateStandingOrder(ctx context.Context, in *CreateStandingOrderRequest, opts ...grpc.CallOption) (*CreateStandingOrderResponse, error)
+	ListStandingOrders(ctx context.Context, in *ListStandingOrdersRequest, opts ...grpc.CallOption) (*ListStandingOrdersResponse, error)
+	PauseStandingOrder(ctx context.Context, in *PauseStandingOrderRequest, opts ...grpc.CallOption) (*PauseStandingOrderResponse, error)
+	ResumeStandingOrder(ctx context.Context, in *ResumeStandingOrderRequest, opts ...grpc.CallOption) (*ResumeStandingOrderResponse, error)
+	DeleteStandingOrder(ctx context.Context, in *DeleteStandingOrderRequest, opts ...grpc.CallOption) (*DeleteStandingOrderResponse, error)
+	AddBeneficiary(ctx context.Context, in *AddBeneficiaryRequest, opts ...grpc.CallOption) (*AddBeneficiaryResponse, error)
+	ListBeneficiaries(ctx context.Context, in *ListBeneficiariesRequest, opts ...grpc.CallOption) (*ListBeneficiariesResponse, error)
+	DeleteBeneficiary(ctx context.Context, in *DeleteBeneficiaryRequest, opts ...grpc.CallOption) (*DeleteBeneficiaryResponse, error)
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	TestWebhook(ctx context.Context, in *TestWebhookRequest, opts ...grpc.CallOption) (*TestWebhookResponse, error)
+	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error)
+	SetOverdraftLimit(ctx context.Context, in *SetOverdraftLimitRequest, opts ...grpc.CallOption) (*SetOverdraftLimitResponse, error)
+	UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*UpdateAccountResponse, error)
+	SetTransactionCategory(ctx context.Context, in *SetTransactionCategoryRequest, opts ...grpc.CallOption) (*SetTransactionCategoryResponse, error)
+	BulkCreateAccounts(ctx context.Context, in *BulkCreateAccountsRequest, opts ...grpc.CallOption) (*BulkCreateAccountsResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	GetAccountSummary(ctx context.Context, in *GetAccountSummaryRequest, opts ...grpc.CallOption) (*GetAccountSummaryResponse, error)
+	// gRPC only: the in-process gateway cannot serve streaming calls
+	StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (SwiftBank_StreamTransactionsClient, error)
+	SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error)
+	ExportTransactions(ctx context.Context, in *ExportTransactionsRequest, opts ...grpc.CallOption) (*ExportTransactionsResponse, error)
+	ListFailedTasks(ctx context.Context, in *ListFailedTasksRequest, opts ...grpc.CallOption) (*ListFailedTasksResponse, error)
+	RetryFailedTask(ctx context.Context, in *RetryFailedTaskRequest, opts ...grpc.CallOption) (*RetryFailedTaskResponse, error)
+	ListAuditLogs(ctx context.Context, in *ListAuditLogsRequest, opts ...grpc.CallOption) (*ListAuditLogsResponse, error)
+	AddCurrency(ctx context.Context, in *AddCurrencyRequest, opts ...grpc.CallOption) (*AddCurrencyResponse, error)
+	DisableCurrency(ctx context.Context, in *DisableCurrencyRequest, opts ...grpc.CallOption) (*DisableCurrencyResponse, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	MigrationStatus(ctx context.Context, in *MigrationStatusRequest, opts ...grpc.CallOption) (*MigrationStatusResponse, error)
+	ServerInfo(ctx context.Context, in *ServerInfoRequest, opts ...grpc.CallOption) (*ServerInfoResponse, error)
 }
 
 type swiftBankClient struct {
@@ -70,123 +163,1576 @@ func (c *swiftBankClient) VerifyEmail(ctx context.Context, in *VerifyEmailReques
 	return out, nil
 }
 
+func (c *swiftBankClient) ResendVerificationEmail(ctx context.Context, in *ResendVerificationEmailRequest, opts ...grpc.CallOption) (*ResendVerificationEmailResponse, error) {
+	out := new(ResendVerificationEmailResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ResendVerificationEmail_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) VerifyPhone(ctx context.Context, in *VerifyPhoneRequest, opts ...grpc.CallOption) (*VerifyPhoneResponse, error) {
+	out := new(VerifyPhoneResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_VerifyPhone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *swiftBankClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
 	out := new(UpdateUserResponse)
 	err := c.cc.Invoke(ctx, SwiftBank_UpdateUser_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *swiftBankClient) GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error) {
+	out := new(GetNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_GetNotificationPreferences_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error) {
+	out := new(UpdateNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_UpdateNotificationPreferences_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListNotifications_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) MarkNotificationRead(ctx context.Context, in *MarkNotificationReadRequest, opts ...grpc.CallOption) (*MarkNotificationReadResponse, error) {
+	out := new(MarkNotificationReadResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_MarkNotificationRead_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) UnreadNotificationCount(ctx context.Context, in *UnreadNotificationCountRequest, opts ...grpc.CallOption) (*UnreadNotificationCountResponse, error) {
+	out := new(UnreadNotificationCountResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_UnreadNotificationCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) GetStatement(ctx context.Context, in *GetStatementRequest, opts ...grpc.CallOption) (*GetStatementResponse, error) {
+	out := new(GetStatementResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_GetStatement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) RequestStatement(ctx context.Context, in *RequestStatementRequest, opts ...grpc.CallOption) (*RequestStatementResponse, error) {
+	out := new(RequestStatementResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_RequestStatement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListStatements(ctx context.Context, in *ListStatementsRequest, opts ...grpc.CallOption) (*ListStatementsResponse, error) {
+	out := new(ListStatementsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListStatements_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) TransferMoney(ctx context.Context, in *TransferMoneyRequest, opts ...grpc.CallOption) (*TransferMoneyResponse, error) {
+	out := new(TransferMoneyResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_TransferMoney_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) GetTransfer(ctx context.Context, in *GetTransferRequest, opts ...grpc.CallOption) (*GetTransferResponse, error) {
+	out := new(GetTransferResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_GetTransfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListTransfers(ctx context.Context, in *ListTransfersRequest, opts ...grpc.CallOption) (*ListTransfersResponse, error) {
+	out := new(ListTransfersResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListTransfers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ReverseTransfer(ctx context.Context, in *ReverseTransferRequest, opts ...grpc.CallOption) (*ReverseTransferResponse, error) {
+	out := new(ReverseTransferResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ReverseTransfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ScheduleTransfer(ctx context.Context, in *ScheduleTransferRequest, opts ...grpc.CallOption) (*ScheduleTransferResponse, error) {
+	out := new(ScheduleTransferResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ScheduleTransfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) CancelScheduledTransfer(ctx context.Context, in *CancelScheduledTransferRequest, opts ...grpc.CallOption) (*CancelScheduledTransferResponse, error) {
+	out := new(CancelScheduledTransferResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_CancelScheduledTransfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) CreateStandingOrder(ctx context.Context, in *CreateStandingOrderRequest, opts ...grpc.CallOption) (*CreateStandingOrderResponse, error) {
+	out := new(CreateStandingOrderResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_CreateStandingOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListStandingOrders(ctx context.Context, in *ListStandingOrdersRequest, opts ...grpc.CallOption) (*ListStandingOrdersResponse, error) {
+	out := new(ListStandingOrdersResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListStandingOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) PauseStandingOrder(ctx context.Context, in *PauseStandingOrderRequest, opts ...grpc.CallOption) (*PauseStandingOrderResponse, error) {
+	out := new(PauseStandingOrderResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_PauseStandingOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ResumeStandingOrder(ctx context.Context, in *ResumeStandingOrderRequest, opts ...grpc.CallOption) (*ResumeStandingOrderResponse, error) {
+	out := new(ResumeStandingOrderResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ResumeStandingOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) DeleteStandingOrder(ctx context.Context, in *DeleteStandingOrderRequest, opts ...grpc.CallOption) (*DeleteStandingOrderResponse, error) {
+	out := new(DeleteStandingOrderResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_DeleteStandingOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) AddBeneficiary(ctx context.Context, in *AddBeneficiaryRequest, opts ...grpc.CallOption) (*AddBeneficiaryResponse, error) {
+	out := new(AddBeneficiaryResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_AddBeneficiary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListBeneficiaries(ctx context.Context, in *ListBeneficiariesRequest, opts ...grpc.CallOption) (*ListBeneficiariesResponse, error) {
+	out := new(ListBeneficiariesResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListBeneficiaries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) DeleteBeneficiary(ctx context.Context, in *DeleteBeneficiaryRequest, opts ...grpc.CallOption) (*DeleteBeneficiaryResponse, error) {
+	out := new(DeleteBeneficiaryResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_DeleteBeneficiary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error) {
+	out := new(CreateWebhookResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_CreateWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListWebhooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_DeleteWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) TestWebhook(ctx context.Context, in *TestWebhookRequest, opts ...grpc.CallOption) (*TestWebhookResponse, error) {
+	out := new(TestWebhookResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_TestWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error) {
+	out := new(CreateAccountResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_CreateAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) SetOverdraftLimit(ctx context.Context, in *SetOverdraftLimitRequest, opts ...grpc.CallOption) (*SetOverdraftLimitResponse, error) {
+	out := new(SetOverdraftLimitResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_SetOverdraftLimit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*UpdateAccountResponse, error) {
+	out := new(UpdateAccountResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_UpdateAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) SetTransactionCategory(ctx context.Context, in *SetTransactionCategoryRequest, opts ...grpc.CallOption) (*SetTransactionCategoryResponse, error) {
+	out := new(SetTransactionCategoryResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_SetTransactionCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) BulkCreateAccounts(ctx context.Context, in *BulkCreateAccountsRequest, opts ...grpc.CallOption) (*BulkCreateAccountsResponse, error) {
+	out := new(BulkCreateAccountsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_BulkCreateAccounts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) GetAccountSummary(ctx context.Context, in *GetAccountSummaryRequest, opts ...grpc.CallOption) (*GetAccountSummaryResponse, error) {
+	out := new(GetAccountSummaryResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_GetAccountSummary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (SwiftBank_StreamTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SwiftBank_ServiceDesc.Streams[0], SwiftBank_StreamTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &swiftBankStreamTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SwiftBank_StreamTransactionsClient interface {
+	Recv() (*StreamTransactionsResponse, error)
+	grpc.ClientStream
+}
+
+type swiftBankStreamTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *swiftBankStreamTransactionsClient) Recv() (*StreamTransactionsResponse, error) {
+	m := new(StreamTransactionsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *swiftBankClient) SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error) {
+	out := new(SearchTransactionsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_SearchTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ExportTransactions(ctx context.Context, in *ExportTransactionsRequest, opts ...grpc.CallOption) (*ExportTransactionsResponse, error) {
+	out := new(ExportTransactionsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ExportTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListFailedTasks(ctx context.Context, in *ListFailedTasksRequest, opts ...grpc.CallOption) (*ListFailedTasksResponse, error) {
+	out := new(ListFailedTasksResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListFailedTasks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) RetryFailedTask(ctx context.Context, in *RetryFailedTaskRequest, opts ...grpc.CallOption) (*RetryFailedTaskResponse, error) {
+	out := new(RetryFailedTaskResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_RetryFailedTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ListAuditLogs(ctx context.Context, in *ListAuditLogsRequest, opts ...grpc.CallOption) (*ListAuditLogsResponse, error) {
+	out := new(ListAuditLogsResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ListAuditLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) AddCurrency(ctx context.Context, in *AddCurrencyRequest, opts ...grpc.CallOption) (*AddCurrencyResponse, error) {
+	out := new(AddCurrencyResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_AddCurrency_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) DisableCurrency(ctx context.Context, in *DisableCurrencyRequest, opts ...grpc.CallOption) (*DisableCurrencyResponse, error) {
+	out := new(DisableCurrencyResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_DisableCurrency_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_SetLogLevel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) MigrationStatus(ctx context.Context, in *MigrationStatusRequest, opts ...grpc.CallOption) (*MigrationStatusResponse, error) {
+	out := new(MigrationStatusResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_MigrationStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftBankClient) ServerInfo(ctx context.Context, in *ServerInfoRequest, opts ...grpc.CallOption) (*ServerInfoResponse, error) {
+	out := new(ServerInfoResponse)
+	err := c.cc.Invoke(ctx, SwiftBank_ServerInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwiftBankServer is the server API for SwiftBank service.
+// All implementations must embed UnimplementedSwiftBankServer
+// for forward compatibility
+type SwiftBankServer interface {
+	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
+	LoginUser(context.Context, *LoginUserRequest) (*LoginUserResponse, error)
+	VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error)
+	ResendVerificationEmail(context.Context, *ResendVerificationEmailRequest) (*ResendVerificationEmailResponse, error)
+	VerifyPhone(context.Context, *VerifyPhoneRequest) (*VerifyPhoneResponse, error)
+	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
+	GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error)
+	ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	MarkNotificationRead(context.Context, *MarkNotificationReadRequest) (*MarkNotificationReadResponse, error)
+	UnreadNotificationCount(context.Context, *UnreadNotificationCountRequest) (*UnreadNotificationCountResponse, error)
+	GetStatement(context.Context, *GetStatementRequest) (*GetStatementResponse, error)
+	RequestStatement(context.Context, *RequestStatementRequest) (*RequestStatementResponse, error)
+	ListStatements(context.Context, *ListStatementsRequest) (*ListStatementsResponse, error)
+	TransferMoney(context.Context, *TransferMoneyRequest) (*TransferMoneyResponse, error)
+	GetTransfer(context.Context, *GetTransferRequest) (*GetTransferResponse, error)
+	ListTransfers(context.Context, *ListTransfersRequest) (*ListTransfersResponse, error)
+	ReverseTransfer(context.Context, *ReverseTransferRequest) (*ReverseTransferResponse, error)
+	ScheduleTransfer(context.Context, *ScheduleTransferRequest) (*ScheduleTransferResponse, error)
+	CancelScheduledTransfer(context.Context, *CancelScheduledTransferRequest) (*CancelScheduledTransferResponse, error)
+	CreateStandingOrder(context.Context, *CreateStandingOrderRequest) (*CreateStandingOrderResponse, error)
+	ListStandingOrders(context.Context, *ListStandingOrdersRequest) (*ListStandingOrdersResponse, error)
+	PauseStandingOrder(context.Context, *PauseStandingOrderRequest) (*PauseStandingOrderResponse, error)
+	ResumeStandingOrder(context.Context, *ResumeStandingOrderRequest) (*ResumeStandingOrderResponse, error)
+	DeleteStandingOrder(context.Context, *DeleteStandingOrderRequest) (*DeleteStandingOrderResponse, error)
+	AddBeneficiary(context.Context, *AddBeneficiaryRequest) (*AddBeneficiaryResponse, error)
+	ListBeneficiaries(context.Context, *ListBeneficiariesRequest) (*ListBeneficiariesResponse, error)
+	DeleteBeneficiary(context.Context, *DeleteBeneficiaryRequest) (*DeleteBeneficiaryResponse, error)
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	TestWebhook(context.Context, *TestWebhookRequest) (*TestWebhookResponse, error)
+	CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error)
+	SetOverdraftLimit(context.Context, *SetOverdraftLimitRequest) (*SetOverdraftLimitResponse, error)
+	UpdateAccount(context.Context, *UpdateAccountRequest) (*UpdateAccountResponse, error)
+	SetTransactionCategory(context.Context, *SetTransactionCategoryRequest) (*SetTransactionCategoryResponse, error)
+	BulkCreateAccounts(context.Context, *BulkCreateAccountsRequest) (*BulkCreateAccountsResponse, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	GetAccountSummary(context.Context, *GetAccountSummaryRequest) (*GetAccountSummaryResponse, error)
+	// gRPC only: the in-process gateway cannot serve streaming calls
+	StreamTransactions(*StreamTransactionsRequest, SwiftBank_StreamTransactionsServer) error
+	SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error)
+	ExportTransactions(context.Context, *ExportTransactionsRequest) (*ExportTransactionsResponse, error)
+	ListFailedTasks(context.Context, *ListFailedTasksRequest) (*ListFailedTasksResponse, error)
+	RetryFailedTask(context.Context, *RetryFailedTaskRequest) (*RetryFailedTaskResponse, error)
+	ListAuditLogs(context.Context, *ListAuditLogsRequest) (*ListAuditLogsResponse, error)
+	AddCurrency(context.Context, *AddCurrencyRequest) (*AddCurrencyResponse, error)
+	DisableCurrency(context.Context, *DisableCurrencyRequest) (*DisableCurrencyResponse, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	MigrationStatus(context.Context, *MigrationStatusRequest) (*MigrationStatusResponse, error)
+	ServerInfo(context.Context, *ServerInfoRequest) (*ServerInfoResponse, error)
+	mustEmbedUnimplementedSwiftBankServer()
+}
+
+// UnimplementedSwiftBankServer must be embedded to have forward compatible implementations.
+type UnimplementedSwiftBankServer struct {
+}
+
+func (UnimplementedSwiftBankServer) CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedSwiftBankServer) LoginUser(context.Context, *LoginUserRequest) (*LoginUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginUser not implemented")
+}
+func (UnimplementedSwiftBankServer) VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyEmail not implemented")
+}
+func (UnimplementedSwiftBankServer) ResendVerificationEmail(context.Context, *ResendVerificationEmailRequest) (*ResendVerificationEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResendVerificationEmail not implemented")
+}
+func (UnimplementedSwiftBankServer) VerifyPhone(context.Context, *VerifyPhoneRequest) (*VerifyPhoneResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyPhone not implemented")
+}
+func (UnimplementedSwiftBankServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedSwiftBankServer) GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedSwiftBankServer) UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationPreferences not implemented")
+}
+func (UnimplementedSwiftBankServer) ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNotifications not implemented")
+}
+func (UnimplementedSwiftBankServer) MarkNotificationRead(context.Context, *MarkNotificationReadRequest) (*MarkNotificationReadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkNotificationRead not implemented")
+}
+func (UnimplementedSwiftBankServer) UnreadNotificationCount(context.Context, *UnreadNotificationCountRequest) (*UnreadNotificationCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnreadNotificationCount not implemented")
+}
+func (UnimplementedSwiftBankServer) GetStatement(context.Context, *GetStatementRequest) (*GetStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatement not implemented")
+}
+func (UnimplementedSwiftBankServer) RequestStatement(context.Context, *RequestStatementRequest) (*RequestStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestStatement not implemented")
+}
+func (UnimplementedSwiftBankServer) ListStatements(context.Context, *ListStatementsRequest) (*ListStatementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStatements not implemented")
+}
+func (UnimplementedSwiftBankServer) TransferMoney(context.Context, *TransferMoneyRequest) (*TransferMoneyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferMoney not implemented")
+}
+func (UnimplementedSwiftBankServer) GetTransfer(context.Context, *GetTransferRequest) (*GetTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransfer not implemented")
+}
+func (UnimplementedSwiftBankServer) ListTransfers(context.Context, *ListTransfersRequest) (*ListTransfersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransfers not implemented")
+}
+func (UnimplementedSwiftBankServer) ReverseTransfer(context.Context, *ReverseTransferRequest) (*ReverseTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseTransfer not implemented")
+}
+func (UnimplementedSwiftBankServer) ScheduleTransfer(context.Context, *ScheduleTransferRequest) (*ScheduleTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScheduleTransfer not implemented")
+}
+func (UnimplementedSwiftBankServer) CancelScheduledTransfer(context.Context, *CancelScheduledTransferRequest) (*CancelScheduledTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduledTransfer not implemented")
+}
+func (UnimplementedSwiftBankServer) CreateStandingOrder(context.Context, *CreateStandingOrderRequest) (*CreateStandingOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateStandingOrder not implemented")
+}
+func (UnimplementedSwiftBankServer) ListStandingOrders(context.Context, *ListStandingOrdersRequest) (*ListStandingOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStandingOrders not implemented")
+}
+func (UnimplementedSwiftBankServer) PauseStandingOrder(context.Context, *PauseStandingOrderRequest) (*PauseStandingOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseStandingOrder not implemented")
+}
+func (UnimplementedSwiftBankServer) ResumeStandingOrder(context.Context, *ResumeStandingOrderRequest) (*ResumeStandingOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeStandingOrder not implemented")
+}
+func (UnimplementedSwiftBankServer) DeleteStandingOrder(context.Context, *DeleteStandingOrderRequest) (*DeleteStandingOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStandingOrder not implemented")
+}
+func (UnimplementedSwiftBankServer) AddBeneficiary(context.Context, *AddBeneficiaryRequest) (*AddBeneficiaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBeneficiary not implemented")
+}
+func (UnimplementedSwiftBankServer) ListBeneficiaries(context.Context, *ListBeneficiariesRequest) (*ListBeneficiariesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBeneficiaries not implemented")
+}
+func (UnimplementedSwiftBankServer) DeleteBeneficiary(context.Context, *DeleteBeneficiaryRequest) (*DeleteBeneficiaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBeneficiary not implemented")
+}
+func (UnimplementedSwiftBankServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedSwiftBankServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedSwiftBankServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedSwiftBankServer) TestWebhook(context.Context, *TestWebhookRequest) (*TestWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestWebhook not implemented")
+}
+func (UnimplementedSwiftBankServer) CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAccount not implemented")
+}
+func (UnimplementedSwiftBankServer) SetOverdraftLimit(context.Context, *SetOverdraftLimitRequest) (*SetOverdraftLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOverdraftLimit not implemented")
+}
+func (UnimplementedSwiftBankServer) UpdateAccount(context.Context, *UpdateAccountRequest) (*UpdateAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAccount not implemented")
+}
+func (UnimplementedSwiftBankServer) SetTransactionCategory(context.Context, *SetTransactionCategoryRequest) (*SetTransactionCategoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTransactionCategory not implemented")
+}
+func (UnimplementedSwiftBankServer) BulkCreateAccounts(context.Context, *BulkCreateAccountsRequest) (*BulkCreateAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreateAccounts not implemented")
+}
+func (UnimplementedSwiftBankServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedSwiftBankServer) GetAccountSummary(context.Context, *GetAccountSummaryRequest) (*GetAccountSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountSummary not implemented")
+}
+func (UnimplementedSwiftBankServer) StreamTransactions(*StreamTransactionsRequest, SwiftBank_StreamTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTransactions not implemented")
+}
+func (UnimplementedSwiftBankServer) SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTransactions not implemented")
+}
+func (UnimplementedSwiftBankServer) ExportTransactions(context.Context, *ExportTransactionsRequest) (*ExportTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportTransactions not implemented")
+}
+func (UnimplementedSwiftBankServer) ListFailedTasks(context.Context, *ListFailedTasksRequest) (*ListFailedTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFailedTasks not implemented")
+}
+func (UnimplementedSwiftBankServer) RetryFailedTask(context.Context, *RetryFailedTaskRequest) (*RetryFailedTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryFailedTask not implemented")
+}
+func (UnimplementedSwiftBankServer) ListAuditLogs(context.Context, *ListAuditLogsRequest) (*ListAuditLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditLogs not implemented")
+}
+func (UnimplementedSwiftBankServer) AddCurrency(context.Context, *AddCurrencyRequest) (*AddCurrencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCurrency not implemented")
+}
+func (UnimplementedSwiftBankServer) DisableCurrency(context.Context, *DisableCurrencyRequest) (*DisableCurrencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableCurrency not implemented")
+}
+func (UnimplementedSwiftBankServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedSwiftBankServer) MigrationStatus(context.Context, *MigrationStatusRequest) (*MigrationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrationStatus not implemented")
+}
+func (UnimplementedSwiftBankServer) ServerInfo(context.Context, *ServerInfoRequest) (*ServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ServerInfo not implemented")
+}
+func (UnimplementedSwiftBankServer) mustEmbedUnimplementedSwiftBankServer() {}
+
+// UnsafeSwiftBankServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwiftBankServer will
+// result in compilation errors.
+type UnsafeSwiftBankServer interface {
+	mustEmbedUnimplementedSwiftBankServer()
+}
+
+func RegisterSwiftBankServer(s grpc.ServiceRegistrar, srv SwiftBankServer) {
+	s.RegisterService(&SwiftBank_ServiceDesc, srv)
+}
+
+func _SwiftBank_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_LoginUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).LoginUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_LoginUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).LoginUser(ctx, req.(*LoginUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).VerifyEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_VerifyEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).VerifyEmail(ctx, req.(*VerifyEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ResendVerificationEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResendVerificationEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ResendVerificationEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ResendVerificationEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ResendVerificationEmail(ctx, req.(*ResendVerificationEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_VerifyPhone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyPhoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).VerifyPhone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_VerifyPhone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).VerifyPhone(ctx, req.(*VerifyPhoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).GetNotificationPreferences(ctx, req.(*GetNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_UpdateNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).UpdateNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_UpdateNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).UpdateNotificationPreferences(ctx, req.(*UpdateNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_MarkNotificationRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkNotificationReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).MarkNotificationRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_MarkNotificationRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).MarkNotificationRead(ctx, req.(*MarkNotificationReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_UnreadNotificationCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnreadNotificationCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).UnreadNotificationCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_UnreadNotificationCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).UnreadNotificationCount(ctx, req.(*UnreadNotificationCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_GetStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).GetStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_GetStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).GetStatement(ctx, req.(*GetStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_RequestStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).RequestStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_RequestStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).RequestStatement(ctx, req.(*RequestStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListStatements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStatementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListStatements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListStatements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListStatements(ctx, req.(*ListStatementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_TransferMoney_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferMoneyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).TransferMoney(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_TransferMoney_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).TransferMoney(ctx, req.(*TransferMoneyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_GetTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).GetTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_GetTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).GetTransfer(ctx, req.(*GetTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransfersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListTransfers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListTransfers(ctx, req.(*ListTransfersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ReverseTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ReverseTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ReverseTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ReverseTransfer(ctx, req.(*ReverseTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ScheduleTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ScheduleTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ScheduleTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ScheduleTransfer(ctx, req.(*ScheduleTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_CancelScheduledTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).CancelScheduledTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_CancelScheduledTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).CancelScheduledTransfer(ctx, req.(*CancelScheduledTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_CreateStandingOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateStandingOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).CreateStandingOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_CreateStandingOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).CreateStandingOrder(ctx, req.(*CreateStandingOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListStandingOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStandingOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListStandingOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListStandingOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListStandingOrders(ctx, req.(*ListStandingOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_PauseStandingOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseStandingOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).PauseStandingOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_PauseStandingOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).PauseStandingOrder(ctx, req.(*PauseStandingOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ResumeStandingOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeStandingOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ResumeStandingOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ResumeStandingOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ResumeStandingOrder(ctx, req.(*ResumeStandingOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_DeleteStandingOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteStandingOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).DeleteStandingOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_DeleteStandingOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).DeleteStandingOrder(ctx, req.(*DeleteStandingOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_AddBeneficiary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBeneficiaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).AddBeneficiary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_AddBeneficiary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).AddBeneficiary(ctx, req.(*AddBeneficiaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListBeneficiaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBeneficiariesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListBeneficiaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListBeneficiaries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListBeneficiaries(ctx, req.(*ListBeneficiariesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_DeleteBeneficiary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBeneficiaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).DeleteBeneficiary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_DeleteBeneficiary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).DeleteBeneficiary(ctx, req.(*DeleteBeneficiaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_TestWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).TestWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_TestWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).TestWebhook(ctx, req.(*TestWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_CreateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).CreateAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_CreateAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).CreateAccount(ctx, req.(*CreateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_SetOverdraftLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOverdraftLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).SetOverdraftLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_SetOverdraftLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).SetOverdraftLimit(ctx, req.(*SetOverdraftLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_UpdateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).UpdateAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_UpdateAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).UpdateAccount(ctx, req.(*UpdateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_SetTransactionCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTransactionCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).SetTransactionCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_SetTransactionCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).SetTransactionCategory(ctx, req.(*SetTransactionCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_BulkCreateAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).BulkCreateAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_BulkCreateAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).BulkCreateAccounts(ctx, req.(*BulkCreateAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// SwiftBankServer is the server API for SwiftBank service.
-// All implementations must embed UnimplementedSwiftBankServer
-// for forward compatibility
-type SwiftBankServer interface {
-	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
-	LoginUser(context.Context, *LoginUserRequest) (*LoginUserResponse, error)
-	VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error)
-	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
-	mustEmbedUnimplementedSwiftBankServer()
+func _SwiftBank_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedSwiftBankServer must be embedded to have forward compatible implementations.
-type UnimplementedSwiftBankServer struct {
+func _SwiftBank_GetAccountSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).GetAccountSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_GetAccountSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).GetAccountSummary(ctx, req.(*GetAccountSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (UnimplementedSwiftBankServer) CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+func _SwiftBank_StreamTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwiftBankServer).StreamTransactions(m, &swiftBankStreamTransactionsServer{stream})
 }
-func (UnimplementedSwiftBankServer) LoginUser(context.Context, *LoginUserRequest) (*LoginUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method LoginUser not implemented")
+
+type SwiftBank_StreamTransactionsServer interface {
+	Send(*StreamTransactionsResponse) error
+	grpc.ServerStream
 }
-func (UnimplementedSwiftBankServer) VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method VerifyEmail not implemented")
+
+type swiftBankStreamTransactionsServer struct {
+	grpc.ServerStream
 }
-func (UnimplementedSwiftBankServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+
+func (x *swiftBankStreamTransactionsServer) Send(m *StreamTransactionsResponse) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (UnimplementedSwiftBankServer) mustEmbedUnimplementedSwiftBankServer() {}
 
-// UnsafeSwiftBankServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to SwiftBankServer will
-// result in compilation errors.
-type UnsafeSwiftBankServer interface {
-	mustEmbedUnimplementedSwiftBankServer()
+func _SwiftBank_SearchTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).SearchTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_SearchTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).SearchTransactions(ctx, req.(*SearchTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterSwiftBankServer(s grpc.ServiceRegistrar, srv SwiftBankServer) {
-	s.RegisterService(&SwiftBank_ServiceDesc, srv)
+func _SwiftBank_ExportTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ExportTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ExportTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ExportTransactions(ctx, req.(*ExportTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _SwiftBank_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateUserRequest)
+func _SwiftBank_ListFailedTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailedTasksRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SwiftBankServer).CreateUser(ctx, in)
+		return srv.(SwiftBankServer).ListFailedTasks(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: SwiftBank_CreateUser_FullMethodName,
+		FullMethod: SwiftBank_ListFailedTasks_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SwiftBankServer).CreateUser(ctx, req.(*CreateUserRequest))
+		return srv.(SwiftBankServer).ListFailedTasks(ctx, req.(*ListFailedTasksRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _SwiftBank_LoginUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginUserRequest)
+func _SwiftBank_RetryFailedTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryFailedTaskRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SwiftBankServer).LoginUser(ctx, in)
+		return srv.(SwiftBankServer).RetryFailedTask(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: SwiftBank_LoginUser_FullMethodName,
+		FullMethod: SwiftBank_RetryFailedTask_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SwiftBankServer).LoginUser(ctx, req.(*LoginUserRequest))
+		return srv.(SwiftBankServer).RetryFailedTask(ctx, req.(*RetryFailedTaskRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _SwiftBank_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VerifyEmailRequest)
+func _SwiftBank_ListAuditLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditLogsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SwiftBankServer).VerifyEmail(ctx, in)
+		return srv.(SwiftBankServer).ListAuditLogs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: SwiftBank_VerifyEmail_FullMethodName,
+		FullMethod: SwiftBank_ListAuditLogs_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SwiftBankServer).VerifyEmail(ctx, req.(*VerifyEmailRequest))
+		return srv.(SwiftBankServer).ListAuditLogs(ctx, req.(*ListAuditLogsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _SwiftBank_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateUserRequest)
+func _SwiftBank_AddCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCurrencyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SwiftBankServer).UpdateUser(ctx, in)
+		return srv.(SwiftBankServer).AddCurrency(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: SwiftBank_UpdateUser_FullMethodName,
+		FullMethod: SwiftBank_AddCurrency_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SwiftBankServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+		return srv.(SwiftBankServer).AddCurrency(ctx, req.(*AddCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_DisableCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).DisableCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_DisableCurrency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).DisableCurrency(ctx, req.(*DisableCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_MigrationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).MigrationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_MigrationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).MigrationStatus(ctx, req.(*MigrationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftBank_ServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftBankServer).ServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftBank_ServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftBankServer).ServerInfo(ctx, req.(*ServerInfoRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -210,11 +1756,197 @@ var SwiftBank_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "VerifyEmail",
 			Handler:    _SwiftBank_VerifyEmail_Handler,
 		},
+		{
+			MethodName: "ResendVerificationEmail",
+			Handler:    _SwiftBank_ResendVerificationEmail_Handler,
+		},
+		{
+			MethodName: "VerifyPhone",
+			Handler:    _SwiftBank_VerifyPhone_Handler,
+		},
 		{
 			MethodName: "UpdateUser",
 			Handler:    _SwiftBank_UpdateUser_Handler,
 		},
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _SwiftBank_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationPreferences",
+			Handler:    _SwiftBank_UpdateNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "ListNotifications",
+			Handler:    _SwiftBank_ListNotifications_Handler,
+		},
+		{
+			MethodName: "MarkNotificationRead",
+			Handler:    _SwiftBank_MarkNotificationRead_Handler,
+		},
+		{
+			MethodName: "UnreadNotificationCount",
+			Handler:    _SwiftBank_UnreadNotificationCount_Handler,
+		},
+		{
+			MethodName: "GetStatement",
+			Handler:    _SwiftBank_GetStatement_Handler,
+		},
+		{
+			MethodName: "RequestStatement",
+			Handler:    _SwiftBank_RequestStatement_Handler,
+		},
+		{
+			MethodName: "ListStatements",
+			Handler:    _SwiftBank_ListStatements_Handler,
+		},
+		{
+			MethodName: "TransferMoney",
+			Handler:    _SwiftBank_TransferMoney_Handler,
+		},
+		{
+			MethodName: "GetTransfer",
+			Handler:    _SwiftBank_GetTransfer_Handler,
+		},
+		{
+			MethodName: "ListTransfers",
+			Handler:    _SwiftBank_ListTransfers_Handler,
+		},
+		{
+			MethodName: "ReverseTransfer",
+			Handler:    _SwiftBank_ReverseTransfer_Handler,
+		},
+		{
+			MethodName: "ScheduleTransfer",
+			Handler:    _SwiftBank_ScheduleTransfer_Handler,
+		},
+		{
+			MethodName: "CancelScheduledTransfer",
+			Handler:    _SwiftBank_CancelScheduledTransfer_Handler,
+		},
+		{
+			MethodName: "CreateStandingOrder",
+			Handler:    _SwiftBank_CreateStandingOrder_Handler,
+		},
+		{
+			MethodName: "ListStandingOrders",
+			Handler:    _SwiftBank_ListStandingOrders_Handler,
+		},
+		{
+			MethodName: "PauseStandingOrder",
+			Handler:    _SwiftBank_PauseStandingOrder_Handler,
+		},
+		{
+			MethodName: "ResumeStandingOrder",
+			Handler:    _SwiftBank_ResumeStandingOrder_Handler,
+		},
+		{
+			MethodName: "DeleteStandingOrder",
+			Handler:    _SwiftBank_DeleteStandingOrder_Handler,
+		},
+		{
+			MethodName: "AddBeneficiary",
+			Handler:    _SwiftBank_AddBeneficiary_Handler,
+		},
+		{
+			MethodName: "ListBeneficiaries",
+			Handler:    _SwiftBank_ListBeneficiaries_Handler,
+		},
+		{
+			MethodName: "DeleteBeneficiary",
+			Handler:    _SwiftBank_DeleteBeneficiary_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _SwiftBank_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _SwiftBank_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _SwiftBank_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "TestWebhook",
+			Handler:    _SwiftBank_TestWebhook_Handler,
+		},
+		{
+			MethodName: "CreateAccount",
+			Handler:    _SwiftBank_CreateAccount_Handler,
+		},
+		{
+			MethodName: "SetOverdraftLimit",
+			Handler:    _SwiftBank_SetOverdraftLimit_Handler,
+		},
+		{
+			MethodName: "UpdateAccount",
+			Handler:    _SwiftBank_UpdateAccount_Handler,
+		},
+		{
+			MethodName: "SetTransactionCategory",
+			Handler:    _SwiftBank_SetTransactionCategory_Handler,
+		},
+		{
+			MethodName: "BulkCreateAccounts",
+			Handler:    _SwiftBank_BulkCreateAccounts_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _SwiftBank_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetAccountSummary",
+			Handler:    _SwiftBank_GetAccountSummary_Handler,
+		},
+		{
+			MethodName: "SearchTransactions",
+			Handler:    _SwiftBank_SearchTransactions_Handler,
+		},
+		{
+			MethodName: "ExportTransactions",
+			Handler:    _SwiftBank_ExportTransactions_Handler,
+		},
+		{
+			MethodName: "ListFailedTasks",
+			Handler:    _SwiftBank_ListFailedTasks_Handler,
+		},
+		{
+			MethodName: "RetryFailedTask",
+			Handler:    _SwiftBank_RetryFailedTask_Handler,
+		},
+		{
+			MethodName: "ListAuditLogs",
+			Handler:    _SwiftBank_ListAuditLogs_Handler,
+		},
+		{
+			MethodName: "AddCurrency",
+			Handler:    _SwiftBank_AddCurrency_Handler,
+		},
+		{
+			MethodName: "DisableCurrency",
+			Handler:    _SwiftBank_DisableCurrency_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _SwiftBank_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "MigrationStatus",
+			Handler:    _SwiftBank_MigrationStatus_Handler,
+		},
+		{
+			MethodName: "ServerInfo",
+			Handler:    _SwiftBank_ServerInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTransactions",
+			Handler:       _SwiftBank_StreamTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service_swift_bank.proto",
 }
@@ -0,0 +1,181 @@
+// Package pdf writes simple PDF documents: pages of text in the standard Helvetica fonts, lines
+// and filled rectangles. It is just enough for statements and needs no fonts to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Color is an RGB color with components from 0 to 1
+type Color struct {
+	R, G, B float64
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{1, 1, 1}
+)
+
+var ErrNoPages = errors.New("pdf has no pages")
+
+// Document is a PDF under construction. Coordinates are in points from the bottom left corner
+// of the page, as in PDF itself.
+type Document struct {
+	Title string
+	pages []*Page
+}
+
+// Page collects the drawing operations of one page
+type Page struct {
+	content bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// AddPage appends an A4 page to the document
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// SetFill sets the color of the text and rectangles drawn after it
+func (p *Page) SetFill(c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s rg\n", num(c.R), num(c.G), num(c.B))
+}
+
+// SetStroke sets the color of the lines drawn after it
+func (p *Page) SetStroke(c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s RG\n", num(c.R), num(c.G), num(c.B))
+}
+
+// Rect fills a rectangle whose bottom left corner is at x, y
+func (p *Page) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(y), num(width), num(height))
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Text draws s with its baseline starting at x, y
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(y), encode(s))
+}
+
+// TextRight draws s so that it ends at right
+func (p *Page) TextRight(right, y float64, font Font, size float64, s string) {
+	p.Text(right-TextWidth(s, font, size), y, font, size, s)
+}
+
+// WriteTo writes the document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		return 0, ErrNoPages
+	}
+
+	cw := &countingWriter{w: w}
+	var offsets []int64
+	object := func(format string, args ...any) {
+		offsets = append(offsets, cw.n)
+		fmt.Fprintf(cw, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(cw, format, args...)
+		io.WriteString(cw, "\nendobj\n")
+	}
+
+	// the binary comment tells transfer programs the file is not text
+	io.WriteString(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1 to 5 come first, then each page followed by its content
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Title (%s) /Producer (Swift Bank) >>", encode(d.Title))
+
+	for i, page := range d.pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(A4Width), num(A4Height), firstPage+2*i+1)
+		object("<< /Length %d >>\nstream\n%s\nendstream", page.content.Len(), page.content.Bytes())
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return cw.n, cw.err
+}
+
+// countingWriter counts what was written and keeps the first error, so WriteTo checks it once
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// encode escapes s as a PDF string in WinAnsiEncoding. Latin-1 characters are kept,
+// anything else becomes a question mark.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r == 0x7f:
+			b.WriteByte(' ')
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
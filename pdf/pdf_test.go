@@ -0,0 +1,85 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Statement (March)")
+	first := doc.AddPage()
+	first.SetFill(Color{0.1, 0.2, 0.3})
+	first.Rect(10, 20, 30, 40)
+	first.Text(50, 60, Bold, 12, `a (b) \ c`)
+	second := doc.AddPage()
+	second.Line(0, 0, 100, 100, 0.5)
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+
+	out := buf.String()
+	require.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	require.Contains(t, out, "/Kids [6 0 R 8 0 R] /Count 2")
+	require.Contains(t, out, "/Title (Statement \\(March\\))")
+	require.Contains(t, out, "0.1 0.2 0.3 rg\n10 20 30 40 re f\n")
+	require.Contains(t, out, `BT /F2 12 Tf 50 60 Td (a \(b\) \\ c) Tj ET`)
+
+	// every object starts where the cross reference table says it does
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	require.NotNil(t, xref)
+	start, err := strconv.Atoi(xref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out[start:], "xref\n0 10\n"))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllStringSubmatch(out[start:], -1)
+	require.Len(t, entries, 9)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(out[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+
+	// and each stream is as long as it says
+	streams := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllStringSubmatch(out, -1)
+	require.Len(t, streams, 2)
+	for _, stream := range streams {
+		require.Equal(t, stream[1], strconv.Itoa(len(stream[2])))
+	}
+}
+
+func TestWriteToNoPages(t *testing.T) {
+	_, err := New("empty").WriteTo(&bytes.Buffer{})
+	require.ErrorIs(t, err, ErrNoPages)
+}
+
+func TestEncode(t *testing.T) {
+	require.Equal(t, "caf\\351 ? ok", encode("café € ok"))
+	require.Equal(t, "a b", encode("a\nb"))
+}
+
+func TestTextWidth(t *testing.T) {
+	require.Equal(t, 0.0, TextWidth("", Regular, 10))
+	require.InDelta(t, 5.56*3, TextWidth("120", Regular, 10), 1e-9)
+	require.InDelta(t, 5.84, TextWidth("~", Bold, 10), 1e-9)
+	require.InDelta(t, 2.78, TextWidth(" ", Bold, 10), 1e-9)
+	require.Greater(t, TextWidth("W", Regular, 10), TextWidth("i", Regular, 10))
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "short", Truncate("short", Regular, 10, 100))
+
+	long := strings.Repeat("transfer ", 10)
+	truncated := Truncate(long, Regular, 10, 100)
+	require.True(t, strings.HasSuffix(truncated, "..."))
+	require.LessOrEqual(t, TextWidth(truncated, Regular, 10), 100.0)
+	require.Empty(t, Truncate(long, Regular, 10, 5))
+}
@@ -0,0 +1,92 @@
+// Package ratelimit counts requests per key in a sliding window kept in redis, so the limits hold
+// across replicas
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit like "5/1m"
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid limit %q, want requests/period", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q, requests must be a positive integer", value)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q, period must be a positive duration", value)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Limits holds the limit of each RPC, keyed by method name, and the limit of all the others
+type Limits struct {
+	Default Limit
+	Methods map[string]Limit
+}
+
+// ParseLimits parses the default limit and a list like "LoginUser:5/1m,TransferMoney:30/1m"
+func ParseLimits(defaultLimit, methodLimits string) (Limits, error) {
+	limit, err := ParseLimit(defaultLimit)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	limits := Limits{Default: limit, Methods: map[string]Limit{}}
+	if strings.TrimSpace(methodLimits) == "" {
+		return limits, nil
+	}
+
+	for _, pair := range strings.Split(methodLimits, ",") {
+		method, value, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || method == "" {
+			return Limits{}, fmt.Errorf("invalid method limit %q", pair)
+		}
+
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return Limits{}, err
+		}
+
+		limits.Methods[method] = limit
+	}
+
+	return limits, nil
+}
+
+// For returns the limit of method
+func (l Limits) For(method string) Limit {
+	if limit, ok := l.Methods[method]; ok {
+		return limit
+	}
+
+	return l.Default
+}
+
+// Result tells whether a request is allowed, and if not, when the next one will be
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether one more request under key fits in limit
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
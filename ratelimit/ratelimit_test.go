@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits("60/1m", "LoginUser:5/1m, TransferMoney:30/30s")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Requests: 60, Period: time.Minute}, limits.For("GetBalance"))
+	require.Equal(t, Limit{Requests: 5, Period: time.Minute}, limits.For("LoginUser"))
+	require.Equal(t, Limit{Requests: 30, Period: 30 * time.Second}, limits.For("TransferMoney"))
+
+	limits, err = ParseLimits("60/1m", "")
+	require.NoError(t, err)
+	require.Empty(t, limits.Methods)
+
+	for _, value := range []string{"", "60", "0/1m", "x/1m", "5/forever", "5/-1s"} {
+		_, err := ParseLimit(value)
+		require.Error(t, err, value)
+	}
+
+	_, err = ParseLimits("60/1m", "LoginUser=5/1m")
+	require.Error(t, err)
+}
+
+func TestRedisLimiter(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Now()
+	limiter := NewRedisLimiter(client)
+	limiter.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(ctx, "LoginUser:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	result, err := limiter.Allow(ctx, "LoginUser:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, time.Minute, result.RetryAfter)
+
+	// other keys have their own window
+	result, err = limiter.Allow(ctx, "LoginUser:ip:10.0.0.2", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// the window slides, once the first request is older than the period there is room again
+	now = now.Add(30 * time.Second)
+	result, err = limiter.Allow(ctx, "LoginUser:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+
+	now = now.Add(30*time.Second + time.Millisecond)
+	result, err = limiter.Allow(ctx, "LoginUser:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
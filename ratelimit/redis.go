@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces the rate limit keys in redis
+const keyPrefix = "ratelimit:"
+
+// slidingWindow keeps a sorted set of request times per key. It drops the ones that left the
+// window, then records the request if there is room. It returns 0 when the request is allowed,
+// and otherwise the milliseconds until the oldest request leaves the window.
+var slidingWindow = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+if redis.call("ZCARD", KEYS[1]) < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return 0
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return math.max(tonumber(oldest[2]) + window - now, 1)
+`)
+
+// RedisLimiter is a sliding window limiter whose counts live in redis
+type RedisLimiter struct {
+	client redis.Scripter
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client redis.Scripter) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := l.now().UnixMilli()
+	member := strconv.FormatInt(now, 10) + "-" + strconv.FormatUint(rand.Uint64(), 36) // requests in the same millisecond count apart
+
+	wait, err := slidingWindow.Run(ctx, l.client, []string{keyPrefix + key},
+		now, limit.Period.Milliseconds(), limit.Requests, member).Int64()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if wait == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	return Result{RetryAfter: time.Duration(wait) * time.Millisecond}, nil
+}
@@ -0,0 +1,180 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// httpAttempts is how many times a fetch is tried before it counts as failed
+	httpAttempts = 3
+	// httpBackoff is the wait before the first retry, it doubles for each one after
+	httpBackoff = 500 * time.Millisecond
+	// breakerThreshold failed fetches in a row open the circuit for breakerCooldown
+	breakerThreshold = 5
+	breakerCooldown  = time.Minute
+)
+
+// errRetryable marks a failed fetch that may succeed when tried again
+var errRetryable = errors.New("retryable")
+
+// HTTPProvider fetches rates from a JSON endpoint. A GET of the endpoint with a base query
+// parameter must answer with every rate of that base:
+//
+//	{"base": "USD", "timestamp": 1718000000, "rates": {"EUR": 0.92, "CAD": 1.37}}
+//
+// timestamp is when the source published the rates, in unix seconds. The rates of a base are kept
+// for cacheTTL, so looking up the pairs of a base one by one makes a single request.
+type HTTPProvider struct {
+	endpoint   string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+	breaker    *breaker
+	backoff    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedRates // by base
+}
+
+type cachedRates struct {
+	rates     map[string]float64
+	fetchedAt time.Time // when the source published them
+	expiresAt time.Time
+}
+
+type ratesResponse struct {
+	Base      string             `json:"base"`
+	Timestamp int64              `json:"timestamp"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+func NewHTTPProvider(endpoint string, httpClient *http.Client, cacheTTL time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		endpoint:   endpoint,
+		httpClient: httpClient,
+		cacheTTL:   cacheTTL,
+		breaker:    newBreaker(breakerThreshold, breakerCooldown),
+		backoff:    httpBackoff,
+		cache:      make(map[string]cachedRates),
+	}
+}
+
+func (p *HTTPProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Rate{Base: base, Quote: quote, Value: 1, FetchedAt: time.Now()}, nil
+	}
+
+	cached, err := p.ratesOf(ctx, base)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	value, ok := cached.rates[quote]
+	if !ok || value <= 0 {
+		return Rate{}, ErrRateUnavailable
+	}
+
+	return Rate{Base: base, Quote: quote, Value: value, FetchedAt: cached.fetchedAt}, nil
+}
+
+// ratesOf returns the rates of base from the cache, fetching them when they expired
+func (p *HTTPProvider) ratesOf(ctx context.Context, base string) (cachedRates, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[base]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	if !p.breaker.allow() {
+		return cachedRates{}, ErrCircuitOpen
+	}
+	cached, err := p.fetchWithRetry(ctx, base)
+	p.breaker.done(err)
+	if err != nil {
+		return cachedRates{}, err
+	}
+
+	p.mu.Lock()
+	p.cache[base] = cached
+	p.mu.Unlock()
+
+	return cached, nil
+}
+
+// fetchWithRetry tries a fetch up to httpAttempts times, backing off between the tries that may
+// succeed when repeated
+func (p *HTTPProvider) fetchWithRetry(ctx context.Context, base string) (cachedRates, error) {
+	wait := p.backoff
+	for attempt := 1; ; attempt++ {
+		cached, err := p.fetch(ctx, base)
+		if err == nil || !errors.Is(err, errRetryable) || attempt == httpAttempts {
+			return cached, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return cachedRates{}, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (p *HTTPProvider) fetch(ctx context.Context, base string) (cachedRates, error) {
+	endpoint, err := url.Parse(p.endpoint)
+	if err != nil {
+		return cachedRates{}, fmt.Errorf("invalid exchange rate endpoint: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("base", base)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return cachedRates{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	rsp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return cachedRates{}, err
+		}
+		return cachedRates{}, fmt.Errorf("failed to fetch %s rates: %w: %w", base, errRetryable, err)
+	}
+	defer rsp.Body.Close()
+
+	switch {
+	case rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500:
+		return cachedRates{}, fmt.Errorf("failed to fetch %s rates: %w: provider responded with status %d", base, errRetryable, rsp.StatusCode)
+	case rsp.StatusCode < 200 || rsp.StatusCode > 299:
+		return cachedRates{}, fmt.Errorf("failed to fetch %s rates: provider responded with status %d", base, rsp.StatusCode)
+	}
+
+	var body ratesResponse
+	if err := json.NewDecoder(io.LimitReader(rsp.Body, 1<<20)).Decode(&body); err != nil {
+		return cachedRates{}, fmt.Errorf("failed to decode %s rates: %w", base, err)
+	}
+	if body.Base != base {
+		return cachedRates{}, fmt.Errorf("asked for %s rates, provider answered with %q", base, body.Base)
+	}
+
+	fetchedAt := time.Now()
+	if body.Timestamp > 0 {
+		fetchedAt = time.Unix(body.Timestamp, 0)
+	}
+
+	return cachedRates{
+		rates:     body.Rates,
+		fetchedAt: fetchedAt,
+		expiresAt: time.Now().Add(p.cacheTTL),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHTTPProvider serves rates from handler, retrying without waiting
+func newTestHTTPProvider(t *testing.T, handler http.HandlerFunc) *HTTPProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := NewHTTPProvider(server.URL+"/latest?app_id=secret", server.Client(), time.Minute)
+	provider.backoff = time.Millisecond
+	return provider
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var requests atomic.Int32
+	provider := newTestHTTPProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "secret", r.URL.Query().Get("app_id"))
+		fmt.Fprintf(w, `{"base": %q, "timestamp": 1718000000, "rates": {"EUR": 0.92, "CAD": 1.37}}`, r.URL.Query().Get("base"))
+	})
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, 0.92, rate.Value)
+	require.Equal(t, time.Unix(1718000000, 0), rate.FetchedAt)
+
+	// the other rates of the base come from the cache
+	rate, err = provider.GetRate(context.Background(), "USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, 1.37, rate.Value)
+	require.Equal(t, int32(1), requests.Load())
+
+	_, err = provider.GetRate(context.Background(), "USD", "JPY")
+	require.ErrorIs(t, err, ErrRateUnavailable)
+}
+
+func TestHTTPProviderRetries(t *testing.T) {
+	var requests atomic.Int32
+	provider := newTestHTTPProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < httpAttempts {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"base": "USD", "rates": {"EUR": 0.92}}`)
+	})
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, 0.92, rate.Value)
+	require.WithinDuration(t, time.Now(), rate.FetchedAt, time.Second)
+	require.Equal(t, int32(httpAttempts), requests.Load())
+}
+
+func TestHTTPProviderClientErrorNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	provider := newTestHTTPProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	_, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.Error(t, err)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestHTTPProviderCircuitBreaker(t *testing.T) {
+	var requests, failing atomic.Int32
+	failing.Store(1)
+	provider := newTestHTTPProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"base": "USD", "rates": {"EUR": 0.92}}`)
+	})
+	provider.breaker = newBreaker(2, 50*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		_, err := provider.GetRate(context.Background(), "USD", "EUR")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	require.Equal(t, int32(2*httpAttempts), requests.Load())
+
+	// open, the provider isn't called
+	_, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, int32(2*httpAttempts), requests.Load())
+
+	// after the cooldown a call goes through and closes it again
+	failing.Store(0)
+	time.Sleep(60 * time.Millisecond)
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, 0.92, rate.Value)
+	require.Zero(t, provider.breaker.failures)
+}
@@ -0,0 +1,63 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+	ErrRateStale       = errors.New("exchange rate is stale")
+)
+
+// Rate is the price of one unit of Base expressed in Quote
+type Rate struct {
+	Base      string
+	Quote     string
+	Value     float64
+	FetchedAt time.Time
+}
+
+// Provider looks up exchange rates. Implementations return ErrRateUnavailable
+// when they have no rate for the requested pair.
+type Provider interface {
+	GetRate(ctx context.Context, base, quote string) (Rate, error)
+}
+
+// StaticProvider serves a fixed set of rates, the inverse of every rate is derived automatically
+type StaticProvider struct {
+	rates map[string]Rate
+}
+
+func NewStaticProvider(rates ...Rate) *StaticProvider {
+	p := &StaticProvider{
+		rates: make(map[string]Rate),
+	}
+
+	for _, rate := range rates {
+		p.rates[pairKey(rate.Base, rate.Quote)] = rate
+	}
+
+	return p
+}
+
+func (p *StaticProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Rate{Base: base, Quote: quote, Value: 1, FetchedAt: time.Now()}, nil
+	}
+
+	if rate, ok := p.rates[pairKey(base, quote)]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := p.rates[pairKey(quote, base)]; ok && rate.Value != 0 {
+		return Rate{Base: base, Quote: quote, Value: 1 / rate.Value, FetchedAt: rate.FetchedAt}, nil
+	}
+
+	return Rate{}, ErrRateUnavailable
+}
+
+func pairKey(base, quote string) string {
+	return base + "/" + quote
+}
@@ -0,0 +1,33 @@
+package rates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	fetchedAt := time.Now().Add(-time.Hour)
+	provider := NewStaticProvider(Rate{Base: "USD", Quote: "EUR", Value: 0.5, FetchedAt: fetchedAt})
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, 0.5, rate.Value)
+	require.Equal(t, fetchedAt, rate.FetchedAt)
+
+	// inverse pair
+	rate, err = provider.GetRate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, rate.Value)
+	require.Equal(t, "EUR", rate.Base)
+
+	// identity
+	rate, err = provider.GetRate(context.Background(), "CAD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, rate.Value)
+
+	_, err = provider.GetRate(context.Background(), "USD", "CAD")
+	require.ErrorIs(t, err, ErrRateUnavailable)
+}
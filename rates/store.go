@@ -0,0 +1,70 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// Store is the subset of the repository the StoreProvider reads from
+type Store interface {
+	GetExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error)
+}
+
+// StoreProvider serves the latest rates saved in the exchange_rates table
+type StoreProvider struct {
+	store  Store
+	maxAge time.Duration
+}
+
+// NewStoreProvider creates a provider that rejects rates older than maxAge, zero disables the check
+func NewStoreProvider(store Store, maxAge time.Duration) *StoreProvider {
+	return &StoreProvider{
+		store:  store,
+		maxAge: maxAge,
+	}
+}
+
+func (p *StoreProvider) GetRate(ctx context.Context, base, quote string) (Rate, error) {
+	if base == quote {
+		return Rate{Base: base, Quote: quote, Value: 1, FetchedAt: time.Now()}, nil
+	}
+
+	rate, err := p.lookup(ctx, base, quote)
+	if errors.Is(err, ErrRateUnavailable) {
+		// only one direction of a pair may be stored
+		inverse, invErr := p.lookup(ctx, quote, base)
+		if invErr != nil {
+			return Rate{}, invErr
+		}
+		rate = Rate{Base: base, Quote: quote, Value: 1 / inverse.Value, FetchedAt: inverse.FetchedAt}
+	} else if err != nil {
+		return Rate{}, err
+	}
+
+	if p.maxAge > 0 && time.Since(rate.FetchedAt) > p.maxAge {
+		return Rate{}, fmt.Errorf("%w: %s/%s fetched at %s", ErrRateStale, base, quote, rate.FetchedAt.Format(time.RFC3339))
+	}
+
+	return rate, nil
+}
+
+func (p *StoreProvider) lookup(ctx context.Context, base, quote string) (Rate, error) {
+	er, err := p.store.GetExchangeRate(ctx, base, quote)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Rate{}, ErrRateUnavailable
+		}
+		return Rate{}, err
+	}
+
+	if er.Rate <= 0 {
+		return Rate{}, ErrRateUnavailable
+	}
+
+	return Rate{Base: er.Base, Quote: er.Quote, Value: er.Rate, FetchedAt: er.FetchedAt}, nil
+}
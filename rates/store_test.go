@@ -0,0 +1,50 @@
+package rates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+type fakeStore map[string]models.ExchangeRate
+
+func (f fakeStore) GetExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	rate, ok := f[pairKey(base, quote)]
+	if !ok {
+		return models.ExchangeRate{}, pgx.ErrNoRows
+	}
+	return rate, nil
+}
+
+func TestStoreProvider(t *testing.T) {
+	store := fakeStore{
+		"USD/EUR": {Base: "USD", Quote: "EUR", Rate: 0.8, FetchedAt: time.Now().Add(-time.Minute)},
+		"USD/CAD": {Base: "USD", Quote: "CAD", Rate: 1.3, FetchedAt: time.Now().Add(-2 * time.Hour)},
+	}
+	provider := NewStoreProvider(store, time.Hour)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, 0.8, rate.Value)
+
+	// inverse pair
+	rate, err = provider.GetRate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, 1.25, rate.Value)
+
+	// stale
+	_, err = provider.GetRate(context.Background(), "USD", "CAD")
+	require.ErrorIs(t, err, ErrRateStale)
+
+	_, err = provider.GetRate(context.Background(), "EUR", "CAD")
+	require.ErrorIs(t, err, ErrRateUnavailable)
+
+	// zero max age never treats a rate as stale
+	rate, err = NewStoreProvider(store, 0).GetRate(context.Background(), "USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, 1.3, rate.Value)
+}
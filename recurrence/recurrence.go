@@ -0,0 +1,80 @@
+// Package recurrence computes the run dates of repeating schedules such as standing orders.
+package recurrence
+
+import (
+	"fmt"
+	"time"
+)
+
+// Supported frequencies
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+func IsSupportedFrequency(frequency string) bool {
+	switch frequency {
+	case Daily, Weekly, Monthly:
+		return true
+	}
+	return false
+}
+
+// Occurrence returns the n-th run of a schedule starting at start, the first run being n = 0.
+// Monthly schedules keep the day of month of start and fall back to the last day of
+// shorter months, so a schedule starting on the 31st runs on Feb 28th (or 29th).
+func Occurrence(start time.Time, frequency string, n int) (time.Time, error) {
+	switch frequency {
+	case Daily:
+		return start.AddDate(0, 0, n), nil
+	case Weekly:
+		return start.AddDate(0, 0, 7*n), nil
+	case Monthly:
+		year, month := start.Year(), start.Month()+time.Month(n)
+		day := start.Day()
+		if last := daysIn(year, month, start.Location()); day > last {
+			day = last
+		}
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location()), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported frequency: %s", frequency)
+}
+
+// Next returns the first run of the schedule strictly after after
+func Next(start time.Time, frequency string, after time.Time) (time.Time, error) {
+	if after.Before(start) {
+		return start, nil
+	}
+
+	// jump close to after instead of walking every run since start
+	var n int
+	switch frequency {
+	case Daily:
+		n = int(after.Sub(start) / (24 * time.Hour))
+	case Weekly:
+		n = int(after.Sub(start) / (7 * 24 * time.Hour))
+	case Monthly:
+		n = (after.Year()-start.Year())*12 + int(after.Month()-start.Month()) - 1
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	for {
+		run, err := Occurrence(start, frequency, n)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if run.After(after) {
+			return run, nil
+		}
+		n++
+	}
+}
+
+// daysIn returns the number of days of month, month may overflow into the next years
+func daysIn(year int, month time.Month, loc *time.Location) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+}
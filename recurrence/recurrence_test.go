@@ -0,0 +1,92 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrenceMonthlyEndOfMonth(t *testing.T) {
+	start := date(2024, time.January, 31)
+
+	expected := []time.Time{
+		date(2024, time.January, 31),
+		date(2024, time.February, 29), // leap year
+		date(2024, time.March, 31),
+		date(2024, time.April, 30),
+	}
+
+	for n, want := range expected {
+		got, err := Occurrence(start, Monthly, n)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	// the shortened month doesn't shift later runs
+	got, err := Occurrence(start, Monthly, 13)
+	require.NoError(t, err)
+	require.Equal(t, date(2025, time.February, 28), got)
+}
+
+func TestNext(t *testing.T) {
+	start := date(2024, time.January, 31)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		after     time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "BEFORE START",
+			frequency: Monthly,
+			after:     date(2024, time.January, 1),
+			expected:  start,
+		},
+		{
+			name:      "MONTHLY AFTER FIRST RUN",
+			frequency: Monthly,
+			after:     start,
+			expected:  date(2024, time.February, 29),
+		},
+		{
+			name:      "MONTHLY MID MONTH",
+			frequency: Monthly,
+			after:     date(2024, time.June, 15),
+			expected:  date(2024, time.June, 30),
+		},
+		{
+			name:      "DAILY",
+			frequency: Daily,
+			after:     date(2024, time.February, 1).Add(time.Hour),
+			expected:  date(2024, time.February, 2),
+		},
+		{
+			name:      "WEEKLY",
+			frequency: Weekly,
+			after:     date(2024, time.February, 7),
+			expected:  date(2024, time.February, 14),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := Next(start, tc.frequency, tc.after)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+}
+
+func TestUnsupportedFrequency(t *testing.T) {
+	_, err := Next(date(2024, time.January, 1), "yearly", date(2024, time.March, 1))
+	require.Error(t, err)
+	require.False(t, IsSupportedFrequency("yearly"))
+}
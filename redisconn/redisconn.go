@@ -0,0 +1,134 @@
+// Package redisconn builds the connections to redis, directly or through sentinel, so asynq and
+// the go-redis client reach the same server the same way
+package redisconn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// Options is how to reach redis. With a sentinel master set the master is asked of the
+// sentinels and Address is not used.
+type Options struct {
+	Address           string
+	Password          string
+	DB                int
+	TLS               bool
+	CAFile            string // verifies the server certificate instead of the system roots
+	SentinelMaster    string
+	SentinelAddresses []string
+}
+
+// SplitAddresses splits comma separated addresses, like those of the sentinels
+func SplitAddresses(addresses string) []string {
+	var split []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			split = append(split, address)
+		}
+	}
+	return split
+}
+
+// AsynqOpt returns the options the task distributor, processor, scheduler and inspector connect
+// with
+func (o Options) AsynqOpt() (asynq.RedisConnOpt, error) {
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.SentinelMaster != "" {
+		return asynq.RedisFailoverClientOpt{
+			MasterName:    o.SentinelMaster,
+			SentinelAddrs: o.SentinelAddresses,
+			Password:      o.Password,
+			DB:            o.DB,
+			TLSConfig:     tlsConfig,
+		}, nil
+	}
+
+	return asynq.RedisClientOpt{
+		Addr:      o.Address,
+		Password:  o.Password,
+		DB:        o.DB,
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+// NewClient returns the go-redis client. credentials, when not nil, gives the password of each
+// new connection, so a rotated one is picked up without a restart. The failover client of
+// go-redis takes no credentials provider, through sentinel a rotated password needs a restart.
+func (o Options) NewClient(credentials func() (string, string)) (*redis.Client, error) {
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.SentinelMaster != "" {
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    o.SentinelMaster,
+			SentinelAddrs: o.SentinelAddresses,
+			Password:      o.Password,
+			DB:            o.DB,
+			TLSConfig:     tlsConfig,
+		}), nil
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:                o.Address,
+		Password:            o.Password,
+		CredentialsProvider: credentials,
+		DB:                  o.DB,
+		TLSConfig:           tlsConfig,
+	}), nil
+}
+
+// tlsConfig returns nil for plaintext. The server name is left to be taken from the address
+// dialed, with sentinel that is only known once the master is.
+func (o Options) tlsConfig() (*tls.Config, error) {
+	if !o.TLS {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(o.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read redis CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.New("redis CA file holds no PEM certificate")
+	}
+	tlsConfig.RootCAs = roots
+
+	return tlsConfig, nil
+}
+
+// Ping checks redis answers through opt, so a wrong address or password stops the start
+// instead of failing the first task enqueued
+func Ping(ctx context.Context, opt asynq.RedisConnOpt) error {
+	client, ok := opt.MakeRedisClient().(redis.UniversalClient)
+	if !ok {
+		return fmt.Errorf("unexpected redis client %T", opt.MakeRedisClient())
+	}
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("cannot reach redis: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package redisconn
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAddresses(t *testing.T) {
+	require.Equal(t, []string{"s1:26379", "s2:26379"}, SplitAddresses(" s1:26379, ,s2:26379 "))
+	require.Empty(t, SplitAddresses(""))
+}
+
+func TestAsynqOptDirect(t *testing.T) {
+	opt, err := Options{Address: "redis:6379", Password: "secret", DB: 2}.AsynqOpt()
+	require.NoError(t, err)
+
+	clientOpt, ok := opt.(asynq.RedisClientOpt)
+	require.True(t, ok)
+	require.Equal(t, "redis:6379", clientOpt.Addr)
+	require.Equal(t, "secret", clientOpt.Password)
+	require.Equal(t, 2, clientOpt.DB)
+	require.Nil(t, clientOpt.TLSConfig)
+}
+
+func TestAsynqOptSentinel(t *testing.T) {
+	options := Options{
+		Address:           "ignored:6379",
+		Password:          "secret",
+		DB:                1,
+		TLS:               true,
+		SentinelMaster:    "mymaster",
+		SentinelAddresses: []string{"s1:26379", "s2:26379"},
+	}
+
+	opt, err := options.AsynqOpt()
+	require.NoError(t, err)
+
+	failoverOpt, ok := opt.(asynq.RedisFailoverClientOpt)
+	require.True(t, ok)
+	require.Equal(t, "mymaster", failoverOpt.MasterName)
+	require.Equal(t, []string{"s1:26379", "s2:26379"}, failoverOpt.SentinelAddrs)
+	require.Equal(t, "secret", failoverOpt.Password)
+	require.Equal(t, 1, failoverOpt.DB)
+	require.NotNil(t, failoverOpt.TLSConfig)
+	require.Nil(t, failoverOpt.TLSConfig.RootCAs) // the system roots
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := Options{Address: "redis:6379", DB: 3}.NewClient(func() (string, string) { return "", "rotated" })
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	require.Equal(t, "redis:6379", client.Options().Addr)
+	require.Equal(t, 3, client.Options().DB)
+	_, password := client.Options().CredentialsProvider()
+	require.Equal(t, "rotated", password)
+
+	client, err = Options{SentinelMaster: "mymaster", SentinelAddresses: []string{"s1:26379"}}.NewClient(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	require.Equal(t, "FailoverClient", client.Options().Addr)
+}
+
+func TestTLSConfigCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certificate, 0o600))
+
+	opt, err := Options{Address: "redis:6379", TLS: true, CAFile: caFile}.AsynqOpt()
+	require.NoError(t, err)
+	require.NotNil(t, opt.(asynq.RedisClientOpt).TLSConfig.RootCAs)
+
+	// a CA file without TLS is left alone, the config refuses it
+	opt, err = Options{Address: "redis:6379", CAFile: caFile}.AsynqOpt()
+	require.NoError(t, err)
+	require.Nil(t, opt.(asynq.RedisClientOpt).TLSConfig)
+
+	notPEM := filepath.Join(dir, "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = Options{Address: "redis:6379", TLS: true, CAFile: notPEM}.AsynqOpt()
+	require.EqualError(t, err, "redis CA file holds no PEM certificate")
+
+	_, err = Options{Address: "redis:6379", TLS: true, CAFile: filepath.Join(dir, "missing.pem")}.NewClient(nil)
+	require.ErrorContains(t, err, "cannot read redis CA file")
+}
+
+func TestPing(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	opt, err := Options{Address: server.Addr(), Password: "secret"}.AsynqOpt()
+	require.NoError(t, err)
+	require.NoError(t, Ping(context.Background(), opt))
+
+	opt, err = Options{Address: server.Addr(), Password: "wrong"}.AsynqOpt()
+	require.NoError(t, err)
+	require.ErrorContains(t, Ping(context.Background(), opt), "cannot reach redis")
+}
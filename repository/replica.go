@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/database"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// replicaRetryAfter is how long reads stay on the primary after the replica failed one
+const replicaRetryAfter = 10 * time.Second
+
+// usersReadKey is what reads and writes of users are tracked under for read-your-writes
+const usersReadKey = "users"
+
+// accountReadKey is what reads and writes of the entries and transfers of an account are
+// tracked under for read-your-writes
+func accountReadKey(id int64) string {
+	return "account:" + strconv.FormatInt(id, 10)
+}
+
+// transferReadKeys are the keys a transfer writes under, the accounts it posts entries to
+func transferReadKeys(arg models.TransferTxParams) []string {
+	keys := []string{accountReadKey(arg.FromAccountID), accountReadKey(arg.ToAccountID)}
+	if arg.FeeAccountID != 0 {
+		keys = append(keys, accountReadKey(arg.FeeAccountID))
+	}
+	return keys
+}
+
+// Replica is a read replica of the database
+type Replica struct {
+	DB database.DB
+	// ReadYourWritesWindow is how long data written through the repository is read from the
+	// primary, for the replica to catch up with it
+	ReadYourWritesWindow time.Duration
+}
+
+// readRouter sends the reads that can be a little stale to a read replica. Whatever this process
+// wrote is read from the primary for a while afterwards, so a user sees their own writes despite
+// replication lag. Writes made by another instance aren't known here, so a user whose requests
+// are spread across instances can still see data as old as the lag.
+//
+// A replica that fails a read hands its reads to the primary for replicaRetryAfter. A nil router
+// sends everything to the primary.
+type readRouter struct {
+	replica *pgxpool.Pool
+	window  time.Duration
+
+	mu        sync.Mutex
+	written   map[string]time.Time // when each key was last written
+	sweptAt   time.Time
+	downUntil time.Time
+}
+
+func newReadRouter(replica *Replica) *readRouter {
+	if replica == nil || replica.DB == nil {
+		return nil
+	}
+
+	return &readRouter{
+		replica: replica.DB.Pool(),
+		window:  replica.ReadYourWritesWindow,
+		written: make(map[string]time.Time),
+	}
+}
+
+// pool returns the pool a read of the data under keys should go to
+func (r *readRouter) pool(primary *pgxpool.Pool, keys ...string) *pgxpool.Pool {
+	if r == nil {
+		return primary
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(r.downUntil) {
+		return primary
+	}
+	for _, key := range keys {
+		if now.Sub(r.written[key]) < r.window {
+			return primary
+		}
+	}
+
+	return r.replica
+}
+
+// wrote notes that the data under keys was just written
+func (r *readRouter) wrote(keys ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		r.written[key] = now
+	}
+
+	// forget the writes the replica has had time to catch up with
+	if now.Sub(r.sweptAt) >= r.window {
+		for key, at := range r.written {
+			if now.Sub(at) >= r.window {
+				delete(r.written, key)
+			}
+		}
+		r.sweptAt = now
+	}
+}
+
+// failed takes the replica out of use for replicaRetryAfter
+func (r *readRouter) failed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Now().After(r.downUntil) {
+		log.Warn().Err(err).Dur("retry_after", replicaRetryAfter).Msg("read replica failed, reading from the primary")
+	}
+	r.downUntil = time.Now().Add(replicaRetryAfter)
+}
+
+// readQuery runs a read of the data under keys on the pool the router picks. A replica that
+// can't be reached is taken out of use and the read runs on the primary instead.
+func (r *repositoryImpl) readQuery(ctx context.Context, keys []string, query string, args ...any) (pgx.Rows, error) {
+	pool := r.reads.pool(r.pool, keys...)
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil && pool != r.pool && replicaUnreachable(ctx, err) {
+		r.reads.failed(err)
+		return r.pool.Query(ctx, query, args...)
+	}
+
+	return rows, err
+}
+
+// replicaUnreachable reports whether err is the replica failing rather than the query, which
+// would fail the same way on the primary
+func replicaUnreachable(ctx context.Context, err error) bool {
+	var pgErr *pgconn.PgError
+	return ctx.Err() == nil && !errors.As(err, &pgErr)
+}
@@ -2,44 +2,185 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zde37/Swift_Bank/database"
 	"github.com/zde37/Swift_Bank/models"
 )
 
+var (
+	// ErrDuplicateIdempotencyKey is returned when a live idempotency key has already been claimed
+	ErrDuplicateIdempotencyKey = errors.New("idempotency key already used")
+	// ErrTransferAlreadyReversed is returned when a transaction already has a reversal
+	ErrTransferAlreadyReversed = errors.New("transfer has already been reversed")
+	// ErrReversalNotCovered is returned when the recipient's balance can't cover a reversal
+	ErrReversalNotCovered = errors.New("recipient balance does not cover the reversal")
+	// ErrInsufficientFunds is returned when a transfer would take the sender past its overdraft limit
+	ErrInsufficientFunds = errors.New("sender does not have sufficient funds to transfer")
+	// ErrTransferLimitReached is returned when a savings account already made its transfers of the month
+	ErrTransferLimitReached = errors.New("monthly outgoing transfer limit reached")
+	// ErrFeeAccountCurrency is returned when the fee account of a transfer holds another currency
+	ErrFeeAccountCurrency = errors.New("fee account is in a different currency")
+	// ErrDuplicateNickname is returned when a user already has a beneficiary with the nickname
+	ErrDuplicateNickname = errors.New("a beneficiary with this nickname already exists")
+	// ErrAccountExists is returned when the owner already has an account in the currency
+	ErrAccountExists = errors.New("owner already has an account in this currency")
+	// ErrSavingsOverdraft is returned when a savings account is given an overdraft limit
+	ErrSavingsOverdraft = errors.New("savings accounts cannot be overdrawn")
+	// ErrAccountNicknameExists is returned when another account of the owner has the nickname
+	ErrAccountNicknameExists = errors.New("another account already has this nickname")
+	// ErrUnknownCategory is returned when an entry is filed under a category that doesn't exist
+	ErrUnknownCategory = errors.New("category does not exist")
+	// ErrCurrencyExponentMismatch is returned when a currency is added again with another minor unit
+	ErrCurrencyExponentMismatch = errors.New("currency already exists with a different minor unit exponent")
+	// ErrWrongPhoneCode is returned when a phone verification code does not match, the guess still counts
+	ErrWrongPhoneCode = errors.New("phone verification code does not match")
+)
+
+// userUniqueFields are the unique constraints of users by the field they keep unique, the
+// lower() indexes catch a taken value in another case
+var userUniqueFields = map[string]string{
+	"users_pkey":               "username",
+	"users_lower_username_key": "username",
+	"users_email_key":          "email",
+	"users_lower_email_key":    "email",
+}
+
+// TakenUserField names the field, username or email, of a user whose unique constraint err
+// violated. Any other error reports false.
+func TakenUserField(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return "", false
+	}
+
+	field, ok := userUniqueFields[pgErr.ConstraintName]
+	return field, ok
+}
+
 type RepositoryProvider interface {
 	CreateAccount(ctx context.Context, account models.Account) (models.Account, error)
 	GetAccount(ctx context.Context, id int64) (models.Account, error)
+	GetAccountByCurrency(ctx context.Context, owner, currency, accountType string) (models.Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (models.Account, error)
-	ListAccounts(ctx context.Context, name string, limit, offset int32) ([]models.Account, error)
-	UpdateAccount(ctx context.Context, id int64, balance float64) (models.Account, error)
-	AddAccountBalance(ctx context.Context, id int64, amount float64) (models.Account, error)
+	ListAccounts(ctx context.Context, arg models.ListAccountsParams) ([]models.Account, error)
+	UpdateAccount(ctx context.Context, id int64, balance int64) (models.Account, error)
+	AddAccountBalance(ctx context.Context, arg models.AddAccountBalanceParams) (models.Account, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	GetAccountIncludingDeleted(ctx context.Context, id int64) (models.Account, error)
+	PurgeDeletedAccounts(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ListInterestAccounts(ctx context.Context, arg models.ListInterestAccountsParams) ([]models.Account, error)
+	AccrueInterest(ctx context.Context, accrual models.InterestAccrual) (models.InterestAccrual, error)
+	PostAccruedInterest(ctx context.Context, accountID int64, before time.Time) (models.Account, models.Entry, error)
 	CreateEntry(ctx context.Context, entry models.Entry) (models.Entry, error)
 	GetEntry(ctx context.Context, id int64) (models.Entry, error)
-	ListEntries(ctx context.Context, accountID, limit, offset int64) ([]models.Entry, error)
+	ListEntries(ctx context.Context, arg models.ListEntriesParams) ([]models.Entry, error)
+	SetEntryCategory(ctx context.Context, arg models.SetEntryCategoryParams) (models.Entry, error)
+	ListEntriesBetween(ctx context.Context, accountID int64, from, to time.Time) ([]models.Entry, error)
+	SumEntriesSince(ctx context.Context, accountID int64, since time.Time) (int64, error)
+	GetBalanceAt(ctx context.Context, accountID int64, at time.Time) (int64, error)
+	CountEntriesBetween(ctx context.Context, accountID int64, from, to time.Time) (int64, error)
+	SummarizeEntries(ctx context.Context, arg models.SummarizeEntriesParams) (models.AccountSummary, error)
+	ForEachLedgerRow(ctx context.Context, accountID int64, from, to time.Time, fn func(models.LedgerRow) error) error
 	CreateVerifyEmail(ctx context.Context, data models.VerifyEmails) (models.VerifyEmails, error)
+	GetVerifyEmail(ctx context.Context, id int64) (models.VerifyEmails, error)
 	VerifyEmailTx(ctx context.Context, arg models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error)
+	DeleteStaleVerifyEmails(ctx context.Context) (int64, error)
+	CreateVerifyPhone(ctx context.Context, data models.VerifyPhones) (models.VerifyPhones, error)
+	VerifyPhoneTx(ctx context.Context, arg models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error)
+	CountUsersWithPhoneNumber(ctx context.Context, phoneNumber, username string) (int64, error)
 	CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
 	GetTransaction(ctx context.Context, id int64) (models.Transaction, error)
 	ListTransactions(ctx context.Context, fromAccountID, toAccountID, limit, offset int64) ([]models.Transaction, error)
+	ListTransactionsBetween(ctx context.Context, accountID int64, from, to time.Time) ([]models.Transaction, error)
+	SearchTransactions(ctx context.Context, arg models.SearchTransactionsParams) ([]models.Transaction, error)
+	ListTransfers(ctx context.Context, arg models.ListTransfersParams) ([]models.Transaction, error)
+	CountOutgoingTransfers(ctx context.Context, accountID int64, since time.Time) (int64, error)
 	TransferTx(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg models.ReverseTransferTxParams) (models.TransferTxResult, error)
+	SetOverdraftLimitTx(ctx context.Context, arg models.SetOverdraftLimitParams) (models.Account, error)
+	UpdateAccountDetails(ctx context.Context, arg models.UpdateAccountDetailsParams) (models.Account, error)
+	BulkCreateAccountsTx(ctx context.Context, arg models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error)
+	CreateScheduledTransferTx(ctx context.Context, arg models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	ClaimScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	FinishScheduledTransfer(ctx context.Context, arg models.FinishScheduledTransferParams) (models.ScheduledTransfer, error)
+	CreateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error)
+	ListStandingOrders(ctx context.Context, arg models.ListStandingOrdersParams) ([]models.StandingOrder, error)
+	ListDueStandingOrders(ctx context.Context, now time.Time, limit int32) ([]models.StandingOrder, error)
+	PauseStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error)
+	ResumeStandingOrder(ctx context.Context, id int64, nextRunAt time.Time) (models.StandingOrder, error)
+	DeleteStandingOrder(ctx context.Context, id int64) error
+	RecordStandingOrderRunTx(ctx context.Context, arg models.RecordStandingOrderRunParams) (models.StandingOrder, error)
+	ListStandingOrderExecutions(ctx context.Context, standingOrderID int64) ([]models.StandingOrderExecution, error)
+	CreateBeneficiary(ctx context.Context, beneficiary models.Beneficiary) (models.Beneficiary, error)
+	GetBeneficiary(ctx context.Context, id int64) (models.Beneficiary, error)
+	GetBeneficiaryByIBAN(ctx context.Context, owner, iban string) (models.Beneficiary, error)
+	ListBeneficiaries(ctx context.Context, arg models.ListBeneficiariesParams) ([]models.Beneficiary, error)
+	DeleteBeneficiary(ctx context.Context, id int64) error
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, arg models.ListWebhooksParams) ([]models.Webhook, error)
+	ListSubscribedWebhooks(ctx context.Context, owners []string, event string) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	RecordWebhookDelivery(ctx context.Context, id int64, succeeded bool, maxFailures int32) (models.Webhook, error)
+	GetIdempotencyKey(ctx context.Context, username, key string) (models.IdempotencyKey, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	UpsertExchangeRate(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error)
+	GetExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error)
+	UpsertFeePolicy(ctx context.Context, policy models.FeePolicy) (models.FeePolicy, error)
+	GetFeePolicy(ctx context.Context, currency string) (models.FeePolicy, error)
+	ListCurrencies(ctx context.Context) ([]models.Currency, error)
+	AddCurrency(ctx context.Context, currency models.Currency) (models.Currency, error)
+	DisableCurrency(ctx context.Context, code string) (models.Currency, error)
+	GetMigrationStatus(ctx context.Context) (models.MigrationStatus, error)
 	CreateUserTx(ctx context.Context, data models.CreateUserTxParams) (models.User, error)
+	RelayOutboxTx(ctx context.Context, limit int32, send func(message models.OutboxMessage) error) (int, error)
+	RequestStatementTx(ctx context.Context, arg models.RequestStatementTxParams) (models.StatementDocument, error)
+	GetStatementDocument(ctx context.Context, id int64) (models.StatementDocument, error)
+	FinishStatementDocument(ctx context.Context, arg models.FinishStatementDocumentParams) (models.StatementDocument, error)
+	ListStatementDocuments(ctx context.Context, arg models.ListStatementDocumentsParams) ([]models.StatementDocument, error)
+	CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error)
+	ListAuditLogs(ctx context.Context, arg models.ListAuditLogsParams) ([]models.AuditLog, error)
 	CreateUser(ctx context.Context, user models.User) (models.User, error)
 	GetUser(ctx context.Context, userName string) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
 	UpdateUser(ctx context.Context, user models.UpdateUserParams) (models.User, error)
+	UpdateUserTx(ctx context.Context, arg models.UpdateUserTxParams) (models.UpdateUserTxResult, error)
+	GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, arg models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error)
+	CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error)
+	GetNotification(ctx context.Context, id int64) (models.Notification, error)
+	ListNotifications(ctx context.Context, arg models.ListNotificationsParams) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, id int64) (models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, username string) (int64, error)
+	HasOtherSessionFromDevice(ctx context.Context, username, userAgent string, sessionID uuid.UUID) (bool, error)
+	CreateFailedNotification(ctx context.Context, arg models.FailedNotification) (models.FailedNotification, error)
 	ListUsers(ctx context.Context, limit, offset int32) ([]models.User, error)
 	CreateSession(ctx context.Context, session models.Session) (models.Session, error)
 	GetSession(ctx context.Context, id uuid.UUID) (models.Session, error)
+	DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int64, error)
 }
 
 type Repository struct {
 	R RepositoryProvider
 }
 
-func NewRepository(pool *pgxpool.Pool) *Repository {
+// NewRepository creates the repository, registering its metrics with registerer. List and
+// statement reads go to replica when there is one, everything else to db. With accountLocks
+// transfers serialize on advisory locks of their accounts before touching any balance.
+func NewRepository(db database.DB, registerer prometheus.Registerer, replica *Replica, accountLocks bool) *Repository {
+	repo := newRepositoryImpl(db.Pool(), NewTxMetrics(registerer))
+	repo.reads = newReadRouter(replica)
+	repo.accountLocks = accountLocks
+
 	return &Repository{
-		R: newRepositoryImpl(pool),
+		R: repo,
 	}
 }
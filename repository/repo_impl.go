@@ -2,35 +2,75 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+// postgres error codes of constraint violations
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	checkViolation      = "23514"
 )
 
 type repositoryImpl struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	reads   *readRouter // nil without a replica
+	metrics *TxMetrics
+
+	accountLocks bool // transfers take advisory locks on their accounts
 }
 
-func newRepositoryImpl(p *pgxpool.Pool) *repositoryImpl {
+func newRepositoryImpl(p *pgxpool.Pool, metrics *TxMetrics) *repositoryImpl {
 	return &repositoryImpl{
-		pool: p,
+		pool:    p,
+		metrics: metrics,
+	}
+}
+
+const accountColumns = `id, owner, balance, currency, overdraft_limit, account_type, nickname, metadata, accrued_interest, created_at, deleted_at`
+
+func scanAccount(row pgx.Row) (models.Account, error) {
+	var a models.Account
+	var nickname *string
+	err := row.Scan(&a.ID, &a.Owner, &a.Balance, &a.Currency, &a.OverdraftLimit, &a.Type, &nickname, &a.Metadata, &a.AccruedInterest, &a.CreatedAt, &a.DeletedAt)
+	if nickname != nil {
+		a.Nickname = *nickname
 	}
+	return a, err
 }
 
+// CreateAccount opens the account, a checking account when it has no Type
 func (r *repositoryImpl) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
 	var a models.Account
-	query := `INSERT INTO accounts (owner, balance, currency) VALUES (@owner, @balance, @currency) RETURNING id, owner, balance, currency, created_at`
+	query := `INSERT INTO accounts (owner, balance, currency, account_type)
+				VALUES (@owner, @balance, @currency, COALESCE(NULLIF(@accountType, ''), 'checking')::account_type) RETURNING ` + accountColumns
 	args := pgx.NamedArgs{
-		"owner":    account.Owner,
-		"balance":  account.Balance,
-		"currency": account.Currency,
+		"owner":       account.Owner,
+		"balance":     account.Balance,
+		"currency":    account.Currency,
+		"accountType": account.Type,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&a.ID, &a.Owner, &a.Balance, &a.Currency, &a.CreatedAt)
+	a, err := scanAccount(r.pool.QueryRow(ctx, query, args))
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "owner_currency_key" {
+			return a, ErrAccountExists
+		}
 		return a, err
 	}
 
@@ -39,12 +79,31 @@ func (r *repositoryImpl) CreateAccount(ctx context.Context, account models.Accou
 
 func (r *repositoryImpl) GetAccount(ctx context.Context, id int64) (models.Account, error) {
 	var account models.Account
-	query := `SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = @id`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = @id AND deleted_at IS NULL`
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&account.ID, &account.Owner, &account.Balance, &account.Currency, &account.CreatedAt)
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// GetAccountByCurrency gets the account of the type the owner has in the currency, there is at
+// most one
+func (r *repositoryImpl) GetAccountByCurrency(ctx context.Context, owner, currency, accountType string) (models.Account, error) {
+	var account models.Account
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE owner = @owner AND currency = @currency AND account_type = @accountType::account_type AND deleted_at IS NULL`
+	args := pgx.NamedArgs{
+		"owner":       owner,
+		"currency":    currency,
+		"accountType": accountType,
+	}
+
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
 	if err != nil {
 		return account, err
 	}
@@ -54,12 +113,12 @@ func (r *repositoryImpl) GetAccount(ctx context.Context, id int64) (models.Accou
 
 func (r *repositoryImpl) GetAccountForUpdate(ctx context.Context, id int64) (models.Account, error) {
 	var account models.Account
-	query := `SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = @id FOR NO KEY UPDATE`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = @id AND deleted_at IS NULL FOR NO KEY UPDATE`
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&account.ID, &account.Owner, &account.Balance, &account.Currency, &account.CreatedAt)
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
 	if err != nil {
 		return account, err
 	}
@@ -67,12 +126,14 @@ func (r *repositoryImpl) GetAccountForUpdate(ctx context.Context, id int64) (mod
 	return account, nil
 }
 
-func (r *repositoryImpl) ListAccounts(ctx context.Context, name string, limit, offset int32) ([]models.Account, error) {
-	query := `SELECT id, owner, balance, currency, created_at FROM accounts WHERE owner = @name ORDER BY id LIMIT @limit OFFSET @offset`
+func (r *repositoryImpl) ListAccounts(ctx context.Context, arg models.ListAccountsParams) ([]models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE owner = @name AND (@accountType = '' OR account_type::text = @accountType)
+				AND deleted_at IS NULL ORDER BY id LIMIT @limit OFFSET @offset`
 	args := pgx.NamedArgs{
-		"name":   name,
-		"limit":  limit,
-		"offset": offset,
+		"name":        arg.Owner,
+		"accountType": arg.Type,
+		"limit":       arg.Limit,
+		"offset":      arg.Offset,
 	}
 
 	rows, err := r.pool.Query(ctx, query, args)
@@ -84,8 +145,8 @@ func (r *repositoryImpl) ListAccounts(ctx context.Context, name string, limit, o
 
 	accounts := []models.Account{}
 	for rows.Next() {
-		var account models.Account
-		if err := rows.Scan(&account.ID, &account.Owner, &account.Balance, &account.Currency, &account.CreatedAt); err != nil {
+		account, err := scanAccount(rows)
+		if err != nil {
 			return nil, err
 		}
 		accounts = append(accounts, account)
@@ -98,8 +159,8 @@ func (r *repositoryImpl) ListAccounts(ctx context.Context, name string, limit, o
 	return accounts, nil
 }
 
-func (r *repositoryImpl) UpdateAccount(ctx context.Context, id int64, balance float64) (models.Account, error) {
-	query := "UPDATE accounts SET balance = @amount WHERE id = @id RETURNING id, owner, balance, currency, created_at"
+func (r *repositoryImpl) UpdateAccount(ctx context.Context, id int64, balance int64) (models.Account, error) {
+	query := "UPDATE accounts SET balance = @amount WHERE id = @id AND deleted_at IS NULL RETURNING " + accountColumns
 	args := pgx.NamedArgs{
 		"id":     id,
 		"amount": balance,
@@ -107,7 +168,7 @@ func (r *repositoryImpl) UpdateAccount(ctx context.Context, id int64, balance fl
 
 	var account models.Account
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&account.ID, &account.Owner, &account.Balance, &account.Currency, &account.CreatedAt)
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
 	if err != nil {
 		return account, err
 	}
@@ -115,37 +176,272 @@ func (r *repositoryImpl) UpdateAccount(ctx context.Context, id int64, balance fl
 	return account, nil
 }
 
-func (r *repositoryImpl) AddAccountBalance(ctx context.Context, id int64, amount float64) (models.Account, error) {
-	query := "UPDATE accounts SET balance = balance + @amount WHERE id = @id RETURNING id, owner, balance, currency, created_at"
-	args := pgx.NamedArgs{
-		"id":     id,
-		"amount": amount,
-	}
-
+// AddAccountBalance moves the balance by arg.Amount and posts the entry for it, so statements
+// see deposits and withdrawals
+func (r *repositoryImpl) AddAccountBalance(ctx context.Context, arg models.AddAccountBalanceParams) (models.Account, error) {
 	var account models.Account
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&account.ID, &account.Owner, &account.Balance, &account.Currency, &account.CreatedAt)
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		query := "UPDATE accounts SET balance = balance + @amount WHERE id = @id AND deleted_at IS NULL RETURNING " + accountColumns
+		args := pgx.NamedArgs{
+			"id":     arg.AccountID,
+			"amount": arg.Amount,
+		}
+
+		account, err = scanAccount(tx.QueryRow(ctx, query, args))
+		if err != nil {
+			return err
+		}
+
+		_, err = insertEntry(ctx, tx, arg.AccountID, arg.Amount, arg.Category, arg.Tags)
+		return err
+	})
 	if err != nil {
 		return account, err
 	}
 
+	r.reads.wrote(accountReadKey(arg.AccountID))
 	return account, nil
 }
 
+// DeleteAccount soft deletes the account, its entries and transfers keep pointing at it for
+// statements. It returns pgx.ErrNoRows when the account doesn't exist or is already deleted.
 func (r *repositoryImpl) DeleteAccount(ctx context.Context, id int64) error {
-	query := "DELETE FROM accounts WHERE id = @id"
+	query := "UPDATE accounts SET deleted_at = now() WHERE id = @id AND deleted_at IS NULL"
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	_, err := r.pool.Exec(ctx, query, args)
+	tag, err := r.pool.Exec(ctx, query, args)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
 
 	return nil
 }
 
+// GetAccountIncludingDeleted gets the account even when it is soft deleted, for whatever reads
+// its history
+func (r *repositoryImpl) GetAccountIncludingDeleted(ctx context.Context, id int64) (models.Account, error) {
+	var account models.Account
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// PurgeDeletedAccounts hard deletes the accounts soft deleted before deletedBefore, along with the
+// beneficiaries saved for them. An account any ledger row, statement or transfer still refers to
+// is kept, the history it belongs to has to stay intact.
+func (r *repositoryImpl) PurgeDeletedAccounts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		purged = 0 // a retried attempt starts over
+
+		query := `SELECT id FROM accounts a WHERE a.deleted_at < @deletedBefore
+				  AND NOT EXISTS (SELECT 1 FROM entries WHERE account_id = a.id)
+				  AND NOT EXISTS (SELECT 1 FROM transactions WHERE a.id IN (from_account_id, to_account_id))
+				  AND NOT EXISTS (SELECT 1 FROM scheduled_transfers WHERE a.id IN (from_account_id, to_account_id))
+				  AND NOT EXISTS (SELECT 1 FROM standing_orders WHERE a.id IN (from_account_id, to_account_id))
+				  AND NOT EXISTS (SELECT 1 FROM overdraft_limit_changes WHERE account_id = a.id)
+				  AND NOT EXISTS (SELECT 1 FROM statements WHERE account_id = a.id)
+				  AND NOT EXISTS (SELECT 1 FROM fees WHERE fee_account_id = a.id)
+				  AND NOT EXISTS (SELECT 1 FROM interest_accruals WHERE account_id = a.id)
+				  FOR UPDATE`
+		args := pgx.NamedArgs{
+			"deletedBefore": deletedBefore,
+		}
+
+		rows, err := tx.Query(ctx, query, args)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		query2 := `DELETE FROM beneficiaries WHERE account_id = ANY(@ids)`
+		args2 := pgx.NamedArgs{
+			"ids": ids,
+		}
+
+		if _, err = tx.Exec(ctx, query2, args2); err != nil {
+			return err
+		}
+
+		query3 := `DELETE FROM accounts WHERE id = ANY(@ids)`
+		tag, err := tx.Exec(ctx, query3, args2)
+		if err != nil {
+			return err
+		}
+
+		purged = tag.RowsAffected()
+		return nil
+	})
+
+	return purged, err
+}
+
+// ListInterestAccounts lists the open savings accounts by id, the page after arg.AfterID
+func (r *repositoryImpl) ListInterestAccounts(ctx context.Context, arg models.ListInterestAccountsParams) ([]models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id > @afterID AND account_type = 'savings'
+				AND deleted_at IS NULL ORDER BY id LIMIT @limit`
+	args := pgx.NamedArgs{
+		"afterID": arg.AfterID,
+		"limit":   arg.Limit,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	accounts := []models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// AccrueInterest records the accrual and adds its amount to the accrued interest of the account.
+// It returns pgx.ErrNoRows when the account already accrued interest on the date or is deleted,
+// nothing is accrued then.
+func (r *repositoryImpl) AccrueInterest(ctx context.Context, accrual models.InterestAccrual) (models.InterestAccrual, error) {
+	var recorded models.InterestAccrual
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		query := `INSERT INTO interest_accruals (account_id, accrual_date, balance, rate, amount)
+					VALUES (@accountID, @accrualDate, @balance, @rate, @amount)
+					ON CONFLICT (account_id, accrual_date) DO NOTHING
+					RETURNING id, account_id, accrual_date, balance, rate::float8, amount, created_at`
+		args := pgx.NamedArgs{
+			"accountID":   accrual.AccountID,
+			"accrualDate": accrual.AccrualDate,
+			"balance":     accrual.Balance,
+			"rate":        accrual.Rate,
+			"amount":      accrual.Amount,
+		}
+
+		err := tx.QueryRow(ctx, query, args).Scan(&recorded.ID, &recorded.AccountID, &recorded.AccrualDate,
+			&recorded.Balance, &recorded.Rate, &recorded.Amount, &recorded.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		query2 := `UPDATE accounts SET accrued_interest = accrued_interest + @amount WHERE id = @accountID AND deleted_at IS NULL`
+		tag, err := tx.Exec(ctx, query2, args)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.InterestAccrual{}, err
+	}
+
+	r.reads.wrote(accountReadKey(accrual.AccountID))
+	return recorded, nil
+}
+
+// PostAccruedInterest pays the unpaid accruals of the account dated before the given day into its
+// balance with an entry filed under the interest category, later accruals stay accrued. It returns
+// pgx.ErrNoRows when there is nothing to pay or the account is deleted.
+func (r *repositoryImpl) PostAccruedInterest(ctx context.Context, accountID int64, before time.Time) (models.Account, models.Entry, error) {
+	var account models.Account
+	var entry models.Entry
+	var amount int64
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		account, entry, amount = models.Account{}, models.Entry{}, 0 // a retried attempt starts over
+
+		// a concurrent run waits on the rows and then finds them paid
+		query := `WITH paid AS (
+					UPDATE interest_accruals SET paid_at = now()
+					WHERE account_id = @id AND accrual_date < @before AND paid_at IS NULL
+					RETURNING amount
+				)
+				SELECT COALESCE(SUM(amount), 0)::bigint FROM paid`
+		args := pgx.NamedArgs{
+			"id":     accountID,
+			"before": before,
+		}
+
+		if err := tx.QueryRow(ctx, query, args).Scan(&amount); err != nil {
+			return err
+		}
+		// accruals that rounded to nothing are marked paid all the same
+		if amount == 0 {
+			return nil
+		}
+
+		query2 := `UPDATE accounts SET balance = balance + @amount, accrued_interest = accrued_interest - @amount
+					WHERE id = @id AND deleted_at IS NULL RETURNING ` + accountColumns
+		args2 := pgx.NamedArgs{
+			"id":     accountID,
+			"amount": amount,
+		}
+
+		var err error
+		account, err = scanAccount(tx.QueryRow(ctx, query2, args2))
+		if err != nil {
+			return err
+		}
+
+		entry, err = insertEntry(ctx, tx, accountID, amount, models.CategoryInterest, nil)
+		return err
+	})
+	if err != nil {
+		return account, entry, err
+	}
+	if amount == 0 {
+		return account, entry, pgx.ErrNoRows
+	}
+
+	r.reads.wrote(accountReadKey(accountID))
+	return account, entry, nil
+}
+
 func (r *repositoryImpl) CreateSession(ctx context.Context, session models.Session) (models.Session, error) {
 	query := `INSERT INTO sessions (id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at) VALUES
 			  (@id, @username, @token, @userAgent, @clientIP, @isBlocked, @expiresAt) RETURNING id, username, refresh_token, 
@@ -184,412 +480,2794 @@ func (r *repositoryImpl) GetSession(ctx context.Context, id uuid.UUID) (models.S
 	return s, nil
 }
 
+// DeleteExpiredSessions removes sessions that expired before the given time
+func (r *repositoryImpl) DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < @expiredBefore`
+	args := pgx.NamedArgs{
+		"expiredBefore": expiredBefore,
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 func (r *repositoryImpl) CreateEntry(ctx context.Context, entry models.Entry) (models.Entry, error) {
-	query := `INSERT INTO entries (account_id, amount) VALUES (@accountID, @amount) RETURNING id, account_id, amount, created_at`
+	query := `INSERT INTO entries (account_id, amount) VALUES (@accountID, @amount) RETURNING ` + entryColumns
 	args := pgx.NamedArgs{
 		"accountID": entry.AccountID,
 		"amount":    entry.Amount,
 	}
 
-	var e models.Entry
-	err := r.pool.QueryRow(ctx, query, args).Scan(&e.ID, &e.AccountID, &e.Amount, &e.CreatedAt)
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, args))
 	if err != nil {
 		return e, err
 	}
 
+	r.reads.wrote(accountReadKey(e.AccountID))
 	return e, nil
 }
 
-func (r *repositoryImpl) GetEntry(ctx context.Context, id int64) (models.Entry, error) {
-	var entry models.Entry
-	query := `SELECT id, account_id, amount, created_at FROM entries WHERE id = @id`
+// entryColumns selects an entry from the entries table, tags included. The table must not be aliased.
+const entryColumns = `id, account_id, amount, COALESCE(category, ''),
+	COALESCE((SELECT array_agg(tag ORDER BY tag) FROM entry_tags WHERE entry_id = entries.id), '{}'), created_at`
+
+func scanEntry(row pgx.Row) (models.Entry, error) {
+	var e models.Entry
+	err := row.Scan(&e.ID, &e.AccountID, &e.Amount, &e.Category, &e.Tags, &e.CreatedAt)
+	return e, err
+}
+
+// insertEntry posts an entry filed under category and tags, an empty category leaves it uncategorized
+func insertEntry(ctx context.Context, tx pgx.Tx, accountID, amount int64, category string, tags []string) (models.Entry, error) {
+	query := `INSERT INTO entries (account_id, amount, category) VALUES (@accountID, @amount, NULLIF(@category, '')) RETURNING ` + entryColumns
 	args := pgx.NamedArgs{
-		"id": id,
+		"accountID": accountID,
+		"amount":    amount,
+		"category":  category,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&entry.ID, &entry.AccountID, &entry.Amount, &entry.CreatedAt)
+	entry, err := scanEntry(tx.QueryRow(ctx, query, args))
 	if err != nil {
-		return entry, err
+		return entry, categoryError(err)
+	}
+
+	if len(tags) > 0 {
+		if err := setEntryTags(ctx, tx, entry.ID, tags); err != nil {
+			return entry, err
+		}
+		entry.Tags = tags
 	}
 
 	return entry, nil
 }
 
-func (r *repositoryImpl) ListEntries(ctx context.Context, accountID, limit, offset int64) ([]models.Entry, error) {
-	query := `SELECT id, account_id, amount, created_at FROM entries WHERE account_id = @accountID ORDER BY id LIMIT @limit OFFSET @offset`
+// setEntryTags replaces the tags of an entry
+func setEntryTags(ctx context.Context, tx pgx.Tx, entryID int64, tags []string) error {
 	args := pgx.NamedArgs{
-		"accountID": accountID,
-		"limit":     limit,
-		"offset":    offset,
+		"entryID": entryID,
+		"tags":    tags,
 	}
 
-	rows, err := r.pool.Query(ctx, query, args)
-	if err != nil {
-		return nil, err
+	if _, err := tx.Exec(ctx, `DELETE FROM entry_tags WHERE entry_id = @entryID`, args); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
 	}
 
-	defer rows.Close()
+	_, err := tx.Exec(ctx, `INSERT INTO entry_tags (entry_id, tag) SELECT @entryID, unnest(@tags::varchar[]) ON CONFLICT DO NOTHING`, args)
+	return err
+}
 
-	var entries []models.Entry
-	for rows.Next() {
-		var entry models.Entry
-		if err := rows.Scan(&entry.ID, &entry.AccountID, &entry.Amount, &entry.CreatedAt); err != nil {
-			return nil, err
-		}
-		entries = append(entries, entry)
+// categoryError turns the violation of the categories foreign key into ErrUnknownCategory
+func categoryError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation && pgErr.ConstraintName == "entries_category_fkey" {
+		return ErrUnknownCategory
 	}
+	return err
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+func (r *repositoryImpl) GetEntry(ctx context.Context, id int64) (models.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
 	}
 
-	return entries, nil
+	return scanEntry(r.pool.QueryRow(ctx, query, args))
 }
 
-func (r *repositoryImpl) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
-	query := `INSERT INTO transactions (amount, fee, currency, description, to_account_id, from_account_id) VALUES 
-	(@amount, @fee, @currency, @description, @toAccountID, @fromAccountID) RETURNING  id, from_account_id, to_account_id,
-	 amount, fee, currency, description, created_at`
+// ListEntries pages the entries of an account, oldest first
+func (r *repositoryImpl) ListEntries(ctx context.Context, arg models.ListEntriesParams) ([]models.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE account_id = @accountID
+				AND (@category = '' OR category = @category)
+				AND (@tag = '' OR EXISTS (SELECT 1 FROM entry_tags WHERE entry_id = entries.id AND tag = @tag))
+				ORDER BY id LIMIT @limit OFFSET @offset`
 	args := pgx.NamedArgs{
-		"amount":        transaction.Amount,
-		"fee":           transaction.Fee,
-		"currency":      transaction.Currency,
-		"description":   transaction.Description,
-		"fromAccountID": transaction.FromAccountID,
-		"toAccountID":   transaction.ToAccountID,
+		"accountID": arg.AccountID,
+		"category":  arg.Category,
+		"tag":       arg.Tag,
+		"limit":     arg.Limit,
+		"offset":    arg.Offset,
 	}
 
-	var t models.Transaction
-	err := r.pool.QueryRow(ctx, query, args).Scan(&t.ID, &t.FromAccountID, &t.ToAccountID, &t.Amount, &t.Fee, &t.Currency, &t.Description, &t.CreatedAt)
+	rows, err := r.readQuery(ctx, []string{accountReadKey(arg.AccountID)}, query, args)
 	if err != nil {
-		return t, err
+		return nil, err
 	}
 
-	return t, nil
-}
+	defer rows.Close()
 
-func (r *repositoryImpl) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
-	var transaction models.Transaction
-	query := `SELECT id, from_account_id, to_account_id, amount, fee, currency, description, created_at FROM transactions WHERE id = @id`
-	args := pgx.NamedArgs{
-		"id": id,
+	var entries []models.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.CreatedAt)
-	if err != nil {
-		return transaction, err
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return transaction, nil
+	return entries, nil
 }
 
-func (r *repositoryImpl) ListTransactions(ctx context.Context, fromAccountID, toAccountID, limit, offset int64) ([]models.Transaction, error) {
-	query := `SELECT id, from_account_id, to_account_id, amount, fee, currency, description, created_at FROM transactions
-				WHERE from_account_id = @fromAccountID OR to_account_id = @toAccountID ORDER BY id LIMIT @limit OFFSET @offset`
+func (r *repositoryImpl) ListEntriesBetween(ctx context.Context, accountID int64, from, to time.Time) ([]models.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE account_id = @accountID
+				AND created_at >= @from AND created_at < @to ORDER BY created_at, id`
 	args := pgx.NamedArgs{
-		"fromAccountID": fromAccountID,
-		"toAccountID":   toAccountID,
-		"limit":         limit,
-		"offset":        offset,
+		"accountID": accountID,
+		"from":      from,
+		"to":        to,
 	}
 
-	rows, err := r.pool.Query(ctx, query, args)
+	rows, err := r.readQuery(ctx, []string{accountReadKey(accountID)}, query, args)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	var transactions []models.Transaction
+	entries := []models.Entry{}
 	for rows.Next() {
-		var transaction models.Transaction
-		if err := rows.Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.CreatedAt); err != nil {
+		entry, err := scanEntry(rows)
+		if err != nil {
 			return nil, err
 		}
-		transactions = append(transactions, transaction)
+		entries = append(entries, entry)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return transactions, nil
+	return entries, nil
 }
 
-func (r *repositoryImpl) CreateUser(ctx context.Context, user models.User) (models.User, error) {
-	var u models.User
-	query := `INSERT INTO users (username, hashed_password, full_name, email) VALUES (@userName, @password, @fullName, @email) RETURNING *`
+// GetBalanceAt gets the balance the account had at the given time, the entries made since taken
+// back out. The balance and the entries are read in one statement on the primary, so they agree.
+func (r *repositoryImpl) GetBalanceAt(ctx context.Context, accountID int64, at time.Time) (int64, error) {
+	var balance int64
+	query := `SELECT (a.balance - COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = a.id AND e.created_at >= @at), 0))::bigint
+				FROM accounts a WHERE a.id = @accountID AND a.deleted_at IS NULL`
+	args := pgx.NamedArgs{
+		"accountID": accountID,
+		"at":        at,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&balance)
+	return balance, err
+}
+
+func (r *repositoryImpl) SumEntriesSince(ctx context.Context, accountID int64, since time.Time) (int64, error) {
+	var sum int64
+	query := `SELECT COALESCE(SUM(amount), 0)::bigint FROM entries WHERE account_id = @accountID AND created_at >= @since`
 	args := pgx.NamedArgs{
-		"userName": user.UserName,
-		"password": user.HashedPassword,
-		"fullName": user.FullName,
-		"email":    user.Email,
+		"accountID": accountID,
+		"since":     since,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&u.UserName, &u.HashedPassword, &u.FullName, &u.Email, &u.PasswordChangedAt, &u.CreatedAt, &user.IsEmailVerified)
+	err := r.pool.QueryRow(ctx, query, args).Scan(&sum)
 	if err != nil {
-		return u, err
+		return sum, err
 	}
 
-	return u, nil
+	return sum, nil
 }
 
-func (r *repositoryImpl) GetUser(ctx context.Context, username string) (models.User, error) {
-	var user models.User
-	query := `SELECT  * FROM users WHERE username = @username`
+func (r *repositoryImpl) CountEntriesBetween(ctx context.Context, accountID int64, from, to time.Time) (int64, error) {
+	var count int64
+	query := `SELECT count(*) FROM entries WHERE account_id = @accountID AND created_at >= @from AND created_at < @to`
 	args := pgx.NamedArgs{
-		"username": username,
+		"accountID": accountID,
+		"from":      from,
+		"to":        to,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&user.UserName, &user.HashedPassword, &user.FullName, &user.Email, &user.PasswordChangedAt, &user.CreatedAt, &user.IsEmailVerified)
+	err := r.pool.QueryRow(ctx, query, args).Scan(&count)
 	if err != nil {
-		return user, err
+		return count, err
 	}
 
-	return user, nil
+	return count, nil
 }
 
-func (r *repositoryImpl) ListUsers(ctx context.Context, limit, offset int32) ([]models.User, error) {
-	query := `SELECT * FROM users ORDER BY username LIMIT @limit OFFSET @offset`
+// SummarizeEntries adds up the entries of an account posted within [arg.From, arg.To) in the
+// database. The period starts no earlier than the account, PeriodStart of the
+// summary says where it did start. It reads the primary, as the summary of a closed month is
+// cached for good. Returns pgx.ErrNoRows when the account doesn't exist.
+func (r *repositoryImpl) SummarizeEntries(ctx context.Context, arg models.SummarizeEntriesParams) (models.AccountSummary, error) {
+	summary := models.AccountSummary{
+		AccountID:  arg.AccountID,
+		Categories: []models.CategoryTotal{},
+	}
+
+	query := `WITH period AS (
+					SELECT GREATEST(@from::timestamptz, a.created_at) AS start_at
+					FROM accounts a
+					WHERE a.id = @accountID
+				)
+				SELECT p.start_at,
+					COALESCE(SUM(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint,
+					COALESCE(-SUM(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint,
+					count(e.id)
+				FROM period p
+				LEFT JOIN entries e ON e.account_id = @accountID AND e.created_at >= p.start_at AND e.created_at < @to
+				GROUP BY p.start_at`
 	args := pgx.NamedArgs{
-		"limit":  limit,
-		"offset": offset,
+		"accountID": arg.AccountID,
+		"from":      arg.From,
+		"to":        arg.To,
 	}
 
-	rows, err := r.pool.Query(ctx, query, args)
-	if err != nil {
-		return nil, err
+	err := r.pool.QueryRow(ctx, query, args).Scan(&summary.PeriodStart, &summary.Inflow, &summary.Outflow, &summary.Count)
+	if err != nil || summary.Count == 0 {
+		return summary, err
 	}
 
+	args["from"] = summary.PeriodStart
+	query = `SELECT ` + entryColumns + ` FROM entries WHERE account_id = @accountID AND created_at >= @from AND created_at < @to
+				ORDER BY abs(amount) DESC, id LIMIT 1`
+	largest, err := scanEntry(r.pool.QueryRow(ctx, query, args))
+	if err != nil {
+		return summary, err
+	}
+	summary.Largest = &largest
+
+	query = `SELECT COALESCE(category, ''),
+					COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::bigint,
+					COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0)::bigint,
+					count(*)
+				FROM entries WHERE account_id = @accountID AND created_at >= @from AND created_at < @to
+				GROUP BY category ORDER BY category IS NULL, category`
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return summary, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var total models.CategoryTotal
+		if err := rows.Scan(&total.Category, &total.In, &total.Out, &total.Count); err != nil {
+			return summary, err
+		}
+		summary.Categories = append(summary.Categories, total)
+	}
+
+	return summary, rows.Err()
+}
+
+// ForEachLedgerRow calls fn with the entries of an account posted within [from, to), oldest first,
+// as they are read from the database. Entries have no link to their transfer, but both are written
+// in one database transaction and so share created_at, which is what they are matched on. Currency
+// and Balance are left to the caller.
+func (r *repositoryImpl) ForEachLedgerRow(ctx context.Context, accountID int64, from, to time.Time, fn func(models.LedgerRow) error) error {
+	query := `SELECT e.id, e.amount, e.created_at, COALESCE(e.category, ''), COALESCE(t.from_account_id, 0), COALESCE(t.to_account_id, 0), COALESCE(t.amount, 0), COALESCE(t.description, '')
+				FROM entries e
+				LEFT JOIN LATERAL (
+					SELECT from_account_id, to_account_id, amount, description FROM transactions
+					WHERE created_at = e.created_at AND e.account_id IN (from_account_id, to_account_id)
+					ORDER BY id LIMIT 1
+				) t ON true
+				WHERE e.account_id = @accountID AND e.created_at >= @from AND e.created_at < @to
+				ORDER BY e.created_at, e.id`
+	args := pgx.NamedArgs{
+		"accountID": accountID,
+		"from":      from,
+		"to":        to,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.LedgerRow
+		var fromAccountID, toAccountID int64
+		var transferAmount int64
+		var category string
+		if err := rows.Scan(&row.EntryID, &row.Amount, &row.Date, &category, &fromAccountID, &toAccountID, &transferAmount, &row.Memo); err != nil {
+			return err
+		}
+
+		switch {
+		case fromAccountID == 0 && category == models.CategoryInterest:
+			row.Type = models.LedgerRowInterest
+		case fromAccountID == 0 && row.Amount >= 0:
+			row.Type = models.LedgerRowDeposit
+		case fromAccountID == 0:
+			row.Type = models.LedgerRowWithdrawal
+		case toAccountID == accountID:
+			row.Type, row.Counterparty = models.LedgerRowTransferIn, fromAccountID
+		case row.Amount == -transferAmount:
+			row.Type, row.Counterparty = models.LedgerRowTransferOut, toAccountID
+		default:
+			// the sender's other entry of a transfer is its fee
+			row.Type = models.LedgerRowFee
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *repositoryImpl) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	if transaction.ToCurrency == "" {
+		transaction.ToAmount, transaction.ToCurrency, transaction.ExchangeRate = transaction.Amount, transaction.Currency, 1
+	}
+
+	query := `INSERT INTO transactions (amount, fee, currency, description, to_amount, to_currency, exchange_rate, to_account_id, from_account_id) VALUES 
+	(@amount, @fee, @currency, @description, @toAmount, @toCurrency, @exchangeRate, @toAccountID, @fromAccountID) RETURNING  id, from_account_id, to_account_id,
+	 amount, fee, currency, description, to_amount, to_currency, exchange_rate, COALESCE(reversal_of, 0), created_at`
+	args := pgx.NamedArgs{
+		"amount":        transaction.Amount,
+		"fee":           transaction.Fee,
+		"currency":      transaction.Currency,
+		"description":   transaction.Description,
+		"toAmount":      transaction.ToAmount,
+		"toCurrency":    transaction.ToCurrency,
+		"exchangeRate":  transaction.ExchangeRate,
+		"fromAccountID": transaction.FromAccountID,
+		"toAccountID":   transaction.ToAccountID,
+	}
+
+	var t models.Transaction
+	err := r.pool.QueryRow(ctx, query, args).Scan(&t.ID, &t.FromAccountID, &t.ToAccountID, &t.Amount, &t.Fee, &t.Currency, &t.Description, &t.ToAmount, &t.ToCurrency, &t.ExchangeRate, &t.ReversalOf, &t.CreatedAt)
+	if err != nil {
+		return t, err
+	}
+
+	r.reads.wrote(accountReadKey(t.FromAccountID), accountReadKey(t.ToAccountID))
+	return t, nil
+}
+
+func (r *repositoryImpl) GetTransaction(ctx context.Context, id int64) (models.Transaction, error) {
+	var transaction models.Transaction
+	query := `SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.fee, t.currency, t.description, t.to_amount, t.to_currency, t.exchange_rate, COALESCE(t.reversal_of, 0), t.created_at,
+				COALESCE((SELECT r.id FROM transactions r WHERE r.reversal_of = t.id), 0) FROM transactions t WHERE t.id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.ToAmount, &transaction.ToCurrency, &transaction.ExchangeRate, &transaction.ReversalOf, &transaction.CreatedAt, &transaction.ReversedBy)
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (r *repositoryImpl) ListTransactions(ctx context.Context, fromAccountID, toAccountID, limit, offset int64) ([]models.Transaction, error) {
+	query := `SELECT id, from_account_id, to_account_id, amount, fee, currency, description, to_amount, to_currency, exchange_rate, COALESCE(reversal_of, 0), created_at FROM transactions
+				WHERE from_account_id = @fromAccountID OR to_account_id = @toAccountID ORDER BY id LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"fromAccountID": fromAccountID,
+		"toAccountID":   toAccountID,
+		"limit":         limit,
+		"offset":        offset,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.ToAmount, &transaction.ToCurrency, &transaction.ExchangeRate, &transaction.ReversalOf, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *repositoryImpl) ListTransactionsBetween(ctx context.Context, accountID int64, from, to time.Time) ([]models.Transaction, error) {
+	query := `SELECT id, from_account_id, to_account_id, amount, fee, currency, description, to_amount, to_currency, exchange_rate, COALESCE(reversal_of, 0), created_at FROM transactions
+				WHERE (from_account_id = @accountID OR to_account_id = @accountID) AND created_at >= @from AND created_at < @to 
+				ORDER BY created_at, id`
+	args := pgx.NamedArgs{
+		"accountID": accountID,
+		"from":      from,
+		"to":        to,
+	}
+
+	rows, err := r.readQuery(ctx, []string{accountReadKey(accountID)}, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.ToAmount, &transaction.ToCurrency, &transaction.ExchangeRate, &transaction.ReversalOf, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// SearchTransactions searches transfer memos of the accounts owned by arg.Owner, best matches first.
+// Single-word queries shorter than 3 characters can't be stemmed meaningfully, so they fall
+// back to a substring match ordered by recency.
+func (r *repositoryImpl) SearchTransactions(ctx context.Context, arg models.SearchTransactionsParams) ([]models.Transaction, error) {
+	ownedBy := `EXISTS (SELECT 1 FROM accounts a WHERE a.owner = @owner AND a.id IN (t.from_account_id, t.to_account_id)
+				AND (@accountID::bigint = 0 OR a.id = @accountID))`
+
+	query := `SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.fee, t.currency, t.description, t.to_amount, t.to_currency, t.exchange_rate, COALESCE(t.reversal_of, 0), t.created_at
+				FROM transactions t, websearch_to_tsquery('english', @query) q
+				WHERE t.description_tsv @@ q AND ` + ownedBy + `
+				ORDER BY ts_rank(t.description_tsv, q) DESC, t.id DESC LIMIT @limit OFFSET @offset`
+
+	term := strings.TrimSpace(arg.Query)
+	if len(strings.Fields(term)) == 1 && utf8.RuneCountInString(term) < 3 {
+		query = `SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.fee, t.currency, t.description, t.to_amount, t.to_currency, t.exchange_rate, COALESCE(t.reversal_of, 0), t.created_at
+				FROM transactions t
+				WHERE t.description ILIKE '%' || @query || '%' AND ` + ownedBy + `
+				ORDER BY t.created_at DESC, t.id DESC LIMIT @limit OFFSET @offset`
+		term = likeEscaper.Replace(term)
+	}
+
+	args := pgx.NamedArgs{
+		"owner":     arg.Owner,
+		"accountID": arg.AccountID,
+		"query":     term,
+		"limit":     arg.Limit,
+		"offset":    arg.Offset,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.ToAmount, &transaction.ToCurrency, &transaction.ExchangeRate, &transaction.ReversalOf, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// countOutgoingTransfersQuery leaves reversals out, the account doesn't choose to make them
+const countOutgoingTransfersQuery = `SELECT count(*) FROM transactions WHERE from_account_id = @accountID AND created_at >= @since AND reversal_of IS NULL`
+
+// CountOutgoingTransfers counts the transfers an account sent since the given time
+func (r *repositoryImpl) CountOutgoingTransfers(ctx context.Context, accountID int64, since time.Time) (int64, error) {
+	args := pgx.NamedArgs{
+		"accountID": accountID,
+		"since":     since,
+	}
+
+	var count int64
+	err := r.pool.QueryRow(ctx, countOutgoingTransfersQuery, args).Scan(&count)
+	return count, err
+}
+
+// ListTransfers pages the transfers of an account newest first. Transfers created at the same time
+// are ordered by id, so a page boundary never skips or repeats one.
+func (r *repositoryImpl) ListTransfers(ctx context.Context, arg models.ListTransfersParams) ([]models.Transaction, error) {
+	// the entry of the account is the first it got in the transaction of the transfer, a fee comes after
+	query := `SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.fee, t.currency, t.description, t.to_amount, t.to_currency, t.exchange_rate,
+				COALESCE(t.reversal_of, 0), COALESCE(e.category, ''),
+				COALESCE((SELECT array_agg(tag ORDER BY tag) FROM entry_tags WHERE entry_id = e.id), '{}'), t.created_at
+				FROM transactions t
+				LEFT JOIN LATERAL (
+					SELECT id, category FROM entries
+					WHERE account_id = @accountID AND created_at = t.created_at
+					ORDER BY id LIMIT 1
+				) e ON true
+				WHERE ((@direction <> 'received' AND t.from_account_id = @accountID) OR (@direction <> 'sent' AND t.to_account_id = @accountID))
+				AND (@startTime::timestamptz IS NULL OR t.created_at >= @startTime)
+				AND (@endTime::timestamptz IS NULL OR t.created_at < @endTime)
+				AND (@minAmount::bigint IS NULL OR t.amount >= @minAmount)
+				AND (@maxAmount::bigint IS NULL OR t.amount <= @maxAmount)
+				AND (@currency = '' OR t.currency = @currency)
+				AND (@category = '' OR e.category = @category)
+				AND (@tag = '' OR EXISTS (SELECT 1 FROM entry_tags WHERE entry_id = e.id AND tag = @tag))
+				AND (@beforeID = 0 OR (t.created_at, t.id) < (@beforeCreatedAt, @beforeID))
+				ORDER BY t.created_at DESC, t.id DESC LIMIT @limit`
+
+	direction := arg.Direction
+	if direction == "" {
+		direction = models.TransferDirectionAll
+	}
+
+	args := pgx.NamedArgs{
+		"accountID":       arg.AccountID,
+		"direction":       direction,
+		"startTime":       arg.StartTime,
+		"endTime":         arg.EndTime,
+		"minAmount":       arg.MinAmount,
+		"maxAmount":       arg.MaxAmount,
+		"currency":        arg.Currency,
+		"category":        arg.Category,
+		"tag":             arg.Tag,
+		"beforeCreatedAt": arg.BeforeCreatedAt,
+		"beforeID":        arg.BeforeID,
+		"limit":           arg.Limit,
+	}
+
+	rows, err := r.readQuery(ctx, []string{accountReadKey(arg.AccountID)}, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(&transaction.ID, &transaction.FromAccountID, &transaction.ToAccountID, &transaction.Amount, &transaction.Fee, &transaction.Currency, &transaction.Description, &transaction.ToAmount, &transaction.ToCurrency, &transaction.ExchangeRate, &transaction.ReversalOf, &transaction.Category, &transaction.Tags, &transaction.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// SetEntryCategory files an entry under another category or tags
+func (r *repositoryImpl) SetEntryCategory(ctx context.Context, arg models.SetEntryCategoryParams) (models.Entry, error) {
+	var entry models.Entry
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		query := `UPDATE entries SET category = CASE WHEN @setCategory::boolean THEN NULLIF(@category::varchar, '') ELSE category END
+					WHERE id = @id RETURNING id`
+		args := pgx.NamedArgs{
+			"id":          arg.EntryID,
+			"setCategory": arg.Category.Valid,
+			"category":    arg.Category.String,
+		}
+
+		var id int64
+		if err := tx.QueryRow(ctx, query, args).Scan(&id); err != nil {
+			return categoryError(err)
+		}
+
+		if arg.Tags != nil {
+			if err := setEntryTags(ctx, tx, id, arg.Tags); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		entry, err = scanEntry(tx.QueryRow(ctx, `SELECT `+entryColumns+` FROM entries WHERE id = @id`, pgx.NamedArgs{"id": id}))
+		return err
+	})
+	if err != nil {
+		return entry, err
+	}
+
+	r.reads.wrote(accountReadKey(entry.AccountID))
+	return entry, nil
+}
+
+// likeEscaper escapes the LIKE wildcards so user input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *repositoryImpl) UpsertExchangeRate(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error) {
+	query := `INSERT INTO exchange_rates (base, quote, rate, fetched_at) VALUES (@base, @quote, @rate, @fetchedAt)
+				ON CONFLICT (base, quote) DO UPDATE SET rate = EXCLUDED.rate, fetched_at = EXCLUDED.fetched_at
+				RETURNING base, quote, rate, fetched_at`
+	args := pgx.NamedArgs{
+		"base":      rate.Base,
+		"quote":     rate.Quote,
+		"rate":      rate.Rate,
+		"fetchedAt": rate.FetchedAt,
+	}
+
+	var er models.ExchangeRate
+	err := r.pool.QueryRow(ctx, query, args).Scan(&er.Base, &er.Quote, &er.Rate, &er.FetchedAt)
+	if err != nil {
+		return er, err
+	}
+
+	return er, nil
+}
+
+func (r *repositoryImpl) UpsertFeePolicy(ctx context.Context, policy models.FeePolicy) (models.FeePolicy, error) {
+	query := `INSERT INTO fees (currency, flat, percentage, fee_account_id) VALUES (@currency, @flat, @percentage, @feeAccountID)
+				ON CONFLICT (currency) DO UPDATE SET flat = EXCLUDED.flat, percentage = EXCLUDED.percentage,
+				fee_account_id = EXCLUDED.fee_account_id, updated_at = now()
+				RETURNING currency, flat, percentage, fee_account_id, updated_at`
+	args := pgx.NamedArgs{
+		"currency":     policy.Currency,
+		"flat":         policy.Flat,
+		"percentage":   policy.Percentage,
+		"feeAccountID": policy.FeeAccountID,
+	}
+
+	var p models.FeePolicy
+	err := r.pool.QueryRow(ctx, query, args).Scan(&p.Currency, &p.Flat, &p.Percentage, &p.FeeAccountID, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+func (r *repositoryImpl) GetFeePolicy(ctx context.Context, currency string) (models.FeePolicy, error) {
+	var p models.FeePolicy
+	query := `SELECT currency, flat, percentage, fee_account_id, updated_at FROM fees WHERE currency = @currency`
+	args := pgx.NamedArgs{
+		"currency": currency,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&p.Currency, &p.Flat, &p.Percentage, &p.FeeAccountID, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+func (r *repositoryImpl) GetExchangeRate(ctx context.Context, base, quote string) (models.ExchangeRate, error) {
+	var er models.ExchangeRate
+	query := `SELECT base, quote, rate, fetched_at FROM exchange_rates WHERE base = @base AND quote = @quote`
+	args := pgx.NamedArgs{
+		"base":  base,
+		"quote": quote,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&er.Base, &er.Quote, &er.Rate, &er.FetchedAt)
+	if err != nil {
+		return er, err
+	}
+
+	return er, nil
+}
+
+const currencyColumns = `code, name, minor_unit_exponent, enabled, created_at`
+
+func scanCurrency(row pgx.Row) (models.Currency, error) {
+	var c models.Currency
+	err := row.Scan(&c.Code, &c.Name, &c.MinorUnitExponent, &c.Enabled, &c.CreatedAt)
+	return c, err
+}
+
+// ListCurrencies returns every currency, the disabled ones too
+func (r *repositoryImpl) ListCurrencies(ctx context.Context) ([]models.Currency, error) {
+	query := `SELECT ` + currencyColumns + ` FROM currencies ORDER BY code`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	currencies := []models.Currency{}
+	for rows.Next() {
+		c, err := scanCurrency(rows)
+		if err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+// AddCurrency adds an enabled currency. Adding a disabled one enables it again and renames it, its
+// minor unit can't change while balances are stored in it.
+func (r *repositoryImpl) AddCurrency(ctx context.Context, currency models.Currency) (models.Currency, error) {
+	query := `INSERT INTO currencies (code, name, minor_unit_exponent) VALUES (@code, @name, @exponent)
+				ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, enabled = true
+				WHERE currencies.minor_unit_exponent = EXCLUDED.minor_unit_exponent
+				RETURNING ` + currencyColumns
+	args := pgx.NamedArgs{
+		"code":     currency.Code,
+		"name":     currency.Name,
+		"exponent": currency.MinorUnitExponent,
+	}
+
+	c, err := scanCurrency(r.pool.QueryRow(ctx, query, args))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the conflicting row was left alone
+		return c, ErrCurrencyExponentMismatch
+	}
+
+	return c, err
+}
+
+// DisableCurrency stops new accounts and transfers in the currency, pgx.ErrNoRows when there is no
+// such currency
+func (r *repositoryImpl) DisableCurrency(ctx context.Context, code string) (models.Currency, error) {
+	query := `UPDATE currencies SET enabled = false WHERE code = @code RETURNING ` + currencyColumns
+	args := pgx.NamedArgs{
+		"code": code,
+	}
+
+	return scanCurrency(r.pool.QueryRow(ctx, query, args))
+}
+
+// GetMigrationStatus returns the last migration applied, pgx.ErrNoRows when none was
+func (r *repositoryImpl) GetMigrationStatus(ctx context.Context) (models.MigrationStatus, error) {
+	var status models.MigrationStatus
+	query := `SELECT version, dirty, applied_at FROM schema_migrations`
+
+	err := r.pool.QueryRow(ctx, query).Scan(&status.Version, &status.Dirty, &status.AppliedAt)
+	return status, err
+}
+
+func (r *repositoryImpl) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	var u models.User
+	query := `INSERT INTO users (username, hashed_password, full_name, email, phone_number) VALUES (@userName, @password, @fullName, @email, @phoneNumber) RETURNING *`
+	args := pgx.NamedArgs{
+		"userName":    user.UserName,
+		"password":    user.HashedPassword,
+		"fullName":    user.FullName,
+		"email":       user.Email,
+		"phoneNumber": user.PhoneNumber,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&u.UserName, &u.HashedPassword, &u.FullName, &u.Email, &u.PasswordChangedAt, &u.CreatedAt, &u.IsEmailVerified, &u.Role, &u.PhoneNumber, &u.IsPhoneVerified, &u.PreviousEmail, &u.EmailChangedAt)
+	if err != nil {
+		return u, err
+	}
+
+	r.reads.wrote(usersReadKey)
+	return u, nil
+}
+
+func (r *repositoryImpl) GetUser(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	query := `SELECT  * FROM users WHERE username = @username`
+	args := pgx.NamedArgs{
+		"username": username,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&user.UserName, &user.HashedPassword, &user.FullName, &user.Email, &user.PasswordChangedAt, &user.CreatedAt, &user.IsEmailVerified, &user.Role, &user.PhoneNumber, &user.IsPhoneVerified, &user.PreviousEmail, &user.EmailChangedAt)
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail finds the user with email, which must be given lowercased
+func (r *repositoryImpl) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	query := `SELECT * FROM users WHERE lower(email) = @email`
+	args := pgx.NamedArgs{
+		"email": email,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&user.UserName, &user.HashedPassword, &user.FullName, &user.Email, &user.PasswordChangedAt, &user.CreatedAt, &user.IsEmailVerified, &user.Role, &user.PhoneNumber, &user.IsPhoneVerified, &user.PreviousEmail, &user.EmailChangedAt)
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *repositoryImpl) ListUsers(ctx context.Context, limit, offset int32) ([]models.User, error) {
+	query := `SELECT * FROM users ORDER BY username LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	rows, err := r.readQuery(ctx, []string{usersReadKey}, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UserName, &user.HashedPassword, &user.FullName, &user.Email, &user.PasswordChangedAt, &user.CreatedAt, &user.IsEmailVerified, &user.Role, &user.PhoneNumber, &user.IsPhoneVerified, &user.PreviousEmail, &user.EmailChangedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+const updateUserQuery = `UPDATE users SET full_name = COALESCE(@newFullName, full_name),
+			  hashed_password = COALESCE(@newPassword, hashed_password),
+			  password_changed_at = COALESCE(@newPasswordChangedTime, password_changed_at),
+			  is_email_verified = COALESCE(@emailVerified, is_email_verified),
+			  email = COALESCE(@newEmail, email),
+			  phone_number = COALESCE(@newPhoneNumber, phone_number),
+			  is_phone_verified = COALESCE(@phoneVerified, is_phone_verified) WHERE username = @username  RETURNING *`
+
+func updateUserArgs(user models.UpdateUserParams) pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"username":               user.UserName,
+		"newFullName":            user.FullName,
+		"newPassword":            user.HashedPassword,
+		"newPasswordChangedTime": user.PasswordChangedAt,
+		"newEmail":               user.Email,
+		"emailVerified":          user.IsEmailVerified,
+		"newPhoneNumber":         user.PhoneNumber,
+		"phoneVerified":          user.IsPhoneVerified,
+	}
+}
+
+func (r *repositoryImpl) UpdateUser(ctx context.Context, user models.UpdateUserParams) (models.User, error) {
+	var u models.User
+
+	err := r.pool.QueryRow(ctx, updateUserQuery, updateUserArgs(user)).Scan(&u.UserName, &u.HashedPassword, &u.FullName, &u.Email, &u.PasswordChangedAt, &u.CreatedAt, &u.IsEmailVerified, &u.Role, &u.PhoneNumber, &u.IsPhoneVerified, &u.PreviousEmail, &u.EmailChangedAt)
+	if err != nil {
+		return u, err
+	}
+
+	r.reads.wrote(usersReadKey)
+	return u, nil
+}
+
+// UpdateUserTx updates the user like UpdateUser. A new email is stored unverified with a verify
+// email for it, the verified address it replaces is kept as the previous email. Setting the
+// email the user already has changes nothing about it.
+func (r *repositoryImpl) UpdateUserTx(ctx context.Context, arg models.UpdateUserTxParams) (models.UpdateUserTxResult, error) {
+	var result models.UpdateUserTxResult
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		emailChanged := false
+		if arg.Email.Valid {
+			// only a verified address is worth falling back to
+			query := `UPDATE users SET previous_email = CASE WHEN is_email_verified THEN email ELSE previous_email END,
+						email_changed_at = now() WHERE username = @username AND email <> @newEmail`
+			tag, err := tx.Exec(ctx, query, pgx.NamedArgs{"username": arg.UserName, "newEmail": arg.Email.String})
+			if err != nil {
+				return err
+			}
+			emailChanged = tag.RowsAffected() > 0
+		}
+
+		user := arg.UpdateUserParams
+		if emailChanged {
+			user.IsEmailVerified = sql.NullBool{Bool: false, Valid: true}
+		}
+
+		u := &result.User
+		err = tx.QueryRow(ctx, updateUserQuery, updateUserArgs(user)).Scan(&u.UserName, &u.HashedPassword, &u.FullName, &u.Email,
+			&u.PasswordChangedAt, &u.CreatedAt, &u.IsEmailVerified, &u.Role, &u.PhoneNumber, &u.IsPhoneVerified, &u.PreviousEmail, &u.EmailChangedAt)
+		if err != nil || !emailChanged {
+			return err
+		}
+
+		query := `INSERT INTO verify_emails (username, email, secret_code) VALUES (@userName, @email, @secretCode) RETURNING *`
+		args := pgx.NamedArgs{
+			"userName":   result.User.UserName,
+			"email":      result.User.Email,
+			"secretCode": arg.SecretCode,
+		}
+
+		err = tx.QueryRow(ctx, query, args).Scan(&result.VerifyEmail.ID, &result.VerifyEmail.Username, &result.VerifyEmail.Email, &result.VerifyEmail.SecretCode,
+			&result.VerifyEmail.IsUsed, &result.VerifyEmail.CreatedAt, &result.VerifyEmail.ExpiredAt)
+		if err != nil {
+			return err
+		}
+
+		messages, err := arg.AfterEmailChange(result.User, result.VerifyEmail) // callback function
+		if err != nil {
+			return err
+		}
+
+		return addOutboxMessages(ctx, tx, messages)
+	})
+
+	if err == nil {
+		r.reads.wrote(usersReadKey)
+	}
+
+	return result, err
+}
+
+func (r *repositoryImpl) GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error) {
+	var p models.NotificationPreferences
+	query := `SELECT username, email_transfers, email_logins, email_statements, prefer_sms, updated_at FROM notification_preferences
+				WHERE username = @username`
+	args := pgx.NamedArgs{
+		"username": username,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&p.Username, &p.EmailTransfers, &p.EmailLogins, &p.EmailStatements, &p.PreferSMS, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// UpdateNotificationPreferences changes the given preferences. Users created before preferences
+// existed get their row here, with everything not given left on.
+func (r *repositoryImpl) UpdateNotificationPreferences(ctx context.Context, arg models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error) {
+	var p models.NotificationPreferences
+	query := `INSERT INTO notification_preferences (username, email_transfers, email_logins, email_statements, prefer_sms)
+				VALUES (@username, COALESCE(@emailTransfers, true), COALESCE(@emailLogins, true), COALESCE(@emailStatements, true),
+				COALESCE(@preferSMS, false))
+				ON CONFLICT (username) DO UPDATE SET
+				email_transfers = COALESCE(@emailTransfers, notification_preferences.email_transfers),
+				email_logins = COALESCE(@emailLogins, notification_preferences.email_logins),
+				email_statements = COALESCE(@emailStatements, notification_preferences.email_statements),
+				prefer_sms = COALESCE(@preferSMS, notification_preferences.prefer_sms),
+				updated_at = now()
+				RETURNING username, email_transfers, email_logins, email_statements, prefer_sms, updated_at`
+	args := pgx.NamedArgs{
+		"username":        arg.Username,
+		"emailTransfers":  arg.EmailTransfers,
+		"emailLogins":     arg.EmailLogins,
+		"emailStatements": arg.EmailStatements,
+		"preferSMS":       arg.PreferSMS,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&p.Username, &p.EmailTransfers, &p.EmailLogins, &p.EmailStatements, &p.PreferSMS, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+func (r *repositoryImpl) CreateVerifyEmail(ctx context.Context, data models.VerifyEmails) (models.VerifyEmails, error) {
+	var e models.VerifyEmails
+	query := `INSERT INTO verify_emails (username, email, secret_code) VALUES (@userName, @email, @secretCode) RETURNING *`
+	args := pgx.NamedArgs{
+		"userName":   data.Username,
+		"secretCode": data.SecretCode,
+		"email":      data.Email,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&e.ID, &e.Username, &e.Email, &e.SecretCode, &e.IsUsed, &e.CreatedAt, &e.ExpiredAt)
+	if err != nil {
+		return e, err
+	}
+
+	return e, nil
+}
+
+func (r *repositoryImpl) GetVerifyEmail(ctx context.Context, id int64) (models.VerifyEmails, error) {
+	var e models.VerifyEmails
+	query := `SELECT id, username, email, secret_code, is_used, created_at, expired_at FROM verify_emails WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&e.ID, &e.Username, &e.Email, &e.SecretCode, &e.IsUsed, &e.CreatedAt, &e.ExpiredAt)
+	if err != nil {
+		return e, err
+	}
+
+	return e, nil
+}
+
+// DeleteStaleVerifyEmails removes verify emails that were used or can no longer be used
+func (r *repositoryImpl) DeleteStaleVerifyEmails(ctx context.Context) (int64, error) {
+	query := `DELETE FROM verify_emails WHERE is_used = TRUE OR expired_at <= now()`
+
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *repositoryImpl) VerifyEmailTx(ctx context.Context, arg models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
+	var result models.VerifyEmailTxResult
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		// update verify emails table
+		query1 := `UPDATE verify_emails SET is_used = TRUE WHERE id = @id AND secret_code = @code AND is_used = FALSE AND expired_at > now() RETURNING *`
+		args1 := pgx.NamedArgs{
+			"id":   arg.EmailId,
+			"code": arg.SecretCode,
+		}
+
+		err = tx.QueryRow(ctx, query1, args1).Scan(&result.VerifyEmail.ID, &result.VerifyEmail.Username, &result.VerifyEmail.Email, &result.VerifyEmail.SecretCode,
+			&result.VerifyEmail.IsUsed, &result.VerifyEmail.CreatedAt, &result.VerifyEmail.ExpiredAt)
+		if err != nil {
+			return err
+		}
+
+		// update users table
+		// a link sent to an address the user has since changed verifies nothing
+		query2 := `UPDATE users SET is_email_verified = COALESCE(@emailVerified, is_email_verified) WHERE username = @username AND email = @email RETURNING *`
+		args2 := pgx.NamedArgs{
+			"username":      result.VerifyEmail.Username,
+			"email":         result.VerifyEmail.Email,
+			"emailVerified": true,
+		}
+
+		err = tx.QueryRow(ctx, query2, args2).Scan(&result.User.UserName, &result.User.HashedPassword, &result.User.FullName,
+			&result.User.Email, &result.User.PasswordChangedAt, &result.User.CreatedAt, &result.User.IsEmailVerified, &result.User.Role, &result.User.PhoneNumber, &result.User.IsPhoneVerified, &result.User.PreviousEmail, &result.User.EmailChangedAt)
+		return err
+	})
+
+	if err == nil {
+		r.reads.wrote(usersReadKey)
+	}
+
+	return result, err
+}
+
+// verifyPhoneMaxAttempts is how many guesses a phone verification code allows
+const verifyPhoneMaxAttempts = 5
+
+const verifyPhoneColumns = `id, username, phone_number, secret_code, attempts, is_used, created_at, expired_at`
+
+func scanVerifyPhone(row pgx.Row) (models.VerifyPhones, error) {
+	var p models.VerifyPhones
+	err := row.Scan(&p.ID, &p.Username, &p.PhoneNumber, &p.SecretCode, &p.Attempts, &p.IsUsed, &p.CreatedAt, &p.ExpiredAt)
+	return p, err
+}
+
+func (r *repositoryImpl) CreateVerifyPhone(ctx context.Context, data models.VerifyPhones) (models.VerifyPhones, error) {
+	query := `INSERT INTO verify_phones (username, phone_number, secret_code) VALUES (@username, @phoneNumber, @secretCode)
+				RETURNING ` + verifyPhoneColumns
+	args := pgx.NamedArgs{
+		"username":    data.Username,
+		"phoneNumber": data.PhoneNumber,
+		"secretCode":  data.SecretCode,
+	}
+
+	return scanVerifyPhone(r.pool.QueryRow(ctx, query, args))
+}
+
+// VerifyPhoneTx uses the user's latest phone code and marks their number verified. A wrong code
+// returns ErrWrongPhoneCode and counts as an attempt; a code that is used up, expired, out of
+// attempts or was sent to a number the user has since changed returns pgx.ErrNoRows.
+func (r *repositoryImpl) VerifyPhoneTx(ctx context.Context, arg models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error) {
+	var result models.VerifyPhoneTxResult
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		query := `UPDATE verify_phones SET attempts = attempts + 1, is_used = (secret_code = @code)
+					WHERE id = (SELECT max(id) FROM verify_phones WHERE username = @username)
+					AND is_used = FALSE AND expired_at > now() AND attempts < @maxAttempts
+					RETURNING ` + verifyPhoneColumns
+		args := pgx.NamedArgs{
+			"username":    arg.Username,
+			"code":        arg.SecretCode,
+			"maxAttempts": verifyPhoneMaxAttempts,
+		}
+
+		result.VerifyPhone, err = scanVerifyPhone(tx.QueryRow(ctx, query, args))
+		if err != nil || !result.VerifyPhone.IsUsed {
+			return err // a wrong guess is committed so it counts
+		}
+
+		query = `UPDATE users SET is_phone_verified = TRUE WHERE username = @username AND phone_number = @phoneNumber RETURNING *`
+		args = pgx.NamedArgs{
+			"username":    result.VerifyPhone.Username,
+			"phoneNumber": result.VerifyPhone.PhoneNumber,
+		}
+
+		return tx.QueryRow(ctx, query, args).Scan(&result.User.UserName, &result.User.HashedPassword, &result.User.FullName,
+			&result.User.Email, &result.User.PasswordChangedAt, &result.User.CreatedAt, &result.User.IsEmailVerified, &result.User.Role,
+			&result.User.PhoneNumber, &result.User.IsPhoneVerified, &result.User.PreviousEmail, &result.User.EmailChangedAt)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if !result.VerifyPhone.IsUsed {
+		return result, ErrWrongPhoneCode
+	}
+
+	r.reads.wrote(usersReadKey)
+	return result, nil
+}
+
+// CountUsersWithPhoneNumber counts the users other than username that gave phoneNumber
+func (r *repositoryImpl) CountUsersWithPhoneNumber(ctx context.Context, phoneNumber, username string) (int64, error) {
+	var count int64
+	query := `SELECT count(*) FROM users WHERE phone_number = @phoneNumber AND username <> @username`
+	args := pgx.NamedArgs{
+		"phoneNumber": phoneNumber,
+		"username":    username,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&count)
+	return count, err
+}
+
+func (r *repositoryImpl) CreateUserTx(ctx context.Context, arg models.CreateUserTxParams) (models.User, error) {
+	var result models.User
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		// create user
+		query := `INSERT INTO users (username, hashed_password, full_name, email, phone_number) VALUES (@userName, @password, @fullName, @email, @phoneNumber) RETURNING *`
+		args := pgx.NamedArgs{
+			"userName":    arg.User.UserName,
+			"password":    arg.User.HashedPassword,
+			"fullName":    arg.User.FullName,
+			"email":       arg.User.Email,
+			"phoneNumber": arg.User.PhoneNumber,
+		}
+
+		err = tx.QueryRow(ctx, query, args).Scan(&result.UserName, &result.HashedPassword, &result.FullName, &result.Email,
+			 &result.PasswordChangedAt, &result.CreatedAt, &result.IsEmailVerified, &result.Role, &result.PhoneNumber, &result.IsPhoneVerified, &result.PreviousEmail, &result.EmailChangedAt)
+		if err != nil {
+			return err
+		}
+
+		// every notification starts out enabled
+		query = `INSERT INTO notification_preferences (username) VALUES (@userName)`
+		if _, err = tx.Exec(ctx, query, pgx.NamedArgs{"userName": result.UserName}); err != nil {
+			return err
+		}
+
+		var verifyEmail models.VerifyEmails
+		query = `INSERT INTO verify_emails (username, email, secret_code) VALUES (@userName, @email, @secretCode) RETURNING *`
+		args = pgx.NamedArgs{
+			"userName":   result.UserName,
+			"email":      result.Email,
+			"secretCode": arg.SecretCode,
+		}
+
+		err = tx.QueryRow(ctx, query, args).Scan(&verifyEmail.ID, &verifyEmail.Username, &verifyEmail.Email, &verifyEmail.SecretCode,
+			&verifyEmail.IsUsed, &verifyEmail.CreatedAt, &verifyEmail.ExpiredAt)
+		if err != nil {
+			return err
+		}
+
+		messages, err := arg.AfterCreate(result, verifyEmail) // callback function
+		if err != nil {
+			return err
+		}
+
+		return addOutboxMessages(ctx, tx, messages)
+	})
+
+	if err == nil {
+		r.reads.wrote(usersReadKey)
+	}
+
+	return result, err
+}
+
+// addOutboxMessages stores messages for the relay as part of the transaction of the change causing them
+func addOutboxMessages(ctx context.Context, tx pgx.Tx, messages []models.OutboxMessage) error {
+	query := `INSERT INTO outbox (task_type, payload, queue, max_retry) VALUES (@taskType, @payload, @queue, @maxRetry)`
+
+	for _, message := range messages {
+		args := pgx.NamedArgs{
+			"taskType": message.TaskType,
+			"payload":  message.Payload,
+			"queue":    message.Queue,
+			"maxRetry": message.MaxRetry,
+		}
+
+		if _, err := tx.Exec(ctx, query, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lockUnsentOutboxMessages(ctx context.Context, tx pgx.Tx, limit int32) ([]models.OutboxMessage, error) {
+	query := `SELECT id, task_type, payload, queue, max_retry, created_at, sent_at FROM outbox
+				WHERE sent_at IS NULL ORDER BY id LIMIT @limit FOR UPDATE SKIP LOCKED`
+	args := pgx.NamedArgs{
+		"limit": limit,
+	}
+
+	rows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var m models.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.TaskType, &m.Payload, &m.Queue, &m.MaxRetry, &m.CreatedAt, &m.SentAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// RelayOutboxTx passes up to limit unsent outbox messages, oldest first, to send and marks the
+// ones it accepted as sent. Rows being relayed are locked, so relays running side by side never
+// pick the same message. It stops at the first message send fails on and returns that error
+// along with how many were sent before it.
+func (r *repositoryImpl) RelayOutboxTx(ctx context.Context, limit int32, send func(message models.OutboxMessage) error) (int, error) {
+	var sent []int64
+	var sendErr error
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		sent, sendErr = nil, nil
+
+		messages, err := lockUnsentOutboxMessages(ctx, tx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			if sendErr = send(message); sendErr != nil {
+				break
+			}
+			sent = append(sent, message.ID)
+		}
+
+		// the messages sent so far are committed even when a later one failed
+		query := `UPDATE outbox SET sent_at = now() WHERE id = ANY(@ids)`
+		_, err = tx.Exec(ctx, query, pgx.NamedArgs{"ids": sent})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(sent), sendErr
+}
+
+// UpdateAccountDetails changes the nickname and metadata of an open account
+func (r *repositoryImpl) UpdateAccountDetails(ctx context.Context, arg models.UpdateAccountDetailsParams) (models.Account, error) {
+	var metadata []byte // stays NULL to keep the metadata
+	if arg.Metadata != nil {
+		var err error
+		if metadata, err = json.Marshal(arg.Metadata); err != nil {
+			return models.Account{}, err
+		}
+	}
+
+	query := `UPDATE accounts SET nickname = CASE WHEN @setNickname::boolean THEN NULLIF(@nickname::varchar, '') ELSE nickname END,
+				metadata = COALESCE(@metadata::jsonb, metadata)
+			  WHERE id = @id AND deleted_at IS NULL RETURNING ` + accountColumns
+	args := pgx.NamedArgs{
+		"id":          arg.AccountID,
+		"setNickname": arg.Nickname.Valid,
+		"nickname":    arg.Nickname.String,
+		"metadata":    metadata,
+	}
+
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "accounts_owner_nickname_key" {
+			return account, ErrAccountNicknameExists
+		}
+		return account, err
+	}
+
+	return account, nil
+}
+
+// SetOverdraftLimitTx changes the overdraft limit of an account and records who changed it from what
+func (r *repositoryImpl) SetOverdraftLimitTx(ctx context.Context, arg models.SetOverdraftLimitParams) (models.Account, error) {
+	var account models.Account
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var oldLimit int64
+
+		query := `SELECT overdraft_limit FROM accounts WHERE id = @id AND deleted_at IS NULL FOR NO KEY UPDATE`
+		args := pgx.NamedArgs{
+			"id": arg.AccountID,
+		}
+
+		err := tx.QueryRow(ctx, query, args).Scan(&oldLimit)
+		if err != nil {
+			return err
+		}
+
+		query2 := `UPDATE accounts SET overdraft_limit = @limit WHERE id = @id RETURNING ` + accountColumns
+		args2 := pgx.NamedArgs{
+			"id":    arg.AccountID,
+			"limit": arg.Limit,
+		}
+
+		account, err = scanAccount(tx.QueryRow(ctx, query2, args2))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == checkViolation && pgErr.ConstraintName == "savings_no_overdraft" {
+				return ErrSavingsOverdraft
+			}
+			return err
+		}
+
+		query3 := `INSERT INTO overdraft_limit_changes (account_id, old_limit, new_limit, changed_by) VALUES (@accountID, @oldLimit, @newLimit, @changedBy)`
+		args3 := pgx.NamedArgs{
+			"accountID": arg.AccountID,
+			"oldLimit":  oldLimit,
+			"newLimit":  arg.Limit,
+			"changedBy": arg.ChangedBy,
+		}
+
+		_, err = tx.Exec(ctx, query3, args3)
+		return err
+	})
+
+	return account, err
+}
+
+// BulkCreateAccountsTx creates the accounts of arg.Rows with a single COPY. A row that arrives
+// with a reason stays rejected, the others are rejected when their owner doesn't exist or already
+// has a checking account in the currency, in the database or in an earlier row. The accounts are
+// all opened as checking accounts.
+func (r *repositoryImpl) BulkCreateAccountsTx(ctx context.Context, arg models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+	var results []models.BulkAccountResult
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		results = make([]models.BulkAccountResult, len(arg.Rows)) // a retried attempt starts over
+
+		owners := make([]string, len(arg.Rows))
+		for i, row := range arg.Rows {
+			owners[i] = row.Owner
+		}
+
+		users, err := existingUsers(ctx, tx, owners)
+		if err != nil {
+			return err
+		}
+
+		// the row each owner and currency is taken by, -1 when by an existing account
+		taken, err := existingAccountKeys(ctx, tx, owners)
+		if err != nil {
+			return err
+		}
+
+		var accepted []models.BulkAccountRow
+		for i, row := range arg.Rows {
+			key := accountKey{owner: row.Owner, currency: row.Currency}
+			reason := row.Reason
+
+			if reason == "" {
+				if !users[row.Owner] {
+					reason = "owner does not exist"
+				} else if by, ok := taken[key]; ok && by < 0 {
+					reason = fmt.Sprintf("owner already has a %s account", row.Currency)
+				} else if ok {
+					reason = fmt.Sprintf("duplicates row %d", by)
+				}
+			}
+
+			if reason != "" {
+				results[i] = models.BulkAccountResult{Status: models.BulkAccountRejected, Reason: reason}
+				continue
+			}
+
+			taken[key] = i
+			results[i].Status = models.BulkAccountCreated
+			accepted = append(accepted, row)
+		}
+
+		if len(accepted) < len(arg.Rows) && !arg.BestEffort {
+			for i := range results {
+				if results[i].Status == models.BulkAccountCreated {
+					results[i].Status = models.BulkAccountSkipped
+				}
+			}
+			return nil
+		}
+		if len(accepted) == 0 {
+			return nil
+		}
+
+		if _, err := copyAccounts(ctx, tx, accepted); err != nil {
+			return err
+		}
+
+		created, err := accountsByKey(ctx, tx, accepted)
+		if err != nil {
+			return err
+		}
+		for i, row := range arg.Rows {
+			if results[i].Status == models.BulkAccountCreated {
+				results[i].Account = created[accountKey{owner: row.Owner, currency: row.Currency}]
+			}
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+func (r *repositoryImpl) TransferTx(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error) {
+	var result models.TransferTxResult
+
+	// transfers within one currency credit exactly what was debited
+	if arg.ToCurrency == "" {
+		arg.ToAmount, arg.ToCurrency, arg.ExchangeRate = arg.Amount, arg.Currency, 1
+	}
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		result = models.TransferTxResult{} // a retried attempt starts over
+
+		var err error
+
+		// claim the key first, a concurrent request with the same key blocks here until
+		// this transaction finishes and then fails instead of moving the money twice
+		if arg.Idempotency != nil {
+			if err = claimIdempotencyKey(ctx, tx, *arg.Idempotency); err != nil {
+				return err
+			}
+		}
+
+		if err = r.lockAccountsAdvisory(ctx, tx, arg.FromAccountID, arg.ToAccountID); err != nil {
+			return err
+		}
+
+		// the funds are checked against the locked row, a concurrent transfer from the same
+		// account waits here until this one commits and then sees the new balance
+		ids := []int64{arg.FromAccountID, arg.ToAccountID}
+		var fee int64
+		if arg.Fee > 0 && arg.FeeAccountID != 0 {
+			ids, fee = append(ids, arg.FeeAccountID), arg.Fee
+		}
+		locked, err := lockAccounts(ctx, tx, ids...)
+		if err != nil {
+			return err
+		}
+		fromAccount, ok := locked[arg.FromAccountID]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		if err = CheckFunds(fromAccount, arg.Amount, fee); err != nil {
+			return err
+		}
+		// the fee is credited as charged, an account in another currency would get the wrong amount
+		if feeAccount, ok := locked[arg.FeeAccountID]; ok && fee > 0 && feeAccount.Currency != arg.Currency {
+			return fmt.Errorf("%w: %s fee cannot go to a %s account", ErrFeeAccountCurrency, arg.Currency, feeAccount.Currency)
+		}
+		if err = checkTransferLimit(ctx, tx, fromAccount, arg.SavingsTransferLimit); err != nil {
+			return err
+		}
+
+		if err = createTransfer(ctx, tx, arg, &result); err != nil {
+			return err
+		}
+
+		if arg.Fee > 0 && arg.FeeAccountID != 0 {
+			if err = chargeFee(ctx, tx, arg, &result); err != nil {
+				return err
+			}
+		}
+
+		if arg.Idempotency != nil {
+			return storeIdempotentResponse(ctx, tx, *arg.Idempotency, result)
+		}
+
+		return nil
+	})
+
+	if err == nil {
+		r.reads.wrote(transferReadKeys(arg)...)
+	}
+
+	return result, err
+}
+
+// ReverseTransferTx makes the compensating transfer of arg and records who reversed the original and why.
+// The recipient of the original has to cover the reversal from its own balance, overdraft doesn't count.
+func (r *repositoryImpl) ReverseTransferTx(ctx context.Context, arg models.ReverseTransferTxParams) (models.TransferTxResult, error) {
+	var result models.TransferTxResult
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		result = models.TransferTxResult{} // a retried attempt starts over
+
+		if err := r.lockAccountsAdvisory(ctx, tx, arg.Transfer.FromAccountID, arg.Transfer.ToAccountID); err != nil {
+			return err
+		}
+
+		err := createTransfer(ctx, tx, arg.Transfer, &result)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "transactions_reversal_of_key" {
+				return ErrTransferAlreadyReversed
+			}
+			return err
+		}
+
+		// checked after the update so the balance can't change in between
+		if result.FromAccount.Balance < 0 {
+			return ErrReversalNotCovered
+		}
+
+		query := `INSERT INTO transfer_reversals (transaction_id, reversal_id, reversed_by, reason) VALUES (@transactionID, @reversalID, @reversedBy, @reason)`
+		args := pgx.NamedArgs{
+			"transactionID": arg.Transfer.ReversalOf,
+			"reversalID":    result.Transaction.ID,
+			"reversedBy":    arg.ReversedBy,
+			"reason":        arg.Reason,
+		}
+
+		_, err = tx.Exec(ctx, query, args)
+		return err
+	})
+
+	if err == nil {
+		r.reads.wrote(transferReadKeys(arg.Transfer)...)
+	}
+
+	return result, err
+}
+
+// createTransfer records the transaction and its entries and moves the money between the two accounts
+func createTransfer(ctx context.Context, tx pgx.Tx, arg models.TransferTxParams, result *models.TransferTxResult) error {
+	var err error
+
+	// create transaction
+	query := `INSERT INTO transactions (amount, fee, currency, description, to_amount, to_currency, exchange_rate, reversal_of, to_account_id, from_account_id) VALUES 
+				(@amount, @fee, @currency, @description, @toAmount, @toCurrency, @exchangeRate, NULLIF(@reversalOf::bigint, 0), @toAccountID, @fromAccountID) RETURNING  id, from_account_id, to_account_id,
+				 amount, fee, currency, description, to_amount, to_currency, exchange_rate, COALESCE(reversal_of, 0), created_at`
+	args := pgx.NamedArgs{
+		"amount":        arg.Amount,
+		"fee":           arg.Fee,
+		"currency":      arg.Currency,
+		"description":   arg.Description,
+		"toAmount":      arg.ToAmount,
+		"toCurrency":    arg.ToCurrency,
+		"exchangeRate":  arg.ExchangeRate,
+		"reversalOf":    arg.ReversalOf,
+		"fromAccountID": arg.FromAccountID,
+		"toAccountID":   arg.ToAccountID,
+	}
+
+	err = tx.QueryRow(ctx, query, args).Scan(&result.Transaction.ID, &result.Transaction.FromAccountID, &result.Transaction.ToAccountID, &result.Transaction.Amount, &result.Transaction.Fee, &result.Transaction.Currency, &result.Transaction.Description, &result.Transaction.ToAmount, &result.Transaction.ToCurrency, &result.Transaction.ExchangeRate, &result.Transaction.ReversalOf, &result.Transaction.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// create entry for sender account, filed as the sender asked
+	result.FromEntry, err = insertEntry(ctx, tx, arg.FromAccountID, -arg.Amount, arg.Category, arg.Tags)
+	if err != nil {
+		return err
+	}
+
+	// create entry for receiver account, the receiver files it themselves
+	result.ToEntry, err = insertEntry(ctx, tx, arg.ToAccountID, arg.ToAmount, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		// update the sender's new balance first and then receiver's balance to avoid deadlock
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, tx, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		// update the receiver's new balance first and then sender's balance to avoid deadlock
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, tx, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.Amount)
+	}
+
+	return err
+}
+
+// claimIdempotencyKey inserts the key, taking over an expired row the cleanup job hasn't removed yet
+func claimIdempotencyKey(ctx context.Context, tx pgx.Tx, key models.IdempotencyKey) error {
+	query := `INSERT INTO idempotency_keys (username, key, request_hash, expires_at) VALUES (@username, @key, @requestHash, @expiresAt)
+				ON CONFLICT (username, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, response = NULL,
+				created_at = now(), expires_at = EXCLUDED.expires_at
+				WHERE idempotency_keys.expires_at <= now()
+				RETURNING key`
+	args := pgx.NamedArgs{
+		"username":    key.UserName,
+		"key":         key.Key,
+		"requestHash": key.RequestHash,
+		"expiresAt":   key.ExpiresAt,
+	}
+
+	var claimed string
+	err := tx.QueryRow(ctx, query, args).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDuplicateIdempotencyKey
+	}
+
+	return err
+}
+
+func storeIdempotentResponse(ctx context.Context, tx pgx.Tx, key models.IdempotencyKey, result models.TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	query := `UPDATE idempotency_keys SET response = @response WHERE username = @username AND key = @key`
+	args := pgx.NamedArgs{
+		"username": key.UserName,
+		"key":      key.Key,
+		"response": response,
+	}
+
+	_, err = tx.Exec(ctx, query, args)
+	return err
+}
+
+func (r *repositoryImpl) GetIdempotencyKey(ctx context.Context, username, key string) (models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+	query := `SELECT username, key, request_hash, response, created_at, expires_at FROM idempotency_keys
+				WHERE username = @username AND key = @key AND expires_at > now()`
+	args := pgx.NamedArgs{
+		"username": username,
+		"key":      key,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&k.UserName, &k.Key, &k.RequestHash, &k.Response, &k.CreatedAt, &k.ExpiresAt)
+	if err != nil {
+		return k, err
+	}
+
+	return k, nil
+}
+
+func (r *repositoryImpl) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= now()`
+
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// checkTransferLimit refuses a transfer from a savings account that already made limit transfers in
+// the calendar month, in UTC like statements. The account has to be locked, so concurrent transfers
+// from it count one after the other.
+func checkTransferLimit(ctx context.Context, tx pgx.Tx, account models.Account, limit int) error {
+	if account.Type != models.AccountSavings || limit <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	args := pgx.NamedArgs{
+		"accountID": account.ID,
+		"since":     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	var count int64
+	if err := tx.QueryRow(ctx, countOutgoingTransfersQuery, args).Scan(&count); err != nil {
+		return err
+	}
+	if count >= int64(limit) {
+		return fmt.Errorf("%w: savings accounts can make %d a month", ErrTransferLimitReached, limit)
+	}
+
+	return nil
+}
+
+// chargeFee moves the transfer fee from the sender to the fee account. The caller has locked
+// the fee account along with the sender and receiver, in id order, so the updates can't deadlock.
+func chargeFee(ctx context.Context, tx pgx.Tx, arg models.TransferTxParams, result *models.TransferTxResult) error {
+	var err error
+	result.FeeEntry, err = insertEntry(ctx, tx, arg.FromAccountID, -arg.Fee, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = insertEntry(ctx, tx, arg.FeeAccountID, arg.Fee, "", nil); err != nil {
+		return err
+	}
+
+	query3 := "UPDATE accounts SET balance = balance + @amount WHERE id = @id AND deleted_at IS NULL RETURNING " + accountColumns
+	args3 := pgx.NamedArgs{
+		"id":     arg.FromAccountID,
+		"amount": -arg.Fee,
+	}
+
+	result.FromAccount, err = scanAccount(tx.QueryRow(ctx, query3, args3))
+	if err != nil {
+		return err
+	}
+
+	query4 := "UPDATE accounts SET balance = balance + @amount WHERE id = @id AND deleted_at IS NULL"
+	args4 := pgx.NamedArgs{
+		"id":     arg.FeeAccountID,
+		"amount": arg.Fee,
+	}
+
+	tag, err := tx.Exec(ctx, query4, args4)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// lockAccounts locks the rows of the accounts until the transaction ends. They are locked in
+// ascending id order, so transactions locking the same accounts queue up instead of deadlocking.
+func lockAccounts(ctx context.Context, tx pgx.Tx, ids ...int64) (map[int64]models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = ANY(@ids) AND deleted_at IS NULL ORDER BY id FOR UPDATE`
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	rows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make(map[int64]models.Account, len(ids))
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts[account.ID] = account
+	}
+
+	return accounts, rows.Err()
+}
+
+// lockAccountsAdvisory takes a transaction level advisory lock keyed by the id of each account
+// when accountLocks is on, the smaller id first so two transfers never wait on each other. A lock
+// on the smaller id alone would let transfers from A to C and from B to C contend for C, so every
+// account gets one. Transfers touching a busy account then queue up here instead of contending
+// for its row and retrying, transfers between other accounts don't wait at all. The locks are
+// released with the transaction.
+func (r *repositoryImpl) lockAccountsAdvisory(ctx context.Context, tx pgx.Tx, ids ...int64) error {
+	if !r.accountLocks {
+		return nil
+	}
+
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	start := time.Now()
+	for _, id := range ids {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(@id)`, pgx.NamedArgs{"id": id}); err != nil {
+			return err
+		}
+	}
+	r.metrics.lockWait.Observe(time.Since(start).Seconds())
+
+	return nil
+}
+
+// CheckFunds makes sure the account can pay amount plus fee, both in its currency, without going
+// past its overdraft limit
+func CheckFunds(account models.Account, amount, fee int64) error {
+	available, err := money.New(account.Balance, account.Currency).Add(money.New(account.OverdraftLimit, account.Currency))
+	if err != nil {
+		return err
+	}
+
+	debit, err := money.New(amount, account.Currency).Add(money.New(fee, account.Currency))
+	if err != nil {
+		return err
+	}
+
+	remaining, err := available.Sub(debit)
+	if err != nil {
+		return err
+	}
+	if remaining.IsNegative() {
+		return ErrInsufficientFunds
+	}
+
+	return nil
+}
+
+// accountKey is what an owner can only have one account of
+type accountKey struct {
+	owner    string
+	currency string
+}
+
+// copyAccounts inserts rows into accounts with COPY, which is far faster than an INSERT per row
+// for a large import. It returns the number of rows copied.
+func copyAccounts(ctx context.Context, tx pgx.Tx, rows []models.BulkAccountRow) (int64, error) {
+	return tx.CopyFrom(ctx, pgx.Identifier{"accounts"}, []string{"owner", "balance", "currency"},
+		pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+			return []any{rows[i].Owner, rows[i].Balance, rows[i].Currency}, nil
+		}),
+	)
+}
+
+// existingUsers returns which of usernames are users
+func existingUsers(ctx context.Context, tx pgx.Tx, usernames []string) (map[string]bool, error) {
+	query := `SELECT username FROM users WHERE username = ANY(@usernames)`
+	args := pgx.NamedArgs{
+		"usernames": usernames,
+	}
+
+	rows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]bool)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		users[username] = true
+	}
+
+	return users, rows.Err()
+}
+
+// existingAccountKeys returns the owner and currency of every account of owners, each taken by -1
+func existingAccountKeys(ctx context.Context, tx pgx.Tx, owners []string) (map[accountKey]int, error) {
+	query := `SELECT owner, currency FROM accounts WHERE owner = ANY(@owners) AND account_type = 'checking' AND deleted_at IS NULL`
+	args := pgx.NamedArgs{
+		"owners": owners,
+	}
+
+	rows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[accountKey]int)
+	for rows.Next() {
+		var key accountKey
+		if err := rows.Scan(&key.owner, &key.currency); err != nil {
+			return nil, err
+		}
+		keys[key] = -1
+	}
+
+	return keys, rows.Err()
+}
+
+// accountsByKey reads back the accounts of rows, COPY doesn't return what it inserted
+func accountsByKey(ctx context.Context, tx pgx.Tx, rows []models.BulkAccountRow) (map[accountKey]models.Account, error) {
+	owners := make([]string, len(rows))
+	currencies := make([]string, len(rows))
+	for i, row := range rows {
+		owners[i] = row.Owner
+		currencies[i] = row.Currency
+	}
+
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE (owner, currency) IN (SELECT * FROM unnest(@owners::varchar[], @currencies::varchar[]))
+				AND account_type = 'checking' AND deleted_at IS NULL`
+	args := pgx.NamedArgs{
+		"owners":     owners,
+		"currencies": currencies,
+	}
+
+	accountRows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer accountRows.Close()
+
+	accounts := make(map[accountKey]models.Account, len(rows))
+	for accountRows.Next() {
+		account, err := scanAccount(accountRows)
+		if err != nil {
+			return nil, err
+		}
+		accounts[accountKey{owner: account.Owner, currency: account.Currency}] = account
+	}
+
+	return accounts, accountRows.Err()
+}
+
+func addMoney(
+	ctx context.Context,
+	tx pgx.Tx,
+	accountID1 int64,
+	amount1 int64,
+	accountID2 int64,
+	amount2 int64,
+) (account1 models.Account, account2 models.Account, err error) {
+	// update account 1
+	query := "UPDATE accounts SET balance = balance + @amount WHERE id = @id AND deleted_at IS NULL RETURNING " + accountColumns
+	args := pgx.NamedArgs{
+		"id":     accountID1,
+		"amount": amount1,
+	}
+
+	account1, err = scanAccount(tx.QueryRow(ctx, query, args))
+	if err != nil {
+		return account1, account2, err
+	}
+
+	// update account 2
+	query2 := "UPDATE accounts SET balance = balance + @amount WHERE id = @id AND deleted_at IS NULL RETURNING " + accountColumns
+	args2 := pgx.NamedArgs{
+		"id":     accountID2,
+		"amount": amount2,
+	}
+
+	account2, err = scanAccount(tx.QueryRow(ctx, query2, args2))
+	if err != nil {
+		return account1, account2, err
+	}
+
+	return account1, account2, err
+}
+
+const scheduledTransferColumns = `id, from_account_id, to_account_id, amount, currency, description, execute_at, status,
+	failure_reason, COALESCE(transaction_id, 0), created_by, created_at, updated_at`
+
+func scanScheduledTransfer(row pgx.Row) (models.ScheduledTransfer, error) {
+	var s models.ScheduledTransfer
+	err := row.Scan(&s.ID, &s.FromAccountID, &s.ToAccountID, &s.Amount, &s.Currency, &s.Description, &s.ExecuteAt, &s.Status,
+		&s.FailureReason, &s.TransactionID, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+// CreateScheduledTransferTx stores a pending scheduled transfer. The row is rolled back if
+// AfterCreate fails, so a transfer is never stored without its execution being queued.
+func (r *repositoryImpl) CreateScheduledTransferTx(ctx context.Context, arg models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error) {
+	var result models.ScheduledTransfer
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		query := `INSERT INTO scheduled_transfers (from_account_id, to_account_id, amount, currency, description, execute_at, created_by)
+					VALUES (@fromAccountID, @toAccountID, @amount, @currency, @description, @executeAt, @createdBy)
+					RETURNING ` + scheduledTransferColumns
+		args := pgx.NamedArgs{
+			"fromAccountID": arg.Transfer.FromAccountID,
+			"toAccountID":   arg.Transfer.ToAccountID,
+			"amount":        arg.Transfer.Amount,
+			"currency":      arg.Transfer.Currency,
+			"description":   arg.Transfer.Description,
+			"executeAt":     arg.Transfer.ExecuteAt,
+			"createdBy":     arg.Transfer.CreatedBy,
+		}
+
+		result, err = scanScheduledTransfer(tx.QueryRow(ctx, query, args))
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(result) // callback function
+	})
+
+	return result, err
+}
+
+func (r *repositoryImpl) GetScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduledTransferColumns + ` FROM scheduled_transfers WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanScheduledTransfer(r.pool.QueryRow(ctx, query, args))
+}
+
+// ClaimScheduledTransfer moves a scheduled transfer to executing. A row left in executing by a
+// crashed worker can be claimed again; its idempotency key keeps the transfer from running twice.
+// pgx.ErrNoRows is returned when the transfer was already finished or canceled.
+func (r *repositoryImpl) ClaimScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	query := `UPDATE scheduled_transfers SET status = @executing, updated_at = now()
+				WHERE id = @id AND status IN (@pending, @executing)
+				RETURNING ` + scheduledTransferColumns
+	args := pgx.NamedArgs{
+		"id":        id,
+		"pending":   models.ScheduledTransferPending,
+		"executing": models.ScheduledTransferExecuting,
+	}
+
+	return scanScheduledTransfer(r.pool.QueryRow(ctx, query, args))
+}
+
+// CancelScheduledTransfer cancels a transfer that is still pending.
+// pgx.ErrNoRows is returned when execution already started.
+func (r *repositoryImpl) CancelScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	query := `UPDATE scheduled_transfers SET status = @canceled, updated_at = now()
+				WHERE id = @id AND status = @pending
+				RETURNING ` + scheduledTransferColumns
+	args := pgx.NamedArgs{
+		"id":       id,
+		"pending":  models.ScheduledTransferPending,
+		"canceled": models.ScheduledTransferCanceled,
+	}
+
+	return scanScheduledTransfer(r.pool.QueryRow(ctx, query, args))
+}
+
+// FinishScheduledTransfer records the outcome of an executing transfer
+func (r *repositoryImpl) FinishScheduledTransfer(ctx context.Context, arg models.FinishScheduledTransferParams) (models.ScheduledTransfer, error) {
+	query := `UPDATE scheduled_transfers SET status = @status, transaction_id = NULLIF(@transactionID::bigint, 0),
+				failure_reason = @failureReason, updated_at = now()
+				WHERE id = @id AND status = @executing
+				RETURNING ` + scheduledTransferColumns
+	args := pgx.NamedArgs{
+		"id":            arg.ID,
+		"status":        arg.Status,
+		"transactionID": arg.TransactionID,
+		"failureReason": arg.FailureReason,
+		"executing":     models.ScheduledTransferExecuting,
+	}
+
+	return scanScheduledTransfer(r.pool.QueryRow(ctx, query, args))
+}
+
+const standingOrderColumns = `id, owner, from_account_id, to_account_id, amount, currency, description, frequency, start_date,
+	end_date, next_run_at, status, consecutive_failures, created_at, updated_at`
+
+func scanStandingOrder(row pgx.Row) (models.StandingOrder, error) {
+	var o models.StandingOrder
+	err := row.Scan(&o.ID, &o.Owner, &o.FromAccountID, &o.ToAccountID, &o.Amount, &o.Currency, &o.Description, &o.Frequency, &o.StartDate,
+		&o.EndDate, &o.NextRunAt, &o.Status, &o.ConsecutiveFailures, &o.CreatedAt, &o.UpdatedAt)
+	return o, err
+}
+
+func (r *repositoryImpl) listStandingOrders(ctx context.Context, query string, args pgx.NamedArgs) ([]models.StandingOrder, error) {
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.StandingOrder
+	for rows.Next() {
+		o, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *repositoryImpl) CreateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error) {
+	query := `INSERT INTO standing_orders (owner, from_account_id, to_account_id, amount, currency, description, frequency, start_date, end_date, next_run_at)
+				VALUES (@owner, @fromAccountID, @toAccountID, @amount, @currency, @description, @frequency, @startDate, @endDate, @nextRunAt)
+				RETURNING ` + standingOrderColumns
+	args := pgx.NamedArgs{
+		"owner":         order.Owner,
+		"fromAccountID": order.FromAccountID,
+		"toAccountID":   order.ToAccountID,
+		"amount":        order.Amount,
+		"currency":      order.Currency,
+		"description":   order.Description,
+		"frequency":     order.Frequency,
+		"startDate":     order.StartDate,
+		"endDate":       order.EndDate,
+		"nextRunAt":     order.NextRunAt,
+	}
+
+	return scanStandingOrder(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) GetStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanStandingOrder(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) ListStandingOrders(ctx context.Context, arg models.ListStandingOrdersParams) ([]models.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE owner = @owner ORDER BY id LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"owner":  arg.Owner,
+		"limit":  arg.Limit,
+		"offset": arg.Offset,
+	}
+
+	return r.listStandingOrders(ctx, query, args)
+}
+
+// ListDueStandingOrders returns active orders whose next run is at or before now, oldest first
+func (r *repositoryImpl) ListDueStandingOrders(ctx context.Context, now time.Time, limit int32) ([]models.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders
+				WHERE status = @active AND next_run_at <= @now ORDER BY next_run_at LIMIT @limit`
+	args := pgx.NamedArgs{
+		"active": models.StandingOrderActive,
+		"now":    now,
+		"limit":  limit,
+	}
+
+	return r.listStandingOrders(ctx, query, args)
+}
+
+// PauseStandingOrder pauses an active order, pgx.ErrNoRows is returned for any other status
+func (r *repositoryImpl) PauseStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error) {
+	query := `UPDATE standing_orders SET status = @paused, updated_at = now()
+				WHERE id = @id AND status = @active
+				RETURNING ` + standingOrderColumns
+	args := pgx.NamedArgs{
+		"id":     id,
+		"active": models.StandingOrderActive,
+		"paused": models.StandingOrderPaused,
+	}
+
+	return scanStandingOrder(r.pool.QueryRow(ctx, query, args))
+}
+
+// ResumeStandingOrder reactivates a paused or suspended order from nextRunAt and clears its failures.
+// pgx.ErrNoRows is returned for any other status.
+func (r *repositoryImpl) ResumeStandingOrder(ctx context.Context, id int64, nextRunAt time.Time) (models.StandingOrder, error) {
+	query := `UPDATE standing_orders SET status = @active, next_run_at = @nextRunAt, consecutive_failures = 0, updated_at = now()
+				WHERE id = @id AND status IN (@paused, @suspended)
+				RETURNING ` + standingOrderColumns
+	args := pgx.NamedArgs{
+		"id":        id,
+		"nextRunAt": nextRunAt,
+		"active":    models.StandingOrderActive,
+		"paused":    models.StandingOrderPaused,
+		"suspended": models.StandingOrderSuspended,
+	}
+
+	return scanStandingOrder(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) DeleteStandingOrder(ctx context.Context, id int64) error {
+	query := `DELETE FROM standing_orders WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordStandingOrderRunTx stores the outcome of a run and moves the order on to its next run.
+// The update only applies while next_run_at still equals the run, so a run is recorded once;
+// pgx.ErrNoRows is returned when it was already recorded or the order was deleted.
+func (r *repositoryImpl) RecordStandingOrderRunTx(ctx context.Context, arg models.RecordStandingOrderRunParams) (models.StandingOrder, error) {
+	var order models.StandingOrder
+
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var err error
+
+		failed := arg.Execution.Status == models.StandingOrderRunFailed
+
+		query := `UPDATE standing_orders SET
+					next_run_at = @nextRunAt,
+					consecutive_failures = CASE WHEN @failed THEN consecutive_failures + 1 ELSE 0 END,
+					status = CASE
+						WHEN @failed AND consecutive_failures + 1 >= @maxFailures THEN @suspended
+						WHEN @completed THEN @completedStatus
+						ELSE status END,
+					updated_at = now()
+					WHERE id = @id AND next_run_at = @runAt
+					RETURNING ` + standingOrderColumns
+		args := pgx.NamedArgs{
+			"id":              arg.Execution.StandingOrderID,
+			"runAt":           arg.Execution.RunAt,
+			"nextRunAt":       arg.NextRunAt,
+			"failed":          failed,
+			"maxFailures":     arg.MaxFailures,
+			"completed":       arg.Completed,
+			"suspended":       models.StandingOrderSuspended,
+			"completedStatus": models.StandingOrderCompleted,
+		}
+
+		order, err = scanStandingOrder(tx.QueryRow(ctx, query, args))
+		if err != nil {
+			return err
+		}
+
+		query2 := `INSERT INTO standing_order_executions (standing_order_id, run_at, status, failure_reason, transaction_id)
+					VALUES (@standingOrderID, @runAt, @status, @failureReason, NULLIF(@transactionID::bigint, 0))`
+		args2 := pgx.NamedArgs{
+			"standingOrderID": arg.Execution.StandingOrderID,
+			"runAt":           arg.Execution.RunAt,
+			"status":          arg.Execution.Status,
+			"failureReason":   arg.Execution.FailureReason,
+			"transactionID":   arg.Execution.TransactionID,
+		}
+
+		_, err = tx.Exec(ctx, query2, args2)
+		return err
+	})
+
+	return order, err
+}
+
+func (r *repositoryImpl) ListStandingOrderExecutions(ctx context.Context, standingOrderID int64) ([]models.StandingOrderExecution, error) {
+	query := `SELECT id, standing_order_id, run_at, status, failure_reason, COALESCE(transaction_id, 0), created_at
+				FROM standing_order_executions WHERE standing_order_id = @standingOrderID ORDER BY run_at`
+	args := pgx.NamedArgs{
+		"standingOrderID": standingOrderID,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []models.StandingOrderExecution
+	for rows.Next() {
+		var e models.StandingOrderExecution
+		if err := rows.Scan(&e.ID, &e.StandingOrderID, &e.RunAt, &e.Status, &e.FailureReason, &e.TransactionID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		executions = append(executions, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+func (r *repositoryImpl) CreateBeneficiary(ctx context.Context, beneficiary models.Beneficiary) (models.Beneficiary, error) {
+	var b models.Beneficiary
+	query := `INSERT INTO beneficiaries (owner, account_id, nickname, iban, bic) VALUES (@owner, @accountID, @nickname, @iban, @bic)
+				RETURNING id, owner, account_id, nickname, iban, bic, created_at`
+	args := pgx.NamedArgs{
+		"owner":     beneficiary.Owner,
+		"accountID": beneficiary.AccountID,
+		"nickname":  beneficiary.Nickname,
+		"iban":      beneficiary.IBAN,
+		"bic":       beneficiary.BIC,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&b.ID, &b.Owner, &b.AccountID, &b.Nickname, &b.IBAN, &b.BIC, &b.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "beneficiaries_owner_nickname_key" {
+			return b, ErrDuplicateNickname
+		}
+		return b, err
+	}
+
+	return b, nil
+}
+
+func (r *repositoryImpl) GetBeneficiary(ctx context.Context, id int64) (models.Beneficiary, error) {
+	var b models.Beneficiary
+	query := `SELECT id, owner, account_id, nickname, iban, bic, created_at FROM beneficiaries WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&b.ID, &b.Owner, &b.AccountID, &b.Nickname, &b.IBAN, &b.BIC, &b.CreatedAt)
+	if err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+// GetBeneficiaryByIBAN looks up the owner's saved payee registered under a normalized IBAN
+func (r *repositoryImpl) GetBeneficiaryByIBAN(ctx context.Context, owner, iban string) (models.Beneficiary, error) {
+	var b models.Beneficiary
+	query := `SELECT id, owner, account_id, nickname, iban, bic, created_at FROM beneficiaries
+				WHERE owner = @owner AND iban = @iban ORDER BY id LIMIT 1`
+	args := pgx.NamedArgs{
+		"owner": owner,
+		"iban":  iban,
+	}
+
+	err := r.pool.QueryRow(ctx, query, args).Scan(&b.ID, &b.Owner, &b.AccountID, &b.Nickname, &b.IBAN, &b.BIC, &b.CreatedAt)
+	if err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+func (r *repositoryImpl) ListBeneficiaries(ctx context.Context, arg models.ListBeneficiariesParams) ([]models.Beneficiary, error) {
+	query := `SELECT id, owner, account_id, nickname, iban, bic, created_at FROM beneficiaries WHERE owner = @owner
+				ORDER BY nickname LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"owner":  arg.Owner,
+		"limit":  arg.Limit,
+		"offset": arg.Offset,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	users := []models.User{}
+	beneficiaries := []models.Beneficiary{}
 	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.UserName, &user.HashedPassword, &user.FullName, &user.Email, &user.PasswordChangedAt, &user.CreatedAt, &user.IsEmailVerified); err != nil {
+		var b models.Beneficiary
+		if err := rows.Scan(&b.ID, &b.Owner, &b.AccountID, &b.Nickname, &b.IBAN, &b.BIC, &b.CreatedAt); err != nil {
 			return nil, err
 		}
-		users = append(users, user)
+		beneficiaries = append(beneficiaries, b)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return users, nil
+	return beneficiaries, nil
 }
 
-func (r *repositoryImpl) UpdateUser(ctx context.Context, user models.UpdateUserParams) (models.User, error) {
-	var u models.User
-	query := `UPDATE users SET full_name = COALESCE(@newFullName, full_name),
-			  hashed_password = COALESCE(@newPassword, hashed_password),
-			  password_changed_at = COALESCE(@newPasswordChangedTime, password_changed_at),
-			  is_email_verified = COALESCE(@emailVerified, is_email_verified),
-			  email = COALESCE(@newEmail, email) WHERE username = @username  RETURNING *`
+// DeleteBeneficiary removes a saved payee. Transfers only ever store account ids, so history is untouched.
+func (r *repositoryImpl) DeleteBeneficiary(ctx context.Context, id int64) error {
+	query := `DELETE FROM beneficiaries WHERE id = @id`
 	args := pgx.NamedArgs{
-		"username":               user.UserName,
-		"newFullName":            user.FullName,
-		"newPassword":            user.HashedPassword,
-		"newPasswordChangedTime": user.PasswordChangedAt,
-		"newEmail":               user.Email,
-		"emailVerified":          user.IsEmailVerified,
+		"id": id,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&u.UserName, &u.HashedPassword, &u.FullName, &u.Email, &u.PasswordChangedAt, &u.CreatedAt, &user.IsEmailVerified)
+	tag, err := r.pool.Exec(ctx, query, args)
 	if err != nil {
-		return u, err
+		return err
 	}
 
-	return u, nil
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
 }
 
-func (r *repositoryImpl) CreateVerifyEmail(ctx context.Context, data models.VerifyEmails) (models.VerifyEmails, error) {
-	var e models.VerifyEmails
-	query := `INSERT INTO verify_emails (username, email, secret_code) VALUES (@userName, @email, @secretCode) RETURNING *`
+const webhookColumns = `id, owner, url, events, secret, active, consecutive_failures, created_at`
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var w models.Webhook
+	err := row.Scan(&w.ID, &w.Owner, &w.URL, &w.Events, &w.Secret, &w.Active, &w.ConsecutiveFailures, &w.CreatedAt)
+	return w, err
+}
+
+func (r *repositoryImpl) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	query := `INSERT INTO webhooks (owner, url, events, secret) VALUES (@owner, @url, @events, @secret)
+				RETURNING ` + webhookColumns
 	args := pgx.NamedArgs{
-		"userName":   data.Username,
-		"secretCode": data.SecretCode,
-		"email":      data.Email,
+		"owner":  webhook.Owner,
+		"url":    webhook.URL,
+		"events": webhook.Events,
+		"secret": webhook.Secret,
 	}
 
-	err := r.pool.QueryRow(ctx, query, args).Scan(&e.ID, &e.Username, &e.Email, &e.SecretCode, &e.IsUsed, &e.CreatedAt, &e.ExpiredAt)
-	if err != nil {
-		return e, err
+	return scanWebhook(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
 	}
 
-	return e, nil
+	return scanWebhook(r.pool.QueryRow(ctx, query, args))
 }
 
-func (r *repositoryImpl) execTx(ctx context.Context, fn func(pgx.Tx) error) error {
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		return err
+func (r *repositoryImpl) ListWebhooks(ctx context.Context, arg models.ListWebhooksParams) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE owner = @owner ORDER BY id LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"owner":  arg.Owner,
+		"limit":  arg.Limit,
+		"offset": arg.Offset,
+	}
+
+	return r.queryWebhooks(ctx, query, args)
+}
+
+// ListSubscribedWebhooks returns the active webhooks of the owners that subscribed to the event
+func (r *repositoryImpl) ListSubscribedWebhooks(ctx context.Context, owners []string, event string) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks
+				WHERE owner = ANY(@owners) AND active AND @event = ANY(events) ORDER BY id`
+	args := pgx.NamedArgs{
+		"owners": owners,
+		"event":  event,
 	}
 
-	err = fn(tx)
+	return r.queryWebhooks(ctx, query, args)
+}
+
+func (r *repositoryImpl) queryWebhooks(ctx context.Context, query string, args pgx.NamedArgs) ([]models.Webhook, error) {
+	rows, err := r.pool.Query(ctx, query, args)
 	if err != nil {
-		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
 		}
+		webhooks = append(webhooks, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *repositoryImpl) DeleteWebhook(ctx context.Context, id int64) error {
+	query := `DELETE FROM webhooks WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args)
+	if err != nil {
 		return err
 	}
 
-	return tx.Commit(ctx)
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
 }
 
-func (r *repositoryImpl) VerifyEmailTx(ctx context.Context, arg models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
-	var result models.VerifyEmailTxResult
+// RecordWebhookDelivery resets the failure run of a webhook on success. A failure extends
+// the run and disables the webhook once it reaches maxFailures.
+func (r *repositoryImpl) RecordWebhookDelivery(ctx context.Context, id int64, succeeded bool, maxFailures int32) (models.Webhook, error) {
+	query := `UPDATE webhooks SET
+				consecutive_failures = CASE WHEN @succeeded THEN 0 ELSE consecutive_failures + 1 END,
+				active = active AND (@succeeded OR consecutive_failures + 1 < @maxFailures)
+				WHERE id = @id
+				RETURNING ` + webhookColumns
+	args := pgx.NamedArgs{
+		"id":          id,
+		"succeeded":   succeeded,
+		"maxFailures": maxFailures,
+	}
 
-	err := r.execTx(ctx, func(tx pgx.Tx) error {
-		var err error
+	return scanWebhook(r.pool.QueryRow(ctx, query, args))
+}
 
-		// update verify emails table
-		query1 := `UPDATE verify_emails SET is_used = TRUE WHERE id = @id AND secret_code = @code AND is_used = FALSE AND expired_at > now() RETURNING *`
-		args1 := pgx.NamedArgs{
-			"id":   arg.EmailId,
-			"code": arg.SecretCode,
-		}
+const notificationColumns = `id, username, kind, title, body, read_at, created_at`
 
-		err = tx.QueryRow(ctx, query1, args1).Scan(&result.VerifyEmail.ID, &result.VerifyEmail.Username, &result.VerifyEmail.Email, &result.VerifyEmail.SecretCode,
-			&result.VerifyEmail.IsUsed, &result.VerifyEmail.CreatedAt, &result.VerifyEmail.ExpiredAt)
+func scanNotification(row pgx.Row) (models.Notification, error) {
+	var n models.Notification
+	err := row.Scan(&n.ID, &n.Username, &n.Kind, &n.Title, &n.Body, &n.ReadAt, &n.CreatedAt)
+	return n, err
+}
+
+func (r *repositoryImpl) CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	query := `INSERT INTO notifications (username, kind, title, body) VALUES (@username, @kind, @title, @body)
+				RETURNING ` + notificationColumns
+	args := pgx.NamedArgs{
+		"username": notification.Username,
+		"kind":     notification.Kind,
+		"title":    notification.Title,
+		"body":     notification.Body,
+	}
+
+	return scanNotification(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) GetNotification(ctx context.Context, id int64) (models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanNotification(r.pool.QueryRow(ctx, query, args))
+}
+
+func (r *repositoryImpl) ListNotifications(ctx context.Context, arg models.ListNotificationsParams) ([]models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications
+				WHERE username = @username AND (@beforeID = 0 OR (created_at, id) < (@beforeCreatedAt, @beforeID))
+				ORDER BY created_at DESC, id DESC LIMIT @limit`
+	args := pgx.NamedArgs{
+		"username":        arg.Username,
+		"beforeCreatedAt": arg.BeforeCreatedAt,
+		"beforeID":        arg.BeforeID,
+		"limit":           arg.Limit,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		notifications = append(notifications, n)
+	}
 
-		// update users table
-		query2 := `UPDATE users SET is_email_verified = COALESCE(@emailVerified, is_email_verified) WHERE username = @username RETURNING *`
-		args2 := pgx.NamedArgs{
-			"username":      result.VerifyEmail.Username,
-			"emailVerified": true,
-		}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		err = tx.QueryRow(ctx, query2, args2).Scan(&result.User.UserName, &result.User.HashedPassword, &result.User.FullName,
-			&result.User.Email, &result.User.PasswordChangedAt, &result.User.CreatedAt, &result.User.IsEmailVerified)
-		return err
-	})
+	return notifications, nil
+}
 
-	return result, err
+// MarkNotificationRead keeps the time a notification was first read, marking it again changes nothing
+func (r *repositoryImpl) MarkNotificationRead(ctx context.Context, id int64) (models.Notification, error) {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = @id
+				RETURNING ` + notificationColumns
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	return scanNotification(r.pool.QueryRow(ctx, query, args))
 }
 
-func (r *repositoryImpl) CreateUserTx(ctx context.Context, arg models.CreateUserTxParams) (models.User, error) {
-	var result models.User
+func (r *repositoryImpl) CountUnreadNotifications(ctx context.Context, username string) (int64, error) {
+	var count int64
+	query := `SELECT count(*) FROM notifications WHERE username = @username AND read_at IS NULL`
+	args := pgx.NamedArgs{
+		"username": username,
+	}
 
-	err := r.execTx(ctx, func(tx pgx.Tx) error {
-		var err error
+	err := r.pool.QueryRow(ctx, query, args).Scan(&count)
+	return count, err
+}
 
-		// create user
-		query := `INSERT INTO users (username, hashed_password, full_name, email) VALUES (@userName, @password, @fullName, @email) RETURNING *`
-		args := pgx.NamedArgs{
-			"userName": arg.User.UserName,
-			"password": arg.User.HashedPassword,
-			"fullName": arg.User.FullName,
-			"email":    arg.User.Email,
-		}
+// HasOtherSessionFromDevice reports whether the user logged in with the user agent before the given session
+func (r *repositoryImpl) HasOtherSessionFromDevice(ctx context.Context, username, userAgent string, sessionID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM sessions WHERE username = @username AND user_agent = @userAgent AND id <> @id)`
+	args := pgx.NamedArgs{
+		"username":  username,
+		"userAgent": userAgent,
+		"id":        sessionID,
+	}
 
-		err = tx.QueryRow(ctx, query, args).Scan(&result.UserName, &result.HashedPassword, &result.FullName, &result.Email,
-			 &result.PasswordChangedAt, &result.CreatedAt, &result.IsEmailVerified)
-		if err != nil {
-			return err
-		}
+	err := r.pool.QueryRow(ctx, query, args).Scan(&exists)
+	return exists, err
+}
 
-		return arg.AfterCreate(result) // callback function
-	})
+func (r *repositoryImpl) CreateFailedNotification(ctx context.Context, arg models.FailedNotification) (models.FailedNotification, error) {
+	var n models.FailedNotification
+	query := `INSERT INTO failed_notifications (username, kind, channel, recipient, subject, error)
+				VALUES (@username, @kind, @channel, @recipient, @subject, @error)
+				RETURNING id, username, kind, channel, recipient, subject, error, created_at`
+	args := pgx.NamedArgs{
+		"username":  arg.Username,
+		"kind":      arg.Kind,
+		"channel":   arg.Channel,
+		"recipient": arg.Recipient,
+		"subject":   arg.Subject,
+		"error":     arg.Error,
+	}
 
-	return result, err
+	err := r.pool.QueryRow(ctx, query, args).Scan(&n.ID, &n.Username, &n.Kind, &n.Channel, &n.Recipient, &n.Subject, &n.Error, &n.CreatedAt)
+	return n, err
 }
 
-func (r *repositoryImpl) TransferTx(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error) {
-	var result models.TransferTxResult
+const statementDocumentColumns = `id, account_id, year, month, status, object_key, failure_reason, requested_by, created_at, updated_at`
+
+func scanStatementDocument(row pgx.Row) (models.StatementDocument, error) {
+	var d models.StatementDocument
+	err := row.Scan(&d.ID, &d.AccountID, &d.Year, &d.Month, &d.Status, &d.ObjectKey, &d.FailureReason, &d.RequestedBy, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// RequestStatementTx queues the rendering of a statement, or renders one that is ready or failed
+// again. A statement that is still pending is returned without queueing it twice.
+func (r *repositoryImpl) RequestStatementTx(ctx context.Context, arg models.RequestStatementTxParams) (models.StatementDocument, error) {
+	var result models.StatementDocument
 
-	err := r.execTx(ctx, func(tx pgx.Tx) error {
+	err := r.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
 		var err error
-		// create transaction
-		query := `INSERT INTO transactions (amount, fee, currency, description, to_account_id, from_account_id) VALUES 
-					(@amount, @fee, @currency, @description, @toAccountID, @fromAccountID) RETURNING  id, from_account_id, to_account_id,
-					 amount, fee, currency, description, created_at`
+
+		query := `INSERT INTO statements (account_id, year, month, requested_by) VALUES (@accountID, @year, @month, @requestedBy)
+					ON CONFLICT (account_id, year, month) DO UPDATE SET status = @pending, object_key = '', failure_reason = '',
+						requested_by = EXCLUDED.requested_by, updated_at = now()
+					WHERE statements.status <> @pending
+					RETURNING ` + statementDocumentColumns
 		args := pgx.NamedArgs{
-			"amount":        arg.Amount,
-			"fee":           arg.Fee,
-			"currency":      arg.Currency,
-			"description":   arg.Description,
-			"fromAccountID": arg.FromAccountID,
-			"toAccountID":   arg.ToAccountID,
+			"accountID":   arg.AccountID,
+			"year":        arg.Year,
+			"month":       arg.Month,
+			"requestedBy": arg.RequestedBy,
+			"pending":     models.StatementDocumentPending,
 		}
 
-		err = tx.QueryRow(ctx, query, args).Scan(&result.Transaction.ID, &result.Transaction.FromAccountID, &result.Transaction.ToAccountID, &result.Transaction.Amount, &result.Transaction.Fee, &result.Transaction.Currency, &result.Transaction.Description, &result.Transaction.CreatedAt)
-		if err != nil {
+		result, err = scanStatementDocument(tx.QueryRow(ctx, query, args))
+		if errors.Is(err, pgx.ErrNoRows) {
+			// already pending, its rendering is queued
+			query = `SELECT ` + statementDocumentColumns + ` FROM statements WHERE account_id = @accountID AND year = @year AND month = @month`
+			result, err = scanStatementDocument(tx.QueryRow(ctx, query, args))
 			return err
 		}
-
-		// create entry for sender account
-		query2 := `INSERT INTO entries (account_id, amount) VALUES (@accountID, @amount) RETURNING id, account_id, amount, created_at`
-		args2 := pgx.NamedArgs{
-			"accountID": arg.FromAccountID,
-			"amount":    -arg.Amount,
-		}
-
-		err = tx.QueryRow(ctx, query2, args2).Scan(&result.FromEntry.ID, &result.FromEntry.AccountID, &result.FromEntry.Amount, &result.FromEntry.CreatedAt)
 		if err != nil {
 			return err
 		}
 
-		// create entry for receiver account
-		query3 := `INSERT INTO entries (account_id, amount) VALUES (@accountID, @amount) RETURNING id, account_id, amount, created_at`
-		args3 := pgx.NamedArgs{
-			"accountID": arg.ToAccountID,
-			"amount":    arg.Amount,
-		}
-
-		err = tx.QueryRow(ctx, query3, args3).Scan(&result.ToEntry.ID, &result.ToEntry.AccountID, &result.ToEntry.Amount, &result.ToEntry.CreatedAt)
+		messages, err := arg.AfterRequest(result) // callback function
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			// update the sender's new balance first and then receiver's balance to avoid deadlock
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, tx, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			// update the receiver's new balance first and then sender's balance to avoid deadlock
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, tx, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
-
-		return err
+		return addOutboxMessages(ctx, tx, messages)
 	})
 
 	return result, err
 }
 
-func addMoney(
-	ctx context.Context,
-	tx pgx.Tx,
-	accountID1 int64,
-	amount1 float64,
-	accountID2 int64,
-	amount2 float64,
-) (account1 models.Account, account2 models.Account, err error) {
-	// update account 1
-	query := "UPDATE accounts SET balance = balance + @amount WHERE id = @id RETURNING id, owner, balance, currency, created_at"
+func (r *repositoryImpl) GetStatementDocument(ctx context.Context, id int64) (models.StatementDocument, error) {
+	query := `SELECT ` + statementDocumentColumns + ` FROM statements WHERE id = @id`
+	return scanStatementDocument(r.pool.QueryRow(ctx, query, pgx.NamedArgs{"id": id}))
+}
+
+// FinishStatementDocument records the outcome of rendering a pending statement. It returns
+// pgx.ErrNoRows when the statement is not pending anymore.
+func (r *repositoryImpl) FinishStatementDocument(ctx context.Context, arg models.FinishStatementDocumentParams) (models.StatementDocument, error) {
+	query := `UPDATE statements SET status = @status, object_key = @objectKey, failure_reason = @failureReason, updated_at = now()
+				WHERE id = @id AND status = @pending
+				RETURNING ` + statementDocumentColumns
 	args := pgx.NamedArgs{
-		"id":     accountID1,
-		"amount": amount1,
+		"id":            arg.ID,
+		"status":        arg.Status,
+		"objectKey":     arg.ObjectKey,
+		"failureReason": arg.FailureReason,
+		"pending":       models.StatementDocumentPending,
+	}
+
+	return scanStatementDocument(r.pool.QueryRow(ctx, query, args))
+}
+
+// ListStatementDocuments lists the statements of an account, the latest month first
+func (r *repositoryImpl) ListStatementDocuments(ctx context.Context, arg models.ListStatementDocumentsParams) ([]models.StatementDocument, error) {
+	query := `SELECT ` + statementDocumentColumns + ` FROM statements WHERE account_id = @accountID
+				ORDER BY year DESC, month DESC LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"accountID": arg.AccountID,
+		"limit":     arg.Limit,
+		"offset":    arg.Offset,
 	}
 
-	err = tx.QueryRow(ctx, query, args).Scan(&account1.ID, &account1.Owner, &account1.Balance, &account1.Currency, &account1.CreatedAt)
+	rows, err := r.pool.Query(ctx, query, args)
 	if err != nil {
-		return account1, account2, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	// update account 2
-	query2 := "UPDATE accounts SET balance = balance + @amount WHERE id = @id RETURNING id, owner, balance, currency, created_at"
-	args2 := pgx.NamedArgs{
-		"id":     accountID2,
-		"amount": amount2,
+	documents := []models.StatementDocument{}
+	for rows.Next() {
+		d, err := scanStatementDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, d)
 	}
 
-	err = tx.QueryRow(ctx, query2, args2).Scan(&account2.ID, &account2.Owner, &account2.Balance, &account2.Currency, &account2.CreatedAt)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+const auditLogColumns = `id, actor, action, entity, entity_id, before, after, request_id, created_at`
+
+func scanAuditLog(row pgx.Row) (models.AuditLog, error) {
+	var l models.AuditLog
+	err := row.Scan(&l.ID, &l.Actor, &l.Action, &l.Entity, &l.EntityID, &l.Before, &l.After, &l.RequestID, &l.CreatedAt)
+	return l, err
+}
+
+// CreateAuditLog appends to the audit log, which is never updated
+func (r *repositoryImpl) CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error) {
+	query := `INSERT INTO audit_logs (actor, action, entity, entity_id, before, after, request_id)
+				VALUES (@actor, @action, @entity, @entityID, @before, @after, @requestID) RETURNING ` + auditLogColumns
+	args := pgx.NamedArgs{
+		"actor":     arg.Actor,
+		"action":    arg.Action,
+		"entity":    arg.Entity,
+		"entityID":  arg.EntityID,
+		"before":    arg.Before,
+		"after":     arg.After,
+		"requestID": arg.RequestID,
+	}
+
+	return scanAuditLog(r.pool.QueryRow(ctx, query, args))
+}
+
+// ListAuditLogs returns the audit log of an entity, or of every entity of its kind, newest first
+func (r *repositoryImpl) ListAuditLogs(ctx context.Context, arg models.ListAuditLogsParams) ([]models.AuditLog, error) {
+	query := `SELECT ` + auditLogColumns + ` FROM audit_logs WHERE entity = @entity AND (@entityID = '' OR entity_id = @entityID)
+				ORDER BY created_at DESC, id DESC LIMIT @limit OFFSET @offset`
+	args := pgx.NamedArgs{
+		"entity":   arg.Entity,
+		"entityID": arg.EntityID,
+		"limit":    arg.Limit,
+		"offset":   arg.Offset,
+	}
+
+	rows, err := r.pool.Query(ctx, query, args)
 	if err != nil {
-		return account1, account2, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return account1, account2, err
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		l, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
 }
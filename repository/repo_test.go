@@ -3,11 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/require"
 	"github.com/zde37/Swift_Bank/helpers"
 	"github.com/zde37/Swift_Bank/models"
@@ -18,7 +23,7 @@ func createRandomAccount(t *testing.T) models.Account {
 
 	arg := models.Account{
 		Owner:    user.UserName,
-		Balance:  float64(helpers.RandomMoney()),
+		Balance:  helpers.RandomMoney(),
 		Currency: helpers.RandomCurrency(),
 	}
 
@@ -36,10 +41,29 @@ func createRandomAccount(t *testing.T) models.Account {
 	return account
 }
 
+// fundAccount gives the account enough money for the transfers of a test, which are checked
+// against its balance
+func fundAccount(t *testing.T, account models.Account) models.Account {
+	account, err := testRepo.R.UpdateAccount(context.Background(), account.ID, 100000)
+	require.NoError(t, err)
+
+	return account
+}
+
+// createFeeAccount makes an account of a new user holding currency, to collect transfer fees in it
+func createFeeAccount(t *testing.T, currency string) models.Account {
+	user := createRandomUser(t)
+
+	account, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Currency: currency})
+	require.NoError(t, err)
+
+	return account
+}
+
 func createRandomEntry(t *testing.T, account *models.Account) models.Entry {
 	arg := models.Entry{
 		AccountID: account.ID,
-		Amount:    float64(helpers.RandomMoney()),
+		Amount:    helpers.RandomMoney(),
 	}
 
 	entry, err := testRepo.R.CreateEntry(context.Background(), arg)
@@ -60,8 +84,8 @@ func createRandomTransaction(t *testing.T, account1, account2 *models.Account) m
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Currency:      helpers.RandomCurrency(),
-		Amount:        float64(helpers.RandomMoney()),
-		Fee:           float64(helpers.RandomFee()),
+		Amount:        helpers.RandomMoney(),
+		Fee:           helpers.RandomFee(),
 		Description:   helpers.RandomString(20),
 	}
 
@@ -75,6 +99,9 @@ func createRandomTransaction(t *testing.T, account1, account2 *models.Account) m
 	require.Equal(t, arg.Amount, transaction.Amount)
 	require.Equal(t, arg.Description, transaction.Description)
 	require.Equal(t, arg.Fee, transaction.Fee)
+	require.Equal(t, arg.Amount, transaction.ToAmount)
+	require.Equal(t, arg.Currency, transaction.ToCurrency)
+	require.Equal(t, float64(1), transaction.ExchangeRate)
 
 	require.NotZero(t, transaction.ID)
 	require.NotZero(t, transaction.CreatedAt)
@@ -101,6 +128,7 @@ func createRandomUser(t *testing.T) models.User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, helpers.DepositorRole, user.Role)
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
@@ -112,6 +140,36 @@ func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
 
+func TestCreateAccountOnePerCurrency(t *testing.T) {
+	user := createRandomUser(t)
+
+	// both requests can get past a check for an existing account, the unique index stops the second
+	n := 2
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Currency: helpers.USD})
+			errs <- err
+		}()
+	}
+
+	var failed []error
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			failed = append(failed, err)
+		}
+	}
+	require.Len(t, failed, 1)
+	require.ErrorIs(t, failed[0], ErrAccountExists)
+
+	account, err := testRepo.R.GetAccountByCurrency(context.Background(), user.UserName, helpers.USD, models.AccountChecking)
+	require.NoError(t, err)
+	require.Equal(t, user.UserName, account.Owner)
+
+	_, err = testRepo.R.GetAccountByCurrency(context.Background(), user.UserName, helpers.EUR, models.AccountChecking)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
 func TestGetAccount(t *testing.T) {
 	// create account
 	account1 := createRandomAccount(t)
@@ -127,10 +185,243 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
+func TestSetOverdraftLimitTx(t *testing.T) {
+	account := createRandomAccount(t)
+	require.Zero(t, account.OverdraftLimit)
+	banker := createRandomUser(t)
+
+	updated, err := testRepo.R.SetOverdraftLimitTx(context.Background(), models.SetOverdraftLimitParams{
+		AccountID: account.ID,
+		Limit:     250,
+		ChangedBy: banker.UserName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, updated.ID)
+	require.Equal(t, int64(250), updated.OverdraftLimit)
+	require.Equal(t, account.Balance, updated.Balance)
+
+	_, err = testRepo.R.SetOverdraftLimitTx(context.Background(), models.SetOverdraftLimitParams{
+		AccountID: account.ID,
+		Limit:     -1,
+		ChangedBy: banker.UserName,
+	})
+	require.Error(t, err)
+
+	_, err = testRepo.R.SetOverdraftLimitTx(context.Background(), models.SetOverdraftLimitParams{
+		AccountID: 0,
+		Limit:     10,
+		ChangedBy: banker.UserName,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUpdateAccountDetails(t *testing.T) {
+	account := createRandomAccount(t)
+	require.Empty(t, account.Nickname)
+	require.Empty(t, account.Metadata)
+
+	updated, err := testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: account.ID,
+		Nickname:  sql.NullString{String: "Rainy day", Valid: true},
+		Metadata:  map[string]string{"goal": "roof"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Rainy day", updated.Nickname)
+	require.Equal(t, map[string]string{"goal": "roof"}, updated.Metadata)
+	require.Equal(t, account.Balance, updated.Balance)
+
+	// unset fields are kept
+	updated, err = testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: account.ID,
+		Metadata:  map[string]string{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Rainy day", updated.Nickname)
+	require.Empty(t, updated.Metadata)
+
+	// another account of the owner can't take the nickname
+	currency := "EUR"
+	if account.Currency == currency {
+		currency = "USD"
+	}
+	other, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: account.Owner, Currency: currency})
+	require.NoError(t, err)
+	_, err = testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: other.ID,
+		Nickname:  sql.NullString{String: "Rainy day", Valid: true},
+	})
+	require.ErrorIs(t, err, ErrAccountNicknameExists)
+
+	// an empty nickname removes it, freeing it for the other account
+	updated, err = testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: account.ID,
+		Nickname:  sql.NullString{Valid: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, updated.Nickname)
+	_, err = testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: other.ID,
+		Nickname:  sql.NullString{String: "Rainy day", Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testRepo.R.UpdateAccountDetails(context.Background(), models.UpdateAccountDetailsParams{
+		AccountID: 0,
+		Nickname:  sql.NullString{String: "Nowhere", Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestBulkCreateAccountsTx(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	existing, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user2.UserName, Currency: helpers.USD})
+	require.NoError(t, err)
+
+	rows := []models.BulkAccountRow{
+		{Owner: user1.UserName, Currency: helpers.USD, Balance: 1050},
+		{Owner: user1.UserName, Currency: helpers.EUR},
+		{Owner: helpers.RandomOwner(), Currency: helpers.USD},
+		{Owner: user1.UserName, Currency: helpers.USD},
+		{Owner: existing.Owner, Currency: existing.Currency},
+		{Owner: user2.UserName, Currency: "XYZ", Reason: "currency must be a supported currency"},
+		{Owner: user2.UserName, Currency: helpers.CAD, Balance: 99},
+	}
+
+	// all or nothing creates none of them
+	results, err := testRepo.R.BulkCreateAccountsTx(context.Background(), models.BulkCreateAccountsParams{Rows: rows})
+	require.NoError(t, err)
+	require.Len(t, results, len(rows))
+	for _, i := range []int{0, 1, 6} {
+		require.Equal(t, models.BulkAccountSkipped, results[i].Status)
+		require.Zero(t, results[i].Account.ID)
+	}
+
+	accounts, err := testRepo.R.ListAccounts(context.Background(), models.ListAccountsParams{Owner: user1.UserName, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	// best effort creates the accepted rows
+	results, err = testRepo.R.BulkCreateAccountsTx(context.Background(), models.BulkCreateAccountsParams{Rows: rows, BestEffort: true})
+	require.NoError(t, err)
+	require.Len(t, results, len(rows))
+
+	require.Equal(t, models.BulkAccountCreated, results[0].Status)
+	require.NotZero(t, results[0].Account.ID)
+	require.Equal(t, user1.UserName, results[0].Account.Owner)
+	require.Equal(t, int64(1050), results[0].Account.Balance)
+	require.Equal(t, helpers.USD, results[0].Account.Currency)
+	require.Equal(t, models.BulkAccountCreated, results[1].Status)
+	require.Equal(t, models.BulkAccountCreated, results[6].Status)
+	require.Equal(t, int64(99), results[6].Account.Balance)
+
+	require.Equal(t, models.BulkAccountResult{Status: models.BulkAccountRejected, Reason: "owner does not exist"}, results[2])
+	require.Equal(t, models.BulkAccountResult{Status: models.BulkAccountRejected, Reason: "duplicates row 0"}, results[3])
+	require.Equal(t, models.BulkAccountResult{Status: models.BulkAccountRejected, Reason: "owner already has a USD account"}, results[4])
+	require.Equal(t, models.BulkAccountResult{Status: models.BulkAccountRejected, Reason: rows[5].Reason}, results[5])
+
+	account, err := testRepo.R.GetAccount(context.Background(), results[1].Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, results[1].Account, account)
+
+	// importing again rejects everything
+	results, err = testRepo.R.BulkCreateAccountsTx(context.Background(), models.BulkCreateAccountsParams{Rows: rows[:2], BestEffort: true})
+	require.NoError(t, err)
+	for _, result := range results {
+		require.Equal(t, models.BulkAccountRejected, result.Status)
+	}
+}
+
+// benchmarkAccountImport times insert creating accounts for 10000 new users, in a transaction
+// rolled back after each iteration so every one starts from the same table
+func benchmarkAccountImport(b *testing.B, insert func(ctx context.Context, tx pgx.Tx, rows []models.BulkAccountRow) error) {
+	const n = 10000
+	ctx := context.Background()
+	pool := testRepo.R.(*repositoryImpl).pool
+
+	rows := make([]models.BulkAccountRow, n)
+	for i := range rows {
+		rows[i] = models.BulkAccountRow{Owner: fmt.Sprintf("bench_user_%d", i), Currency: helpers.USD, Balance: int64(i)}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tx, err := pool.Begin(ctx)
+		require.NoError(b, err)
+
+		_, err = tx.Exec(ctx, `INSERT INTO users (username, hashed_password, full_name, email)
+			SELECT 'bench_user_' || i, 'secret', 'Bench User', 'bench_user_' || i || '@example.com' FROM generate_series(0, @n - 1) AS i`, pgx.NamedArgs{"n": n})
+		require.NoError(b, err)
+		b.StartTimer()
+
+		require.NoError(b, insert(ctx, tx, rows))
+
+		b.StopTimer()
+		require.NoError(b, tx.Rollback(ctx))
+		b.StartTimer()
+	}
+}
+
+func BenchmarkImportAccountsCopyFrom(b *testing.B) {
+	benchmarkAccountImport(b, func(ctx context.Context, tx pgx.Tx, rows []models.BulkAccountRow) error {
+		_, err := copyAccounts(ctx, tx, rows)
+		return err
+	})
+}
+
+func BenchmarkImportAccountsRowByRow(b *testing.B) {
+	benchmarkAccountImport(b, func(ctx context.Context, tx pgx.Tx, rows []models.BulkAccountRow) error {
+		for _, row := range rows {
+			query := `INSERT INTO accounts (owner, balance, currency) VALUES (@owner, @balance, @currency)`
+			args := pgx.NamedArgs{
+				"owner":    row.Owner,
+				"balance":  row.Balance,
+				"currency": row.Currency,
+			}
+
+			if _, err := tx.Exec(ctx, query, args); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func TestCreateUser(t *testing.T) {
 	createRandomUser(t)
 }
 
+func TestCreateUserCaseInsensitive(t *testing.T) {
+	user := createRandomUser(t)
+
+	testCases := []models.User{
+		{UserName: strings.ToUpper(user.UserName), Email: helpers.RandomEmail()},
+		{UserName: helpers.RandomOwner(), Email: strings.ToUpper(user.Email)},
+	}
+
+	for _, arg := range testCases {
+		arg.FullName = user.FullName
+		arg.HashedPassword = user.HashedPassword
+
+		_, err := testRepo.R.CreateUser(context.Background(), arg)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+	}
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	found, err := testRepo.R.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.UserName, found.UserName)
+
+	_, err = testRepo.R.GetUserByEmail(context.Background(), helpers.RandomEmail())
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
 func TestGetUser(t *testing.T) {
 	// create user
 	user1 := createRandomUser(t)
@@ -213,6 +504,85 @@ func TestUpdateUserPassword(t *testing.T) {
 	require.Equal(t, user1.Email, updatedUser.Email)
 }
 
+// updateUserEmailTx changes the user's email through UpdateUserTx, returning the verify email it created
+func updateUserEmailTx(t *testing.T, username, email string) (models.UpdateUserTxResult, error) {
+	return testRepo.R.UpdateUserTx(context.Background(), models.UpdateUserTxParams{
+		UpdateUserParams: models.UpdateUserParams{
+			UserName: username,
+			Email:    sql.NullString{String: email, Valid: true},
+		},
+		SecretCode: helpers.RandomString(32),
+		AfterEmailChange: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+			payload := fmt.Sprintf(`{"username":%q,"verify_email_id":%d}`, user.UserName, verifyEmail.ID)
+			return []models.OutboxMessage{{TaskType: helpers.RandomString(12), Payload: []byte(payload), Queue: "critical", MaxRetry: 10}}, nil
+		},
+	})
+}
+
+func TestUpdateUserTxChangesEmail(t *testing.T) {
+	user, verifyEmail := createUserWithOutboxMessage(t, helpers.RandomString(12))
+	_, err := testRepo.R.VerifyEmailTx(context.Background(), models.VerifyEmailTxParams{
+		EmailId:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.NoError(t, err)
+
+	newEmail := helpers.RandomEmail()
+	result, err := updateUserEmailTx(t, user.UserName, newEmail)
+	require.NoError(t, err)
+	require.Equal(t, newEmail, result.User.Email)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, user.Email, result.User.PreviousEmail)
+	require.WithinDuration(t, time.Now(), result.User.EmailChangedAt, time.Minute)
+	require.Equal(t, newEmail, result.VerifyEmail.Email)
+
+	// recovery mail keeps going to the verified address during the grace period
+	require.Equal(t, user.Email, result.User.RecoveryEmail(time.Now(), time.Hour))
+	require.Equal(t, newEmail, result.User.RecoveryEmail(time.Now().Add(2*time.Hour), time.Hour))
+
+	// the link sent to the old address no longer verifies anything
+	_, err = testRepo.R.VerifyEmailTx(context.Background(), models.VerifyEmailTxParams{
+		EmailId:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.Error(t, err)
+
+	verified, err := testRepo.R.VerifyEmailTx(context.Background(), models.VerifyEmailTxParams{
+		EmailId:    result.VerifyEmail.ID,
+		SecretCode: result.VerifyEmail.SecretCode,
+	})
+	require.NoError(t, err)
+	require.True(t, verified.User.IsEmailVerified)
+	require.Equal(t, newEmail, verified.User.RecoveryEmail(time.Now(), time.Hour))
+}
+
+func TestUpdateUserTxSameEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	result, err := updateUserEmailTx(t, user.UserName, user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, result.User.Email)
+	require.Equal(t, user.IsEmailVerified, result.User.IsEmailVerified)
+	require.Empty(t, result.User.PreviousEmail)
+	require.Zero(t, result.VerifyEmail.ID)
+}
+
+func TestUpdateUserTxTakenEmail(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+
+	_, err := updateUserEmailTx(t, user1.UserName, strings.ToUpper(user2.Email))
+	require.Error(t, err)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, uniqueViolation, pgErr.Code)
+
+	stored, err := testRepo.R.GetUser(context.Background(), user1.UserName)
+	require.NoError(t, err)
+	require.Equal(t, user1.Email, stored.Email)
+	require.True(t, stored.EmailChangedAt.IsZero())
+}
+
 func TestUpdateAllUserDetails(t *testing.T) {
 	user1 := createRandomUser(t)
 
@@ -248,6 +618,43 @@ func TestUpdateAllUserDetails(t *testing.T) {
 	require.Equal(t, newPassword, updatedUser.HashedPassword)
 }
 
+func TestVerifyPhoneTx(t *testing.T) {
+	user := createRandomUser(t)
+	phoneNumber := "+1415555" + fmt.Sprintf("%04d", helpers.RandomInt(0, 9999))
+
+	user, err := testRepo.R.UpdateUser(context.Background(), models.UpdateUserParams{
+		UserName:    user.UserName,
+		PhoneNumber: sql.NullString{String: phoneNumber, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, user.IsPhoneVerified)
+
+	verifyPhone, err := testRepo.R.CreateVerifyPhone(context.Background(), models.VerifyPhones{
+		Username:    user.UserName,
+		PhoneNumber: phoneNumber,
+		SecretCode:  "123456",
+	})
+	require.NoError(t, err)
+
+	// a wrong guess counts, the right code still works after it
+	_, err = testRepo.R.VerifyPhoneTx(context.Background(), models.VerifyPhoneTxParams{Username: user.UserName, SecretCode: "654321"})
+	require.ErrorIs(t, err, ErrWrongPhoneCode)
+
+	result, err := testRepo.R.VerifyPhoneTx(context.Background(), models.VerifyPhoneTxParams{Username: user.UserName, SecretCode: "123456"})
+	require.NoError(t, err)
+	require.Equal(t, verifyPhone.ID, result.VerifyPhone.ID)
+	require.Equal(t, int32(2), result.VerifyPhone.Attempts)
+	require.True(t, result.User.IsPhoneVerified)
+
+	// a code is used once
+	_, err = testRepo.R.VerifyPhoneTx(context.Background(), models.VerifyPhoneTxParams{Username: user.UserName, SecretCode: "123456"})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	others, err := testRepo.R.CountUsersWithPhoneNumber(context.Background(), phoneNumber, user.UserName)
+	require.NoError(t, err)
+	require.Zero(t, others)
+}
+
 func TestListUsers(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		createRandomUser(t)
@@ -269,13 +676,13 @@ func TestUpdateAccount(t *testing.T) {
 
 	newBalance := helpers.RandomMoney()
 
-	account2, err := testRepo.R.UpdateAccount(context.Background(), account1.ID, float64(newBalance))
+	account2, err := testRepo.R.UpdateAccount(context.Background(), account1.ID, newBalance)
 	require.NoError(t, err)
 	require.NotEmpty(t, account2)
 
 	require.Equal(t, account1.ID, account2.ID)
 	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, float64(newBalance), account2.Balance)
+	require.Equal(t, newBalance, account2.Balance)
 	require.Equal(t, account1.Currency, account2.Currency)
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
@@ -289,6 +696,111 @@ func TestDeleteAccount(t *testing.T) {
 	require.Error(t, err)
 	require.EqualError(t, err, pgx.ErrNoRows.Error())
 	require.Empty(t, account2)
+
+	// the row stays, soft deleted
+	account3, err := testRepo.R.GetAccountIncludingDeleted(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account3.ID)
+	require.NotNil(t, account3.DeletedAt)
+	require.WithinDuration(t, time.Now(), *account3.DeletedAt, 5*time.Second)
+
+	accounts, err := testRepo.R.ListAccounts(context.Background(), models.ListAccountsParams{Owner: account1.Owner, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	_, err = testRepo.R.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{AccountID: account1.ID, Amount: 10})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	require.ErrorIs(t, testRepo.R.DeleteAccount(context.Background(), account1.ID), pgx.ErrNoRows)
+
+	// the owner can open another account in the currency
+	account4, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: account1.Owner, Currency: account1.Currency})
+	require.NoError(t, err)
+	require.NotEqual(t, account1.ID, account4.ID)
+}
+
+func TestDeleteAccountKeepsHistory(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, testRepo.R.DeleteAccount(context.Background(), account2.ID))
+
+	transfer, err := testRepo.R.GetTransaction(context.Background(), result.Transaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, transfer.ToAccountID)
+
+	entries, err := testRepo.R.ListEntries(context.Background(), models.ListEntriesParams{AccountID: account2.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// nothing is moved to or from a deleted account
+	_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+		Currency:      account2.Currency,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// an account with history is never purged
+	backdateAccountDeletion(t, account2.ID, time.Now().AddDate(-10, 0, 0))
+	_, err = testRepo.R.PurgeDeletedAccounts(context.Background(), time.Now().AddDate(-7, 0, 0))
+	require.NoError(t, err)
+
+	_, err = testRepo.R.GetAccountIncludingDeleted(context.Background(), account2.ID)
+	require.NoError(t, err)
+}
+
+// backdateAccountDeletion backdates when the account was deleted
+func backdateAccountDeletion(t *testing.T, id int64, deletedAt time.Time) {
+	_, err := testRepo.R.(*repositoryImpl).pool.Exec(context.Background(),
+		`UPDATE accounts SET deleted_at = @deletedAt WHERE id = @id`, pgx.NamedArgs{"id": id, "deletedAt": deletedAt})
+	require.NoError(t, err)
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	old := createRandomAccount(t)
+	recent := createRandomAccount(t)
+	saver := createRandomUser(t)
+
+	beneficiary, err := testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     saver.UserName,
+		AccountID: old.ID,
+		Nickname:  "closed",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, testRepo.R.DeleteAccount(context.Background(), old.ID))
+	require.NoError(t, testRepo.R.DeleteAccount(context.Background(), recent.ID))
+	backdateAccountDeletion(t, old.ID, time.Now().AddDate(-8, 0, 0))
+
+	purged, err := testRepo.R.PurgeDeletedAccounts(context.Background(), time.Now().AddDate(-7, 0, 0))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = testRepo.R.GetAccountIncludingDeleted(context.Background(), old.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testRepo.R.GetBeneficiary(context.Background(), beneficiary.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testRepo.R.GetAccountIncludingDeleted(context.Background(), recent.ID)
+	require.NoError(t, err)
 }
 
 func TestListAccounts(t *testing.T) {
@@ -299,7 +811,7 @@ func TestListAccounts(t *testing.T) {
 
 	limit, offset := 5, 0
 
-	accounts, err := testRepo.R.ListAccounts(context.Background(), lastAccount.Owner, int32(limit), int32(offset))
+	accounts, err := testRepo.R.ListAccounts(context.Background(), models.ListAccountsParams{Owner: lastAccount.Owner, Limit: int32(limit), Offset: int32(offset)})
 	require.NoError(t, err)
 	require.NotEmpty(t, accounts)
 
@@ -333,7 +845,7 @@ func TestListEntries(t *testing.T) {
 
 	limit, offset := 7, 3
 
-	entries, err := testRepo.R.ListEntries(context.Background(), account.ID, int64(limit), int64(offset))
+	entries, err := testRepo.R.ListEntries(context.Background(), models.ListEntriesParams{AccountID: account.ID, Limit: int64(limit), Offset: int64(offset)})
 	require.NoError(t, err)
 	require.NotEmpty(t, entries)
 
@@ -345,12 +857,66 @@ func TestListEntries(t *testing.T) {
 	}
 }
 
+func TestListEntriesBetween(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var sum int64
+	for i := 0; i < 5; i++ {
+		entry := createRandomEntry(t, &account)
+		sum += entry.Amount
+	}
+
+	from := time.Now().Add(-time.Minute)
+	to := time.Now().Add(time.Minute)
+
+	entries, err := testRepo.R.ListEntriesBetween(context.Background(), account.ID, from, to)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	for _, entry := range entries {
+		require.Equal(t, account.ID, entry.AccountID)
+		require.True(t, !entry.CreatedAt.Before(from) && entry.CreatedAt.Before(to))
+	}
+
+	// nothing was posted in the future window
+	entries, err = testRepo.R.ListEntriesBetween(context.Background(), account.ID, to, to.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	total, err := testRepo.R.SumEntriesSince(context.Background(), account.ID, from)
+	require.NoError(t, err)
+	require.Equal(t, sum, total)
+
+	total, err = testRepo.R.SumEntriesSince(context.Background(), account.ID, to)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
 func TestCreateTransaction(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
 	createRandomTransaction(t, &account1, &account2)
 }
 
+func TestCreateTransactionAmountChecks(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// amounts the edge should have rejected still can't be stored
+	for _, arg := range []models.Transaction{
+		{Amount: 0},
+		{Amount: -100},
+		{Amount: 100, Fee: -1},
+	} {
+		arg.FromAccountID, arg.ToAccountID, arg.Currency = account1.ID, account2.ID, account1.Currency
+
+		_, err := testRepo.R.CreateTransaction(context.Background(), arg)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23514", pgErr.Code)
+	}
+}
+
 func TestGetTransaction(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
@@ -385,42 +951,259 @@ func TestListTransactions(t *testing.T) {
 	}
 }
 
-func TestTransferTx(t *testing.T) {
+func TestListTransactionsBetween(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
-	fmt.Println(">== before: ", account1.Balance, account2.Balance)
 
-	// run 'n' concurrent transfer transactions
-	n := 5
-	amount := float64(10)
+	for i := 0; i < 3; i++ {
+		createRandomTransaction(t, &account1, &account2)
+		createRandomTransaction(t, &account2, &account1)
+	}
 
-	errs := make(chan error)
-	results := make(chan models.TransferTxResult)
+	from := time.Now().Add(-time.Minute)
+	to := time.Now().Add(time.Minute)
 
-	for i := 0; i < n; i++ {
-		go func() {
-			result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
-				FromAccountID: account1.ID,
-				ToAccountID:   account2.ID,
-				Amount:        amount,
-				Currency:      helpers.RandomCurrency(),
-				Description:   helpers.RandomString(20),
-				Fee:           float64(helpers.RandomFee()),
-			})
+	transactions, err := testRepo.R.ListTransactionsBetween(context.Background(), account1.ID, from, to)
+	require.NoError(t, err)
+	require.Len(t, transactions, 6)
 
-			errs <- err
-			results <- result
-		}()
+	for _, transaction := range transactions {
+		require.True(t, transaction.FromAccountID == account1.ID || transaction.ToAccountID == account1.ID)
 	}
+}
 
-	// check results
-	existed := make(map[int]bool)
-	for i := 0; i < n; i++ {
-		err := <-errs
-		require.NoError(t, err)
-
-		result := <-results
-		require.NotEmpty(t, result)
+func createTransactionWithDescription(t *testing.T, from, to *models.Account, description string) models.Transaction {
+	transaction, err := testRepo.R.CreateTransaction(context.Background(), models.Transaction{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Currency:      from.Currency,
+		Amount:        helpers.RandomMoney(),
+		Description:   description,
+	})
+	require.NoError(t, err)
+	require.Equal(t, description, transaction.Description)
+
+	return transaction
+}
+
+func TestSearchTransactions(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	stranger1 := createRandomAccount(t)
+	stranger2 := createRandomAccount(t)
+
+	keyword := helpers.RandomString(12) // unique per run so other tests' memos never match
+	best := createTransactionWithDescription(t, &account1, &account2, fmt.Sprintf("%s %s %s refund", keyword, keyword, keyword))
+	good := createTransactionWithDescription(t, &account2, &account1, fmt.Sprintf("%s for dinner with a long memo about nothing", keyword))
+	createTransactionWithDescription(t, &account1, &account2, "groceries")
+	createTransactionWithDescription(t, &stranger1, &stranger2, keyword) // not owned by the caller
+
+	transactions, err := testRepo.R.SearchTransactions(context.Background(), models.SearchTransactionsParams{
+		Owner:  account1.Owner,
+		Query:  keyword,
+		Limit:  10,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	require.Equal(t, best.ID, transactions[0].ID)
+	require.Equal(t, good.ID, transactions[1].ID)
+
+	// pagination
+	transactions, err = testRepo.R.SearchTransactions(context.Background(), models.SearchTransactionsParams{
+		Owner:  account1.Owner,
+		Query:  keyword,
+		Limit:  1,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, good.ID, transactions[0].ID)
+
+	// the other owner can't see account1's transactions
+	transactions, err = testRepo.R.SearchTransactions(context.Background(), models.SearchTransactionsParams{
+		Owner:     stranger2.Owner,
+		AccountID: account1.ID,
+		Query:     keyword,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transactions)
+}
+
+func TestTransactionDescriptionLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	memo := strings.Repeat("é", 140)
+	transaction := createTransactionWithDescription(t, &account1, &account2, memo)
+
+	got, err := testRepo.R.GetTransaction(context.Background(), transaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, memo, got.Description)
+
+	_, err = testRepo.R.CreateTransaction(context.Background(), models.Transaction{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Currency:      account1.Currency,
+		Amount:        10,
+		Description:   memo + "x",
+	})
+	require.Error(t, err)
+}
+
+func TestSearchTransactionsShortQuery(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	memo := createTransactionWithDescription(t, &account1, &account2, "paid 42% of the bill")
+	createTransactionWithDescription(t, &account1, &account2, "nothing to see")
+
+	// too short to be a full-text term, matched as a literal substring instead
+	transactions, err := testRepo.R.SearchTransactions(context.Background(), models.SearchTransactionsParams{
+		Owner:     account1.Owner,
+		AccountID: account1.ID,
+		Query:     "2%",
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, memo.ID, transactions[0].ID)
+}
+
+func createTransactionWithAmount(t *testing.T, account1, account2 *models.Account, amount int64, currency string) models.Transaction {
+	transaction, err := testRepo.R.CreateTransaction(context.Background(), models.Transaction{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Currency:      currency,
+		Amount:        amount,
+		Description:   helpers.RandomString(20),
+	})
+	require.NoError(t, err)
+
+	return transaction
+}
+
+func TestListTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	stranger := createRandomAccount(t)
+
+	// amounts overlap so range bounds and page boundaries fall between equal values
+	var sent, received []models.Transaction
+	for _, amount := range []int64{10, 20, 20, 20, 30} {
+		sent = append(sent, createTransactionWithAmount(t, &account1, &account2, amount, helpers.USD))
+	}
+	for _, amount := range []int64{20, 20, 40} {
+		received = append(received, createTransactionWithAmount(t, &account2, &account1, amount, helpers.EUR))
+	}
+	createTransactionWithAmount(t, &account2, &stranger, 20, helpers.USD)
+
+	// give every transfer of account1 the same time, so only the id keeps the order stable
+	createdAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	_, err := testRepo.R.(*repositoryImpl).pool.Exec(context.Background(),
+		`UPDATE transactions SET created_at = @createdAt WHERE from_account_id = @id OR to_account_id = @id`,
+		pgx.NamedArgs{"createdAt": createdAt, "id": account1.ID})
+	require.NoError(t, err)
+
+	listAll := func(arg models.ListTransfersParams) []int64 {
+		var ids []int64
+		arg.AccountID, arg.Limit = account1.ID, 2
+		for {
+			page, err := testRepo.R.ListTransfers(context.Background(), arg)
+			require.NoError(t, err)
+			for _, transfer := range page {
+				ids = append(ids, transfer.ID)
+			}
+			if len(page) < int(arg.Limit) {
+				return ids
+			}
+
+			last := page[len(page)-1]
+			arg.BeforeCreatedAt, arg.BeforeID = last.CreatedAt, last.ID
+		}
+	}
+
+	idsOf := func(transfers ...models.Transaction) []int64 {
+		ids := make([]int64, 0, len(transfers))
+		for i := len(transfers) - 1; i >= 0; i-- {
+			ids = append(ids, transfers[i].ID)
+		}
+		return ids
+	}
+
+	// every page boundary falls on a tie, none of the rows is skipped or repeated
+	all := append(append([]models.Transaction{}, sent...), received...)
+	require.Equal(t, idsOf(all...), listAll(models.ListTransfersParams{}))
+
+	require.Equal(t, idsOf(sent[1], sent[2], sent[3], received[0], received[1]), listAll(models.ListTransfersParams{
+		MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 20, Valid: true},
+	}))
+
+	require.Equal(t, idsOf(sent[1], sent[2], sent[3]), listAll(models.ListTransfersParams{
+		MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 20, Valid: true},
+		Direction: models.TransferDirectionSent,
+	}))
+
+	require.Equal(t, idsOf(received...), listAll(models.ListTransfersParams{
+		Direction: models.TransferDirectionReceived,
+	}))
+
+	// resuming from a cursor on the tie continues right after it
+	require.Equal(t, idsOf(received[0], received[1]), listAll(models.ListTransfersParams{
+		MinAmount:       sql.NullInt64{Int64: 20, Valid: true},
+		Currency:        helpers.EUR,
+		BeforeCreatedAt: createdAt,
+		BeforeID:        received[2].ID,
+	}))
+
+	require.Empty(t, listAll(models.ListTransfersParams{
+		StartTime: sql.NullTime{Time: createdAt.Add(time.Second), Valid: true},
+	}))
+	require.Equal(t, idsOf(all...), listAll(models.ListTransfersParams{
+		StartTime: sql.NullTime{Time: createdAt, Valid: true},
+		EndTime:   sql.NullTime{Time: createdAt.Add(time.Second), Valid: true},
+	}))
+}
+
+func TestTransferTx(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	fmt.Println(">== before: ", account1.Balance, account2.Balance)
+
+	// run 'n' concurrent transfer transactions
+	n := 5
+	amount := int64(10)
+
+	errs := make(chan error)
+	results := make(chan models.TransferTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      helpers.RandomCurrency(),
+				Description:   helpers.RandomString(20),
+				Fee:           helpers.RandomFee(),
+			})
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	// check results
+	existed := make(map[int]bool)
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.NotEmpty(t, result)
 
 		// check transfer
 		transaction := result.Transaction
@@ -497,54 +1280,1679 @@ func TestTransferTx(t *testing.T) {
 	require.Equal(t, int64(account2.Balance)+int64(n)*int64(amount), int64(updatedAccount2.Balance))
 }
 
-func TestTransferTxDeadLock(t *testing.T) {
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-	fmt.Println(">== before: ", account1.Balance, account2.Balance)
+func TestTransferTxCrossCurrency(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
 
-	// run 'n' concurrent transfer transactions
-	n := 10
-	amount := float64(10)
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      helpers.USD,
+		ToAmount:      915,
+		ToCurrency:    helpers.EUR,
+		ExchangeRate:  0.915,
+	})
+	require.NoError(t, err)
 
-	errs := make(chan error)
+	require.Equal(t, int64(1000), result.Transaction.Amount)
+	require.Equal(t, int64(915), result.Transaction.ToAmount)
+	require.Equal(t, helpers.EUR, result.Transaction.ToCurrency)
+	require.Equal(t, 0.915, result.Transaction.ExchangeRate)
 
-	for i := 0; i < n; i++ {
-		fromAccountID := account1.ID
-		toAccountID := account2.ID
+	require.Equal(t, int64(-1000), result.FromEntry.Amount)
+	require.Equal(t, int64(915), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-1000, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+915, result.ToAccount.Balance)
+}
 
-		if i%2 == 1 {
-			fromAccountID = account2.ID
-			toAccountID = account1.ID
+func TestExecTxRollsBackOnTimeout(t *testing.T) {
+	account := createRandomAccount(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// the update is made, then the transaction outlives its deadline
+	err := testRepo.R.(*repositoryImpl).execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE accounts SET balance = balance + 100 WHERE id = @id`, pgx.NamedArgs{"id": account.ID}); err != nil {
+			return err
 		}
 
-		go func() {
-			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
-				FromAccountID: fromAccountID,
-				ToAccountID:   toAccountID,
-				Amount:        amount,
-				Currency:      helpers.RandomCurrency(),
-				Description:   helpers.RandomString(20),
-				Fee:           float64(helpers.RandomFee()),
-			})
+		_, err := tx.Exec(ctx, `SELECT pg_sleep(2)`)
+		return err
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
 
-			errs <- err
-		}()
+	got, err := testRepo.R.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, got.Balance)
+}
+
+func reversalOf(transfer models.Transaction, reversedBy string) models.ReverseTransferTxParams {
+	return models.ReverseTransferTxParams{
+		Transfer: models.TransferTxParams{
+			FromAccountID: transfer.ToAccountID,
+			ToAccountID:   transfer.FromAccountID,
+			Amount:        transfer.ToAmount,
+			Currency:      transfer.ToCurrency,
+			ReversalOf:    transfer.ID,
+		},
+		ReversedBy: reversedBy,
+		Reason:     "sent to the wrong account",
+	}
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	banker := createRandomUser(t)
+
+	original, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	reversal, err := testRepo.R.ReverseTransferTx(context.Background(), reversalOf(original.Transaction, banker.UserName))
+	require.NoError(t, err)
+	require.Equal(t, original.Transaction.ID, reversal.Transaction.ReversalOf)
+	require.Equal(t, account1.Balance, reversal.ToAccount.Balance)
+	require.Equal(t, account2.Balance, reversal.FromAccount.Balance)
+
+	// both sides show the link
+	transfer, err := testRepo.R.GetTransaction(context.Background(), original.Transaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, reversal.Transaction.ID, transfer.ReversedBy)
+
+	transfer, err = testRepo.R.GetTransaction(context.Background(), reversal.Transaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Transaction.ID, transfer.ReversalOf)
+	require.Zero(t, transfer.ReversedBy)
+
+	_, err = testRepo.R.ReverseTransferTx(context.Background(), reversalOf(original.Transaction, banker.UserName))
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+}
+
+func TestReverseTransferTxNotCovered(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	banker := createRandomUser(t)
+
+	original, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	// the recipient spent the money in the meantime
+	_, err = testRepo.R.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{AccountID: account2.ID, Amount: -original.ToAccount.Balance + 5})
+	require.NoError(t, err)
+
+	_, err = testRepo.R.ReverseTransferTx(context.Background(), reversalOf(original.Transaction, banker.UserName))
+	require.ErrorIs(t, err, ErrReversalNotCovered)
+
+	// nothing was rolled forward
+	transfer, err := testRepo.R.GetTransaction(context.Background(), original.Transaction.ID)
+	require.NoError(t, err)
+	require.Zero(t, transfer.ReversedBy)
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	feeAccount := createFeeAccount(t, account1.Currency)
+
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      account1.Currency,
+		Fee:           150,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(150), result.Transaction.Fee)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-150), result.FeeEntry.Amount)
+	require.Equal(t, account1.Balance-1150, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+1000, result.ToAccount.Balance)
+
+	updatedFeeAccount, err := testRepo.R.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+150, updatedFeeAccount.Balance)
+
+	feeEntries, err := testRepo.R.ListEntries(context.Background(), models.ListEntriesParams{AccountID: feeAccount.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, feeEntries, 1)
+	require.Equal(t, int64(150), feeEntries[0].Amount)
+
+	// a fee account in another currency is refused and nothing moves
+	currency := helpers.RandomCurrency()
+	for currency == account1.Currency {
+		currency = helpers.RandomCurrency()
 	}
+	otherFeeAccount := createFeeAccount(t, currency)
 
-	// check error
-	for i := 0; i < n; i++ {
-		err := <-errs
+	_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      account1.Currency,
+		Fee:           150,
+		FeeAccountID:  otherFeeAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrFeeAccountCurrency)
+
+	unchanged, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, unchanged.Balance)
+}
+
+func TestTransactionCategories(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      account1.Currency,
+		Category:      "groceries",
+		Tags:          []string{"market", "weekly"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "groceries", result.FromEntry.Category)
+	require.Equal(t, []string{"market", "weekly"}, result.FromEntry.Tags)
+	// the receiver files its side of the transfer itself
+	require.Empty(t, result.ToEntry.Category)
+	require.Empty(t, result.ToEntry.Tags)
+
+	_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Currency:      account1.Currency,
+		Category:      "dining",
+		Tags:          []string{"weekly"},
+	})
+	require.NoError(t, err)
+
+	deposit, err := testRepo.R.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{
+		AccountID: account1.ID,
+		Amount:    500,
+		Category:  "income",
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-150+500, deposit.Balance)
+
+	transfers, err := testRepo.R.ListTransfers(context.Background(), models.ListTransfersParams{AccountID: account1.ID, Category: "groceries", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transaction.ID, transfers[0].ID)
+	require.Equal(t, "groceries", transfers[0].Category)
+	require.Equal(t, []string{"market", "weekly"}, transfers[0].Tags)
+
+	transfers, err = testRepo.R.ListTransfers(context.Background(), models.ListTransfersParams{AccountID: account1.ID, Tag: "weekly", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+
+	// the tags are account1's, they don't show up for the receiver
+	transfers, err = testRepo.R.ListTransfers(context.Background(), models.ListTransfersParams{AccountID: account2.ID, Tag: "weekly", Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	entries, err := testRepo.R.ListEntries(context.Background(), models.ListEntriesParams{AccountID: account1.ID, Category: "income", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(500), entries[0].Amount)
+
+	entries, err = testRepo.R.ListEntries(context.Background(), models.ListEntriesParams{AccountID: account1.ID, Tag: "market", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.FromEntry.ID, entries[0].ID)
+
+	// file the receiver's side after the fact, then take the category away again
+	entry, err := testRepo.R.SetEntryCategory(context.Background(), models.SetEntryCategoryParams{
+		EntryID:  result.ToEntry.ID,
+		Category: sql.NullString{String: "income", Valid: true},
+		Tags:     []string{"refund"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "income", entry.Category)
+	require.Equal(t, []string{"refund"}, entry.Tags)
+
+	entry, err = testRepo.R.SetEntryCategory(context.Background(), models.SetEntryCategoryParams{
+		EntryID:  result.ToEntry.ID,
+		Category: sql.NullString{Valid: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, entry.Category)
+	require.Equal(t, []string{"refund"}, entry.Tags) // nil tags keep them
+
+	_, err = testRepo.R.SetEntryCategory(context.Background(), models.SetEntryCategoryParams{
+		EntryID:  result.ToEntry.ID,
+		Category: sql.NullString{String: "not_a_category", Valid: true},
+	})
+	require.ErrorIs(t, err, ErrUnknownCategory)
+
+	_, err = testRepo.R.SetEntryCategory(context.Background(), models.SetEntryCategoryParams{
+		EntryID:  0,
+		Category: sql.NullString{String: "income", Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSummarizeEntries(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+
+	_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        300,
+		Currency:      account1.Currency,
+		Category:      "housing",
+	})
+	require.NoError(t, err)
+
+	_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        40,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	_, err = testRepo.R.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{
+		AccountID: account1.ID,
+		Amount:    500,
+		Category:  "income",
+	})
+	require.NoError(t, err)
+
+	// a period reaching back before the account was opened starts when it was
+	arg := models.SummarizeEntriesParams{
+		AccountID: account1.ID,
+		From:      account1.CreatedAt.AddDate(-1, 0, 0),
+		To:        time.Now().Add(time.Minute),
+	}
+	summary, err := testRepo.R.SummarizeEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.WithinDuration(t, account1.CreatedAt, summary.PeriodStart, time.Second)
+	require.Equal(t, int64(500), summary.Inflow)
+	require.Equal(t, int64(340), summary.Outflow)
+	require.Equal(t, int64(160), summary.NetChange())
+	require.Equal(t, int64(3), summary.Count)
+	require.NotNil(t, summary.Largest)
+	require.Equal(t, int64(500), summary.Largest.Amount)
+	require.Equal(t, []models.CategoryTotal{
+		{Category: "housing", Out: 300, Count: 1},
+		{Category: "income", In: 500, Count: 1},
+		{Out: 40, Count: 1},
+	}, summary.Categories)
+
+	// the receiver sees its side of the transfers only
+	summary, err = testRepo.R.SummarizeEntries(context.Background(), models.SummarizeEntriesParams{AccountID: account2.ID, From: arg.From, To: arg.To})
+	require.NoError(t, err)
+	require.Equal(t, int64(340), summary.Inflow)
+	require.Zero(t, summary.Outflow)
+	require.Equal(t, []models.CategoryTotal{{In: 340, Count: 2}}, summary.Categories)
+
+	// nothing was posted before the account was opened
+	summary, err = testRepo.R.SummarizeEntries(context.Background(), models.SummarizeEntriesParams{AccountID: account1.ID, From: arg.From, To: account1.CreatedAt})
+	require.NoError(t, err)
+	require.Zero(t, summary.Count)
+	require.Nil(t, summary.Largest)
+	require.Empty(t, summary.Categories)
+
+	_, err = testRepo.R.SummarizeEntries(context.Background(), models.SummarizeEntriesParams{AccountID: 0, From: arg.From, To: arg.To})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAccountTypes(t *testing.T) {
+	user := createRandomUser(t)
+
+	checking, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Balance: 1000, Currency: helpers.USD})
+	require.NoError(t, err)
+	require.Equal(t, models.AccountChecking, checking.Type)
+
+	// a savings account may sit next to the checking account in the currency, but only one
+	savings, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Balance: 1000, Currency: helpers.USD, Type: models.AccountSavings})
+	require.NoError(t, err)
+	require.Equal(t, models.AccountSavings, savings.Type)
+
+	_, err = testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Currency: helpers.USD, Type: models.AccountSavings})
+	require.ErrorIs(t, err, ErrAccountExists)
+
+	account, err := testRepo.R.GetAccountByCurrency(context.Background(), user.UserName, helpers.USD, models.AccountSavings)
+	require.NoError(t, err)
+	require.Equal(t, savings.ID, account.ID)
+
+	accounts, err := testRepo.R.ListAccounts(context.Background(), models.ListAccountsParams{Owner: user.UserName, Type: models.AccountSavings, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, savings.ID, accounts[0].ID)
+
+	accounts, err = testRepo.R.ListAccounts(context.Background(), models.ListAccountsParams{Owner: user.UserName, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	_, err = testRepo.R.SetOverdraftLimitTx(context.Background(), models.SetOverdraftLimitParams{AccountID: savings.ID, Limit: 500, ChangedBy: user.UserName})
+	require.ErrorIs(t, err, ErrSavingsOverdraft)
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	count, err := testRepo.R.CountOutgoingTransfers(context.Background(), savings.ID, monthStart)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	for i := 0; i < 2; i++ {
+		_, err = testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+			FromAccountID: savings.ID,
+			ToAccountID:   checking.ID,
+			Amount:        10,
+			Currency:      helpers.USD,
+		})
 		require.NoError(t, err)
 	}
 
-	// check the final updated balance
-	updatedAccount1, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	count, err = testRepo.R.CountOutgoingTransfers(context.Background(), savings.ID, monthStart)
 	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
 
-	updatedAccount2, err := testRepo.R.GetAccount(context.Background(), account2.ID)
+	// what came in doesn't count
+	count, err = testRepo.R.CountOutgoingTransfers(context.Background(), checking.ID, monthStart)
 	require.NoError(t, err)
+	require.Zero(t, count)
+}
 
-	fmt.Println(">== after: ", updatedAccount1.Balance, updatedAccount2.Balance)
-	require.Equal(t, account1.Balance, updatedAccount1.Balance)
-	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+func TestAccrueInterest(t *testing.T) {
+	user := createRandomUser(t)
+	savings, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Balance: 3650000, Currency: helpers.USD, Type: models.AccountSavings})
+	require.NoError(t, err)
+
+	accounts, err := testRepo.R.ListInterestAccounts(context.Background(), models.ListInterestAccountsParams{AfterID: savings.ID - 1, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, savings.ID, accounts[0].ID)
+
+	date := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	accrual := models.InterestAccrual{AccountID: savings.ID, AccrualDate: date, Balance: savings.Balance, Rate: 2.5, Amount: 250}
+
+	recorded, err := testRepo.R.AccrueInterest(context.Background(), accrual)
+	require.NoError(t, err)
+	require.NotZero(t, recorded.ID)
+	require.True(t, date.Equal(recorded.AccrualDate))
+	require.Equal(t, 2.5, recorded.Rate)
+
+	// a second run on the same date accrues nothing
+	_, err = testRepo.R.AccrueInterest(context.Background(), accrual)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	accrual.AccrualDate = date.AddDate(0, 0, 1)
+	_, err = testRepo.R.AccrueInterest(context.Background(), accrual)
+	require.NoError(t, err)
+
+	account, err := testRepo.R.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), account.AccruedInterest)
+	require.Equal(t, savings.Balance, account.Balance)
+
+	// only March is closed, the accrual of April stays accrued
+	beforePost := time.Now()
+	account, entry, err := testRepo.R.PostAccruedInterest(context.Background(), savings.ID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, savings.Balance+250, account.Balance)
+	require.Equal(t, int64(250), account.AccruedInterest)
+	require.Equal(t, int64(250), entry.Amount)
+	require.Equal(t, models.CategoryInterest, entry.Category)
+
+	// the interest entry is taken back out of a balance of before it was paid
+	balance, err := testRepo.R.GetBalanceAt(context.Background(), savings.ID, beforePost)
+	require.NoError(t, err)
+	require.Equal(t, savings.Balance, balance)
+	balance, err = testRepo.R.GetBalanceAt(context.Background(), savings.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	// March was paid
+	_, _, err = testRepo.R.PostAccruedInterest(context.Background(), savings.ID, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	account, entry, err = testRepo.R.PostAccruedInterest(context.Background(), savings.ID, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, savings.Balance+500, account.Balance)
+	require.Zero(t, account.AccruedInterest)
+	require.Equal(t, int64(250), entry.Amount)
+}
+
+func ledgerRows(t *testing.T, accountID int64, from, to time.Time) []models.LedgerRow {
+	var rows []models.LedgerRow
+	err := testRepo.R.ForEachLedgerRow(context.Background(), accountID, from, to, func(row models.LedgerRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	require.NoError(t, err)
+	return rows
+}
+
+func TestForEachLedgerRow(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	feeAccount := createFeeAccount(t, account1.Currency)
+	from := time.Now().Add(-time.Minute)
+
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      account1.Currency,
+		Fee:           150,
+		FeeAccountID:  feeAccount.ID,
+		Description:   "rent",
+	})
+	require.NoError(t, err)
+	deposit := createRandomEntry(t, &account2)
+	to := time.Now().Add(time.Minute)
+
+	count, err := testRepo.R.CountEntriesBetween(context.Background(), account1.ID, from, to)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	sent := ledgerRows(t, account1.ID, from, to)
+	require.Len(t, sent, 2)
+	require.Equal(t, models.LedgerRow{EntryID: result.FromEntry.ID, Date: sent[0].Date, Type: models.LedgerRowTransferOut, Counterparty: account2.ID, Amount: -1000, Memo: "rent"}, sent[0])
+	require.Equal(t, models.LedgerRowFee, sent[1].Type)
+	require.Equal(t, result.FeeEntry.ID, sent[1].EntryID)
+	require.Equal(t, int64(-150), sent[1].Amount)
+
+	received := ledgerRows(t, account2.ID, from, to)
+	require.Len(t, received, 2)
+	require.Equal(t, models.LedgerRowTransferIn, received[0].Type)
+	require.Equal(t, account1.ID, received[0].Counterparty)
+	require.Equal(t, deposit.ID, received[1].EntryID)
+	require.Equal(t, models.LedgerRowDeposit, received[1].Type)
+	require.Zero(t, received[1].Counterparty)
+
+	fee := ledgerRows(t, feeAccount.ID, from, to)
+	require.Len(t, fee, 1)
+	require.Equal(t, models.LedgerRowFee, fee[0].Type)
+
+	require.Empty(t, ledgerRows(t, account1.ID, to, to.Add(time.Minute)))
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	key := models.IdempotencyKey{
+		UserName:    account1.Owner,
+		Key:         helpers.RandomString(16),
+		RequestHash: helpers.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	// concurrent requests with the same key, only one may move the money
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+				Currency:      account1.Currency,
+				Idempotency:   &key,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updatedAccount1, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updatedAccount1.Balance)
+
+	stored, err := testRepo.R.GetIdempotencyKey(context.Background(), key.UserName, key.Key)
+	require.NoError(t, err)
+	require.Equal(t, key.RequestHash, stored.RequestHash)
+
+	var result models.TransferTxResult
+	require.NoError(t, json.Unmarshal(stored.Response, &result))
+	require.Equal(t, account1.ID, result.FromAccount.ID)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	key := models.IdempotencyKey{
+		UserName:    account1.Owner,
+		Key:         helpers.RandomString(16),
+		RequestHash: helpers.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}
+
+	arg := models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Currency:      account1.Currency,
+		Idempotency:   &key,
+	}
+	_, err := testRepo.R.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// expired keys are invisible and can be claimed again
+	_, err = testRepo.R.GetIdempotencyKey(context.Background(), key.UserName, key.Key)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testRepo.R.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	deleted, err := testRepo.R.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+}
+
+func TestFeePolicy(t *testing.T) {
+	feeAccount := createRandomAccount(t)
+	currency := helpers.RandomString(3)
+
+	_, err := testRepo.R.GetFeePolicy(context.Background(), currency)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testRepo.R.UpsertFeePolicy(context.Background(), models.FeePolicy{
+		Currency:     currency,
+		Flat:         1,
+		Percentage:   0.5,
+		FeeAccountID: feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	policy, err := testRepo.R.GetFeePolicy(context.Background(), currency)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), policy.Flat)
+	require.Equal(t, 0.5, policy.Percentage)
+	require.Equal(t, feeAccount.ID, policy.FeeAccountID)
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	base := helpers.RandomString(3)
+	quote := helpers.RandomString(3)
+
+	rate, err := testRepo.R.UpsertExchangeRate(context.Background(), models.ExchangeRate{
+		Base:      base,
+		Quote:     quote,
+		Rate:      1.5,
+		FetchedAt: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1.5, rate.Rate)
+
+	// a newer quote replaces the stored one
+	fetchedAt := time.Now()
+	_, err = testRepo.R.UpsertExchangeRate(context.Background(), models.ExchangeRate{
+		Base:      base,
+		Quote:     quote,
+		Rate:      1.6,
+		FetchedAt: fetchedAt,
+	})
+	require.NoError(t, err)
+
+	rate, err = testRepo.R.GetExchangeRate(context.Background(), base, quote)
+	require.NoError(t, err)
+	require.Equal(t, 1.6, rate.Rate)
+	require.WithinDuration(t, fetchedAt, rate.FetchedAt, time.Second)
+
+	_, err = testRepo.R.GetExchangeRate(context.Background(), quote, base)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAddAndDisableCurrency(t *testing.T) {
+	// no seeded currency starts with X, so the test never disables one accounts are opened in
+	code := "X" + strings.ToUpper(helpers.RandomString(2))
+
+	currency, err := testRepo.R.AddCurrency(context.Background(), models.Currency{Code: code, Name: "Test Dollar", MinorUnitExponent: 2})
+	require.NoError(t, err)
+	require.Equal(t, code, currency.Code)
+	require.True(t, currency.Enabled)
+
+	currency, err = testRepo.R.DisableCurrency(context.Background(), code)
+	require.NoError(t, err)
+	require.False(t, currency.Enabled)
+
+	currencies, err := testRepo.R.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, currencies, currency)
+
+	// adding it again enables it, but its minor unit can't change
+	_, err = testRepo.R.AddCurrency(context.Background(), models.Currency{Code: code, Name: "Test Dollar", MinorUnitExponent: 3})
+	require.ErrorIs(t, err, ErrCurrencyExponentMismatch)
+
+	currency, err = testRepo.R.AddCurrency(context.Background(), models.Currency{Code: code, Name: "New Test Dollar", MinorUnitExponent: 2})
+	require.NoError(t, err)
+	require.True(t, currency.Enabled)
+	require.Equal(t, "New Test Dollar", currency.Name)
+
+	_, err = testRepo.R.DisableCurrency(context.Background(), "ZZZZ")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestDecimalViewsUseCurrencyExponent(t *testing.T) {
+	code := "X" + strings.ToUpper(helpers.RandomString(2))
+	_, err := testRepo.R.AddCurrency(context.Background(), models.Currency{Code: code, Name: "Test Dinar", MinorUnitExponent: 3})
+	require.NoError(t, err)
+
+	account, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: createRandomUser(t).UserName, Balance: 12345, Currency: code})
+	require.NoError(t, err)
+
+	var scaled bool
+	err = testRepo.R.(*repositoryImpl).pool.QueryRow(context.Background(),
+		`SELECT balance = 12.345 FROM accounts_decimal WHERE id = $1`, account.ID).Scan(&scaled)
+	require.NoError(t, err)
+	require.True(t, scaled)
+}
+
+func createRandomScheduledTransfer(t *testing.T) models.ScheduledTransfer {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+
+	transfer, err := testRepo.R.CreateScheduledTransferTx(context.Background(), models.CreateScheduledTransferTxParams{
+		Transfer: models.ScheduledTransfer{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        10,
+			Currency:      from.Currency,
+			ExecuteAt:     time.Now().Add(time.Hour),
+			CreatedBy:     from.Owner,
+		},
+		AfterCreate: func(transfer models.ScheduledTransfer) error {
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ScheduledTransferPending, transfer.Status)
+	require.Zero(t, transfer.TransactionID)
+
+	return transfer
+}
+
+func TestCreateScheduledTransferTxRollback(t *testing.T) {
+	from := createRandomAccount(t)
+	enqueueErr := errors.New("enqueue failed")
+
+	transfer, err := testRepo.R.CreateScheduledTransferTx(context.Background(), models.CreateScheduledTransferTxParams{
+		Transfer: models.ScheduledTransfer{
+			FromAccountID: from.ID,
+			ToAccountID:   from.ID,
+			Amount:        10,
+			Currency:      from.Currency,
+			ExecuteAt:     time.Now().Add(time.Hour),
+			CreatedBy:     from.Owner,
+		},
+		AfterCreate: func(transfer models.ScheduledTransfer) error {
+			return enqueueErr
+		},
+	})
+	require.ErrorIs(t, err, enqueueErr)
+
+	_, err = testRepo.R.GetScheduledTransfer(context.Background(), transfer.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	transfer := createRandomScheduledTransfer(t)
+
+	canceled, err := testRepo.R.CancelScheduledTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.ScheduledTransferCanceled, canceled.Status)
+
+	// a canceled transfer can't be claimed for execution
+	_, err = testRepo.R.ClaimScheduledTransfer(context.Background(), transfer.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCancelRacesClaimScheduledTransfer(t *testing.T) {
+	transfer := createRandomScheduledTransfer(t)
+
+	cancelErrs := make(chan error)
+	claimErrs := make(chan error)
+
+	go func() {
+		_, err := testRepo.R.CancelScheduledTransfer(context.Background(), transfer.ID)
+		cancelErrs <- err
+	}()
+	go func() {
+		_, err := testRepo.R.ClaimScheduledTransfer(context.Background(), transfer.ID)
+		claimErrs <- err
+	}()
+
+	cancelErr, claimErr := <-cancelErrs, <-claimErrs
+
+	// exactly one side wins
+	require.True(t, (cancelErr == nil) != (claimErr == nil))
+
+	stored, err := testRepo.R.GetScheduledTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	if cancelErr == nil {
+		require.Equal(t, models.ScheduledTransferCanceled, stored.Status)
+		return
+	}
+
+	require.ErrorIs(t, cancelErr, pgx.ErrNoRows)
+	require.Equal(t, models.ScheduledTransferExecuting, stored.Status)
+}
+
+func TestFinishScheduledTransfer(t *testing.T) {
+	transfer := createRandomScheduledTransfer(t)
+
+	_, err := testRepo.R.ClaimScheduledTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+
+	finished, err := testRepo.R.FinishScheduledTransfer(context.Background(), models.FinishScheduledTransferParams{
+		ID:            transfer.ID,
+		Status:        models.ScheduledTransferFailed,
+		FailureReason: "insufficient funds",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ScheduledTransferFailed, finished.Status)
+	require.Equal(t, "insufficient funds", finished.FailureReason)
+
+	// once finished, neither execution nor cancellation can touch it again
+	_, err = testRepo.R.ClaimScheduledTransfer(context.Background(), transfer.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testRepo.R.CancelScheduledTransfer(context.Background(), transfer.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func createRandomStandingOrder(t *testing.T) models.StandingOrder {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+
+	order, err := testRepo.R.CreateStandingOrder(context.Background(), models.StandingOrder{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		Frequency:     "daily",
+		StartDate:     start,
+		NextRunAt:     start,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.StandingOrderActive, order.Status)
+	require.Nil(t, order.EndDate)
+	require.WithinDuration(t, start, order.NextRunAt, time.Second)
+
+	return order
+}
+
+func recordStandingOrderRun(order models.StandingOrder, status string) (models.StandingOrder, error) {
+	return testRepo.R.RecordStandingOrderRunTx(context.Background(), models.RecordStandingOrderRunParams{
+		Execution: models.StandingOrderExecution{
+			StandingOrderID: order.ID,
+			RunAt:           order.NextRunAt,
+			Status:          status,
+		},
+		NextRunAt:   order.NextRunAt.AddDate(0, 0, 1),
+		MaxFailures: 2,
+	})
+}
+
+func TestListDueStandingOrders(t *testing.T) {
+	order := createRandomStandingOrder(t)
+
+	orders, err := testRepo.R.ListDueStandingOrders(context.Background(), order.NextRunAt, 1000)
+	require.NoError(t, err)
+	require.Contains(t, standingOrderIDs(orders), order.ID)
+
+	_, err = testRepo.R.PauseStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+
+	orders, err = testRepo.R.ListDueStandingOrders(context.Background(), order.NextRunAt, 1000)
+	require.NoError(t, err)
+	require.NotContains(t, standingOrderIDs(orders), order.ID)
+}
+
+func standingOrderIDs(orders []models.StandingOrder) []int64 {
+	ids := make([]int64, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func TestRecordStandingOrderRunTx(t *testing.T) {
+	order := createRandomStandingOrder(t)
+
+	updated, err := recordStandingOrderRun(order, models.StandingOrderRunFailed)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), updated.ConsecutiveFailures)
+	require.Equal(t, models.StandingOrderActive, updated.Status)
+
+	// the same run can only be recorded once
+	_, err = recordStandingOrderRun(order, models.StandingOrderRunFailed)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// a success clears the failures
+	updated, err = recordStandingOrderRun(updated, models.StandingOrderRunExecuted)
+	require.NoError(t, err)
+	require.Zero(t, updated.ConsecutiveFailures)
+
+	// two failures in a row suspend the order
+	updated, err = recordStandingOrderRun(updated, models.StandingOrderRunFailed)
+	require.NoError(t, err)
+	updated, err = recordStandingOrderRun(updated, models.StandingOrderRunFailed)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), updated.ConsecutiveFailures)
+	require.Equal(t, models.StandingOrderSuspended, updated.Status)
+
+	executions, err := testRepo.R.ListStandingOrderExecutions(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Len(t, executions, 4)
+	require.Equal(t, models.StandingOrderRunExecuted, executions[1].Status)
+
+	// pausing only applies to active orders, resuming clears the failures
+	_, err = testRepo.R.PauseStandingOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	resumed, err := testRepo.R.ResumeStandingOrder(context.Background(), order.ID, updated.NextRunAt)
+	require.NoError(t, err)
+	require.Equal(t, models.StandingOrderActive, resumed.Status)
+	require.Zero(t, resumed.ConsecutiveFailures)
+
+	_, err = testRepo.R.ResumeStandingOrder(context.Background(), order.ID, updated.NextRunAt)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestDeleteStandingOrder(t *testing.T) {
+	order := createRandomStandingOrder(t)
+
+	_, err := recordStandingOrderRun(order, models.StandingOrderRunExecuted)
+	require.NoError(t, err)
+
+	require.NoError(t, testRepo.R.DeleteStandingOrder(context.Background(), order.ID))
+
+	_, err = testRepo.R.GetStandingOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.ErrorIs(t, testRepo.R.DeleteStandingOrder(context.Background(), order.ID), pgx.ErrNoRows)
+}
+
+func TestBeneficiaries(t *testing.T) {
+	user := createRandomUser(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	beneficiary, err := testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     user.UserName,
+		AccountID: account1.ID,
+		Nickname:  "landlord",
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.UserName, beneficiary.Owner)
+	require.Equal(t, account1.ID, beneficiary.AccountID)
+
+	// nicknames are unique per user only
+	_, err = testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     user.UserName,
+		AccountID: account2.ID,
+		Nickname:  "landlord",
+	})
+	require.ErrorIs(t, err, ErrDuplicateNickname)
+
+	_, err = testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     account2.Owner,
+		AccountID: account1.ID,
+		Nickname:  "landlord",
+	})
+	require.NoError(t, err)
+
+	beneficiaries, err := testRepo.R.ListBeneficiaries(context.Background(), models.ListBeneficiariesParams{
+		Owner: user.UserName,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, beneficiaries, 1)
+	require.Equal(t, beneficiary, beneficiaries[0])
+}
+
+func TestDeleteBeneficiaryKeepsTransfers(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+
+	beneficiary, err := testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     account1.Owner,
+		AccountID: account2.ID,
+		Nickname:  "savings",
+	})
+	require.NoError(t, err)
+
+	result, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   beneficiary.AccountID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, testRepo.R.DeleteBeneficiary(context.Background(), beneficiary.ID))
+	require.ErrorIs(t, testRepo.R.DeleteBeneficiary(context.Background(), beneficiary.ID), pgx.ErrNoRows)
+
+	transfer, err := testRepo.R.GetTransaction(context.Background(), result.Transaction.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, transfer.ToAccountID)
+}
+
+func TestGetBeneficiaryByIBAN(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	beneficiary, err := testRepo.R.CreateBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     account1.Owner,
+		AccountID: account2.ID,
+		Nickname:  "supplier",
+		IBAN:      "DE89370400440532013000",
+		BIC:       "DEUTDEFF",
+	})
+	require.NoError(t, err)
+
+	found, err := testRepo.R.GetBeneficiaryByIBAN(context.Background(), account1.Owner, "DE89370400440532013000")
+	require.NoError(t, err)
+	require.Equal(t, beneficiary, found)
+
+	// another user's payees are never matched
+	_, err = testRepo.R.GetBeneficiaryByIBAN(context.Background(), account2.Owner, "DE89370400440532013000")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestTransferTxDeadLock(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	fmt.Println(">== before: ", account1.Balance, account2.Balance)
+
+	// run 'n' concurrent transfer transactions
+	n := 10
+	amount := int64(10)
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromAccountID := account1.ID
+		toAccountID := account2.ID
+
+		if i%2 == 1 {
+			fromAccountID = account2.ID
+			toAccountID = account1.ID
+		}
+
+		go func() {
+			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        amount,
+				Currency:      helpers.RandomCurrency(),
+				Description:   helpers.RandomString(20),
+				Fee:           helpers.RandomFee(),
+			})
+
+			errs <- err
+		}()
+	}
+
+	// check error
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	// check the final updated balance
+	updatedAccount1, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	updatedAccount2, err := testRepo.R.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	fmt.Println(">== after: ", updatedAccount1.Balance, updatedAccount2.Balance)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxConcurrentBothDirections(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
+	since := time.Now().Add(-time.Second)
+
+	// 20 transfers of different amounts, half each way
+	n := 20
+	errs := make(chan error)
+	var net int64 // what account1 ends up sending account2
+
+	for i := 0; i < n; i++ {
+		fromAccountID, toAccountID := account1.ID, account2.ID
+		amount := int64(i + 1)
+		if i%2 == 1 {
+			fromAccountID, toAccountID = account2.ID, account1.ID
+			net -= amount
+		} else {
+			net += amount
+		}
+
+		go func() {
+			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        amount,
+				Currency:      account1.Currency,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	updatedAccount1, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updatedAccount2, err := testRepo.R.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-net, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance+net, updatedAccount2.Balance)
+
+	// the entries add up to the same change
+	sum1, err := testRepo.R.SumEntriesSince(context.Background(), account1.ID, since)
+	require.NoError(t, err)
+	sum2, err := testRepo.R.SumEntriesSince(context.Background(), account2.ID, since)
+	require.NoError(t, err)
+	require.Equal(t, -net, sum1)
+	require.Equal(t, net, sum2)
+}
+
+func TestTransferTxChecksFundsOfLockedRow(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// room for exactly three of the transfers
+	account1, err := testRepo.R.UpdateAccount(context.Background(), account1.ID, 100)
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        30,
+				Currency:      account1.Currency,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+	require.Equal(t, 3, succeeded)
+
+	updatedAccount1, err := testRepo.R.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), updatedAccount1.Balance)
+}
+
+func TestTransferTxSavingsLimitConcurrent(t *testing.T) {
+	user := createRandomUser(t)
+	account2 := createRandomAccount(t)
+	savings, err := testRepo.R.CreateAccount(context.Background(), models.Account{Owner: user.UserName, Balance: 1000, Currency: account2.Currency, Type: models.AccountSavings})
+	require.NoError(t, err)
+
+	// enough money for all of them, only the limit stops the rest
+	limit, n := 3, 8
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testRepo.R.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID:        savings.ID,
+				ToAccountID:          account2.ID,
+				Amount:               10,
+				Currency:             savings.Currency,
+				SavingsTransferLimit: limit,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitReached)
+	}
+	require.Equal(t, limit, succeeded)
+
+	updated, err := testRepo.R.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000-10*limit), updated.Balance)
+}
+
+func TestRecordWebhookDeliveryDisablesAfterFailures(t *testing.T) {
+	user := createRandomUser(t)
+
+	webhook, err := testRepo.R.CreateWebhook(context.Background(), models.Webhook{
+		Owner:  user.UserName,
+		URL:    "https://example.com/hooks",
+		Events: []string{models.WebhookEventTransferCompleted},
+		Secret: helpers.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.True(t, webhook.Active)
+
+	subscribed, err := testRepo.R.ListSubscribedWebhooks(context.Background(), []string{user.UserName}, models.WebhookEventTransferCompleted)
+	require.NoError(t, err)
+	require.Len(t, subscribed, 1)
+
+	subscribed, err = testRepo.R.ListSubscribedWebhooks(context.Background(), []string{user.UserName}, models.WebhookEventDepositCompleted)
+	require.NoError(t, err)
+	require.Empty(t, subscribed)
+
+	// a success in between starts the run of failures over
+	webhook, err = testRepo.R.RecordWebhookDelivery(context.Background(), webhook.ID, false, 2)
+	require.NoError(t, err)
+	require.True(t, webhook.Active)
+	webhook, err = testRepo.R.RecordWebhookDelivery(context.Background(), webhook.ID, true, 2)
+	require.NoError(t, err)
+	require.Zero(t, webhook.ConsecutiveFailures)
+
+	for i := 0; i < 2; i++ {
+		webhook, err = testRepo.R.RecordWebhookDelivery(context.Background(), webhook.ID, false, 2)
+		require.NoError(t, err)
+	}
+	require.False(t, webhook.Active)
+	require.EqualValues(t, 2, webhook.ConsecutiveFailures)
+
+	subscribed, err = testRepo.R.ListSubscribedWebhooks(context.Background(), []string{user.UserName}, models.WebhookEventTransferCompleted)
+	require.NoError(t, err)
+	require.Empty(t, subscribed)
+}
+
+func TestCreateUserTxEnablesNotifications(t *testing.T) {
+	hashedPassword, err := helpers.HashPassword(helpers.RandomString(6))
+	require.NoError(t, err)
+
+	user, err := testRepo.R.CreateUserTx(context.Background(), models.CreateUserTxParams{
+		User: models.User{
+			UserName:       helpers.RandomOwner(),
+			Email:          helpers.RandomEmail(),
+			FullName:       helpers.RandomOwner(),
+			HashedPassword: hashedPassword,
+		},
+		SecretCode: helpers.RandomString(32),
+		AfterCreate: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+
+	preferences, err := testRepo.R.GetNotificationPreferences(context.Background(), user.UserName)
+	require.NoError(t, err)
+	require.True(t, preferences.EmailTransfers)
+	require.True(t, preferences.EmailLogins)
+	require.True(t, preferences.EmailStatements)
+
+	preferences, err = testRepo.R.UpdateNotificationPreferences(context.Background(), models.UpdateNotificationPreferencesParams{
+		Username:        user.UserName,
+		EmailStatements: sql.NullBool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, preferences.EmailTransfers)
+	require.False(t, preferences.EmailStatements)
+}
+
+func TestUpdateNotificationPreferencesWithoutRow(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testRepo.R.GetNotificationPreferences(context.Background(), user.UserName)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	preferences, err := testRepo.R.UpdateNotificationPreferences(context.Background(), models.UpdateNotificationPreferencesParams{
+		Username:       user.UserName,
+		EmailTransfers: sql.NullBool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, preferences.EmailTransfers)
+	require.True(t, preferences.EmailLogins)
+}
+
+func TestNotificationsInbox(t *testing.T) {
+	user := createRandomUser(t)
+
+	var created []models.Notification
+	for i := 0; i < 3; i++ {
+		notification, err := testRepo.R.CreateNotification(context.Background(), models.Notification{
+			Username: user.UserName,
+			Kind:     models.NotificationKindIncomingTransfer,
+			Title:    "You received money",
+			Body:     fmt.Sprintf("transfer %d", i),
+		})
+		require.NoError(t, err)
+		require.Nil(t, notification.ReadAt)
+		created = append(created, notification)
+	}
+
+	count, err := testRepo.R.CountUnreadNotifications(context.Background(), user.UserName)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+
+	// newest first, continuing after the last row of the previous page
+	page, err := testRepo.R.ListNotifications(context.Background(), models.ListNotificationsParams{
+		Username: user.UserName,
+		Limit:    2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, created[2].ID, page[0].ID)
+	require.Equal(t, created[1].ID, page[1].ID)
+
+	page, err = testRepo.R.ListNotifications(context.Background(), models.ListNotificationsParams{
+		Username:        user.UserName,
+		BeforeCreatedAt: page[1].CreatedAt,
+		BeforeID:        page[1].ID,
+		Limit:           2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, created[0].ID, page[0].ID)
+
+	read, err := testRepo.R.MarkNotificationRead(context.Background(), created[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, read.ReadAt)
+
+	// marking it again keeps the first read time
+	again, err := testRepo.R.MarkNotificationRead(context.Background(), created[0].ID)
+	require.NoError(t, err)
+	require.True(t, read.ReadAt.Equal(*again.ReadAt))
+
+	count, err = testRepo.R.CountUnreadNotifications(context.Background(), user.UserName)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+}
+
+func TestCreateFailedNotification(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := models.FailedNotification{
+		Username:  user.UserName,
+		Kind:      models.NotificationKindVerifyEmail,
+		Channel:   "email",
+		Recipient: user.Email,
+		Subject:   "Welcome to Swift Bank",
+		Error:     "550 No such user here",
+	}
+
+	failed, err := testRepo.R.CreateFailedNotification(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, failed.ID)
+	require.NotZero(t, failed.CreatedAt)
+
+	arg.ID = failed.ID
+	arg.CreatedAt = failed.CreatedAt
+	require.Equal(t, arg, failed)
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	user := createRandomUser(t)
+
+	newSession := func(expiresAt time.Time) models.Session {
+		session, err := testRepo.R.CreateSession(context.Background(), models.Session{
+			ID:           uuid.New(),
+			UserName:     user.UserName,
+			RefreshToken: helpers.RandomString(32),
+			UserAgent:    "test-agent",
+			ClientIp:     "127.0.0.1",
+			ExpiresAt:    expiresAt,
+		})
+		require.NoError(t, err)
+		return session
+	}
+
+	expired := newSession(time.Now().Add(-48 * time.Hour))
+	recent := newSession(time.Now().Add(-time.Hour))
+	active := newSession(time.Now().Add(time.Hour))
+
+	deleted, err := testRepo.R.DeleteExpiredSessions(context.Background(), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testRepo.R.GetSession(context.Background(), expired.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	for _, session := range []models.Session{recent, active} {
+		_, err = testRepo.R.GetSession(context.Background(), session.ID)
+		require.NoError(t, err)
+	}
+}
+
+func TestDeleteStaleVerifyEmails(t *testing.T) {
+	user := createRandomUser(t)
+
+	verifyEmail, err := testRepo.R.CreateVerifyEmail(context.Background(), models.VerifyEmails{
+		Username:   user.UserName,
+		Email:      user.Email,
+		SecretCode: helpers.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	// a fresh code is still usable and must survive the purge
+	_, err = testRepo.R.DeleteStaleVerifyEmails(context.Background())
+	require.NoError(t, err)
+
+	_, err = testRepo.R.VerifyEmailTx(context.Background(), models.VerifyEmailTxParams{
+		EmailId:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.NoError(t, err)
+
+	deleted, err := testRepo.R.DeleteStaleVerifyEmails(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+}
+
+func createUserWithOutboxMessage(t *testing.T, taskType string) (models.User, models.VerifyEmails) {
+	hashedPassword, err := helpers.HashPassword(helpers.RandomString(6))
+	require.NoError(t, err)
+
+	var verifyEmail models.VerifyEmails
+	user, err := testRepo.R.CreateUserTx(context.Background(), models.CreateUserTxParams{
+		User: models.User{
+			UserName:       helpers.RandomOwner(),
+			Email:          helpers.RandomEmail(),
+			FullName:       helpers.RandomOwner(),
+			HashedPassword: hashedPassword,
+		},
+		SecretCode: helpers.RandomString(32),
+		AfterCreate: func(user models.User, created models.VerifyEmails) ([]models.OutboxMessage, error) {
+			verifyEmail = created
+			payload := fmt.Sprintf(`{"username":%q,"verify_email_id":%d}`, user.UserName, created.ID)
+			return []models.OutboxMessage{{TaskType: taskType, Payload: []byte(payload), Queue: "critical", MaxRetry: 10}}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	return user, verifyEmail
+}
+
+func TestCreateUserTxStoresVerifyEmail(t *testing.T) {
+	user, verifyEmail := createUserWithOutboxMessage(t, helpers.RandomString(12))
+
+	stored, err := testRepo.R.GetVerifyEmail(context.Background(), verifyEmail.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.UserName, stored.Username)
+	require.Equal(t, user.Email, stored.Email)
+	require.False(t, stored.IsUsed)
+	require.Equal(t, verifyEmail.SecretCode, stored.SecretCode)
+}
+
+func TestCreateUserTxRollsBackOnAfterCreateError(t *testing.T) {
+	username := helpers.RandomOwner()
+	_, err := testRepo.R.CreateUserTx(context.Background(), models.CreateUserTxParams{
+		User: models.User{
+			UserName:       username,
+			Email:          helpers.RandomEmail(),
+			FullName:       helpers.RandomOwner(),
+			HashedPassword: helpers.RandomString(60),
+		},
+		SecretCode: helpers.RandomString(32),
+		AfterCreate: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+			return nil, errors.New("cannot build message")
+		},
+	})
+	require.Error(t, err)
+
+	_, err = testRepo.R.GetUser(context.Background(), username)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCreateUserTxTaken(t *testing.T) {
+	existing := createRandomUser(t)
+
+	testCases := []struct {
+		name  string
+		user  models.User
+		field string
+	}{
+		{
+			name:  "Username",
+			user:  models.User{UserName: strings.ToUpper(existing.UserName), Email: helpers.RandomEmail()},
+			field: "username",
+		},
+		{
+			name:  "Email",
+			user:  models.User{UserName: helpers.RandomOwner(), Email: strings.ToUpper(existing.Email)},
+			field: "email",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.user.FullName, tc.user.HashedPassword = helpers.RandomOwner(), helpers.RandomString(60)
+			_, err := testRepo.R.CreateUserTx(context.Background(), models.CreateUserTxParams{
+				User:       tc.user,
+				SecretCode: helpers.RandomString(32),
+				AfterCreate: func(user models.User, verifyEmail models.VerifyEmails) ([]models.OutboxMessage, error) {
+					return nil, nil
+				},
+			})
+			field, ok := TakenUserField(err)
+			require.True(t, ok, err)
+			require.Equal(t, tc.field, field)
+		})
+	}
+}
+
+func TestRelayOutboxTx(t *testing.T) {
+	// the task type tells this test's messages apart from the ones other tests leave behind
+	taskType := helpers.RandomString(12)
+	createUserWithOutboxMessage(t, taskType)
+	createUserWithOutboxMessage(t, taskType)
+
+	relayed := func(fail bool) (int, []models.OutboxMessage, error) {
+		var messages []models.OutboxMessage
+		n, err := testRepo.R.RelayOutboxTx(context.Background(), 1000, func(message models.OutboxMessage) error {
+			if message.TaskType != taskType {
+				return nil
+			}
+			if fail {
+				return errors.New("redis is down")
+			}
+			messages = append(messages, message)
+			return nil
+		})
+		return n, messages, err
+	}
+
+	// a failed send leaves the message unsent
+	_, messages, err := relayed(true)
+	require.Error(t, err)
+	require.Empty(t, messages)
+
+	_, messages, err = relayed(false)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Less(t, messages[0].ID, messages[1].ID)
+	require.Equal(t, "critical", messages[0].Queue)
+	require.EqualValues(t, 10, messages[0].MaxRetry)
+	require.False(t, messages[0].SentAt.Valid)
+
+	// sent messages are not relayed again
+	_, messages, err = relayed(false)
+	require.NoError(t, err)
+	require.Empty(t, messages)
+}
+
+func requestStatement(t *testing.T, account models.Account, month int) (models.StatementDocument, int) {
+	queued := 0
+	document, err := testRepo.R.RequestStatementTx(context.Background(), models.RequestStatementTxParams{
+		AccountID:   account.ID,
+		Year:        2024,
+		Month:       month,
+		RequestedBy: account.Owner,
+		AfterRequest: func(document models.StatementDocument) ([]models.OutboxMessage, error) {
+			queued++
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	return document, queued
+}
+
+func TestRequestStatementTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	document, queued := requestStatement(t, account, 3)
+	require.Equal(t, 1, queued)
+	require.Equal(t, models.StatementDocumentPending, document.Status)
+	require.Equal(t, account.Owner, document.RequestedBy)
+
+	// a pending statement is not queued twice
+	again, queued := requestStatement(t, account, 3)
+	require.Zero(t, queued)
+	require.Equal(t, document.ID, again.ID)
+
+	failed, err := testRepo.R.FinishStatementDocument(context.Background(), models.FinishStatementDocumentParams{
+		ID:            document.ID,
+		Status:        models.StatementDocumentFailed,
+		FailureReason: "could not render",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "could not render", failed.FailureReason)
+
+	// only pending statements can be finished
+	_, err = testRepo.R.FinishStatementDocument(context.Background(), models.FinishStatementDocumentParams{ID: document.ID, Status: models.StatementDocumentReady})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// a failed statement is rendered again
+	retried, queued := requestStatement(t, account, 3)
+	require.Equal(t, 1, queued)
+	require.Equal(t, document.ID, retried.ID)
+	require.Equal(t, models.StatementDocumentPending, retried.Status)
+	require.Empty(t, retried.FailureReason)
+
+	ready, err := testRepo.R.FinishStatementDocument(context.Background(), models.FinishStatementDocumentParams{
+		ID:        document.ID,
+		Status:    models.StatementDocumentReady,
+		ObjectKey: "statements/key.pdf",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "statements/key.pdf", ready.ObjectKey)
+
+	stored, err := testRepo.R.GetStatementDocument(context.Background(), document.ID)
+	require.NoError(t, err)
+	require.Equal(t, ready, stored)
+}
+
+func TestRequestStatementTxRollsBackOnAfterRequestError(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testRepo.R.RequestStatementTx(context.Background(), models.RequestStatementTxParams{
+		AccountID:   account.ID,
+		Year:        2024,
+		Month:       3,
+		RequestedBy: account.Owner,
+		AfterRequest: func(document models.StatementDocument) ([]models.OutboxMessage, error) {
+			return nil, errors.New("cannot build message")
+		},
+	})
+	require.Error(t, err)
+
+	documents, err := testRepo.R.ListStatementDocuments(context.Background(), models.ListStatementDocumentsParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, documents)
+}
+
+func TestListStatementDocuments(t *testing.T) {
+	account := createRandomAccount(t)
+	for _, month := range []int{2, 11, 5} {
+		requestStatement(t, account, month)
+	}
+
+	documents, err := testRepo.R.ListStatementDocuments(context.Background(), models.ListStatementDocumentsParams{AccountID: account.ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+	require.Equal(t, 11, documents[0].Month)
+	require.Equal(t, 5, documents[1].Month)
+
+	documents, err = testRepo.R.ListStatementDocuments(context.Background(), models.ListStatementDocumentsParams{AccountID: account.ID, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Equal(t, 2, documents[0].Month)
+}
+
+func TestAuditLogs(t *testing.T) {
+	entityID := helpers.RandomString(12) // no other test records this entity
+	for i, action := range []string{"user.update", "user.change_password"} {
+		arg := models.CreateAuditLogParams{
+			Actor:     "ada",
+			Action:    action,
+			Entity:    "user",
+			EntityID:  entityID,
+			RequestID: helpers.RandomString(8),
+		}
+		if i == 0 {
+			arg.Before = json.RawMessage(`{"fullName":"Ada Lovelace"}`)
+			arg.After = json.RawMessage(`{"fullName":"Ada King"}`)
+		}
+
+		log, err := testRepo.R.CreateAuditLog(context.Background(), arg)
+		require.NoError(t, err)
+		require.NotZero(t, log.ID)
+		require.Equal(t, arg.RequestID, log.RequestID)
+		require.WithinDuration(t, time.Now(), log.CreatedAt, time.Second)
+	}
+
+	logs, err := testRepo.R.ListAuditLogs(context.Background(), models.ListAuditLogsParams{Entity: "user", EntityID: entityID, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, "user.change_password", logs[0].Action)
+	require.Nil(t, logs[0].Before)
+	require.Equal(t, "user.update", logs[1].Action)
+	require.JSONEq(t, `{"fullName":"Ada King"}`, string(logs[1].After))
+
+	// the log is append only
+	pool := testRepo.R.(*repositoryImpl).pool
+	_, err = pool.Exec(context.Background(), `UPDATE audit_logs SET actor = 'mallory' WHERE id = $1`, logs[0].ID)
+	require.ErrorContains(t, err, "append only")
+	_, err = pool.Exec(context.Background(), `DELETE FROM audit_logs WHERE id = $1`, logs[0].ID)
+	require.ErrorContains(t, err, "append only")
+}
+
+func TestGetMigrationStatus(t *testing.T) {
+	status, err := testRepo.R.GetMigrationStatus(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, status.Version, int64(33))
+	require.False(t, status.Dirty)
+	require.False(t, status.AppliedAt.IsZero())
 }
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+// SlowQueryTracer logs the statements that take longer than its threshold at warn level, with
+// the request that ran them. Only the SQL is logged, never the arguments, which carry the data
+// of the users. A zero threshold logs nothing.
+type SlowQueryTracer struct {
+	threshold time.Duration
+}
+
+func NewSlowQueryTracer(threshold time.Duration) *SlowQueryTracer {
+	return &SlowQueryTracer{threshold: threshold}
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql  string
+	time time.Time
+}
+
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if t.threshold <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, time: time.Now()})
+}
+
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(start.time)
+	if duration <= t.threshold {
+		return
+	}
+
+	logger := log.Warn().
+		Str("sql", compactSQL(start.sql)).
+		Int64("rows", data.CommandTag.RowsAffected()).
+		Dur("duration", duration)
+	if data.Err != nil {
+		logger = logger.Err(data.Err)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		logger = logger.Str("request_id", id)
+	}
+	logger.Msg("slow query")
+}
+
+// QueryLogger logs every statement the pool runs, transactions included: the ones that fail at
+// error level, the others at debug level. Events below its level are dropped, so production can
+// keep it at error. Like SlowQueryTracer it never logs the arguments.
+type QueryLogger struct {
+	level      zerolog.Level
+	statements sync.Map // names of the prepared statements, run by name instead of SQL
+}
+
+func NewQueryLogger(level zerolog.Level) *QueryLogger {
+	return &QueryLogger{level: level}
+}
+
+type queryLogStartKey struct{}
+
+func (l *QueryLogger) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if l.level > zerolog.ErrorLevel {
+		return ctx
+	}
+	return context.WithValue(ctx, queryLogStartKey{}, queryStart{sql: data.SQL, time: time.Now()})
+}
+
+func (l *QueryLogger) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryLogStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	level := zerolog.DebugLevel
+	if data.Err != nil {
+		level = zerolog.ErrorLevel
+	}
+	if level < l.level {
+		return
+	}
+
+	logger := log.WithLevel(level).
+		Str("operation", l.operation(start.sql)).
+		Str("sql", compactSQL(start.sql)).
+		Int64("rows", data.CommandTag.RowsAffected()).
+		Dur("duration", time.Since(start.time))
+	if data.Err != nil {
+		logger = logger.Err(data.Err)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		logger = logger.Str("request_id", id)
+	}
+	logger.Msg("database query")
+}
+
+// TracePrepareStart remembers the names of prepared statements, they label the queries run by name
+func (l *QueryLogger) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	if data.Name != "" {
+		l.statements.Store(data.Name, struct{}{})
+	}
+	return ctx
+}
+
+func (l *QueryLogger) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+}
+
+// operation labels a query with the name of its prepared statement, or else its first keyword,
+// like select or begin
+func (l *QueryLogger) operation(sql string) string {
+	if _, ok := l.statements.Load(sql); ok {
+		return sql
+	}
+
+	words := strings.Fields(sql)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0])
+}
+
+// compactSQL puts a query on one line, the queries of repo_impl.go span many
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
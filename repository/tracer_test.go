@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/database"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var logs bytes.Buffer
+	defaultLogger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	t.Cleanup(func() { log.Logger = defaultLogger })
+
+	return &logs
+}
+
+// newTracedPool connects a pool of its own, testRepo's has no tracers but otelpgx
+func newTracedPool(t *testing.T, tracers ...pgx.QueryTracer) *pgxpool.Pool {
+	db, err := database.NewPostgresClient(context.Background(), testDSN, database.PoolConfig{}, tracers...)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	return db.Pool()
+}
+
+func TestSlowQueryTracer(t *testing.T) {
+	logs := captureLogs(t)
+	pool := newTracedPool(t, NewSlowQueryTracer(50*time.Millisecond))
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	_, err := pool.Exec(ctx, "SELECT 1 WHERE @secret::text <> ''", pgx.NamedArgs{"secret": "hunter2"})
+	require.NoError(t, err)
+	require.Empty(t, logs.String())
+
+	_, err = pool.Exec(ctx, "SELECT pg_sleep(0.1) WHERE @secret::text <> ''", pgx.NamedArgs{"secret": "hunter2"})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), `"level":"warn"`)
+	require.Contains(t, logs.String(), `"sql":"SELECT pg_sleep(0.1) WHERE @secret::text <> ''"`)
+	require.Contains(t, logs.String(), `"rows":1`)
+	require.Contains(t, logs.String(), `"request_id":"req-1"`)
+	require.NotContains(t, logs.String(), "hunter2") // the arguments are never logged
+}
+
+func TestQueryLogger(t *testing.T) {
+	logs := captureLogs(t)
+	pool := newTracedPool(t, NewQueryLogger(zerolog.DebugLevel))
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	account := createRandomAccount(t)
+	tag, err := pool.Exec(ctx, "UPDATE accounts SET balance = balance WHERE id = @id", pgx.NamedArgs{"id": account.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), tag.RowsAffected())
+
+	event := lastEvent(t, logs)
+	require.Equal(t, "debug", event["level"])
+	require.Equal(t, "update", event["operation"])
+	require.Equal(t, "UPDATE accounts SET balance = balance WHERE id = @id", event["sql"])
+	require.Equal(t, float64(1), event["rows"])
+	require.Equal(t, "req-1", event["request_id"])
+	require.Contains(t, event, "duration")
+
+	_, err = pool.Exec(ctx, "SELECT * FROM no_such_table")
+	require.Error(t, err)
+
+	event = lastEvent(t, logs)
+	require.Equal(t, "error", event["level"])
+	require.Equal(t, "select", event["operation"])
+	require.Contains(t, event["error"], "no_such_table")
+
+	// transactions are logged statement by statement
+	logs.Reset()
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+	require.Contains(t, logs.String(), `"operation":"begin"`)
+	require.Contains(t, logs.String(), `"operation":"rollback"`)
+}
+
+func TestQueryLoggerPreparedStatement(t *testing.T) {
+	logs := captureLogs(t)
+	pool := newTracedPool(t, NewQueryLogger(zerolog.DebugLevel))
+	ctx := context.Background()
+
+	conn, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	_, err = conn.Conn().Prepare(ctx, "count_accounts", "SELECT count(*) FROM accounts")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "count_accounts")
+	require.NoError(t, err)
+
+	require.Equal(t, "count_accounts", lastEvent(t, logs)["operation"])
+}
+
+func TestQueryLoggerLevel(t *testing.T) {
+	logs := captureLogs(t)
+	pool := newTracedPool(t, NewQueryLogger(zerolog.ErrorLevel))
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+	require.Empty(t, logs.String()) // only failures at error level
+
+	_, err = pool.Exec(ctx, "SELECT * FROM no_such_table")
+	require.Error(t, err)
+	require.Equal(t, "error", lastEvent(t, logs)["level"])
+}
+
+func lastEvent(t *testing.T, logs *bytes.Buffer) map[string]any {
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.NotEmpty(t, lines[len(lines)-1])
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &event))
+	return event
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// rollbackTimeout bounds the rollback of a failed transaction
+	rollbackTimeout = 5 * time.Second
+	// txMaxRetries is how many times a transaction that lost to a concurrent one is run again
+	// before its error is returned
+	txMaxRetries = 3
+	// txRetryBaseDelay is the most the first retry waits, it doubles for each one after it
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+// retryableTxErrors are the SQLSTATEs of a transaction that lost to a concurrent one and can
+// run again as it is, by the reason label they are counted under
+var retryableTxErrors = map[string]string{
+	"40001": "serialization_failure",
+	"40P01": "deadlock_detected",
+}
+
+// TxMetrics counts the transactions run again after a conflict and times the wait for account locks
+type TxMetrics struct {
+	retries  *prometheus.CounterVec
+	lockWait prometheus.Histogram
+}
+
+// NewTxMetrics creates the transaction metrics and registers them with registerer
+func NewTxMetrics(registerer prometheus.Registerer) *TxMetrics {
+	m := &TxMetrics{
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_transaction_retries_total",
+			Help: "Number of database transactions run again after a conflict, by reason.",
+		}, []string{"reason"}),
+		lockWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "db_account_lock_wait_seconds",
+			Help:    "Time transfers waited for the advisory locks of their accounts.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+	}
+
+	registerer.MustRegister(m.retries, m.lockWait)
+	return m
+}
+
+// execTx runs fn in a transaction started with opts and commits it. When fn or the commit fails
+// with a serialization failure or a deadlock the transaction is rolled back and run again, up
+// to txMaxRetries times with a jittered backoff, so fn must be safe to run more than once and
+// keep nothing from an attempt that failed. Nothing is retried once ctx is done.
+func (r *repositoryImpl) execTx(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		err := r.runTx(ctx, opts, fn)
+
+		reason, retryable := retryableTxError(err)
+		if !retryable || attempt == txMaxRetries || ctx.Err() != nil {
+			return err
+		}
+		r.metrics.retries.WithLabelValues(reason).Inc()
+
+		// full jitter, transactions that conflicted with each other shouldn't meet again
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(txRetryBaseDelay << attempt))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *repositoryImpl) runTx(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := r.pool.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		// ctx may be the one that expired, the rollback still has to reach the database
+		rbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+		defer cancel()
+
+		if rbErr := tx.Rollback(rbCtx); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// retryableTxError reports whether err ended a transaction that can run again, and why
+func retryableTxError(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	reason, ok := retryableTxErrors[pgErr.Code]
+	return reason, ok
+}
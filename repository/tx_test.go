@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// newCountingRepo returns a repository with metrics of its own, so the counts start at zero
+func newCountingRepo(t *testing.T) *repositoryImpl {
+	t.Helper()
+	return newRepositoryImpl(testRepo.R.(*repositoryImpl).pool, NewTxMetrics(prometheus.NewRegistry()))
+}
+
+func TestExecTxRetriesConflicts(t *testing.T) {
+	repo := newCountingRepo(t)
+
+	attempts := 0
+	err := repo.execTx(context.Background(), pgx.TxOptions{}, func(tx pgx.Tx) error {
+		attempts++
+		switch attempts {
+		case 1:
+			return &pgconn.PgError{Code: "40001"}
+		case 2:
+			return &pgconn.PgError{Code: "40P01"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, float64(1), testutil.ToFloat64(repo.metrics.retries.WithLabelValues("serialization_failure")))
+	require.Equal(t, float64(1), testutil.ToFloat64(repo.metrics.retries.WithLabelValues("deadlock_detected")))
+}
+
+func TestExecTxGivesUp(t *testing.T) {
+	repo := newCountingRepo(t)
+	conflict := &pgconn.PgError{Code: "40001"}
+
+	attempts := 0
+	err := repo.execTx(context.Background(), pgx.TxOptions{}, func(tx pgx.Tx) error {
+		attempts++
+		return conflict
+	})
+	require.ErrorIs(t, err, conflict)
+	require.Equal(t, txMaxRetries+1, attempts)
+	require.Equal(t, float64(txMaxRetries), testutil.ToFloat64(repo.metrics.retries.WithLabelValues("serialization_failure")))
+}
+
+func TestExecTxDoesNotRetry(t *testing.T) {
+	repo := newCountingRepo(t)
+
+	// other errors
+	attempts := 0
+	failure := errors.New("failed")
+	err := repo.execTx(context.Background(), pgx.TxOptions{}, func(tx pgx.Tx) error {
+		attempts++
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, attempts)
+
+	// a done context
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	err = repo.execTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		attempts++
+		cancel()
+		return &pgconn.PgError{Code: "40001"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+	require.Zero(t, testutil.CollectAndCount(repo.metrics.retries))
+}
+
+func TestExecTxRetriesSerializationFailureAtCommit(t *testing.T) {
+	repo := newCountingRepo(t)
+	account := createRandomAccount(t)
+	opts := pgx.TxOptions{IsoLevel: pgx.Serializable}
+
+	// both read the balance, then both write it, so one of them can't be serialized and runs again
+	var read sync.WaitGroup
+	read.Add(2)
+	errs := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			first := true
+			errs <- repo.execTx(context.Background(), opts, func(tx pgx.Tx) error {
+				var balance int64
+				if err := tx.QueryRow(context.Background(), `SELECT balance FROM accounts WHERE id = @id`, pgx.NamedArgs{"id": account.ID}).Scan(&balance); err != nil {
+					return err
+				}
+				if first {
+					first = false
+					read.Done()
+					read.Wait()
+				}
+
+				_, err := tx.Exec(context.Background(), `UPDATE accounts SET balance = @balance WHERE id = @id`, pgx.NamedArgs{"id": account.ID, "balance": balance + 1})
+				return err
+			})
+		}()
+	}
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	// neither increment was lost
+	updated, err := testRepo.R.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+2, updated.Balance)
+	require.GreaterOrEqual(t, testutil.ToFloat64(repo.metrics.retries.WithLabelValues("serialization_failure")), float64(1))
+}
+
+// hotAccountStress runs transfers between one busy account and a few others, both ways, and
+// returns how many transactions were retried for each reason
+func hotAccountStress(t *testing.T, repo *repositoryImpl) map[string]float64 {
+	t.Helper()
+
+	hot := fundAccount(t, createRandomAccount(t))
+	others := make([]models.Account, 4)
+	for i := range others {
+		others[i] = fundAccount(t, createRandomAccount(t))
+	}
+
+	n := 40
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		fromAccountID, toAccountID := hot.ID, others[i%len(others)].ID
+		if i%2 == 1 {
+			fromAccountID, toAccountID = toAccountID, fromAccountID
+		}
+
+		go func() {
+			_, err := repo.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        10,
+				Currency:      hot.Currency,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// as many transfers came in as went out
+	updated, err := testRepo.R.GetAccount(context.Background(), hot.ID)
+	require.NoError(t, err)
+	require.Equal(t, hot.Balance, updated.Balance)
+
+	retries := make(map[string]float64)
+	for _, reason := range retryableTxErrors {
+		retries[reason] = testutil.ToFloat64(repo.metrics.retries.WithLabelValues(reason))
+	}
+	return retries
+}
+
+func TestTransferTxAdvisoryLocks(t *testing.T) {
+	without := hotAccountStress(t, newCountingRepo(t))
+
+	locking := newCountingRepo(t)
+	locking.accountLocks = true
+	with := hotAccountStress(t, locking)
+
+	t.Logf("retries without advisory locks: %v, with: %v", without, with)
+
+	// the transfers queued on the locks rather than running into each other
+	require.Zero(t, with["deadlock_detected"])
+	require.LessOrEqual(t, totalRetries(with), totalRetries(without))
+	require.Equal(t, uint64(40), histogramCount(t, locking.metrics.lockWait))
+}
+
+func totalRetries(retries map[string]float64) float64 {
+	var total float64
+	for _, n := range retries {
+		total += n
+	}
+	return total
+}
+
+func histogramCount(t *testing.T, histogram prometheus.Histogram) uint64 {
+	t.Helper()
+
+	var metric dto.Metric
+	require.NoError(t, histogram.Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
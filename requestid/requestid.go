@@ -0,0 +1,38 @@
+// Package requestid carries the id that ties log lines and background tasks back to the
+// request that caused them.
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header is the HTTP header and gRPC metadata key the id travels in
+const Header = "x-request-id"
+
+var isValid = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`).MatchString
+
+type contextKey struct{}
+
+// New returns a random id for a request that came without one
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether an id sent by a client can be used as is. Anything else is replaced,
+// so a client cannot break log lines with it.
+func Valid(id string) bool {
+	return isValid(id)
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the id of the request ctx belongs to, or "" outside of a request
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
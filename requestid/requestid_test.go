@@ -0,0 +1,25 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	require.Empty(t, FromContext(context.Background()))
+
+	id := New()
+	require.True(t, Valid(id))
+	require.Equal(t, id, FromContext(NewContext(context.Background(), id)))
+}
+
+func TestValid(t *testing.T) {
+	require.True(t, Valid("b3f1c0de-42"))
+	require.True(t, Valid("req_1.2"))
+	require.False(t, Valid(""))
+	require.False(t, Valid("a b"))
+	require.False(t, Valid("id\nforged log line"))
+	require.False(t, Valid(string(make([]byte, 129))))
+}
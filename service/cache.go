@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrCacheMiss is returned by a Cache without a value for the key
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache keeps encoded values for a while
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// RedisCache is a Cache shared by every replica
+type RedisCache struct {
+	client redis.UniversalClient
+}
+
+func NewRedisCache(client redis.UniversalClient) *RedisCache {
+	return &RedisCache{
+		client: client,
+	}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return value, err
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// EntityCache keeps the users and accounts read through GetUser and GetAccount for ttl, encoded
+// as protobuf, and the summaries of closed months for AccountSummaryTTL. The writes made through
+// the service drop what they changed once they are done.
+// A read racing such a write can still put back the old value, the short ttl bounds how long
+// it is served.
+// Failing to reach the cache is never an error: the database answers instead, and the failure
+// is only counted. A nil EntityCache caches nothing.
+type EntityCache struct {
+	cache  Cache
+	ttl    time.Duration
+	errors *prometheus.CounterVec
+}
+
+// NewEntityCache creates an entity cache on top of cache, with its metric registered with registerer
+func NewEntityCache(cache Cache, ttl time.Duration, registerer prometheus.Registerer) *EntityCache {
+	c := &EntityCache{
+		cache: cache,
+		ttl:   ttl,
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "entity_cache_errors_total",
+			Help: "Number of user and account cache operations that failed and fell back to the database, by operation.",
+		}, []string{"operation"}),
+	}
+
+	registerer.MustRegister(c.errors)
+	return c
+}
+
+// AccountSummaryTTL is how long the summary of a closed month is kept. Nothing is posted to a
+// closed month anymore, only filing one of its entries under another category changes it, and
+// that drops it.
+const AccountSummaryTTL = 30 * 24 * time.Hour
+
+func userKey(username string) string {
+	return "swift_bank:user:" + username
+}
+
+func accountKey(id int64) string {
+	return fmt.Sprintf("swift_bank:account:%d", id)
+}
+
+func (c *EntityCache) user(ctx context.Context, username string) (models.User, bool) {
+	var cached pb.CachedUser
+	if !c.get(ctx, userKey(username), &cached) {
+		return models.User{}, false
+	}
+
+	return models.User{
+		UserName:          cached.GetUsername(),
+		HashedPassword:    cached.GetHashedPassword(),
+		FullName:          cached.GetFullName(),
+		Email:             cached.GetEmail(),
+		IsEmailVerified:   cached.GetIsEmailVerified(),
+		Role:              cached.GetRole(),
+		PhoneNumber:       cached.GetPhoneNumber(),
+		IsPhoneVerified:   cached.GetIsPhoneVerified(),
+		PreviousEmail:     cached.GetPreviousEmail(),
+		EmailChangedAt:    fromCachedTime(cached.GetEmailChangedAt()),
+		PasswordChangedAt: fromCachedTime(cached.GetPasswordChangedAt()),
+		CreatedAt:         fromCachedTime(cached.GetCreatedAt()),
+	}, true
+}
+
+func (c *EntityCache) storeUser(ctx context.Context, user models.User) {
+	c.set(ctx, userKey(user.UserName), &pb.CachedUser{
+		Username:          user.UserName,
+		HashedPassword:    user.HashedPassword,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		IsEmailVerified:   user.IsEmailVerified,
+		Role:              user.Role,
+		PhoneNumber:       user.PhoneNumber,
+		IsPhoneVerified:   user.IsPhoneVerified,
+		PreviousEmail:     user.PreviousEmail,
+		EmailChangedAt:    toCachedTime(user.EmailChangedAt),
+		PasswordChangedAt: toCachedTime(user.PasswordChangedAt),
+		CreatedAt:         toCachedTime(user.CreatedAt),
+	})
+}
+
+func (c *EntityCache) forgetUser(ctx context.Context, username string) {
+	c.delete(ctx, userKey(username))
+}
+
+func (c *EntityCache) account(ctx context.Context, id int64) (models.Account, bool) {
+	var cached pb.CachedAccount
+	if !c.get(ctx, accountKey(id), &cached) {
+		return models.Account{}, false
+	}
+
+	account := models.Account{
+		ID:              cached.GetId(),
+		Owner:           cached.GetOwner(),
+		Balance:         cached.GetBalance(),
+		Currency:        cached.GetCurrency(),
+		OverdraftLimit:  cached.GetOverdraftLimit(),
+		Type:            cached.GetAccountType(),
+		Nickname:        cached.GetNickname(),
+		Metadata:        cached.GetMetadata(),
+		AccruedInterest: cached.GetAccruedInterest(),
+		CreatedAt:       fromCachedTime(cached.GetCreatedAt()),
+	}
+	if account.Type == "" {
+		account.Type = models.AccountChecking // cached before accounts had types
+	}
+	if cached.GetDeletedAt() != nil {
+		deletedAt := fromCachedTime(cached.GetDeletedAt())
+		account.DeletedAt = &deletedAt
+	}
+	return account, true
+}
+
+func (c *EntityCache) storeAccount(ctx context.Context, account models.Account) {
+	cached := &pb.CachedAccount{
+		Id:              account.ID,
+		Owner:           account.Owner,
+		Balance:         account.Balance,
+		Currency:        account.Currency,
+		OverdraftLimit:  account.OverdraftLimit,
+		AccountType:     account.Type,
+		Nickname:        account.Nickname,
+		Metadata:        account.Metadata,
+		AccruedInterest: account.AccruedInterest,
+		CreatedAt:       toCachedTime(account.CreatedAt),
+	}
+	if account.DeletedAt != nil {
+		cached.DeletedAt = toCachedTime(*account.DeletedAt)
+	}
+	c.set(ctx, accountKey(account.ID), cached)
+}
+
+// forgetAccounts drops the accounts with the given ids, a zero id is skipped
+func (c *EntityCache) forgetAccounts(ctx context.Context, ids ...int64) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			keys = append(keys, accountKey(id))
+		}
+	}
+	c.delete(ctx, keys...)
+}
+
+func accountSummaryKey(accountID int64, year, month int) string {
+	return fmt.Sprintf("swift_bank:account_summary:%d:%04d-%02d", accountID, year, month)
+}
+
+func (c *EntityCache) accountSummary(ctx context.Context, accountID int64, year, month int) (models.AccountSummary, bool) {
+	var cached pb.CachedAccountSummary
+	if !c.get(ctx, accountSummaryKey(accountID, year, month), &cached) {
+		return models.AccountSummary{}, false
+	}
+
+	summary := models.AccountSummary{
+		AccountID:   cached.GetAccountId(),
+		Currency:    cached.GetCurrency(),
+		Year:        int(cached.GetYear()),
+		Month:       int(cached.GetMonth()),
+		PeriodStart: fromCachedTime(cached.GetPeriodStart()),
+		PeriodEnd:   fromCachedTime(cached.GetPeriodEnd()),
+		Inflow:      cached.GetInflow(),
+		Outflow:     cached.GetOutflow(),
+		Count:       cached.GetCount(),
+		Categories:  make([]models.CategoryTotal, 0, len(cached.GetCategories())),
+	}
+	if largest := cached.GetLargest(); largest != nil {
+		summary.Largest = &models.Entry{
+			ID:        largest.GetId(),
+			AccountID: largest.GetAccountId(),
+			Amount:    largest.GetAmount(),
+			CreatedAt: fromCachedTime(largest.GetCreatedAt()),
+			Category:  largest.GetCategory(),
+			Tags:      largest.GetTags(),
+		}
+	}
+	for _, total := range cached.GetCategories() {
+		summary.Categories = append(summary.Categories, models.CategoryTotal{
+			Category: total.GetCategory(),
+			In:       total.GetIn(),
+			Out:      total.GetOut(),
+			Count:    int(total.GetCount()),
+		})
+	}
+	return summary, true
+}
+
+func (c *EntityCache) storeAccountSummary(ctx context.Context, summary models.AccountSummary) {
+	cached := &pb.CachedAccountSummary{
+		AccountId:   summary.AccountID,
+		Currency:    summary.Currency,
+		Year:        int32(summary.Year),
+		Month:       int32(summary.Month),
+		PeriodStart: toCachedTime(summary.PeriodStart),
+		PeriodEnd:   toCachedTime(summary.PeriodEnd),
+		Inflow:      summary.Inflow,
+		Outflow:     summary.Outflow,
+		Count:       summary.Count,
+	}
+	if summary.Largest != nil {
+		cached.Largest = &pb.CachedEntry{
+			Id:        summary.Largest.ID,
+			AccountId: summary.Largest.AccountID,
+			Amount:    summary.Largest.Amount,
+			CreatedAt: toCachedTime(summary.Largest.CreatedAt),
+			Category:  summary.Largest.Category,
+			Tags:      summary.Largest.Tags,
+		}
+	}
+	for _, total := range summary.Categories {
+		cached.Categories = append(cached.Categories, &pb.CachedCategoryTotal{
+			Category: total.Category,
+			In:       total.In,
+			Out:      total.Out,
+			Count:    int64(total.Count),
+		})
+	}
+	c.setFor(ctx, accountSummaryKey(summary.AccountID, summary.Year, summary.Month), cached, AccountSummaryTTL)
+}
+
+// forgetAccountSummary drops the summary of the month the entry was posted in
+func (c *EntityCache) forgetAccountSummary(ctx context.Context, entry models.Entry) {
+	postedAt := entry.CreatedAt.UTC() // statement months are in UTC
+	c.delete(ctx, accountSummaryKey(entry.AccountID, postedAt.Year(), int(postedAt.Month())))
+}
+
+// get decodes the value of key into message, telling whether there was one
+func (c *EntityCache) get(ctx context.Context, key string, message proto.Message) bool {
+	if c == nil {
+		return false
+	}
+
+	value, err := c.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			c.failed("get", key, err)
+		}
+		return false
+	}
+
+	if err := proto.Unmarshal(value, message); err != nil {
+		c.failed("decode", key, err)
+		return false
+	}
+	return true
+}
+
+func (c *EntityCache) set(ctx context.Context, key string, message proto.Message) {
+	if c == nil {
+		return
+	}
+
+	c.setFor(ctx, key, message, c.ttl)
+}
+
+func (c *EntityCache) setFor(ctx context.Context, key string, message proto.Message, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	value, err := proto.Marshal(message)
+	if err != nil {
+		c.failed("encode", key, err)
+		return
+	}
+
+	if err := c.cache.Set(ctx, key, value, ttl); err != nil {
+		c.failed("set", key, err)
+	}
+}
+
+// delete drops keys. A failed delete leaves the old value to be served until the ttl runs out.
+func (c *EntityCache) delete(ctx context.Context, keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+
+	if err := c.cache.Delete(ctx, keys...); err != nil {
+		c.failed("delete", keys[0], err)
+	}
+}
+
+func (c *EntityCache) failed(operation, key string, err error) {
+	c.errors.WithLabelValues(operation).Inc()
+	log.Debug().Err(err).Str("key", key).Msgf("entity cache %s failed", operation)
+}
+
+// toCachedTime leaves zero times out, so they decode to the zero time again
+func toCachedTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromCachedTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime().Local()
+}
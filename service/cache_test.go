@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"go.uber.org/mock/gomock"
+)
+
+// memoryCache is a Cache in a map, ttls are ignored
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.values[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] = value
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+// brokenCache fails every operation, like redis being out of reach
+type brokenCache struct{}
+
+func (brokenCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (brokenCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (brokenCache) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("connection refused")
+}
+
+func TestGetAccountCached(t *testing.T) {
+	account := randomAccount()
+	account.Type = models.AccountSavings
+	account.AccruedInterest = 123
+	account.CreatedAt = time.Now().Truncate(time.Microsecond)
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+
+	for i := 0; i < 2; i++ {
+		cached, err := service.S.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, cached.Balance)
+		require.Equal(t, account.Owner, cached.Owner)
+		require.Equal(t, models.AccountSavings, cached.Type)
+		require.Equal(t, account.AccruedInterest, cached.AccruedInterest)
+		require.True(t, account.CreatedAt.Equal(cached.CreatedAt))
+		require.Nil(t, cached.DeletedAt)
+	}
+}
+
+func TestGetAccountNotStaleAfterBalanceChange(t *testing.T) {
+	account := randomAccount()
+	updated := account
+	updated.Balance += 50
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil),
+		repo.EXPECT().AddAccountBalance(gomock.Any(), models.AddAccountBalanceParams{AccountID: account.ID, Amount: 50}).Times(1).Return(updated, nil),
+		repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(updated, nil),
+	)
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+
+	got, err := service.S.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, got.Balance)
+
+	_, err = service.S.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{AccountID: account.ID, Amount: 50})
+	require.NoError(t, err)
+
+	got, err = service.S.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Balance, got.Balance)
+}
+
+func TestGetAccountNotStaleAfterDetailsChange(t *testing.T) {
+	account := randomAccount()
+	account.Nickname = "Bills"
+	account.Metadata = map[string]string{"color": "red"}
+	updated := account
+	updated.Nickname = "Groceries"
+	updated.Metadata = map[string]string{"color": "green"}
+	arg := models.UpdateAccountDetailsParams{
+		AccountID: account.ID,
+		Nickname:  sql.NullString{String: updated.Nickname, Valid: true},
+		Metadata:  updated.Metadata,
+	}
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil),
+		repo.EXPECT().UpdateAccountDetails(gomock.Any(), arg).Times(1).Return(updated, nil),
+		repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(updated, nil),
+	)
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+
+	// the second read comes from the cache, nickname and metadata included
+	for i := 0; i < 2; i++ {
+		got, err := service.S.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Nickname, got.Nickname)
+		require.Equal(t, account.Metadata, got.Metadata)
+	}
+
+	_, err := service.S.UpdateAccountDetails(context.Background(), arg)
+	require.NoError(t, err)
+
+	got, err := service.S.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Nickname, got.Nickname)
+	require.Equal(t, updated.Metadata, got.Metadata)
+}
+
+func TestGetUserNotStaleAfterPasswordChange(t *testing.T) {
+	user := models.User{
+		UserName:       helpers.RandomOwner(),
+		HashedPassword: "old hash",
+		Email:          helpers.RandomEmail(),
+		Role:           "depositor",
+	}
+	updated := user
+	updated.HashedPassword = "new hash"
+	updated.PasswordChangedAt = time.Now().Truncate(time.Microsecond)
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().GetUser(gomock.Any(), user.UserName).Times(1).Return(user, nil),
+		repo.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(models.UpdateUserTxResult{User: updated}, nil),
+		repo.EXPECT().GetUser(gomock.Any(), user.UserName).Times(1).Return(updated, nil),
+	)
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+
+	for i := 0; i < 2; i++ {
+		got, err := service.S.GetUser(context.Background(), user.UserName)
+		require.NoError(t, err)
+		require.Equal(t, user, got)
+	}
+
+	_, err := service.S.UpdateUserTx(context.Background(), models.UpdateUserTxParams{
+		UpdateUserParams: models.UpdateUserParams{
+			UserName:       user.UserName,
+			HashedPassword: sql.NullString{String: updated.HashedPassword, Valid: true},
+		},
+	})
+	require.NoError(t, err)
+
+	got, err := service.S.GetUser(context.Background(), user.UserName)
+	require.NoError(t, err)
+	require.Equal(t, updated.HashedPassword, got.HashedPassword)
+	require.True(t, updated.PasswordChangedAt.Equal(got.PasswordChangedAt))
+}
+
+func TestGetAccountCacheUnavailable(t *testing.T) {
+	account := randomAccount()
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(2).Return(account, nil)
+
+	cache := NewEntityCache(brokenCache{}, time.Minute, prometheus.NewRegistry())
+	service := NewService(repo, nil, nil, nil, nil, cache, 0)
+
+	for i := 0; i < 2; i++ {
+		got, err := service.S.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account, got)
+	}
+
+	require.Equal(t, float64(2), testutil.ToFloat64(cache.errors.WithLabelValues("get")))
+	require.Equal(t, float64(2), testutil.ToFloat64(cache.errors.WithLabelValues("set")))
+}
+
+func TestGetAccountSummaryCached(t *testing.T) {
+	account := randomAccount()
+	start, end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	largest := models.Entry{ID: 3, AccountID: account.ID, Amount: -900, CreatedAt: start.Add(48 * time.Hour).Local(), Category: "housing", Tags: []string{"rent"}}
+	summary := models.AccountSummary{
+		AccountID:   account.ID,
+		PeriodStart: start.Local(),
+		Inflow:      1500,
+		Outflow:     1000,
+		Count:       3,
+		Largest:     &largest,
+		Categories: []models.CategoryTotal{
+			{Category: "housing", Out: 900, Count: 1},
+			{In: 1500, Out: 100, Count: 2},
+		},
+	}
+	arg := models.SetEntryCategoryParams{EntryID: largest.ID, Category: sql.NullString{String: "utilities", Valid: true}}
+	recategorized := largest
+	recategorized.Category = "utilities"
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	summarize := models.SummarizeEntriesParams{AccountID: account.ID, From: start, To: end}
+	gomock.InOrder(
+		repo.EXPECT().SummarizeEntries(gomock.Any(), summarize).Times(1).Return(summary, nil),
+		repo.EXPECT().SetEntryCategory(gomock.Any(), arg).Times(1).Return(recategorized, nil),
+		repo.EXPECT().SummarizeEntries(gomock.Any(), summarize).Times(1).Return(summary, nil),
+	)
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+	params := models.GetAccountSummaryParams{Account: account, Year: 2024, Month: 3}
+
+	// March 2024 is closed, the second read comes from the cache
+	want := summary
+	want.Currency, want.Year, want.Month, want.PeriodEnd = account.Currency, 2024, 3, end
+	for i := 0; i < 2; i++ {
+		got, err := service.S.GetAccountSummary(context.Background(), params)
+		require.NoError(t, err)
+		require.True(t, end.Equal(got.PeriodEnd))
+		got.PeriodEnd = end // the cached one is in local time, like the times read from the database
+		require.Equal(t, want, got)
+	}
+
+	// filing one of its entries under another category drops it
+	_, err := service.S.SetEntryCategory(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = service.S.GetAccountSummary(context.Background(), params)
+	require.NoError(t, err)
+}
+
+func TestGetAccountSummaryCurrentMonth(t *testing.T) {
+	account := randomAccount()
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// the month isn't over, every read goes to the database and ends now
+	repo.EXPECT().
+		SummarizeEntries(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, arg models.SummarizeEntriesParams) (models.AccountSummary, error) {
+			require.Equal(t, start, arg.From)
+			require.WithinDuration(t, time.Now(), arg.To, time.Second)
+			return models.AccountSummary{AccountID: account.ID, PeriodStart: arg.From}, nil
+		})
+
+	service := NewService(repo, nil, nil, nil, nil, NewEntityCache(newMemoryCache(), time.Minute, prometheus.NewRegistry()), 0)
+	params := models.GetAccountSummaryParams{Account: account, Year: now.Year(), Month: int(now.Month())}
+	for i := 0; i < 2; i++ {
+		got, err := service.S.GetAccountSummary(context.Background(), params)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), got.PeriodEnd, time.Second)
+	}
+}
+
+func TestGetAccountSummaryBeforeAccountOpened(t *testing.T) {
+	account := randomAccount()
+	opened := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().SummarizeEntries(gomock.Any(), gomock.Any()).Times(1).Return(models.AccountSummary{AccountID: account.ID, PeriodStart: opened}, nil)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+	got, err := service.S.GetAccountSummary(context.Background(), models.GetAccountSummaryParams{Account: account, Year: 2024, Month: 3})
+	require.NoError(t, err)
+
+	// the period is empty rather than ending before it starts
+	require.Equal(t, end, got.PeriodStart)
+	require.Equal(t, end, got.PeriodEnd)
+}
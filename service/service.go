@@ -2,45 +2,106 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/zde37/Swift_Bank/events"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/rates"
 	"github.com/zde37/Swift_Bank/repository"
 )
 
 type ServiceProvider interface {
 	CreateAccount(ctx context.Context, data models.CreateAccountRequest, username string) (models.Account, error)
 	GetAccount(ctx context.Context, id int64) (models.Account, error)
+	GetAccountIncludingDeleted(ctx context.Context, id int64) (models.Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (models.Account, error)
-	ListAccounts(ctx context.Context, naem string, limit, offset int32) ([]models.Account, error)
-	UpdateAccount(ctx context.Context, id int64, balance float64) (models.Account, error)
-	AddAccountBalance(ctx context.Context, id int64, balance float64) (models.Account, error)
+	ListAccounts(ctx context.Context, arg models.ListAccountsParams) ([]models.Account, error)
+	UpdateAccount(ctx context.Context, id int64, balance int64) (models.Account, error)
+	AddAccountBalance(ctx context.Context, arg models.AddAccountBalanceParams) (models.Account, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	ListInterestAccounts(ctx context.Context, arg models.ListInterestAccountsParams) ([]models.Account, error)
+	AccrueInterest(ctx context.Context, arg models.AccrueInterestParams) (models.InterestAccrual, error)
+	PostAccruedInterest(ctx context.Context, accountID int64, before time.Time) (models.Account, error)
 	CreateEntry(ctx context.Context, entry models.Entry) (models.Entry, error)
 	GetEntry(ctx context.Context, id int64) (models.Entry, error)
-	ListEntries(ctx context.Context, accountID, limit, offset int64) ([]models.Entry, error)
+	ListEntries(ctx context.Context, arg models.ListEntriesParams) ([]models.Entry, error)
+	SetEntryCategory(ctx context.Context, arg models.SetEntryCategoryParams) (models.Entry, error)
 	CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
 	GetTransaction(ctx context.Context, id int64) (models.Transaction, error)
 	ListTransactions(ctx context.Context, fromAccountID, toAccountID, limit, offset int64) ([]models.Transaction, error)
 	TransferTx(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error)
+	ReverseTransfer(ctx context.Context, arg models.ReverseTransferParams) (models.ReverseTransferResult, error)
+	AddBeneficiary(ctx context.Context, beneficiary models.Beneficiary) (models.Beneficiary, error)
+	GetBeneficiary(ctx context.Context, id int64) (models.Beneficiary, error)
+	GetBeneficiaryByIBAN(ctx context.Context, owner, iban string) (models.Beneficiary, error)
+	ListBeneficiaries(ctx context.Context, arg models.ListBeneficiariesParams) ([]models.Beneficiary, error)
+	DeleteBeneficiary(ctx context.Context, id int64) error
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, arg models.ListWebhooksParams) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	RecordWebhookDelivery(ctx context.Context, id int64, succeeded bool) (models.Webhook, error)
+	SetOverdraftLimitTx(ctx context.Context, arg models.SetOverdraftLimitParams) (models.Account, error)
+	UpdateAccountDetails(ctx context.Context, arg models.UpdateAccountDetailsParams) (models.Account, error)
+	BulkCreateAccountsTx(ctx context.Context, arg models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error)
+	ScheduleTransferTx(ctx context.Context, arg models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	ExecuteScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error)
+	CreateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error)
+	ListStandingOrders(ctx context.Context, arg models.ListStandingOrdersParams) ([]models.StandingOrder, error)
+	ListDueStandingOrders(ctx context.Context, now time.Time, limit int32) ([]models.StandingOrder, error)
+	PauseStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error)
+	ResumeStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error)
+	DeleteStandingOrder(ctx context.Context, id int64) error
+	ExecuteStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error)
 	CreateUserTx(ctx context.Context, data models.CreateUserTxParams) (models.User, error)
 	CreateVerifyEmail(ctx context.Context, data models.VerifyEmails) (models.VerifyEmails, error)
 	VerifyEmailTx(ctx context.Context, arg models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) 
+	CreateVerifyPhone(ctx context.Context, data models.VerifyPhones) (models.VerifyPhones, error)
+	VerifyPhoneTx(ctx context.Context, arg models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error)
+	CountUsersWithPhoneNumber(ctx context.Context, phoneNumber, username string) (int64, error)
 	CreateUser(ctx context.Context, data models.CreateUserRequest) (models.User, error)
 	LoginUser(ctx context.Context, data models.LoginUserRequest) (models.User, error)
 	GetUser(ctx context.Context, username string) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
 	UpdateUser(ctx context.Context, user models.UpdateUserParams) (models.User, error)
+	UpdateUserTx(ctx context.Context, arg models.UpdateUserTxParams) (models.UpdateUserTxResult, error)
+	GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, arg models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error)
+	CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error)
+	GetNotification(ctx context.Context, id int64) (models.Notification, error)
+	ListNotifications(ctx context.Context, arg models.ListNotificationsParams) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, id int64) (models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, username string) (int64, error)
 	ListUsers(ctx context.Context, limit, offset int32) ([]models.User, error)
 	NewSession(ctx context.Context, data models.Session) (models.Session, error)
 	FetchSession(ctx context.Context, id uuid.UUID) (models.Session, error)
+	GetStatement(ctx context.Context, arg models.GetStatementParams) (models.Statement, error)
+	GetAccountSummary(ctx context.Context, arg models.GetAccountSummaryParams) (models.AccountSummary, error)
+	RequestStatementTx(ctx context.Context, arg models.RequestStatementTxParams) (models.StatementDocument, error)
+	ListStatementDocuments(ctx context.Context, arg models.ListStatementDocumentsParams) ([]models.StatementDocument, error)
+	CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error)
+	ListAuditLogs(ctx context.Context, arg models.ListAuditLogsParams) ([]models.AuditLog, error)
+	ListCurrencies(ctx context.Context) ([]models.Currency, error)
+	AddCurrency(ctx context.Context, currency models.Currency) (models.Currency, error)
+	DisableCurrency(ctx context.Context, code string) (models.Currency, error)
+	GetMigrationStatus(ctx context.Context) (models.MigrationStatus, error)
+	CountExportRows(ctx context.Context, arg models.ExportTransactionsParams) (int64, error)
+	ExportTransactions(ctx context.Context, arg models.ExportTransactionsParams, fn func(models.LedgerRow) error) error
+	SearchTransactions(ctx context.Context, arg models.SearchTransactionsParams) ([]models.Transaction, error)
+	ListTransfers(ctx context.Context, arg models.ListTransfersParams) ([]models.Transaction, error)
+	GetBalance(ctx context.Context, arg models.GetBalanceParams) (models.Balance, error)
 }
 
 type Service struct {
 	S ServiceProvider
 }
 
-func NewService(repo repository.RepositoryProvider) *Service {
+func NewService(repo repository.RepositoryProvider, publisher events.Publisher, rateProvider rates.Provider, webhooks events.WebhookDispatcher, alerter events.TransferAlerter, cache *EntityCache, savingsTransferLimit int) *Service {
 	return &Service{
-		S: newServiceImpl(repo),
+		S: newServiceImpl(repo, publisher, rateProvider, webhooks, alerter, cache, savingsTransferLimit),
 	}
 }
@@ -2,51 +2,200 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/events"
 	"github.com/zde37/Swift_Bank/helpers"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/rates"
+	"github.com/zde37/Swift_Bank/recurrence"
 	"github.com/zde37/Swift_Bank/repository"
+	"github.com/zde37/Swift_Bank/statement"
+	"github.com/zde37/Swift_Bank/webhook"
 )
 
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still being processed")
+	ErrScheduledTransferDone  = errors.New("scheduled transfer is no longer pending")
+	ErrReverseReversal        = errors.New("a reversal cannot be reversed")
+
+	ErrInsufficientFunds       = repository.ErrInsufficientFunds
+	ErrTransferLimitReached    = repository.ErrTransferLimitReached
+	ErrFeeAccountCurrency      = repository.ErrFeeAccountCurrency
+	ErrTransferAlreadyReversed = repository.ErrTransferAlreadyReversed
+	ErrReversalNotCovered      = repository.ErrReversalNotCovered
+	ErrDuplicateNickname       = repository.ErrDuplicateNickname
+	ErrAccountExists           = repository.ErrAccountExists
+	ErrAccountNicknameExists   = repository.ErrAccountNicknameExists
+	ErrSavingsOverdraft        = repository.ErrSavingsOverdraft
+	ErrUnknownCategory         = repository.ErrUnknownCategory
+
+	ErrCurrencyDisabled         = errors.New("currency is disabled")
+	ErrCurrencyExponentMismatch = repository.ErrCurrencyExponentMismatch
+
+	ErrWrongPhoneCode = repository.ErrWrongPhoneCode
+
+	ErrStandingOrderStatus      = errors.New("standing order status does not allow this change")
+	ErrStandingOrderEnded       = errors.New("standing order is past its end date")
+	ErrStandingOrderRunRecorded = errors.New("standing order run was already recorded")
+
+	ErrInterestAlreadyAccrued = errors.New("interest was already accrued for the day")
+	ErrNoAccruedInterest      = errors.New("account has no accrued interest")
+	ErrNoInterestDue          = errors.New("account had no balance to earn interest on")
+)
+
+// IdempotencyKeyTTL is how long a transfer's idempotency key replays its first result
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// StandingOrderMaxFailures is how many runs in a row may fail before a standing order is suspended
+const StandingOrderMaxFailures = 3
+
+// WebhookMaxFailures is how many deliveries in a row may fail before a webhook is disabled
+const WebhookMaxFailures = 5
+
 type serviceImpl struct {
-	repo repository.RepositoryProvider
+	repo      repository.RepositoryProvider
+	publisher events.Publisher
+	rates     rates.Provider
+	webhooks  events.WebhookDispatcher
+	alerter   events.TransferAlerter
+	cache     *EntityCache
+
+	savingsTransferLimit int // outgoing transfers a savings account may make a month, 0 for no limit
 }
 
-func newServiceImpl(r repository.RepositoryProvider) *serviceImpl {
+func newServiceImpl(r repository.RepositoryProvider, p events.Publisher, rp rates.Provider, wd events.WebhookDispatcher, ta events.TransferAlerter, c *EntityCache, savingsTransferLimit int) *serviceImpl {
 	return &serviceImpl{
-		repo: r,
+		repo:                 r,
+		publisher:            p,
+		rates:                rp,
+		webhooks:             wd,
+		alerter:              ta,
+		cache:                c,
+		savingsTransferLimit: savingsTransferLimit,
 	}
 }
 
+// AccountExistsError is returned when the owner already has an account of the type in the
+// currency. It names the account, so clients can send the user to it instead.
+type AccountExistsError struct {
+	AccountID int64
+	Currency  string
+	Type      string
+}
+
+func (e *AccountExistsError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("you already have a %s account with id %d", e.Currency, e.AccountID)
+	}
+	return fmt.Sprintf("you already have a %s %s account with id %d", e.Currency, e.Type, e.AccountID)
+}
+
+func (e *AccountExistsError) Unwrap() error {
+	return ErrAccountExists
+}
+
+// CreateAccount opens an account of data.Type in data.Currency, a checking account when the type
+// is left out. An owner has one account of each type per currency, asking for a second one
+// returns an *AccountExistsError.
 func (s *serviceImpl) CreateAccount(ctx context.Context, data models.CreateAccountRequest, username string) (models.Account, error) {
+	accountType := data.Type
+	if accountType == "" {
+		accountType = models.AccountChecking
+	}
+
+	existing, err := s.repo.GetAccountByCurrency(ctx, username, data.Currency, accountType)
+	if err == nil {
+		return models.Account{}, &AccountExistsError{AccountID: existing.ID, Currency: existing.Currency, Type: existing.Type}
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Account{}, err
+	}
+
 	arg := models.Account{
 		Owner:    username,
 		Balance:  0,
 		Currency: data.Currency,
+		Type:     accountType,
 	}
 
-	return s.repo.CreateAccount(ctx, arg)
+	account, err := s.repo.CreateAccount(ctx, arg)
+	if errors.Is(err, ErrAccountExists) {
+		// a concurrent request created it after the check above, the unique index caught it
+		existing, getErr := s.repo.GetAccountByCurrency(ctx, username, data.Currency, accountType)
+		if getErr != nil {
+			return models.Account{}, err
+		}
+		return models.Account{}, &AccountExistsError{AccountID: existing.ID, Currency: existing.Currency, Type: existing.Type}
+	}
+
+	return account, err
 }
 
+// GetAccount answers from the cache when it has the account
 func (s *serviceImpl) GetAccount(ctx context.Context, id int64) (models.Account, error) {
-	return s.repo.GetAccount(ctx, id)
+	if account, ok := s.cache.account(ctx, id); ok {
+		return account, nil
+	}
+
+	account, err := s.repo.GetAccount(ctx, id)
+	if err != nil {
+		return account, err
+	}
+
+	s.cache.storeAccount(ctx, account)
+	return account, nil
+}
+
+// GetAccountIncludingDeleted also finds soft deleted accounts, GetAccount doesn't
+func (s *serviceImpl) GetAccountIncludingDeleted(ctx context.Context, id int64) (models.Account, error) {
+	return s.repo.GetAccountIncludingDeleted(ctx, id)
 }
 
 func (s *serviceImpl) GetAccountForUpdate(ctx context.Context, id int64) (models.Account, error) {
 	return s.repo.GetAccountForUpdate(ctx, id)
 }
 
-func (s *serviceImpl) ListAccounts(ctx context.Context, name string, limit, offset int32) ([]models.Account, error) {
-	return s.repo.ListAccounts(ctx, name, limit, offset)
+func (s *serviceImpl) ListAccounts(ctx context.Context, arg models.ListAccountsParams) ([]models.Account, error) {
+	return s.repo.ListAccounts(ctx, arg)
 }
 
-func (s *serviceImpl) UpdateAccount(ctx context.Context, id int64, balance float64) (models.Account, error) {
+func (s *serviceImpl) UpdateAccount(ctx context.Context, id int64, balance int64) (models.Account, error) {
+	defer s.cache.forgetAccounts(ctx, id)
 	return s.repo.UpdateAccount(ctx, id, balance)
 }
 
-func (s *serviceImpl) AddAccountBalance(ctx context.Context, id int64, balance float64) (models.Account, error) {
-	return s.repo.AddAccountBalance(ctx, id, balance)
+// AddAccountBalance deposits a positive amount or withdraws a negative one
+func (s *serviceImpl) AddAccountBalance(ctx context.Context, arg models.AddAccountBalanceParams) (models.Account, error) {
+	account, err := s.repo.AddAccountBalance(ctx, arg)
+	s.cache.forgetAccounts(ctx, arg.AccountID)
+	if err != nil {
+		return account, err
+	}
+
+	event := models.WebhookEventDepositCompleted
+	if arg.Amount < 0 {
+		event = models.WebhookEventWithdrawalCompleted
+	}
+	s.notifyWebhooks(ctx, event, models.BalanceChange{
+		AccountID: account.ID,
+		Amount:    money.New(arg.Amount, account.Currency).String(),
+		Currency:  account.Currency,
+		Balance:   money.New(account.Balance, account.Currency).String(),
+	}, account.Owner)
+
+	return account, nil
 }
 
 func (s *serviceImpl) DeleteAccount(ctx context.Context, id int64) error {
@@ -55,9 +204,79 @@ func (s *serviceImpl) DeleteAccount(ctx context.Context, id int64) error {
 		return err
 	}
 
+	defer s.cache.forgetAccounts(ctx, id)
 	return s.repo.DeleteAccount(ctx, id)
 }
 
+func (s *serviceImpl) ListInterestAccounts(ctx context.Context, arg models.ListInterestAccountsParams) ([]models.Account, error) {
+	return s.repo.ListInterestAccounts(ctx, arg)
+}
+
+// AccrueInterest adds a day of interest on the balance of the account to its accrued interest,
+// the balance itself only grows when PostAccruedInterest pays it. The date is the UTC day the
+// interest was earned, it is earned on the balance the day closed with. An account accrues at
+// most once for a day and ErrInterestAlreadyAccrued is returned for every later try,
+// ErrNoInterestDue when the day closed without a positive balance.
+func (s *serviceImpl) AccrueInterest(ctx context.Context, arg models.AccrueInterestParams) (models.InterestAccrual, error) {
+	date := arg.Date.UTC()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	balance, err := s.repo.GetBalanceAt(ctx, arg.Account.ID, day.AddDate(0, 0, 1))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.InterestAccrual{}, ErrNoInterestDue // deleted since it was listed
+	}
+	if err != nil {
+		return models.InterestAccrual{}, err
+	}
+	if balance <= 0 {
+		return models.InterestAccrual{}, ErrNoInterestDue
+	}
+
+	interest, err := money.New(balance, arg.Account.Currency).DailyInterest(arg.Rate)
+	if err != nil {
+		return models.InterestAccrual{}, err
+	}
+
+	accrual, err := s.repo.AccrueInterest(ctx, models.InterestAccrual{
+		AccountID:   arg.Account.ID,
+		AccrualDate: day,
+		Balance:     balance,
+		Rate:        arg.Rate,
+		Amount:      interest.Units,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return accrual, ErrInterestAlreadyAccrued
+	}
+	if err != nil {
+		return accrual, err
+	}
+
+	s.cache.forgetAccounts(ctx, arg.Account.ID)
+	return accrual, nil
+}
+
+// PostAccruedInterest pays the interest the account accrued before the given day into its balance,
+// subscribers hear of it as a deposit. ErrNoAccruedInterest is returned when there is nothing to pay.
+func (s *serviceImpl) PostAccruedInterest(ctx context.Context, accountID int64, before time.Time) (models.Account, error) {
+	account, entry, err := s.repo.PostAccruedInterest(ctx, accountID, before)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return account, ErrNoAccruedInterest
+	}
+	if err != nil {
+		return account, err
+	}
+
+	s.cache.forgetAccounts(ctx, accountID)
+	s.notifyWebhooks(ctx, models.WebhookEventDepositCompleted, models.BalanceChange{
+		AccountID: account.ID,
+		Amount:    money.New(entry.Amount, account.Currency).String(),
+		Currency:  account.Currency,
+		Balance:   money.New(account.Balance, account.Currency).String(),
+	}, account.Owner)
+
+	return account, nil
+}
+
 func (s *serviceImpl) CreateEntry(ctx context.Context, entry models.Entry) (models.Entry, error) {
 
 	return models.Entry{}, nil
@@ -67,9 +286,19 @@ func (s *serviceImpl) GetEntry(ctx context.Context, id int64) (models.Entry, err
 	return s.repo.GetEntry(ctx, id)
 }
 
-func (s *serviceImpl) ListEntries(ctx context.Context, accountID, limit, offset int64) ([]models.Entry, error) {
+func (s *serviceImpl) ListEntries(ctx context.Context, arg models.ListEntriesParams) ([]models.Entry, error) {
+	return s.repo.ListEntries(ctx, arg)
+}
 
-	return nil, nil
+func (s *serviceImpl) SetEntryCategory(ctx context.Context, arg models.SetEntryCategoryParams) (models.Entry, error) {
+	entry, err := s.repo.SetEntryCategory(ctx, arg)
+	if err != nil {
+		return entry, err
+	}
+
+	// the category totals of its month changed
+	s.cache.forgetAccountSummary(ctx, entry)
+	return entry, nil
 }
 
 func (s *serviceImpl) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
@@ -86,6 +315,14 @@ func (s *serviceImpl) ListTransactions(ctx context.Context, fromAccountID, toAcc
 	return nil, nil
 }
 
+func (s *serviceImpl) SearchTransactions(ctx context.Context, arg models.SearchTransactionsParams) ([]models.Transaction, error) {
+	return s.repo.SearchTransactions(ctx, arg)
+}
+
+func (s *serviceImpl) ListTransfers(ctx context.Context, arg models.ListTransfersParams) ([]models.Transaction, error) {
+	return s.repo.ListTransfers(ctx, arg)
+}
+
 func (s *serviceImpl) NewSession(ctx context.Context, data models.Session) (models.Session, error) {
 	return s.repo.CreateSession(ctx, data)
 }
@@ -99,13 +336,30 @@ func (s *serviceImpl) CreateUserTx(ctx context.Context, data models.CreateUserTx
 }
 
 func (s *serviceImpl) VerifyEmailTx(ctx context.Context, arg models.VerifyEmailTxParams) (models.VerifyEmailTxResult, error) {
-	return s.repo.VerifyEmailTx(ctx, arg)
+	result, err := s.repo.VerifyEmailTx(ctx, arg)
+	if err == nil {
+		s.cache.forgetUser(ctx, result.User.UserName)
+	}
+	return result, err
 }
 
 func (s *serviceImpl) CreateVerifyEmail(ctx context.Context, data models.VerifyEmails) (models.VerifyEmails, error) {
 	return s.repo.CreateVerifyEmail(ctx, data)
 }
 
+func (s *serviceImpl) CreateVerifyPhone(ctx context.Context, data models.VerifyPhones) (models.VerifyPhones, error) {
+	return s.repo.CreateVerifyPhone(ctx, data)
+}
+
+func (s *serviceImpl) VerifyPhoneTx(ctx context.Context, arg models.VerifyPhoneTxParams) (models.VerifyPhoneTxResult, error) {
+	defer s.cache.forgetUser(ctx, arg.Username)
+	return s.repo.VerifyPhoneTx(ctx, arg)
+}
+
+func (s *serviceImpl) CountUsersWithPhoneNumber(ctx context.Context, phoneNumber, username string) (int64, error) {
+	return s.repo.CountUsersWithPhoneNumber(ctx, phoneNumber, username)
+}
+
 func (s *serviceImpl) CreateUser(ctx context.Context, data models.CreateUserRequest) (models.User, error) {
 
 	arg := models.User{
@@ -130,11 +384,72 @@ func (s *serviceImpl) LoginUser(ctx context.Context, data models.LoginUserReques
 }
 
 func (s *serviceImpl) UpdateUser(ctx context.Context, user models.UpdateUserParams) (models.User, error) {
+	defer s.cache.forgetUser(ctx, user.UserName)
 	return s.repo.UpdateUser(ctx, user)
 }
 
+// UpdateUserTx also changes passwords, the cached user goes with the old one
+func (s *serviceImpl) UpdateUserTx(ctx context.Context, arg models.UpdateUserTxParams) (models.UpdateUserTxResult, error) {
+	defer s.cache.forgetUser(ctx, arg.UserName)
+	return s.repo.UpdateUserTx(ctx, arg)
+}
+
+// GetNotificationPreferences falls back to everything enabled for users without stored preferences
+func (s *serviceImpl) GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error) {
+	preferences, err := s.repo.GetNotificationPreferences(ctx, username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.NotificationPreferences{
+			Username:        username,
+			EmailTransfers:  true,
+			EmailLogins:     true,
+			EmailStatements: true,
+		}, nil
+	}
+
+	return preferences, err
+}
+
+func (s *serviceImpl) UpdateNotificationPreferences(ctx context.Context, arg models.UpdateNotificationPreferencesParams) (models.NotificationPreferences, error) {
+	return s.repo.UpdateNotificationPreferences(ctx, arg)
+}
+
+func (s *serviceImpl) CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	return s.repo.CreateNotification(ctx, notification)
+}
+
+func (s *serviceImpl) GetNotification(ctx context.Context, id int64) (models.Notification, error) {
+	return s.repo.GetNotification(ctx, id)
+}
+
+func (s *serviceImpl) ListNotifications(ctx context.Context, arg models.ListNotificationsParams) ([]models.Notification, error) {
+	return s.repo.ListNotifications(ctx, arg)
+}
+
+func (s *serviceImpl) MarkNotificationRead(ctx context.Context, id int64) (models.Notification, error) {
+	return s.repo.MarkNotificationRead(ctx, id)
+}
+
+func (s *serviceImpl) CountUnreadNotifications(ctx context.Context, username string) (int64, error) {
+	return s.repo.CountUnreadNotifications(ctx, username)
+}
+
+// GetUser answers from the cache when it has the user
 func (s *serviceImpl) GetUser(ctx context.Context, username string) (models.User, error) {
-	return s.repo.GetUser(ctx, username)
+	if user, ok := s.cache.user(ctx, username); ok {
+		return user, nil
+	}
+
+	user, err := s.repo.GetUser(ctx, username)
+	if err != nil {
+		return user, err
+	}
+
+	s.cache.storeUser(ctx, user)
+	return user, nil
+}
+
+func (s *serviceImpl) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.repo.GetUserByEmail(ctx, email)
 }
 
 func (s *serviceImpl) ListUsers(ctx context.Context, limit, offset int32) ([]models.User, error) {
@@ -142,5 +457,690 @@ func (s *serviceImpl) ListUsers(ctx context.Context, limit, offset int32) ([]mod
 }
 
 func (s *serviceImpl) TransferTx(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error) {
-	return s.repo.TransferTx(ctx, arg)
+	// retries are answered before any balance check, the first attempt may have drained the account
+	if arg.Idempotency != nil {
+		key := *arg.Idempotency
+		key.RequestHash = transferRequestHash(arg)
+		key.ExpiresAt = time.Now().Add(IdempotencyKeyTTL)
+		arg.Idempotency = &key
+
+		result, found, err := s.replayTransfer(ctx, key)
+		if err != nil || found {
+			return result, err
+		}
+	}
+
+	fromAccount, err := s.repo.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return models.TransferTxResult{}, err
+	}
+
+	toAccount, err := s.repo.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return models.TransferTxResult{}, err
+	}
+
+	// balances in a disabled currency stay readable, but no money moves in or out of them
+	for _, currency := range []string{fromAccount.Currency, toAccount.Currency} {
+		if !helpers.ISSupportedCurrency(currency) {
+			return models.TransferTxResult{}, fmt.Errorf("%w: %s", ErrCurrencyDisabled, currency)
+		}
+	}
+
+	// currencies without a fee policy are free to send from
+	policy, err := s.repo.GetFeePolicy(ctx, arg.Currency)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.TransferTxResult{}, err
+	}
+
+	amount := money.New(arg.Amount, arg.Currency)
+	fee, err := transferFee(policy, amount)
+	if err != nil {
+		return models.TransferTxResult{}, err
+	}
+	arg.Fee = fee.Units
+	arg.FeeAccountID = policy.FeeAccountID
+
+	// fails fast before a rate is fetched, the repository checks again against the locked row
+	if err := repository.CheckFunds(fromAccount, arg.Amount, fee.Units); err != nil {
+		return models.TransferTxResult{}, err
+	}
+
+	arg.ToAmount, arg.ToCurrency, arg.ExchangeRate = arg.Amount, toAccount.Currency, 1
+	if toAccount.Currency != arg.Currency {
+		rate, err := s.getRate(ctx, arg.Currency, toAccount.Currency)
+		if err != nil {
+			return models.TransferTxResult{}, fmt.Errorf("cannot convert %s to %s: %w", arg.Currency, toAccount.Currency, err)
+		}
+
+		toAmount, err := amount.Convert(rate.Value, toAccount.Currency)
+		if err != nil {
+			return models.TransferTxResult{}, err
+		}
+		arg.ToAmount = toAmount.Units
+		arg.ExchangeRate = rate.Value
+	}
+
+	// counted by the repository against the locked sender row, so concurrent transfers can't go past it
+	arg.SavingsTransferLimit = s.savingsTransferLimit
+	result, err := s.repo.TransferTx(ctx, arg)
+	// dropped whatever the outcome, an error may have come after the commit
+	s.cache.forgetAccounts(ctx, arg.FromAccountID, arg.ToAccountID, arg.FeeAccountID)
+	if err != nil {
+		// a concurrent request with the same key got there first, answer with its result
+		if arg.Idempotency != nil && errors.Is(err, repository.ErrDuplicateIdempotencyKey) {
+			result, found, err := s.replayTransfer(ctx, *arg.Idempotency)
+			if err == nil && !found {
+				err = ErrIdempotencyKeyInFlight
+			}
+			return result, err
+		}
+		return result, err
+	}
+
+	s.publishEntries(ctx, result.FromEntry, result.ToEntry)
+	if result.FeeEntry.ID != 0 {
+		s.publishEntries(ctx, result.FeeEntry)
+	}
+	s.notifyWebhooks(ctx, models.WebhookEventTransferCompleted, transferCompleted(result.Transaction), fromAccount.Owner, toAccount.Owner)
+	s.alertLargeTransfer(ctx, result.Transaction)
+
+	return result, nil
+}
+
+// ReverseTransfer sends the money of a transfer back the way it came. The sender gets back exactly
+// what was debited, also across currencies, while a fee charged on the original is kept.
+func (s *serviceImpl) ReverseTransfer(ctx context.Context, arg models.ReverseTransferParams) (models.ReverseTransferResult, error) {
+	var result models.ReverseTransferResult
+
+	original, err := s.repo.GetTransaction(ctx, arg.TransactionID)
+	if err != nil {
+		return result, err
+	}
+
+	if original.ReversalOf != 0 {
+		return result, ErrReverseReversal
+	}
+
+	if original.ReversedBy != 0 {
+		return result, ErrTransferAlreadyReversed
+	}
+
+	result.Reversal, err = s.repo.ReverseTransferTx(ctx, models.ReverseTransferTxParams{
+		Transfer: models.TransferTxParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        original.ToAmount,
+			Currency:      original.ToCurrency,
+			Description:   fmt.Sprintf("Reversal of transfer %d", original.ID),
+			ToAmount:      original.Amount,
+			ToCurrency:    original.Currency,
+			ExchangeRate:  1 / original.ExchangeRate,
+			ReversalOf:    original.ID,
+		},
+		ReversedBy: arg.ReversedBy,
+		Reason:     arg.Reason,
+	})
+	s.cache.forgetAccounts(ctx, original.FromAccountID, original.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	original.ReversedBy = result.Reversal.Transaction.ID
+	result.Original = original
+
+	s.publishEntries(ctx, result.Reversal.FromEntry, result.Reversal.ToEntry)
+
+	return result, nil
+}
+
+// AddBeneficiary saves the account as a payee of the user, the account has to exist
+func (s *serviceImpl) AddBeneficiary(ctx context.Context, beneficiary models.Beneficiary) (models.Beneficiary, error) {
+	if _, err := s.repo.GetAccount(ctx, beneficiary.AccountID); err != nil {
+		return models.Beneficiary{}, err
+	}
+
+	return s.repo.CreateBeneficiary(ctx, beneficiary)
+}
+
+func (s *serviceImpl) GetBeneficiary(ctx context.Context, id int64) (models.Beneficiary, error) {
+	return s.repo.GetBeneficiary(ctx, id)
+}
+
+func (s *serviceImpl) GetBeneficiaryByIBAN(ctx context.Context, owner, iban string) (models.Beneficiary, error) {
+	return s.repo.GetBeneficiaryByIBAN(ctx, owner, iban)
+}
+
+func (s *serviceImpl) ListBeneficiaries(ctx context.Context, arg models.ListBeneficiariesParams) ([]models.Beneficiary, error) {
+	return s.repo.ListBeneficiaries(ctx, arg)
+}
+
+func (s *serviceImpl) DeleteBeneficiary(ctx context.Context, id int64) error {
+	return s.repo.DeleteBeneficiary(ctx, id)
+}
+
+// SetOverdraftLimitTx changes how far the account may be overdrawn, savings accounts can't be
+func (s *serviceImpl) SetOverdraftLimitTx(ctx context.Context, arg models.SetOverdraftLimitParams) (models.Account, error) {
+	if arg.Limit > 0 {
+		account, err := s.repo.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return account, err
+		}
+		if account.Type == models.AccountSavings {
+			return models.Account{}, ErrSavingsOverdraft
+		}
+	}
+
+	defer s.cache.forgetAccounts(ctx, arg.AccountID)
+	return s.repo.SetOverdraftLimitTx(ctx, arg)
+}
+
+func (s *serviceImpl) UpdateAccountDetails(ctx context.Context, arg models.UpdateAccountDetailsParams) (models.Account, error) {
+	defer s.cache.forgetAccounts(ctx, arg.AccountID)
+	return s.repo.UpdateAccountDetails(ctx, arg)
+}
+
+func (s *serviceImpl) BulkCreateAccountsTx(ctx context.Context, arg models.BulkCreateAccountsParams) ([]models.BulkAccountResult, error) {
+	return s.repo.BulkCreateAccountsTx(ctx, arg)
+}
+
+// ScheduleTransferTx stores a future-dated transfer. Balances are only checked when it executes.
+func (s *serviceImpl) ScheduleTransferTx(ctx context.Context, arg models.CreateScheduledTransferTxParams) (models.ScheduledTransfer, error) {
+	if _, err := s.repo.GetAccount(ctx, arg.Transfer.ToAccountID); err != nil {
+		return models.ScheduledTransfer{}, err
+	}
+
+	return s.repo.CreateScheduledTransferTx(ctx, arg)
+}
+
+func (s *serviceImpl) GetScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	return s.repo.GetScheduledTransfer(ctx, id)
+}
+
+// CancelScheduledTransfer cancels a pending transfer, ErrScheduledTransferDone is returned once execution started
+func (s *serviceImpl) CancelScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	transfer, err := s.repo.CancelScheduledTransfer(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return transfer, ErrScheduledTransferDone
+	}
+
+	return transfer, err
+}
+
+// ExecuteScheduledTransfer runs a due transfer and records the outcome. Business failures such as
+// insufficient funds mark the transfer failed, any other error is returned so the caller can retry.
+func (s *serviceImpl) ExecuteScheduledTransfer(ctx context.Context, id int64) (models.ScheduledTransfer, error) {
+	transfer, err := s.repo.ClaimScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transfer, ErrScheduledTransferDone
+		}
+		return transfer, err
+	}
+
+	result, err := s.TransferTx(ctx, models.TransferTxParams{
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Currency:      transfer.Currency,
+		Description:   transfer.Description,
+		// a retry after a crash between the transfer and the status update must not move money twice
+		Idempotency: &models.IdempotencyKey{
+			UserName: transfer.CreatedBy,
+			Key:      fmt.Sprintf("scheduled-%d", transfer.ID),
+		},
+	})
+
+	finish := models.FinishScheduledTransferParams{
+		ID:     transfer.ID,
+		Status: models.ScheduledTransferExecuted,
+	}
+
+	if err != nil {
+		reason, ok := transferFailureReason(err)
+		if !ok {
+			return transfer, err
+		}
+		finish.Status, finish.FailureReason = models.ScheduledTransferFailed, reason
+	}
+	finish.TransactionID = result.Transaction.ID
+
+	return s.repo.FinishScheduledTransfer(ctx, finish)
+}
+
+// transferFailureReason tells business failures of a deferred transfer, which are recorded
+// and reported to the owner, apart from errors worth retrying
+func transferFailureReason(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrTransferLimitReached), errors.Is(err, rates.ErrRateUnavailable), errors.Is(err, rates.ErrRateStale):
+		return err.Error(), true
+	case errors.Is(err, pgx.ErrNoRows):
+		return "account no longer exists", true
+	}
+
+	return "", false
+}
+
+func (s *serviceImpl) CreateStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error) {
+	if _, err := s.repo.GetAccount(ctx, order.ToAccountID); err != nil {
+		return models.StandingOrder{}, err
+	}
+
+	order.NextRunAt = order.StartDate
+	return s.repo.CreateStandingOrder(ctx, order)
+}
+
+func (s *serviceImpl) GetStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error) {
+	return s.repo.GetStandingOrder(ctx, id)
+}
+
+func (s *serviceImpl) ListStandingOrders(ctx context.Context, arg models.ListStandingOrdersParams) ([]models.StandingOrder, error) {
+	return s.repo.ListStandingOrders(ctx, arg)
+}
+
+func (s *serviceImpl) ListDueStandingOrders(ctx context.Context, now time.Time, limit int32) ([]models.StandingOrder, error) {
+	return s.repo.ListDueStandingOrders(ctx, now, limit)
+}
+
+func (s *serviceImpl) PauseStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error) {
+	order, err := s.repo.PauseStandingOrder(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return order, ErrStandingOrderStatus
+	}
+
+	return order, err
+}
+
+// ResumeStandingOrder reactivates a paused or suspended order. Runs missed in the meantime are skipped.
+func (s *serviceImpl) ResumeStandingOrder(ctx context.Context, id int64) (models.StandingOrder, error) {
+	order, err := s.repo.GetStandingOrder(ctx, id)
+	if err != nil {
+		return order, err
+	}
+
+	nextRunAt := order.NextRunAt
+	if now := time.Now(); nextRunAt.Before(now) {
+		nextRunAt, err = recurrence.Next(order.StartDate, order.Frequency, now)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	if order.EndDate != nil && nextRunAt.After(*order.EndDate) {
+		return order, ErrStandingOrderEnded
+	}
+
+	order, err = s.repo.ResumeStandingOrder(ctx, id, nextRunAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return order, ErrStandingOrderStatus
+	}
+
+	return order, err
+}
+
+func (s *serviceImpl) DeleteStandingOrder(ctx context.Context, id int64) error {
+	return s.repo.DeleteStandingOrder(ctx, id)
+}
+
+// ExecuteStandingOrder makes the due run of order, records it and schedules the next one.
+// Business failures count towards suspending the order, any other error is returned so the caller can retry.
+func (s *serviceImpl) ExecuteStandingOrder(ctx context.Context, order models.StandingOrder) (models.StandingOrder, error) {
+	execution := models.StandingOrderExecution{
+		StandingOrderID: order.ID,
+		RunAt:           order.NextRunAt,
+		Status:          models.StandingOrderRunExecuted,
+	}
+
+	result, err := s.TransferTx(ctx, models.TransferTxParams{
+		FromAccountID: order.FromAccountID,
+		ToAccountID:   order.ToAccountID,
+		Amount:        order.Amount,
+		Currency:      order.Currency,
+		Description:   order.Description,
+		// one key per run, a retried run must not move money twice
+		Idempotency: &models.IdempotencyKey{
+			UserName: order.Owner,
+			Key:      fmt.Sprintf("standing-order-%d-%d", order.ID, order.NextRunAt.Unix()),
+		},
+	})
+	if err != nil {
+		reason, ok := transferFailureReason(err)
+		if !ok {
+			return order, err
+		}
+		execution.Status, execution.FailureReason = models.StandingOrderRunFailed, reason
+	}
+	execution.TransactionID = result.Transaction.ID
+
+	nextRunAt, err := recurrence.Next(order.StartDate, order.Frequency, order.NextRunAt)
+	if err != nil {
+		return order, err
+	}
+
+	order, err = s.repo.RecordStandingOrderRunTx(ctx, models.RecordStandingOrderRunParams{
+		Execution:   execution,
+		NextRunAt:   nextRunAt,
+		Completed:   order.EndDate != nil && nextRunAt.After(*order.EndDate),
+		MaxFailures: StandingOrderMaxFailures,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return order, ErrStandingOrderRunRecorded
+	}
+
+	return order, err
+}
+
+// replayTransfer returns the stored result of an earlier transfer made with the same key
+func (s *serviceImpl) replayTransfer(ctx context.Context, key models.IdempotencyKey) (models.TransferTxResult, bool, error) {
+	var result models.TransferTxResult
+
+	stored, err := s.repo.GetIdempotencyKey(ctx, key.UserName, key.Key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, false, nil
+		}
+		return result, false, err
+	}
+
+	if stored.RequestHash != key.RequestHash {
+		return result, true, ErrIdempotencyKeyReused
+	}
+
+	if len(stored.Response) == 0 {
+		return result, true, ErrIdempotencyKeyInFlight
+	}
+
+	if err := json.Unmarshal(stored.Response, &result); err != nil {
+		return result, true, fmt.Errorf("failed to unmarshal stored response: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// transferRequestHash fingerprints what the client asked for, server computed fields are left out.
+// So are the category and tags, filing the entry doesn't make it another transfer.
+func transferRequestHash(arg models.TransferTxParams) string {
+	request := fmt.Sprintf("%d|%d|%s|%s|%s",
+		arg.FromAccountID,
+		arg.ToAccountID,
+		strconv.FormatInt(arg.Amount, 10),
+		arg.Currency,
+		arg.Description,
+	)
+
+	sum := sha256.Sum256([]byte(request))
+	return hex.EncodeToString(sum[:])
+}
+
+// transferFee applies the flat plus percentage fee of policy, the policy of the amount's currency
+func transferFee(policy models.FeePolicy, amount money.Amount) (money.Amount, error) {
+	if policy.FeeAccountID == 0 {
+		return money.New(0, amount.Currency), nil
+	}
+
+	percentage, err := amount.Percent(policy.Percentage)
+	if err != nil {
+		return money.Amount{}, err
+	}
+
+	return money.New(policy.Flat, amount.Currency).Add(percentage)
+}
+
+// transferCompleted is what webhooks are told about a transfer, with the amounts as decimals
+func transferCompleted(transfer models.Transaction) models.TransferCompleted {
+	return models.TransferCompleted{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Currency:      transfer.Currency,
+		Description:   transfer.Description,
+		Amount:        money.New(transfer.Amount, transfer.Currency).String(),
+		Fee:           money.New(transfer.Fee, transfer.Currency).String(),
+		ToAmount:      money.New(transfer.ToAmount, transfer.ToCurrency).String(),
+		ToCurrency:    transfer.ToCurrency,
+		ExchangeRate:  transfer.ExchangeRate,
+		ReversalOf:    transfer.ReversalOf,
+		CreatedAt:     transfer.CreatedAt,
+	}
+}
+
+// publishEntries notifies live subscribers about committed entries. The money has
+// already moved at this point, so a failure is logged rather than returned.
+func (s *serviceImpl) publishEntries(ctx context.Context, entries ...models.Entry) {
+	if s.publisher == nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if err := s.publisher.PublishEntry(ctx, entry); err != nil {
+			log.Error().Err(err).Int64("account_id", entry.AccountID).Int64("entry_id", entry.ID).Msg("failed to publish entry")
+		}
+	}
+}
+
+// notifyWebhooks queues the event for every active webhook of the owners that subscribed to it.
+// Like publishEntries it runs once the money has moved, so failures are only logged.
+func (s *serviceImpl) notifyWebhooks(ctx context.Context, eventType string, data any, owners ...string) {
+	if s.webhooks == nil {
+		return
+	}
+
+	webhooks, err := s.repo.ListSubscribedWebhooks(ctx, owners, eventType)
+	if err != nil {
+		log.Error().Err(err).Str("event", eventType).Msg("failed to list subscribed webhooks")
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	event, err := webhook.NewEvent(eventType, data)
+	if err != nil {
+		log.Error().Err(err).Str("event", eventType).Msg("failed to create webhook event")
+		return
+	}
+
+	for _, w := range webhooks {
+		if err := s.webhooks.DispatchWebhook(ctx, w.ID, event); err != nil {
+			log.Error().Err(err).Int64("webhook_id", w.ID).Str("event", eventType).Msg("failed to dispatch webhook")
+		}
+	}
+}
+
+// alertLargeTransfer queues an alert for a committed transfer above its currency's threshold,
+// a rolled back transfer never gets here. Failures are only logged, the money has moved.
+func (s *serviceImpl) alertLargeTransfer(ctx context.Context, transfer models.Transaction) {
+	if s.alerter == nil {
+		return
+	}
+
+	threshold, ok := s.alerter.LargeTransferThreshold(transfer.Currency)
+	if !ok || transfer.Amount <= threshold {
+		return
+	}
+
+	if err := s.alerter.AlertLargeTransfer(ctx, transfer); err != nil {
+		log.Error().Err(err).Int64("transaction_id", transfer.ID).Msg("failed to queue large transfer alert")
+	}
+}
+
+func (s *serviceImpl) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	return s.repo.CreateWebhook(ctx, webhook)
+}
+
+func (s *serviceImpl) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	return s.repo.GetWebhook(ctx, id)
+}
+
+func (s *serviceImpl) ListWebhooks(ctx context.Context, arg models.ListWebhooksParams) ([]models.Webhook, error) {
+	return s.repo.ListWebhooks(ctx, arg)
+}
+
+func (s *serviceImpl) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+// RecordWebhookDelivery keeps track of failed deliveries in a row, disabling the webhook after WebhookMaxFailures
+func (s *serviceImpl) RecordWebhookDelivery(ctx context.Context, id int64, succeeded bool) (models.Webhook, error) {
+	return s.repo.RecordWebhookDelivery(ctx, id, succeeded, WebhookMaxFailures)
+}
+
+func (s *serviceImpl) GetStatement(ctx context.Context, arg models.GetStatementParams) (models.Statement, error) {
+	start, end := statement.Period(arg.Year, arg.Month)
+
+	// the opening balance is derived backwards from the current one, so the statement stays
+	// correct for accounts opened with a non-zero balance. The balance and the entries since the
+	// start are read together on the primary, arg.Account may come from the cache and be stale.
+	// The rows may come from a lagging replica, which leaves out the latest ones but can't make
+	// the balances wrong, they are built on the opening one.
+	opening, err := s.repo.GetBalanceAt(ctx, arg.Account.ID, start)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	entries, err := s.repo.ListEntriesBetween(ctx, arg.Account.ID, start, end)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	transfers, err := s.repo.ListTransactionsBetween(ctx, arg.Account.ID, start, end)
+	if err != nil {
+		return models.Statement{}, err
+	}
+
+	return statement.Build(arg, opening, entries, transfers), nil
+}
+
+// GetAccountSummary adds up the entries of a month. The summary of a closed month can't change
+// anymore and is cached, the current month ends now and is always read from the database.
+func (s *serviceImpl) GetAccountSummary(ctx context.Context, arg models.GetAccountSummaryParams) (models.AccountSummary, error) {
+	start, end := statement.Period(arg.Year, arg.Month)
+
+	closed := !end.After(time.Now())
+	if closed {
+		if summary, ok := s.cache.accountSummary(ctx, arg.Account.ID, arg.Year, arg.Month); ok {
+			return summary, nil
+		}
+	} else {
+		end = time.Now()
+	}
+
+	summary, err := s.repo.SummarizeEntries(ctx, models.SummarizeEntriesParams{
+		AccountID: arg.Account.ID,
+		From:      start,
+		To:        end,
+	})
+	if err != nil {
+		return models.AccountSummary{}, err
+	}
+
+	// a month before the account was opened is empty
+	if summary.PeriodStart.After(end) {
+		summary.PeriodStart = end
+	}
+	summary.PeriodEnd = end
+	summary.Currency = arg.Account.Currency
+	summary.Year, summary.Month = arg.Year, arg.Month
+
+	if closed {
+		s.cache.storeAccountSummary(ctx, summary)
+	}
+	return summary, nil
+}
+
+func (s *serviceImpl) RequestStatementTx(ctx context.Context, arg models.RequestStatementTxParams) (models.StatementDocument, error) {
+	return s.repo.RequestStatementTx(ctx, arg)
+}
+
+func (s *serviceImpl) ListStatementDocuments(ctx context.Context, arg models.ListStatementDocumentsParams) ([]models.StatementDocument, error) {
+	return s.repo.ListStatementDocuments(ctx, arg)
+}
+
+func (s *serviceImpl) CreateAuditLog(ctx context.Context, arg models.CreateAuditLogParams) (models.AuditLog, error) {
+	return s.repo.CreateAuditLog(ctx, arg)
+}
+
+func (s *serviceImpl) ListAuditLogs(ctx context.Context, arg models.ListAuditLogsParams) ([]models.AuditLog, error) {
+	return s.repo.ListAuditLogs(ctx, arg)
+}
+
+func (s *serviceImpl) ListCurrencies(ctx context.Context) ([]models.Currency, error) {
+	return s.repo.ListCurrencies(ctx)
+}
+
+func (s *serviceImpl) AddCurrency(ctx context.Context, currency models.Currency) (models.Currency, error) {
+	return s.repo.AddCurrency(ctx, currency)
+}
+
+func (s *serviceImpl) DisableCurrency(ctx context.Context, code string) (models.Currency, error) {
+	return s.repo.DisableCurrency(ctx, code)
+}
+
+func (s *serviceImpl) GetMigrationStatus(ctx context.Context) (models.MigrationStatus, error) {
+	return s.repo.GetMigrationStatus(ctx)
+}
+
+func (s *serviceImpl) CountExportRows(ctx context.Context, arg models.ExportTransactionsParams) (int64, error) {
+	return s.repo.CountEntriesBetween(ctx, arg.Account.ID, arg.From, arg.To)
+}
+
+// ExportTransactions calls fn with the ledger rows of the export, each carrying the balance of the
+// account right after it. Like statements, the balance is derived backwards from the current one.
+func (s *serviceImpl) ExportTransactions(ctx context.Context, arg models.ExportTransactionsParams, fn func(models.LedgerRow) error) error {
+	balance, err := s.repo.GetBalanceAt(ctx, arg.Account.ID, arg.From)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.ForEachLedgerRow(ctx, arg.Account.ID, arg.From, arg.To, func(row models.LedgerRow) error {
+		balance += row.Amount
+		row.Currency, row.Balance = arg.Account.Currency, balance
+		return fn(row)
+	})
+}
+
+func (s *serviceImpl) GetBalance(ctx context.Context, arg models.GetBalanceParams) (models.Balance, error) {
+	balance := models.Balance{
+		AccountID:       arg.Account.ID,
+		Balance:         arg.Account.Balance,
+		Currency:        arg.Account.Currency,
+		DisplayCurrency: arg.Account.Currency,
+	}
+
+	if arg.DisplayCurrency == "" || arg.DisplayCurrency == arg.Account.Currency {
+		balance.ConvertedBalance = arg.Account.Balance
+		balance.Rate = 1
+		return balance, nil
+	}
+
+	balance.DisplayCurrency = arg.DisplayCurrency
+
+	// a missing rate should never hide the balance itself, so the lookup
+	// failure is only reported through the flag
+	rate, err := s.getRate(ctx, arg.Account.Currency, arg.DisplayCurrency)
+	if err != nil {
+		log.Warn().Err(err).Str("base", arg.Account.Currency).Str("quote", arg.DisplayCurrency).Msg("exchange rate unavailable")
+		balance.ConvertedUnavailable = true
+		return balance, nil
+	}
+
+	converted, err := money.New(arg.Account.Balance, arg.Account.Currency).Convert(rate.Value, arg.DisplayCurrency)
+	if err != nil {
+		return balance, err
+	}
+
+	balance.ConvertedBalance = converted.Units
+	balance.Rate = rate.Value
+	balance.RateTimestamp = rate.FetchedAt
+
+	return balance, nil
+}
+
+func (s *serviceImpl) getRate(ctx context.Context, base, quote string) (rates.Rate, error) {
+	if s.rates == nil {
+		return rates.Rate{}, rates.ErrRateUnavailable
+	}
+
+	return s.rates.GetRate(ctx, base, quote)
 }
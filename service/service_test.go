@@ -2,12 +2,20 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"github.com/zde37/Swift_Bank/helpers"
 	mockedproviders "github.com/zde37/Swift_Bank/mock"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/rates"
+	"github.com/zde37/Swift_Bank/recurrence"
+	"github.com/zde37/Swift_Bank/repository"
 	"go.uber.org/mock/gomock"
 )
 
@@ -26,7 +34,7 @@ func TestGetAccount(t *testing.T) {
 		Return(account, nil)
 
 	// create service
-	service := NewService(repo)
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
 
 	createdAccount, err := service.S.GetAccount(context.Background(), account.ID)
 	require.NoError(t, err)
@@ -35,11 +43,1448 @@ func TestGetAccount(t *testing.T) {
 
 }
 
+func TestCreateAccount(t *testing.T) {
+	account := randomAccount()
+	req := models.CreateAccountRequest{Currency: account.Currency}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(repo *mockedproviders.MockRepositoryProvider)
+		check      func(t *testing.T, created models.Account, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().GetAccountByCurrency(gomock.Any(), account.Owner, account.Currency, models.AccountChecking).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+				repo.EXPECT().CreateAccount(gomock.Any(), models.Account{Owner: account.Owner, Currency: account.Currency, Type: models.AccountChecking}).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, created models.Account, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account, created)
+			},
+		},
+		{
+			name: "Exists",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().GetAccountByCurrency(gomock.Any(), account.Owner, account.Currency, models.AccountChecking).Times(1).Return(account, nil)
+				repo.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, created models.Account, err error) {
+				var existsErr *AccountExistsError
+				require.ErrorAs(t, err, &existsErr)
+				require.Equal(t, account.ID, existsErr.AccountID)
+				require.ErrorIs(t, err, ErrAccountExists)
+				require.Contains(t, err.Error(), fmt.Sprint(account.ID))
+			},
+		},
+		{
+			// another request created the account between the check and the insert
+			name: "CreatedConcurrently",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				gomock.InOrder(
+					repo.EXPECT().GetAccountByCurrency(gomock.Any(), account.Owner, account.Currency, models.AccountChecking).Times(1).Return(models.Account{}, pgx.ErrNoRows),
+					repo.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(models.Account{}, repository.ErrAccountExists),
+					repo.EXPECT().GetAccountByCurrency(gomock.Any(), account.Owner, account.Currency, models.AccountChecking).Times(1).Return(account, nil),
+				)
+			},
+			check: func(t *testing.T, created models.Account, err error) {
+				var existsErr *AccountExistsError
+				require.ErrorAs(t, err, &existsErr)
+				require.Equal(t, account.ID, existsErr.AccountID)
+			},
+		},
+		{
+			name: "LookupError",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().GetAccountByCurrency(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(models.Account{}, pgx.ErrTxClosed)
+				repo.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, created models.Account, err error) {
+				require.ErrorIs(t, err, pgx.ErrTxClosed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			tc.buildStubs(repo)
+
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+			created, err := service.S.CreateAccount(context.Background(), req, account.Owner)
+			tc.check(t, created, err)
+		})
+	}
+}
+
+func TestCreateSavingsAccount(t *testing.T) {
+	checking := randomAccount()
+	savings := checking
+	savings.ID++
+	savings.Type = models.AccountSavings
+	req := models.CreateAccountRequest{Currency: checking.Currency, Type: models.AccountSavings}
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// the checking account in the currency doesn't stand in the way
+	gomock.InOrder(
+		repo.EXPECT().GetAccountByCurrency(gomock.Any(), checking.Owner, checking.Currency, models.AccountSavings).Times(1).Return(models.Account{}, pgx.ErrNoRows),
+		repo.EXPECT().CreateAccount(gomock.Any(), models.Account{Owner: checking.Owner, Currency: checking.Currency, Type: models.AccountSavings}).Times(1).Return(savings, nil),
+		repo.EXPECT().GetAccountByCurrency(gomock.Any(), checking.Owner, checking.Currency, models.AccountSavings).Times(1).Return(savings, nil),
+	)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+	created, err := service.S.CreateAccount(context.Background(), req, checking.Owner)
+	require.NoError(t, err)
+	require.Equal(t, savings, created)
+
+	_, err = service.S.CreateAccount(context.Background(), req, checking.Owner)
+	var existsErr *AccountExistsError
+	require.ErrorAs(t, err, &existsErr)
+	require.Equal(t, savings.ID, existsErr.AccountID)
+	require.Equal(t, fmt.Sprintf("you already have a %s savings account with id %d", savings.Currency, savings.ID), err.Error())
+}
+
 func randomAccount() models.Account {
 	return models.Account{
 		ID:       helpers.RandomInt(1, 1000),
 		Owner:    helpers.RandomOwner(),
-		Balance:  float64(helpers.RandomMoney()),
+		Balance:  helpers.RandomMoney(),
 		Currency: helpers.RandomCurrency(),
+		Type:     models.AccountChecking,
+	}
+}
+
+func TestGetStatementEmptyMonth(t *testing.T) {
+	account := randomAccount()
+	arg := models.GetStatementParams{
+		Account: account,
+		Year:    2024,
+		Month:   2,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs
+	repo.EXPECT().
+		GetBalanceAt(gomock.Any(), gomock.Eq(account.ID), gomock.Any()).
+		Times(1).
+		Return(account.Balance, nil)
+	repo.EXPECT().
+		ListEntriesBetween(gomock.Any(), gomock.Eq(account.ID), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]models.Entry{}, nil)
+	repo.EXPECT().
+		ListTransactionsBetween(gomock.Any(), gomock.Eq(account.ID), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]models.Transaction{}, nil)
+
+	// create service
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	st, err := service.S.GetStatement(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Empty(t, st.Rows)
+	require.Equal(t, account.Balance, st.OpeningBalance)
+	require.Equal(t, st.OpeningBalance, st.ClosingBalance)
+}
+
+func TestGetStatement(t *testing.T) {
+	account := randomAccount()
+	account.Balance = 100
+	arg := models.GetStatementParams{
+		Account: account,
+		Year:    2024,
+		Month:   2,
+	}
+
+	entries := []models.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 30},
+		{ID: 2, AccountID: account.ID, Amount: -10},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs: 20 was posted this month and another 5 after it ended, the cached balance
+	// in arg is stale and not used
+	arg.Account.Balance = 0
+	repo.EXPECT().
+		GetBalanceAt(gomock.Any(), gomock.Eq(account.ID), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)).
+		Times(1).
+		Return(int64(75), nil)
+	repo.EXPECT().
+		ListEntriesBetween(gomock.Any(), gomock.Eq(account.ID), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(entries, nil)
+	repo.EXPECT().
+		ListTransactionsBetween(gomock.Any(), gomock.Eq(account.ID), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]models.Transaction{}, nil)
+
+	// create service
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	st, err := service.S.GetStatement(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Len(t, st.Rows, 2)
+	require.Equal(t, int64(75), st.OpeningBalance)
+	require.Equal(t, int64(95), st.ClosingBalance)
+}
+
+func TestGetBalance(t *testing.T) {
+	account := randomAccount()
+	account.Balance = 10000
+	account.Currency = helpers.USD
+
+	fetchedAt := time.Now().Add(-time.Minute).UTC()
+	provider := rates.NewStaticProvider(rates.Rate{Base: helpers.USD, Quote: helpers.EUR, Value: 0.9, FetchedAt: fetchedAt})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+
+	// create service
+	service := NewService(repo, nil, provider, nil, nil, nil, 0)
+
+	// same currency
+	balance, err := service.S.GetBalance(context.Background(), models.GetBalanceParams{Account: account})
+	require.NoError(t, err)
+	require.Equal(t, helpers.USD, balance.DisplayCurrency)
+	require.Equal(t, account.Balance, balance.ConvertedBalance)
+	require.False(t, balance.ConvertedUnavailable)
+
+	// converted
+	balance, err = service.S.GetBalance(context.Background(), models.GetBalanceParams{Account: account, DisplayCurrency: helpers.EUR})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance.Balance)
+	require.Equal(t, int64(9000), balance.ConvertedBalance)
+	require.Equal(t, 0.9, balance.Rate)
+	require.Equal(t, fetchedAt, balance.RateTimestamp)
+	require.False(t, balance.ConvertedUnavailable)
+
+	// no rate for the pair, the raw balance is still returned
+	balance, err = service.S.GetBalance(context.Background(), models.GetBalanceParams{Account: account, DisplayCurrency: helpers.CAD})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance.Balance)
+	require.Equal(t, helpers.CAD, balance.DisplayCurrency)
+	require.True(t, balance.ConvertedUnavailable)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 10000
+	fromAccount.Currency = helpers.USD
+	toAccount := randomAccount()
+	toAccount.Currency = helpers.EUR
+
+	arg := models.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        3333,
+		Currency:      helpers.USD,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs
+	stubTransferAccounts(repo, fromAccount, toAccount)
+	repo.EXPECT().
+		GetExchangeRate(gomock.Any(), gomock.Eq(helpers.USD), gomock.Eq(helpers.EUR)).
+		Times(1).
+		Return(models.ExchangeRate{Base: helpers.USD, Quote: helpers.EUR, Rate: 0.915, FetchedAt: time.Now()}, nil)
+
+	// 33.33 * 0.915 = 30.49695 is credited as 30.50 EUR
+	expected := arg
+	expected.ToAmount = 3050
+	expected.ToCurrency = helpers.EUR
+	expected.ExchangeRate = 0.915
+	repo.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(expected)).
+		Times(1).
+		Return(models.TransferTxResult{}, nil)
+
+	// create service
+	service := NewService(repo, nil, rates.NewStoreProvider(repo, time.Hour), nil, nil, nil, 0)
+
+	_, err := service.S.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestTransferTxSameCurrency(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 100
+	toAccount := randomAccount()
+	toAccount.Currency = fromAccount.Currency
+	arg := models.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Currency:      toAccount.Currency,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs
+	stubTransferAccounts(repo, fromAccount, toAccount)
+	repo.EXPECT().
+		GetExchangeRate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	expected := arg
+	expected.ToAmount = arg.Amount
+	expected.ToCurrency = arg.Currency
+	expected.ExchangeRate = 1
+	repo.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(expected)).
+		Times(1).
+		Return(models.TransferTxResult{}, nil)
+
+	// create service
+	service := NewService(repo, nil, rates.NewStoreProvider(repo, time.Hour), nil, nil, nil, 0)
+
+	_, err := service.S.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestTransferTxStaleRate(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 100
+	fromAccount.Currency = helpers.USD
+	toAccount := randomAccount()
+	toAccount.Currency = helpers.CAD
+	arg := models.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Currency:      helpers.USD,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs
+	stubTransferAccounts(repo, fromAccount, toAccount)
+	repo.EXPECT().
+		GetExchangeRate(gomock.Any(), gomock.Eq(helpers.USD), gomock.Eq(helpers.CAD)).
+		Times(1).
+		Return(models.ExchangeRate{Base: helpers.USD, Quote: helpers.CAD, Rate: 1.3, FetchedAt: time.Now().Add(-2 * time.Hour)}, nil)
+	repo.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// create service
+	service := NewService(repo, nil, rates.NewStoreProvider(repo, time.Hour), nil, nil, nil, 0)
+
+	_, err := service.S.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, rates.ErrRateStale)
+}
+
+func TestTransferTxCurrencyDisabled(t *testing.T) {
+	// CAD is disabled, its accounts keep their balances but take no transfers
+	helpers.SetSupportedCurrencies([]string{helpers.USD, helpers.EUR})
+	t.Cleanup(func() { helpers.SetSupportedCurrencies([]string{helpers.USD, helpers.EUR, helpers.CAD}) })
+
+	fromAccount := randomAccount()
+	fromAccount.Balance = 100
+	fromAccount.Currency = helpers.USD
+	toAccount := randomAccount()
+	toAccount.Currency = helpers.CAD
+	arg := models.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Currency:      helpers.USD,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	// build stubs
+	repo.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+		Times(1).
+		Return(fromAccount, nil)
+	repo.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+		Times(1).
+		Return(toAccount, nil)
+	repo.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// create service
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	_, err := service.S.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrCurrencyDisabled)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Currency = helpers.USD
+	toAccount := randomAccount()
+	toAccount.Currency = helpers.USD
+	policy := models.FeePolicy{Currency: helpers.USD, Flat: 100, Percentage: 1.5, FeeAccountID: helpers.RandomInt(1001, 2000)}
+
+	testCases := []struct {
+		name      string
+		balance   int64
+		expectErr error
+	}{
+		{
+			name:    "EXACTLY COVERS FEE",
+			balance: 10250, // 100 + 1 + 1.5% of 100
+		},
+		{
+			name:      "INSUFFICIENT FUNDS FOR FEE",
+			balance:   10249,
+			expectErr: ErrInsufficientFunds,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			from := fromAccount
+			from.Balance = tc.balance
+			arg := models.TransferTxParams{
+				FromAccountID: from.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10000,
+				Currency:      helpers.USD,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			repo.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(from.ID)).
+				Times(1).
+				Return(from, nil)
+			repo.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+			repo.EXPECT().
+				GetFeePolicy(gomock.Any(), gomock.Eq(helpers.USD)).
+				Times(1).
+				Return(policy, nil)
+
+			expected := arg
+			expected.Fee = 250
+			expected.FeeAccountID = policy.FeeAccountID
+			expected.ToAmount = arg.Amount
+			expected.ToCurrency = helpers.USD
+			expected.ExchangeRate = 1
+
+			times := 1
+			if tc.expectErr != nil {
+				times = 0
+			}
+			repo.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(expected)).
+				Times(times).
+				Return(models.TransferTxResult{}, nil)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			_, err := service.S.TransferTx(context.Background(), arg)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// stubTransferAccounts expects the account lookups of a transfer without a fee policy
+func stubTransferAccounts(repo *mockedproviders.MockRepositoryProvider, fromAccount, toAccount models.Account) {
+	repo.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+		Times(1).
+		Return(fromAccount, nil)
+	repo.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+		Times(1).
+		Return(toAccount, nil)
+	repo.EXPECT().
+		GetFeePolicy(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.FeePolicy{}, pgx.ErrNoRows)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 1010
+	fromAccount.OverdraftLimit = 5020
+	toAccount := randomAccount()
+	toAccount.Currency = fromAccount.Currency
+
+	testCases := []struct {
+		name      string
+		amount    int64
+		expectErr error
+	}{
+		{
+			name:   "EXACTLY AT LIMIT",
+			amount: 6030,
+		},
+		{
+			name:      "ONE CENT OVER LIMIT",
+			amount:    6031,
+			expectErr: ErrInsufficientFunds,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			arg := models.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        tc.amount,
+				Currency:      fromAccount.Currency,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			stubTransferAccounts(repo, fromAccount, toAccount)
+
+			times := 1
+			if tc.expectErr != nil {
+				times = 0
+			}
+			repo.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(times).
+				Return(models.TransferTxResult{}, nil)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			_, err := service.S.TransferTx(context.Background(), arg)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTransferTxSavingsLimit(t *testing.T) {
+	toAccount := randomAccount()
+
+	testCases := []struct {
+		name      string
+		limit     int
+		repoErr   error
+		expectErr error
+	}{
+		{name: "UNDER LIMIT", limit: 6},
+		{name: "AT LIMIT", limit: 6, repoErr: fmt.Errorf("%w: savings accounts can make 6 a month", ErrTransferLimitReached), expectErr: ErrTransferLimitReached},
+		{name: "NO LIMIT"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fromAccount := randomAccount()
+			fromAccount.ID = toAccount.ID + 1
+			fromAccount.Type = models.AccountSavings
+			fromAccount.Balance = 1000
+			fromAccount.Currency = toAccount.Currency
+			arg := models.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10,
+				Currency:      fromAccount.Currency,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			repo.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			repo.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			repo.EXPECT().GetFeePolicy(gomock.Any(), gomock.Any()).Times(1).Return(models.FeePolicy{}, pgx.ErrNoRows)
+			// the limit is counted by the repository against the locked row
+			repo.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, got models.TransferTxParams) (models.TransferTxResult, error) {
+					require.Equal(t, tc.limit, got.SavingsTransferLimit)
+					return models.TransferTxResult{}, tc.repoErr
+				})
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, tc.limit)
+
+			_, err := service.S.TransferTx(context.Background(), arg)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				require.Contains(t, err.Error(), "6 a month")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSetOverdraftLimitSavings(t *testing.T) {
+	account := randomAccount()
+	account.Type = models.AccountSavings
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+	repo.EXPECT().SetOverdraftLimitTx(gomock.Any(), gomock.Any()).Times(0)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	_, err := service.S.SetOverdraftLimitTx(context.Background(), models.SetOverdraftLimitParams{AccountID: account.ID, Limit: 5000, ChangedBy: "banker"})
+	require.ErrorIs(t, err, ErrSavingsOverdraft)
+
+	// taking a limit away needs no lookup, there is nothing to refuse
+	arg := models.SetOverdraftLimitParams{AccountID: account.ID, ChangedBy: "banker"}
+	repo.EXPECT().SetOverdraftLimitTx(gomock.Any(), arg).Times(1).Return(account, nil)
+
+	_, err = service.S.SetOverdraftLimitTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestAccrueInterest(t *testing.T) {
+	account := randomAccount()
+	account.Type = models.AccountSavings
+	account.Balance = 5000000
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	want := models.InterestAccrual{
+		AccountID:   account.ID,
+		AccrualDate: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC),
+		Balance:     1277500, // a day at 1% is 35 cents exactly
+		Rate:        1,
+		Amount:      35,
+	}
+	// the interest is earned on the balance the day closed with, not the listed one
+	endOfDay := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	repo.EXPECT().GetBalanceAt(gomock.Any(), account.ID, endOfDay).Times(2).Return(want.Balance, nil)
+	repo.EXPECT().AccrueInterest(gomock.Any(), want).Times(1).Return(want, nil)
+
+	// whatever the time of day, the accrual is for the UTC day
+	arg := models.AccrueInterestParams{Account: account, Date: time.Date(2024, 3, 14, 23, 30, 0, 0, time.UTC), Rate: 1}
+	accrual, err := service.S.AccrueInterest(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, want, accrual)
+
+	// the day was taken by the first run
+	repo.EXPECT().AccrueInterest(gomock.Any(), want).Times(1).Return(models.InterestAccrual{}, pgx.ErrNoRows)
+	_, err = service.S.AccrueInterest(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyAccrued)
+
+	// a day that closed without money earns nothing
+	repo.EXPECT().GetBalanceAt(gomock.Any(), account.ID, endOfDay).Times(1).Return(int64(0), nil)
+	repo.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(0)
+	_, err = service.S.AccrueInterest(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNoInterestDue)
+
+	// a half cent goes to the even one
+	repo.EXPECT().GetBalanceAt(gomock.Any(), account.ID, endOfDay).Times(1).Return(int64(18250), nil)
+	repo.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, accrual models.InterestAccrual) (models.InterestAccrual, error) {
+			require.Zero(t, accrual.Amount)
+			return accrual, nil
+		})
+	_, err = service.S.AccrueInterest(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestPostAccruedInterest(t *testing.T) {
+	account := randomAccount()
+	account.Type = models.AccountSavings
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	before := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	entry := models.Entry{ID: 9, AccountID: account.ID, Amount: 512, Category: models.CategoryInterest}
+	repo.EXPECT().PostAccruedInterest(gomock.Any(), account.ID, before).Times(1).Return(account, entry, nil)
+
+	paid, err := service.S.PostAccruedInterest(context.Background(), account.ID, before)
+	require.NoError(t, err)
+	require.Equal(t, account, paid)
+
+	repo.EXPECT().PostAccruedInterest(gomock.Any(), account.ID, before).Times(1).Return(models.Account{}, models.Entry{}, pgx.ErrNoRows)
+	_, err = service.S.PostAccruedInterest(context.Background(), account.ID, before)
+	require.ErrorIs(t, err, ErrNoAccruedInterest)
+}
+
+func TestTransferTxIdempotency(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 100
+	toAccount := randomAccount()
+	toAccount.Currency = fromAccount.Currency
+
+	arg := models.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Currency:      fromAccount.Currency,
+		Idempotency:   &models.IdempotencyKey{UserName: fromAccount.Owner, Key: helpers.RandomString(16)},
+	}
+
+	firstResult := models.TransferTxResult{
+		Transaction: models.Transaction{ID: helpers.RandomInt(1, 1000), Amount: 10},
+		FromAccount: fromAccount,
+	}
+	response, err := json.Marshal(firstResult)
+	require.NoError(t, err)
+
+	stored := models.IdempotencyKey{
+		UserName:    fromAccount.Owner,
+		Key:         arg.Idempotency.Key,
+		RequestHash: transferRequestHash(arg),
+		Response:    response,
+	}
+
+	otherRequest := stored
+	otherRequest.RequestHash = "different"
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockedproviders.MockRepositoryProvider)
+		checkResponse func(t *testing.T, result models.TransferTxResult, err error)
+	}{
+		{
+			name: "FIRST REQUEST",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(fromAccount.Owner), gomock.Eq(stored.Key)).
+					Times(1).
+					Return(models.IdempotencyKey{}, pgx.ErrNoRows)
+				stubTransferAccounts(repo, fromAccount, toAccount)
+				repo.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, got models.TransferTxParams) (models.TransferTxResult, error) {
+						require.NotNil(t, got.Idempotency)
+						require.Equal(t, stored.RequestHash, got.Idempotency.RequestHash)
+						require.WithinDuration(t, time.Now().Add(IdempotencyKeyTTL), got.Idempotency.ExpiresAt, time.Minute)
+						return firstResult, nil
+					})
+			},
+			checkResponse: func(t *testing.T, result models.TransferTxResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, firstResult.Transaction.ID, result.Transaction.ID)
+			},
+		},
+		{
+			name: "REPLAYED",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(fromAccount.Owner), gomock.Eq(stored.Key)).
+					Times(1).
+					Return(stored, nil)
+				repo.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, result models.TransferTxResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, firstResult.Transaction.ID, result.Transaction.ID)
+			},
+		},
+		{
+			name: "DIFFERENT PAYLOAD",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(otherRequest, nil)
+				repo.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, result models.TransferTxResult, err error) {
+				require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+			},
+		},
+		{
+			name: "CONCURRENT DUPLICATE",
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				gomock.InOrder(
+					repo.EXPECT().
+						GetIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(models.IdempotencyKey{}, pgx.ErrNoRows),
+					repo.EXPECT().
+						GetIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(stored, nil),
+				)
+				stubTransferAccounts(repo, fromAccount, toAccount)
+				repo.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.TransferTxResult{}, repository.ErrDuplicateIdempotencyKey)
+			},
+			checkResponse: func(t *testing.T, result models.TransferTxResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, firstResult.Transaction.ID, result.Transaction.ID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			tc.buildStubs(repo)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			result, err := service.S.TransferTx(context.Background(), arg)
+			tc.checkResponse(t, result, err)
+		})
+	}
+}
+
+func TestExecuteScheduledTransfer(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 50
+	toAccount := randomAccount()
+	toAccount.Currency = fromAccount.Currency
+
+	scheduled := models.ScheduledTransfer{
+		ID:            helpers.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Currency:      fromAccount.Currency,
+		Status:        models.ScheduledTransferExecuting,
+		CreatedBy:     fromAccount.Owner,
+	}
+
+	testCases := []struct {
+		name       string
+		amount     int64
+		buildStubs func(repo *mockedproviders.MockRepositoryProvider, transfer models.ScheduledTransfer)
+		check      func(t *testing.T, transfer models.ScheduledTransfer, err error)
+	}{
+		{
+			name:   "EXECUTED",
+			amount: 20,
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider, transfer models.ScheduledTransfer) {
+				repo.EXPECT().ClaimScheduledTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(transfer.CreatedBy), gomock.Eq(fmt.Sprintf("scheduled-%d", transfer.ID))).
+					Times(1).
+					Return(models.IdempotencyKey{}, pgx.ErrNoRows)
+				stubTransferAccounts(repo, fromAccount, toAccount)
+				repo.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(models.TransferTxResult{Transaction: models.Transaction{ID: 42}}, nil)
+				repo.EXPECT().
+					FinishScheduledTransfer(gomock.Any(), gomock.Eq(models.FinishScheduledTransferParams{
+						ID:            transfer.ID,
+						Status:        models.ScheduledTransferExecuted,
+						TransactionID: 42,
+					})).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg models.FinishScheduledTransferParams) (models.ScheduledTransfer, error) {
+						transfer.Status, transfer.TransactionID = arg.Status, arg.TransactionID
+						return transfer, nil
+					})
+			},
+			check: func(t *testing.T, transfer models.ScheduledTransfer, err error) {
+				require.NoError(t, err)
+				require.Equal(t, models.ScheduledTransferExecuted, transfer.Status)
+				require.Equal(t, int64(42), transfer.TransactionID)
+			},
+		},
+		{
+			name:   "INSUFFICIENT FUNDS AT EXECUTION",
+			amount: 80,
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider, transfer models.ScheduledTransfer) {
+				repo.EXPECT().ClaimScheduledTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(models.IdempotencyKey{}, pgx.ErrNoRows)
+				stubTransferAccounts(repo, fromAccount, toAccount)
+				repo.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().
+					FinishScheduledTransfer(gomock.Any(), gomock.Eq(models.FinishScheduledTransferParams{
+						ID:            transfer.ID,
+						Status:        models.ScheduledTransferFailed,
+						FailureReason: ErrInsufficientFunds.Error(),
+					})).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg models.FinishScheduledTransferParams) (models.ScheduledTransfer, error) {
+						transfer.Status, transfer.FailureReason = arg.Status, arg.FailureReason
+						return transfer, nil
+					})
+			},
+			check: func(t *testing.T, transfer models.ScheduledTransfer, err error) {
+				require.NoError(t, err)
+				require.Equal(t, models.ScheduledTransferFailed, transfer.Status)
+				require.Equal(t, ErrInsufficientFunds.Error(), transfer.FailureReason)
+			},
+		},
+		{
+			name:   "ALREADY CANCELED",
+			amount: 20,
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider, transfer models.ScheduledTransfer) {
+				repo.EXPECT().ClaimScheduledTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(models.ScheduledTransfer{}, pgx.ErrNoRows)
+				repo.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().FinishScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, transfer models.ScheduledTransfer, err error) {
+				require.ErrorIs(t, err, ErrScheduledTransferDone)
+			},
+		},
+		{
+			name:   "TRANSIENT ERROR IS RETRIED",
+			amount: 20,
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider, transfer models.ScheduledTransfer) {
+				repo.EXPECT().ClaimScheduledTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(models.IdempotencyKey{}, pgx.ErrNoRows)
+				stubTransferAccounts(repo, fromAccount, toAccount)
+				repo.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(models.TransferTxResult{}, pgx.ErrTxClosed)
+				repo.EXPECT().FinishScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, transfer models.ScheduledTransfer, err error) {
+				require.ErrorIs(t, err, pgx.ErrTxClosed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transfer := scheduled
+			transfer.Amount = tc.amount
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			tc.buildStubs(repo, transfer)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			result, err := service.S.ExecuteScheduledTransfer(context.Background(), transfer.ID)
+			tc.check(t, result, err)
+		})
+	}
+}
+
+func TestCancelScheduledTransferAfterExecutionStarted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().
+		CancelScheduledTransfer(gomock.Any(), gomock.Eq(int64(7))).
+		Times(1).
+		Return(models.ScheduledTransfer{}, pgx.ErrNoRows)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	_, err := service.S.CancelScheduledTransfer(context.Background(), 7)
+	require.ErrorIs(t, err, ErrScheduledTransferDone)
+}
+
+func TestExecuteStandingOrder(t *testing.T) {
+	fromAccount := randomAccount()
+	fromAccount.Balance = 50
+	toAccount := randomAccount()
+	toAccount.Currency = fromAccount.Currency
+
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+	order := models.StandingOrder{
+		ID:            helpers.RandomInt(1, 1000),
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Currency:      fromAccount.Currency,
+		Frequency:     recurrence.Monthly,
+		StartDate:     start,
+		NextRunAt:     start,
+		Status:        models.StandingOrderActive,
+	}
+
+	testCases := []struct {
+		name     string
+		amount   int64
+		endDate  *time.Time
+		expected models.RecordStandingOrderRunParams
+	}{
+		{
+			name:   "EXECUTED",
+			amount: 20,
+			expected: models.RecordStandingOrderRunParams{
+				Execution: models.StandingOrderExecution{
+					StandingOrderID: order.ID,
+					RunAt:           start,
+					Status:          models.StandingOrderRunExecuted,
+					TransactionID:   42,
+				},
+				NextRunAt:   endDate, // no 31st in February
+				MaxFailures: StandingOrderMaxFailures,
+			},
+		},
+		{
+			name:   "INSUFFICIENT FUNDS",
+			amount: 80,
+			expected: models.RecordStandingOrderRunParams{
+				Execution: models.StandingOrderExecution{
+					StandingOrderID: order.ID,
+					RunAt:           start,
+					Status:          models.StandingOrderRunFailed,
+					FailureReason:   ErrInsufficientFunds.Error(),
+				},
+				NextRunAt:   endDate,
+				MaxFailures: StandingOrderMaxFailures,
+			},
+		},
+		{
+			name:    "LAST RUN",
+			amount:  20,
+			endDate: &start,
+			expected: models.RecordStandingOrderRunParams{
+				Execution: models.StandingOrderExecution{
+					StandingOrderID: order.ID,
+					RunAt:           start,
+					Status:          models.StandingOrderRunExecuted,
+					TransactionID:   42,
+				},
+				NextRunAt:   endDate,
+				Completed:   true,
+				MaxFailures: StandingOrderMaxFailures,
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			arg := order
+			arg.Amount = tc.amount
+			arg.EndDate = tc.endDate
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			repo.EXPECT().
+				GetIdempotencyKey(gomock.Any(), gomock.Eq(order.Owner), gomock.Eq(fmt.Sprintf("standing-order-%d-%d", order.ID, start.Unix()))).
+				Times(1).
+				Return(models.IdempotencyKey{}, pgx.ErrNoRows)
+			stubTransferAccounts(repo, fromAccount, toAccount)
+
+			times := 1
+			if tc.expected.Execution.Status == models.StandingOrderRunFailed {
+				times = 0
+			}
+			repo.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(times).
+				Return(models.TransferTxResult{Transaction: models.Transaction{ID: 42}}, nil)
+			repo.EXPECT().
+				RecordStandingOrderRunTx(gomock.Any(), gomock.Eq(tc.expected)).
+				Times(1).
+				Return(arg, nil)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			_, err := service.S.ExecuteStandingOrder(context.Background(), arg)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestResumeStandingOrderSkipsMissedRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -10)
+	order := models.StandingOrder{
+		ID:        helpers.RandomInt(1, 1000),
+		Frequency: recurrence.Daily,
+		StartDate: start,
+		NextRunAt: start.AddDate(0, 0, 2), // paused eight days ago
+		Status:    models.StandingOrderPaused,
+	}
+	tomorrow := start.AddDate(0, 0, 11)
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+	repo.EXPECT().
+		ResumeStandingOrder(gomock.Any(), gomock.Eq(order.ID), gomock.Eq(tomorrow)).
+		Times(1).
+		Return(models.StandingOrder{}, pgx.ErrNoRows)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	// a concurrent resume got there first
+	_, err := service.S.ResumeStandingOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, ErrStandingOrderStatus)
+}
+
+func TestReverseTransfer(t *testing.T) {
+	original := models.Transaction{
+		ID:            helpers.RandomInt(1, 1000),
+		FromAccountID: helpers.RandomInt(1, 1000),
+		ToAccountID:   helpers.RandomInt(1001, 2000),
+		Amount:        100,
+		Currency:      helpers.USD,
+		ToAmount:      92,
+		ToCurrency:    helpers.EUR,
+		ExchangeRate:  0.92,
+	}
+
+	testCases := []struct {
+		name       string
+		original   func() models.Transaction
+		buildStubs func(repo *mockedproviders.MockRepositoryProvider)
+		expectErr  error
+	}{
+		{
+			name:     "OK",
+			original: func() models.Transaction { return original },
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(models.ReverseTransferTxParams{
+						Transfer: models.TransferTxParams{
+							FromAccountID: original.ToAccountID,
+							ToAccountID:   original.FromAccountID,
+							Amount:        92,
+							Currency:      helpers.EUR,
+							Description:   fmt.Sprintf("Reversal of transfer %d", original.ID),
+							ToAmount:      100,
+							ToCurrency:    helpers.USD,
+							ExchangeRate:  1 / 0.92,
+							ReversalOf:    original.ID,
+						},
+						ReversedBy: "banker",
+						Reason:     "fraud",
+					})).
+					Times(1).
+					Return(models.TransferTxResult{Transaction: models.Transaction{ID: original.ID + 1, ReversalOf: original.ID}}, nil)
+			},
+		},
+		{
+			name: "ALREADY REVERSED",
+			original: func() models.Transaction {
+				reversed := original
+				reversed.ReversedBy = original.ID + 1
+				return reversed
+			},
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectErr: ErrTransferAlreadyReversed,
+		},
+		{
+			name: "REVERSAL OF A REVERSAL",
+			original: func() models.Transaction {
+				reversal := original
+				reversal.ReversalOf = original.ID - 1
+				return reversal
+			},
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectErr: ErrReverseReversal,
+		},
+		{
+			name:     "NOT COVERED",
+			original: func() models.Transaction { return original },
+			buildStubs: func(repo *mockedproviders.MockRepositoryProvider) {
+				repo.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.TransferTxResult{}, repository.ErrReversalNotCovered)
+			},
+			expectErr: ErrReversalNotCovered,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			// build stubs
+			repo.EXPECT().GetTransaction(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(tc.original(), nil)
+			tc.buildStubs(repo)
+
+			// create service
+			service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+			result, err := service.S.ReverseTransfer(context.Background(), models.ReverseTransferParams{
+				TransactionID: original.ID,
+				ReversedBy:    "banker",
+				Reason:        "fraud",
+			})
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, result.Reversal.Transaction.ID, result.Original.ReversedBy)
+			require.Equal(t, original.ID, result.Reversal.Transaction.ReversalOf)
+		})
+	}
+}
+
+func TestAddBeneficiaryUnknownAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(7))).Times(1).Return(models.Account{}, pgx.ErrNoRows)
+	repo.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	_, err := service.S.AddBeneficiary(context.Background(), models.Beneficiary{
+		Owner:     helpers.RandomOwner(),
+		AccountID: 7,
+		Nickname:  "landlord",
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+// recordingDispatcher keeps the webhook deliveries the service queued
+type recordingDispatcher struct {
+	deliveries map[int64]models.WebhookEvent
+}
+
+func (d *recordingDispatcher) DispatchWebhook(ctx context.Context, webhookID int64, event models.WebhookEvent) error {
+	d.deliveries[webhookID] = event
+	return nil
+}
+
+func TestAddAccountBalanceNotifiesWebhooks(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount int64
+		event  string
+	}{
+		{name: "Deposit", amount: 50, event: models.WebhookEventDepositCompleted},
+		{name: "Withdrawal", amount: -50, event: models.WebhookEventWithdrawalCompleted},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			account := randomAccount()
+			webhook := models.Webhook{ID: helpers.RandomInt(1, 1000), Owner: account.Owner, Active: true}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			repo.EXPECT().
+				AddAccountBalance(gomock.Any(), gomock.Eq(models.AddAccountBalanceParams{AccountID: account.ID, Amount: tc.amount})).
+				Times(1).
+				Return(account, nil)
+			repo.EXPECT().
+				ListSubscribedWebhooks(gomock.Any(), gomock.Eq([]string{account.Owner}), gomock.Eq(tc.event)).
+				Times(1).
+				Return([]models.Webhook{webhook}, nil)
+
+			dispatcher := &recordingDispatcher{deliveries: map[int64]models.WebhookEvent{}}
+			service := NewService(repo, nil, nil, dispatcher, nil, nil, 0)
+
+			_, err := service.S.AddAccountBalance(context.Background(), models.AddAccountBalanceParams{AccountID: account.ID, Amount: tc.amount})
+			require.NoError(t, err)
+
+			require.Len(t, dispatcher.deliveries, 1)
+			event := dispatcher.deliveries[webhook.ID]
+			require.Equal(t, tc.event, event.Type)
+
+			var change models.BalanceChange
+			require.NoError(t, json.Unmarshal(event.Data, &change))
+			require.Equal(t, account.ID, change.AccountID)
+			require.Equal(t, money.New(tc.amount, account.Currency).String(), change.Amount)
+		})
+	}
+}
+
+func TestTransferTxNotifiesBothOwners(t *testing.T) {
+	from := randomAccount()
+	to := randomAccount()
+	to.Currency = from.Currency
+	from.Balance = 1000
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	stubTransferAccounts(repo, from, to)
+	repo.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.TransferTxResult{Transaction: models.Transaction{ID: 7, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1050, Currency: from.Currency}}, nil)
+	repo.EXPECT().
+		ListSubscribedWebhooks(gomock.Any(), gomock.Eq([]string{from.Owner, to.Owner}), gomock.Eq(models.WebhookEventTransferCompleted)).
+		Times(1).
+		Return([]models.Webhook{{ID: 1, Owner: from.Owner}, {ID: 2, Owner: to.Owner}}, nil)
+
+	dispatcher := &recordingDispatcher{deliveries: map[int64]models.WebhookEvent{}}
+	service := NewService(repo, nil, nil, dispatcher, nil, nil, 0)
+
+	_, err := service.S.TransferTx(context.Background(), models.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, dispatcher.deliveries, 2)
+	require.Equal(t, dispatcher.deliveries[1].ID, dispatcher.deliveries[2].ID)
+
+	var transaction models.TransferCompleted
+	require.NoError(t, json.Unmarshal(dispatcher.deliveries[1].Data, &transaction))
+	require.Equal(t, int64(7), transaction.ID)
+	require.Equal(t, "10.50", transaction.Amount)
+}
+
+func TestGetNotificationPreferencesDefaultsToEnabled(t *testing.T) {
+	username := helpers.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(models.NotificationPreferences{}, pgx.ErrNoRows)
+
+	service := NewService(repo, nil, nil, nil, nil, nil, 0)
+
+	preferences, err := service.S.GetNotificationPreferences(context.Background(), username)
+	require.NoError(t, err)
+	require.Equal(t, username, preferences.Username)
+	require.True(t, preferences.Allows(models.NotificationTransfers))
+	require.True(t, preferences.Allows(models.NotificationLogins))
+	require.True(t, preferences.Allows(models.NotificationStatements))
+}
+
+// recordingAlerter alerts on USD transfers above 100.00
+type recordingAlerter struct {
+	alerted []models.Transaction
+}
+
+func (a *recordingAlerter) LargeTransferThreshold(currency string) (int64, bool) {
+	return 10000, currency == helpers.USD
+}
+
+func (a *recordingAlerter) AlertLargeTransfer(ctx context.Context, transfer models.Transaction) error {
+	a.alerted = append(a.alerted, transfer)
+	return nil
+}
+
+func TestTransferTxAlertsAboveThreshold(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   int64
+		currency string
+		alerted  bool
+	}{
+		{name: "AboveThreshold", amount: 10001, currency: helpers.USD, alerted: true},
+		{name: "AtThreshold", amount: 10000, currency: helpers.USD},
+		{name: "NoThresholdForCurrency", amount: 500000, currency: helpers.EUR},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			from := randomAccount()
+			from.Currency, from.Balance = tc.currency, 1000000
+			to := randomAccount()
+			to.Currency = tc.currency
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			stubTransferAccounts(repo, from, to)
+			repo.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, arg models.TransferTxParams) (models.TransferTxResult, error) {
+					return models.TransferTxResult{Transaction: models.Transaction{ID: 1, Amount: arg.Amount, Currency: arg.Currency}}, nil
+				})
+
+			alerter := &recordingAlerter{}
+			service := NewService(repo, nil, nil, nil, alerter, nil, 0)
+
+			_, err := service.S.TransferTx(context.Background(), models.TransferTxParams{
+				FromAccountID: from.ID,
+				ToAccountID:   to.ID,
+				Amount:        tc.amount,
+				Currency:      tc.currency,
+			})
+			require.NoError(t, err)
+
+			if tc.alerted {
+				require.Len(t, alerter.alerted, 1)
+				return
+			}
+			require.Empty(t, alerter.alerted)
+		})
 	}
 }
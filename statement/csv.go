@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+const dateLayout = "2006-01-02"
+
+var csvHeader = []string{"date", "description", "entry_id", "amount", "balance"}
+
+// WriteCSV writes the statement to w as CSV. The opening and closing balances
+// are written as the first and last rows so the file balances on its own.
+func WriteCSV(w io.Writer, st models.Statement) error {
+	cw := csv.NewWriter(w)
+	start, end := Period(st.Year, st.Month)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	opening := []string{start.Format(dateLayout), "opening balance", "", "", money.New(st.OpeningBalance, st.Account.Currency).String()}
+	if err := cw.Write(opening); err != nil {
+		return err
+	}
+
+	// the descriptions start with the kind of the entry, so a memo never starts a cell that a
+	// spreadsheet app would evaluate
+	descriptions := describer(st)
+	for _, row := range st.Rows {
+		record := []string{
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			descriptions(row),
+			strconv.FormatInt(row.EntryID, 10),
+			money.New(row.Amount, st.Account.Currency).String(),
+			money.New(row.Balance, st.Account.Currency).String(),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	closing := []string{end.AddDate(0, 0, -1).Format(dateLayout), "closing balance", "", "", money.New(st.ClosingBalance, st.Account.Currency).String()}
+	if err := cw.Write(closing); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
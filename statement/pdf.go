@@ -0,0 +1,230 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/pdf"
+)
+
+// layout of the PDF statement, in points
+const (
+	margin        = 50.0
+	rowHeight     = 16.0
+	fontSize      = 9.0
+	firstTableTop = pdf.A4Height - 250 // below the header, holder and summary
+	nextTableTop  = pdf.A4Height - 100 // below the short header of the following pages
+	tableBottom   = 70.0               // above the footer
+
+	dateColumn        = margin + 6
+	descriptionColumn = margin + 80
+	amountRight       = pdf.A4Width - margin - 96
+	balanceRight      = pdf.A4Width - margin - 6
+	descriptionWidth  = amountRight - 90 - descriptionColumn
+)
+
+var (
+	brand = pdf.Color{R: 0.05, G: 0.33, B: 0.64}
+	muted = pdf.Color{R: 0.4, G: 0.4, B: 0.4}
+	shade = pdf.Color{R: 0.93, G: 0.94, B: 0.96}
+)
+
+// WritePDF renders the statement as a PDF for holder, the full name of the account owner.
+// The transaction table continues over as many pages as it needs, each repeating its header.
+func WritePDF(w io.Writer, st models.Statement, holder string) error {
+	start, _ := Period(st.Year, st.Month)
+	period := start.Format("January 2006")
+	doc := pdf.New(fmt.Sprintf("Swift Bank statement %s, account %d", period, st.Account.ID))
+
+	pages := paginate(st.Rows)
+	for i, rows := range pages {
+		page := doc.AddPage()
+
+		top := nextTableTop
+		if i == 0 {
+			top = firstTableTop
+			drawHeader(page, st, holder, period)
+		} else {
+			drawLogo(page, pdf.A4Height-margin-24, 24)
+			page.SetFill(pdf.Black)
+			page.Text(margin+32, pdf.A4Height-margin-16, pdf.Bold, 11, fmt.Sprintf("Statement %s, account %d", period, st.Account.ID))
+		}
+
+		drawTable(page, top, st, rows)
+
+		page.SetFill(muted)
+		page.Text(margin, 40, pdf.Regular, 8, fmt.Sprintf("Amounts in %s", st.Account.Currency))
+		page.TextRight(pdf.A4Width-margin, 40, pdf.Regular, 8, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// paginate splits rows into pages, the first of which has less room. There is always at least
+// one page, even without rows.
+func paginate(rows []models.StatementRow) [][]models.StatementRow {
+	perPage := func(top float64) int {
+		return int((top - rowHeight - tableBottom) / rowHeight) // less the table header
+	}
+
+	pages := [][]models.StatementRow{}
+	size := perPage(firstTableTop)
+	for len(rows) > size {
+		pages = append(pages, rows[:size])
+		rows = rows[size:]
+		size = perPage(nextTableTop)
+	}
+
+	return append(pages, rows)
+}
+
+// drawLogo draws the bank's mark, a square of size with its bottom left corner at margin, y
+func drawLogo(page *pdf.Page, y, size float64) {
+	page.SetFill(brand)
+	page.Rect(margin, y, size, size)
+	page.SetFill(pdf.White)
+	textSize := size * 0.45
+	page.Text(margin+(size-pdf.TextWidth("SB", pdf.Bold, textSize))/2, y+size*0.32, pdf.Bold, textSize, "SB")
+}
+
+func drawHeader(page *pdf.Page, st models.Statement, holder, period string) {
+	top := pdf.A4Height - margin
+
+	drawLogo(page, top-36, 36)
+	page.SetFill(brand)
+	page.Text(margin+46, top-24, pdf.Bold, 18, "Swift Bank")
+	page.SetFill(pdf.Black)
+	page.TextRight(pdf.A4Width-margin, top-14, pdf.Bold, 14, "Account statement")
+	page.SetFill(muted)
+	page.TextRight(pdf.A4Width-margin, top-30, pdf.Regular, 10, period)
+
+	labelled := func(x, y float64, label, value string) {
+		page.SetFill(muted)
+		page.Text(x, y, pdf.Regular, 8, label)
+		page.SetFill(pdf.Black)
+		page.Text(x, y-14, pdf.Bold, 11, value)
+	}
+
+	labelled(margin, top-70, "Account holder", holder)
+	labelled(margin+260, top-70, "Account", fmt.Sprintf("%d (%s)", st.Account.ID, st.Account.Currency))
+	if st.Account.Nickname != "" {
+		page.SetFill(muted)
+		page.Text(margin+260, top-98, pdf.Regular, 9, st.Account.Nickname)
+		page.SetFill(pdf.Black)
+	}
+
+	var in, out int64
+	for _, row := range st.Rows {
+		if row.Amount >= 0 {
+			in += row.Amount
+		} else {
+			out -= row.Amount
+		}
+	}
+
+	page.SetFill(shade)
+	page.Rect(margin, top-170, pdf.A4Width-2*margin, 56)
+	summary := []struct {
+		label  string
+		amount int64
+	}{
+		{"Opening balance", st.OpeningBalance},
+		{"Money in", in},
+		{"Money out", out},
+		{"Closing balance", st.ClosingBalance},
+	}
+	width := (pdf.A4Width - 2*margin) / float64(len(summary))
+	for i, item := range summary {
+		labelled(margin+12+float64(i)*width, top-132, item.label, formatMoney(money.New(item.amount, st.Account.Currency)))
+	}
+}
+
+func drawTable(page *pdf.Page, top float64, st models.Statement, rows []models.StatementRow) {
+	page.SetFill(brand)
+	page.Rect(margin, top-rowHeight, pdf.A4Width-2*margin, rowHeight)
+	page.SetFill(pdf.White)
+	baseline := top - rowHeight + 5
+	page.Text(dateColumn, baseline, pdf.Bold, fontSize, "Date")
+	page.Text(descriptionColumn, baseline, pdf.Bold, fontSize, "Description")
+	page.TextRight(amountRight, baseline, pdf.Bold, fontSize, "Amount")
+	page.TextRight(balanceRight, baseline, pdf.Bold, fontSize, "Balance")
+
+	page.SetFill(pdf.Black)
+	if len(st.Rows) == 0 {
+		page.Text(descriptionColumn, baseline-rowHeight, pdf.Regular, fontSize, "No transactions in this period")
+		return
+	}
+
+	descriptions := describer(st)
+	page.SetStroke(shade)
+	for i, row := range rows {
+		y := top - float64(i+2)*rowHeight
+		page.Text(dateColumn, y+5, pdf.Regular, fontSize, row.CreatedAt.UTC().Format("02 Jan 2006"))
+		page.Text(descriptionColumn, y+5, pdf.Regular, fontSize, pdf.Truncate(descriptions(row), pdf.Regular, fontSize, descriptionWidth))
+		page.TextRight(amountRight, y+5, pdf.Regular, fontSize, formatMoney(money.New(row.Amount, st.Account.Currency)))
+		page.TextRight(balanceRight, y+5, pdf.Regular, fontSize, formatMoney(money.New(row.Balance, st.Account.Currency)))
+		page.Line(margin, y, pdf.A4Width-margin, y, 0.5)
+	}
+}
+
+// describer returns a function describing the entry of a row. Entries are matched to the transfer
+// that created them by time, as both are written by the same database transaction.
+func describer(st models.Statement) func(models.StatementRow) string {
+	transfers := make(map[int64]models.Transaction, len(st.Transfers))
+	for _, transfer := range st.Transfers {
+		transfers[transfer.CreatedAt.UnixNano()] = transfer
+	}
+
+	return func(row models.StatementRow) string {
+		transfer, ok := transfers[row.CreatedAt.UnixNano()]
+		var description string
+		switch {
+		case !ok && row.Category == models.CategoryInterest:
+			return "Interest"
+		case !ok && row.Amount >= 0:
+			return "Deposit"
+		case !ok:
+			return "Withdrawal"
+		case transfer.ToAccountID == st.Account.ID:
+			description = fmt.Sprintf("Transfer from account %d", transfer.FromAccountID)
+		case row.Amount == -transfer.Amount:
+			description = fmt.Sprintf("Transfer to account %d", transfer.ToAccountID)
+		default:
+			return fmt.Sprintf("Fee for transfer to account %d", transfer.ToAccountID)
+		}
+
+		if memo := strings.TrimSpace(transfer.Description); memo != "" {
+			description += ": " + memo
+		}
+		return description
+	}
+}
+
+// formatMoney formats amount with the decimals of its currency and thousands separators, like
+// -1,234.50
+func formatMoney(amount money.Amount) string {
+	s := amount.String()
+	sign := ""
+	if amount.IsNegative() {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package statement
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+)
+
+func TestWritePDFEmptyMonth(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD"}, Year: 2024, Month: 2}
+	st := Build(arg, 123450, nil, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, st, "Ada Lovelace"))
+
+	out := buf.String()
+	require.True(t, strings.HasPrefix(out, "%PDF-"))
+	require.Equal(t, 1, strings.Count(out, "/Type /Page "))
+	require.Contains(t, out, "(Ada Lovelace)")
+	require.Contains(t, out, "(February 2024)")
+	require.Contains(t, out, "(1,234.50)")
+	require.Contains(t, out, "(No transactions in this period)")
+	require.Contains(t, out, "(Page 1 of 1)")
+	require.NotContains(t, out, "(Savings)")
+}
+
+func TestWritePDFNickname(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD", Nickname: "Savings"}, Year: 2024, Month: 2}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, Build(arg, 0, nil, nil), "Ada Lovelace"))
+	require.Contains(t, buf.String(), "(Savings)")
+}
+
+func TestWritePDFPages(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD"}, Year: 2024, Month: 2}
+	start, _ := Period(2024, 2)
+
+	entries := make([]models.Entry, 100)
+	for i := range entries {
+		entries[i] = models.Entry{ID: int64(i + 1), AccountID: 1, Amount: 100, CreatedAt: start.Add(time.Duration(i) * time.Hour)}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, Build(arg, 0, entries, nil), "Ada Lovelace"))
+
+	out := buf.String()
+	pages := paginate(Build(arg, 0, entries, nil).Rows)
+	require.Len(t, pages, 3)
+	require.Equal(t, 3, strings.Count(out, "/Type /Page "))
+	require.Equal(t, 3, strings.Count(out, "(Balance)"), "every page repeats the table header")
+	require.Contains(t, out, "(Page 3 of 3)")
+	require.Equal(t, 100, strings.Count(out, "(Deposit)"))
+	require.Contains(t, out, "(100.00)")
+}
+
+func TestPaginate(t *testing.T) {
+	first := len(paginate(make([]models.StatementRow, 1000))[0])
+
+	require.Len(t, paginate(nil), 1)
+	require.Len(t, paginate(make([]models.StatementRow, first)), 1)
+	require.Len(t, paginate(make([]models.StatementRow, first+1)), 2)
+}
+
+func TestDescriber(t *testing.T) {
+	at := time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC)
+	st := models.Statement{
+		Account: models.Account{ID: 1},
+		Transfers: []models.Transaction{
+			{FromAccountID: 1, ToAccountID: 2, Amount: 1000, Fee: 150, Description: "rent", CreatedAt: at},
+			{FromAccountID: 3, ToAccountID: 1, Amount: 500, CreatedAt: at.Add(time.Hour)},
+		},
+	}
+	describe := describer(st)
+
+	require.Equal(t, "Transfer to account 2: rent", describe(models.StatementRow{Amount: -1000, CreatedAt: at}))
+	require.Equal(t, "Fee for transfer to account 2", describe(models.StatementRow{Amount: -150, CreatedAt: at}))
+	require.Equal(t, "Transfer from account 3", describe(models.StatementRow{Amount: 500, CreatedAt: at.Add(time.Hour)}))
+	require.Equal(t, "Deposit", describe(models.StatementRow{Amount: 500, CreatedAt: at.Add(2 * time.Hour)}))
+	require.Equal(t, "Withdrawal", describe(models.StatementRow{Amount: -500, CreatedAt: at.Add(2 * time.Hour)}))
+	require.Equal(t, "Interest", describe(models.StatementRow{Amount: 312, Category: models.CategoryInterest, CreatedAt: at.Add(3 * time.Hour)}))
+}
+
+func TestFormatMoney(t *testing.T) {
+	require.Equal(t, "0.00", formatMoney(money.New(0, "USD")))
+	require.Equal(t, "-0.05", formatMoney(money.New(-5, "USD")))
+	require.Equal(t, "999.99", formatMoney(money.New(99999, "USD")))
+	require.Equal(t, "-1,000.00", formatMoney(money.New(-100000, "USD")))
+	require.Equal(t, "1,234,567.89", formatMoney(money.New(123456789, "USD")))
+	require.Equal(t, "1,500", formatMoney(money.New(1500, "JPY")))
+	require.Equal(t, "12,345.678", formatMoney(money.New(12345678, "BHD")))
+}
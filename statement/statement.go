@@ -0,0 +1,78 @@
+package statement
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zde37/Swift_Bank/models"
+)
+
+// Period returns the [start, end) bounds of the given month in UTC
+func Period(year, month int) (start, end time.Time) {
+	start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end = start.AddDate(0, 1, 0)
+	return start, end
+}
+
+// Build assembles a monthly statement from the entries posted within the month.
+// Rows carry a running balance that starts from the opening balance, so a month
+// without activity yields an empty statement whose opening and closing balances are equal.
+// The running balance may go below zero on overdrawn accounts.
+func Build(arg models.GetStatementParams, openingBalance int64, entries []models.Entry, transfers []models.Transaction) models.Statement {
+	st := models.Statement{
+		Account:        arg.Account,
+		Year:           arg.Year,
+		Month:          arg.Month,
+		OpeningBalance: openingBalance,
+		ClosingBalance: openingBalance,
+		Rows:           make([]models.StatementRow, 0, len(entries)),
+		Transfers:      transfers,
+	}
+
+	if st.Transfers == nil {
+		st.Transfers = []models.Transaction{}
+	}
+
+	for _, entry := range entries {
+		st.ClosingBalance += entry.Amount
+		st.Rows = append(st.Rows, models.StatementRow{
+			EntryID:   entry.ID,
+			Amount:    entry.Amount,
+			Balance:   st.ClosingBalance,
+			Category:  entry.Category,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	st.Categories = categoryTotals(st.Rows)
+
+	return st
+}
+
+// categoryTotals sums rows by category, sorted by name with the uncategorized rows last
+func categoryTotals(rows []models.StatementRow) []models.CategoryTotal {
+	totals := []models.CategoryTotal{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.Category]
+		if !ok {
+			i = len(totals)
+			index[row.Category] = i
+			totals = append(totals, models.CategoryTotal{Category: row.Category})
+		}
+
+		if row.Amount >= 0 {
+			totals[i].In += row.Amount
+		} else {
+			totals[i].Out -= row.Amount
+		}
+		totals[i].Count++
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if (totals[i].Category == "") != (totals[j].Category == "") {
+			return totals[j].Category == ""
+		}
+		return totals[i].Category < totals[j].Category
+	})
+	return totals
+}
@@ -0,0 +1,130 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+func TestPeriod(t *testing.T) {
+	start, end := Period(2024, 12)
+	require.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestBuildEmptyMonth(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD"}, Year: 2024, Month: 2}
+
+	st := Build(arg, 15000, nil, nil)
+	require.Equal(t, st.OpeningBalance, st.ClosingBalance)
+	require.Empty(t, st.Rows)
+	require.NotNil(t, st.Transfers)
+}
+
+func TestBuildRunningBalance(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD"}, Year: 2024, Month: 2}
+	entries := []models.Entry{
+		{ID: 1, AccountID: 1, Amount: 5000},
+		{ID: 2, AccountID: 1, Amount: -2000},
+		{ID: 3, AccountID: 1, Amount: 500},
+	}
+
+	st := Build(arg, 10000, entries, nil)
+	require.Len(t, st.Rows, 3)
+	require.Equal(t, int64(15000), st.Rows[0].Balance)
+	require.Equal(t, int64(13000), st.Rows[1].Balance)
+	require.Equal(t, int64(13500), st.Rows[2].Balance)
+	require.Equal(t, int64(13500), st.ClosingBalance)
+}
+
+func TestBuildCategoryTotals(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD"}, Year: 2024, Month: 2}
+	entries := []models.Entry{
+		{ID: 1, AccountID: 1, Amount: 5000, Category: "income"},
+		{ID: 2, AccountID: 1, Amount: -2000, Category: "groceries"},
+		{ID: 3, AccountID: 1, Amount: 300},
+		{ID: 4, AccountID: 1, Amount: -450, Category: "groceries"},
+		{ID: 5, AccountID: 1, Amount: 100, Category: "groceries"}, // a refund
+		{ID: 6, AccountID: 1, Amount: -25},
+	}
+
+	st := Build(arg, 0, entries, nil)
+	require.Equal(t, "groceries", st.Rows[1].Category)
+	require.Equal(t, []models.CategoryTotal{
+		{Category: "groceries", In: 100, Out: 2450, Count: 3},
+		{Category: "income", In: 5000, Count: 1},
+		{Category: "", In: 300, Out: 25, Count: 2},
+	}, st.Categories)
+
+	empty := Build(arg, 0, nil, nil)
+	require.NotNil(t, empty.Categories)
+	require.Empty(t, empty.Categories)
+}
+
+func TestBuildNegativeRunningBalance(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 1, Currency: "USD", OverdraftLimit: 10000}, Year: 2024, Month: 2}
+	entries := []models.Entry{
+		{ID: 1, AccountID: 1, Amount: -3010},
+		{ID: 2, AccountID: 1, Amount: -20},
+		{ID: 3, AccountID: 1, Amount: 2030},
+		{ID: 4, AccountID: 1, Amount: 2000},
+	}
+
+	st := Build(arg, 1000, entries, nil)
+	require.Len(t, st.Rows, 4)
+	require.Equal(t, int64(-2010), st.Rows[0].Balance)
+	require.Equal(t, int64(-2030), st.Rows[1].Balance)
+	require.Equal(t, int64(0), st.Rows[2].Balance)
+	require.Equal(t, int64(2000), st.ClosingBalance)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, st))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "-20.10", records[2][4])
+	require.Equal(t, "0.00", records[4][4])
+}
+
+func TestWriteCSV(t *testing.T) {
+	arg := models.GetStatementParams{Account: models.Account{ID: 7, Currency: "EUR"}, Year: 2024, Month: 3}
+	entries := []models.Entry{
+		{ID: 10, AccountID: 7, Amount: 1250, CreatedAt: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+	}
+	st := Build(arg, 0, entries, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, st))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, []string{"2024-03-01", "opening balance", "", "", "0.00"}, records[1])
+	require.Equal(t, []string{"2024-03-04T10:00:00Z", "Deposit", "10", "12.50", "12.50"}, records[2])
+	require.Equal(t, []string{"2024-03-31", "closing balance", "", "", "12.50"}, records[3])
+}
+
+func TestWriteCSVMemo(t *testing.T) {
+	at := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	arg := models.GetStatementParams{Account: models.Account{ID: 7, Currency: "EUR"}, Year: 2024, Month: 3}
+	entries := []models.Entry{
+		{ID: 10, AccountID: 7, Amount: -1250, CreatedAt: at},
+	}
+	transfers := []models.Transaction{
+		{FromAccountID: 7, ToAccountID: 9, Amount: 1250, Currency: "EUR", Description: "=rent, \"March\"", CreatedAt: at},
+	}
+	st := Build(arg, 2000, entries, transfers)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, st))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, []string{"2024-03-04T10:00:00Z", `Transfer to account 9: =rent, "March"`, "10", "-12.50", "7.50"}, records[2])
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileStore keeps objects as files under a directory and serves its own signed URLs, for
+// deployments without an object storage service. Objects are served with the content type
+// of their extension.
+type FileStore struct {
+	dir     string
+	baseURL string
+	key     []byte
+	now     func() time.Time
+}
+
+// NewFileStore stores objects under dir. Signed URLs point below baseURL, where the store has to
+// be mounted, and their signing key is derived from secret so it can be shared with other uses.
+func NewFileStore(dir, baseURL, secret string) (*FileStore, error) {
+	if len(secret) < 16 {
+		return nil, errors.New("storage secret must be at least 16 characters")
+	}
+
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid storage base url: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("swift bank storage urls"))
+	return &FileStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		key:     mac.Sum(nil),
+		now:     time.Now,
+	}, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// written aside and renamed, so a download never sees half a file
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *FileStore) SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, time.Time, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", time.Time{}, ErrNotFound
+		}
+		return "", time.Time{}, err
+	}
+
+	expiresAt := s.now().Add(expiresIn).Truncate(time.Second)
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"signature": {s.sign(key, expiresAt.Unix())},
+	}
+
+	return s.baseURL + (&url.URL{Path: "/" + key}).EscapedPath() + "?" + query.Encode(), expiresAt, nil
+}
+
+// ServeHTTP serves the objects of signed URLs. It expects the path below the base URL, so it has
+// to be mounted with http.StripPrefix.
+func (s *FileStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || !hmac.Equal([]byte(r.URL.Query().Get("signature")), []byte(s.sign(key, expires))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	if s.now().Unix() > expires {
+		http.Error(w, "link expired", http.StatusForbidden)
+		return
+	}
+
+	name, err := s.path(key)
+	if err != nil {
+		http.Error(w, "invalid key", http.StatusBadRequest)
+		return
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), file)
+}
+
+// path returns the file of key, refusing keys that would leave the directory
+func (s *FileStore) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *FileStore) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFileStore(t *testing.T) (*FileStore, *httptest.Server) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	store, err := NewFileStore(t.TempDir(), server.URL+"/v1/files/", "a secret of at least 16 characters")
+	require.NoError(t, err)
+	mux.Handle("GET /v1/files/", http.StripPrefix("/v1/files", store))
+
+	return store, server
+}
+
+func get(t *testing.T, rawURL string) (*http.Response, string) {
+	res, err := http.Get(rawURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res, string(body)
+}
+
+func TestFileStoreSignedURL(t *testing.T) {
+	store, server := newTestFileStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "statements/7/2024-03.pdf", "application/pdf", []byte("%PDF-1.4 first")))
+	require.NoError(t, store.Put(ctx, "statements/7/2024-03.pdf", "application/pdf", []byte("%PDF-1.4 second")))
+
+	signed, expiresAt, err := store.SignedURL(ctx, "statements/7/2024-03.pdf", 15*time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(signed, server.URL+"/v1/files/statements/7/2024-03.pdf?"))
+	require.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, 2*time.Second)
+
+	res, body := get(t, signed)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "%PDF-1.4 second", body)
+	require.Equal(t, "application/pdf", res.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename=2024-03.pdf`, res.Header.Get("Content-Disposition"))
+}
+
+func TestFileStoreRejectsBadLinks(t *testing.T) {
+	store, _ := newTestFileStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "statements/7/2024-03.pdf", "application/pdf", []byte("mine")))
+	require.NoError(t, store.Put(ctx, "statements/8/2024-03.pdf", "application/pdf", []byte("theirs")))
+
+	signed, _, err := store.SignedURL(ctx, "statements/7/2024-03.pdf", time.Minute)
+	require.NoError(t, err)
+
+	// the signature only covers the key and expiry it was made for
+	other := strings.Replace(signed, "/7/", "/8/", 1)
+	res, _ := get(t, other)
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("expires", "9999999999")
+	u.RawQuery = query.Encode()
+	res, _ = get(t, u.String())
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	res, body := get(t, signed)
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+	require.Contains(t, body, "expired")
+}
+
+func TestFileStoreKeys(t *testing.T) {
+	store, _ := newTestFileStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", ".", "../secret", "/etc/passwd", `statements\..\x`, "a//b"} {
+		require.Error(t, store.Put(ctx, key, "text/plain", []byte("x")), key)
+	}
+
+	_, _, err := store.SignedURL(ctx, "statements/missing.pdf", time.Minute)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewFileStoreShortSecret(t *testing.T) {
+	_, err := NewFileStore(t.TempDir(), "http://localhost/v1/files", "short")
+	require.Error(t, err)
+}
@@ -0,0 +1,18 @@
+// Package storage keeps the files the bank generates, like PDF statements, out of the database.
+// Files are handed to users as signed URLs that expire, so they can be downloaded without a token.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Store saves objects under a key, like statements/42/2024-03.pdf
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// SignedURL returns a URL that downloads the object until it expires
+	SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, time.Time, error)
+}
@@ -0,0 +1,81 @@
+// Package tlscert serves a TLS certificate from files that can be replaced while the server runs
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Reloader hands out the certificate last loaded from its files. Renewed certificates are picked
+// up by Reload, without restarting the server or dropping connections.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// NewReloader loads the key pair from certFile and keyFile
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the key pair again. The current certificate stays in use if that fails.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// ServerConfig returns a server TLS config using the current certificate for every handshake
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Watch reloads the key pair every time a signal arrives, until ctx is cancelled
+func (r *Reloader) Watch(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if err := r.Reload(); err != nil {
+				log.Error().Err(err).Str("cert_file", r.certFile).Msg("failed to reload TLS certificate")
+				continue
+			}
+			log.Info().Str("cert_file", r.certFile).Msg("reloaded TLS certificate")
+		}
+	}
+}
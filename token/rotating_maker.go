@@ -0,0 +1,55 @@
+package token
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// RotatingMaker makes PASETO tokens with the current key. After Rotate it keeps accepting tokens
+// of the previous key, so a rotated key doesn't log everyone out; those run out on their own.
+type RotatingMaker struct {
+	mu       sync.RWMutex
+	current  Maker
+	previous Maker // nil until the first rotation
+}
+
+func NewRotatingMaker(symmetricKey string) (*RotatingMaker, error) {
+	maker, err := NewPastoMaker(symmetricKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotatingMaker{current: maker}, nil
+}
+
+// Rotate makes new tokens with symmetricKey. Only the key before it is still accepted.
+func (r *RotatingMaker) Rotate(symmetricKey string) error {
+	maker, err := NewPastoMaker(symmetricKey)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.previous, r.current = r.current, maker
+	return nil
+}
+
+func (r *RotatingMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current.CreateToken(username, role, duration)
+}
+
+func (r *RotatingMaker) VerifyToken(token string) (*Payload, error) {
+	r.mu.RLock()
+	current, previous := r.current, r.previous
+	r.mu.RUnlock()
+
+	payload, err := current.VerifyToken(token)
+	if errors.Is(err, ErrInvalidToken) && previous != nil {
+		return previous.VerifyToken(token)
+	}
+	return payload, err
+}
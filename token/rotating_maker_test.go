@@ -0,0 +1,50 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+)
+
+func TestRotatingMaker(t *testing.T) {
+	maker, err := NewRotatingMaker(helpers.RandomString(32))
+	require.NoError(t, err)
+
+	username := helpers.RandomOwner()
+	first, _, err := maker.CreateToken(username, helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, maker.Rotate(helpers.RandomString(32)))
+	second, _, err := maker.CreateToken(username, helpers.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	// tokens of the previous key keep working, new ones use the new key
+	for _, token := range []string{first, second} {
+		payload, err := maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, username, payload.UserName)
+	}
+
+	// two rotations later the first key is gone
+	require.NoError(t, maker.Rotate(helpers.RandomString(32)))
+	_, err = maker.VerifyToken(first)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = maker.VerifyToken(second)
+	require.NoError(t, err)
+
+	require.Error(t, maker.Rotate("short"))
+}
+
+func TestRotatingMakerExpiredToken(t *testing.T) {
+	maker, err := NewRotatingMaker(helpers.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(helpers.RandomOwner(), helpers.DepositorRole, -time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, maker.Rotate(helpers.RandomString(32)))
+	_, err = maker.VerifyToken(token)
+	require.ErrorIs(t, err, ErrExpiredToken)
+}
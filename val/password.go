@@ -0,0 +1,134 @@
+package val
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// character classes a password policy can require
+const (
+	CharClassLower  = "lower"
+	CharClassUpper  = "upper"
+	CharClassDigit  = "digit"
+	CharClassSymbol = "symbol"
+)
+
+const (
+	defaultPasswordMinLength = 6
+	maxPasswordLength        = 100
+	minUserInfoLength        = 3 // shorter usernames and email names would reject too many passwords
+)
+
+// common_passwords.txt is the SecLists 10k-most-common list, make commonpasswords refreshes it
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords holds the lowercased entries of common_passwords.txt
+var commonPasswords = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[strings.ToLower(line)] = true
+		}
+	}
+	return set
+}()
+
+// PasswordPolicy is what a new password must satisfy
+type PasswordPolicy struct {
+	MinLength      int
+	Require        []string // character classes the password needs at least one character of
+	RejectUserInfo bool     // the username and the name part of the email may not appear in the password
+	RejectCommon   bool     // passwords on the common password list are refused
+}
+
+// DefaultPasswordPolicy only checks the length, it is what passwords were held to before policies
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: defaultPasswordMinLength}
+
+// NewPasswordPolicy builds a policy from config. classes is a comma separated list of lower, upper,
+// digit and symbol, a minLength of 0 keeps the default.
+func NewPasswordPolicy(minLength int, classes string, rejectUserInfo, rejectCommon bool) (PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:      minLength,
+		RejectUserInfo: rejectUserInfo,
+		RejectCommon:   rejectCommon,
+	}
+	if policy.MinLength == 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	if policy.MinLength < defaultPasswordMinLength || policy.MinLength > maxPasswordLength {
+		return PasswordPolicy{}, fmt.Errorf("password min length must be from %d - %d, got %d", defaultPasswordMinLength, maxPasswordLength, minLength)
+	}
+
+	for _, class := range strings.Split(classes, ",") {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if class == "" {
+			continue
+		}
+		if _, ok := charClassRules[class]; !ok {
+			return PasswordPolicy{}, fmt.Errorf("unknown password character class %q", class)
+		}
+		policy.Require = append(policy.Require, class)
+	}
+
+	return policy, nil
+}
+
+type charClassRule struct {
+	matches func(rune) bool
+	message string
+}
+
+var charClassRules = map[string]charClassRule{
+	CharClassLower:  {unicode.IsLower, "must contain a lowercase letter"},
+	CharClassUpper:  {unicode.IsUpper, "must contain an uppercase letter"},
+	CharClassDigit:  {unicode.IsDigit, "must contain a digit"},
+	CharClassSymbol: {isSymbol, "must contain a symbol"},
+}
+
+func isSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// ValidatePassword checks value against the policy, the first rule it breaks is returned.
+// username and email are the account's, they are only looked at when the policy rejects user info.
+func ValidatePassword(value string, policy PasswordPolicy, username, email string) error {
+	minLength := policy.MinLength
+	if minLength == 0 {
+		minLength = defaultPasswordMinLength
+	}
+	if err := ValidateString(value, minLength, maxPasswordLength); err != nil {
+		return err
+	}
+
+	for _, class := range policy.Require {
+		rule, ok := charClassRules[class]
+		if !ok {
+			continue
+		}
+		if !strings.ContainsFunc(value, rule.matches) {
+			return errors.New(rule.message)
+		}
+	}
+
+	if policy.RejectUserInfo {
+		lower := strings.ToLower(value)
+		if len(username) >= minUserInfoLength && strings.Contains(lower, strings.ToLower(username)) {
+			return fmt.Errorf("must not contain your username")
+		}
+		name, _, _ := strings.Cut(email, "@")
+		if len(name) >= minUserInfoLength && strings.Contains(lower, strings.ToLower(name)) {
+			return fmt.Errorf("must not contain your email address")
+		}
+	}
+
+	if policy.RejectCommon && commonPasswords[strings.ToLower(value)] {
+		return fmt.Errorf("is too common, choose a less guessable password")
+	}
+
+	return nil
+}
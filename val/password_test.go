@@ -0,0 +1,84 @@
+package val
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePassword(t *testing.T) {
+	strict := PasswordPolicy{
+		MinLength:      10,
+		Require:        []string{CharClassLower, CharClassUpper, CharClassDigit, CharClassSymbol},
+		RejectUserInfo: true,
+		RejectCommon:   true,
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		policy   PasswordPolicy
+		err      string
+	}{
+		{name: "DEFAULT", password: "secret", policy: DefaultPasswordPolicy},
+		{name: "DEFAULT ALLOWS COMMON", password: "password", policy: DefaultPasswordPolicy},
+		{name: "ZERO POLICY", password: "secret", policy: PasswordPolicy{}},
+		{name: "DEFAULT TOO SHORT", password: "abc", policy: DefaultPasswordPolicy, err: "must contain from 6 - 100 characters"},
+		{name: "TOO LONG", password: strings.Repeat("a", 101), policy: DefaultPasswordPolicy, err: "must contain from 6 - 100 characters"},
+		{name: "STRICT", password: "Tr0ub4dor&3x", policy: strict},
+		{name: "STRICT TOO SHORT", password: "Tr0ub&3x", policy: strict, err: "must contain from 10 - 100 characters"},
+		{name: "NO LOWERCASE", password: "TR0UB4DOR&3X", policy: strict, err: "must contain a lowercase letter"},
+		{name: "NO UPPERCASE", password: "tr0ub4dor&3x", policy: strict, err: "must contain an uppercase letter"},
+		{name: "NO DIGIT", password: "Troubador&xx", policy: strict, err: "must contain a digit"},
+		{name: "NO SYMBOL", password: "Tr0ub4dor3xx", policy: strict, err: "must contain a symbol"},
+		{name: "USERNAME", password: "Jdoe_4ever!x", policy: strict, err: "must not contain your username"},
+		{name: "EMAIL NAME", password: "Janed0e.rocks!", policy: strict, err: "must not contain your email address"},
+		{name: "COMMON", password: "Password1", policy: PasswordPolicy{MinLength: 6, RejectCommon: true}, err: "is too common, choose a less guessable password"},
+		{name: "COMMON ANY CASE", password: "QWERTY123", policy: PasswordPolicy{MinLength: 6, RejectCommon: true}, err: "is too common, choose a less guessable password"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePassword(tc.password, tc.policy, "jdoe", "janed0e@example.com")
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(0, "", false, false)
+	require.NoError(t, err)
+	require.Equal(t, DefaultPasswordPolicy, policy)
+
+	policy, err = NewPasswordPolicy(12, " Upper, digit ,", true, true)
+	require.NoError(t, err)
+	require.Equal(t, PasswordPolicy{
+		MinLength:      12,
+		Require:        []string{CharClassUpper, CharClassDigit},
+		RejectUserInfo: true,
+		RejectCommon:   true,
+	}, policy)
+
+	_, err = NewPasswordPolicy(4, "", false, false)
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(101, "", false, false)
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(8, "lower,emoji", false, false)
+	require.EqualError(t, err, `unknown password character class "emoji"`)
+}
+
+func TestCommonPasswordList(t *testing.T) {
+	// the top 10k list, a few entries differ only by case and fold together
+	lines := strings.Fields(commonPasswordList)
+	require.Equal(t, 10000, len(lines), "run make commonpasswords")
+	require.Greater(t, len(commonPasswords), 9900)
+}
@@ -0,0 +1,41 @@
+package val
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Violation is a field of a request that breaks one of its rules
+type Violation struct {
+	Field string
+	Err   error
+}
+
+// Rules holds the field checks of request messages, by message type. Checking a message without
+// rules finds nothing.
+type Rules struct {
+	checks map[protoreflect.FullName]func(msg proto.Message) []Violation
+}
+
+func NewRules() *Rules {
+	return &Rules{
+		checks: make(map[protoreflect.FullName]func(msg proto.Message) []Violation),
+	}
+}
+
+// AddRule makes check the rule of the messages of type M, replacing the one there was
+func AddRule[M proto.Message](rules *Rules, check func(req M) []Violation) {
+	var msg M
+	rules.checks[msg.ProtoReflect().Descriptor().FullName()] = func(msg proto.Message) []Violation {
+		return check(msg.(M))
+	}
+}
+
+// Check returns what msg violates, in the order its rule found it
+func (r *Rules) Check(msg proto.Message) []Violation {
+	check, ok := r.checks[msg.ProtoReflect().Descriptor().FullName()]
+	if !ok {
+		return nil
+	}
+	return check(msg)
+}
@@ -0,0 +1,25 @@
+package val
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRules(t *testing.T) {
+	rules := NewRules()
+	AddRule(rules, func(req *wrapperspb.StringValue) (violations []Violation) {
+		if err := ValidateUsername(req.GetValue()); err != nil {
+			violations = append(violations, Violation{Field: "value", Err: err})
+		}
+		return violations
+	})
+
+	require.Nil(t, rules.Check(wrapperspb.String("alice")))
+	require.Equal(t, []Violation{{Field: "value", Err: errors.New("must contain from 3 - 100 characters")}}, rules.Check(wrapperspb.String("a")))
+
+	// messages without a rule pass
+	require.Nil(t, rules.Check(wrapperspb.Int64(-1)))
+}
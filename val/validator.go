@@ -1,14 +1,33 @@
 package val
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/mail"
+	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/recurrence"
 )
 
 var (
 	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
 	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidPhone    = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`).MatchString
+	isCurrencyCode  = regexp.MustCompile(`^[A-Z]{3}$`).MatchString
+	isPhoneCode     = regexp.MustCompile(`^[0-9]{6}$`).MatchString
+	isValidCategory = regexp.MustCompile(`^[a-z_]+$`).MatchString
+	isValidTag      = regexp.MustCompile(`^[a-z0-9_-]+$`).MatchString
 )
 
 func ValidateString(value string, minLength, maxLength int) error {
@@ -20,7 +39,19 @@ func ValidateString(value string, minLength, maxLength int) error {
 	return nil
 }
 
-func ValidateUsername(value string) error { // TODO: trim spaces
+// NormalizeUsername returns the canonical form usernames are stored and compared in, so "Alice "
+// and "alice" are the same user
+func NormalizeUsername(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// NormalizeEmail lowercases the whole address. The local part may be case sensitive by the RFC,
+// but no mail provider treats it so and two accounts must not differ only by it.
+func NormalizeEmail(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func ValidateUsername(value string) error {
 	if err := ValidateString(value, 3, 100); err != nil {
 		return err
 	}
@@ -32,10 +63,6 @@ func ValidateUsername(value string) error { // TODO: trim spaces
 	return nil
 }
 
-func ValidatePassword(value string) error {
-	return ValidateString(value, 6, 100)
-}
-
 func ValidateEmail(value string) error {
 	if err := ValidateString(value, 3, 200); err != nil {
 		return err
@@ -60,6 +87,22 @@ func ValidateFullName(value string) error {
 	return nil
 }
 
+// NormalizePhoneNumber drops the spaces and dashes people write phone numbers with, so
+// "+1 415-555-2671" becomes "+14155552671". It does not validate the result.
+func NormalizePhoneNumber(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(value))
+}
+
+// ValidatePhoneNumber accepts numbers in E.164 form, e.g. +14155552671. Numbers typed by people
+// should go through NormalizePhoneNumber first.
+func ValidatePhoneNumber(value string) error {
+	if !isValidPhone(value) {
+		return fmt.Errorf("must be in E.164 format, a + followed by 7 to 15 digits")
+	}
+
+	return nil
+}
+
 func ValidateEmailId(value int64) error {
 	if value <= 0 {
 		return fmt.Errorf("must be a positive integer")
@@ -68,6 +111,482 @@ func ValidateEmailId(value int64) error {
 	return nil
 }
 
+func ValidateAccountId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateTransferId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateEntryId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateReversalReason(value string) error {
+	return ValidateString(strings.TrimSpace(value), 3, 500)
+}
+
+func ValidateBeneficiaryId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateNickname(value string) error {
+	return ValidateString(strings.TrimSpace(value), 1, 50)
+}
+
+// MaxDescriptionLength is the most characters the memo of a transfer may have
+const MaxDescriptionLength = 140
+
+// NormalizeDescription strips the control characters from the memo of a transfer, so it can't
+// break the lines of a statement, a CSV export or an email. Line breaks and tabs become spaces.
+func NormalizeDescription(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if !unicode.IsControl(r) {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return -1
+	}, value)
+
+	return strings.TrimSpace(value)
+}
+
+// ValidateDescription checks a normalized memo, an empty one is fine
+func ValidateDescription(value string) error {
+	if n := utf8.RuneCountInString(value); n > MaxDescriptionLength {
+		return fmt.Errorf("must contain at most %d characters, it contains %d", MaxDescriptionLength, n)
+	}
+
+	return nil
+}
+
+// ValidateCategory checks the form of a category name, whether it exists is up to the categories table
+func ValidateCategory(value string) error {
+	if err := ValidateString(value, 1, 50); err != nil {
+		return err
+	}
+	if !isValidCategory(value) {
+		return fmt.Errorf("must contain only lowercase letters, or underscore")
+	}
+
+	return nil
+}
+
+// MaxTags is how many tags an entry may have
+const MaxTags = 10
+
+// NormalizeTags lowercases and trims the tags, dropping empty and repeated ones, and sorts them.
+// It returns nil when no tag is left.
+func NormalizeTags(value []string) []string {
+	var tags []string
+	seen := make(map[string]bool, len(value))
+	for _, tag := range value {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// ValidateTags checks normalized tags
+func ValidateTags(value []string) error {
+	if len(value) > MaxTags {
+		return fmt.Errorf("must have at most %d tags", MaxTags)
+	}
+
+	for _, tag := range value {
+		if err := ValidateString(tag, 1, 30); err != nil {
+			return fmt.Errorf("tag %q %w", tag, err)
+		}
+		if !isValidTag(tag) {
+			return fmt.Errorf("tag %q must contain only letters, digits, hyphen, or underscore", tag)
+		}
+	}
+
+	return nil
+}
+
+// MaxAccountMetadataSize is the most bytes the metadata of an account may take as JSON
+const MaxAccountMetadataSize = 4096
+
+// ReservedMetadataPrefix starts the metadata keys the bank keeps for itself
+const ReservedMetadataPrefix = "sb_"
+
+func ValidateAccountMetadata(value map[string]string) error {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("must not have an empty key")
+		}
+		if strings.HasPrefix(strings.ToLower(key), ReservedMetadataPrefix) {
+			return fmt.Errorf("key %q is reserved, keys must not start with %s", key, ReservedMetadataPrefix)
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxAccountMetadataSize {
+		return fmt.Errorf("must take at most %d bytes as JSON, it takes %d", MaxAccountMetadataSize, len(data))
+	}
+
+	return nil
+}
+
+// ValidateIBAN accepts an IBAN in printed or electronic form
+func ValidateIBAN(value string) error {
+	iban := helpers.NormalizeIBAN(value)
+	if len(iban) < 2 {
+		return fmt.Errorf("must start with a country code")
+	}
+
+	length, ok := helpers.IBANLength(iban[:2])
+	if !ok {
+		return fmt.Errorf("must start with the code of a country that issues IBANs")
+	}
+
+	if len(iban) != length {
+		return fmt.Errorf("must contain %d characters for %s", length, iban[:2])
+	}
+
+	if !helpers.IsIBANChecksumValid(iban) {
+		return fmt.Errorf("must have valid check digits")
+	}
+
+	return nil
+}
+
+func ValidateBIC(value string) error {
+	if len(value) != 8 && len(value) != 11 {
+		return fmt.Errorf("must contain 8 or 11 characters")
+	}
+
+	if !helpers.IsValidBIC(value) {
+		return fmt.Errorf("must be a bank code, country code and location, optionally followed by a branch")
+	}
+
+	return nil
+}
+
+func ValidateWebhookId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateWebhookURL(value string) error {
+	if err := ValidateString(value, 1, 2048); err != nil {
+		return err
+	}
+
+	u, err := url.ParseRequestURI(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https url")
+	}
+
+	return nil
+}
+
+func ValidateWebhookEvents(value []string) error {
+	if len(value) == 0 {
+		return fmt.Errorf("must contain at least one event")
+	}
+
+	seen := make(map[string]bool, len(value))
+	for _, event := range value {
+		switch event {
+		case models.WebhookEventTransferCompleted, models.WebhookEventDepositCompleted, models.WebhookEventWithdrawalCompleted:
+		default:
+			return fmt.Errorf("%q is not an event that can be subscribed to", event)
+		}
+
+		if seen[event] {
+			return fmt.Errorf("must not repeat %q", event)
+		}
+		seen[event] = true
+	}
+
+	return nil
+}
+
+func ValidateWebhookSecret(value string) error {
+	return ValidateString(value, 16, 128)
+}
+
+func ValidateNotificationId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateTaskId(value string) error {
+	return ValidateString(value, 1, 64)
+}
+
+func ValidateScheduledTransferId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+func ValidateStandingOrderId(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+
+	return nil
+}
+
+// ValidateLogLevel checks value is a level worth setting at runtime, the ones past error would
+// hide nearly everything
+func ValidateLogLevel(value string) error {
+	switch value {
+	case "trace", "debug", "info", "warn", "error":
+		return nil
+	}
+
+	return fmt.Errorf("must be trace, debug, info, warn or error")
+}
+
+// maxLogLevelDuration bounds how long a level set at runtime lasts, trace logging left on for
+// days fills the disks
+const maxLogLevelDuration = 24 * time.Hour
+
+func ValidateLogLevelDuration(value time.Duration) error {
+	if value <= 0 || value > maxLogLevelDuration {
+		return fmt.Errorf("must be more than 0 and at most %s", maxLogLevelDuration)
+	}
+
+	return nil
+}
+
+func ValidateYear(value int32) error {
+	if value < 1970 || value > 9999 {
+		return fmt.Errorf("must be between 1970 and 9999")
+	}
+
+	return nil
+}
+
+func ValidateMonth(value int32) error {
+	if value < 1 || value > 12 {
+		return fmt.Errorf("must be between 1 and 12")
+	}
+
+	return nil
+}
+
+func ValidateTransferDirection(value string) error {
+	switch value {
+	case models.TransferDirectionAll, models.TransferDirectionSent, models.TransferDirectionReceived:
+		return nil
+	}
+
+	return fmt.Errorf("must be sent, received or all")
+}
+
+func ValidateAccountType(value string) error {
+	switch value {
+	case models.AccountChecking, models.AccountSavings:
+		return nil
+	}
+
+	return fmt.Errorf("must be checking or savings")
+}
+
+// ValidateAmount checks value is a decimal amount of currency greater than 0, like "10.50". It
+// leaves an unsupported currency to ValidateCurrency.
+// maxAmount is the most money one amount may move, in major units of its currency. Nothing a
+// customer does needs more, a larger amount is a typo or an attack on the arithmetic.
+const maxAmount = 1_000_000_000
+
+// ValidateAmount checks value is a positive decimal amount of currency, like "10.50", with no more
+// decimal places than the currency has and no more than maxAmount
+func ValidateAmount(value, currency string) error {
+	amount, err := parseAmount(value, currency)
+	if err != nil {
+		return err
+	}
+
+	return ValidateAmountUnits(amount.Units, currency)
+}
+
+// ValidateAmountUnits is ValidateAmount for an amount already in minor units of currency
+func ValidateAmountUnits(units int64, currency string) error {
+	if units <= 0 {
+		return fmt.Errorf("must be greater than 0")
+	}
+
+	limit, err := money.FromDecimalString(strconv.Itoa(maxAmount), currency)
+	if err == nil && units > limit.Units {
+		return fmt.Errorf("must be at most %d %s", maxAmount, currency)
+	}
+
+	return nil
+}
+
+func ValidateOverdraftLimit(value, currency string) error {
+	return validateNotNegative(value, currency)
+}
+
+// ValidateOpeningBalance checks value is a decimal amount of currency an imported account may
+// start with, it can't start overdrawn
+func ValidateOpeningBalance(value, currency string) error {
+	return validateNotNegative(value, currency)
+}
+
+func validateNotNegative(value, currency string) error {
+	amount, err := parseAmount(value, currency)
+	if err != nil {
+		return err
+	}
+
+	if amount.IsNegative() {
+		return fmt.Errorf("must not be negative")
+	}
+
+	return nil
+}
+
+func parseAmount(value, currency string) (money.Amount, error) {
+	amount, err := money.FromDecimalString(value, currency)
+	switch {
+	case errors.Is(err, money.ErrUnknownCurrency):
+		return amount, nil
+	case errors.Is(err, money.ErrTooPrecise):
+		exponent, _ := money.Exponent(currency)
+		return amount, fmt.Errorf("must have at most %d decimal places in %s", exponent, currency)
+	case errors.Is(err, money.ErrOverflow):
+		return amount, fmt.Errorf("is too large")
+	case err != nil:
+		return amount, fmt.Errorf("must be a decimal number like 10.50")
+	}
+
+	return amount, nil
+}
+
+func ValidateCurrency(value string) error {
+	if !helpers.ISSupportedCurrency(value) {
+		return fmt.Errorf("must be a supported currency")
+	}
+
+	return nil
+}
+
+// ValidateCurrencyCode checks the form of an ISO 4217 code, ValidateCurrency whether it is enabled
+func ValidateCurrencyCode(value string) error {
+	if !isCurrencyCode(value) {
+		return fmt.Errorf("must be 3 uppercase letters")
+	}
+
+	return nil
+}
+
+func ValidateCurrencyName(value string) error {
+	return ValidateString(strings.TrimSpace(value), 1, 100)
+}
+
+// ValidateMinorUnitExponent bounds the decimal places of a currency, ISO 4217 has none with more than 4
+func ValidateMinorUnitExponent(value int32) error {
+	if value < 0 || value > 4 {
+		return fmt.Errorf("must be between 0 and 4")
+	}
+
+	return nil
+}
+
+func ValidateSearchQuery(value string) error {
+	return ValidateString(strings.TrimSpace(value), 1, 100)
+}
+
+func ValidatePageSize(value int32) error {
+	if value < 1 || value > 50 {
+		return fmt.Errorf("must be between 1 and 50")
+	}
+
+	return nil
+}
+
+func ValidateIdempotencyKey(value string) error {
+	return ValidateString(value, 8, 128)
+}
+
+// MaxScheduleAhead is how far in the future a transfer may be scheduled
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+func ValidateExecuteAt(value time.Time) error {
+	now := time.Now()
+	if !value.After(now) {
+		return fmt.Errorf("must be in the future")
+	}
+
+	if value.After(now.Add(MaxScheduleAhead)) {
+		return fmt.Errorf("must be within a year from now")
+	}
+
+	return nil
+}
+
+func ValidateFrequency(value string) error {
+	if !recurrence.IsSupportedFrequency(value) {
+		return fmt.Errorf("must be daily, weekly or monthly")
+	}
+
+	return nil
+}
+
+func ValidateStartDate(value time.Time) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value.Before(today) {
+		return fmt.Errorf("must not be in the past")
+	}
+
+	return nil
+}
+
 func ValidateSecretCode(value string) error {
 	return ValidateString(value, 32, 128)
 }
+
+// ValidatePhoneCode checks a code texted to verify a phone number, six digits
+func ValidatePhoneCode(value string) error {
+	if !isPhoneCode(value) {
+		return fmt.Errorf("must be the 6 digit code sent to your phone")
+	}
+
+	return nil
+}
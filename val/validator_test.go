@@ -0,0 +1,188 @@
+package val
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	testCases := []struct {
+		name  string
+		iban  string
+		valid bool
+	}{
+		{name: "DE", iban: "DE89370400440532013000", valid: true},
+		{name: "GB PRINTED FORM", iban: "GB82 WEST 1234 5698 7654 32", valid: true},
+		{name: "FR", iban: "FR1420041010050500013M02606", valid: true},
+		{name: "NL", iban: "NL91ABNA0417164300", valid: true},
+		{name: "BE", iban: "BE68539007547034", valid: true},
+		{name: "CH", iban: "CH9300762011623852957", valid: true},
+		{name: "NO SHORTEST", iban: "NO9386011117947", valid: true},
+		{name: "ES", iban: "ES9121000418450200051332", valid: true},
+		{name: "IT", iban: "IT60X0542811101000000123456", valid: true},
+		{name: "LOWER CASE", iban: "gb82west12345698765432", valid: true},
+		{name: "DE WRONG CHECK DIGITS", iban: "DE88370400440532013000"},
+		{name: "GB SWAPPED DIGITS", iban: "GB82WEST12345698765423"},
+		{name: "FR CHANGED LETTER", iban: "FR1420041010050500013N02606"},
+		{name: "NL TOO SHORT", iban: "NL91ABNA041716430"},
+		{name: "BE TOO LONG", iban: "BE685390075470345"},
+		{name: "UNKNOWN COUNTRY", iban: "US64SVBKUS6S3300958879"},
+		{name: "PUNCTUATION", iban: "DE89-3704-0044-0532-0130-00"},
+		{name: "EMPTY", iban: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateIBAN(tc.iban)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bic   string
+		valid bool
+	}{
+		{name: "EIGHT CHARACTERS", bic: "DEUTDEFF", valid: true},
+		{name: "WITH BRANCH", bic: "DEUTDEFF500", valid: true},
+		{name: "DIGIT IN LOCATION", bic: "NWBKGB2L", valid: true},
+		{name: "NINE CHARACTERS", bic: "DEUTDEFF5"},
+		{name: "DIGIT IN BANK CODE", bic: "DEU1DEFF"},
+		{name: "DIGIT IN COUNTRY", bic: "DEUTD3FF"},
+		{name: "LOWER CASE", bic: "deutdeff"},
+		{name: "EMPTY", bic: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBIC(tc.bic)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidatePhoneNumber(t *testing.T) {
+	testCases := []struct {
+		name  string
+		phone string
+		valid bool
+	}{
+		{name: "US NUMBER", phone: "+14155552671", valid: true},
+		{name: "UK NUMBER", phone: "+442071838750", valid: true},
+		{name: "MISSING PLUS", phone: "14155552671"},
+		{name: "LEADING ZERO", phone: "+04155552671"},
+		{name: "SPACES", phone: "+1 415 555 2671"},
+		{name: "TOO LONG", phone: "+1234567890123456"},
+		{name: "EMPTY", phone: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePhoneNumber(tc.phone)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNormalizeUsernameAndEmail(t *testing.T) {
+	require.Equal(t, "alice", NormalizeUsername(" Alice "))
+	require.NoError(t, ValidateUsername(NormalizeUsername("ALICE_1")))
+	require.Equal(t, "alice@example.com", NormalizeEmail("Alice@Example.COM "))
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	require.Equal(t, "+14155552671", NormalizePhoneNumber("+1 415 555 2671"))
+	require.Equal(t, "+14155552671", NormalizePhoneNumber(" +1-415-555-2671 "))
+	require.Equal(t, "+442071838750", NormalizePhoneNumber("+44 20 7183-8750"))
+	require.NoError(t, ValidatePhoneNumber(NormalizePhoneNumber("+1 415-555 2671")))
+	require.Error(t, ValidatePhoneNumber(NormalizePhoneNumber("+1 (415) 555-2671")))
+	require.Equal(t, "", NormalizePhoneNumber("  "))
+}
+
+func TestNormalizeDescription(t *testing.T) {
+	require.Equal(t, "rent for March", NormalizeDescription(" rent\tfor\nMarch\r"))
+	require.Equal(t, "dinner", NormalizeDescription("din\x00ner\x1b"))
+	require.Equal(t, "", NormalizeDescription("\x07 \n"))
+
+	require.NoError(t, ValidateDescription(""))
+	require.NoError(t, ValidateDescription(strings.Repeat("é", MaxDescriptionLength)))
+	require.EqualError(t, ValidateDescription(strings.Repeat("é", MaxDescriptionLength+1)), "must contain at most 140 characters, it contains 141")
+}
+
+func TestValidateWebhookEvents(t *testing.T) {
+	require.NoError(t, ValidateWebhookEvents([]string{"transfer.completed", "deposit.completed", "withdrawal.completed"}))
+	require.Error(t, ValidateWebhookEvents(nil))
+	require.Error(t, ValidateWebhookEvents([]string{"ping"}))
+	require.Error(t, ValidateWebhookEvents([]string{"transfer.completed", "transfer.completed"}))
+}
+
+func TestValidateAmount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   string
+		currency string
+		valid    bool
+	}{
+		{name: "CENTS", amount: "10.50", currency: "USD", valid: true},
+		{name: "WHOLE", amount: "10", currency: "USD", valid: true},
+		{name: "YEN", amount: "1500", currency: "JPY", valid: true},
+		{name: "FILS", amount: "1.005", currency: "BHD", valid: true},
+		{name: "TOO PRECISE", amount: "10.005", currency: "USD"},
+		{name: "FRACTIONAL YEN", amount: "1.5", currency: "JPY"},
+		{name: "ZERO", amount: "0.00", currency: "USD"},
+		{name: "NEGATIVE", amount: "-1", currency: "USD"},
+		{name: "EXPONENT", amount: "1e3", currency: "USD"},
+		{name: "TOO LARGE", amount: "92233720368547758.08", currency: "USD"},
+		{name: "EMPTY", amount: "", currency: "USD"},
+		{name: "ZERO WHOLE", amount: "0", currency: "USD"},
+		{name: "SUB CENT", amount: "1.999", currency: "USD"},
+		{name: "NEGATIVE CENTS", amount: "-0.01", currency: "USD"},
+		{name: "LARGEST", amount: "1000000000.00", currency: "USD", valid: true},
+		{name: "OVER LARGEST", amount: "1000000000.01", currency: "USD"},
+		{name: "ABSURD", amount: "99999999999999", currency: "JPY"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAmount(tc.amount, tc.currency)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+
+	require.NoError(t, ValidateAmountUnits(100000000000, "USD"))
+	require.Error(t, ValidateAmountUnits(100000000001, "USD"))
+	require.Error(t, ValidateAmountUnits(0, "USD"))
+
+	require.NoError(t, ValidateOverdraftLimit("0", "USD"))
+	require.Error(t, ValidateOverdraftLimit("-0.01", "USD"))
+	require.NoError(t, ValidateOpeningBalance("0", "JPY"))
+	require.Error(t, ValidateOpeningBalance("-0.01", "USD"))
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the body, keyed with the webhook secret
+	SignatureHeader = "X-Swift-Bank-Signature"
+	EventHeader     = "X-Swift-Bank-Event"
+	DeliveryHeader  = "X-Swift-Bank-Delivery"
+)
+
+// DefaultTimeout bounds a single delivery attempt
+const DefaultTimeout = 10 * time.Second
+
+// NewEvent wraps data in an event with a fresh delivery id
+func NewEvent(eventType string, data any) (models.WebhookEvent, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return models.WebhookEvent{}, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	return models.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+	}, nil
+}
+
+// Sign returns the value of the signature header for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender posts signed events to webhook endpoints
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send posts the event to the webhook and returns the response status code. Any status
+// outside 2xx is reported as an error so the delivery is retried.
+func (s *Sender) Send(ctx context.Context, webhook models.Webhook, event models.WebhookEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID.String())
+
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post event: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	// drain so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return rsp.StatusCode, fmt.Errorf("endpoint responded with status %d", rsp.StatusCode)
+	}
+
+	return rsp.StatusCode, nil
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+func TestSendSignsBody(t *testing.T) {
+	secret := helpers.RandomString(32)
+	event, err := NewEvent(models.WebhookEventPing, map[string]string{"message": "hello"})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		require.Equal(t, Sign(secret, body), r.Header.Get(SignatureHeader))
+		require.Equal(t, models.WebhookEventPing, r.Header.Get(EventHeader))
+		require.Equal(t, event.ID.String(), r.Header.Get(DeliveryHeader))
+
+		var received models.WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &received))
+		require.Equal(t, event.ID, received.ID)
+		require.JSONEq(t, `{"message":"hello"}`, string(received.Data))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	code, err := NewSender(DefaultTimeout).Send(context.Background(), models.Webhook{URL: server.URL, Secret: secret}, event)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, code)
+}
+
+func TestSendFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	event, err := NewEvent(models.WebhookEventPing, nil)
+	require.NoError(t, err)
+
+	code, err := NewSender(DefaultTimeout).Send(context.Background(), models.Webhook{URL: server.URL, Secret: "secret"}, event)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadGateway, code)
+}
+
+func TestSign(t *testing.T) {
+	// reference value from the example in RFC 4231 test case 2
+	require.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", Sign("Jefe", []byte("what do ya want for nothing?")))
+	require.NotEqual(t, Sign("secret", []byte("body")), Sign("other", []byte("body")))
+}
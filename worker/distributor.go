@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hibiken/asynq"
+	"github.com/zde37/Swift_Bank/models"
 )
 
 type TaskDistributor interface {
@@ -12,13 +13,54 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSendVerifyPhone(
+		ctx context.Context,
+		payload *PayloadSendVerifyPhone,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskExecuteScheduledTransfer(
+		ctx context.Context,
+		payload *PayloadExecuteScheduledTransfer,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskDeliverWebhook(
+		ctx context.Context,
+		payload *PayloadDeliverWebhook,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskNotifyIncomingTransfer(
+		ctx context.Context,
+		payload *PayloadNotifyIncomingTransfer,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskNotifyNewLogin(
+		ctx context.Context,
+		payload *PayloadNotifyNewLogin,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendTransferAlertEmail(
+		ctx context.Context,
+		payload *PayloadSendTransferAlertEmail,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskEmailStatement(
+		ctx context.Context,
+		payload *PayloadEmailStatement,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskEmailTransactionExport(
+		ctx context.Context,
+		payload *PayloadEmailTransactionExport,
+		opts ...asynq.Option,
+	) error
+	DistributeOutboxMessage(ctx context.Context, message models.OutboxMessage) error
 }
 
 type RedisTaskDistributor struct {
 	client *asynq.Client
 }
 
-func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
+func NewRedisTaskDistributor(redisOpt asynq.RedisConnOpt) TaskDistributor {
 	client := asynq.NewClient(redisOpt)
 	return &RedisTaskDistributor{
 		client: client,
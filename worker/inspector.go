@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// FailedTask is a task asynq gave up on and moved to the archive
+type FailedTask struct {
+	ID           string
+	Queue        string
+	Type         string
+	Payload      string // emails are redacted
+	LastError    string
+	LastFailedAt time.Time
+	Retried      int
+	MaxRetry     int
+}
+
+// TaskInspector lists archived tasks and puts them back on their queue
+type TaskInspector interface {
+	ListFailedTasks(queue string, offset, limit int) ([]FailedTask, error)
+	RetryFailedTask(queue, id string) error
+}
+
+type RedisTaskInspector struct {
+	inspector *asynq.Inspector
+}
+
+func NewRedisTaskInspector(redisOpt asynq.RedisConnOpt) TaskInspector {
+	return &RedisTaskInspector{
+		inspector: asynq.NewInspector(redisOpt),
+	}
+}
+
+// ListFailedTasks returns the archived tasks of queue, most recently failed first
+func (i *RedisTaskInspector) ListFailedTasks(queue string, offset, limit int) ([]FailedTask, error) {
+	// asynq pages by page number, the archive is capped so reading up to the offset is cheap enough
+	infos, err := i.inspector.ListArchivedTasks(queue, asynq.PageSize(offset+limit), asynq.Page(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived tasks: %w", err)
+	}
+
+	if offset >= len(infos) {
+		return nil, nil
+	}
+
+	tasks := make([]FailedTask, 0, len(infos)-offset)
+	for _, info := range infos[offset:] {
+		tasks = append(tasks, FailedTask{
+			ID:           info.ID,
+			Queue:        info.Queue,
+			Type:         info.Type,
+			Payload:      redactEmails(string(info.Payload)),
+			LastError:    redactEmails(info.LastErr),
+			LastFailedAt: info.LastFailedAt,
+			Retried:      info.Retried,
+			MaxRetry:     info.MaxRetry,
+		})
+	}
+
+	return tasks, nil
+}
+
+// RetryFailedTask moves an archived task back to its queue to be processed right away
+func (i *RedisTaskInspector) RetryFailedTask(queue, id string) error {
+	if err := i.inspector.RunTask(queue, id); err != nil {
+		return fmt.Errorf("failed to run archived task: %w", err)
+	}
+
+	return nil
+}
+
+var emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*(@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// redactEmails keeps the first letter and the domain of every email address in s
+func redactEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, "$1***$2")
+}
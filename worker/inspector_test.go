@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactEmails(t *testing.T) {
+	require.Equal(t, `{"username":"ada","email":"a***@example.com"}`, redactEmails(`{"username":"ada","email":"ada.lovelace@example.com"}`))
+	require.Equal(t, "failed to add recipient b***@mail.swiftbank.test: 550 no such user",
+		redactEmails("failed to add recipient bob@mail.swiftbank.test: 550 no such user"))
+	require.Equal(t, `{"transaction_id":42}`, redactEmails(`{"transaction_id":42}`))
+}
+
+func TestHandleTaskErrorCountsFinalFailures(t *testing.T) {
+	count := func() int64 {
+		if v, ok := archivedTasks.Get(TaskSendVerifyEmail).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+
+	before := count()
+
+	// without retry information in the context the task is on its last attempt
+	handleTaskError(context.Background(), asynq.NewTask(TaskSendVerifyEmail, nil), errors.New("smtp down"))
+	require.Equal(t, before+1, count())
+
+	handleTaskError(context.Background(), asynq.NewTask(TaskSendVerifyEmail, nil), asynq.SkipRetry)
+	require.Equal(t, before+2, count())
+}
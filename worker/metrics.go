@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// status label values of tasks_processed_total
+const (
+	taskStatusSucceeded = "succeeded"
+	taskStatusRetried   = "retried"  // failed and will be tried again
+	taskStatusArchived  = "archived" // failed for the last time
+)
+
+// Metrics records task throughput and latency, and the depth of the queues
+type Metrics struct {
+	tasksProcessed *prometheus.CounterVec
+	taskDuration   *prometheus.HistogramVec
+	queueDepth     *prometheus.GaugeVec
+}
+
+// NewMetrics creates the worker metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		tasksProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tasks_processed_total",
+			Help: "Number of tasks processed, by type and outcome.",
+		}, []string{"type", "status"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_duration_seconds",
+			Help:    "Time taken to process a task, by type.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"type"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "queue_depth",
+			Help: "Number of tasks in a queue, by queue and state.",
+		}, []string{"queue", "state"}),
+	}
+
+	registerer.MustRegister(m.tasksProcessed, m.taskDuration, m.queueDepth)
+	return m
+}
+
+// isFinalAttempt reports whether a task that failed with err goes to the archive rather than
+// being retried. Without retry information in ctx the attempt is taken to be the last.
+func isFinalAttempt(ctx context.Context, err error) bool {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried >= maxRetry || errors.Is(err, asynq.SkipRetry)
+}
+
+func taskStatus(ctx context.Context, err error) string {
+	switch {
+	case err == nil:
+		return taskStatusSucceeded
+	case isFinalAttempt(ctx, err):
+		return taskStatusArchived
+	default:
+		return taskStatusRetried
+	}
+}
+
+// instrument counts and times every task. It must wrap limitRetries, which decides whether a
+// failed task is retried.
+func (m *Metrics) instrument(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		startTime := time.Now()
+		err := next.ProcessTask(ctx, task)
+
+		m.taskDuration.WithLabelValues(task.Type()).Observe(time.Since(startTime).Seconds())
+		m.tasksProcessed.WithLabelValues(task.Type(), taskStatus(ctx, err)).Inc()
+		return err
+	})
+}
+
+// PollQueueDepth updates queue_depth from redis every interval until ctx is cancelled
+func (m *Metrics) PollQueueDepth(ctx context.Context, inspector *asynq.Inspector, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.updateQueueDepth(inspector)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Metrics) updateQueueDepth(inspector *asynq.Inspector) {
+	for _, queue := range []string{QueueCritical, QueueDefault, QueueLow} {
+		info, err := inspector.GetQueueInfo(queue)
+		if err != nil {
+			// a queue nothing was enqueued on yet does not exist
+			if !errors.Is(err, asynq.ErrQueueNotFound) {
+				log.Error().Err(err).Str("queue", queue).Msg("failed to get queue info")
+			}
+			continue
+		}
+
+		m.queueDepth.WithLabelValues(queue, "pending").Set(float64(info.Pending))
+		m.queueDepth.WithLabelValues(queue, "active").Set(float64(info.Active))
+		m.queueDepth.WithLabelValues(queue, "scheduled").Set(float64(info.Scheduled))
+		m.queueDepth.WithLabelValues(queue, "retry").Set(float64(info.Retry))
+		m.queueDepth.WithLabelValues(queue, "archived").Set(float64(info.Archived))
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, registry *prometheus.Registry) string {
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsInstrument(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	fail := false
+	handler := metrics.instrument(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if fail {
+			return fmt.Errorf("smtp down: %w", asynq.SkipRetry)
+		}
+		return nil
+	}))
+
+	task := asynq.NewTask("task:fake", nil)
+	require.NoError(t, handler.ProcessTask(context.Background(), task))
+	require.NoError(t, handler.ProcessTask(context.Background(), task))
+
+	fail = true
+	require.Error(t, handler.ProcessTask(context.Background(), task))
+
+	body := scrape(t, registry)
+	require.Contains(t, body, `tasks_processed_total{status="succeeded",type="task:fake"} 2`)
+	require.Contains(t, body, `tasks_processed_total{status="archived",type="task:fake"} 1`)
+	require.Contains(t, body, `task_duration_seconds_count{type="task:fake"} 3`)
+}
+
+func TestTaskStatus(t *testing.T) {
+	require.Equal(t, taskStatusSucceeded, taskStatus(context.Background(), nil))
+	require.Equal(t, taskStatusArchived, taskStatus(context.Background(), asynq.SkipRetry))
+	// without retry information the attempt is the last one
+	require.Equal(t, taskStatusArchived, taskStatus(context.Background(), errors.New("smtp down")))
+}
+
+func TestMetricsQueueDepth(t *testing.T) {
+	distributor, inspector := newTestDistributor(t)
+	require.NoError(t, distributor.DistributeTaskSendVerifyEmail(context.Background(), &PayloadSendVerifyEmail{Username: "ada"}, asynq.Queue(QueueCritical)))
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	metrics.updateQueueDepth(inspector)
+
+	body := scrape(t, registry)
+	require.Contains(t, body, `queue_depth{queue="critical",state="pending"} 1`)
+	// nothing was ever enqueued on the low queue
+	require.NotContains(t, body, `queue="low"`)
+}
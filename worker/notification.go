@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+)
+
+// notificationPreferences checks the user's preferences before an optional notification is sent.
+// Transactional emails such as email verification must not call it. When the preferences cannot
+// be loaded the defaults are used rather than silently dropping the notification.
+func (processor *RedisTaskProcessor) notificationPreferences(ctx context.Context, username, notification, message string) (models.NotificationPreferences, bool) {
+	preferences, err := processor.service.GetNotificationPreferences(ctx, username)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("username", username).Msg("failed to get notification preferences")
+		return models.NotificationPreferences{Username: username}, true
+	}
+
+	if !preferences.Allows(notification) {
+		log.Ctx(ctx).Info().
+			Str("username", username).
+			Str("notification", notification).
+			Str("message", message).
+			Str("reason", "suppressed").
+			Msg("skipped notification")
+		return preferences, false
+	}
+
+	return preferences, true
+}
+
+// notificationChannel picks SMS for users who prefer it and verified their phone number, email otherwise
+func (processor *RedisTaskProcessor) notificationChannel(user models.User, preferences models.NotificationPreferences) string {
+	if preferences.PreferSMS && user.PhoneNumber != "" && user.IsPhoneVerified && processor.notifier.Supports(notify.ChannelSMS) {
+		return notify.ChannelSMS
+	}
+	return notify.ChannelEmail
+}
+
+// sendNotification sends html and text by email or only text by SMS. Errors are returned so that,
+// like email failures, a failed SMS can be retried by asynq.
+func (processor *RedisTaskProcessor) sendNotification(ctx context.Context, kind string, user models.User, preferences models.NotificationPreferences, subject, html, text string) error {
+	channel := processor.notificationChannel(user, preferences)
+
+	recipient := notify.Recipient{
+		Name:  user.FullName,
+		Email: user.Email,
+		Phone: user.PhoneNumber,
+	}
+	if err := processor.notifier.Send(ctx, recipient, notify.Message{Subject: subject, HTML: html, Text: text}, channel); err != nil {
+		address := recipient.Email
+		if channel == notify.ChannelSMS {
+			address = recipient.Phone
+		}
+
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      kind,
+			Channel:   channel,
+			Recipient: address,
+			Subject:   subject,
+		}, fmt.Errorf("failed to send %s notification: %w", channel, err))
+	}
+
+	return nil
+}
+
+// sendFailed decides whether a failed send is worth retrying. Transient errors are returned as they
+// are so asynq retries them with backoff. Permanent ones, such as an unknown recipient, are written
+// to failed_notifications for later inspection and returned wrapping asynq.SkipRetry.
+func (processor *RedisTaskProcessor) sendFailed(ctx context.Context, failed models.FailedNotification, err error) error {
+	if !mail.IsPermanent(err) {
+		return err
+	}
+
+	failed.Error = err.Error()
+	if _, recordErr := processor.repo.CreateFailedNotification(ctx, failed); recordErr != nil {
+		log.Ctx(ctx).Error().Err(recordErr).Str("username", failed.Username).Str("kind", failed.Kind).Msg("failed to record failed notification")
+	}
+
+	return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+}
+
+// recordNotification adds an entry to the user's inbox. It is written by the same handlers
+// that send the matching email, but preferences only cover email so the inbox always gets it.
+func (processor *RedisTaskProcessor) recordNotification(ctx context.Context, notification models.Notification) error {
+	if _, err := processor.repo.CreateNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/repository"
+)
+
+// outboxBatchSize is how many messages the relay moves to the queue per transaction
+const outboxBatchSize = 100
+
+// NewOutboxMessage builds an outbox message that the relay enqueues as a task of the given type.
+// The payload keeps the request id of ctx, so the task can be traced back to the request.
+func NewOutboxMessage(ctx context.Context, taskType string, payload any, queue string, maxRetry int32) (models.OutboxMessage, error) {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return models.OutboxMessage{}, fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	return models.OutboxMessage{
+		TaskType: taskType,
+		Payload:  jsonPayload,
+		Queue:    queue,
+		MaxRetry: maxRetry,
+	}, nil
+}
+
+// DistributeOutboxMessage enqueues the task of an outbox message. The task id is derived from the
+// message, so a message relayed twice while its task is still known to redis is enqueued once.
+func (distributor *RedisTaskDistributor) DistributeOutboxMessage(ctx context.Context, message models.OutboxMessage) error {
+	task := asynq.NewTask(message.TaskType, message.Payload,
+		asynq.Queue(message.Queue),
+		asynq.MaxRetry(int(message.MaxRetry)),
+		asynq.TaskID(fmt.Sprintf("outbox-%d", message.ID)),
+	)
+
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return nil
+		}
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Int64("outbox_id", message.ID).
+		Str("request_id", payloadRequestID(message.Payload)).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// OutboxRelay moves messages from the outbox to the task queue. A message is only marked sent
+// after it was enqueued, so tasks may be delivered more than once and their handlers must
+// tolerate that.
+type OutboxRelay struct {
+	repo        repository.RepositoryProvider
+	distributor TaskDistributor
+	interval    time.Duration
+}
+
+func NewOutboxRelay(repo repository.RepositoryProvider, distributor TaskDistributor, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repo:        repo,
+		distributor: distributor,
+		interval:    interval,
+	}
+}
+
+// Run relays the outbox every interval until ctx is cancelled
+func (relay *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := relay.Relay(ctx); err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("failed to relay outbox")
+			}
+		}
+	}
+}
+
+// Relay enqueues the unsent messages until the outbox is empty
+func (relay *OutboxRelay) Relay(ctx context.Context) error {
+	for {
+		sent, err := relay.repo.RelayOutboxTx(ctx, outboxBatchSize, func(message models.OutboxMessage) error {
+			return relay.distributor.DistributeOutboxMessage(ctx, message)
+		})
+		if err != nil {
+			return err
+		}
+
+		if sent < outboxBatchSize {
+			return nil
+		}
+	}
+}
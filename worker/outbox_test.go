@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRelayEnqueuesOutboxMessagesOnce(t *testing.T) {
+	distributor, inspector := newTestDistributor(t)
+
+	message, err := NewOutboxMessage(context.Background(), TaskSendVerifyEmail, &PayloadSendVerifyEmail{Username: "ada", VerifyEmailID: 7}, QueueCritical, 10)
+	require.NoError(t, err)
+	message.ID = 1
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().
+		RelayOutboxTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, limit int32, send func(models.OutboxMessage) error) (int, error) {
+			// the second relay sees the message again, as if marking it sent had failed
+			if err := send(message); err != nil {
+				return 0, err
+			}
+			return 1, nil
+		})
+
+	relay := NewOutboxRelay(repo, distributor, time.Second)
+	require.NoError(t, relay.Relay(context.Background()))
+	require.NoError(t, relay.Relay(context.Background()))
+
+	tasks, err := inspector.ListPendingTasks(QueueCritical)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, TaskSendVerifyEmail, tasks[0].Type)
+	require.Equal(t, 10, tasks[0].MaxRetry)
+	require.JSONEq(t, `{"username":"ada","verify_email_id":7}`, string(tasks[0].Payload))
+}
+
+func TestProcessTaskSendVerifyEmailReusesCode(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	verifyEmail := models.VerifyEmails{
+		ID:         7,
+		Username:   user.UserName,
+		Email:      user.Email,
+		SecretCode: helpers.RandomString(32),
+		ExpiredAt:  time.Now().Add(10 * time.Minute),
+	}
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(3).Return(user, nil)
+	repo.EXPECT().GetVerifyEmail(gomock.Any(), gomock.Eq(verifyEmail.ID)).Times(2).Return(verifyEmail, nil)
+
+	// a second delivery of the task sends the same code again
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Nil(), gomock.Nil(), gomock.Nil()).
+		Times(2).
+		DoAndReturn(func(subject, content, text string, to, cc, bcc []string, attachments []mail.Attachment) error {
+			require.Contains(t, content, "secret_code="+verifyEmail.SecretCode)
+			return nil
+		})
+
+	processor := &RedisTaskProcessor{repo: repo, mailer: mailer}
+
+	payload, err := json.Marshal(PayloadSendVerifyEmail{Username: user.UserName, VerifyEmailID: verifyEmail.ID})
+	require.NoError(t, err)
+	task := asynq.NewTask(TaskSendVerifyEmail, payload)
+
+	require.NoError(t, processor.ProcessTaskSendVerifyEmail(context.Background(), task))
+	require.NoError(t, processor.ProcessTaskSendVerifyEmail(context.Background(), task))
+
+	// once the code was used nothing is sent
+	verifyEmail.IsUsed = true
+	repo.EXPECT().GetVerifyEmail(gomock.Any(), gomock.Eq(verifyEmail.ID)).Times(1).Return(verifyEmail, nil)
+	require.NoError(t, processor.ProcessTaskSendVerifyEmail(context.Background(), task))
+}
@@ -2,55 +2,237 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/rates"
 	"github.com/zde37/Swift_Bank/repository"
+	"github.com/zde37/Swift_Bank/service"
+	"github.com/zde37/Swift_Bank/storage"
+	"github.com/zde37/Swift_Bank/webhook"
 )
 
 const (
 	QueueCritical = "critical"
 	QueueDefault  = "default"
+	QueueLow      = "low"
 )
 
+// QueueWeights sets how often each queue is picked relative to the others, so a
+// burst of low priority tasks cannot starve transactional email
+type QueueWeights struct {
+	Critical int
+	Default  int
+	Low      int
+}
+
+func (w QueueWeights) queues() map[string]int {
+	return map[string]int{
+		QueueCritical: w.Critical,
+		QueueDefault:  w.Default,
+		QueueLow:      w.Low,
+	}
+}
+
 type TaskProcessor interface {
 	Start() error
+	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendVerifyPhone(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPurgeIdempotencyKeys(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPurgeSessions(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPurgeVerifyEmails(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPurgeDeletedAccounts(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRefreshExchangeRates(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRunDueStandingOrders(ctx context.Context, task *asynq.Task) error
+	ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
+	ProcessTaskNotifyIncomingTransfer(ctx context.Context, task *asynq.Task) error
+	ProcessTaskNotifyNewLogin(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTransferAlertEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskEmailStatement(ctx context.Context, task *asynq.Task) error
+	ProcessTaskEmailTransactionExport(ctx context.Context, task *asynq.Task) error
+	ProcessTaskGenerateStatementPDF(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	repo   repository.RepositoryProvider
-	mailer mail.EmailSender
+	server   *asynq.Server
+	repo     repository.RepositoryProvider
+	service  service.ServiceProvider
+	mailer   mail.EmailSender
+	notifier notify.Notifier
+	webhooks *webhook.Sender
+	maxRetry map[string]int
+	metrics  *Metrics
+	files    storage.Store
+	rates    rates.Provider // nil when no provider is configured
+
+	statementURLTTL time.Duration
+}
+
+// emailTaskMaxRetry caps the retries of tasks sending email, whatever MaxRetry they were enqueued with
+var emailTaskMaxRetry = map[string]int{
+	TaskSendVerifyEmail:        10,
+	TaskSendTransferAlertEmail: 3,
+	TaskEmailStatement:         5,
+	TaskEmailTransactionExport: 5,
+	TaskGenerateStatementPDF:   3,
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, repo repository.RepositoryProvider, mailer mail.EmailSender) TaskProcessor {
+// NewRedisTaskProcessor creates a processor that, on Shutdown, waits up to shutdownTimeout
+// for running tasks before handing them back to their queue
+func NewRedisTaskProcessor(redisOpt asynq.RedisConnOpt, weights QueueWeights, shutdownTimeout time.Duration, metrics *Metrics, repo repository.RepositoryProvider, service service.ServiceProvider, mailer mail.EmailSender, notifier notify.Notifier, files storage.Store, statementURLTTL time.Duration, rateProvider rates.Provider) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
-			Queues: map[string]int{
-				QueueCritical: 10,
-				QueueDefault:  5,
-			},
-			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				log.Error().Err(err).Str("type", task.Type()).Bytes("payload", task.Payload()).Msg("process task failed")
-			}),
-			Logger: NewLogger(),
+			Queues:          weights.queues(),
+			ShutdownTimeout: shutdownTimeout,
+			ErrorHandler:    asynq.ErrorHandlerFunc(handleTaskError),
+			RetryDelayFunc:  retryDelay,
+			Logger:          NewLogger(),
 		},
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
-		repo:   repo,
-		mailer: mailer,
+		server:   server,
+		repo:     repo,
+		service:  service,
+		mailer:   mailer,
+		notifier: notifier,
+		webhooks: webhook.NewSender(webhook.DefaultTimeout),
+		maxRetry: emailTaskMaxRetry,
+		metrics:  metrics,
+		files:    files,
+		rates:    rateProvider,
+
+		statementURLTTL: statementURLTTL,
+	}
+}
+
+// archivedTasks counts the tasks by type that failed for the last time and went to the archive
+var archivedTasks = expvar.NewMap("worker_archived_tasks")
+
+// handleTaskError logs every failed attempt, and counts and logs separately the ones that will not
+// be retried so they can be looked at with ListFailedTasks
+func handleTaskError(ctx context.Context, task *asynq.Task, err error) {
+	if !isFinalAttempt(ctx, err) {
+		log.Error().Err(err).Str("type", task.Type()).Bytes("payload", task.Payload()).Msg("process task failed")
+		return
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	taskID, _ := asynq.GetTaskID(ctx)
+	archivedTasks.Add(task.Type(), 1)
+	log.Error().
+		Err(err).
+		Str("type", task.Type()).
+		Str("task_id", taskID).
+		Str("request_id", payloadRequestID(task.Payload())).
+		Int("retried", retried).
+		Msg("task failed for the last time and was archived")
+}
+
+// retryDelay backs webhook deliveries and emails off exponentially, other tasks keep asynq's default
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhookRetryDelay(n)
+	}
+
+	if _, ok := emailTaskMaxRetry[task.Type()]; ok {
+		return emailRetryDelay(n, rand.Float64())
 	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
+
+// emailRetryDelay doubles the wait after every failed send, starting at 30 seconds and capped at an
+// hour. Up to half of it is added at random so emails that failed together are not retried together.
+func emailRetryDelay(n int, jitter float64) time.Duration {
+	const maxDelay = time.Hour
+	delay := maxDelay
+	if n < 7 {
+		delay = min(30*time.Second<<n, maxDelay)
+	}
+
+	return delay + time.Duration(jitter*float64(delay)/2)
+}
+
+// limitRetries stops retrying a task once it failed more often than maxRetry allows for its type
+func (processor *RedisTaskProcessor) limitRetries(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		err := next.ProcessTask(ctx, task)
+		if err == nil || errors.Is(err, asynq.SkipRetry) {
+			return err
+		}
+
+		maxRetry, ok := processor.maxRetry[task.Type()]
+		if !ok {
+			return err
+		}
+
+		if retried, _ := asynq.GetRetryCount(ctx); retried >= maxRetry {
+			return fmt.Errorf("%w: gave up after %d retries: %w", err, retried, asynq.SkipRetry)
+		}
+
+		return err
+	})
+}
+
+// isLastAttempt tells whether the task will not be retried if it fails now, so a handler can record
+// the failure before giving up
+func (processor *RedisTaskProcessor) isLastAttempt(ctx context.Context, taskType string) bool {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, ok := processor.maxRetry[taskType]
+	if enqueued, found := asynq.GetMaxRetry(ctx); found && (!ok || enqueued < maxRetry) {
+		maxRetry, ok = enqueued, true
+	}
+
+	return ok && retried >= maxRetry
 }
 
 func (processor *RedisTaskProcessor) Start() error {
+	return processor.server.Start(processor.serveMux())
+}
+
+// Shutdown stops fetching new tasks and waits for the running ones to finish
+func (processor *RedisTaskProcessor) Shutdown() {
+	processor.server.Shutdown()
+}
+
+func (processor *RedisTaskProcessor) serveMux() *asynq.ServeMux {
 	mux := asynq.NewServeMux()
+	mux.Use(withTrace)
+	mux.Use(withRequestID)
+	if processor.metrics != nil {
+		mux.Use(processor.metrics.instrument)
+	}
+	mux.Use(processor.limitRetries)
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendVerifyPhone, processor.ProcessTaskSendVerifyPhone)
+	mux.HandleFunc(TaskPurgeIdempotencyKeys, processor.ProcessTaskPurgeIdempotencyKeys)
+	mux.HandleFunc(TaskPurgeSessions, processor.ProcessTaskPurgeSessions)
+	mux.HandleFunc(TaskPurgeVerifyEmails, processor.ProcessTaskPurgeVerifyEmails)
+	mux.HandleFunc(TaskPurgeDeletedAccounts, processor.ProcessTaskPurgeDeletedAccounts)
+	mux.HandleFunc(TaskRefreshExchangeRates, processor.ProcessTaskRefreshExchangeRates)
+	mux.HandleFunc(TaskExecuteScheduledTransfer, processor.ProcessTaskExecuteScheduledTransfer)
+	mux.HandleFunc(TaskRunDueStandingOrders, processor.ProcessTaskRunDueStandingOrders)
+	mux.HandleFunc(TaskAccrueInterest, processor.ProcessTaskAccrueInterest)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
+	mux.HandleFunc(TaskNotifyIncomingTransfer, processor.ProcessTaskNotifyIncomingTransfer)
+	mux.HandleFunc(TaskNotifyNewLogin, processor.ProcessTaskNotifyNewLogin)
+	mux.HandleFunc(TaskSendTransferAlertEmail, processor.ProcessTaskSendTransferAlertEmail)
+	mux.HandleFunc(TaskEmailStatement, processor.ProcessTaskEmailStatement)
+	mux.HandleFunc(TaskEmailTransactionExport, processor.ProcessTaskEmailTransactionExport)
+	mux.HandleFunc(TaskGenerateStatementPDF, processor.ProcessTaskGenerateStatementPDF)
 
-	return processor.server.Start(mux)
+	return mux
 }
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/models"
+)
+
+func TestServeMuxHandlesEveryTask(t *testing.T) {
+	processor := &RedisTaskProcessor{}
+	mux := processor.serveMux()
+
+	taskTypes := []string{
+		TaskSendVerifyEmail,
+		TaskPurgeIdempotencyKeys,
+		TaskPurgeSessions,
+		TaskPurgeVerifyEmails,
+		TaskPurgeDeletedAccounts,
+		TaskRefreshExchangeRates,
+		TaskExecuteScheduledTransfer,
+		TaskRunDueStandingOrders,
+		TaskAccrueInterest,
+		TaskDeliverWebhook,
+		TaskNotifyIncomingTransfer,
+		TaskNotifyNewLogin,
+		TaskSendTransferAlertEmail,
+		TaskEmailStatement,
+		TaskGenerateStatementPDF,
+	}
+	for _, taskType := range taskTypes {
+		_, pattern := mux.Handler(asynq.NewTask(taskType, nil))
+		require.Equal(t, taskType, pattern)
+	}
+}
+
+func TestQueueWeights(t *testing.T) {
+	weights := QueueWeights{Critical: 6, Default: 3, Low: 1}
+	require.Equal(t, map[string]int{QueueCritical: 6, QueueDefault: 3, QueueLow: 1}, weights.queues())
+}
+
+func newTestDistributor(t *testing.T) (TaskDistributor, *asynq.Inspector) {
+	server := miniredis.RunT(t)
+	redisOpt := asynq.RedisClientOpt{Addr: server.Addr()}
+
+	distributor := NewRedisTaskDistributor(redisOpt)
+	inspector := asynq.NewInspector(redisOpt)
+	t.Cleanup(func() {
+		distributor.(*RedisTaskDistributor).client.Close()
+		inspector.Close()
+	})
+
+	return distributor, inspector
+}
+
+func requireQueueSize(t *testing.T, inspector *asynq.Inspector, queue string, size int) {
+	info, err := inspector.GetQueueInfo(queue)
+	require.NoError(t, err)
+	require.Equal(t, size, info.Size)
+}
+
+func TestEnqueueQueues(t *testing.T) {
+	distributor, inspector := newTestDistributor(t)
+	ctx := context.Background()
+
+	// the options create user passes for the verification email
+	err := distributor.DistributeTaskSendVerifyEmail(ctx, &PayloadSendVerifyEmail{Username: "ada"}, asynq.Queue(QueueCritical))
+	require.NoError(t, err)
+
+	alerts := NewTransferAlertDispatcher(distributor, nil)
+	require.NoError(t, alerts.AlertLargeTransfer(ctx, models.Transaction{ID: 1}))
+	requireQueueSize(t, inspector, QueueCritical, 2)
+
+	statement := &PayloadEmailStatement{Username: "ada", AccountID: 1, Year: 2024, Month: 1}
+	require.NoError(t, distributor.DistributeTaskEmailStatement(ctx, statement))
+	requireQueueSize(t, inspector, QueueLow, 1)
+
+	// callers can still pick another queue for a statement
+	require.NoError(t, distributor.DistributeTaskEmailStatement(ctx, statement, asynq.Queue(QueueDefault)))
+	requireQueueSize(t, inspector, QueueDefault, 1)
+}
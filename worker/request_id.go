@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+// requestIDField is added to every task payload enqueued on behalf of a request
+const requestIDField = "request_id"
+
+// marshalPayload encodes a task payload together with the request id and trace context of ctx,
+// if there are any
+func marshalPayload(ctx context.Context, payload any) ([]byte, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id := requestid.FromContext(ctx)
+	trace := traceCarrier(ctx)
+	if id == "" && len(trace) == 0 {
+		return jsonPayload, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonPayload, &fields); err != nil {
+		return nil, err
+	}
+
+	if id != "" {
+		if fields[requestIDField], err = json.Marshal(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(trace) != 0 {
+		if fields[traceField], err = json.Marshal(trace); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// payloadRequestID returns the request id a payload was enqueued with, or ""
+func payloadRequestID(payload []byte) string {
+	var fields struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return ""
+	}
+
+	return fields.RequestID
+}
+
+// withRequestID restores the request id of a task into the handler context and gives the
+// handler a logger that tags every line with it
+func withRequestID(handler asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		logger := log.Logger
+		if id := payloadRequestID(task.Payload()); id != "" {
+			ctx = requestid.NewContext(ctx, id)
+			logger = logger.With().Str(requestIDField, id).Logger()
+		}
+
+		return handler.ProcessTask(logger.WithContext(ctx), task)
+	})
+}
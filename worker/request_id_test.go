@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/requestid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMarshalPayloadRequestID(t *testing.T) {
+	payload := &PayloadSendVerifyEmail{Username: "ada"}
+
+	jsonPayload, err := marshalPayload(context.Background(), payload)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"username":"ada"}`, string(jsonPayload))
+	require.Empty(t, payloadRequestID(jsonPayload))
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	jsonPayload, err = marshalPayload(ctx, payload)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"username":"ada","request_id":"req-1"}`, string(jsonPayload))
+	require.Equal(t, "req-1", payloadRequestID(jsonPayload))
+}
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	distributor, inspector := newTestDistributor(t)
+
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	verifyEmail := models.VerifyEmails{ID: 7, Email: user.Email, SecretCode: helpers.RandomString(32), ExpiredAt: time.Now().Add(time.Minute)}
+
+	id := requestid.New()
+	ctx := requestid.NewContext(context.Background(), id)
+	payload := &PayloadSendVerifyEmail{Username: user.UserName, VerifyEmailID: verifyEmail.ID}
+	require.NoError(t, distributor.DistributeTaskSendVerifyEmail(ctx, payload, asynq.Queue(QueueCritical)))
+
+	tasks, err := inspector.ListPendingTasks(QueueCritical)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	// the handler sees the id in its context and every line it logs carries it
+	var logs bytes.Buffer
+	defaultLogger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	t.Cleanup(func() { log.Logger = defaultLogger })
+
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.UserName)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, username string) (models.User, error) {
+			require.Equal(t, id, requestid.FromContext(ctx))
+			return user, nil
+		})
+	repo.EXPECT().GetVerifyEmail(gomock.Any(), gomock.Eq(verifyEmail.ID)).Times(1).Return(verifyEmail, nil)
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+	processor := &RedisTaskProcessor{repo: repo, mailer: mailer}
+	task := asynq.NewTask(tasks[0].Type, tasks[0].Payload)
+	require.NoError(t, processor.serveMux().ProcessTask(context.Background(), task))
+	require.Contains(t, logs.String(), `"message":"processed task"`)
+	require.Contains(t, logs.String(), `"request_id":"`+id+`"`)
+}
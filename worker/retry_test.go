@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskSendVerifyEmailRetries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sendErr   error
+		permanent bool
+	}{
+		{
+			name:    "NETWORK ERROR",
+			sendErr: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		},
+		{
+			name:    "SMTP 421",
+			sendErr: &textproto.Error{Code: 421, Msg: "Service not available, try again later"},
+		},
+		{
+			name:    "PROVIDER THROTTLED",
+			sendErr: &mail.ProviderError{Provider: mail.ProviderSendGrid, StatusCode: 429, Message: "too many requests"},
+		},
+		{
+			name:      "SMTP 550",
+			sendErr:   &textproto.Error{Code: 550, Msg: "No such user here"},
+			permanent: true,
+		},
+		{
+			name:      "PROVIDER REJECTED",
+			sendErr:   &mail.ProviderError{Provider: mail.ProviderSES, StatusCode: 400, Code: "MessageRejected", Message: "Email address is not verified"},
+			permanent: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+			repo.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(models.VerifyEmails{ID: 1, Username: user.UserName, Email: user.Email, SecretCode: helpers.RandomString(32)}, nil)
+
+			failures := 0
+			if tc.permanent {
+				failures = 1
+			}
+			repo.EXPECT().
+				CreateFailedNotification(gomock.Any(), gomock.Any()).
+				Times(failures).
+				DoAndReturn(func(ctx context.Context, arg models.FailedNotification) (models.FailedNotification, error) {
+					require.Equal(t, user.UserName, arg.Username)
+					require.Equal(t, models.NotificationKindVerifyEmail, arg.Kind)
+					require.Equal(t, notify.ChannelEmail, arg.Channel)
+					require.Equal(t, user.Email, arg.Recipient)
+					require.Contains(t, arg.Error, tc.sendErr.Error())
+					return arg, nil
+				})
+
+			mailer := mockedproviders.NewMockEmailSender(ctrl)
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(fmt.Errorf("failed to send: %w", tc.sendErr))
+
+			processor := &RedisTaskProcessor{
+				repo:   repo,
+				mailer: mailer,
+			}
+
+			payload, err := json.Marshal(PayloadSendVerifyEmail{Username: user.UserName})
+			require.NoError(t, err)
+
+			err = processor.ProcessTaskSendVerifyEmail(context.Background(), asynq.NewTask(TaskSendVerifyEmail, payload))
+			require.Error(t, err)
+			require.Equal(t, tc.permanent, errors.Is(err, asynq.SkipRetry))
+		})
+	}
+}
+
+func TestLimitRetries(t *testing.T) {
+	failing := asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		return errors.New("temporary failure")
+	})
+
+	processor := &RedisTaskProcessor{
+		maxRetry: map[string]int{TaskSendVerifyEmail: 0},
+	}
+	handler := processor.limitRetries(failing)
+
+	// out of retries for its type
+	err := handler.ProcessTask(context.Background(), asynq.NewTask(TaskSendVerifyEmail, nil))
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+
+	// no limit configured for the type
+	err = handler.ProcessTask(context.Background(), asynq.NewTask(TaskDeliverWebhook, nil))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, asynq.SkipRetry))
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, emailRetryDelay(0, 0))
+	require.Equal(t, 45*time.Second, emailRetryDelay(0, 1))
+	require.Equal(t, 2*time.Minute, emailRetryDelay(2, 0))
+	require.Equal(t, time.Hour, emailRetryDelay(7, 0))
+	require.Equal(t, time.Hour, emailRetryDelay(100, 0))
+	require.Equal(t, 90*time.Minute, emailRetryDelay(100, 1))
+}
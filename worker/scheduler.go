@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// CleanupTasks selects which purge tasks the scheduler enqueues
+type CleanupTasks struct {
+	Sessions        bool
+	VerifyEmails    bool
+	IdempotencyKeys bool
+	// DeletedAccountRetentionYears is how long soft deleted accounts are kept, 0 keeps them forever
+	DeletedAccountRetentionYears int
+}
+
+// periodicTasks provides the maintenance tasks the scheduler enqueues
+type periodicTasks struct {
+	cleanup       CleanupTasks
+	rateRefresh   time.Duration      // how often exchange rates are refreshed, 0 never
+	interestRates map[string]float64 // yearly percent by currency, savings earn nothing without any
+}
+
+func (p periodicTasks) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	configs := []*asynq.PeriodicTaskConfig{
+		// standing orders run at most once a day, so hourly is precise enough
+		{
+			Cronspec: "@hourly",
+			Task:     asynq.NewTask(TaskRunDueStandingOrders, nil),
+			Opts:     []asynq.Option{asynq.Queue(QueueCritical)},
+		},
+	}
+
+	if p.cleanup.Sessions {
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@hourly",
+			Task:     asynq.NewTask(TaskPurgeSessions, nil),
+			Opts:     []asynq.Option{asynq.Queue(QueueLow)},
+		})
+	}
+
+	if p.cleanup.VerifyEmails {
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@daily",
+			Task:     asynq.NewTask(TaskPurgeVerifyEmails, nil),
+			Opts:     []asynq.Option{asynq.Queue(QueueLow)},
+		})
+	}
+
+	// expired keys are already ignored by lookups, purging only reclaims space
+	if p.cleanup.IdempotencyKeys {
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@daily",
+			Task:     asynq.NewTask(TaskPurgeIdempotencyKeys, nil),
+			Opts:     []asynq.Option{asynq.Queue(QueueLow)},
+		})
+	}
+
+	if p.cleanup.DeletedAccountRetentionYears > 0 {
+		payload, err := json.Marshal(PayloadPurgeDeletedAccounts{RetentionYears: p.cleanup.DeletedAccountRetentionYears})
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@daily",
+			Task:     asynq.NewTask(TaskPurgeDeletedAccounts, payload),
+			Opts:     []asynq.Option{asynq.Queue(QueueLow)},
+		})
+	}
+
+	// at midnight UTC, for the day that just ended
+	if len(p.interestRates) > 0 {
+		payload, err := json.Marshal(PayloadAccrueInterest{Rates: p.interestRates})
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@daily",
+			Task:     asynq.NewTask(TaskAccrueInterest, payload),
+			Opts:     []asynq.Option{asynq.Queue(QueueCritical)},
+		})
+	}
+
+	// a refresh that outlives its interval is not started a second time
+	if p.rateRefresh > 0 {
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: "@every " + p.rateRefresh.String(),
+			Task:     asynq.NewTask(TaskRefreshExchangeRates, nil),
+			Opts:     []asynq.Option{asynq.Queue(QueueDefault), asynq.MaxRetry(2), asynq.Unique(p.rateRefresh)},
+		})
+	}
+
+	return configs, nil
+}
+
+// NewScheduler creates a manager that enqueues the periodic tasks, refreshing exchange rates every
+// rateRefresh unless it is 0 and accruing interest at interestRates unless there are none
+func NewScheduler(redisOpt asynq.RedisConnOpt, cleanup CleanupTasks, rateRefresh time.Duration, interestRates map[string]float64) (*asynq.PeriodicTaskManager, error) {
+	return asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               redisOpt,
+		PeriodicTaskConfigProvider: periodicTasks{cleanup: cleanup, rateRefresh: rateRefresh, interestRates: interestRates},
+		SchedulerOpts: &asynq.SchedulerOpts{
+			Location: time.UTC,
+			Logger:   NewLogger(),
+		},
+	})
+}
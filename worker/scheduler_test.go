@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scheduledTasks(t *testing.T, cleanup CleanupTasks) map[string]string {
+	configs, err := periodicTasks{cleanup: cleanup}.GetConfigs()
+	require.NoError(t, err)
+
+	cronspecs := make(map[string]string, len(configs))
+	for _, config := range configs {
+		cronspecs[config.Task.Type()] = config.Cronspec
+	}
+	return cronspecs
+}
+
+func TestPeriodicTasks(t *testing.T) {
+	cronspecs := scheduledTasks(t, CleanupTasks{Sessions: true, VerifyEmails: true, IdempotencyKeys: true, DeletedAccountRetentionYears: 7})
+	require.Equal(t, map[string]string{
+		TaskRunDueStandingOrders: "@hourly",
+		TaskPurgeSessions:        "@hourly",
+		TaskPurgeVerifyEmails:    "@daily",
+		TaskPurgeIdempotencyKeys: "@daily",
+		TaskPurgeDeletedAccounts: "@daily",
+	}, cronspecs)
+}
+
+func TestPeriodicTasksCleanupDisabled(t *testing.T) {
+	cronspecs := scheduledTasks(t, CleanupTasks{VerifyEmails: true})
+	require.Equal(t, map[string]string{
+		TaskRunDueStandingOrders: "@hourly",
+		TaskPurgeVerifyEmails:    "@daily",
+	}, cronspecs)
+}
+
+func TestPeriodicTasksPurgeDeletedAccounts(t *testing.T) {
+	configs, err := periodicTasks{cleanup: CleanupTasks{DeletedAccountRetentionYears: 7}}.GetConfigs()
+	require.NoError(t, err)
+
+	var payload PayloadPurgeDeletedAccounts
+	for _, config := range configs {
+		if config.Task.Type() == TaskPurgeDeletedAccounts {
+			require.NoError(t, json.Unmarshal(config.Task.Payload(), &payload))
+		}
+	}
+	require.Equal(t, 7, payload.RetentionYears)
+}
+
+func TestPeriodicTasksAccrueInterest(t *testing.T) {
+	configs, err := periodicTasks{interestRates: map[string]float64{"USD": 2.5}}.GetConfigs()
+	require.NoError(t, err)
+
+	var payload PayloadAccrueInterest
+	for _, config := range configs {
+		if config.Task.Type() == TaskAccrueInterest {
+			require.Equal(t, "@daily", config.Cronspec)
+			require.NoError(t, json.Unmarshal(config.Task.Payload(), &payload))
+		}
+	}
+	require.Equal(t, map[string]float64{"USD": 2.5}, payload.Rates)
+
+	// without rates nothing accrues
+	require.NotContains(t, scheduledTasks(t, CleanupTasks{}), TaskAccrueInterest)
+}
+
+func TestPeriodicTasksRefreshExchangeRates(t *testing.T) {
+	configs, err := periodicTasks{rateRefresh: 5 * time.Minute}.GetConfigs()
+	require.NoError(t, err)
+
+	cronspecs := make(map[string]string, len(configs))
+	for _, config := range configs {
+		cronspecs[config.Task.Type()] = config.Cronspec
+	}
+	require.Equal(t, "@every 5m0s", cronspecs[TaskRefreshExchangeRates])
+}
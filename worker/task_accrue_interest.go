@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/service"
+)
+
+const TaskAccrueInterest = "task:accrue_interest"
+
+// interestBatchSize is how many accounts are loaded at a time
+const interestBatchSize = 100
+
+type PayloadAccrueInterest struct {
+	Rates map[string]float64 `json:"rates"` // yearly, in percent by currency
+}
+
+// ProcessTaskAccrueInterest accrues a day of interest on every account that earns it, for the UTC
+// day before the task runs. Every run also pays the interest accrued in closed months into the
+// balances, so a month is paid on its first run after it ended, whether or not that is on the
+// first. A run that is repeated or retried accrues each day and pays each accrual once.
+func (processor *RedisTaskProcessor) ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadAccrueInterest
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	date, accrued, paid, err := processor.accrueInterest(ctx, payload.Rates, time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Time("date", date).
+		Int("accrued", accrued).
+		Int("paid", paid).
+		Msg("processed task")
+
+	return nil
+}
+
+// accrueInterest accrues the interest of the day before now and pays what was accrued before the
+// month of now. It returns the day accrued and how many accounts accrued and were paid.
+func (processor *RedisTaskProcessor) accrueInterest(ctx context.Context, rates map[string]float64, now time.Time) (time.Time, int, int, error) {
+	now = now.UTC()
+	date := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var accrued, paid int
+	var afterID int64
+
+	for {
+		accounts, err := processor.service.ListInterestAccounts(ctx, models.ListInterestAccountsParams{AfterID: afterID, Limit: interestBatchSize})
+		if err != nil {
+			return date, accrued, paid, fmt.Errorf("failed to list interest accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			if !account.EarnsInterest() {
+				continue
+			}
+
+			// the balance earning interest is the one the day closed with, the listed one may be newer
+			if rate, ok := rates[account.Currency]; ok {
+				_, err := processor.service.AccrueInterest(ctx, models.AccrueInterestParams{Account: account, Date: date, Rate: rate})
+				switch {
+				case err == nil:
+					accrued++
+				case !errors.Is(err, service.ErrInterestAlreadyAccrued) && !errors.Is(err, service.ErrNoInterestDue):
+					return date, accrued, paid, fmt.Errorf("failed to accrue interest on account %d: %w", account.ID, err)
+				}
+			}
+
+			_, err := processor.service.PostAccruedInterest(ctx, account.ID, monthStart)
+			switch {
+			case err == nil:
+				paid++
+			case !errors.Is(err, service.ErrNoAccruedInterest):
+				return date, accrued, paid, fmt.Errorf("failed to pay interest into account %d: %w", account.ID, err)
+			}
+		}
+
+		if len(accounts) < interestBatchSize {
+			break
+		}
+	}
+
+	return date, accrued, paid, nil
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/service"
+	"go.uber.org/mock/gomock"
+)
+
+var interestAccounts = []models.Account{
+	{ID: 1, Balance: 3650000, Currency: helpers.USD, Type: models.AccountSavings},
+	{ID: 2, Balance: 3650000, Currency: helpers.USD, Type: models.AccountChecking},
+	{ID: 3, Balance: 3650000, Currency: helpers.CAD, Type: models.AccountSavings}, // no rate for CAD
+	{ID: 4, Currency: helpers.USD, Type: models.AccountSavings},
+}
+
+func TestAccrueInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mockedproviders.NewMockServiceProvider(ctrl)
+	processor := &RedisTaskProcessor{service: svc}
+
+	svc.EXPECT().ListInterestAccounts(gomock.Any(), models.ListInterestAccountsParams{Limit: interestBatchSize}).Times(1).Return(interestAccounts, nil)
+
+	date := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+	arg := models.AccrueInterestParams{Account: interestAccounts[0], Date: date, Rate: 2.5}
+	svc.EXPECT().AccrueInterest(gomock.Any(), arg).Times(1).Return(models.InterestAccrual{AccountID: 1, Amount: 250}, nil)
+	// the listed balance is of now, the day may have closed with money on it
+	arg = models.AccrueInterestParams{Account: interestAccounts[3], Date: date, Rate: 2.5}
+	svc.EXPECT().AccrueInterest(gomock.Any(), arg).Times(1).Return(models.InterestAccrual{}, service.ErrNoInterestDue)
+
+	// February is still unpaid on account 3, every run pays what closed months left
+	monthStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []int64{1, 4} {
+		svc.EXPECT().PostAccruedInterest(gomock.Any(), id, monthStart).Times(1).Return(models.Account{}, service.ErrNoAccruedInterest)
+	}
+	svc.EXPECT().PostAccruedInterest(gomock.Any(), int64(3), monthStart).Times(1).Return(models.Account{ID: 3}, nil)
+
+	got, accrued, paid, err := processor.accrueInterest(context.Background(), map[string]float64{helpers.USD: 2.5}, time.Date(2024, 3, 15, 0, 0, 5, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, date, got)
+	require.Equal(t, 1, accrued)
+	require.Equal(t, 1, paid)
+}
+
+func TestAccrueInterestRerunOnFirstOfMonth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mockedproviders.NewMockServiceProvider(ctrl)
+	processor := &RedisTaskProcessor{service: svc}
+
+	svc.EXPECT().ListInterestAccounts(gomock.Any(), gomock.Any()).Times(1).Return(interestAccounts, nil)
+
+	// the first run already accrued the day and paid the month
+	svc.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(2).Return(models.InterestAccrual{}, service.ErrInterestAlreadyAccrued)
+	monthStart := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []int64{1, 3, 4} {
+		svc.EXPECT().PostAccruedInterest(gomock.Any(), id, monthStart).Times(1).Return(models.Account{}, service.ErrNoAccruedInterest)
+	}
+
+	date, accrued, paid, err := processor.accrueInterest(context.Background(), map[string]float64{helpers.USD: 2.5}, time.Date(2024, 4, 1, 0, 0, 5, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), date)
+	require.Zero(t, accrued)
+	require.Zero(t, paid)
+}
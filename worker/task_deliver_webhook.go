@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+type PayloadDeliverWebhook struct {
+	WebhookID int64               `json:"webhook_id"`
+	Event     models.WebhookEvent `json:"event"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskDeliverWebhook, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Int64("webhook_id", payload.WebhookID).
+		Str("event", payload.Event.Type).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// WebhookDispatcher lets the service queue webhook deliveries without depending on the worker
+type WebhookDispatcher struct {
+	distributor TaskDistributor
+	maxRetry    int
+}
+
+func NewWebhookDispatcher(distributor TaskDistributor, maxRetry int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		distributor: distributor,
+		maxRetry:    maxRetry,
+	}
+}
+
+func (d *WebhookDispatcher) DispatchWebhook(ctx context.Context, webhookID int64, event models.WebhookEvent) error {
+	payload := &PayloadDeliverWebhook{
+		WebhookID: webhookID,
+		Event:     event,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(d.maxRetry),
+		asynq.Queue(QueueDefault),
+	}
+
+	return d.distributor.DistributeTaskDeliverWebhook(ctx, payload, opts...)
+}
+
+// webhookRetryDelay doubles the wait after every failed delivery, starting at 10 seconds and capped at 6 hours
+func webhookRetryDelay(n int) time.Duration {
+	const maxDelay = 6 * time.Hour
+	if n > 11 {
+		return maxDelay
+	}
+
+	return min(10*time.Second<<n, maxDelay)
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDeliverWebhook
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	hook, err := processor.service.GetWebhook(ctx, payload.WebhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Ctx(ctx).Info().Str("type", task.Type()).Int64("webhook_id", payload.WebhookID).Msg("webhook was deleted")
+			return nil
+		}
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if !hook.Active {
+		log.Ctx(ctx).Info().Str("type", task.Type()).Int64("webhook_id", hook.ID).Msg("webhook is disabled")
+		return nil
+	}
+
+	code, err := processor.webhooks.Send(ctx, hook, payload.Event)
+	if err != nil {
+		// only a delivery that used up its retries counts towards disabling the webhook
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			updated, rerr := processor.service.RecordWebhookDelivery(ctx, hook.ID, false)
+			if rerr != nil {
+				log.Ctx(ctx).Error().Err(rerr).Int64("webhook_id", hook.ID).Msg("failed to record webhook failure")
+			} else if !updated.Active {
+				log.Ctx(ctx).Warn().Int64("webhook_id", hook.ID).Int32("failures", updated.ConsecutiveFailures).Msg("disabled webhook")
+			}
+		}
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+
+	if hook.ConsecutiveFailures > 0 {
+		if _, err := processor.service.RecordWebhookDelivery(ctx, hook.ID, true); err != nil {
+			log.Ctx(ctx).Error().Err(err).Int64("webhook_id", hook.ID).Msg("failed to record webhook delivery")
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("webhook_id", hook.ID).
+		Str("event", payload.Event.Type).
+		Int("status_code", code).
+		Msg("processed task")
+
+	return nil
+}
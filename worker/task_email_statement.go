@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/requestid"
+	"github.com/zde37/Swift_Bank/statement"
+)
+
+const TaskEmailStatement = "task:email_statement"
+
+type PayloadEmailStatement struct {
+	Username  string `json:"username"`
+	AccountID int64  `json:"account_id"`
+	Year      int    `json:"year"`
+	Month     int    `json:"month"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskEmailStatement(
+	ctx context.Context,
+	payload *PayloadEmailStatement,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	// statements are not urgent, they go to the low queue unless the caller says otherwise
+	opts = append([]asynq.Option{asynq.Queue(QueueLow)}, opts...)
+	task := asynq.NewTask(TaskEmailStatement, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// ProcessTaskEmailStatement emails the monthly statement of an account to its owner as a CSV file
+func (processor *RedisTaskProcessor) ProcessTaskEmailStatement(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadEmailStatement
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	account, err := processor.repo.GetAccountIncludingDeleted(ctx, payload.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account.Owner != payload.Username {
+		return fmt.Errorf("account %d does not belong to %s: %w", account.ID, payload.Username, asynq.SkipRetry)
+	}
+
+	if _, ok := processor.notificationPreferences(ctx, payload.Username, models.NotificationStatements, "statement"); !ok {
+		return nil
+	}
+
+	user, err := processor.repo.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	st, err := processor.service.GetStatement(ctx, models.GetStatementParams{
+		Account: account,
+		Year:    payload.Year,
+		Month:   payload.Month,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get statement: %w", err)
+	}
+
+	var csv bytes.Buffer
+	if err := statement.WriteCSV(&csv, st); err != nil {
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+
+	period := time.Date(payload.Year, time.Month(payload.Month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006")
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateStatementReady, mail.StatementReadyData{
+		FullName:  user.FullName,
+		AccountID: account.ID,
+		Period:    period,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render statement email: %w", err)
+	}
+
+	attachment := mail.Attachment{
+		Name:        fmt.Sprintf("statement-%d-%04d-%02d.csv", account.ID, payload.Year, payload.Month),
+		ContentType: "text/csv",
+		Data:        csv.Bytes(),
+	}
+	err = processor.mailer.SendEmail(subject, content, text, []string{user.Email}, nil, nil, []mail.Attachment{attachment})
+	if err != nil {
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      models.NotificationKindStatement,
+			Channel:   notify.ChannelEmail,
+			Recipient: user.Email,
+			Subject:   subject,
+		}, fmt.Errorf("failed to send statement email: %w", err))
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("account_id", account.ID).
+		Int("year", payload.Year).
+		Int("month", payload.Month).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskEmailStatement(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	account := models.Account{ID: 7, Owner: user.UserName, Currency: helpers.USD, Balance: 150}
+	st := models.Statement{Account: account, Year: 2024, Month: 3, OpeningBalance: 100, ClosingBalance: 150}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Eq(user.UserName)).
+		Times(1).
+		Return(models.NotificationPreferences{Username: user.UserName, EmailStatements: true}, nil)
+	service.EXPECT().
+		GetStatement(gomock.Any(), gomock.Eq(models.GetStatementParams{Account: account, Year: 2024, Month: 3})).
+		Times(1).
+		Return(st, nil)
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Nil(), gomock.Nil(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(subject, content, text string, to, cc, bcc []string, attachments []mail.Attachment) error {
+			require.Equal(t, "Your Swift Bank statement for March 2024", subject)
+			require.Len(t, attachments, 1)
+			require.Equal(t, "statement-7-2024-03.csv", attachments[0].Name)
+			require.Equal(t, "text/csv", attachments[0].ContentType)
+			require.Contains(t, string(attachments[0].Data), "opening balance")
+			return nil
+		})
+
+	processor := &RedisTaskProcessor{
+		repo:    repo,
+		service: service,
+		mailer:  mailer,
+	}
+
+	payload, err := json.Marshal(PayloadEmailStatement{Username: user.UserName, AccountID: account.ID, Year: 2024, Month: 3})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskEmailStatement(context.Background(), asynq.NewTask(TaskEmailStatement, payload))
+	require.NoError(t, err)
+}
+
+func TestProcessTaskEmailStatementTooLarge(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	account := models.Account{ID: 7, Owner: user.UserName, Currency: helpers.USD}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	repo.EXPECT().
+		CreateFailedNotification(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.FailedNotification) (models.FailedNotification, error) {
+			require.Equal(t, models.NotificationKindStatement, arg.Kind)
+			return arg, nil
+		})
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.NotificationPreferences{EmailStatements: true}, nil)
+	service.EXPECT().
+		GetStatement(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(models.Statement{Account: account, Year: 2024, Month: 3}, nil)
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(fmt.Errorf("%w: 20 bytes attached, the limit is 10", mail.ErrAttachmentTooLarge))
+
+	processor := &RedisTaskProcessor{
+		repo:    repo,
+		service: service,
+		mailer:  mailer,
+	}
+
+	payload, err := json.Marshal(PayloadEmailStatement{Username: user.UserName, AccountID: account.ID, Year: 2024, Month: 3})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskEmailStatement(context.Background(), asynq.NewTask(TaskEmailStatement, payload))
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+	require.ErrorContains(t, err, "the limit is 10")
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/export"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskEmailTransactionExport = "task:email_transaction_export"
+
+type PayloadEmailTransactionExport struct {
+	Username  string    `json:"username"`
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskEmailTransactionExport(
+	ctx context.Context,
+	payload *PayloadEmailTransactionExport,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	// like statements, exports go to the low queue unless the caller says otherwise
+	opts = append([]asynq.Option{asynq.Queue(QueueLow)}, opts...)
+	task := asynq.NewTask(TaskEmailTransactionExport, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// ProcessTaskEmailTransactionExport emails an export that was too large to download right away to
+// the owner of the account. It was asked for, so notification preferences don't apply.
+func (processor *RedisTaskProcessor) ProcessTaskEmailTransactionExport(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadEmailTransactionExport
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	account, err := processor.repo.GetAccountIncludingDeleted(ctx, payload.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account.Owner != payload.Username {
+		return fmt.Errorf("account %d does not belong to %s: %w", account.ID, payload.Username, asynq.SkipRetry)
+	}
+
+	user, err := processor.repo.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var csv bytes.Buffer
+	cw, err := export.NewCSVWriter(&csv)
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	err = processor.service.ExportTransactions(ctx, models.ExportTransactionsParams{
+		Account: account,
+		From:    payload.From,
+		To:      payload.To,
+	}, cw.Write)
+	if err != nil {
+		return fmt.Errorf("failed to export transactions: %w", err)
+	}
+
+	if err := cw.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	from, to := payload.From.UTC().Format(time.DateOnly), payload.To.UTC().Format(time.DateOnly)
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateExportReady, mail.ExportReadyData{
+		FullName:  user.FullName,
+		AccountID: account.ID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render export email: %w", err)
+	}
+
+	attachment := mail.Attachment{
+		Name:        fmt.Sprintf("transactions-%d-%s-%s.csv", account.ID, from, to),
+		ContentType: "text/csv",
+		Data:        csv.Bytes(),
+	}
+	err = processor.mailer.SendEmail(subject, content, text, []string{user.Email}, nil, nil, []mail.Attachment{attachment})
+	if err != nil {
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      models.NotificationKindTransactionExport,
+			Channel:   notify.ChannelEmail,
+			Recipient: user.Email,
+			Subject:   subject,
+		}, fmt.Errorf("failed to send export email: %w", err))
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("account_id", account.ID).
+		Str("from", from).
+		Str("to", to).
+		Msg("processed task")
+
+	return nil
+}
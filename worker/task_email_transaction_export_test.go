@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskEmailTransactionExport(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	account := models.Account{ID: 7, Owner: user.UserName, Currency: helpers.USD, Balance: 150}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		ExportTransactions(gomock.Any(), gomock.Eq(models.ExportTransactionsParams{Account: account, From: from, To: to}), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.ExportTransactionsParams, fn func(models.LedgerRow) error) error {
+			return fn(models.LedgerRow{Date: from, Type: models.LedgerRowTransferIn, Counterparty: 9, Amount: 5000, Currency: helpers.USD, Balance: 15000, Memo: "rent, march"})
+		})
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Nil(), gomock.Nil(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(subject, content, text string, to, cc, bcc []string, attachments []mail.Attachment) error {
+			require.Equal(t, "Your Swift Bank transaction export is ready", subject)
+			require.Contains(t, text, "from 2024-01-01 to 2024-04-01")
+			require.Len(t, attachments, 1)
+			require.Equal(t, "transactions-7-2024-01-01-2024-04-01.csv", attachments[0].Name)
+			require.Equal(t, "text/csv", attachments[0].ContentType)
+			require.Contains(t, string(attachments[0].Data), `transfer_in,9,50.00,USD,150.00,"rent, march"`)
+			return nil
+		})
+
+	processor := &RedisTaskProcessor{
+		repo:    repo,
+		service: service,
+		mailer:  mailer,
+	}
+
+	payload, err := json.Marshal(PayloadEmailTransactionExport{Username: user.UserName, AccountID: account.ID, From: from, To: to})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskEmailTransactionExport(context.Background(), asynq.NewTask(TaskEmailTransactionExport, payload))
+	require.NoError(t, err)
+}
+
+func TestProcessTaskEmailTransactionExportOtherOwner(t *testing.T) {
+	account := models.Account{ID: 7, Owner: "bob", Currency: helpers.USD}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().ExportTransactions(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	processor := &RedisTaskProcessor{
+		repo:    repo,
+		service: service,
+	}
+
+	payload, err := json.Marshal(PayloadEmailTransactionExport{Username: "ada", AccountID: account.ID, From: time.Now().Add(-time.Hour), To: time.Now()})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskEmailTransactionExport(context.Background(), asynq.NewTask(TaskEmailTransactionExport, payload))
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+}
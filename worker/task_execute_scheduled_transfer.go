@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/requestid"
+	"github.com/zde37/Swift_Bank/service"
+)
+
+const TaskExecuteScheduledTransfer = "task:execute_scheduled_transfer"
+
+type PayloadExecuteScheduledTransfer struct {
+	ID int64 `json:"id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskExecuteScheduledTransfer(
+	ctx context.Context,
+	payload *PayloadExecuteScheduledTransfer,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskExecuteScheduledTransfer, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadExecuteScheduledTransfer
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	transfer, err := processor.service.ExecuteScheduledTransfer(ctx, payload.ID)
+	if err != nil {
+		// canceled before it was due, or already finished by an earlier attempt
+		if errors.Is(err, service.ErrScheduledTransferDone) {
+			log.Ctx(ctx).Info().Str("type", task.Type()).Int64("id", payload.ID).Msg("scheduled transfer is no longer pending")
+			return nil
+		}
+		return fmt.Errorf("failed to execute scheduled transfer: %w", err)
+	}
+
+	if transfer.Status == models.ScheduledTransferFailed {
+		if err := processor.notifyScheduledTransferFailed(ctx, transfer); err != nil {
+			// the transfer itself is settled, retrying the task would only notify again
+			log.Ctx(ctx).Error().Err(err).Int64("id", transfer.ID).Msg("failed to notify about scheduled transfer failure")
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("id", transfer.ID).
+		Str("status", transfer.Status).
+		Msg("processed task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) notifyScheduledTransferFailed(ctx context.Context, transfer models.ScheduledTransfer) error {
+	amount := money.New(transfer.Amount, transfer.Currency)
+	text := fmt.Sprintf("Your scheduled transfer of %s %s from account %d to account %d could not be made: %s",
+		amount, transfer.Currency, transfer.FromAccountID, transfer.ToAccountID, transfer.FailureReason)
+	err := processor.recordNotification(ctx, models.Notification{
+		Username: transfer.CreatedBy,
+		Kind:     models.NotificationKindScheduledTransferFailed,
+		Title:    "Your scheduled transfer failed",
+		Body:     text,
+	})
+	if err != nil {
+		return err
+	}
+
+	preferences, ok := processor.notificationPreferences(ctx, transfer.CreatedBy, models.NotificationTransfers, "scheduled_transfer_failed")
+	if !ok {
+		return nil
+	}
+
+	user, err := processor.repo.GetUser(ctx, transfer.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject := "Your scheduled transfer failed"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	Your scheduled transfer of %s %s from account %d to account %d could not be made.<br/>
+	Reason: %s<br/>`, user.FullName, amount, transfer.Currency, transfer.FromAccountID, transfer.ToAccountID, transfer.FailureReason)
+
+	return processor.sendNotification(ctx, models.NotificationKindScheduledTransferFailed, user, preferences, subject, content, text)
+}
@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/statement"
+)
+
+const TaskGenerateStatementPDF = "task:generate_statement_pdf"
+
+type PayloadGenerateStatementPDF struct {
+	StatementID int64 `json:"statement_id"`
+}
+
+// StatementObjectKey is where the PDF of a statement is stored, a new rendering replaces the old one
+func StatementObjectKey(document models.StatementDocument) string {
+	return fmt.Sprintf("statements/%d/%04d-%02d.pdf", document.AccountID, document.Year, document.Month)
+}
+
+// ProcessTaskGenerateStatementPDF renders a requested statement as a PDF, stores it and emails its
+// owner a download link. Once the last attempt to render it fails the statement is marked failed.
+func (processor *RedisTaskProcessor) ProcessTaskGenerateStatementPDF(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadGenerateStatementPDF
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	document, err := processor.repo.GetStatementDocument(ctx, payload.StatementID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("statement %d does not exist: %w", payload.StatementID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get statement: %w", err)
+	}
+
+	if document.Status == models.StatementDocumentFailed {
+		log.Ctx(ctx).Info().Str("type", task.Type()).Int64("statement_id", document.ID).Msg("statement has failed, it has to be requested again")
+		return nil
+	}
+
+	// the statement of a month is still owed when the account was deleted since
+	account, err := processor.repo.GetAccountIncludingDeleted(ctx, document.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	user, err := processor.repo.GetUser(ctx, account.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// a ready statement was rendered by an earlier attempt whose email failed
+	if document.Status == models.StatementDocumentPending {
+		document, err = processor.renderStatement(ctx, task, document, account, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := processor.emailStatementLink(ctx, document, user); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("statement_id", document.ID).
+		Int64("account_id", account.ID).
+		Msg("processed task")
+
+	return nil
+}
+
+// renderStatement stores the PDF of a pending statement and marks it ready
+func (processor *RedisTaskProcessor) renderStatement(ctx context.Context, task *asynq.Task, document models.StatementDocument, account models.Account, user models.User) (models.StatementDocument, error) {
+	key := StatementObjectKey(document)
+	if err := processor.writeStatementPDF(ctx, key, document, account, user); err != nil {
+		if !processor.isLastAttempt(ctx, task.Type()) {
+			return document, err
+		}
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		_, finishErr := processor.repo.FinishStatementDocument(ctx, models.FinishStatementDocumentParams{
+			ID:            document.ID,
+			Status:        models.StatementDocumentFailed,
+			FailureReason: fmt.Sprintf("the PDF could not be generated after %d attempts", retried+1),
+		})
+		if finishErr != nil {
+			log.Ctx(ctx).Error().Err(finishErr).Int64("statement_id", document.ID).Msg("failed to mark statement failed")
+		}
+		return document, fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	ready, err := processor.repo.FinishStatementDocument(ctx, models.FinishStatementDocumentParams{
+		ID:        document.ID,
+		Status:    models.StatementDocumentReady,
+		ObjectKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return document, fmt.Errorf("statement %d is not pending anymore: %w", document.ID, asynq.SkipRetry)
+		}
+		return document, fmt.Errorf("failed to finish statement: %w", err)
+	}
+
+	return ready, nil
+}
+
+func (processor *RedisTaskProcessor) writeStatementPDF(ctx context.Context, key string, document models.StatementDocument, account models.Account, user models.User) error {
+	st, err := processor.service.GetStatement(ctx, models.GetStatementParams{
+		Account: account,
+		Year:    document.Year,
+		Month:   document.Month,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get statement: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := statement.WritePDF(&buf, st, user.FullName); err != nil {
+		return fmt.Errorf("failed to render statement: %w", err)
+	}
+
+	if err := processor.files.Put(ctx, key, "application/pdf", buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to store statement: %w", err)
+	}
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) emailStatementLink(ctx context.Context, document models.StatementDocument, user models.User) error {
+	if _, ok := processor.notificationPreferences(ctx, user.UserName, models.NotificationStatements, "statement"); !ok {
+		return nil
+	}
+
+	url, expiresAt, err := processor.files.SignedURL(ctx, document.ObjectKey, processor.statementURLTTL)
+	if err != nil {
+		return fmt.Errorf("failed to sign statement url: %w", err)
+	}
+
+	period := time.Date(document.Year, time.Month(document.Month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006")
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateStatementPDF, mail.StatementPDFData{
+		FullName:    user.FullName,
+		AccountID:   document.AccountID,
+		Period:      period,
+		DownloadURL: url,
+		ExpiresAt:   expiresAt.UTC().Format(time.RFC1123),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render statement email: %w", err)
+	}
+
+	err = processor.mailer.SendEmail(subject, content, text, []string{user.Email}, nil, nil, nil)
+	if err != nil {
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      models.NotificationKindStatement,
+			Channel:   notify.ChannelEmail,
+			Recipient: user.Email,
+			Subject:   subject,
+		}, fmt.Errorf("failed to send statement email: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/storage"
+	"go.uber.org/mock/gomock"
+)
+
+type statementPDFFixture struct {
+	user     models.User
+	account  models.Account
+	document models.StatementDocument
+	dir      string
+	repo     *mockedproviders.MockRepositoryProvider
+	service  *mockedproviders.MockServiceProvider
+	mailer   *mockedproviders.MockEmailSender
+}
+
+func newStatementPDFFixture(t *testing.T, status string) *statementPDFFixture {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	account := models.Account{ID: 7, Owner: user.UserName, Currency: helpers.USD, Balance: 150}
+	document := models.StatementDocument{ID: 3, AccountID: account.ID, Year: 2024, Month: 3, Status: status, RequestedBy: user.UserName}
+	if status == models.StatementDocumentReady {
+		document.ObjectKey = StatementObjectKey(document)
+	}
+
+	ctrl := gomock.NewController(t)
+	f := &statementPDFFixture{
+		user:     user,
+		account:  account,
+		document: document,
+		dir:      t.TempDir(),
+		repo:     mockedproviders.NewMockRepositoryProvider(ctrl),
+		service:  mockedproviders.NewMockServiceProvider(ctrl),
+		mailer:   mockedproviders.NewMockEmailSender(ctrl),
+	}
+
+	f.repo.EXPECT().GetStatementDocument(gomock.Any(), gomock.Eq(document.ID)).Times(1).Return(document, nil)
+	if status != models.StatementDocumentFailed {
+		f.repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		f.repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	}
+
+	return f
+}
+
+func (f *statementPDFFixture) process(t *testing.T, maxRetry int) error {
+	files, err := storage.NewFileStore(f.dir, "https://bank.example/v1/files", "a secret of at least 16 characters")
+	require.NoError(t, err)
+
+	processor := &RedisTaskProcessor{
+		repo:            f.repo,
+		service:         f.service,
+		mailer:          f.mailer,
+		files:           files,
+		maxRetry:        map[string]int{TaskGenerateStatementPDF: maxRetry},
+		statementURLTTL: 24 * time.Hour,
+	}
+
+	payload, err := json.Marshal(PayloadGenerateStatementPDF{StatementID: f.document.ID})
+	require.NoError(t, err)
+
+	return processor.ProcessTaskGenerateStatementPDF(context.Background(), asynq.NewTask(TaskGenerateStatementPDF, payload))
+}
+
+func (f *statementPDFFixture) expectStatement(err error) {
+	st := models.Statement{Account: f.account, Year: 2024, Month: 3, OpeningBalance: 100, ClosingBalance: 150}
+	f.service.EXPECT().
+		GetStatement(gomock.Any(), gomock.Eq(models.GetStatementParams{Account: f.account, Year: 2024, Month: 3})).
+		Times(1).
+		Return(st, err)
+}
+
+func (f *statementPDFFixture) expectEmail(t *testing.T) {
+	f.service.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Eq(f.user.UserName)).
+		Times(1).
+		Return(models.NotificationPreferences{Username: f.user.UserName, EmailStatements: true}, nil)
+	f.mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{f.user.Email}), gomock.Nil(), gomock.Nil(), gomock.Nil()).
+		Times(1).
+		DoAndReturn(func(subject, content, text string, to, cc, bcc []string, attachments any) error {
+			require.Equal(t, "Your Swift Bank statement for March 2024 is ready", subject)
+			require.Contains(t, text, "https://bank.example/v1/files/statements/7/2024-03.pdf?expires=")
+			return nil
+		})
+}
+
+func TestProcessTaskGenerateStatementPDF(t *testing.T) {
+	f := newStatementPDFFixture(t, models.StatementDocumentPending)
+	f.expectStatement(nil)
+	f.repo.EXPECT().
+		FinishStatementDocument(gomock.Any(), gomock.Eq(models.FinishStatementDocumentParams{
+			ID:        f.document.ID,
+			Status:    models.StatementDocumentReady,
+			ObjectKey: "statements/7/2024-03.pdf",
+		})).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg models.FinishStatementDocumentParams) (models.StatementDocument, error) {
+			ready := f.document
+			ready.Status, ready.ObjectKey = arg.Status, arg.ObjectKey
+			return ready, nil
+		})
+	f.expectEmail(t)
+
+	require.NoError(t, f.process(t, 3))
+
+	data, err := os.ReadFile(filepath.Join(f.dir, "statements", "7", "2024-03.pdf"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "%PDF-"))
+	require.Contains(t, string(data), "(Ada Lovelace)")
+}
+
+func TestProcessTaskGenerateStatementPDFRetries(t *testing.T) {
+	f := newStatementPDFFixture(t, models.StatementDocumentPending)
+	f.expectStatement(errors.New("connection reset"))
+	f.repo.EXPECT().FinishStatementDocument(gomock.Any(), gomock.Any()).Times(0)
+	f.mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	err := f.process(t, 3)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, asynq.SkipRetry))
+}
+
+func TestProcessTaskGenerateStatementPDFGivesUp(t *testing.T) {
+	f := newStatementPDFFixture(t, models.StatementDocumentPending)
+	f.expectStatement(errors.New("connection reset"))
+	f.repo.EXPECT().
+		FinishStatementDocument(gomock.Any(), gomock.Eq(models.FinishStatementDocumentParams{
+			ID:            f.document.ID,
+			Status:        models.StatementDocumentFailed,
+			FailureReason: "the PDF could not be generated after 1 attempts",
+		})).
+		Times(1).
+		Return(models.StatementDocument{}, nil)
+	f.mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// no retries left, so the failure is recorded on the statement
+	err := f.process(t, 0)
+	require.ErrorIs(t, err, asynq.SkipRetry)
+}
+
+func TestProcessTaskGenerateStatementPDFReady(t *testing.T) {
+	f := newStatementPDFFixture(t, models.StatementDocumentReady)
+	require.NoError(t, os.MkdirAll(filepath.Join(f.dir, "statements", "7"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(f.dir, "statements", "7", "2024-03.pdf"), []byte("%PDF-1.4"), 0o600))
+
+	// rendered by an earlier attempt, only the email is sent again
+	f.service.EXPECT().GetStatement(gomock.Any(), gomock.Any()).Times(0)
+	f.repo.EXPECT().FinishStatementDocument(gomock.Any(), gomock.Any()).Times(0)
+	f.expectEmail(t)
+
+	require.NoError(t, f.process(t, 3))
+}
+
+func TestProcessTaskGenerateStatementPDFFailed(t *testing.T) {
+	f := newStatementPDFFixture(t, models.StatementDocumentFailed)
+	f.service.EXPECT().GetStatement(gomock.Any(), gomock.Any()).Times(0)
+
+	require.NoError(t, f.process(t, 3))
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskNotifyIncomingTransfer = "task:notify_incoming_transfer"
+
+type PayloadNotifyIncomingTransfer struct {
+	TransactionID int64 `json:"transaction_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskNotifyIncomingTransfer(
+	ctx context.Context,
+	payload *PayloadNotifyIncomingTransfer,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskNotifyIncomingTransfer, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskNotifyIncomingTransfer(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadNotifyIncomingTransfer
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	transfer, err := processor.repo.GetTransaction(ctx, payload.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	// either account may have been deleted after the transfer was made
+	fromAccount, err := processor.repo.GetAccountIncludingDeleted(ctx, transfer.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get sending account: %w", err)
+	}
+
+	toAccount, err := processor.repo.GetAccountIncludingDeleted(ctx, transfer.ToAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get receiving account: %w", err)
+	}
+
+	// moving money between one's own accounts is not worth a notification
+	if fromAccount.Owner == toAccount.Owner {
+		return nil
+	}
+
+	text := fmt.Sprintf("Account %d received %s %s from account %d.",
+		toAccount.ID, money.New(transfer.ToAmount, transfer.ToCurrency), transfer.ToCurrency, fromAccount.ID)
+	err = processor.recordNotification(ctx, models.Notification{
+		Username: toAccount.Owner,
+		Kind:     models.NotificationKindIncomingTransfer,
+		Title:    "You received money",
+		Body:     text,
+	})
+	if err != nil {
+		return err
+	}
+
+	if preferences, ok := processor.notificationPreferences(ctx, toAccount.Owner, models.NotificationTransfers, "incoming_transfer"); ok {
+		if err := processor.sendIncomingTransferNotification(ctx, transfer, toAccount, preferences, text); err != nil {
+			// the inbox already has it, retrying the task would add it twice
+			log.Ctx(ctx).Error().Err(err).Int64("transaction_id", transfer.ID).Msg("failed to send incoming transfer notification")
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("transaction_id", transfer.ID).
+		Msg("processed task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) sendIncomingTransferNotification(ctx context.Context, transfer models.Transaction, toAccount models.Account, preferences models.NotificationPreferences, text string) error {
+	user, err := processor.repo.GetUser(ctx, toAccount.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject := "You received money"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	Your account %d received %s %s from account %d.<br/>`,
+		user.FullName, toAccount.ID, money.New(transfer.ToAmount, transfer.ToCurrency), transfer.ToCurrency, transfer.FromAccountID)
+
+	return processor.sendNotification(ctx, models.NotificationKindIncomingTransfer, user, preferences, subject, content, text)
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskNotifyNewLogin = "task:notify_new_login"
+
+type PayloadNotifyNewLogin struct {
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskNotifyNewLogin(
+	ctx context.Context,
+	payload *PayloadNotifyNewLogin,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskNotifyNewLogin, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// ProcessTaskNotifyNewLogin warns the user about a login from a user agent they never logged in with before
+func (processor *RedisTaskProcessor) ProcessTaskNotifyNewLogin(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadNotifyNewLogin
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	session, err := processor.repo.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	known, err := processor.repo.HasOtherSessionFromDevice(ctx, session.UserName, session.UserAgent, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check device: %w", err)
+	}
+
+	if known {
+		return nil
+	}
+
+	text := fmt.Sprintf("Your account was logged into from a new device (%s, %s).", session.UserAgent, session.ClientIp)
+	err = processor.recordNotification(ctx, models.Notification{
+		Username: session.UserName,
+		Kind:     models.NotificationKindNewLogin,
+		Title:    "New login to your account",
+		Body:     text,
+	})
+	if err != nil {
+		return err
+	}
+
+	if preferences, ok := processor.notificationPreferences(ctx, session.UserName, models.NotificationLogins, "new_login"); ok {
+		if err := processor.sendNewLoginNotification(ctx, session, preferences, text); err != nil {
+			// the inbox already has it, retrying the task would add it twice
+			log.Ctx(ctx).Error().Err(err).Str("username", session.UserName).Msg("failed to send new login notification")
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Str("username", session.UserName).
+		Msg("processed task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) sendNewLoginNotification(ctx context.Context, session models.Session, preferences models.NotificationPreferences, text string) error {
+	user, err := processor.repo.GetUser(ctx, session.UserName)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject := "New login to your Swift Bank account"
+	content := fmt.Sprintf(`Hello %s,<br/>
+	Your account was logged into from a new device.<br/>
+	Device: %s<br/>
+	IP address: %s<br/>
+	If this wasn't you, change your password right away.<br/>`, user.FullName, session.UserAgent, session.ClientIp)
+
+	return processor.sendNotification(ctx, models.NotificationKindNewLogin, user, preferences, subject, content, text)
+}
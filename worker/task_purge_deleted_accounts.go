@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskPurgeDeletedAccounts = "task:purge_deleted_accounts"
+
+type PayloadPurgeDeletedAccounts struct {
+	RetentionYears int `json:"retention_years"`
+}
+
+// ProcessTaskPurgeDeletedAccounts hard deletes the accounts soft deleted more than the retention
+// ago, the ones still referred to by their history are kept
+func (processor *RedisTaskProcessor) ProcessTaskPurgeDeletedAccounts(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadPurgeDeletedAccounts
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+	if payload.RetentionYears < 1 {
+		return fmt.Errorf("retention of %d years is too short: %w", payload.RetentionYears, asynq.SkipRetry)
+	}
+
+	purged, err := processor.repo.PurgeDeletedAccounts(ctx, time.Now().AddDate(-payload.RetentionYears, 0, 0))
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted accounts: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).Int64("purged", purged).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskPurgeDeletedAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	processor := &RedisTaskProcessor{repo: repo}
+
+	var deletedBefore time.Time
+	repo.EXPECT().PurgeDeletedAccounts(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+			deletedBefore = before
+			return 3, nil
+		})
+
+	payload, err := json.Marshal(PayloadPurgeDeletedAccounts{RetentionYears: 7})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskPurgeDeletedAccounts(context.Background(), asynq.NewTask(TaskPurgeDeletedAccounts, payload))
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().AddDate(-7, 0, 0), deletedBefore, time.Minute)
+}
+
+func TestProcessTaskPurgeDeletedAccountsNoRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	processor := &RedisTaskProcessor{repo: repo}
+
+	// a task without a retention would purge every deleted account
+	repo.EXPECT().PurgeDeletedAccounts(gomock.Any(), gomock.Any()).Times(0)
+
+	err := processor.ProcessTaskPurgeDeletedAccounts(context.Background(), asynq.NewTask(TaskPurgeDeletedAccounts, []byte(`{}`)))
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+}
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskPurgeIdempotencyKeys = "task:purge_idempotency_keys"
+
+func (processor *RedisTaskProcessor) ProcessTaskPurgeIdempotencyKeys(ctx context.Context, task *asynq.Task) error {
+	deleted, err := processor.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
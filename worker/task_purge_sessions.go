@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskPurgeSessions = "task:purge_sessions"
+
+// sessionRetention is how long a session is kept after it expires, new login
+// alerts look at past sessions to recognise devices a user has signed in from
+const sessionRetention = 30 * 24 * time.Hour
+
+func (processor *RedisTaskProcessor) ProcessTaskPurgeSessions(ctx context.Context, task *asynq.Task) error {
+	deleted, err := processor.repo.DeleteExpiredSessions(ctx, time.Now().Add(-sessionRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskPurgeVerifyEmails = "task:purge_verify_emails"
+
+func (processor *RedisTaskProcessor) ProcessTaskPurgeVerifyEmails(ctx context.Context, task *asynq.Task) error {
+	deleted, err := processor.repo.DeleteStaleVerifyEmails(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete stale verify emails: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
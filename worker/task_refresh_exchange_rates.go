@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/rates"
+)
+
+const TaskRefreshExchangeRates = "task:refresh_exchange_rates"
+
+// ProcessTaskRefreshExchangeRates saves the rate of every pair of enabled currencies to the
+// exchange_rates table. Requests only read the table, so while the provider is down they get the
+// last saved rates until those are too old. A pair the provider has no rate for is skipped, the
+// task fails when a rate couldn't be fetched or saved.
+func (processor *RedisTaskProcessor) ProcessTaskRefreshExchangeRates(ctx context.Context, task *asynq.Task) error {
+	if processor.rates == nil {
+		return fmt.Errorf("no exchange rate provider configured: %w", asynq.SkipRetry)
+	}
+
+	currencies, err := processor.repo.ListCurrencies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list currencies: %w", err)
+	}
+
+	var enabled []string
+	for _, currency := range currencies {
+		if currency.Enabled {
+			enabled = append(enabled, currency.Code)
+		}
+	}
+
+	var refreshed, unavailable int
+	var errs []error
+	for _, base := range enabled {
+		for _, quote := range enabled {
+			if base == quote {
+				continue
+			}
+
+			rate, err := processor.rates.GetRate(ctx, base, quote)
+			if errors.Is(err, rates.ErrRateUnavailable) {
+				unavailable++
+				log.Ctx(ctx).Warn().Str("base", base).Str("quote", quote).Msg("provider has no exchange rate for pair")
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get %s/%s rate: %w", base, quote, err))
+				continue
+			}
+
+			_, err = processor.repo.UpsertExchangeRate(ctx, models.ExchangeRate{
+				Base:      rate.Base,
+				Quote:     rate.Quote,
+				Rate:      rate.Value,
+				FetchedAt: rate.FetchedAt,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to save %s/%s rate: %w", base, quote, err))
+				continue
+			}
+			refreshed++
+		}
+	}
+
+	log.Ctx(ctx).Info().Str("type", task.Type()).Int("refreshed", refreshed).Int("unavailable", unavailable).Int("failed", len(errs)).Msg("processed task")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/rates"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskRefreshExchangeRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	fetchedAt := time.Now().Add(-time.Minute)
+	processor := &RedisTaskProcessor{
+		repo: repo,
+		// no rate between EUR and CAD
+		rates: rates.NewStaticProvider(rates.Rate{Base: "USD", Quote: "EUR", Value: 0.8, FetchedAt: fetchedAt},
+			rates.Rate{Base: "USD", Quote: "CAD", Value: 1.25, FetchedAt: fetchedAt}),
+	}
+
+	repo.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]models.Currency{
+		{Code: "CAD", Enabled: true},
+		{Code: "EUR", Enabled: true},
+		{Code: "NGN"},
+		{Code: "USD", Enabled: true},
+	}, nil)
+
+	saved := map[string]models.ExchangeRate{}
+	repo.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(4).
+		DoAndReturn(func(_ context.Context, rate models.ExchangeRate) (models.ExchangeRate, error) {
+			saved[rate.Base+"/"+rate.Quote] = rate
+			return rate, nil
+		})
+
+	err := processor.ProcessTaskRefreshExchangeRates(context.Background(), asynq.NewTask(TaskRefreshExchangeRates, nil))
+	require.NoError(t, err)
+	require.Len(t, saved, 4)
+	require.Equal(t, 0.8, saved["USD/EUR"].Rate)
+	require.Equal(t, 1/1.25, saved["CAD/USD"].Rate)
+	require.Equal(t, fetchedAt, saved["USD/EUR"].FetchedAt)
+}
+
+type failingProvider struct{}
+
+func (failingProvider) GetRate(ctx context.Context, base, quote string) (rates.Rate, error) {
+	return rates.Rate{}, rates.ErrCircuitOpen
+}
+
+func TestProcessTaskRefreshExchangeRatesProviderDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	processor := &RedisTaskProcessor{repo: repo, rates: failingProvider{}}
+
+	repo.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]models.Currency{
+		{Code: "EUR", Enabled: true},
+		{Code: "USD", Enabled: true},
+	}, nil)
+	// the last saved rates stay, they turn stale rather than disappear
+	repo.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+
+	err := processor.ProcessTaskRefreshExchangeRates(context.Background(), asynq.NewTask(TaskRefreshExchangeRates, nil))
+	require.ErrorIs(t, err, rates.ErrCircuitOpen)
+}
+
+func TestProcessTaskRefreshExchangeRatesNoProvider(t *testing.T) {
+	processor := &RedisTaskProcessor{}
+
+	err := processor.ProcessTaskRefreshExchangeRates(context.Background(), asynq.NewTask(TaskRefreshExchangeRates, nil))
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/service"
+)
+
+const TaskRunDueStandingOrders = "task:run_due_standing_orders"
+
+// standingOrderBatchSize is how many due orders are loaded at a time
+const standingOrderBatchSize = 100
+
+func (processor *RedisTaskProcessor) ProcessTaskRunDueStandingOrders(ctx context.Context, task *asynq.Task) error {
+	var executed, failed int
+
+	for {
+		orders, err := processor.service.ListDueStandingOrders(ctx, time.Now(), standingOrderBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list due standing orders: %w", err)
+		}
+
+		for _, order := range orders {
+			updated, err := processor.service.ExecuteStandingOrder(ctx, order)
+			if err != nil {
+				if errors.Is(err, service.ErrStandingOrderRunRecorded) {
+					continue
+				}
+				// the run is retried with the whole task, its idempotency key keeps it from paying twice
+				return fmt.Errorf("failed to execute standing order %d: %w", order.ID, err)
+			}
+
+			if updated.ConsecutiveFailures == 0 {
+				executed++
+				continue
+			}
+
+			failed++
+			if updated.Status == models.StandingOrderSuspended {
+				if err := processor.notifyStandingOrderSuspended(ctx, updated); err != nil {
+					log.Ctx(ctx).Error().Err(err).Int64("id", updated.ID).Msg("failed to notify about suspended standing order")
+				}
+			}
+		}
+
+		if len(orders) < standingOrderBatchSize {
+			break
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int("executed", executed).
+		Int("failed", failed).
+		Msg("processed task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) notifyStandingOrderSuspended(ctx context.Context, order models.StandingOrder) error {
+	user, err := processor.repo.GetUser(ctx, order.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateStandingOrderSuspended, mail.StandingOrderSuspendedData{
+		FullName:      user.FullName,
+		Frequency:     order.Frequency,
+		Amount:        money.New(order.Amount, order.Currency).String(),
+		Currency:      order.Currency,
+		FromAccountID: order.FromAccountID,
+		ToAccountID:   order.ToAccountID,
+		Failures:      order.ConsecutiveFailures,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render standing order notification: %w", err)
+	}
+
+	err = processor.recordNotification(ctx, models.Notification{
+		Username: order.Owner,
+		Kind:     models.NotificationKindStandingOrderSuspended,
+		Title:    subject,
+		Body:     text,
+	})
+	if err != nil {
+		return err
+	}
+
+	preferences, ok := processor.notificationPreferences(ctx, order.Owner, models.NotificationTransfers, "standing_order_suspended")
+	if !ok {
+		return nil
+	}
+
+	return processor.sendNotification(ctx, models.NotificationKindStandingOrderSuspended, user, preferences, subject, content, text)
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotifyStandingOrderSuspended(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "<b>Ada</b>", Email: helpers.RandomEmail()}
+	order := models.StandingOrder{
+		ID:                  7,
+		Owner:               user.UserName,
+		FromAccountID:       1,
+		ToAccountID:         2,
+		Amount:              12000,
+		Currency:            helpers.USD,
+		Frequency:           "monthly",
+		Status:              models.StandingOrderSuspended,
+		ConsecutiveFailures: 3,
+	}
+	text := "Your monthly standing order of 120.00 USD from account 1 to account 2 failed 3 times in a row and has been suspended."
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	repo.EXPECT().CreateNotification(gomock.Any(), models.Notification{
+		Username: user.UserName,
+		Kind:     models.NotificationKindStandingOrderSuspended,
+		Title:    "Your standing order was suspended",
+		Body:     text,
+	}).Times(1).Return(models.Notification{}, nil)
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().GetNotificationPreferences(gomock.Any(), gomock.Eq(user.UserName)).Times(1).
+		Return(models.NotificationPreferences{Username: user.UserName, EmailTransfers: true}, nil)
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email}), gomock.Nil(), gomock.Nil(), gomock.Nil()).
+		Times(1).
+		DoAndReturn(func(subject, content, plain string, to, cc, bcc []string, attachments []mail.Attachment) error {
+			require.Equal(t, "Your standing order was suspended", subject)
+			require.Contains(t, content, "Hello &lt;b&gt;Ada&lt;/b&gt;")
+			require.Equal(t, text, plain)
+			return nil
+		})
+
+	processor := &RedisTaskProcessor{
+		repo:     repo,
+		service:  service,
+		mailer:   mailer,
+		notifier: notify.New(notify.NewEmailNotifier(mailer)),
+	}
+
+	require.NoError(t, processor.notifyStandingOrderSuspended(context.Background(), order))
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/mail"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/money"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskSendTransferAlertEmail = "task:send_transfer_alert_email"
+
+type PayloadSendTransferAlertEmail struct {
+	TransactionID int64 `json:"transaction_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendTransferAlertEmail(
+	ctx context.Context,
+	payload *PayloadSendTransferAlertEmail,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendTransferAlertEmail, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+// TransferAlertDispatcher lets the service queue large transfer alerts without depending on the worker
+type TransferAlertDispatcher struct {
+	distributor TaskDistributor
+	thresholds  map[string]int64
+}
+
+// NewTransferAlertDispatcher alerts on transfers above the threshold of their currency,
+// currencies without a threshold are never alerted on
+func NewTransferAlertDispatcher(distributor TaskDistributor, thresholds map[string]int64) *TransferAlertDispatcher {
+	return &TransferAlertDispatcher{
+		distributor: distributor,
+		thresholds:  thresholds,
+	}
+}
+
+func (d *TransferAlertDispatcher) LargeTransferThreshold(currency string) (int64, bool) {
+	threshold, ok := d.thresholds[currency]
+	return threshold, ok
+}
+
+func (d *TransferAlertDispatcher) AlertLargeTransfer(ctx context.Context, transfer models.Transaction) error {
+	payload := &PayloadSendTransferAlertEmail{
+		TransactionID: transfer.ID,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(QueueCritical),
+	}
+
+	return d.distributor.DistributeTaskSendTransferAlertEmail(ctx, payload, opts...)
+}
+
+// ProcessTaskSendTransferAlertEmail tells the sender about a large transfer. It is a security
+// alert, so it ignores notification preferences.
+func (processor *RedisTaskProcessor) ProcessTaskSendTransferAlertEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendTransferAlertEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	transfer, err := processor.repo.GetTransaction(ctx, payload.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	fromAccount, err := processor.repo.GetAccountIncludingDeleted(ctx, transfer.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get sending account: %w", err)
+	}
+
+	toAccount, err := processor.repo.GetAccountIncludingDeleted(ctx, transfer.ToAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get receiving account: %w", err)
+	}
+
+	sender, err := processor.repo.GetUser(ctx, fromAccount.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get sender: %w", err)
+	}
+
+	recipient, err := processor.repo.GetUser(ctx, toAccount.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient: %w", err)
+	}
+
+	// only the channel is taken from the preferences, the alert itself cannot be turned off
+	preferences, err := processor.service.GetNotificationPreferences(ctx, sender.UserName)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("username", sender.UserName).Msg("failed to get notification preferences")
+		preferences = models.NotificationPreferences{Username: sender.UserName}
+	}
+
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateTransferAlert, mail.TransferAlertData{
+		FullName:      sender.FullName,
+		Amount:        money.New(transfer.Amount, transfer.Currency).String(),
+		Currency:      transfer.Currency,
+		FromAccountID: fromAccount.ID,
+		RecipientName: recipient.FullName,
+		ToAccountID:   toAccount.ID,
+		SentAt:        transfer.CreatedAt.UTC().Format(time.RFC1123),
+		Description:   transfer.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render transfer alert: %w", err)
+	}
+
+	if err := processor.sendNotification(ctx, models.NotificationKindLargeTransfer, sender, preferences, subject, content, text); err != nil {
+		return fmt.Errorf("failed to send transfer alert: %w", err)
+	}
+
+	// written once the alert went out so a retried send does not add it twice
+	err = processor.recordNotification(ctx, models.Notification{
+		Username: sender.UserName,
+		Kind:     models.NotificationKindLargeTransfer,
+		Title:    subject,
+		Body:     text,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int64("transaction_id", transfer.ID).Msg("failed to record large transfer notification")
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("transaction_id", transfer.ID).
+		Str("email", sender.Email).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskSendTransferAlertEmail(t *testing.T) {
+	sender := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail()}
+	recipient := models.User{UserName: helpers.RandomOwner(), FullName: "Charles Babbage", Email: helpers.RandomEmail()}
+	fromAccount := models.Account{ID: 1, Owner: sender.UserName, Currency: helpers.USD}
+	toAccount := models.Account{ID: 2, Owner: recipient.UserName, Currency: helpers.USD}
+	transfer := models.Transaction{
+		ID:            42,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        2500000,
+		Currency:      helpers.USD,
+		Description:   "deposit for the flat",
+		CreatedAt:     time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetTransaction(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.UserName)).Times(1).Return(sender, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.UserName)).Times(1).Return(recipient, nil)
+	repo.EXPECT().
+		CreateNotification(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, notification models.Notification) (models.Notification, error) {
+			require.Equal(t, sender.UserName, notification.Username)
+			require.Equal(t, models.NotificationKindLargeTransfer, notification.Kind)
+			return notification, nil
+		})
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq([]string{sender.Email}), gomock.Nil(), gomock.Nil(), gomock.Nil()).
+		Times(1).
+		DoAndReturn(func(subject, content, text string, to, cc, bcc []string, attachments []mail.Attachment) error {
+			require.Equal(t, "Large transfer from your account", subject)
+			require.Contains(t, content, "Hello Ada Lovelace")
+			require.Contains(t, content, "25000.00 USD")
+			require.Contains(t, content, "from your account 1 to Charles Babbage (account 2)")
+			require.Contains(t, content, "Tue, 05 Mar 2024 14:30:00 UTC")
+			require.Contains(t, text, "25000.00 USD")
+			require.Contains(t, text, "Memo: deposit for the flat")
+			require.NotContains(t, text, "<p>")
+			return nil
+		})
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Eq(sender.UserName)).
+		Times(1).
+		Return(models.NotificationPreferences{Username: sender.UserName}, nil)
+
+	processor := &RedisTaskProcessor{
+		repo:     repo,
+		service:  service,
+		mailer:   mailer,
+		notifier: notify.New(notify.NewEmailNotifier(mailer)),
+	}
+
+	payload, err := json.Marshal(PayloadSendTransferAlertEmail{TransactionID: transfer.ID})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendTransferAlertEmail(context.Background(), asynq.NewTask(TaskSendTransferAlertEmail, payload))
+	require.NoError(t, err)
+}
+
+type fakeSMSClient struct {
+	to, body string
+}
+
+func (c *fakeSMSClient) SendSMS(ctx context.Context, from, to, body string) error {
+	c.to, c.body = to, body
+	return nil
+}
+
+func TestProcessTaskSendTransferAlertEmailBySMS(t *testing.T) {
+	sender := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail(), PhoneNumber: "+15005550006", IsPhoneVerified: true}
+	recipient := models.User{UserName: helpers.RandomOwner(), FullName: "Charles Babbage", Email: helpers.RandomEmail()}
+	fromAccount := models.Account{ID: 1, Owner: sender.UserName, Currency: helpers.USD}
+	toAccount := models.Account{ID: 2, Owner: recipient.UserName, Currency: helpers.USD}
+	transfer := models.Transaction{ID: 42, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 2500000, Currency: helpers.USD}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetTransaction(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	repo.EXPECT().GetAccountIncludingDeleted(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.UserName)).Times(1).Return(sender, nil)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.UserName)).Times(1).Return(recipient, nil)
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Times(1).Return(models.Notification{}, nil)
+
+	service := mockedproviders.NewMockServiceProvider(ctrl)
+	service.EXPECT().
+		GetNotificationPreferences(gomock.Any(), gomock.Eq(sender.UserName)).
+		Times(1).
+		Return(models.NotificationPreferences{Username: sender.UserName, PreferSMS: true}, nil)
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	sms := &fakeSMSClient{}
+	processor := &RedisTaskProcessor{
+		repo:     repo,
+		service:  service,
+		mailer:   mailer,
+		notifier: notify.New(notify.NewEmailNotifier(mailer), notify.NewSMSNotifier(sms, "+15005550000")),
+	}
+
+	payload, err := json.Marshal(PayloadSendTransferAlertEmail{TransactionID: transfer.ID})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendTransferAlertEmail(context.Background(), asynq.NewTask(TaskSendTransferAlertEmail, payload))
+	require.NoError(t, err)
+	require.Equal(t, sender.PhoneNumber, sms.to)
+	require.Contains(t, sms.body, "25000.00 USD")
+	require.NotContains(t, sms.body, "<p>")
+}
+
+func TestTransferAlertDispatcherThreshold(t *testing.T) {
+	dispatcher := NewTransferAlertDispatcher(nil, map[string]int64{helpers.USD: 1000000})
+
+	threshold, ok := dispatcher.LargeTransferThreshold(helpers.USD)
+	require.True(t, ok)
+	require.Equal(t, int64(1000000), threshold)
+
+	_, ok = dispatcher.LargeTransferThreshold(helpers.EUR)
+	require.False(t, ok)
+}
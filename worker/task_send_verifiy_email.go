@@ -4,17 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	"github.com/zde37/Swift_Bank/helpers"
+	"github.com/zde37/Swift_Bank/mail"
 	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/requestid"
 )
 
 const TaskSendVerifyEmail = "task:send_verify_email"
 
+// PayloadSendVerifyEmail sends the code of an existing verify email when VerifyEmailID is set,
+// so a task delivered twice sends the same code. Otherwise a new code is created.
 type PayloadSendVerifyEmail struct {
-	Username string `json:"username"`
+	Username      string `json:"username"`
+	VerifyEmailID int64  `json:"verify_email_id,omitempty"`
 }
 
 func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
@@ -22,7 +29,7 @@ func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
 	payload *PayloadSendVerifyEmail,
 	opts ...asynq.Option,
 ) error {
-	jsonPayload, err := json.Marshal(payload)
+	jsonPayload, err := marshalPayload(ctx, payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal task payload: %w", err)
 	}
@@ -35,6 +42,7 @@ func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
 
 	log.Info().
 		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
 		Bytes("payload", task.Payload()).
 		Str("queue", info.Queue).
 		Int("max_retry", info.MaxRetry).
@@ -57,28 +65,42 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	verifyEmail, err := processor.repo.CreateVerifyEmail(ctx, models.VerifyEmails{
-		Username:   user.UserName,
-		Email:      user.Email,
-		SecretCode: helpers.RandomString(32),
-	})
-
+	verifyEmail, err := processor.verifyEmail(ctx, payload, user)
 	if err != nil {
-		return fmt.Errorf("failed to create verify email: %w", err)
+		return err
 	}
 
-	subject := "Welcome to Swift Bank"
-	verifyURL := fmt.Sprintf("http://localhost:8080/sb/api/v1/verify_email?email_id=%d&secret_code=%s", verifyEmail.ID, verifyEmail.SecretCode)
-	content := fmt.Sprintf(`Hello %s,<br/>
-	Thank you for registering with us!<br/>
-	Please <a href="%s">Click here</a> to verify your email address.<br/>`, user.FullName, verifyURL)
+	// an existing code may have been used, run out or been made for an address the user has since
+	// changed while the task waited, sending it would only confuse the user
+	if payload.VerifyEmailID != 0 && (verifyEmail.IsUsed || !verifyEmail.ExpiredAt.After(time.Now()) || verifyEmail.Email != user.Email) {
+		log.Ctx(ctx).Info().
+			Str("type", task.Type()).
+			Int64("verify_email_id", verifyEmail.ID).
+			Msg("skipped task, verify email can no longer be used")
+		return nil
+	}
+
+	verifyURL := fmt.Sprintf("http://localhost:8080/v1/verify_email?email_id=%d&secret_code=%s", verifyEmail.ID, verifyEmail.SecretCode)
+	subject, content, text, err := mail.RenderTemplate(mail.TemplateVerifyEmail, mail.VerifyEmailData{
+		FullName:  user.FullName,
+		VerifyURL: verifyURL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render verify email: %w", err)
+	}
 	to := []string{user.Email}
 
-	if err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil); err != nil {
-		return fmt.Errorf("failed to send verify email: %w", err)
+	if err = processor.mailer.SendEmail(subject, content, text, to, nil, nil, nil); err != nil {
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      models.NotificationKindVerifyEmail,
+			Channel:   notify.ChannelEmail,
+			Recipient: user.Email,
+			Subject:   subject,
+		}, fmt.Errorf("failed to send verify email: %w", err))
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("type", task.Type()).
 		Bytes("payload", task.Payload()).
 		Str("email", user.Email).
@@ -86,3 +108,24 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 
 	return nil
 }
+
+func (processor *RedisTaskProcessor) verifyEmail(ctx context.Context, payload PayloadSendVerifyEmail, user models.User) (models.VerifyEmails, error) {
+	if payload.VerifyEmailID != 0 {
+		verifyEmail, err := processor.repo.GetVerifyEmail(ctx, payload.VerifyEmailID)
+		if err != nil {
+			return verifyEmail, fmt.Errorf("failed to get verify email: %w", err)
+		}
+		return verifyEmail, nil
+	}
+
+	verifyEmail, err := processor.repo.CreateVerifyEmail(ctx, models.VerifyEmails{
+		Username:   user.UserName,
+		Email:      user.Email,
+		SecretCode: helpers.RandomString(32),
+	})
+	if err != nil {
+		return verifyEmail, fmt.Errorf("failed to create verify email: %w", err)
+	}
+
+	return verifyEmail, nil
+}
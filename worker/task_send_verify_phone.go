@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"github.com/zde37/Swift_Bank/requestid"
+)
+
+const TaskSendVerifyPhone = "task:send_verify_phone"
+
+// phoneCodeDigits is the length of the code texted to verify a phone number
+const phoneCodeDigits = 6
+
+// PayloadSendVerifyPhone texts a new code to the user's current phone number. Each attempt
+// creates a new code, only the latest one can be used.
+type PayloadSendVerifyPhone struct {
+	Username string `json:"username"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyPhone(
+	ctx context.Context,
+	payload *PayloadSendVerifyPhone,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := marshalPayload(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendVerifyPhone, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("request_id", requestid.FromContext(ctx)).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendVerifyPhone(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendVerifyPhone
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.repo.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// the number may have been removed or verified while the task waited
+	if user.PhoneNumber == "" || user.IsPhoneVerified {
+		log.Ctx(ctx).Info().
+			Str("type", task.Type()).
+			Str("username", user.UserName).
+			Msg("skipped task, phone number needs no verification")
+		return nil
+	}
+
+	if !processor.notifier.Supports(notify.ChannelSMS) {
+		return fmt.Errorf("cannot verify phone number, SMS is not configured: %w", asynq.SkipRetry)
+	}
+
+	code, err := newPhoneCode()
+	if err != nil {
+		return fmt.Errorf("failed to create phone code: %w", err)
+	}
+
+	verifyPhone, err := processor.repo.CreateVerifyPhone(ctx, models.VerifyPhones{
+		Username:    user.UserName,
+		PhoneNumber: user.PhoneNumber,
+		SecretCode:  code,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verify phone: %w", err)
+	}
+
+	recipient := notify.Recipient{
+		Name:  user.FullName,
+		Phone: verifyPhone.PhoneNumber,
+	}
+	text := fmt.Sprintf("Your Swift Bank verification code is %s. It expires in 10 minutes.", verifyPhone.SecretCode)
+	if err := processor.notifier.Send(ctx, recipient, notify.Message{Text: text}, notify.ChannelSMS); err != nil {
+		return processor.sendFailed(ctx, models.FailedNotification{
+			Username:  user.UserName,
+			Kind:      models.NotificationKindVerifyPhone,
+			Channel:   notify.ChannelSMS,
+			Recipient: verifyPhone.PhoneNumber,
+		}, fmt.Errorf("failed to send verify phone SMS: %w", err))
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Str("username", user.UserName).
+		Int64("verify_phone_id", verifyPhone.ID).
+		Msg("processed task")
+
+	return nil
+}
+
+// newPhoneCode returns phoneCodeDigits random digits, from crypto/rand as the code is short
+func newPhoneCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(phoneCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n), nil
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Swift_Bank/helpers"
+	mockedproviders "github.com/zde37/Swift_Bank/mock"
+	"github.com/zde37/Swift_Bank/models"
+	"github.com/zde37/Swift_Bank/notify"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessTaskSendVerifyPhone(t *testing.T) {
+	user := models.User{UserName: helpers.RandomOwner(), FullName: "Ada Lovelace", Email: helpers.RandomEmail(), PhoneNumber: "+15005550006"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var created models.VerifyPhones
+	repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+	repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.UserName)).Times(1).Return(user, nil)
+	repo.EXPECT().
+		CreateVerifyPhone(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, data models.VerifyPhones) (models.VerifyPhones, error) {
+			created = data
+			data.ID = 1
+			return data, nil
+		})
+
+	mailer := mockedproviders.NewMockEmailSender(ctrl)
+	sms := &fakeSMSClient{}
+	processor := &RedisTaskProcessor{
+		repo:     repo,
+		notifier: notify.New(notify.NewEmailNotifier(mailer), notify.NewSMSNotifier(sms, "+15005550000")),
+	}
+
+	payload, err := json.Marshal(PayloadSendVerifyPhone{Username: user.UserName})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendVerifyPhone(context.Background(), asynq.NewTask(TaskSendVerifyPhone, payload))
+	require.NoError(t, err)
+
+	require.Equal(t, user.UserName, created.Username)
+	require.Equal(t, user.PhoneNumber, created.PhoneNumber)
+	require.Regexp(t, `^[0-9]{6}$`, created.SecretCode)
+	require.Equal(t, user.PhoneNumber, sms.to)
+	require.Contains(t, sms.body, created.SecretCode)
+}
+
+func TestProcessTaskSendVerifyPhoneSkips(t *testing.T) {
+	testCases := []struct {
+		name     string
+		user     models.User
+		notifier notify.Notifier
+		err      error
+	}{
+		{
+			name:     "NoPhoneNumber",
+			user:     models.User{UserName: "ada"},
+			notifier: notify.New(),
+		},
+		{
+			name:     "AlreadyVerified",
+			user:     models.User{UserName: "ada", PhoneNumber: "+15005550006", IsPhoneVerified: true},
+			notifier: notify.New(),
+		},
+		{
+			name:     "SMSNotConfigured",
+			user:     models.User{UserName: "ada", PhoneNumber: "+15005550006"},
+			notifier: notify.New(),
+			err:      asynq.SkipRetry,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mockedproviders.NewMockRepositoryProvider(ctrl)
+			repo.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.UserName)).Times(1).Return(tc.user, nil)
+			repo.EXPECT().CreateVerifyPhone(gomock.Any(), gomock.Any()).Times(0)
+
+			processor := &RedisTaskProcessor{repo: repo, notifier: tc.notifier}
+
+			payload, err := json.Marshal(PayloadSendVerifyPhone{Username: tc.user.UserName})
+			require.NoError(t, err)
+
+			err = processor.ProcessTaskSendVerifyPhone(context.Background(), asynq.NewTask(TaskSendVerifyPhone, payload))
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceField carries the trace context of the request that enqueued a task, as W3C headers
+const traceField = "trace"
+
+var tracer = otel.Tracer("github.com/zde37/Swift_Bank/worker")
+
+// traceCarrier returns the trace context of ctx as headers, empty when ctx is not part of a trace
+func traceCarrier(ctx context.Context) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// payloadTraceCarrier returns the trace headers a payload was enqueued with
+func payloadTraceCarrier(payload []byte) propagation.MapCarrier {
+	var fields struct {
+		Trace map[string]string `json:"trace"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return propagation.MapCarrier{}
+	}
+
+	return propagation.MapCarrier(fields.Trace)
+}
+
+// withTrace runs each task in a span continuing the trace of the request that enqueued it
+func withTrace(handler asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, payloadTraceCarrier(task.Payload()))
+
+		ctx, span := tracer.Start(ctx, task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attribute.String("task.type", task.Type())),
+		)
+		defer span.End()
+
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			span.SetAttributes(attribute.String("task.id", id))
+		}
+
+		err := handler.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return err
+	})
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider that samples every span and keeps them in memory
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	defaultProvider, defaultPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(defaultProvider)
+		otel.SetTextMapPropagator(defaultPropagator)
+	})
+
+	return recorder
+}
+
+func TestMarshalPayloadTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "CreateUser")
+	parent.End()
+
+	jsonPayload, err := marshalPayload(ctx, &PayloadSendVerifyEmail{Username: "ada"})
+	require.NoError(t, err)
+
+	carrier := payloadTraceCarrier(jsonPayload)
+	require.Contains(t, carrier.Get("traceparent"), parent.SpanContext().TraceID().String())
+
+	var handled trace.SpanContext
+	handler := withTrace(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		handled = trace.SpanContextFromContext(ctx)
+		return nil
+	}))
+	require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(TaskSendVerifyEmail, jsonPayload)))
+
+	// the task span joins the trace of the request that enqueued it
+	require.Equal(t, parent.SpanContext().TraceID(), handled.TraceID())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, TaskSendVerifyEmail, spans[1].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+}
+
+func TestMarshalPayloadWithoutTrace(t *testing.T) {
+	recordSpans(t)
+
+	jsonPayload, err := marshalPayload(context.Background(), &PayloadSendVerifyEmail{Username: "ada"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"username":"ada"}`, string(jsonPayload))
+}